@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loykin/provisr/internal/audit"
+	"github.com/loykin/provisr/internal/auth/store"
+	"github.com/loykin/provisr/internal/config"
+)
+
+// auditPageSize is how many store entries AuditVerify reads at a time.
+const auditPageSize = 1000
+
+// AuditVerify checks the hash chain of an audit log file, or of the log
+// configured in [server.audit].
+func (c *command) AuditVerify(f AuditVerifyFlags, configPath string) error {
+	entries, err := loadAuditEntries(f.File, configPath)
+	if err != nil {
+		return err
+	}
+	if err := audit.Verify(entries); err != nil {
+		return err
+	}
+	fmt.Printf("audit log intact: %d entries\n", len(entries))
+	return nil
+}
+
+func loadAuditEntries(file, configPath string) ([]*audit.Entry, error) {
+	if file != "" {
+		return audit.ReadFile(file)
+	}
+	if configPath == "" {
+		return nil, fmt.Errorf("--file or --config is required")
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	if cfg.Server == nil || cfg.Server.Audit == nil || !cfg.Server.Audit.Enabled {
+		return nil, fmt.Errorf("server.audit is not enabled in %s", configPath)
+	}
+	if cfg.Server.Audit.Sink != "store" {
+		return audit.ReadFile(cfg.Server.Audit.Path)
+	}
+
+	authStore, err := store.NewAuthStore(cfg.Server.Auth.Store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth store: %w", err)
+	}
+	defer func() { _ = authStore.Close() }()
+	var entries []*audit.Entry
+	for {
+		page, total, err := authStore.ListAuditEntries(context.Background(), len(entries), auditPageSize)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page...)
+		if len(page) == 0 || len(entries) >= total {
+			return entries, nil
+		}
+	}
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/loykin/provisr/internal/auth"
@@ -12,26 +15,28 @@ import (
 
 // createAuthenticatedAPIClient creates an API client with session authentication
 func (c *command) createAuthenticatedAPIClient(apiUrl string, timeout time.Duration) (*APIClient, error) {
-	// Try to load session first
-	sessionManager := NewSessionManager()
-	session, err := sessionManager.LoadSession()
+	// Try to load the selected context first
+	session, err := c.sessions().LoadContext()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load session: %w", err)
 	}
 
-	// If no session found, return regular client
+	// If no context found, return regular client
 	if session == nil {
 		return NewAPIClient(apiUrl, timeout), nil
 	}
 
-	// Use session's server URL if apiUrl is empty
+	// Use the context's server URL if apiUrl is empty
 	if apiUrl == "" {
 		apiUrl = session.ServerURL
 	}
 
-	// Create authenticated API client
+	// Create authenticated API client; an expired token is left out so the
+	// server answers 401 rather than rejecting a stale token.
 	client := NewAPIClient(apiUrl, timeout)
-	client.SetAuthToken(session.Token)
+	if !session.expired() {
+		client.SetAuthToken(session.Token)
+	}
 
 	return client, nil
 }
@@ -60,14 +65,8 @@ func (c *command) createAuthStore(configPath string) (store.AuthStore, error) {
 // AuthUserCreate creates a new user
 func (c *command) AuthUserCreate(f AuthUserCreateFlags, configPath string) error {
 	ctx := context.Background()
-	validRoles := map[string]bool{"admin": true, "operator": true, "viewer": true}
-	if len(f.Roles) == 0 {
-		return fmt.Errorf("at least one role is required")
-	}
-	for _, role := range f.Roles {
-		if !validRoles[role] {
-			return fmt.Errorf("invalid role %q (allowed: admin, operator, viewer)", role)
-		}
+	if err := auth.ValidateRoles(f.Roles); err != nil {
+		return err
 	}
 
 	authStore, err := c.createAuthStore(configPath)
@@ -91,9 +90,13 @@ func (c *command) AuthUserCreate(f AuthUserCreateFlags, configPath string) error
 	return nil
 }
 
-// AuthUserList lists all users
-func (c *command) AuthUserList(configPath string) error {
+// AuthUserList lists users in the given output format
+func (c *command) AuthUserList(f AuthUserListFlags, configPath, output string) error {
 	ctx := context.Background()
+	format, err := parseOutputFormat(output, os.Stdout)
+	if err != nil {
+		return err
+	}
 
 	authStore, err := c.createAuthStore(configPath)
 	if err != nil {
@@ -105,12 +108,18 @@ func (c *command) AuthUserList(configPath string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create auth service: %w", err)
 	}
-	cliHelper := auth.NewCLIHelper(authService)
+	filter := auth.UserFilter{IncludeDeleted: f.IncludeDeleted, ActiveOnly: f.ActiveOnly}
+	users, total, err := authService.ListUsersWithFilter(ctx, filter, 0, 100)
+	if err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
+	}
 
-	return cliHelper.ListUsers(ctx)
+	return writeOutput(os.Stdout, format, users, func(w io.Writer, _ outputFormat) error {
+		return auth.WriteUsers(w, users, total)
+	})
 }
 
-// AuthUserDelete deletes a user
+// AuthUserDelete soft-deletes a user, or removes it with --hard
 func (c *command) AuthUserDelete(f AuthUserDeleteFlags, configPath string) error {
 	ctx := context.Background()
 
@@ -126,7 +135,26 @@ func (c *command) AuthUserDelete(f AuthUserDeleteFlags, configPath string) error
 	}
 	cliHelper := auth.NewCLIHelper(authService)
 
-	return cliHelper.DeleteUser(ctx, f.Username)
+	return cliHelper.DeleteUser(ctx, f.Username, f.Hard)
+}
+
+// AuthUserRestore reactivates a soft-deleted user
+func (c *command) AuthUserRestore(f AuthUserRestoreFlags, configPath string) error {
+	ctx := context.Background()
+
+	authStore, err := c.createAuthStore(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to create auth store: %w", err)
+	}
+	defer func() { _ = authStore.Close() }()
+
+	authService, err := auth.NewAuthServiceWithStore(authStore)
+	if err != nil {
+		return fmt.Errorf("failed to create auth service: %w", err)
+	}
+	cliHelper := auth.NewCLIHelper(authService)
+
+	return cliHelper.RestoreUser(ctx, f.Username)
 }
 
 // AuthUserPassword resets a user's password
@@ -148,6 +176,33 @@ func (c *command) AuthUserPassword(f AuthUserPasswordFlags, configPath string) e
 	return cliHelper.ResetUserPassword(ctx, f.Username, f.NewPassword)
 }
 
+// AuthUserRoles replaces a user's roles
+func (c *command) AuthUserRoles(f AuthUserRolesFlags, configPath string) error {
+	ctx := context.Background()
+	if err := auth.ValidateRoles(f.Roles); err != nil {
+		return err
+	}
+
+	authStore, err := c.createAuthStore(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to create auth store: %w", err)
+	}
+	defer func() { _ = authStore.Close() }()
+
+	authService, err := auth.NewAuthServiceWithStore(authStore)
+	if err != nil {
+		return fmt.Errorf("failed to create auth service: %w", err)
+	}
+
+	user, err := authService.SetUserRoles(ctx, f.Username, f.Roles)
+	if err != nil {
+		return fmt.Errorf("failed to set roles: %w", err)
+	}
+
+	fmt.Printf("Roles for user '%s' set to %s\n", user.Username, strings.Join(user.Roles, ","))
+	return nil
+}
+
 // AuthTest tests authentication with given credentials
 func (c *command) AuthTest(f AuthTestFlags, configPath string) error {
 	ctx := context.Background()
@@ -193,12 +248,22 @@ func (c *command) Login(f LoginFlags) error {
 		if f.Username == "" || f.Password == "" {
 			return fmt.Errorf("username and password are required for basic auth")
 		}
+	case "oidc":
+		if f.IDToken == "" {
+			return fmt.Errorf("--id-token is required for oidc auth")
+		}
 	default:
-		return fmt.Errorf("unsupported auth method: %s (supported: basic)", f.Method)
+		return fmt.Errorf("unsupported auth method: %s (supported: basic, oidc)", f.Method)
 	}
 
-	// Default server URL if not specified
+	// Default to the context's server URL, then the local daemon
+	sessionManager := c.sessions()
 	serverURL := f.ServerURL
+	if serverURL == "" {
+		if saved, err := sessionManager.LoadContext(); err == nil && saved != nil {
+			serverURL = saved.ServerURL
+		}
+	}
 	if serverURL == "" {
 		serverURL = "http://localhost:8080/api"
 	}
@@ -217,6 +282,8 @@ func (c *command) Login(f LoginFlags) error {
 	if f.Method == "basic" {
 		loginRequest["username"] = f.Username
 		loginRequest["password"] = f.Password
+	} else {
+		loginRequest["token"] = f.IDToken
 	}
 
 	// Make login request
@@ -226,15 +293,15 @@ func (c *command) Login(f LoginFlags) error {
 	}
 
 	// Save session
-	sessionManager := NewSessionManager()
 	session := &Session{
-		Token:     result.Token.Value,
-		TokenType: result.Token.Type,
-		ExpiresAt: result.Token.ExpiresAt,
-		Username:  result.Username,
-		UserID:    result.UserID,
-		Roles:     result.Roles,
-		ServerURL: serverURL,
+		Token:        result.Token.Value,
+		TokenType:    result.Token.Type,
+		RefreshToken: result.Token.RefreshToken,
+		ExpiresAt:    result.Token.ExpiresAt,
+		Username:     result.Username,
+		UserID:       result.UserID,
+		Roles:        result.Roles,
+		ServerURL:    serverURL,
 	}
 
 	if err := sessionManager.SaveSession(session); err != nil {
@@ -250,7 +317,7 @@ func (c *command) Login(f LoginFlags) error {
 
 // Logout clears the saved session
 func (c *command) Logout() error {
-	sessionManager := NewSessionManager()
+	sessionManager := c.sessions()
 
 	if !sessionManager.IsLoggedIn() {
 		fmt.Println("No active session found")
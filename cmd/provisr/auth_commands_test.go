@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -376,3 +377,104 @@ func TestSession_Expiration(t *testing.T) {
 		t.Error("should not be logged in with expired session")
 	}
 }
+
+func TestSessionContexts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PROVISR_CONTEXT", "")
+
+	sessions := NewSessionManager()
+	for _, name := range []string{"staging", "prod"} {
+		session := &Session{
+			Token:        name + "-token",
+			TokenType:    "Bearer",
+			RefreshToken: name + "-refresh",
+			ExpiresAt:    time.Now().Add(time.Hour),
+			Username:     "admin",
+			ServerURL:    "http://" + name + ":8080/api",
+		}
+		if err := sessions.WithContext(name).SaveSession(session); err != nil {
+			t.Fatalf("save %s: %v", name, err)
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(sessions.GetSessionPath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Fatalf("contexts file mode = %o, want 600", perm)
+		}
+	}
+
+	// The first context saved becomes current.
+	current, err := NewSessionManager().LoadSession()
+	if err != nil || current == nil || current.Token != "staging-token" || current.RefreshToken != "staging-refresh" {
+		t.Fatalf("current session = %+v, err=%v; want staging", current, err)
+	}
+
+	cmd := &command{}
+	if err := cmd.ContextUse("prod"); err != nil {
+		t.Fatal(err)
+	}
+	client, err := cmd.createAuthenticatedAPIClient("", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.baseURL != "http://prod:8080/api" {
+		t.Fatalf("client URL after switching = %s, want prod", client.baseURL)
+	}
+
+	override := "staging"
+	client, err = (&command{sessionContext: &override}).createAuthenticatedAPIClient("", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.baseURL != "http://staging:8080/api" {
+		t.Fatalf("client URL with --context = %s, want staging", client.baseURL)
+	}
+
+	if err := cmd.ContextDelete("staging"); err != nil {
+		t.Fatal(err)
+	}
+	contexts, err := sessions.Contexts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contexts) != 1 || contexts[0].Name != "prod" || !contexts[0].Current || !contexts[0].LoggedIn {
+		t.Fatalf("contexts after delete = %+v, want only current prod", contexts)
+	}
+	if err := cmd.ContextUse("staging"); err == nil {
+		t.Fatal("expected an error switching to a deleted context")
+	}
+}
+
+func TestSessionLegacyFileBecomesDefaultContext(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PROVISR_CONTEXT", "")
+
+	legacy := `{"token":"old","token_type":"Bearer","expires_at":"` +
+		time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `","server_url":"http://old:8080/api"}`
+	if err := os.MkdirAll(filepath.Join(home, ".provisr"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".provisr", "session.json"), []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := NewSessionManager().LoadSession()
+	if err != nil || session == nil || session.Token != "old" {
+		t.Fatalf("legacy session = %+v, err=%v", session, err)
+	}
+	if err := NewSessionManager().WithContext("other").SaveSession(&Session{ServerURL: "http://other"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".provisr", "session.json")); !os.IsNotExist(err) {
+		t.Fatalf("legacy session file still present: %v", err)
+	}
+	contexts, err := NewSessionManager().Contexts()
+	if err != nil || len(contexts) != 2 || contexts[0].Name != defaultContextName || !contexts[0].Current {
+		t.Fatalf("contexts = %+v, err=%v; want current default and other", contexts, err)
+	}
+}
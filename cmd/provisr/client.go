@@ -1,14 +1,35 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/loykin/provisr"
+	"github.com/loykin/provisr/internal/unixsock"
+	"github.com/loykin/provisr/internal/version"
+	apiwire "github.com/loykin/provisr/pkg/api"
 )
 
+// errProcessNotFound is returned by GetStatus for a name the daemon does not
+// manage.
+var errProcessNotFound = errors.New("process not found")
+
+// errMetricsDisabled is returned by ProcessMetrics when the daemon does not
+// collect process metrics.
+var errMetricsDisabled = errors.New("process metrics collection is disabled on the daemon")
+
 // APIClient provides HTTP client functionality to communicate with provisr daemon
 type APIClient struct {
 	baseURL   string
@@ -24,11 +45,15 @@ func NewAPIClient(baseURL string, timeout time.Duration) *APIClient {
 	if timeout == 0 {
 		timeout = 10 * time.Second
 	}
+	client := &http.Client{Timeout: timeout}
+	// unix:///path/to.sock talks to a daemon listening on a Unix socket.
+	if socketPath, httpURL, ok := unixsock.ParseURL(baseURL); ok {
+		client.Transport = unixsock.Transport(socketPath)
+		baseURL = httpURL
+	}
 	return &APIClient{
 		baseURL: baseURL,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:  client,
 	}
 }
 
@@ -61,8 +86,60 @@ func (c *APIClient) RegisterProcess(spec interface{}) error {
 	return nil
 }
 
+// GetSpec fetches the spec of a registered process via
+// GET /processes/{name}/spec, decoded as a JSON object.
+func (c *APIClient) GetSpec(name string) (map[string]any, error) {
+	resp, err := c.doRequest("GET", c.baseURL+"/processes/"+neturl.PathEscape(name)+"/spec", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+	var spec map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// UpdateProcess replaces the spec of a registered process via POST /update;
+// the daemon saves it to its program file and restarts the process.
+func (c *APIClient) UpdateProcess(spec any) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doRequest("POST", c.baseURL+"/update", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return c.handleErrorResponse(resp)
+	}
+	return nil
+}
+
 // GetStatus gets process status via API
 func (c *APIClient) GetStatus(name string) (interface{}, error) {
+	return c.getStatus(name, false, false, nil)
+}
+
+// GetDetailedStatus gets the expanded status (uptime, last exit, resource
+// usage, hook summaries) via GET /status?detailed=true.
+func (c *APIClient) GetDetailedStatus(name string) (interface{}, error) {
+	return c.getStatus(name, true, false, nil)
+}
+
+// getStatus fetches name, or every process when name is empty. running
+// leaves stopped processes out of the list, and labels ("key=value") the
+// processes without those labels.
+func (c *APIClient) getStatus(name string, detailed, running bool, labels []string) (interface{}, error) {
 	url := c.baseURL + "/status"
 	if name != "" {
 		url += "?name=" + name
@@ -70,6 +147,15 @@ func (c *APIClient) GetStatus(name string) (interface{}, error) {
 		// When no name is provided, fetch all statuses using wildcard match
 		url += "?wildcard=*"
 	}
+	if detailed {
+		url += "&detailed=true"
+	}
+	if running {
+		url += "&running=true"
+	}
+	for _, label := range labels {
+		url += "&label=" + neturl.QueryEscape(label)
+	}
 
 	resp, err := c.doRequest("GET", url, nil)
 	if err != nil {
@@ -78,7 +164,11 @@ func (c *APIClient) GetStatus(name string) (interface{}, error) {
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleErrorResponse(resp)
+		err := c.handleErrorResponse(resp)
+		if name != "" && strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("%w: %s", errProcessNotFound, name)
+		}
+		return nil, err
 	}
 
 	var result interface{}
@@ -113,6 +203,114 @@ func (c *APIClient) StartProcess(name string) error {
 	return c.doPostRequest(url)
 }
 
+// Restart stops and starts a process, or every member of a group when
+// group is set, via POST /restart. The daemon answers once the processes
+// are running again; a 207 response still carries per-process results.
+func (c *APIClient) Restart(name string, group bool, wait time.Duration) (apiwire.RestartResponse, error) {
+	var result apiwire.RestartResponse
+	selector := "name"
+	if group {
+		selector = "group"
+	}
+	url := fmt.Sprintf("%s/restart?%s=%s&wait=%s", c.baseURL, selector, neturl.QueryEscape(name), wait)
+	resp, err := c.doRequest("POST", url, nil)
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+		return result, c.handleErrorResponse(resp)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
+
+// Signal sends sig, a signal name or number, to a running process via
+// POST /signal.
+func (c *APIClient) Signal(name, sig string) error {
+	url := fmt.Sprintf("%s/signal?name=%s&signal=%s", c.baseURL, neturl.QueryEscape(name), neturl.QueryEscape(sig))
+	return c.doPostRequest(url)
+}
+
+// Scale changes the number of instances of the process set name via
+// POST /scale.
+func (c *APIClient) Scale(name string, instances int) error {
+	url := fmt.Sprintf("%s/scale?name=%s&instances=%d", c.baseURL, neturl.QueryEscape(name), instances)
+	return c.doPostRequest(url)
+}
+
+// CreateJob creates and starts a job via POST /jobs.
+func (c *APIClient) CreateJob(spec provisr.JobSpec) error {
+	body, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	resp, err := c.doRequest("POST", c.baseURL+"/jobs", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return c.handleErrorResponse(resp)
+	}
+	return nil
+}
+
+// Jobs lists the daemon's jobs with their status, sorted by name.
+func (c *APIClient) Jobs() ([]apiwire.Job, error) {
+	var jobs []apiwire.Job
+	if err := c.getJSON(c.baseURL+"/jobs", &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetJob returns the spec and status of the job name.
+func (c *APIClient) GetJob(name string) (apiwire.Job, error) {
+	var job apiwire.Job
+	err := c.getJSON(c.baseURL+"/jobs/"+neturl.PathEscape(name), &job)
+	return job, err
+}
+
+// JobInstances returns every instance launched for the job name via
+// GET /jobs/{name}/instances.
+func (c *APIClient) JobInstances(name string) ([]provisr.JobInstanceStatus, error) {
+	var instances []provisr.JobInstanceStatus
+	if err := c.getJSON(c.baseURL+"/jobs/"+neturl.PathEscape(name)+"/instances", &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// DeleteJob stops the job name's instances and removes it via
+// DELETE /jobs/{name}.
+func (c *APIClient) DeleteJob(name string) error {
+	resp, err := c.doRequest("DELETE", c.baseURL+"/jobs/"+neturl.PathEscape(name), nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return c.handleErrorResponse(resp)
+	}
+	return nil
+}
+
+// SuspendJob stops the job name from launching new instances via
+// POST /jobs/{name}/suspend.
+func (c *APIClient) SuspendJob(name string) error {
+	return c.doPostRequest(c.baseURL + "/jobs/" + neturl.PathEscape(name) + "/suspend")
+}
+
+// ResumeJob lets a suspended job launch instances again via
+// POST /jobs/{name}/resume.
+func (c *APIClient) ResumeJob(name string) error {
+	return c.doPostRequest(c.baseURL + "/jobs/" + neturl.PathEscape(name) + "/resume")
+}
+
 // UnregisterProcess stops and unregisters a process via API
 func (c *APIClient) UnregisterProcess(name string, wait ...time.Duration) error {
 	url := c.baseURL + "/unregister?name=" + name
@@ -157,6 +355,260 @@ func (c *APIClient) GetGroupStatus(groupName string) (interface{}, error) {
 	return result, nil
 }
 
+// ProcessNames lists the names of every process the daemon manages.
+func (c *APIClient) ProcessNames() ([]string, error) {
+	resp, err := c.doRequest("GET", c.baseURL+"/status?wildcard=*", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+	var statuses []struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(statuses))
+	for _, st := range statuses {
+		names = append(names, st.Name)
+	}
+	return names, nil
+}
+
+// GroupNames lists the names of the daemon's process groups.
+func (c *APIClient) GroupNames() ([]string, error) {
+	resp, err := c.doRequest("GET", c.baseURL+"/groups", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+	var groups []apiwire.GroupInfo
+	if err := json.NewDecoder(resp.Body).Decode(&groups); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	return names, nil
+}
+
+// CronJobInfo is a cron job as listed by GET /cronjobs: its spec, status
+// and next scheduled run.
+type CronJobInfo struct {
+	provisr.CronJob
+	Status       provisr.CronJobStatus `json:"status"`
+	NextSchedule *time.Time            `json:"next_schedule,omitempty"`
+	Provisioned  bool                  `json:"provisioned"`
+}
+
+// CronJobs lists the daemon's cron jobs by name. A daemon without a cron
+// scheduler has none.
+func (c *APIClient) CronJobs() ([]CronJobInfo, error) {
+	resp, err := c.doRequest("GET", c.baseURL+"/cronjobs", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return []CronJobInfo{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+	var jobs []CronJobInfo
+	if err := json.NewDecoder(resp.Body).Decode(&jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ProcessMetrics returns the latest resource sample of every running
+// process, keyed by process name, via GET /metrics.
+func (c *APIClient) ProcessMetrics() (map[string]provisr.ProcessMetrics, error) {
+	resp, err := c.doRequest("GET", c.baseURL+"/metrics", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusServiceUnavailable:
+		return nil, errMetricsDisabled
+	default:
+		return nil, c.handleErrorResponse(resp)
+	}
+	var metrics map[string]provisr.ProcessMetrics
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// Export downloads the definition of every process, group and cron job via
+// GET /export, as the raw JSON document.
+func (c *APIClient) Export() ([]byte, error) {
+	resp, err := c.doRequest("GET", c.baseURL+"/export", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Import recreates the definitions of an export document via POST /import;
+// force replaces entries that already exist.
+func (c *APIClient) Import(doc []byte, force bool) (apiwire.ImportResult, error) {
+	var result apiwire.ImportResult
+	url := c.baseURL + "/import"
+	if force {
+		url += "?force=true"
+	}
+	resp, err := c.doRequest("POST", url, bytes.NewReader(doc))
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return result, c.handleErrorResponse(resp)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
+
+// LogsPage is one page of captured output from GET /processes/{name}/logs.
+type LogsPage struct {
+	Lines []provisr.LogLine `json:"lines"`
+	Next  uint64            `json:"next"` // offset to pass as since on the next call
+}
+
+// Logs fetches up to limit captured stdout/stderr lines of a process,
+// starting at offset since.
+func (c *APIClient) Logs(name string, since uint64, limit int) (LogsPage, error) {
+	var page LogsPage
+	url := fmt.Sprintf("%s/processes/%s/logs?since=%d&limit=%d", c.baseURL, neturl.PathEscape(name), since, limit)
+	resp, err := c.doRequest("GET", url, nil)
+	if err != nil {
+		return page, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return page, c.handleErrorResponse(resp)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&page)
+	return page, err
+}
+
+// EventsQuery selects the lifecycle events Events and FollowEvents return.
+type EventsQuery struct {
+	Name  string
+	Types []string
+	Since string // RFC 3339 time or duration ago
+	Until string
+	Limit int
+}
+
+func (q EventsQuery) encode(follow bool) string {
+	v := neturl.Values{}
+	if q.Name != "" {
+		v.Set("name", q.Name)
+	}
+	if len(q.Types) > 0 {
+		v.Set("type", strings.Join(q.Types, ","))
+	}
+	if q.Since != "" {
+		v.Set("since", q.Since)
+	}
+	if q.Until != "" {
+		v.Set("until", q.Until)
+	}
+	v.Set("limit", strconv.Itoa(q.Limit))
+	if follow {
+		v.Set("follow", "true")
+	}
+	return v.Encode()
+}
+
+// Events fetches recorded lifecycle events, oldest first.
+func (c *APIClient) Events(q EventsQuery) ([]apiwire.LifecycleEvent, error) {
+	resp, err := c.doRequest("GET", c.baseURL+"/events?"+q.encode(false), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+	var result apiwire.EventsResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result.Events, err
+}
+
+// FollowEvents calls fn with the recorded events matching q and then with
+// each new one as it happens, until ctx is done or fn fails.
+func (c *APIClient) FollowEvents(ctx context.Context, q EventsQuery, fn func(apiwire.LifecycleEvent) error) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/events?"+q.encode(true), nil)
+	if err != nil {
+		return err
+	}
+	if c.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+	// The stream stays open indefinitely; the client timeout would end it.
+	client := *c.client
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return c.handleErrorResponse(resp)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var e apiwire.LifecycleEvent
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			return fmt.Errorf("decode event: %w", err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("the daemon closed the event stream")
+}
+
 // GroupStart starts all processes in a group
 func (c *APIClient) GroupStart(groupName string) error {
 	url := c.baseURL + "/group/start?group=" + groupName
@@ -172,6 +624,27 @@ func (c *APIClient) GroupStop(groupName string, wait ...time.Duration) error {
 	return c.doPostRequest(url)
 }
 
+// GroupRollingRestart restarts the members of a group a batch of
+// maxUnavailable at a time via POST /group/rolling-restart. The daemon
+// answers once the rollout finished or stopped at a failed batch; a 207
+// response still carries per-process results.
+func (c *APIClient) GroupRollingRestart(groupName string, maxUnavailable int, wait, readyTimeout time.Duration) (apiwire.RestartResponse, error) {
+	var result apiwire.RestartResponse
+	url := fmt.Sprintf("%s/group/rolling-restart?group=%s&max_unavailable=%d&wait=%s&ready_timeout=%s",
+		c.baseURL, neturl.QueryEscape(groupName), maxUnavailable, wait, readyTimeout)
+	resp, err := c.doRequest("POST", url, nil)
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+		return result, c.handleErrorResponse(resp)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
+
 // LoginResponse represents the response from login endpoint
 type LoginResponse struct {
 	Success  bool       `json:"success"`
@@ -186,6 +659,8 @@ type TokenInfo struct {
 	Type      string    `json:"type"`
 	Value     string    `json:"value"`
 	ExpiresAt time.Time `json:"expires_at"`
+	// RefreshToken is set when the server issues one.
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
 // Login authenticates with the server and returns login response
@@ -243,7 +718,35 @@ func (c *APIClient) doRequest(method, url string, body io.Reader) (*http.Respons
 		req.Header.Set("Authorization", "Bearer "+c.authToken)
 	}
 
-	return c.client.Do(req)
+	resp, err := c.client.Do(req)
+	if err == nil {
+		warnVersionSkew(resp.Header.Get(apiwire.VersionHeader))
+	}
+	return resp, err
+}
+
+var (
+	versionWarnOnce sync.Once
+	versionWarnOut  io.Writer = os.Stderr
+)
+
+// warnVersionSkew warns once per run when the daemon that answered runs a
+// different release than the CLI.
+func warnVersionSkew(daemon string) {
+	client := version.Get().Version
+	if !version.Skewed(client, daemon) {
+		return
+	}
+	versionWarnOnce.Do(func() {
+		_, _ = fmt.Fprintf(versionWarnOut, "warning: provisr %s is talking to a %s daemon; see 'provisr version'\n", client, daemon)
+	})
+}
+
+// Version returns the build information of the daemon via GET /version.
+func (c *APIClient) Version() (apiwire.VersionInfo, error) {
+	var info apiwire.VersionInfo
+	err := c.getJSON(c.baseURL+"/version", &info)
+	return info, err
 }
 
 // handleErrorResponse decodes and returns API error responses
@@ -261,6 +764,20 @@ func (c *APIClient) handleErrorResponse(resp *http.Response) error {
 	return fmt.Errorf("API error: %s", errorResp.Error)
 }
 
+// getJSON performs a GET request and decodes a 200 response into out.
+func (c *APIClient) getJSON(url string, out any) error {
+	resp, err := c.doRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return c.handleErrorResponse(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
 // doPostRequest performs a POST request with standard error handling
 func (c *APIClient) doPostRequest(url string) error {
 	resp, err := c.doRequest("POST", url, nil)
@@ -3,8 +3,11 @@ package main
 import (
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/loykin/provisr"
 )
 
 func TestNewAPIClient(t *testing.T) {
@@ -210,3 +213,32 @@ func TestAPIClientNetworkErrors(t *testing.T) {
 		t.Error("Expected network error for stop")
 	}
 }
+
+func TestAPIClientOverUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "provisr.sock")
+	mgr := provisr.New()
+	defer func() { _ = mgr.Shutdown() }()
+	srv, err := provisr.NewHTTPServerWithHistoryReader(provisr.ServerConfig{Listen: "unix://" + path, BasePath: "/api"}, mgr, nil, nil, "")
+	if err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	defer func() { _ = srv.Close() }()
+
+	client := NewAPIClient("unix://"+path, 5*time.Second)
+	if client.baseURL != "http://unix/api" {
+		t.Fatalf("unexpected baseURL %q", client.baseURL)
+	}
+	if !client.IsReachable() {
+		t.Fatal("daemon not reachable over unix socket")
+	}
+	if err := client.RegisterProcess(provisr.Spec{Name: "sock-demo", Command: "sleep 5"}); err != nil {
+		t.Fatalf("register over socket: %v", err)
+	}
+	status, err := client.GetStatus("sock-demo")
+	if err != nil {
+		t.Fatalf("status over socket: %v", err)
+	}
+	if st, ok := status.(map[string]interface{}); !ok || st["name"] != "sock-demo" {
+		t.Fatalf("unexpected status %#v", status)
+	}
+}
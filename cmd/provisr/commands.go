@@ -8,6 +8,19 @@ import (
 
 type command struct {
 	mgr *provisr.Manager
+	// sessionContext points at the --context flag; nil selects the
+	// default context.
+	sessionContext *string
+}
+
+// sessions returns the session manager for the --context flag, else for
+// $PROVISR_CONTEXT or the current context.
+func (c *command) sessions() *SessionManager {
+	sm := NewSessionManager()
+	if c.sessionContext != nil {
+		return sm.WithContext(*c.sessionContext)
+	}
+	return sm
 }
 
 // isExpectedShutdownError checks if the error is expected during shutdown
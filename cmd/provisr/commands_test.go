@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -368,7 +370,7 @@ func TestCommand_GroupStopViaAPI(t *testing.T) {
 func TestCommand_GroupStatusViaAPI(t *testing.T) {
 	mockServer := createMockAPIServer(
 		map[string]string{
-			"GET:/api/group/status?group=test-group": `{"name": "test-group", "members": ["proc1", "proc2"]}`,
+			"GET:/api/group/status?group=test-group": `{"proc1": [{"name": "proc1", "state": "running", "running": true, "pid": 42}], "proc2": []}`,
 		},
 		map[string]int{
 			"GET:/api/group/status?group=test-group": 200,
@@ -581,3 +583,183 @@ func TestCommand_GroupStop_WaitDefault(t *testing.T) {
 		t.Errorf("expected daemon not reachable error, got: %v", err)
 	}
 }
+
+func TestCommand_ExportImportViaAPI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	doc := `{"version":1,"processes":[{"name":"web","command":"sleep 5"}],"groups":[],"cronjobs":[]}`
+	mockServer := createMockAPIServer(
+		map[string]string{
+			"GET:/api/export":             doc,
+			"POST:/api/import?force=true": `{"created":[],"replaced":["process/web"],"skipped":[]}`,
+			"POST:/api/import":            `{"error":"already defined: process/web; import with force=true to replace them"}`,
+		},
+		map[string]int{"POST:/api/import": http.StatusConflict},
+	)
+	defer mockServer.Close()
+	cmd := &command{mgr: &provisr.Manager{}}
+	apiURL := mockServer.URL + "/api"
+
+	dump := filepath.Join(t.TempDir(), "dump.json")
+	if err := cmd.Export(ExportFlags{Output: dump, APIUrl: apiURL, APITimeout: 5 * time.Second}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	data, err := os.ReadFile(dump)
+	if err != nil {
+		t.Fatalf("read dump: %v", err)
+	}
+	if !strings.Contains(string(data), "\n  \"processes\"") {
+		t.Fatalf("expected an indented export document, got %s", data)
+	}
+
+	err = cmd.Import(ImportFlags{File: dump, APIUrl: apiURL, APITimeout: 5 * time.Second})
+	if err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Fatalf("import without force: expected conflict, got %v", err)
+	}
+	if err := cmd.Import(ImportFlags{File: dump, Force: true, APIUrl: apiURL, APITimeout: 5 * time.Second}); err != nil {
+		t.Fatalf("forced import: %v", err)
+	}
+}
+
+func TestCommand_RestartViaAPI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mockServer := createMockAPIServer(
+		map[string]string{
+			"POST:/api/restart?name=web&wait=5s":      `{"results":[{"name":"web","ok":true,"pid":4242}]}`,
+			"POST:/api/restart?group=backend&wait=3s": `{"results":[{"name":"api","ok":true,"pid":11},{"name":"db","ok":false,"error":"failed to start process"}]}`,
+			"POST:/api/restart?name=ghost&wait=3s":    `{"error":"no processes match ghost"}`,
+		},
+		map[string]int{
+			"POST:/api/restart?group=backend&wait=3s": http.StatusMultiStatus,
+			"POST:/api/restart?name=ghost&wait=3s":    http.StatusNotFound,
+		},
+	)
+	defer mockServer.Close()
+	cmd := &command{mgr: &provisr.Manager{}}
+	apiURL := mockServer.URL + "/api"
+
+	if err := cmd.Restart(RestartFlags{Name: "web", Wait: 5 * time.Second, APIUrl: apiURL, APITimeout: 5 * time.Second}); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	err := cmd.Restart(RestartFlags{Group: "backend", APIUrl: apiURL, APITimeout: 5 * time.Second})
+	if err == nil || !strings.Contains(err.Error(), "db") || strings.Contains(err.Error(), "api") {
+		t.Fatalf("group restart: expected a failure naming only db, got %v", err)
+	}
+	if err := cmd.Restart(RestartFlags{Name: "ghost", APIUrl: apiURL, APITimeout: 5 * time.Second}); err == nil {
+		t.Fatal("restart of an unknown process should fail")
+	}
+	if err := cmd.Restart(RestartFlags{Name: "web", Group: "backend", APIUrl: apiURL}); err == nil {
+		t.Fatal("restart with both --name and --group should fail")
+	}
+}
+
+func TestCommand_RollingRestartViaAPI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mockServer := createMockAPIServer(
+		map[string]string{
+			"POST:/api/group/rolling-restart?group=front&max_unavailable=1&wait=3s&ready_timeout=30s": `{"results":[{"name":"web-1","ok":true,"pid":11},{"name":"web-2","ok":true,"pid":12}]}`,
+			"POST:/api/group/rolling-restart?group=back&max_unavailable=2&wait=3s&ready_timeout=30s":  `{"results":[{"name":"db","ok":false,"error":"not running 30s after restart"}]}`,
+		},
+		map[string]int{
+			"POST:/api/group/rolling-restart?group=back&max_unavailable=2&wait=3s&ready_timeout=30s": http.StatusMultiStatus,
+		},
+	)
+	defer mockServer.Close()
+	cmd := &command{mgr: &provisr.Manager{}}
+	apiURL := mockServer.URL + "/api"
+	f := RollingRestartFlags{GroupName: "front", MaxUnavailable: 1, Wait: 3 * time.Second, ReadyTimeout: 30 * time.Second, APIUrl: apiURL, APITimeout: 5 * time.Second}
+
+	if err := cmd.RollingRestart(f); err != nil {
+		t.Fatalf("rolling restart: %v", err)
+	}
+	f.GroupName, f.MaxUnavailable = "back", 2
+	if err := cmd.RollingRestart(f); err == nil || !strings.Contains(err.Error(), "db") {
+		t.Fatalf("expected a failure naming db, got %v", err)
+	}
+	f.MaxUnavailable = 0
+	if err := cmd.RollingRestart(f); err == nil {
+		t.Fatal("rolling restart with --max-unavailable=0 should fail")
+	}
+}
+
+func TestCommand_ScaleViaAPI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mockServer := createMockAPIServer(
+		map[string]string{
+			"POST:/api/scale?name=worker&instances=5": `{"ok":true}`,
+			"POST:/api/scale?name=ghost&instances=2":  `{"error":"process ghost not found"}`,
+		},
+		map[string]int{
+			"POST:/api/scale?name=ghost&instances=2": http.StatusNotFound,
+		},
+	)
+	defer mockServer.Close()
+	cmd := &command{mgr: &provisr.Manager{}}
+	apiURL := mockServer.URL + "/api"
+
+	if err := cmd.Scale(ScaleFlags{Name: "worker", Instances: 5, APIUrl: apiURL, APITimeout: 5 * time.Second}); err != nil {
+		t.Fatalf("scale: %v", err)
+	}
+	if err := cmd.Scale(ScaleFlags{Name: "ghost", Instances: 2, APIUrl: apiURL, APITimeout: 5 * time.Second}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if err := cmd.Scale(ScaleFlags{Name: "worker", APIUrl: apiURL}); err == nil {
+		t.Fatal("scale without --instances should fail")
+	}
+}
+
+func TestCommand_SignalViaAPI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mockServer := createMockAPIServer(
+		map[string]string{
+			"POST:/api/signal?name=web&signal=HUP":  `{"ok":true}`,
+			"POST:/api/signal?name=idle&signal=HUP": `{"error":"process idle (state: stopped): process is not running"}`,
+		},
+		map[string]int{
+			"POST:/api/signal?name=idle&signal=HUP": http.StatusConflict,
+		},
+	)
+	defer mockServer.Close()
+	cmd := &command{mgr: &provisr.Manager{}}
+	apiURL := mockServer.URL + "/api"
+
+	if err := cmd.Signal(SignalFlags{Name: "web", Signal: "HUP", APIUrl: apiURL, APITimeout: 5 * time.Second}); err != nil {
+		t.Fatalf("signal: %v", err)
+	}
+	err := cmd.Signal(SignalFlags{Name: "idle", Signal: "HUP", APIUrl: apiURL, APITimeout: 5 * time.Second})
+	if err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Fatalf("signal to a stopped process: expected not running error, got %v", err)
+	}
+	if err := cmd.Signal(SignalFlags{Name: "web", APIUrl: apiURL}); err == nil {
+		t.Fatal("signal without --signal should fail")
+	}
+}
+
+func TestCommand_SuspendResumeJobViaAPI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mockServer := createMockAPIServer(
+		map[string]string{
+			"POST:/api/jobs/migrate/suspend": `{"name":"migrate","status":{"phase":"Suspended"}}`,
+			"POST:/api/jobs/migrate/resume":  `{"name":"migrate","status":{"phase":"Running"}}`,
+			"POST:/api/jobs/ghost/suspend":   `{"error":"job \"ghost\" not found"}`,
+		},
+		map[string]int{
+			"POST:/api/jobs/ghost/suspend": http.StatusNotFound,
+		},
+	)
+	defer mockServer.Close()
+	cmd := &command{mgr: &provisr.Manager{}}
+	apiURL := mockServer.URL + "/api"
+
+	if err := cmd.SuspendJob(JobFlags{Name: "migrate", APIUrl: apiURL, APITimeout: 5 * time.Second}); err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	if err := cmd.ResumeJob(JobFlags{Name: "migrate", APIUrl: apiURL, APITimeout: 5 * time.Second}); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if err := cmd.SuspendJob(JobFlags{Name: "ghost", APIUrl: apiURL, APITimeout: 5 * time.Second}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if err := cmd.ResumeJob(JobFlags{APIUrl: apiURL}); err == nil {
+		t.Fatal("resume without --name should fail")
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/loykin/provisr"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds how long completing a name waits for the daemon,
+// so a slow daemon doesn't stall the shell.
+const completionTimeout = 2 * time.Second
+
+// createCompletionCommand creates the completion subcommand
+func createCompletionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate a shell completion script",
+		Long: `Generate a completion script for your shell. Besides commands and flags,
+--name and --group complete process and group names: from the daemon when
+it is reachable (--api-url, or the logged-in session), otherwise from the
+--config file when one is given.
+
+Load it in the current shell:
+  source <(provisr completion bash)
+  source <(provisr completion zsh)
+  provisr completion fish | source
+  provisr completion powershell | Out-String | Invoke-Expression
+
+Or install it for every session:
+  provisr completion bash > /etc/bash_completion.d/provisr
+  provisr completion zsh > "${fpath[1]}/_provisr"
+  provisr completion fish > ~/.config/fish/completions/provisr.fish`,
+		DisableFlagsInUseLine: true,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, out := cmd.Root(), cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			case "fish":
+				return root.GenFishCompletion(out, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(out)
+			}
+			return fmt.Errorf("unsupported shell %q", args[0])
+		},
+	}
+}
+
+// completeProcessNames completes a --name flag with the processes the
+// daemon manages, or those of the --config file when the daemon isn't
+// reachable.
+func (c *command) completeProcessNames(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := c.completionNames(cmd, (*APIClient).ProcessNames)
+	if err != nil {
+		names = configProcessNames(cmd)
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGroupNames completes a --group flag like completeProcessNames.
+func (c *command) completeGroupNames(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := c.completionNames(cmd, (*APIClient).GroupNames)
+	if err != nil {
+		names = configGroupNames(cmd)
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func (c *command) completionNames(cmd *cobra.Command, list func(*APIClient) ([]string, error)) ([]string, error) {
+	apiUrl := ""
+	if f := cmd.Flag("api-url"); f != nil {
+		apiUrl = f.Value.String()
+	}
+	apiClient, err := c.createAuthenticatedAPIClient(apiUrl, completionTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return list(apiClient)
+}
+
+// completionConfig loads the --config file, or returns nil without one.
+func completionConfig(cmd *cobra.Command) *provisr.LoadedConfig {
+	f := cmd.Flag("config")
+	if f == nil || f.Value.String() == "" {
+		return nil
+	}
+	cfg, err := provisr.LoadConfig(f.Value.String())
+	if err != nil {
+		return nil
+	}
+	return cfg
+}
+
+func configProcessNames(cmd *cobra.Command) []string {
+	cfg := completionConfig(cmd)
+	if cfg == nil {
+		return nil
+	}
+	naming, err := cfg.InstanceNaming.Scheme()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, spec := range cfg.Specs {
+		names = append(names, naming.Names(spec.Name, spec.Instances)...)
+	}
+	return names
+}
+
+func configGroupNames(cmd *cobra.Command) []string {
+	cfg := completionConfig(cmd)
+	if cfg == nil {
+		return nil
+	}
+	names := make([]string, 0, len(cfg.GroupSpecs))
+	for _, g := range cfg.GroupSpecs {
+		names = append(names, g.Name)
+	}
+	return names
+}
+
+// filterCompletions returns the names starting with prefix, sorted and
+// without duplicates.
+func filterCompletions(names []string, prefix string) []string {
+	sort.Strings(names)
+	var out []string
+	for i, name := range names {
+		if strings.HasPrefix(name, prefix) && (i == 0 || names[i-1] != name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// mustCompleteFlag registers fn to complete flag of cmd.
+func mustCompleteFlag(cmd *cobra.Command, flag string, fn cobra.CompletionFunc) {
+	if err := cmd.RegisterFlagCompletionFunc(flag, fn); err != nil {
+		panic(err) // This should never happen during setup
+	}
+}
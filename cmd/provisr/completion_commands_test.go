@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/loykin/provisr"
+)
+
+// complete runs the hidden __complete command the shell scripts call and
+// returns the offered completions.
+func complete(t *testing.T, args ...string) []string {
+	t.Helper()
+	root, _ := buildRoot(&provisr.Manager{})
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(append([]string{"__complete"}, args...))
+	if err := root.Execute(); err != nil {
+		t.Fatalf("__complete %v: %v", args, err)
+	}
+	var completions []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if !strings.HasPrefix(line, ":") {
+			completions = append(completions, line)
+		}
+	}
+	return completions
+}
+
+func TestCompletionFromDaemon(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	mockServer := createMockAPIServer(map[string]string{
+		"GET:/api/status?wildcard=*": `[{"name":"worker"},{"name":"web-2"},{"name":"web-1"}]`,
+		"GET:/api/groups":            `[{"name":"backend","members":[]},{"name":"frontend","members":[]}]`,
+	}, nil)
+	defer mockServer.Close()
+	apiUrl := mockServer.URL + "/api"
+
+	if got := strings.Join(complete(t, "stop", "--api-url", apiUrl, "--name", "we"), ","); got != "web-1,web-2" {
+		t.Fatalf("process names: got %s", got)
+	}
+	if got := strings.Join(complete(t, "group-start", "--api-url", apiUrl, "--group", ""), ","); got != "backend,frontend" {
+		t.Fatalf("group names: got %s", got)
+	}
+}
+
+func TestCompletionFallsBackToConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	configData := `
+[[processes]]
+type = "process"
+[processes.spec]
+name = "web"
+command = "sleep 1"
+instances = 2
+
+[[processes]]
+type = "process"
+[processes.spec]
+name = "worker"
+command = "sleep 1"
+
+[[groups]]
+name = "backend"
+members = ["worker"]
+`
+	if err := os.WriteFile(configPath, []byte(configData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Nothing listens here, so the daemon is unreachable.
+	mockServer := createMockAPIServer(nil, nil)
+	apiUrl := mockServer.URL + "/api"
+	mockServer.Close()
+
+	if got := strings.Join(complete(t, "status", "--config", configPath, "--api-url", apiUrl, "--name", ""), ","); got != "web-1,web-2,worker" {
+		t.Fatalf("process names: got %s", got)
+	}
+	if got := strings.Join(complete(t, "restart", "--config", configPath, "--api-url", apiUrl, "--group", "b"), ","); got != "backend" {
+		t.Fatalf("group names: got %s", got)
+	}
+	if got := complete(t, "start", "--api-url", apiUrl, "--name", ""); len(got) != 0 {
+		t.Fatalf("expected no names without a daemon or config, got %v", got)
+	}
+}
+
+func TestCompletionCommand(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		root, _ := buildRoot(&provisr.Manager{})
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs([]string{"completion", shell})
+		if err := root.Execute(); err != nil {
+			t.Fatalf("completion %s: %v", shell, err)
+		}
+		if !strings.Contains(out.String(), "provisr") {
+			t.Fatalf("completion %s produced no script", shell)
+		}
+	}
+	root, _ := buildRoot(&provisr.Manager{})
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"completion", "tcsh"})
+	if err := root.Execute(); err == nil {
+		t.Fatal("expected an error for an unsupported shell")
+	}
+}
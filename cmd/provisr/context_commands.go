@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// ContextList prints the saved contexts, marking the current one.
+func (c *command) ContextList(output string) error {
+	format, err := parseOutputFormat(output, os.Stdout)
+	if err != nil {
+		return err
+	}
+	contexts, err := c.sessions().Contexts()
+	if err != nil {
+		return fmt.Errorf("failed to load contexts: %w", err)
+	}
+	return writeOutput(os.Stdout, format, contexts, func(w io.Writer, _ outputFormat) error {
+		return writeContexts(w, contexts)
+	})
+}
+
+func writeContexts(w io.Writer, contexts []ContextInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CURRENT\tNAME\tSERVER\tUSER\tEXPIRES")
+	for _, info := range contexts {
+		current := ""
+		if info.Current {
+			current = "*"
+		}
+		user, expires := "-", "logged out"
+		if info.Username != "" {
+			user = info.Username
+		}
+		if info.LoggedIn {
+			expires = info.ExpiresAt.Local().Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", current, info.Name, info.ServerURL, user, expires)
+	}
+	return tw.Flush()
+}
+
+// ContextUse makes name the context later commands target.
+func (c *command) ContextUse(name string) error {
+	if err := c.sessions().UseContext(name); err != nil {
+		return err
+	}
+	fmt.Printf("Switched to context %q\n", name)
+	return nil
+}
+
+// ContextDelete removes a saved context and its token.
+func (c *command) ContextDelete(name string) error {
+	if err := c.sessions().DeleteContext(name); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted context %q\n", name)
+	return nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// runEditor opens path in $EDITOR and waits for it to exit. Tests replace
+// it to edit files without a terminal.
+var runEditor = func(path string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+		if runtime.GOOS == "windows" {
+			editor = []string{"notepad"}
+		}
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s: %w", editor[0], err)
+	}
+	return nil
+}
+
+// Edit opens the spec of process f.Name in $EDITOR and applies it once
+// saved. Without --api-url the program file in the programs directory is
+// edited, and a running local daemon updates the process from it; with
+// --api-url the spec is fetched from and pushed back to that daemon.
+func (c *command) Edit(f EditFlags, configPath string) error {
+	if f.Name == "" {
+		return fmt.Errorf("process name is required")
+	}
+	if f.APIUrl != "" {
+		apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+		if err != nil {
+			return err
+		}
+		return c.editViaAPI(f, apiClient)
+	}
+	return c.editLocally(f, configPath)
+}
+
+// editViaAPI edits the spec the daemon reports for f.Name and sends it back
+// with POST /update.
+func (c *command) editViaAPI(f EditFlags, apiClient *APIClient) error {
+	spec, err := apiClient.GetSpec(f.Name)
+	if err != nil {
+		return err
+	}
+	if provisioned, _ := spec["provisioned"].(bool); provisioned {
+		return fmt.Errorf("cannot edit process '%s': it is defined in config.toml", f.Name)
+	}
+	delete(spec, "provisioned")
+
+	edited, changed, err := c.editSpec(f.Name, spec)
+	if err != nil || !changed {
+		return err
+	}
+	if err := apiClient.UpdateProcess(edited); err != nil {
+		return err
+	}
+	fmt.Printf("Process '%s' updated\n", f.Name)
+	return nil
+}
+
+// editLocally edits the JSON program file of f.Name. A reachable local
+// daemon saves the result and restarts the process; otherwise the file is
+// rewritten and the change applies the next time the daemon loads it.
+func (c *command) editLocally(f EditFlags, configPath string) error {
+	if c.isProcessInConfigFile(f.Name, configPath) {
+		return fmt.Errorf("cannot edit process '%s': it is defined in config.toml", f.Name)
+	}
+	programsDir, err := c.getProgramsDirectory(configPath)
+	if err != nil {
+		return err
+	}
+	programFile := filepath.Join(programsDir, f.Name+".json")
+	data, err := os.ReadFile(programFile)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("process '%s' is not registered (no %s)", f.Name, programFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read program file: %w", err)
+	}
+
+	var program map[string]interface{}
+	if err := json.Unmarshal(data, &program); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", programFile, err)
+	}
+	// Program files are normally {type, spec}; older ones are a bare spec.
+	spec := program
+	if wrapped, ok := program["spec"].(map[string]interface{}); ok {
+		if t, _ := program["type"].(string); t != "" && t != "process" {
+			return fmt.Errorf("%s defines a %s, not a process", programFile, t)
+		}
+		spec = wrapped
+	}
+
+	edited, changed, err := c.editSpec(f.Name, spec)
+	if err != nil || !changed {
+		return err
+	}
+
+	if apiClient, err := c.daemonClient("", f.APITimeout); err == nil {
+		if err := apiClient.UpdateProcess(edited); err != nil {
+			return err
+		}
+		fmt.Printf("Process '%s' updated\n", f.Name)
+		return nil
+	}
+
+	if _, ok := program["spec"]; ok {
+		program["spec"] = edited
+	} else {
+		program = edited
+	}
+	jsonData, err := json.MarshalIndent(program, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal program data: %w", err)
+	}
+	if err := os.WriteFile(programFile, jsonData, 0o644); err != nil {
+		return fmt.Errorf("failed to write program file: %w", err)
+	}
+	fmt.Printf("Process '%s' updated in %s; the daemon is not running and will load it on start\n", f.Name, programFile)
+	return nil
+}
+
+// editSpec lets the user edit spec as JSON in a temporary file and returns
+// the result, or changed=false when the file was saved unchanged. The
+// edited spec must pass validateProcessSpec and keep its name.
+func (c *command) editSpec(name string, spec map[string]interface{}) (map[string]interface{}, bool, error) {
+	original, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	tmp, err := os.CreateTemp("", "provisr-"+name+"-*.json")
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	_, err = tmp.Write(append(original, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := runEditor(tmp.Name()); err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return nil, false, err
+	}
+	if bytes.Equal(bytes.TrimSpace(data), original) {
+		fmt.Printf("Process '%s' unchanged\n", name)
+		return nil, false, nil
+	}
+
+	var edited map[string]interface{}
+	if err := json.Unmarshal(data, &edited); err != nil {
+		return nil, false, fmt.Errorf("edited spec is not valid JSON: %w", err)
+	}
+	if err := c.validateProcessSpec(edited); err != nil {
+		return nil, false, fmt.Errorf("invalid process specification: %w", err)
+	}
+	if edited["name"] != name {
+		return nil, false, fmt.Errorf("the name of process '%s' cannot be changed; register a new process instead", name)
+	}
+	return edited, true, nil
+}
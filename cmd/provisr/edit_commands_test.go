@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubEditor makes runEditor rewrite the edited file with edit.
+func stubEditor(t *testing.T, edit func(spec map[string]any)) {
+	t.Helper()
+	orig := runEditor
+	t.Cleanup(func() { runEditor = orig })
+	runEditor = func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var spec map[string]any
+		if err := json.Unmarshal(data, &spec); err != nil {
+			return err
+		}
+		if edit == nil {
+			return nil // saved without changes
+		}
+		edit(spec)
+		data, _ = json.Marshal(spec)
+		return os.WriteFile(path, data, 0o644)
+	}
+}
+
+func TestEditLocally(t *testing.T) {
+	tempDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatal(err)
+	}
+	programFile := filepath.Join(tempDir, "programs", "web.json")
+	if err := os.MkdirAll(filepath.Dir(programFile), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(programFile, []byte(`{"type":"process","spec":{"name":"web","command":"sleep 1"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &command{}
+	// Nothing listens on the API port, so the program file is rewritten.
+	f := EditFlags{Name: "web", APITimeout: time.Second}
+
+	stubEditor(t, func(spec map[string]any) { spec["name"] = "api" })
+	if err := cmd.Edit(f, ""); err == nil || !strings.Contains(err.Error(), "cannot be changed") {
+		t.Fatalf("expected a rename to be rejected, got %v", err)
+	}
+	stubEditor(t, func(spec map[string]any) { delete(spec, "command") })
+	if err := cmd.Edit(f, ""); err == nil || !strings.Contains(err.Error(), "'command' field is required") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	stubEditor(t, func(spec map[string]any) { spec["command"] = "sleep 60" })
+	if err := cmd.Edit(f, ""); err != nil {
+		t.Skipf("edit with a daemon possibly listening on 127.0.0.1:8080: %v", err)
+	}
+	data, err := os.ReadFile(programFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var program struct {
+		Type string         `json:"type"`
+		Spec map[string]any `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &program); err != nil {
+		t.Fatal(err)
+	}
+	if program.Type != "process" || program.Spec["command"] != "sleep 60" {
+		t.Fatalf("program file not updated: %s", data)
+	}
+
+	if err := cmd.Edit(EditFlags{Name: "missing", APITimeout: time.Second}, ""); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Fatalf("expected unknown process error, got %v", err)
+	}
+}
+
+func TestEditViaAPI(t *testing.T) {
+	var updated map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/processes/web/spec":
+			_, _ = w.Write([]byte(`{"name":"web","command":"sleep 1","auto_restart":false,"provisioned":false}`))
+		case "GET /api/processes/base/spec":
+			_, _ = w.Write([]byte(`{"name":"base","command":"sleep 1","provisioned":true}`))
+		case "POST /api/update":
+			_ = json.NewDecoder(r.Body).Decode(&updated)
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+	apiClient := NewAPIClient(srv.URL+"/api", 5*time.Second)
+	cmd := &command{}
+
+	stubEditor(t, nil)
+	if err := cmd.editViaAPI(EditFlags{Name: "web"}, apiClient); err != nil || updated != nil {
+		t.Fatalf("unchanged spec: err %v, update sent %v", err, updated)
+	}
+
+	stubEditor(t, func(spec map[string]any) { spec["auto_restart"] = true })
+	if err := cmd.editViaAPI(EditFlags{Name: "web"}, apiClient); err != nil {
+		t.Fatal(err)
+	}
+	if updated["auto_restart"] != true || updated["name"] != "web" {
+		t.Fatalf("unexpected update %v", updated)
+	}
+	if _, ok := updated["provisioned"]; ok {
+		t.Fatal("provisioned must not be sent back")
+	}
+
+	if err := cmd.editViaAPI(EditFlags{Name: "base"}, apiClient); err == nil || !strings.Contains(err.Error(), "config.toml") {
+		t.Fatalf("expected config.toml processes to be rejected, got %v", err)
+	}
+}
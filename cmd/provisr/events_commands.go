@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	apiwire "github.com/loykin/provisr/pkg/api"
+)
+
+// Events prints the recorded lifecycle events of f.Name, or of every
+// process, to out. With f.Follow it keeps printing new events until ctx is
+// done.
+func (c *command) Events(ctx context.Context, f EventsFlags, out io.Writer) error {
+	if f.Tail < 0 {
+		return fmt.Errorf("--tail must not be negative")
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	q := EventsQuery{Name: f.Name, Types: f.Types, Since: f.Since, Until: f.Until, Limit: f.Tail}
+	printEvent := func(e apiwire.LifecycleEvent) error {
+		_, err := fmt.Fprintln(out, formatEvent(e))
+		return err
+	}
+	if f.Follow {
+		return apiClient.FollowEvents(ctx, q, printEvent)
+	}
+	events, err := apiClient.Events(q)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if err := printEvent(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatEvent renders e as one line: time, type, process and whatever
+// details the event carries.
+func formatEvent(e apiwire.LifecycleEvent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %-7s  %s", e.Time.Local().Format(time.RFC3339), e.Type, e.Name)
+	if e.PID > 0 {
+		fmt.Fprintf(&b, "  pid=%d", e.PID)
+	}
+	if e.ExitCode != nil {
+		fmt.Fprintf(&b, "  exit=%d", *e.ExitCode)
+	}
+	if e.Error != "" {
+		fmt.Fprintf(&b, "  error=%q", e.Error)
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr"
+)
+
+func TestCommand_EventsViaAPI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	stamp := at.Local().Format(time.RFC3339)
+	events := `{"events":[
+		{"time":"2026-01-01T12:00:00Z","type":"start","name":"web","pid":42,"status":"running"},
+		{"time":"2026-01-01T12:00:00Z","type":"stop","name":"web","pid":42,"status":"failed","exit_code":2,"error":"exit status 2"}
+	]}`
+	stream := `data: {"time":"2026-01-01T12:00:00Z","type":"fatal","name":"web","error":"no such file"}` + "\n\n"
+	mockServer := createMockAPIServer(map[string]string{
+		"GET:/api/events?limit=50&name=web&type=start%2Cstop": events,
+		"GET:/api/events?follow=true&limit=0&name=web":        stream,
+		"GET:/api/events?limit=50&since=not-a-time":           `{"error":"invalid since"}`,
+	}, map[string]int{
+		"GET:/api/events?limit=50&since=not-a-time": 400,
+	})
+	defer mockServer.Close()
+	cmd := &command{mgr: &provisr.Manager{}}
+	apiUrl := mockServer.URL + "/api"
+
+	var out bytes.Buffer
+	err := cmd.Events(context.Background(), EventsFlags{Name: "web", Tail: 50, Types: []string{"start", "stop"}, APIUrl: apiUrl}, &out)
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	want := stamp + "  start    web  pid=42\n" +
+		stamp + "  stop     web  pid=42  exit=2  error=\"exit status 2\"\n"
+	if out.String() != want {
+		t.Fatalf("got %q, want %q", out.String(), want)
+	}
+
+	// The daemon ending the stream is reported after the events it sent.
+	out.Reset()
+	err = cmd.Events(context.Background(), EventsFlags{Name: "web", Follow: true, APIUrl: apiUrl}, &out)
+	if err == nil || !strings.Contains(err.Error(), "closed the event stream") {
+		t.Fatalf("expected a closed stream error, got %v", err)
+	}
+	if want := stamp + "  fatal    web  error=\"no such file\"\n"; out.String() != want {
+		t.Fatalf("followed %q, want %q", out.String(), want)
+	}
+
+	err = cmd.Events(context.Background(), EventsFlags{Tail: 50, Since: "not-a-time", APIUrl: apiUrl}, &out)
+	if err == nil || !strings.Contains(err.Error(), "invalid since") {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	apiwire "github.com/loykin/provisr/pkg/api"
+)
+
+// Export writes every process, group and cron job definition of the daemon
+// to f.Output, or to stdout when no output file is given.
+func (c *command) Export(f ExportFlags) error {
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	data, err := apiClient.Export()
+	if err != nil {
+		return err
+	}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, data, "", "  "); err != nil {
+		return fmt.Errorf("invalid export document: %w", err)
+	}
+	if f.Output == "" || f.Output == "-" {
+		_, err := os.Stdout.Write(pretty.Bytes())
+		return err
+	}
+
+	var doc apiwire.ExportDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid export document: %w", err)
+	}
+	// Specs may carry secrets in their environment.
+	if err := os.WriteFile(f.Output, pretty.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	fmt.Printf("Exported %d process(es), %d group(s) and %d cron job(s) to %s\n",
+		len(doc.Processes), len(doc.Groups), len(doc.CronJobs), f.Output)
+	return nil
+}
+
+// Import recreates the definitions of an export file on the daemon.
+func (c *command) Import(f ImportFlags) error {
+	if f.File == "" {
+		return fmt.Errorf("import requires an export file")
+	}
+	data, err := os.ReadFile(f.File)
+	if err != nil {
+		return fmt.Errorf("failed to read export file: %w", err)
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("%s is not a JSON export document", f.File)
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	result, err := apiClient.Import(data, f.Force)
+	if err != nil {
+		return err
+	}
+	printImportEntries("Created", result.Created)
+	printImportEntries("Replaced", result.Replaced)
+	printImportEntries("Skipped (defined in the daemon's config file)", result.Skipped)
+	return nil
+}
+
+// daemonClient returns an API client for apiUrl, the session's server or
+// the local daemon, in that order, and checks that the daemon answers.
+func (c *command) daemonClient(apiUrl string, timeout time.Duration) (*APIClient, error) {
+	apiClient, err := c.createAuthenticatedAPIClient(apiUrl, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if apiClient.baseURL == "" {
+		apiClient = NewAPIClient("http://127.0.0.1:8080/api", timeout)
+	}
+	if !apiClient.IsReachable() {
+		return nil, fmt.Errorf("daemon not reachable - please start daemon first with 'provisr serve'")
+	}
+	return apiClient, nil
+}
+
+func printImportEntries(label string, entries []string) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Printf("%s: %s\n", label, strings.Join(entries, ", "))
+}
@@ -12,7 +12,15 @@ type StartFlags struct {
 
 type StatusFlags struct {
 	Name     string
-	Detailed bool // Show detailed state information
+	Detailed bool     // Show detailed state information
+	Running  bool     // List only running processes; stopped ones are shown by default
+	Labels   []string // key=value labels listed processes must have
+	Output   string   // table, json, yaml or text; see GlobalFlags.Output
+	// Exit status for scripts; both require Name. See statusExitCode.
+	FailIfStopped bool
+	WaitFor       string        // running or stopped: poll until Name reaches it
+	WaitTimeout   time.Duration // how long WaitFor polls
+	Watch         time.Duration // redraw the status this often until interrupted; 0 prints it once
 	// Remote daemon connection
 	APIUrl     string
 	APITimeout time.Duration
@@ -26,9 +34,55 @@ type StopFlags struct {
 	APITimeout time.Duration
 }
 
+// RestartFlags holds flags for the restart command.
+type RestartFlags struct {
+	Name  string
+	Group string // restart every member of this group instead of Name
+	Wait  time.Duration
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
+}
+
+type SignalFlags struct {
+	Name   string
+	Signal string // signal name or number, e.g. HUP or SIGUSR1
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
+}
+
+type ScaleFlags struct {
+	Name      string
+	Instances int
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
+}
+
+// JobFlags selects a job of the daemon for the job subcommands; the spec
+// fields are only used by job create.
+type JobFlags struct {
+	Name           string
+	Command        string
+	WorkDir        string
+	Env            []string
+	Completions    int32
+	Parallelism    int32
+	BackoffLimit   int32
+	CompletionMode string
+	ActiveDeadline time.Duration
+	DependsOn      []string
+	Output         string // table, json, yaml or text; see GlobalFlags.Output
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
+}
+
 type CronFlags struct {
 	// For tests we can set NonBlocking to avoid infinite block
 	NonBlocking bool
+	Output      string // table, json, yaml or text; see GlobalFlags.Output
 	// Remote daemon connection
 	APIUrl     string
 	APITimeout time.Duration
@@ -37,6 +91,17 @@ type CronFlags struct {
 type GroupFlags struct {
 	GroupName string
 	Wait      time.Duration
+	Output    string // table, json, yaml or text (group-status only); see GlobalFlags.Output
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
+}
+
+type RollingRestartFlags struct {
+	GroupName      string
+	MaxUnavailable int           // instances restarted at once
+	Wait           time.Duration // graceful stop timeout per instance
+	ReadyTimeout   time.Duration // how long a restarted instance may take to run again
 	// Remote daemon connection
 	APIUrl     string
 	APITimeout time.Duration
@@ -47,6 +112,79 @@ type ServeFlags struct {
 	Daemonize  bool
 	PidFile    string
 	LogFile    string
+	DryRun     bool // print the apply plan instead of serving
+}
+
+// ExportFlags holds flags for the export command.
+type ExportFlags struct {
+	Output string // file to write; stdout when empty
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
+}
+
+// EditFlags holds flags for the edit command.
+type EditFlags struct {
+	Name string
+	// Remote daemon connection; when APIUrl is set the spec is edited on
+	// the daemon instead of in the local programs directory.
+	APIUrl     string
+	APITimeout time.Duration
+}
+
+// LogsFlags holds flags for the logs command.
+type LogsFlags struct {
+	Name   string
+	Follow bool
+	Tail   int           // last N lines; negative for all
+	Since  time.Duration // only lines from this long ago on; 0 for all
+	Stdout bool          // only stdout
+	Stderr bool          // only stderr
+	Local  bool          // read the log files set in --config instead of asking the daemon
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
+}
+
+// EventsFlags holds flags for the events command.
+type EventsFlags struct {
+	Name   string
+	Tail   int      // most recent N recorded events
+	Types  []string // start, stop, restart or fatal; all when empty
+	Since  string   // RFC 3339 time or duration ago
+	Until  string
+	Follow bool
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
+}
+
+// TopFlags holds flags for the top command.
+type TopFlags struct {
+	Sort     string        // cpu, mem, threads, fds, pid or name
+	Filter   string        // glob the process names shown must match; all when empty
+	Interval time.Duration // how often the table is refreshed
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
+}
+
+// VersionFlags holds flags for the version command.
+type VersionFlags struct {
+	Client bool   // only the CLI's version; the daemon is not asked
+	Output string // table, json, yaml or text; see GlobalFlags.Output
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
+}
+
+// ImportFlags holds flags for the import command.
+type ImportFlags struct {
+	File  string
+	Force bool // replace processes, cron jobs and groups that already exist
+	// Remote daemon connection
+	APIUrl     string
+	APITimeout time.Duration
 }
 
 // Auth command flags
@@ -57,8 +195,27 @@ type AuthUserCreateFlags struct {
 	Roles    []string
 }
 
+type AuthUserListFlags struct {
+	IncludeDeleted bool
+	ActiveOnly     bool
+}
+
 type AuthUserDeleteFlags struct {
 	Username string
+	Hard     bool
+}
+
+type AuthUserRestoreFlags struct {
+	Username string
+}
+
+type AuthUserRolesFlags struct {
+	Username string
+	Roles    []string
+}
+
+type AuditVerifyFlags struct {
+	File string
 }
 
 type AuthUserPasswordFlags struct {
@@ -77,6 +234,7 @@ type AuthTestFlags struct {
 type LoginFlags struct {
 	Username  string
 	Password  string
+	IDToken   string
 	Method    string
 	ServerURL string
 }
@@ -87,4 +245,5 @@ type TemplateCreateFlags struct {
 	Type   string
 	Force  bool
 	Output string
+	List   bool // list the available templates instead of creating one
 }
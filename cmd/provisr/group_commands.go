@@ -2,11 +2,21 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
 	"time"
+
+	apiwire "github.com/loykin/provisr/pkg/api"
 )
 
-// Cron verifies cron scheduler via daemon (REST). The actual scheduler runs inside the daemon started by 'serve'.
+// Cron lists the cron jobs of the daemon started by 'serve', which runs
+// their schedules; the CLI never runs a scheduler itself.
 func (c *command) Cron(f CronFlags) error {
+	format, err := parseOutputFormat(f.Output, os.Stdout)
+	if err != nil {
+		return err
+	}
 	// Always use API - default to local daemon if not specified
 	apiUrl := f.APIUrl
 	if apiUrl == "" {
@@ -16,13 +26,36 @@ func (c *command) Cron(f CronFlags) error {
 	if !apiClient.IsReachable() {
 		return fmt.Errorf("daemon not reachable at %s - please start daemon first with 'provisr serve'", apiUrl)
 	}
-	// Optionally check that daemon is healthy and responding with a status list
-	if _, err := apiClient.GetStatus(""); err != nil {
+	jobs, err := apiClient.CronJobs()
+	if err != nil {
 		return err
 	}
-	// Success: daemon manages cron; CLI does not run scheduler locally
-	fmt.Println("Cron scheduler is managed by the daemon. Jobs defined in the config are executed by 'provisr serve'.")
-	return nil
+	return writeOutput(os.Stdout, format, jobs, func(w io.Writer, _ outputFormat) error {
+		return writeCronJobs(w, jobs)
+	})
+}
+
+// writeCronJobs prints jobs as a table.
+func writeCronJobs(w io.Writer, jobs []CronJobInfo) error {
+	if len(jobs) == 0 {
+		_, err := fmt.Fprintln(w, "No cron jobs found")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tSCHEDULE\tSUSPENDED\tACTIVE\tLAST SCHEDULE\tNEXT SCHEDULE")
+	for _, job := range jobs {
+		suspended := job.Suspend != nil && *job.Suspend
+		last, next := "-", "-"
+		if t := job.Status.LastScheduleTime; t != nil {
+			last = t.Local().Format(time.RFC3339)
+		}
+		if job.NextSchedule != nil && !suspended {
+			next = job.NextSchedule.Local().Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%v\t%d\t%s\t%s\n",
+			job.Name, job.Schedule, suspended, len(job.Status.Active), last, next)
+	}
+	return tw.Flush()
 }
 
 // GroupStart starts a group
@@ -79,6 +112,32 @@ func (c *command) GroupStop(f GroupFlags) error {
 	return c.groupStopViaAPI(f, apiClient)
 }
 
+// RollingRestart restarts the members of a group a few at a time through
+// the daemon, so the group keeps serving during a redeploy
+func (c *command) RollingRestart(f RollingRestartFlags) error {
+	if f.GroupName == "" {
+		return fmt.Errorf("rolling-restart requires --group name")
+	}
+	if f.MaxUnavailable < 1 {
+		return fmt.Errorf("--max-unavailable must be at least 1")
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	result, err := apiClient.GroupRollingRestart(f.GroupName, f.MaxUnavailable, f.Wait, f.ReadyTimeout)
+	if err != nil {
+		return err
+	}
+	for _, res := range result.Results {
+		if !res.OK {
+			return fmt.Errorf("rolling restart of group %s stopped: failed to restart %s: %s", f.GroupName, res.Name, res.Error)
+		}
+		fmt.Printf("Restarted %s (PID %d)\n", res.Name, res.PID)
+	}
+	return nil
+}
+
 // groupStopViaAPI stops a group using the daemon API
 func (c *command) groupStopViaAPI(f GroupFlags, apiClient *APIClient) error {
 	err := apiClient.GroupStop(f.GroupName, f.Wait)
@@ -112,11 +171,20 @@ func (c *command) GroupStatus(f GroupFlags) error {
 
 // groupStatusViaAPI gets group status using the daemon API
 func (c *command) groupStatusViaAPI(f GroupFlags, apiClient *APIClient) error {
+	format, err := parseOutputFormat(f.Output, os.Stdout)
+	if err != nil {
+		return err
+	}
 	result, err := apiClient.GetGroupStatus(f.GroupName)
 	if err != nil {
 		return err
 	}
 
-	printJSON(result)
-	return nil
+	return writeOutput(os.Stdout, format, result, func(w io.Writer, format outputFormat) error {
+		rows, err := groupStatusRows(result)
+		if err != nil {
+			return err
+		}
+		return apiwire.WriteStatusRows(w, apiwire.Format(format), rows)
+	})
 }
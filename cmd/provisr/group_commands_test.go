@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -359,3 +361,34 @@ func TestGroupStop_WaitDurationHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestCronJobsTable(t *testing.T) {
+	next := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockServer := createMockAPIServer(map[string]string{
+		"GET:/api/cronjobs": fmt.Sprintf(`[
+			{"name":"backup","schedule":"@every 1h","suspend":false,"status":{},"next_schedule":%q},
+			{"name":"cleanup","schedule":"0 3 * * *","suspend":true,"status":{"active":[{"name":"cleanup-1"}]}}
+		]`, next.Format(time.RFC3339)),
+	}, nil)
+	defer mockServer.Close()
+
+	jobs, err := NewAPIClient(mockServer.URL+"/api", 5*time.Second).CronJobs()
+	if err != nil {
+		t.Fatalf("CronJobs: %v", err)
+	}
+	var out bytes.Buffer
+	if err := writeCronJobs(&out, jobs); err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME     SCHEDULE   SUSPENDED  ACTIVE  LAST SCHEDULE  NEXT SCHEDULE\n" +
+		"backup   @every 1h  false      0       -              " + next.Local().Format(time.RFC3339) + "\n" +
+		"cleanup  0 3 * * *  true       1       -              -\n"
+	if out.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := writeCronJobs(&out, nil); err != nil || out.String() != "No cron jobs found\n" {
+		t.Fatalf("empty list: got %q, %v", out.String(), err)
+	}
+}
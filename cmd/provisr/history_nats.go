@@ -0,0 +1,20 @@
+//go:build nats
+
+package main
+
+import (
+	"github.com/loykin/provisr"
+	corehistory "github.com/loykin/provisr/core/history"
+	"github.com/loykin/provisr/internal/config"
+	"github.com/loykin/provisr/internal/history/nats"
+	"github.com/loykin/provisr/pkg/metrics"
+)
+
+func newNATSHistorySink(c *config.NATSHistoryConfig) (provisr.HistorySink, error) {
+	return nats.New(nats.Options{
+		URL:       c.URL,
+		Subject:   c.Subject,
+		QueueSize: c.QueueSize,
+		OnDrop:    func(corehistory.Event) { metrics.IncHistoryEventDropped("nats") },
+	})
+}
@@ -0,0 +1,14 @@
+//go:build !nats
+
+package main
+
+import (
+	"errors"
+
+	"github.com/loykin/provisr"
+	"github.com/loykin/provisr/internal/config"
+)
+
+func newNATSHistorySink(*config.NATSHistoryConfig) (provisr.HistorySink, error) {
+	return nil, errors.New("this provisr binary was built without NATS support; rebuild with -tags nats")
+}
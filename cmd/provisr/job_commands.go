@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/loykin/provisr"
+	apiwire "github.com/loykin/provisr/pkg/api"
+)
+
+// CreateJob creates a job on the daemon, which starts it right away (or once
+// the jobs it depends on have succeeded).
+func (c *command) CreateJob(f JobFlags) error {
+	if f.Name == "" || strings.TrimSpace(f.Command) == "" {
+		return fmt.Errorf("job create requires --name and --command")
+	}
+	spec := provisr.JobSpec{
+		Name:           f.Name,
+		Command:        f.Command,
+		WorkDir:        f.WorkDir,
+		Env:            f.Env,
+		Completions:    &f.Completions,
+		Parallelism:    &f.Parallelism,
+		BackoffLimit:   &f.BackoffLimit,
+		CompletionMode: f.CompletionMode,
+		DependsOn:      f.DependsOn,
+	}
+	if f.ActiveDeadline > 0 {
+		seconds := int64(f.ActiveDeadline.Round(time.Second) / time.Second)
+		spec.ActiveDeadlineSeconds = &seconds
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	if err := apiClient.CreateJob(spec); err != nil {
+		return err
+	}
+	fmt.Printf("Created job %s\n", f.Name)
+	return nil
+}
+
+// Jobs lists the daemon's jobs with their phase and instance counts.
+func (c *command) Jobs(f JobFlags) error {
+	format, err := parseOutputFormat(f.Output, os.Stdout)
+	if err != nil {
+		return err
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	jobs, err := apiClient.Jobs()
+	if err != nil {
+		return err
+	}
+	return writeOutput(os.Stdout, format, jobs, func(w io.Writer, _ outputFormat) error {
+		return writeJobs(w, jobs)
+	})
+}
+
+// jobDetail is what 'job status' prints: the job and each of its instances.
+type jobDetail struct {
+	apiwire.Job
+	Instances []provisr.JobInstanceStatus `json:"instances"`
+}
+
+// JobStatus shows a job of the daemon and every instance it launched.
+func (c *command) JobStatus(f JobFlags) error {
+	if f.Name == "" {
+		return fmt.Errorf("job status requires --name")
+	}
+	format, err := parseOutputFormat(f.Output, os.Stdout)
+	if err != nil {
+		return err
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	job, err := apiClient.GetJob(f.Name)
+	if err != nil {
+		return err
+	}
+	instances, err := apiClient.JobInstances(f.Name)
+	if err != nil {
+		return err
+	}
+	detail := jobDetail{Job: job, Instances: instances}
+	return writeOutput(os.Stdout, format, detail, func(w io.Writer, _ outputFormat) error {
+		return writeJobDetail(w, detail)
+	})
+}
+
+// DeleteJob stops a job's running instances and removes it from the daemon.
+func (c *command) DeleteJob(f JobFlags) error {
+	if f.Name == "" {
+		return fmt.Errorf("job delete requires --name")
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	if err := apiClient.DeleteJob(f.Name); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted job %s\n", f.Name)
+	return nil
+}
+
+// SuspendJob stops a job of the daemon from launching new instances; the
+// ones already running are left to finish.
+func (c *command) SuspendJob(f JobFlags) error {
+	if f.Name == "" {
+		return fmt.Errorf("job suspend requires --name")
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	if err := apiClient.SuspendJob(f.Name); err != nil {
+		return err
+	}
+	fmt.Printf("Suspended job %s\n", f.Name)
+	return nil
+}
+
+// ResumeJob lets a suspended job of the daemon launch instances again.
+func (c *command) ResumeJob(f JobFlags) error {
+	if f.Name == "" {
+		return fmt.Errorf("job resume requires --name")
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	if err := apiClient.ResumeJob(f.Name); err != nil {
+		return err
+	}
+	fmt.Printf("Resumed job %s\n", f.Name)
+	return nil
+}
+
+// writeJobs prints jobs as a table.
+func writeJobs(w io.Writer, jobs []apiwire.Job) error {
+	if len(jobs) == 0 {
+		_, err := fmt.Fprintln(w, "No jobs found")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tPHASE\tCOMPLETIONS\tACTIVE\tFAILED\tSTARTED\tCOMPLETED")
+	for _, job := range jobs {
+		completions := int32(1)
+		if job.Completions != nil {
+			completions = *job.Completions
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%d\t%d\t%s\t%s\n",
+			job.Name, job.Status.Phase, job.Status.Succeeded, completions, job.Status.Active,
+			job.Status.Failed, formatJobTime(job.Status.StartTime), formatJobTime(job.Status.CompletionTime))
+	}
+	return tw.Flush()
+}
+
+// writeJobDetail prints a job followed by a table of its instances.
+func writeJobDetail(w io.Writer, detail jobDetail) error {
+	if err := writeJobs(w, []apiwire.Job{detail.Job}); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w)
+	if len(detail.Instances) == 0 {
+		_, err := fmt.Fprintln(w, "No instances launched")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "INSTANCE\tATTEMPT\tINDEX\tSTATE\tPID\tEXIT CODE\tSTARTED")
+	for _, instance := range detail.Instances {
+		index, pid, exitCode := "-", "-", "-"
+		if instance.Index != nil {
+			index = fmt.Sprint(*instance.Index)
+		}
+		if instance.PID != 0 {
+			pid = fmt.Sprint(instance.PID)
+		}
+		if instance.ExitCode != nil {
+			exitCode = fmt.Sprint(*instance.ExitCode)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			instance.Name, instance.Attempt, index, instance.State, pid, exitCode, formatJobTime(&instance.StartTime))
+	}
+	return tw.Flush()
+}
+
+func formatJobTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr"
+)
+
+func TestCommand_CreateJobViaAPI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	var got provisr.JobSpec
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/status":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/jobs":
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &got); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/jobs/shards":
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"job \"ghost\" not found"}`))
+		}
+	}))
+	defer mockServer.Close()
+	cmd := &command{mgr: &provisr.Manager{}}
+	apiURL := mockServer.URL + "/api"
+
+	err := cmd.CreateJob(JobFlags{
+		Name:           "shards",
+		Command:        "./shard",
+		Completions:    4,
+		Parallelism:    2,
+		BackoffLimit:   0,
+		CompletionMode: "Indexed",
+		ActiveDeadline: 90 * time.Second,
+		DependsOn:      []string{"migrate"},
+		APIUrl:         apiURL,
+		APITimeout:     5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if got.Name != "shards" || got.Command != "./shard" || *got.Completions != 4 || *got.Parallelism != 2 ||
+		*got.BackoffLimit != 0 || got.CompletionMode != "Indexed" || *got.ActiveDeadlineSeconds != 90 ||
+		len(got.DependsOn) != 1 {
+		t.Fatalf("unexpected spec sent: %+v", got)
+	}
+	if err := cmd.CreateJob(JobFlags{Name: "shards", APIUrl: apiURL}); err == nil {
+		t.Fatal("create without --command should fail")
+	}
+
+	if err := cmd.DeleteJob(JobFlags{Name: "shards", APIUrl: apiURL, APITimeout: 5 * time.Second}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := cmd.DeleteJob(JobFlags{Name: "ghost", APIUrl: apiURL, APITimeout: 5 * time.Second}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestJobDetailTable(t *testing.T) {
+	started := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	stamp := started.Format(time.RFC3339)
+	mockServer := createMockAPIServer(map[string]string{
+		"GET:/api/jobs/shards": `{"name":"shards","command":"./shard","completions":3,
+			"status":{"phase":"Running","active":1,"succeeded":1,"failed":1,"start_time":"` + stamp + `"}}`,
+		"GET:/api/jobs/shards/instances": `[
+			{"name":"shards-0","index":0,"attempt":1,"state":"Succeeded","pid":101,"exit_code":0,"start_time":"` + stamp + `"},
+			{"name":"shards-1","index":1,"attempt":2,"state":"Failed","pid":102,"exit_code":3,"start_time":"` + stamp + `"},
+			{"name":"shards-2","index":2,"attempt":3,"state":"Running","pid":103,"start_time":"` + stamp + `"}
+		]`,
+	}, nil)
+	defer mockServer.Close()
+
+	apiClient := NewAPIClient(mockServer.URL+"/api", 5*time.Second)
+	job, err := apiClient.GetJob("shards")
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	instances, err := apiClient.JobInstances("shards")
+	if err != nil {
+		t.Fatalf("JobInstances: %v", err)
+	}
+	var out bytes.Buffer
+	if err := writeJobDetail(&out, jobDetail{Job: job, Instances: instances}); err != nil {
+		t.Fatal(err)
+	}
+	local := started.Local().Format(time.RFC3339)
+	want := "NAME    PHASE    COMPLETIONS  ACTIVE  FAILED  STARTED" + strings.Repeat(" ", len(local)-5) + "COMPLETED\n" +
+		"shards  Running  1/3          1       1       " + local + "  -\n" +
+		"\n" +
+		"INSTANCE  ATTEMPT  INDEX  STATE      PID  EXIT CODE  STARTED\n" +
+		"shards-0  1        0      Succeeded  101  0          " + local + "\n" +
+		"shards-1  2        1      Failed     102  3          " + local + "\n" +
+		"shards-2  3        2      Running    103  -          " + local + "\n"
+	if out.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := writeJobs(&out, nil); err != nil || out.String() != "No jobs found\n" {
+		t.Fatalf("empty list: got %q, %v", out.String(), err)
+	}
+}
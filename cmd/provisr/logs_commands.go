@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/loykin/provisr"
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/core/instancename"
+)
+
+const (
+	// logsPollInterval is how often --follow checks for new output.
+	logsPollInterval = 500 * time.Millisecond
+	// logsPageLimit is the most lines GET /processes/{name}/logs returns.
+	logsPageLimit = 1000
+)
+
+// Logs prints the captured output of process f.Name to out: through the
+// daemon, or from the log files set in the config file with f.Local or when
+// no daemon is reachable and --config is given. With f.Follow it keeps
+// printing new output until ctx is done.
+func (c *command) Logs(ctx context.Context, f LogsFlags, configPath string, out io.Writer) error {
+	if f.Name == "" {
+		return fmt.Errorf("logs requires --name")
+	}
+	p := newLogPrinter(f, out)
+	if !f.Local {
+		apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+		if err == nil {
+			return daemonLogs(ctx, apiClient, f, p)
+		}
+		if f.APIUrl != "" || configPath == "" {
+			return err
+		}
+	}
+	if configPath == "" {
+		return fmt.Errorf("reading log files requires --config")
+	}
+	return localLogs(ctx, f, configPath, p)
+}
+
+// logPrinter filters log lines by stream and age and writes them out,
+// stderr in red when out is a terminal.
+type logPrinter struct {
+	out    io.Writer
+	stream string    // "stdout", "stderr", or "" for every stream
+	after  time.Time // lines captured earlier are skipped; zero keeps all
+	color  bool
+}
+
+func newLogPrinter(f LogsFlags, out io.Writer) *logPrinter {
+	p := &logPrinter{out: out, color: colorEnabled(out)}
+	switch {
+	case f.Stdout && !f.Stderr:
+		p.stream = "stdout"
+	case f.Stderr && !f.Stdout:
+		p.stream = "stderr"
+	}
+	if f.Since > 0 {
+		p.after = time.Now().Add(-f.Since)
+	}
+	return p
+}
+
+func (p *logPrinter) keep(l provisr.LogLine) bool {
+	if p.stream != "" && l.Stream != p.stream {
+		return false
+	}
+	return p.after.IsZero() || !l.Time.Before(p.after)
+}
+
+func (p *logPrinter) print(lines []provisr.LogLine) error {
+	for _, l := range lines {
+		if !p.keep(l) {
+			continue
+		}
+		var err error
+		if p.color && l.Stream == "stderr" {
+			_, err = fmt.Fprintf(p.out, "\033[31m%s\033[0m\n", l.Text)
+		} else {
+			_, err = fmt.Fprintln(p.out, l.Text)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printTail prints the last tail lines that pass the filters, or all of
+// them when tail is negative.
+func (p *logPrinter) printTail(lines []provisr.LogLine, tail int) error {
+	kept := make([]provisr.LogLine, 0, len(lines))
+	for _, l := range lines {
+		if p.keep(l) {
+			kept = append(kept, l)
+		}
+	}
+	if tail >= 0 && len(kept) > tail {
+		kept = kept[len(kept)-tail:]
+	}
+	return p.print(kept)
+}
+
+// colorEnabled reports whether out is a terminal that should get ANSI
+// colors; NO_COLOR and TERM=dumb turn them off.
+func colorEnabled(out io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(out)
+}
+
+// daemonLogs prints the output the daemon keeps for f.Name. The daemon only
+// buffers the most recent lines, so --tail and --since can't reach further
+// back than that.
+func daemonLogs(ctx context.Context, apiClient *APIClient, f LogsFlags, p *logPrinter) error {
+	var lines []provisr.LogLine
+	var since uint64
+	for {
+		page, err := apiClient.Logs(f.Name, since, logsPageLimit)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, page.Lines...)
+		since = page.Next
+		if len(page.Lines) < logsPageLimit {
+			break
+		}
+	}
+	if err := p.printTail(lines, f.Tail); err != nil || !f.Follow {
+		return err
+	}
+
+	ticker := time.NewTicker(logsPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		page, err := apiClient.Logs(f.Name, since, logsPageLimit)
+		if err != nil {
+			return err
+		}
+		if page.Next < since {
+			// The process was re-registered and its buffer started over.
+			since = 0
+			continue
+		}
+		if err := p.print(page.Lines); err != nil {
+			return err
+		}
+		since = page.Next
+	}
+}
+
+// localLogs prints f.Name's output from the log files the config file at
+// configPath sets for it.
+func localLogs(ctx context.Context, f LogsFlags, configPath string, p *logPrinter) error {
+	cfg, err := provisr.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	naming, err := cfg.InstanceNaming.Scheme()
+	if err != nil {
+		return err
+	}
+	files, err := processLogFiles(cfg.Specs, naming, f.Name, p.stream)
+	if err != nil {
+		return err
+	}
+	if f.Since > 0 {
+		for _, lf := range files {
+			if lf.format != core.LogOutputFormatJSON && lf.format != core.LogOutputFormatPrefixed {
+				return fmt.Errorf("--since needs log timestamps: set output_format = \"json\" or \"prefixed\" in the [log] settings of %s", f.Name)
+			}
+		}
+	}
+
+	var lines []provisr.LogLine
+	for _, lf := range files {
+		read, err := lf.readNew()
+		if err != nil {
+			return err
+		}
+		lines = append(lines, read...)
+	}
+	// Raw files carry no timestamps; their streams are printed one after
+	// the other.
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Time.Before(lines[j].Time) })
+	if err := p.printTail(lines, f.Tail); err != nil || !f.Follow {
+		return err
+	}
+
+	ticker := time.NewTicker(logsPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		lines = lines[:0]
+		for _, lf := range files {
+			read, err := lf.readNew()
+			if err != nil {
+				return err
+			}
+			lines = append(lines, read...)
+		}
+		sort.SliceStable(lines, func(i, j int) bool { return lines[i].Time.Before(lines[j].Time) })
+		if err := p.print(lines); err != nil {
+			return err
+		}
+	}
+}
+
+// processLogFiles returns the log files of process name in specs, limited
+// to stream unless it's "". Instances of a multi-instance process log to
+// files of their own, so name must be an instance name such as web-1, as
+// named by naming.
+func processLogFiles(specs []provisr.Spec, naming *instancename.Scheme, name, stream string) ([]*logFile, error) {
+	for _, spec := range specs {
+		if spec.Instances > 1 {
+			if name == spec.Name {
+				return nil, fmt.Errorf("process %s runs %d instances; choose one, e.g. --name=%s", name, spec.Instances, naming.Name(name, 0))
+			}
+			if naming.Ordinal(name, spec.Name, spec.Instances) < 0 {
+				continue
+			}
+		} else if name != spec.Name {
+			continue
+		}
+
+		stdoutPath, stderrPath := spec.Log.FilePaths(name)
+		format := spec.Log.File.OutputFormat
+		var files []*logFile
+		if stdoutPath != "" && stream != "stderr" {
+			files = append(files, &logFile{path: stdoutPath, stream: "stdout", format: format})
+		}
+		// Both streams may share one file; it's read once.
+		if stderrPath != "" && stream != "stdout" && (stderrPath != stdoutPath || stream == "stderr") {
+			files = append(files, &logFile{path: stderrPath, stream: "stderr", format: format})
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("process %s does not log %s to a file", name, streamLabel(stream))
+		}
+		return files, nil
+	}
+	return nil, fmt.Errorf("process %s not found in the config file", name)
+}
+
+func streamLabel(stream string) string {
+	if stream == "" {
+		return "its output"
+	}
+	return stream
+}
+
+// logFile reads the lines appended to one process log file since the last
+// read.
+type logFile struct {
+	path    string
+	stream  string
+	format  core.LogOutputFormat // output_format the records are written with
+	offset  int64
+	partial []byte // unterminated last line, completed by the next read
+}
+
+// jsonLogRecord is the part of a json output_format record logs needs.
+type jsonLogRecord struct {
+	TS     time.Time `json:"ts"`
+	Stream string    `json:"stream"`
+	Line   string    `json:"line"`
+}
+
+// readNew returns the complete lines written since the previous call. A
+// missing file has no lines yet; a file shorter than what was already read
+// was rotated and is read from the start.
+func (lf *logFile) readNew() ([]provisr.LogLine, error) {
+	file, err := os.Open(lf.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < lf.offset {
+		lf.offset, lf.partial = 0, nil
+	}
+	if _, err := file.Seek(lf.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var lines []provisr.LogLine
+	reader := bufio.NewReader(file)
+	for {
+		chunk, err := reader.ReadBytes('\n')
+		lf.offset += int64(len(chunk))
+		if err == io.EOF {
+			lf.partial = append(lf.partial, chunk...)
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+		text := append(lf.partial, bytes.TrimRight(chunk, "\r\n")...)
+		lf.partial = nil
+		lines = append(lines, lf.parse(text))
+	}
+}
+
+func (lf *logFile) parse(text []byte) provisr.LogLine {
+	line := provisr.LogLine{Stream: lf.stream, Text: string(text)}
+	switch lf.format {
+	case core.LogOutputFormatJSON:
+		var rec jsonLogRecord
+		if json.Unmarshal(text, &rec) == nil {
+			line.Text, line.Time = rec.Line, rec.TS
+			if rec.Stream != "" {
+				line.Stream = rec.Stream
+			}
+		}
+	case core.LogOutputFormatPrefixed:
+		// "[<time> <stream>] text"
+		prefix, rest, ok := strings.Cut(strings.TrimPrefix(line.Text, "["), "] ")
+		ts, stream, _ := strings.Cut(prefix, " ")
+		if t, err := time.Parse(core.LogPrefixTimeLayout, ts); ok && err == nil && strings.HasPrefix(line.Text, "[") {
+			line.Text, line.Time, line.Stream = rest, t, stream
+		}
+	}
+	return line
+}
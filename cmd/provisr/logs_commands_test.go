@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr"
+	"github.com/loykin/provisr/core"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while a follow loop writes it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestCommand_LogsViaAPI(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Now().UTC()
+	old := now.Add(-time.Hour).Format(time.RFC3339Nano)
+	recent := now.Format(time.RFC3339Nano)
+	page := fmt.Sprintf(`{"lines":[
+		{"offset":0,"stream":"stdout","text":"booting","time":%q},
+		{"offset":1,"stream":"stderr","text":"warn: slow disk","time":%q},
+		{"offset":2,"stream":"stdout","text":"ready","time":%q},
+		{"offset":3,"stream":"stderr","text":"error: timeout","time":%q}
+	],"next":4}`, old, old, recent, recent)
+	mockServer := createMockAPIServer(map[string]string{
+		"GET:/api/processes/web/logs?since=0&limit=1000": page,
+	}, nil)
+	defer mockServer.Close()
+	cmd := &command{mgr: &provisr.Manager{}}
+	base := LogsFlags{Name: "web", Tail: -1, APIUrl: mockServer.URL + "/api", APITimeout: 5 * time.Second}
+
+	tests := []struct {
+		name  string
+		flags func(f *LogsFlags)
+		want  string
+	}{
+		{"all", func(f *LogsFlags) {}, "booting\nwarn: slow disk\nready\nerror: timeout\n"},
+		{"tail", func(f *LogsFlags) { f.Tail = 1 }, "error: timeout\n"},
+		{"stderr", func(f *LogsFlags) { f.Stderr = true }, "warn: slow disk\nerror: timeout\n"},
+		{"since", func(f *LogsFlags) { f.Stdout = true; f.Since = 10 * time.Minute }, "ready\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := base
+			tt.flags(&f)
+			var out bytes.Buffer
+			if err := cmd.Logs(context.Background(), f, "", &out); err != nil {
+				t.Fatalf("Logs: %v", err)
+			}
+			if out.String() != tt.want {
+				t.Fatalf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCommand_LogsFromLocalFiles(t *testing.T) {
+	dir := t.TempDir()
+	logDir := filepath.Join(dir, "logs")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.toml")
+	configData := fmt.Sprintf(`
+[log]
+dir = %q
+output_format = "json"
+
+[[processes]]
+type = "process"
+[processes.spec]
+name = "web"
+command = "sleep 1"
+
+[[processes]]
+type = "process"
+[processes.spec]
+name = "worker"
+command = "sleep 1"
+instances = 2
+`, logDir)
+	if err := os.WriteFile(configPath, []byte(configData), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	record := func(at time.Time, stream, line string) string {
+		return fmt.Sprintf(`{"ts":%q,"stream":%q,"process":"web","line":%q}`+"\n", at.UTC().Format(time.RFC3339Nano), stream, line)
+	}
+	now := time.Now()
+	stdoutPath := filepath.Join(logDir, "web.stdout.log")
+	stdout := record(now.Add(-time.Hour), "stdout", "booting") + record(now.Add(-time.Second), "stdout", "ready")
+	if err := os.WriteFile(stdoutPath, []byte(stdout), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stderr := record(now.Add(-2*time.Second), "stderr", "warn: slow disk")
+	if err := os.WriteFile(filepath.Join(logDir, "web.stderr.log"), []byte(stderr), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &command{mgr: &provisr.Manager{}}
+
+	var out bytes.Buffer
+	if err := cmd.Logs(context.Background(), LogsFlags{Name: "web", Tail: 2, Local: true}, configPath, &out); err != nil {
+		t.Fatalf("Logs: %v", err)
+	}
+	if want := "warn: slow disk\nready\n"; out.String() != want {
+		t.Fatalf("got %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := cmd.Logs(context.Background(), LogsFlags{Name: "web", Tail: -1, Since: time.Minute, Stdout: true, Local: true}, configPath, &out); err != nil {
+		t.Fatalf("Logs --since: %v", err)
+	}
+	if want := "ready\n"; out.String() != want {
+		t.Fatalf("got %q, want %q", out.String(), want)
+	}
+
+	err := cmd.Logs(context.Background(), LogsFlags{Name: "worker", Local: true}, configPath, &out)
+	if err == nil || !strings.Contains(err.Error(), "--name=worker-1") {
+		t.Fatalf("expected an instance hint, got %v", err)
+	}
+
+	// --follow prints lines appended after the initial tail, including one
+	// written in two parts.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var followed syncBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Logs(ctx, LogsFlags{Name: "web", Tail: 0, Follow: true, Local: true}, configPath, &followed)
+	}()
+	time.Sleep(2 * logsPollInterval)
+	file, err := os.OpenFile(stdoutPath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+	next := record(time.Now(), "stdout", "serving")
+	if _, err := file.WriteString(next[:10]); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * logsPollInterval)
+	if _, err := file.WriteString(next[10:]); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for followed.String() != "serving\n" && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Logs --follow: %v", err)
+	}
+	if got := followed.String(); got != "serving\n" {
+		t.Fatalf("followed %q, want %q", got, "serving\n")
+	}
+}
+
+func TestLogFileParsesPrefixedLines(t *testing.T) {
+	lf := &logFile{stream: "stdout", format: core.LogOutputFormatPrefixed}
+	line := lf.parse([]byte("[2026-01-02T03:04:05.678Z stderr] disk full"))
+	want := time.Date(2026, 1, 2, 3, 4, 5, 678e6, time.UTC)
+	if line.Stream != "stderr" || line.Text != "disk full" || !line.Time.Equal(want) {
+		t.Fatalf("parsed %+v", line)
+	}
+	// A line written before the format was switched stays as it is.
+	if line := lf.parse([]byte("[not a prefix] text")); line.Stream != "stdout" || line.Text != "[not a prefix] text" {
+		t.Fatalf("parsed unprefixed line as %+v", line)
+	}
+}
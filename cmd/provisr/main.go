@@ -2,19 +2,27 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
 	"time"
+	_ "time/tzdata" // cron time_zone names resolve on hosts without a zoneinfo database
 
 	"github.com/loykin/provisr"
+	"github.com/loykin/provisr/internal/config"
 	historyruntime "github.com/loykin/provisr/internal/history"
 	"github.com/loykin/provisr/internal/history/clickhouse"
 	"github.com/loykin/provisr/internal/history/opensearch"
+	"github.com/loykin/provisr/internal/history/webhook"
+	"github.com/loykin/provisr/pkg/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +33,10 @@ func main() {
 	bind()
 
 	if err := root.Execute(); err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		_, _ = fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -32,7 +44,9 @@ func main() {
 
 // GlobalFlags holds minimal global/persistent flags for CLI commands
 type GlobalFlags struct {
-	ConfigPath string // Only config path for CLI commands
+	ConfigPath string
+	Context    string // saved login context to target; empty means $PROVISR_CONTEXT or the current one
+	Output     string // table, json, yaml or text; empty picks table on a terminal and json otherwise
 }
 
 // ProcessFlags holds process-related flags
@@ -45,6 +59,10 @@ type ProcessFlags struct {
 	RestartInterval time.Duration
 	StartDuration   time.Duration
 	Instances       int
+	WaitFor         string // status --wait-for
+	WaitTimeout     time.Duration
+	Labels          []string      // status --label
+	Watch           time.Duration // status --watch
 	// API connection
 	APIUrl     string
 	APITimeout time.Duration
@@ -52,13 +70,21 @@ type ProcessFlags struct {
 
 // RegisterFlags holds flags for register command
 type RegisterFlags struct {
-	Name       string
-	Command    string
-	WorkDir    string
-	LogDir     string
-	AutoStart  bool
-	APIUrl     string
-	APITimeout time.Duration
+	Name      string
+	Command   string
+	WorkDir   string
+	LogDir    string
+	AutoStart bool
+	// Optional spec fields; zero values are left out of the program file
+	Env             []string // KEY=VALUE
+	Labels          []string // key=value
+	Instances       int
+	Retries         uint32
+	RetryInterval   time.Duration
+	StartDuration   time.Duration
+	RestartInterval time.Duration
+	APIUrl          string
+	APITimeout      time.Duration
 }
 
 // RegisterFileFlags holds flags for register-file command
@@ -89,11 +115,22 @@ func buildRoot(mgr *provisr.Manager) (*cobra.Command, func()) {
 	registerFlags := &RegisterFlags{}
 	registerFileFlags := &RegisterFileFlags{}
 	unregisterFlags := &UnregisterFlags{}
+	editFlags := &EditFlags{}
 	groupFlags := &GroupCommandFlags{}
 	cronFlags := &CronFlags{}
 	templateFlags := &TemplateCreateFlags{}
-
-	provisrCommand := command{mgr: mgr}
+	exportFlags := &ExportFlags{}
+	importFlags := &ImportFlags{}
+	logsFlags := &LogsFlags{}
+	restartFlags := &RestartFlags{}
+	signalFlags := &SignalFlags{}
+	scaleFlags := &ScaleFlags{}
+	rollingRestartFlags := &RollingRestartFlags{}
+	eventsFlags := &EventsFlags{}
+	topFlags := &TopFlags{}
+	versionFlags := &VersionFlags{}
+
+	provisrCommand := command{mgr: mgr, sessionContext: &globalFlags.Context}
 
 	root := createRootCommand(globalFlags)
 
@@ -102,18 +139,33 @@ func buildRoot(mgr *provisr.Manager) (*cobra.Command, func()) {
 		createRegisterCommand(provisrCommand, registerFlags, globalFlags),
 		createRegisterFileCommand(provisrCommand, registerFileFlags, globalFlags),
 		createUnregisterCommand(provisrCommand, unregisterFlags, globalFlags),
+		createEditCommand(provisrCommand, editFlags, globalFlags),
 		createStartCommand(provisrCommand, processFlags),
-		createStatusCommand(provisrCommand, processFlags),
+		createStatusCommand(provisrCommand, processFlags, globalFlags),
 		createStopCommand(provisrCommand, processFlags),
-		createCronCommand(provisrCommand, cronFlags),
+		createRestartCommand(provisrCommand, restartFlags),
+		createSignalCommand(provisrCommand, signalFlags),
+		createScaleCommand(provisrCommand, scaleFlags),
+		createLogsCommand(provisrCommand, logsFlags, globalFlags),
+		createEventsCommand(provisrCommand, eventsFlags),
+		createTopCommand(provisrCommand, topFlags),
+		createVersionCommand(provisrCommand, versionFlags, globalFlags),
+		createCronCommand(provisrCommand, cronFlags, globalFlags),
+		createJobCommand(provisrCommand, globalFlags),
 		createGroupStartCommand(provisrCommand, groupFlags),
 		createGroupStopCommand(provisrCommand, groupFlags),
-		createGroupStatusCommand(provisrCommand, groupFlags),
+		createRollingRestartCommand(provisrCommand, rollingRestartFlags),
+		createGroupStatusCommand(provisrCommand, groupFlags, globalFlags),
 		createAuthCommand(provisrCommand, globalFlags),
+		createAuditCommand(provisrCommand, globalFlags),
 		createLoginCommand(provisrCommand),
 		createLogoutCommand(provisrCommand),
+		createContextCommand(provisrCommand, globalFlags),
 		createServeCommand(globalFlags),
 		createTemplateCommand(provisrCommand, templateFlags),
+		createExportCommand(provisrCommand, exportFlags),
+		createImportCommand(provisrCommand, importFlags),
+		createCompletionCommand(),
 	)
 
 	return root, func() {
@@ -139,6 +191,8 @@ Examples:
 
 	// Only essential flags for CLI commands
 	root.PersistentFlags().StringVar(&flags.ConfigPath, "config", "", "path to TOML config file (optional)")
+	root.PersistentFlags().StringVarP(&flags.Output, "output", "o", "", "output format of status, group-status, cron, job list, job status, auth user list and context list: table, json, yaml or text (default table on a terminal, json otherwise)")
+	root.PersistentFlags().StringVar(&flags.Context, "context", "", "saved login context to use (default: $PROVISR_CONTEXT or the current context)")
 
 	return root
 }
@@ -153,17 +207,11 @@ This allows the process to be managed by the provisr daemon.
 
 Examples:
   provisr register --name=web --command="python app.py" --work-dir=/app
-  provisr register --name=api --command="./api-server" --log-dir=/var/log/api --auto-start`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return provisrCommand.Register(RegisterFlags{
-				Name:       registerFlags.Name,
-				Command:    registerFlags.Command,
-				WorkDir:    registerFlags.WorkDir,
-				LogDir:     registerFlags.LogDir,
-				AutoStart:  registerFlags.AutoStart,
-				APIUrl:     registerFlags.APIUrl,
-				APITimeout: registerFlags.APITimeout,
-			}, globalFlags.ConfigPath)
+  provisr register --name=api --command="./api-server" --log-dir=/var/log/api --auto-start
+  provisr register --name=worker --command="./worker" --instances=3 --env=QUEUE=jobs --env=LOG_LEVEL=debug
+  provisr register --name=flaky --command="./flaky" --retries=5 --retry-interval=2s --start-duration=3s`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.Register(*registerFlags, globalFlags.ConfigPath)
 		},
 	}
 
@@ -173,9 +221,16 @@ Examples:
 	cmd.Flags().StringVar(&registerFlags.WorkDir, "work-dir", "", "working directory")
 	cmd.Flags().StringVar(&registerFlags.LogDir, "log-dir", "", "log directory")
 	cmd.Flags().BoolVar(&registerFlags.AutoStart, "auto-start", false, "auto-start process when daemon starts")
+	cmd.Flags().StringArrayVar(&registerFlags.Env, "env", nil, "environment variable KEY=VALUE (repeatable)")
+	cmd.Flags().StringArrayVar(&registerFlags.Labels, "label", nil, "label key=value, e.g. team=payments (repeatable)")
+	cmd.Flags().IntVar(&registerFlags.Instances, "instances", 0, "number of instances to run (default 1)")
+	cmd.Flags().Uint32Var(&registerFlags.Retries, "retries", 0, "number of retries on start failure")
+	cmd.Flags().DurationVar(&registerFlags.RetryInterval, "retry-interval", 0, "wait between start retries")
+	cmd.Flags().DurationVar(&registerFlags.StartDuration, "start-duration", 0, "time the process must stay up to count as started")
+	cmd.Flags().DurationVar(&registerFlags.RestartInterval, "restart-interval", 0, "wait before an auto-restart")
 
 	// Remote daemon connection
-	cmd.Flags().StringVar(&registerFlags.APIUrl, "api-url", "", "remote daemon URL (e.g. http://host:8080/api)")
+	cmd.Flags().StringVar(&registerFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
 	cmd.Flags().DurationVar(&registerFlags.APITimeout, "api-timeout", 10*time.Second, "request timeout")
 
 	// Mark required flags
@@ -193,12 +248,14 @@ Examples:
 func createRegisterFileCommand(provisrCommand command, registerFileFlags *RegisterFileFlags, globalFlags *GlobalFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "register-file",
-		Short: "Register a process from JSON file",
-		Long: `Register a process by copying an existing JSON file to the programs directory.
-The JSON file must contain valid process configuration.
+		Short: "Register a process from a JSON, YAML or TOML file",
+		Long: `Register a process by copying an existing file to the programs directory.
+The file must contain valid process configuration. Files ending in .yaml, .yml
+or .toml are read and written in that format; any other file is JSON.
 
 Examples:
   provisr register-file --file=./my-process.json
+  provisr register-file --file=./worker.yaml
   provisr register-file --file=./web-server.json --api-url=http://remote:8080/api
 
 JSON file format example:
@@ -223,10 +280,10 @@ JSON file format example:
 	}
 
 	// Add flags specific to register-file command
-	cmd.Flags().StringVar(&registerFileFlags.FilePath, "file", "", "path to JSON file (required)")
+	cmd.Flags().StringVar(&registerFileFlags.FilePath, "file", "", "path to a JSON, YAML or TOML process file (required)")
 
 	// Remote daemon connection
-	cmd.Flags().StringVar(&registerFileFlags.APIUrl, "api-url", "", "remote daemon URL (e.g. http://host:8080/api)")
+	cmd.Flags().StringVar(&registerFileFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
 	cmd.Flags().DurationVar(&registerFileFlags.APITimeout, "api-timeout", 10*time.Second, "request timeout")
 
 	// Mark required flags
@@ -260,9 +317,10 @@ Examples:
 
 	// Add flags specific to unregister command
 	cmd.Flags().StringVar(&unregisterFlags.Name, "name", "", "process name (required)")
+	mustCompleteFlag(cmd, "name", provisrCommand.completeProcessNames)
 
 	// Remote daemon connection
-	cmd.Flags().StringVar(&unregisterFlags.APIUrl, "api-url", "", "remote daemon URL (e.g. http://host:8080/api)")
+	cmd.Flags().StringVar(&unregisterFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
 	cmd.Flags().DurationVar(&unregisterFlags.APITimeout, "api-timeout", 10*time.Second, "request timeout")
 
 	// Mark required flags
@@ -273,6 +331,39 @@ Examples:
 	return cmd
 }
 
+// createEditCommand creates the edit subcommand
+func createEditCommand(provisrCommand command, editFlags *EditFlags, globalFlags *GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit a registered process in $EDITOR",
+		Long: `Open the spec of a registered process in $EDITOR as JSON. When the file
+is saved, the spec is validated and the process is updated and restarted by
+the daemon. Without a running daemon the program file is rewritten and used
+the next time the daemon starts. The name cannot be changed, and processes
+defined in config.toml cannot be edited.
+
+With --api-url the spec is fetched from and sent back to that daemon.
+
+Examples:
+  provisr edit --name=web
+  EDITOR=nano provisr edit --name=api --api-url=http://remote:8080/api`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.Edit(*editFlags, globalFlags.ConfigPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&editFlags.Name, "name", "", "process name (required)")
+	mustCompleteFlag(cmd, "name", provisrCommand.completeProcessNames)
+	cmd.Flags().StringVar(&editFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&editFlags.APITimeout, "api-timeout", 10*time.Second, "request timeout")
+
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
 // createStartCommand creates the start subcommand
 func createStartCommand(provisrCommand command, processFlags *ProcessFlags) *cobra.Command {
 	cmd := &cobra.Command{
@@ -295,9 +386,10 @@ Examples:
 
 	// Add flags specific to start command
 	cmd.Flags().StringVar(&processFlags.Name, "name", "", "process name (required)")
+	mustCompleteFlag(cmd, "name", provisrCommand.completeProcessNames)
 
 	// Remote daemon connection
-	cmd.Flags().StringVar(&processFlags.APIUrl, "api-url", "", "remote daemon URL (e.g. http://host:8080/api)")
+	cmd.Flags().StringVar(&processFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
 	cmd.Flags().DurationVar(&processFlags.APITimeout, "api-timeout", 10*time.Second, "request timeout")
 
 	// Mark required flags
@@ -309,7 +401,7 @@ Examples:
 }
 
 // createStatusCommand creates the status subcommand
-func createStatusCommand(provisrCommand command, processFlags *ProcessFlags) *cobra.Command {
+func createStatusCommand(provisrCommand command, processFlags *ProcessFlags, globalFlags *GlobalFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show process status",
@@ -318,20 +410,66 @@ func createStatusCommand(provisrCommand command, processFlags *ProcessFlags) *co
 Examples:
   provisr status                    # Show all processes
   provisr status --name=web         # Show specific process
-  provisr status --api-url=http://remote:8080/api  # Remote status`,
+  provisr status --running          # Leave out stopped processes
+  provisr status --label=team=payments  # Processes with that label
+  provisr status -o yaml            # YAML instead of a table
+  provisr status --api-url=http://remote:8080/api  # Remote status
+
+With --fail-if-stopped or --wait-for the exit status reports the state of
+--name: 0 running, 3 stopped, 4 not found. Otherwise it is 0 whenever the
+query succeeds.
+
+  provisr status --name=web --fail-if-stopped
+  provisr status --name=web --wait-for=running --wait-timeout=1m
+
+--watch redraws the status every 2s, or every --watch=5s, until Ctrl-C. On a
+terminal a table longer than the screen is cut off; narrow it with
+--running, --label or --name.
+
+  provisr status --watch --running`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return provisrCommand.Status(StatusFlags{
-				Name:       processFlags.Name,
-				APIUrl:     processFlags.APIUrl,
-				APITimeout: processFlags.APITimeout,
-				Detailed:   cmd.Flag("detailed").Changed,
-			})
+			flags := StatusFlags{
+				Name:          processFlags.Name,
+				APIUrl:        processFlags.APIUrl,
+				APITimeout:    processFlags.APITimeout,
+				Detailed:      cmd.Flag("detailed").Changed,
+				Running:       cmd.Flag("running").Changed,
+				Labels:        processFlags.Labels,
+				Output:        globalFlags.Output,
+				FailIfStopped: cmd.Flag("fail-if-stopped").Changed,
+				WaitFor:       processFlags.WaitFor,
+				WaitTimeout:   processFlags.WaitTimeout,
+				Watch:         processFlags.Watch,
+			}
+			if cmd.Flag("watch").Changed {
+				if flags.Watch <= 0 {
+					return fmt.Errorf("--watch interval must be positive")
+				}
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				return provisrCommand.WatchStatus(ctx, flags, os.Stdout)
+			}
+			err := provisrCommand.Status(flags)
+			var exitErr *exitError
+			if errors.As(err, &exitErr) {
+				cmd.SilenceUsage = true
+				cmd.SilenceErrors = exitErr.msg == ""
+			}
+			return err
 		},
 	}
 	cmd.Flags().StringVar(&processFlags.Name, "name", "", "process name (optional)")
-	cmd.Flags().StringVar(&processFlags.APIUrl, "api-url", "", "remote daemon URL (e.g. http://host:8080/api)")
+	mustCompleteFlag(cmd, "name", provisrCommand.completeProcessNames)
+	cmd.Flags().StringVar(&processFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
 	cmd.Flags().DurationVar(&processFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
 	cmd.Flags().Bool("detailed", false, "show detailed info")
+	cmd.Flags().Bool("running", false, "list only running processes")
+	cmd.Flags().StringArrayVar(&processFlags.Labels, "label", nil, "list only processes with this key=value label (repeatable)")
+	cmd.Flags().Bool("fail-if-stopped", false, "exit 3 if --name is not running, 4 if it does not exist")
+	cmd.Flags().StringVar(&processFlags.WaitFor, "wait-for", "", "wait until --name is running or stopped, then exit as --fail-if-stopped")
+	cmd.Flags().DurationVar(&processFlags.WaitTimeout, "wait-timeout", 30*time.Second, "how long --wait-for waits")
+	cmd.Flags().DurationVar(&processFlags.Watch, "watch", 0, "redraw the status on this interval until interrupted (--watch alone: 2s)")
+	cmd.Flags().Lookup("watch").NoOptDefVal = "2s"
 	return cmd
 }
 
@@ -362,8 +500,9 @@ Examples:
 		},
 	}
 	cmd.Flags().StringVar(&processFlags.Name, "name", "", "process name (required)")
+	mustCompleteFlag(cmd, "name", provisrCommand.completeProcessNames)
 	cmd.Flags().Duration("wait", 3*time.Second, "time to wait for graceful shutdown")
-	cmd.Flags().StringVar(&processFlags.APIUrl, "api-url", "", "remote daemon URL (e.g. http://host:8080/api)")
+	cmd.Flags().StringVar(&processFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
 	cmd.Flags().DurationVar(&processFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
 
 	// Mark required flags
@@ -373,30 +512,294 @@ Examples:
 	return cmd
 }
 
+// createRestartCommand creates the restart subcommand
+func createRestartCommand(provisrCommand command, restartFlags *RestartFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restart",
+		Short: "Restart a process or group",
+		Long: `Stop a process, or every member of a group, and start it again through
+the daemon, then print the new PIDs. Returns once the processes are running.
+A process that wasn't running is just started.
+
+Examples:
+  provisr restart --name=web             # Restart specific process
+  provisr restart --name=web --wait=5s   # Allow 5s for graceful shutdown
+  provisr restart --group=backend        # Restart every group member`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.Restart(*restartFlags)
+		},
+	}
+	cmd.Flags().StringVar(&restartFlags.Name, "name", "", "process name")
+	mustCompleteFlag(cmd, "name", provisrCommand.completeProcessNames)
+	cmd.Flags().StringVar(&restartFlags.Group, "group", "", "group name")
+	mustCompleteFlag(cmd, "group", provisrCommand.completeGroupNames)
+	cmd.Flags().DurationVar(&restartFlags.Wait, "wait", 3*time.Second, "time to wait for graceful shutdown")
+	cmd.Flags().StringVar(&restartFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&restartFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
+	cmd.MarkFlagsOneRequired("name", "group")
+	cmd.MarkFlagsMutuallyExclusive("name", "group")
+	return cmd
+}
+
+// createScaleCommand creates the scale subcommand
+func createScaleCommand(provisrCommand command, scaleFlags *ScaleFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scale",
+		Short: "Change the number of instances of a process",
+		Long: `Change how many instances of a process run through the daemon. Scaling
+up starts the new instances (worker-3, worker-4, ...) and scaling down stops
+the highest-numbered ones; the others keep running. The new count is saved
+to the process's program file.
+
+Examples:
+  provisr scale --name=worker --instances=5
+  provisr scale --name=worker --instances=1`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.Scale(*scaleFlags)
+		},
+	}
+	cmd.Flags().StringVar(&scaleFlags.Name, "name", "", "process base name")
+	mustCompleteFlag(cmd, "name", provisrCommand.completeProcessNames)
+	cmd.Flags().IntVar(&scaleFlags.Instances, "instances", 0, "desired number of instances")
+	cmd.Flags().StringVar(&scaleFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&scaleFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("instances")
+	return cmd
+}
+
+// createSignalCommand creates the signal subcommand
+func createSignalCommand(provisrCommand command, signalFlags *SignalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "signal",
+		Short: "Send a signal to a running process",
+		Long: `Send a signal to a running process through the daemon without stopping
+it, e.g. to make it reload its configuration or reopen its log files. The
+signal goes to the process itself, not to the rest of its process group.
+
+Examples:
+  provisr signal --name=web --signal=HUP     # Reload configuration
+  provisr signal --name=web --signal=USR1    # Names may omit the SIG prefix
+  provisr signal --name=web --signal=10      # Or be given as numbers`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.Signal(*signalFlags)
+		},
+	}
+	cmd.Flags().StringVar(&signalFlags.Name, "name", "", "process name")
+	mustCompleteFlag(cmd, "name", provisrCommand.completeProcessNames)
+	cmd.Flags().StringVar(&signalFlags.Signal, "signal", "", "signal name or number (e.g. HUP, SIGUSR1)")
+	cmd.Flags().StringVar(&signalFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&signalFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("signal")
+	return cmd
+}
+
 // createCronCommand creates the cron subcommand
-func createCronCommand(provisrCommand command, cronFlags *CronFlags) *cobra.Command {
+func createCronCommand(provisrCommand command, cronFlags *CronFlags, globalFlags *GlobalFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "cron",
-		Short: "Control scheduled jobs via daemon (REST)",
+		Short: "List scheduled jobs of the daemon",
 		Long: `Cron jobs are executed by the provisr daemon started with 'serve'.
-This command communicates with the running daemon via REST to verify readiness.
+This command lists them via REST with their schedule, whether they are
+suspended, running jobs and last and next run times.
 
 Examples:
-  provisr cron                 # Verify daemon is running and has loaded cron jobs
+  provisr cron                 # List the daemon's cron jobs
+  provisr cron -o json
   provisr cron --api-url=http://remote:8080/api`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return provisrCommand.Cron(CronFlags{
 				APIUrl:      cronFlags.APIUrl,
 				APITimeout:  cronFlags.APITimeout,
+				Output:      globalFlags.Output,
 				NonBlocking: true, // CLI should not block; daemon runs scheduler
 			})
 		},
 	}
-	cmd.Flags().StringVar(&cronFlags.APIUrl, "api-url", "", "remote daemon URL (e.g. http://host:8080/api)")
+	cmd.Flags().StringVar(&cronFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
 	cmd.Flags().DurationVar(&cronFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
 	return cmd
 }
 
+// createJobCommand creates the job command with subcommands
+func createJobCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "job",
+		Short: "Manage the daemon's jobs",
+		Long: `Manage one-off jobs run by the provisr daemon started with 'serve'. A job
+runs its command until it has succeeded --completions times, retrying
+failed instances up to --backoff-limit times.
+
+Examples:
+  provisr job create --name=migrate --command="./migrate up"
+  provisr job create --name=batch --command="./work" --completions=10 --parallelism=3
+  provisr job list
+  provisr job status --name=batch      # The job and each instance it launched
+  provisr job suspend --name=batch     # Launch no new instances
+  provisr job resume --name=batch
+  provisr job delete --name=batch`,
+	}
+
+	cmd.AddCommand(
+		createJobCreateCommand(provisrCommand),
+		createJobListCommand(provisrCommand, globalFlags),
+		createJobStatusCommand(provisrCommand, globalFlags),
+		createJobDeleteCommand(provisrCommand),
+		createJobSuspendCommand(provisrCommand),
+		createJobResumeCommand(provisrCommand),
+	)
+
+	return cmd
+}
+
+// createJobCreateCommand creates the job create subcommand
+func createJobCreateCommand(provisrCommand command) *cobra.Command {
+	flags := &JobFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create and start a job",
+		Long: `Create a job on the daemon. It starts right away, or once every job
+listed in --depends-on has succeeded.
+
+Examples:
+  provisr job create --name=migrate --command="./migrate up" --work-dir=/app
+  provisr job create --name=shards --command="./shard" --completions=4 --parallelism=4 --completion-mode=Indexed
+  provisr job create --name=report --command="./report" --depends-on=migrate --active-deadline=10m`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.CreateJob(*flags)
+		},
+	}
+	addJobFlags(cmd, flags)
+	cmd.Flags().StringVar(&flags.Command, "command", "", "command to run (shell string)")
+	cmd.Flags().StringVar(&flags.WorkDir, "work-dir", "", "working directory")
+	cmd.Flags().StringArrayVar(&flags.Env, "env", nil, "environment variable KEY=VALUE (repeatable)")
+	cmd.Flags().Int32Var(&flags.Completions, "completions", 1, "successful instances required")
+	cmd.Flags().Int32Var(&flags.Parallelism, "parallelism", 1, "instances running at once")
+	cmd.Flags().Int32Var(&flags.BackoffLimit, "backoff-limit", 6, "failed instances tolerated before the job fails")
+	cmd.Flags().StringVar(&flags.CompletionMode, "completion-mode", "", "NonIndexed (default) or Indexed")
+	cmd.Flags().DurationVar(&flags.ActiveDeadline, "active-deadline", 0, "fail the job when it runs longer than this")
+	cmd.Flags().StringSliceVar(&flags.DependsOn, "depends-on", nil, "jobs that must succeed before this one starts")
+	_ = cmd.MarkFlagRequired("command")
+	return cmd
+}
+
+// createJobListCommand creates the job list subcommand
+func createJobListCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
+	flags := &JobFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the daemon's jobs",
+		Long: `List the daemon's jobs with their phase, completions and failures.
+
+Examples:
+  provisr job list
+  provisr job list -o json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.Output = globalFlags.Output
+			return provisrCommand.Jobs(*flags)
+		},
+	}
+	addJobAPIFlags(cmd, flags)
+	return cmd
+}
+
+// createJobStatusCommand creates the job status subcommand
+func createJobStatusCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
+	flags := &JobFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show a job and its instances",
+		Long: `Show a job's status and every instance it launched, with its attempt,
+state, PID and exit code, to find which completion of a job is failing.
+
+Examples:
+  provisr job status --name=batch`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.Output = globalFlags.Output
+			return provisrCommand.JobStatus(*flags)
+		},
+	}
+	addJobFlags(cmd, flags)
+	return cmd
+}
+
+// createJobDeleteCommand creates the job delete subcommand
+func createJobDeleteCommand(provisrCommand command) *cobra.Command {
+	flags := &JobFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Stop a job and remove it",
+		Long: `Delete a job: its running instances are stopped and it is removed from
+the daemon.
+
+Examples:
+  provisr job delete --name=batch`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.DeleteJob(*flags)
+		},
+	}
+	addJobFlags(cmd, flags)
+	return cmd
+}
+
+// createJobSuspendCommand creates the job suspend subcommand
+func createJobSuspendCommand(provisrCommand command) *cobra.Command {
+	flags := &JobFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "suspend",
+		Short: "Stop a job from launching new instances",
+		Long: `Suspend a job: it launches no new instances until it is resumed. Instances
+that are already running are left to finish and still count towards the
+job's completions.
+
+Examples:
+  provisr job suspend --name=migrate`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.SuspendJob(*flags)
+		},
+	}
+	addJobFlags(cmd, flags)
+	return cmd
+}
+
+// createJobResumeCommand creates the job resume subcommand
+func createJobResumeCommand(provisrCommand command) *cobra.Command {
+	flags := &JobFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Let a suspended job launch instances again",
+		Long: `Resume a suspended job.
+
+Examples:
+  provisr job resume --name=migrate`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.ResumeJob(*flags)
+		},
+	}
+	addJobFlags(cmd, flags)
+	return cmd
+}
+
+// addJobFlags adds the required --name of a job and the daemon connection
+// flags.
+func addJobFlags(cmd *cobra.Command, flags *JobFlags) {
+	cmd.Flags().StringVar(&flags.Name, "name", "", "job name")
+	_ = cmd.MarkFlagRequired("name")
+	addJobAPIFlags(cmd, flags)
+}
+
+func addJobAPIFlags(cmd *cobra.Command, flags *JobFlags) {
+	cmd.Flags().StringVar(&flags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&flags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
+}
+
 // createGroupStartCommand creates the group-start subcommand
 func createGroupStartCommand(provisrCommand command, groupFlags *GroupCommandFlags) *cobra.Command {
 	cmd := &cobra.Command{
@@ -416,7 +819,8 @@ Example:
 		},
 	}
 	cmd.Flags().StringVar(&groupFlags.GroupName, "group", "", "group name (required)")
-	cmd.Flags().StringVar(&groupFlags.APIUrl, "api-url", "", "remote daemon URL (e.g. http://host:8080/api)")
+	mustCompleteFlag(cmd, "group", provisrCommand.completeGroupNames)
+	cmd.Flags().StringVar(&groupFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
 	cmd.Flags().DurationVar(&groupFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
 
 	// Mark required flags
@@ -453,8 +857,9 @@ Example:
 		},
 	}
 	cmd.Flags().StringVar(&groupFlags.GroupName, "group", "", "group name (required)")
+	mustCompleteFlag(cmd, "group", provisrCommand.completeGroupNames)
 	cmd.Flags().Duration("wait", 3*time.Second, "time to wait for graceful shutdown")
-	cmd.Flags().StringVar(&groupFlags.APIUrl, "api-url", "", "remote daemon URL (e.g. http://host:8080/api)")
+	cmd.Flags().StringVar(&groupFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
 	cmd.Flags().DurationVar(&groupFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
 
 	// Mark required flags
@@ -464,8 +869,39 @@ Example:
 	return cmd
 }
 
+// createRollingRestartCommand creates the rolling-restart subcommand
+func createRollingRestartCommand(provisrCommand command, f *RollingRestartFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rolling-restart",
+		Short: "Restart a process group without downtime",
+		Long: `Restart the instances of a named group a few at a time through the
+daemon, waiting for each batch to run again before restarting the next, so
+the group keeps serving during a redeploy. At least one instance always
+stays up. The rollout stops at the first instance that fails to come back.
+
+Example:
+  provisr rolling-restart --group=webstack
+  provisr rolling-restart --group=webstack --max-unavailable=2 --ready-timeout=1m`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.RollingRestart(*f)
+		},
+	}
+	cmd.Flags().StringVar(&f.GroupName, "group", "", "group name (required)")
+	mustCompleteFlag(cmd, "group", provisrCommand.completeGroupNames)
+	cmd.Flags().IntVar(&f.MaxUnavailable, "max-unavailable", 1, "instances restarted at once")
+	cmd.Flags().DurationVar(&f.Wait, "wait", 3*time.Second, "time to wait for graceful shutdown of each instance")
+	cmd.Flags().DurationVar(&f.ReadyTimeout, "ready-timeout", 30*time.Second, "time each restarted instance may take to run again")
+	cmd.Flags().StringVar(&f.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&f.APITimeout, "api-timeout", 10*time.Minute, "request timeout, covering the whole rollout")
+
+	if err := cmd.MarkFlagRequired("group"); err != nil {
+		panic(err) // This should never happen during setup
+	}
+	return cmd
+}
+
 // createGroupStatusCommand creates the group-status subcommand
-func createGroupStatusCommand(provisrCommand command, groupFlags *GroupCommandFlags) *cobra.Command {
+func createGroupStatusCommand(provisrCommand command, groupFlags *GroupCommandFlags, globalFlags *GlobalFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "group-status",
 		Short: "Show group status",
@@ -473,17 +909,20 @@ func createGroupStatusCommand(provisrCommand command, groupFlags *GroupCommandFl
 
 Example:
   provisr group-status --group=webstack
-  provisr group-status --group=webstack --api-url=http://127.0.0.1:8080/api`,
+  provisr group-status --group=webstack --api-url=http://127.0.0.1:8080/api
+  provisr group-status --group=webstack --output=json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return provisrCommand.GroupStatus(GroupFlags{
 				GroupName:  groupFlags.GroupName,
+				Output:     globalFlags.Output,
 				APIUrl:     groupFlags.APIUrl,
 				APITimeout: groupFlags.APITimeout,
 			})
 		},
 	}
 	cmd.Flags().StringVar(&groupFlags.GroupName, "group", "", "group name (required)")
-	cmd.Flags().StringVar(&groupFlags.APIUrl, "api-url", "", "remote daemon URL (e.g. http://host:8080/api)")
+	mustCompleteFlag(cmd, "group", provisrCommand.completeGroupNames)
+	cmd.Flags().StringVar(&groupFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
 	cmd.Flags().DurationVar(&groupFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
 
 	// Mark required flags
@@ -508,7 +947,8 @@ All configuration is loaded from config.toml file.
 Examples:
   provisr serve                     # Start daemon (uses --config)
   provisr serve config.toml         # Start with specific config file
-  provisr serve --daemonize         # Run as daemon in background (configured via [daemon])`,
+  provisr serve --daemonize         # Run as daemon in background (configured via [daemon])
+  provisr serve config.toml --dry-run  # Validate the config and print what a fresh serve would do`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runSimpleServeCommand(serveFlags, args)
 		},
@@ -517,10 +957,56 @@ Examples:
 	// Add daemonize flags
 	cmd.Flags().BoolVar(&serveFlags.Daemonize, "daemonize", false, "run as daemon in background")
 	cmd.Flags().StringVar(&serveFlags.LogFile, "logfile", "", "redirect daemon logs to file")
+	cmd.Flags().BoolVar(&serveFlags.DryRun, "dry-run", false, "validate the config, print the plan a fresh serve would carry out as JSON and exit; a running daemon is not consulted")
 
 	return cmd
 }
 
+// managerGroups converts the config's group definitions for the manager.
+func managerGroups(cfg *config.LoadedConfig) []provisr.ManagerInstanceGroup {
+	groups := make([]provisr.ManagerInstanceGroup, len(cfg.GroupSpecs))
+	for i, group := range cfg.GroupSpecs {
+		groups[i] = provisr.ManagerInstanceGroup{
+			Name:    group.Name,
+			Members: group.Members,
+		}
+	}
+	return groups
+}
+
+// dryRunServe validates cfg and prints, as JSON, the plan serve would carry
+// out if it started now: which processes it would recover from their PID
+// files or start, and the groups and cron jobs it would set up. It plans
+// against an empty manager, not a running daemon, so it never reports keep
+// or stop. Nothing is started or written.
+func dryRunServe(cfg *config.LoadedConfig) error {
+	mgr := provisr.New()
+	defer func() { _ = mgr.Shutdown() }()
+	mgr.SetGlobalEnv(cfg.GlobalEnv)
+
+	plan, err := mgr.ApplyConfigWithOptions(cfg.Specs, provisr.ApplyOptions{DryRun: true})
+	if err != nil {
+		return err
+	}
+	plan.Groups = mgr.PlanInstanceGroups(managerGroups(cfg))
+	for _, j := range cfg.CronJobs {
+		plan.CronJobs = append(plan.CronJobs, provisr.PlanChange{Name: j.Name, Action: provisr.PlanAdd})
+	}
+	printJSON(plan)
+	return nil
+}
+
+// daemonID identifies the daemon serving configPath, so that after a crash
+// it only adopts the processes it started itself and not those of a daemon
+// on another config.
+func daemonID(configPath string) string {
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
+	sum := sha256.Sum256([]byte(configPath))
+	return hex.EncodeToString(sum[:8])
+}
+
 func runSimpleServeCommand(flags *ServeFlags, args []string) error {
 	configPath := flags.ConfigPath
 	if len(args) > 0 {
@@ -541,12 +1027,15 @@ func runSimpleServeCommand(flags *ServeFlags, args []string) error {
 	if cfg.PIDDir == "" {
 		return fmt.Errorf("pid_dir must be set in the config to determine where to write process PID files")
 	}
+	if flags.DryRun {
+		return dryRunServe(cfg)
+	}
 	pidDir := cfg.PIDDir
 	if !filepath.IsAbs(pidDir) {
 		pidDir = filepath.Join(filepath.Dir(configPath), pidDir)
 	}
-	if err := os.MkdirAll(pidDir, 0o750); err != nil {
-		return fmt.Errorf("failed to create pid_dir %s: %w", pidDir, err)
+	if err := provisr.EnsureDir(pidDir, cfg.PIDDirMode, cfg.PIDDirOwner); err != nil {
+		return fmt.Errorf("pid_dir: %w", err)
 	}
 
 	// Daemon process settings are separate from the HTTP server settings.
@@ -562,22 +1051,60 @@ func runSimpleServeCommand(flags *ServeFlags, args []string) error {
 		return daemonize(pidfile, logfile)
 	}
 
+	// Route daemon logs through the [log] settings, including the optional
+	// syslog tee configured under [log.syslog].
+	if cfg.Log != nil {
+		slog.SetDefault(cfg.Log.NewSlogger())
+	}
+
 	// Create manager (PID-only management; persistent store removed)
 	mgr := provisr.New()
 
 	// Apply global environment
 	mgr.SetGlobalEnv(cfg.GlobalEnv)
-
-	// Convert and set group definitions
-	managerGroups := make([]provisr.ManagerInstanceGroup, len(cfg.GroupSpecs))
-	for i, group := range cfg.GroupSpecs {
-		managerGroups[i] = provisr.ManagerInstanceGroup{
-			Name:    group.Name,
-			Members: group.Members,
+	if cfg.Log != nil {
+		mgr.SetLogConfig(*cfg.Log)
+	}
+	if cfg.HealthCheckInterval > 0 {
+		mgr.SetDefaultHealthCheckInterval(cfg.HealthCheckInterval)
+	}
+	mgr.SetRestartJitter(cfg.RestartJitter)
+	mgr.SetMaxConcurrentStarts(cfg.MaxConcurrentStarts)
+	if cfg.InstanceNaming != nil {
+		// validated when the config was loaded
+		naming, _ := cfg.InstanceNaming.Scheme()
+		mgr.SetInstanceNaming(naming)
+	}
+	mgr.SetValidateCommands(cfg.ValidateCommands)
+	mgr.SetDaemonID(daemonID(configPath))
+	if cfg.ChildSubreaper {
+		if err := mgr.SetChildSubreaper(); err != nil {
+			return fmt.Errorf("child_subreaper: %w", err)
+		}
+	}
+	if t := cfg.Tracing; t != nil && t.Enabled {
+		tp, err := tracing.Setup(context.Background(), tracing.Options{
+			Endpoint:    t.Endpoint,
+			Insecure:    t.Insecure,
+			ServiceName: t.ServiceName,
+			SampleRatio: t.SampleRatio,
+		})
+		if err != nil {
+			return fmt.Errorf("setup tracing: %w", err)
 		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = tp.Shutdown(ctx)
+		}()
+		mgr.SetTracer(tracing.NewTracer(tp))
 	}
-	mgr.SetInstanceGroups(managerGroups)
+
+	// Convert and set group definitions
+	mgr.SetInstanceGroups(managerGroups(cfg))
 	var historyReader provisr.HistoryReader
+	var jobOutputStore provisr.JobOutputStore
+	var metadataStore provisr.ProcessMetadataStore
 	var historyClosers []io.Closer
 	retentionCtx, stopRetention := context.WithCancel(context.Background())
 	defer stopRetention()
@@ -591,6 +1118,14 @@ func runSimpleServeCommand(flags *ServeFlags, args []string) error {
 			if closer, ok := sink.(io.Closer); ok {
 				historyClosers = append(historyClosers, closer)
 			}
+			// Captured job output goes to the first store that can keep it.
+			if store, ok := sink.(provisr.JobOutputStore); ok && jobOutputStore == nil {
+				jobOutputStore = store
+			}
+			// So do process labels and other metadata.
+			if store, ok := sink.(provisr.ProcessMetadataStore); ok && metadataStore == nil {
+				metadataStore = store
+			}
 			if name == cfg.History.Primary {
 				reader, ok := sink.(provisr.HistoryReader)
 				if !ok {
@@ -664,6 +1199,37 @@ func runSimpleServeCommand(flags *ServeFlags, args []string) error {
 			}
 		}
 
+		if hook := cfg.History.Webhook; hook != nil && hook.Enabled {
+			sink, err := webhook.New(webhook.Options{
+				URL:           hook.URL,
+				Secret:        hook.Secret,
+				Events:        hook.Events,
+				Timeout:       hook.Timeout,
+				MaxRetries:    hook.MaxRetries,
+				RetryInterval: hook.RetryInterval,
+				QueueSize:     hook.QueueSize,
+			})
+			if err != nil {
+				return fmt.Errorf("setup history webhook: %w", err)
+			}
+			if err := register("webhook", sink, 0, 0); err != nil {
+				return err
+			}
+		}
+
+		if store := cfg.History.NATS; store != nil && store.Enabled {
+			sink, err := newNATSHistorySink(store)
+			if err != nil {
+				return fmt.Errorf("setup nats history publisher: %w", err)
+			}
+			if err := register("nats", sink, 0, 0); err != nil {
+				return err
+			}
+		}
+
+		if metadataStore != nil {
+			mgr.SetMetadataStore(metadataStore)
+		}
 		if len(sinks) > 0 {
 			mgr.SetHistorySinks(sinks...)
 			fmt.Printf("History tracking enabled (%d store(s))\n", len(sinks))
@@ -678,9 +1244,33 @@ func runSimpleServeCommand(flags *ServeFlags, args []string) error {
 		}
 	}()
 
+	// Check Server config (was HTTP config)
+	if cfg.Server == nil {
+		return fmt.Errorf("server must be configured to run serve command")
+	}
+
+	// One auth service for the HTTP, gRPC and metrics servers, so a token
+	// issued by one is accepted by all and they see the same users.
+	authService, err := provisr.NewAuthService(cfg.Server.Auth)
+	if err != nil {
+		return err
+	}
+	if authService != nil {
+		defer func() { _ = authService.Close() }()
+	}
+	// One audit logger for both servers keeps the hash chain unbroken.
+	auditLogger, err := provisr.NewAuditLogger(cfg.Server.Audit, authService)
+	if err != nil {
+		return err
+	}
+	if auditLogger != nil {
+		defer func() { _ = auditLogger.Close() }()
+	}
+
 	// Setup metrics from config
 	if cfg.Metrics != nil && cfg.Metrics.Enabled {
 		mgr.SetObservers(provisr.MetricsObserver())
+		provisr.SetMetricsConstLabels(cfg.Metrics.Labels)
 		// Configure process metrics if enabled
 		if cfg.Metrics.ProcessMetrics != nil && cfg.Metrics.ProcessMetrics.Enabled {
 			processMetricsConfig := *cfg.Metrics.ProcessMetrics
@@ -708,24 +1298,36 @@ func runSimpleServeCommand(flags *ServeFlags, args []string) error {
 
 		if cfg.Metrics.Listen != "" {
 			go func() {
-				if err := provisr.ServeMetrics(cfg.Metrics.Listen); err != nil {
+				if err := provisr.ServeMetricsWithConfig(*cfg.Metrics, authService); err != nil {
 					fmt.Printf("Metrics server error: %v\n", err)
 				}
 			}()
 		}
-	}
 
-	// Check Server config (was HTTP config)
-	if cfg.Server == nil {
-		return fmt.Errorf("server must be configured to run serve command")
+		if cfg.Metrics.Push != nil {
+			pusher := provisr.NewMetricsPusher(*cfg.Metrics.Push)
+			pusher.Start(context.Background())
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := pusher.Stop(ctx); err != nil {
+					fmt.Printf("Warning: failed to push metrics on shutdown: %v\n", err)
+				}
+			}()
+		}
 	}
 
 	// Apply config: recover from PID files, start missing, and cleanup removed processes
-	if err := mgr.ApplyConfig(cfg.Specs); err != nil {
+	if result, err := mgr.Reconcile(cfg.Specs); err != nil {
 		fmt.Printf("Warning: failed to apply config: %v\n", err)
+	} else {
+		fmt.Printf("Applied config: %s\n", result)
 	}
 
 	jobManager := provisr.NewJobManager(mgr)
+	if jobOutputStore != nil {
+		jobManager.SetOutputStore(jobOutputStore)
+	}
 
 	// Always create the cron scheduler (even with zero initial jobs) so the
 	// HTTP /cronjobs* endpoints can register jobs on a running daemon, not
@@ -747,12 +1349,12 @@ func runSimpleServeCommand(flags *ServeFlags, args []string) error {
 
 	if cfg.Server.TLS != nil && cfg.Server.TLS.Enabled {
 		protocol = "HTTPS"
-		server, err = provisr.NewTLSServerWithHistoryReader(*cfg.Server, mgr, cronScheduler, historyReader, cfg.ResolvedProgramsDirectory)
+		server, err = provisr.NewTLSServerWithAuth(*cfg.Server, mgr, cronScheduler, historyReader, authService, auditLogger, cfg.ResolvedProgramsDirectory)
 		if err != nil {
 			return fmt.Errorf("failed to create HTTPS server: %w", err)
 		}
 	} else {
-		server, err = provisr.NewHTTPServerWithHistoryReader(*cfg.Server, mgr, cronScheduler, historyReader, cfg.ResolvedProgramsDirectory)
+		server, err = provisr.NewHTTPServerWithAuth(*cfg.Server, mgr, cronScheduler, historyReader, authService, auditLogger, cfg.ResolvedProgramsDirectory)
 		if err != nil {
 			return fmt.Errorf("failed to create HTTP server: %w", err)
 		}
@@ -760,10 +1362,35 @@ func runSimpleServeCommand(flags *ServeFlags, args []string) error {
 
 	fmt.Printf("Starting provisr %s server on %s%s\n", protocol, cfg.Server.Listen, cfg.Server.BasePath)
 
-	// Wait for shutdown signal
+	var grpcServer *provisr.GRPCServer
+	if cfg.Server.GRPC != nil {
+		grpcServer, err = provisr.NewGRPCServerWithAuth(*cfg.Server, mgr, cronScheduler, authService, auditLogger, cfg.ResolvedProgramsDirectory)
+		if err != nil {
+			_ = server.Close()
+			return fmt.Errorf("failed to create gRPC server: %w", err)
+		}
+		fmt.Printf("Starting provisr gRPC server on %s\n", grpcServer.Addr())
+	}
+
+	// Wait for shutdown signal; SIGHUP reloads the config, picking up
+	// program files added to or removed from the programs directory.
+	reloader := newConfigReloader(configPath, mgr, cronScheduler, cfg)
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	<-sigCh
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range sigCh {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		result, err := reloader.reload()
+		if err != nil {
+			fmt.Printf("Warning: config reload failed: %v\n", err)
+			if result.Changed() {
+				fmt.Printf("Processes before the failure: %s\n", result)
+			}
+			continue
+		}
+		fmt.Printf("Reloaded config: %s\n", result)
+	}
 
 	fmt.Println("Shutting down...")
 	stopRetention()
@@ -772,6 +1399,9 @@ func runSimpleServeCommand(flags *ServeFlags, args []string) error {
 	}
 	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancelShutdown()
+	if grpcServer != nil {
+		_ = grpcServer.Shutdown(shutdownCtx)
+	}
 	return server.Shutdown(shutdownCtx)
 }
 
@@ -796,6 +1426,40 @@ Examples:
 	return cmd
 }
 
+// createAuditCommand creates the audit command with subcommands
+func createAuditCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Audit log commands",
+		Long:  "Inspect the control-plane audit log written by the daemon",
+	}
+	cmd.AddCommand(createAuditVerifyCommand(provisrCommand, globalFlags))
+	return cmd
+}
+
+// createAuditVerifyCommand creates the audit verify subcommand
+func createAuditVerifyCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
+	flags := &AuditVerifyFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check the audit log hash chain",
+		Long: `Check that no entry of a hash-chained audit log was edited or removed.
+Without --file, the log configured in [server.audit] is checked.
+
+Examples:
+  provisr audit verify --config=config.toml
+  provisr audit verify --file=/var/log/provisr/audit.log`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.AuditVerify(*flags, globalFlags.ConfigPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.File, "file", "", "audit log file (default: server.audit.path from the config)")
+
+	return cmd
+}
+
 // createAuthUserCommand creates the auth user subcommand
 func createAuthUserCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
 	cmd := &cobra.Command{
@@ -809,7 +1473,9 @@ func createAuthUserCommand(provisrCommand command, globalFlags *GlobalFlags) *co
 		createAuthUserCreateCommand(provisrCommand, globalFlags),
 		createAuthUserListCommand(provisrCommand, globalFlags),
 		createAuthUserDeleteCommand(provisrCommand, globalFlags),
+		createAuthUserRestoreCommand(provisrCommand, globalFlags),
 		createAuthUserPasswordCommand(provisrCommand, globalFlags),
+		createAuthUserRolesCommand(provisrCommand, globalFlags),
 	)
 
 	return cmd
@@ -846,15 +1512,25 @@ Examples:
 
 // createAuthUserListCommand creates the auth user list subcommand
 func createAuthUserListCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
+	flags := &AuthUserListFlags{}
+
 	cmd := &cobra.Command{
 		Use:   "list",
-		Short: "List all users",
-		Long:  "List all user accounts in the system",
+		Short: "List users",
+		Long: `List user accounts. Deleted users are hidden unless --deleted is set.
+
+Examples:
+  provisr auth user list
+  provisr auth user list --active
+  provisr auth user list --deleted`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return provisrCommand.AuthUserList(globalFlags.ConfigPath)
+			return provisrCommand.AuthUserList(*flags, globalFlags.ConfigPath, globalFlags.Output)
 		},
 	}
 
+	cmd.Flags().BoolVar(&flags.IncludeDeleted, "deleted", false, "include soft-deleted users")
+	cmd.Flags().BoolVar(&flags.ActiveOnly, "active", false, "only list active users")
+
 	return cmd
 }
 
@@ -865,16 +1541,41 @@ func createAuthUserDeleteCommand(provisrCommand command, globalFlags *GlobalFlag
 	cmd := &cobra.Command{
 		Use:   "delete",
 		Short: "Delete a user",
-		Long: `Delete a user account from the system.
+		Long: `Delete a user account. The user is deactivated and kept so it can be
+restored; --hard removes it permanently.
 
 Examples:
-  provisr auth user delete --username=olduser`,
+  provisr auth user delete --username=olduser
+  provisr auth user delete --username=olduser --hard`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return provisrCommand.AuthUserDelete(*flags, globalFlags.ConfigPath)
 		},
 	}
 
 	cmd.Flags().StringVar(&flags.Username, "username", "", "username to delete (required)")
+	cmd.Flags().BoolVar(&flags.Hard, "hard", false, "remove the user permanently instead of soft-deleting it")
+	_ = cmd.MarkFlagRequired("username")
+
+	return cmd
+}
+
+// createAuthUserRestoreCommand creates the auth user restore subcommand
+func createAuthUserRestoreCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
+	flags := &AuthUserRestoreFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a deleted user",
+		Long: `Reactivate a soft-deleted user with its previous roles and password.
+
+Examples:
+  provisr auth user restore --username=olduser`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.AuthUserRestore(*flags, globalFlags.ConfigPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.Username, "username", "", "username to restore (required)")
 	_ = cmd.MarkFlagRequired("username")
 
 	return cmd
@@ -905,6 +1606,33 @@ Examples:
 	return cmd
 }
 
+// createAuthUserRolesCommand creates the auth user roles subcommand
+func createAuthUserRolesCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
+	flags := &AuthUserRolesFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "roles",
+		Short: "Set user roles",
+		Long: `Replace a user's roles. Tokens issued after the change carry the new
+roles; tokens issued before keep the old ones until they expire.
+
+Examples:
+  provisr auth user roles --username=alice --set=operator
+  provisr auth user roles --username=bob --set=admin,operator`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.AuthUserRoles(*flags, globalFlags.ConfigPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.Username, "username", "", "username (required)")
+	cmd.Flags().StringSliceVar(&flags.Roles, "set", nil, "roles to assign, replacing the current ones (admin, operator, viewer)")
+
+	_ = cmd.MarkFlagRequired("username")
+	_ = cmd.MarkFlagRequired("set")
+
+	return cmd
+}
+
 // createAuthTestCommand creates the auth test subcommand
 func createAuthTestCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
 	flags := &AuthTestFlags{}
@@ -938,17 +1666,24 @@ func createLoginCommand(provisrCommand command) *cobra.Command {
 		Short: "Login to provisr server",
 		Long: `Login to provisr server and save session for future commands.
 
+The session is saved in the context selected by --context, $PROVISR_CONTEXT
+or the current context; see 'provisr context'. Without --server-url, the
+context's saved server is used.
+
 Examples:
   provisr login --username=admin --password=secret
-  provisr login --server-url=http://remote:8080/api --username=admin --password=secret`,
+  provisr login --server-url=http://remote:8080/api --username=admin --password=secret
+  provisr login --context=prod --server-url=https://prod:8080/api --username=admin --password=secret
+  provisr login --method=oidc --id-token="$(my-idp-cli print-id-token)"`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return provisrCommand.Login(*flags)
 		},
 	}
 
-	cmd.Flags().StringVar(&flags.Method, "method", "basic", "authentication method (basic)")
+	cmd.Flags().StringVar(&flags.Method, "method", "basic", "authentication method (basic or oidc)")
 	cmd.Flags().StringVar(&flags.Username, "username", "", "username (for basic auth)")
 	cmd.Flags().StringVar(&flags.Password, "password", "", "password (for basic auth)")
+	cmd.Flags().StringVar(&flags.IDToken, "id-token", "", "ID token from the OIDC provider (for oidc auth)")
 	cmd.Flags().StringVar(&flags.ServerURL, "server-url", "", "server URL (default: http://localhost:8080/api)")
 
 	return cmd
@@ -971,6 +1706,239 @@ Examples:
 	return cmd
 }
 
+// createContextCommand creates the context command with subcommands
+func createContextCommand(provisrCommand command, globalFlags *GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Manage saved login contexts",
+		Long: `Each context holds the server URL and session of one daemon, so a CLI can
+target several daemons. 'provisr login --context=NAME' creates or refreshes
+a context; other commands use the current context unless --context or
+$PROVISR_CONTEXT names another. Contexts are kept in ~/.provisr/contexts.json,
+readable by its owner only.
+
+Examples:
+  provisr context list
+  provisr context use prod
+  provisr status --context=staging
+  provisr context delete staging`,
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List saved contexts",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return provisrCommand.ContextList(globalFlags.Output)
+			},
+		},
+		&cobra.Command{
+			Use:   "use NAME",
+			Short: "Make a context the current one",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return provisrCommand.ContextUse(args[0])
+			},
+		},
+		&cobra.Command{
+			Use:   "delete NAME",
+			Short: "Delete a saved context",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return provisrCommand.ContextDelete(args[0])
+			},
+		},
+	)
+
+	return cmd
+}
+
+// createLogsCommand creates the logs subcommand
+func createLogsCommand(provisrCommand command, logsFlags *LogsFlags, globalFlags *GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the output of a process",
+		Long: `Show the stdout and stderr of a process as captured by the daemon, which
+keeps the most recent lines of each process. Without a reachable daemon, or
+with --local, the log files set in the --config file are read instead;
+--since then needs output_format = "json" log files. Stderr is shown in red
+on terminals unless NO_COLOR is set.
+
+Examples:
+  provisr logs --name=web --follow --tail=100
+  provisr logs --name=web --stderr --since=10m
+  provisr logs --name=web-2 --local --config=config.toml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return provisrCommand.Logs(ctx, *logsFlags, globalFlags.ConfigPath, os.Stdout)
+		},
+	}
+	cmd.Flags().StringVar(&logsFlags.Name, "name", "", "process name (required)")
+	mustCompleteFlag(cmd, "name", provisrCommand.completeProcessNames)
+	cmd.Flags().BoolVarP(&logsFlags.Follow, "follow", "f", false, "keep printing new output")
+	cmd.Flags().IntVar(&logsFlags.Tail, "tail", 100, "number of recent lines to show (-1 for all)")
+	cmd.Flags().DurationVar(&logsFlags.Since, "since", 0, "only show lines newer than this, e.g. 10m")
+	cmd.Flags().BoolVar(&logsFlags.Stdout, "stdout", false, "only show stdout")
+	cmd.Flags().BoolVar(&logsFlags.Stderr, "stderr", false, "only show stderr")
+	cmd.Flags().BoolVar(&logsFlags.Local, "local", false, "read the log files set in --config instead of asking the daemon")
+	cmd.Flags().StringVar(&logsFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&logsFlags.APITimeout, "api-timeout", 10*time.Second, "request timeout")
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// createEventsCommand creates the events subcommand
+func createEventsCommand(provisrCommand command, eventsFlags *EventsFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Show process lifecycle events",
+		Long: `Show recent process lifecycle events (start, stop, restart, fatal) with
+their times, PIDs and exit codes, oldest first. Recorded events come from
+the daemon's history store; --follow then streams new events as they happen,
+and works without a history store too. --name matches a process or all
+instances of a multi-instance process. --since and --until take an RFC 3339
+time or a duration ago.
+
+Examples:
+  provisr events --name=web --tail=50
+  provisr events --type=stop,fatal --since=24h
+  provisr events --name=web --follow`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return provisrCommand.Events(ctx, *eventsFlags, os.Stdout)
+		},
+	}
+	cmd.Flags().StringVar(&eventsFlags.Name, "name", "", "process name (default all processes)")
+	mustCompleteFlag(cmd, "name", provisrCommand.completeProcessNames)
+	cmd.Flags().IntVar(&eventsFlags.Tail, "tail", 50, "number of recent recorded events to show")
+	cmd.Flags().StringSliceVar(&eventsFlags.Types, "type", nil, "only show these event types: start, stop, restart, fatal")
+	cmd.Flags().StringVar(&eventsFlags.Since, "since", "", "only show events after this time or duration ago, e.g. 1h")
+	cmd.Flags().StringVar(&eventsFlags.Until, "until", "", "only show events up to this time or duration ago")
+	cmd.Flags().BoolVarP(&eventsFlags.Follow, "follow", "f", false, "keep printing new events")
+	cmd.Flags().StringVar(&eventsFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&eventsFlags.APITimeout, "api-timeout", 10*time.Second, "request timeout")
+	cmd.MarkFlagsMutuallyExclusive("follow", "until")
+	return cmd
+}
+
+// createTopCommand creates the top subcommand
+func createTopCommand(provisrCommand command, topFlags *TopFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show processes by CPU and memory usage",
+		Long: `Show the processes of the daemon sorted by CPU, memory, threads or open
+files, refreshed every --interval until Ctrl-C. The numbers come from the
+daemon's process metrics, which must be enabled:
+
+  [metrics.process_metrics]
+  enabled = true
+
+--filter takes a glob matched against process names. On a terminal a table
+longer than the screen is cut off.
+
+Examples:
+  provisr top
+  provisr top --sort=mem --filter='web-*'
+  provisr top --interval=5s --api-url=http://remote:8080/api`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return provisrCommand.Top(ctx, *topFlags, os.Stdout)
+		},
+	}
+	cmd.Flags().StringVar(&topFlags.Sort, "sort", "cpu", "sort by cpu, mem, threads, fds, pid or name")
+	cmd.Flags().StringVar(&topFlags.Filter, "filter", "", "only show processes whose name matches this glob, e.g. 'web-*'")
+	cmd.Flags().DurationVar(&topFlags.Interval, "interval", 2*time.Second, "how often to refresh")
+	cmd.Flags().StringVar(&topFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&topFlags.APITimeout, "api-timeout", 10*time.Second, "request timeout")
+	return cmd
+}
+
+// createVersionCommand creates the version subcommand
+func createVersionCommand(provisrCommand command, versionFlags *VersionFlags, globalFlags *GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show the CLI and daemon versions",
+		Long: `Show the version, git commit, build date and Go version of this CLI and
+of the daemon it talks to, and warn when they differ. Other commands warn
+about such version skew on stderr too.
+
+Examples:
+  provisr version
+  provisr version --client
+  provisr version -o json --api-url=http://remote:8080/api`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			versionFlags.Output = globalFlags.Output
+			return provisrCommand.Version(*versionFlags, os.Stdout)
+		},
+	}
+	cmd.Flags().BoolVar(&versionFlags.Client, "client", false, "only show the CLI's version")
+	cmd.Flags().StringVar(&versionFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&versionFlags.APITimeout, "api-timeout", 5*time.Second, "request timeout")
+	return cmd
+}
+
+// createExportCommand creates the export subcommand
+func createExportCommand(provisrCommand command, exportFlags *ExportFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export all process definitions",
+		Long: `Export every registered process (from program files and the config
+file), group and cron job of the daemon into one JSON document, for backup
+or migration to another daemon with 'provisr import'.
+
+Examples:
+  provisr export --output=dump.json
+  provisr export --api-url=http://remote:8080/api > dump.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.Export(ExportFlags{
+				Output:     exportFlags.Output,
+				APIUrl:     exportFlags.APIUrl,
+				APITimeout: exportFlags.APITimeout,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&exportFlags.Output, "output", "", "file to write (defaults to stdout)")
+	cmd.Flags().StringVar(&exportFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&exportFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
+	return cmd
+}
+
+// createImportCommand creates the import subcommand
+func createImportCommand(provisrCommand command, importFlags *ImportFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import process definitions from an export",
+		Long: `Recreate the processes, groups and cron jobs of a 'provisr export'
+document on the daemon. Processes and cron jobs are written as program files;
+imported groups apply until the daemon restarts. Entries that already exist
+are an error unless --force replaces them. Entries declared in the daemon's
+config file are never replaced.
+
+Examples:
+  provisr import dump.json
+  provisr import dump.json --force --api-url=http://remote:8080/api`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return provisrCommand.Import(ImportFlags{
+				File:       args[0],
+				Force:      importFlags.Force,
+				APIUrl:     importFlags.APIUrl,
+				APITimeout: importFlags.APITimeout,
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&importFlags.Force, "force", false, "replace processes, cron jobs and groups that already exist")
+	cmd.Flags().StringVar(&importFlags.APIUrl, "api-url", "", "daemon URL (e.g. http://host:8080/api or unix:///run/provisr.sock)")
+	cmd.Flags().DurationVar(&importFlags.APITimeout, "api-timeout", 30*time.Second, "request timeout")
+	return cmd
+}
+
 // createTemplateCommand creates the template command
 func createTemplateCommand(provisrCommand command, templateFlags *TemplateCreateFlags) *cobra.Command {
 	cmd := &cobra.Command{
@@ -987,12 +1955,22 @@ Supported template types:
   cron      - Scheduled task
   simple    - Basic process
 
+Custom templates are JSON process specs named <type>.tmpl.json in the
+templates directory ($PROVISR_TEMPLATES, or ./templates); they take precedence
+over built-in types of the same name. Generated <name>.json specs written
+there are not read back as templates. {{.Name}} in a custom template is replaced
+with --name.
+
 Examples:
+  provisr template --list
   provisr template --type=web --name=my-webapp
   provisr template --type=api --name=user-service
   provisr template --type=worker --output=./custom-worker.json
   provisr template --type=simple --name=hello-world --force`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if templateFlags.List {
+				return provisrCommand.TemplateList(os.Stdout)
+			}
 			return provisrCommand.TemplateCreate(TemplateCreateFlags{
 				Name:   templateFlags.Name,
 				Type:   templateFlags.Type,
@@ -1003,15 +1981,12 @@ Examples:
 	}
 
 	// Add flags specific to template command
-	cmd.Flags().StringVar(&templateFlags.Type, "type", "", "template type (required): web, api, worker, database, cron, simple")
+	cmd.Flags().StringVar(&templateFlags.Type, "type", "", "template type (required unless --list): web, api, worker, database, cron, simple or a custom template")
 	cmd.Flags().StringVar(&templateFlags.Name, "name", "", "process name for template (defaults to type-sample)")
 	cmd.Flags().StringVar(&templateFlags.Output, "output", "", "output file path (defaults to templates/name.json)")
 	cmd.Flags().BoolVar(&templateFlags.Force, "force", false, "overwrite existing template file")
-
-	// Mark required flags
-	if err := cmd.MarkFlagRequired("type"); err != nil {
-		panic(err)
-	}
+	cmd.Flags().BoolVar(&templateFlags.List, "list", false, "list the available templates")
+	cmd.MarkFlagsMutuallyExclusive("list", "type")
 
 	return cmd
 }
@@ -62,7 +62,7 @@ func TestCreateStatusCommand(t *testing.T) {
 	cmd := command{mgr: mgr}
 	processFlags := &ProcessFlags{}
 
-	statusCmd := createStatusCommand(cmd, processFlags)
+	statusCmd := createStatusCommand(cmd, processFlags, &GlobalFlags{})
 
 	if statusCmd == nil {
 		t.Fatal("createStatusCommand returned nil")
@@ -94,7 +94,7 @@ func TestCreateCronCommand(t *testing.T) {
 	cmd := command{mgr: mgr}
 	cronFlags := &CronFlags{}
 
-	cronCmd := createCronCommand(cmd, cronFlags)
+	cronCmd := createCronCommand(cmd, cronFlags, &GlobalFlags{})
 
 	if cronCmd == nil {
 		t.Fatal("createCronCommand returned nil")
@@ -142,7 +142,7 @@ func TestCreateGroupStatusCommand(t *testing.T) {
 	cmd := command{mgr: mgr}
 	groupFlags := &GroupCommandFlags{}
 
-	groupStatusCmd := createGroupStatusCommand(cmd, groupFlags)
+	groupStatusCmd := createGroupStatusCommand(cmd, groupFlags, &GlobalFlags{})
 
 	if groupStatusCmd == nil {
 		t.Fatal("createGroupStatusCommand returned nil")
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// outputFormat is how a command renders its result, chosen with the global
+// --output flag.
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table" // aligned columns with a header row
+	outputJSON  outputFormat = "json"
+	outputYAML  outputFormat = "yaml"
+	outputText  outputFormat = "text" // status commands: key=value pairs per process; others print a table
+)
+
+// parseOutputFormat validates an --output value. Without one, output to a
+// terminal is a table and anything else, such as a pipe, gets JSON.
+func parseOutputFormat(s string, out io.Writer) (outputFormat, error) {
+	switch f := outputFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		if isTerminal(out) {
+			return outputTable, nil
+		}
+		return outputJSON, nil
+	case outputTable, outputJSON, outputYAML, outputText:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid output format %q: must be table, json, yaml or text", s)
+	}
+}
+
+// writeOutput renders v to out: json and yaml encode v, with the same
+// field names in both, while table and text call table to print the
+// human-readable form.
+func writeOutput(out io.Writer, format outputFormat, v any, table func(io.Writer, outputFormat) error) error {
+	switch format {
+	case outputJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	case outputYAML:
+		// Going through JSON keeps the json tags as YAML keys.
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic any
+		if err := json.Unmarshal(b, &generic); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(out)
+		enc.SetIndent(2)
+		if err := enc.Encode(generic); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return table(out, format)
+	}
+}
+
+// isTerminal reports whether out is a terminal rather than a file or pipe.
+func isTerminal(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	// A buffer isn't a terminal, so the default is JSON for scripts.
+	if f, err := parseOutputFormat("", &bytes.Buffer{}); err != nil || f != outputJSON {
+		t.Fatalf("default output: got %q, %v", f, err)
+	}
+	for in, want := range map[string]outputFormat{"table": outputTable, "JSON": outputJSON, " yaml ": outputYAML, "text": outputText} {
+		if f, err := parseOutputFormat(in, &bytes.Buffer{}); err != nil || f != want {
+			t.Errorf("%q: got %q, %v", in, f, err)
+		}
+	}
+	if _, err := parseOutputFormat("xml", &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error for an unknown output format")
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	type item struct {
+		Name     string `json:"name"`
+		Restarts int    `json:"restarts"`
+		Note     string `json:"note,omitempty"`
+	}
+	items := []item{{Name: "web", Restarts: 2}}
+	table := func(w io.Writer, f outputFormat) error {
+		_, err := fmt.Fprintf(w, "%s: %s %d\n", f, items[0].Name, items[0].Restarts)
+		return err
+	}
+
+	tests := []struct {
+		format outputFormat
+		want   string
+	}{
+		{outputJSON, "[\n  {\n    \"name\": \"web\",\n    \"restarts\": 2\n  }\n]\n"},
+		{outputYAML, "- name: web\n  restarts: 2\n"},
+		{outputTable, "table: web 2\n"},
+		{outputText, "text: web 2\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := writeOutput(&out, tt.format, items, table); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.format, out.String(), tt.want)
+		}
+	}
+}
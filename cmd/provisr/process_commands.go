@@ -1,13 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/loykin/provisr"
+	apiwire "github.com/loykin/provisr/pkg/api"
 )
 
 // Start Method-style handlers bound to a command with an embedded manager
@@ -60,24 +66,213 @@ func (c *command) Status(f StatusFlags) error {
 	return c.statusViaAPI(f, apiClient)
 }
 
+// Exit codes of 'status --name' with --fail-if-stopped or --wait-for.
+const (
+	exitRunning  = 0
+	exitStopped  = 3
+	exitNotFound = 4
+)
+
+// statusPollInterval is how often --wait-for queries the daemon.
+var statusPollInterval = 500 * time.Millisecond
+
+// exitError makes main exit with code. An empty msg exits without printing
+// anything, for results the command has already reported.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string { return e.msg }
+
 // statusViaAPI gets status using the daemon API
 func (c *command) statusViaAPI(f StatusFlags, apiClient *APIClient) error {
-	result, err := apiClient.GetStatus(f.Name)
+	format, err := parseOutputFormat(f.Output, os.Stdout)
 	if err != nil {
 		return err
 	}
-
-	if f.Detailed {
-		// For detailed status, we might need to format differently
-		// For now, just print the JSON
-		printJSON(result)
+	checkState := f.FailIfStopped || f.WaitFor != ""
+	if checkState && f.Name == "" {
+		return fmt.Errorf("--fail-if-stopped and --wait-for require --name")
+	}
+	if f.WaitFor != "" && f.WaitFor != "running" && f.WaitFor != "stopped" {
+		return fmt.Errorf("invalid --wait-for %q: must be running or stopped", f.WaitFor)
+	}
+	if err := checkStatusFilters(f); err != nil {
+		return err
+	}
+	get := statusGetter(f, apiClient)
+	var result any
+	if f.WaitFor != "" {
+		result, err = waitForStatus(get, f.Name, f.WaitFor == "running", f.WaitTimeout)
 	} else {
-		printJSON(result)
+		result, err = get(f.Name)
+	}
+	if err != nil {
+		if checkState && errors.Is(err, errProcessNotFound) {
+			return &exitError{code: exitNotFound, msg: err.Error()}
+		}
+		return err
+	}
+
+	running := statusRunning(result)
+	err = writeStatus(os.Stdout, format, f, result)
+	if err != nil || !checkState {
+		return err
 	}
+	return statusExitCode(f, running)
+}
 
+// checkStatusFilters rejects list filters combined with a single --name.
+func checkStatusFilters(f StatusFlags) error {
+	if f.Running && f.Name != "" {
+		return fmt.Errorf("--running filters the process list and cannot be combined with --name")
+	}
+	if len(f.Labels) > 0 && f.Name != "" {
+		return fmt.Errorf("--label filters the process list and cannot be combined with --name")
+	}
 	return nil
 }
 
+// statusGetter returns the daemon query for the status f selects.
+func statusGetter(f StatusFlags, apiClient *APIClient) func(string) (any, error) {
+	get := apiClient.GetStatus
+	if f.Detailed {
+		get = apiClient.GetDetailedStatus
+	}
+	if f.Running || len(f.Labels) > 0 {
+		get = func(name string) (interface{}, error) {
+			return apiClient.getStatus(name, f.Detailed, f.Running, f.Labels)
+		}
+	}
+	return get
+}
+
+// writeStatus renders a status result to out in format.
+func writeStatus(out io.Writer, format outputFormat, f StatusFlags, result any) error {
+	if format == outputJSON || format == outputYAML {
+		humanizeUptime(result)
+	}
+	return writeOutput(out, format, result, func(w io.Writer, format outputFormat) error {
+		rows, err := processStatusRows(result, f.Detailed)
+		if err != nil {
+			return err
+		}
+		return apiwire.WriteStatusRows(w, apiwire.Format(format), rows)
+	})
+}
+
+// WatchStatus redraws the status f selects every f.Watch until ctx is
+// done, like watch(1) around 'provisr status'.
+func (c *command) WatchStatus(ctx context.Context, f StatusFlags, out io.Writer) error {
+	if f.FailIfStopped || f.WaitFor != "" {
+		return fmt.Errorf("--watch cannot be combined with --fail-if-stopped or --wait-for")
+	}
+	if err := checkStatusFilters(f); err != nil {
+		return err
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	return watchStatus(ctx, f, apiClient, out)
+}
+
+// watchStatus draws one frame per f.Watch tick. On a terminal each frame
+// replaces the previous one and is cut to the screen height; elsewhere the
+// frames follow each other. A failed query is shown in the frame rather
+// than ending the watch, so it survives a daemon restart.
+func watchStatus(ctx context.Context, f StatusFlags, apiClient *APIClient, out io.Writer) error {
+	format, err := parseOutputFormat(f.Output, out)
+	if err != nil {
+		return err
+	}
+	get := statusGetter(f, apiClient)
+	tty := isTerminal(out)
+	ticker := time.NewTicker(f.Watch)
+	defer ticker.Stop()
+	for {
+		var frame bytes.Buffer
+		fmt.Fprintf(&frame, "Every %s: provisr status    %s\n\n", f.Watch, time.Now().Format(time.DateTime))
+		if result, err := get(f.Name); err != nil {
+			fmt.Fprintf(&frame, "error: %v\n", err)
+		} else if err := writeStatus(&frame, format, f, result); err != nil {
+			return err
+		}
+
+		text := frame.String()
+		if tty {
+			// Home the cursor and clear the screen before redrawing.
+			text = "\033[H\033[2J" + fitScreen(text, terminalHeight(out))
+		} else {
+			text += "\n"
+		}
+		if _, err := io.WriteString(out, text); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// fitScreen cuts text to height lines, replacing what does not fit with a
+// note, so the header of a long table stays on screen. A height of 0 or
+// less leaves text as is.
+func fitScreen(text string, height int) string {
+	lines := strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+	if height <= 0 || len(lines) <= height {
+		return text
+	}
+	keep := height - 1
+	return strings.Join(lines[:keep], "") +
+		fmt.Sprintf("... %d more lines; narrow the list with --running, --label or --name\n", len(lines)-keep)
+}
+
+// statusExitCode turns the final state of f.Name into the exit status
+// scripts check: exitRunning, or exitStopped when it is not running.
+// Waiting for a stop that never came fails with a message.
+func statusExitCode(f StatusFlags, running bool) error {
+	switch {
+	case f.WaitFor == "stopped" && running:
+		return &exitError{code: 1, msg: fmt.Sprintf("timed out after %s waiting for %s to stop", f.WaitTimeout, f.Name)}
+	case f.WaitFor == "stopped" || running:
+		return nil
+	case f.WaitFor == "running":
+		return &exitError{code: exitStopped, msg: fmt.Sprintf("timed out after %s waiting for %s to be running", f.WaitTimeout, f.Name)}
+	default:
+		return &exitError{code: exitStopped}
+	}
+}
+
+// waitForStatus polls get until name is running (or stopped) or timeout
+// passes, and returns the last status it saw. A process that is not
+// registered yet is polled like a stopped one.
+func waitForStatus(get func(string) (any, error), name string, running bool, timeout time.Duration) (any, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		result, err := get(name)
+		if err != nil && !errors.Is(err, errProcessNotFound) {
+			return nil, err
+		}
+		if (err == nil && statusRunning(result) == running) || !time.Now().Before(deadline) {
+			return result, err
+		}
+		time.Sleep(statusPollInterval)
+	}
+}
+
+// statusRunning reports the "running" field of a decoded single-process
+// status.
+func statusRunning(result any) bool {
+	m, _ := result.(map[string]any)
+	running, _ := m["running"].(bool)
+	return running
+}
+
 // Stop stops processes by name/base from flags or config
 func (c *command) Stop(f StopFlags) error {
 	// Try to use authenticated API client first
@@ -124,8 +319,85 @@ func (c *command) stopViaAPI(f StopFlags, apiClient *APIClient) error {
 	return nil
 }
 
+// Restart stops and starts a process, or every member of a group, through
+// the daemon and prints the new PIDs. A process that wasn't running is just
+// started.
+func (c *command) Restart(f RestartFlags) error {
+	if (f.Name == "") == (f.Group == "") {
+		return fmt.Errorf("restart requires either --name or --group")
+	}
+	if f.Wait <= 0 {
+		f.Wait = 3 * time.Second
+	}
+	// The daemon only answers once the processes are running again.
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout+f.Wait)
+	if err != nil {
+		return err
+	}
+	target, group := f.Name, false
+	if f.Group != "" {
+		target, group = f.Group, true
+	}
+	result, err := apiClient.Restart(target, group, f.Wait)
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, res := range result.Results {
+		if !res.OK {
+			fmt.Printf("Failed to restart %s: %s\n", res.Name, res.Error)
+			failed = append(failed, res.Name)
+			continue
+		}
+		fmt.Printf("Restarted %s (PID %d)\n", res.Name, res.PID)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to restart %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Scale changes the number of instances of a process through the daemon
+func (c *command) Scale(f ScaleFlags) error {
+	if f.Name == "" {
+		return fmt.Errorf("scale requires --name")
+	}
+	if f.Instances < 1 {
+		return fmt.Errorf("--instances must be at least 1")
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	if err := apiClient.Scale(f.Name, f.Instances); err != nil {
+		return err
+	}
+	fmt.Printf("Scaled %s to %d instance(s)\n", f.Name, f.Instances)
+	return nil
+}
+
+// Signal sends a signal to a running process through the daemon
+func (c *command) Signal(f SignalFlags) error {
+	if f.Name == "" || f.Signal == "" {
+		return fmt.Errorf("signal requires --name and --signal")
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	if err := apiClient.Signal(f.Name, f.Signal); err != nil {
+		return err
+	}
+	fmt.Printf("Sent %s to %s\n", strings.ToUpper(strings.TrimSpace(f.Signal)), f.Name)
+	return nil
+}
+
 // Register registers a new process by creating a program file
 func (c *command) Register(f RegisterFlags, configPath string) error {
+	if err := validateRegisterFlags(f); err != nil {
+		return err
+	}
 	if f.APIUrl != "" {
 		apiClient := NewAPIClient(f.APIUrl, f.APITimeout)
 		if !apiClient.IsReachable() {
@@ -140,7 +412,13 @@ func (c *command) Register(f RegisterFlags, configPath string) error {
 
 // registerViaAPI registers a process via the daemon API
 func (c *command) registerViaAPI(f RegisterFlags, apiClient *APIClient) error {
-	// Create process spec for API
+	// The API decodes durations as nanoseconds
+	return apiClient.RegisterProcess(registerSpecData(f, func(d time.Duration) interface{} { return int64(d) }))
+}
+
+// registerSpecData builds the JSON spec for f, leaving out optional fields
+// that were not given. duration encodes the duration fields.
+func registerSpecData(f RegisterFlags, duration func(time.Duration) interface{}) map[string]interface{} {
 	spec := map[string]interface{}{
 		"name":         f.Name,
 		"command":      f.Command,
@@ -155,8 +433,45 @@ func (c *command) registerViaAPI(f RegisterFlags, apiClient *APIClient) error {
 			},
 		}
 	}
+	if len(f.Env) > 0 {
+		spec["env"] = f.Env
+	}
+	if labels, err := provisr.ParseLabelSelector(f.Labels...); err == nil && len(labels) > 0 {
+		spec["labels"] = labels
+	}
+	if f.Instances > 0 {
+		spec["instances"] = f.Instances
+	}
+	if f.Retries > 0 {
+		spec["retry_count"] = f.Retries
+	}
+	if f.RetryInterval > 0 {
+		spec["retry_interval"] = duration(f.RetryInterval)
+	}
+	if f.StartDuration > 0 {
+		spec["start_duration"] = duration(f.StartDuration)
+	}
+	if f.RestartInterval > 0 {
+		spec["restart_interval"] = duration(f.RestartInterval)
+	}
+	return spec
+}
 
-	return apiClient.RegisterProcess(spec)
+// validateRegisterFlags checks the optional register flags that cobra
+// cannot validate itself.
+func validateRegisterFlags(f RegisterFlags) error {
+	for _, kv := range f.Env {
+		if k, _, ok := strings.Cut(kv, "="); !ok || k == "" {
+			return fmt.Errorf("invalid --env %q: expected KEY=VALUE", kv)
+		}
+	}
+	if _, err := provisr.ParseLabelSelector(f.Labels...); err != nil {
+		return err
+	}
+	if f.Instances < 0 {
+		return fmt.Errorf("--instances must not be negative")
+	}
+	return nil
 }
 
 // registerLocally creates a program file in the programs directory
@@ -172,19 +487,6 @@ func (c *command) registerLocally(f RegisterFlags, configPath string) error {
 		return fmt.Errorf("failed to create programs directory: %w", err)
 	}
 
-	// Create process spec
-	spec := provisr.Spec{
-		Name:        f.Name,
-		Command:     f.Command,
-		WorkDir:     f.WorkDir,
-		AutoRestart: f.AutoStart, // AutoStart maps to AutoRestart in Spec
-	}
-
-	// Add log configuration if provided
-	if f.LogDir != "" {
-		spec.Log.File.Dir = f.LogDir
-	}
-
 	// Create program file as JSON
 	programFile := filepath.Join(programsDir, f.Name+".json")
 
@@ -193,21 +495,9 @@ func (c *command) registerLocally(f RegisterFlags, configPath string) error {
 		return fmt.Errorf("process '%s' is already registered", f.Name)
 	}
 
-	// Convert spec to JSON-friendly format
-	specData := map[string]interface{}{
-		"name":         spec.Name,
-		"command":      spec.Command,
-		"work_dir":     spec.WorkDir,
-		"auto_restart": spec.AutoRestart,
-	}
-
-	if f.LogDir != "" {
-		specData["log"] = map[string]interface{}{
-			"file": map[string]interface{}{
-				"dir": f.LogDir,
-			},
-		}
-	}
+	// Program files are read by config.LoadConfig, which takes durations
+	// as strings such as "5s"
+	specData := registerSpecData(f, func(d time.Duration) interface{} { return d.String() })
 
 	// Wrap in the {type, spec} discriminated-union shape the daemon's
 	// loadProgramEntries actually expects (the same shape as inline
@@ -266,18 +556,8 @@ func (c *command) unregisterLocally(f UnregisterFlags, configPath string) error
 	}
 
 	// Find and remove program file
-	extensions := []string{".json", ".toml", ".yaml", ".yml"}
-	var foundFile string
-
-	for _, ext := range extensions {
-		programFile := filepath.Join(programsDir, f.Name+ext)
-		if _, err := os.Stat(programFile); err == nil {
-			foundFile = programFile
-			break
-		}
-	}
-
-	if foundFile == "" {
+	foundFile, ok := findProgramFile(programsDir, f.Name)
+	if !ok {
 		return fmt.Errorf("process '%s' is not registered", f.Name)
 	}
 
@@ -289,7 +569,7 @@ func (c *command) unregisterLocally(f UnregisterFlags, configPath string) error
 	return nil
 }
 
-// RegisterFile registers a process from an existing JSON file
+// RegisterFile registers a process from an existing JSON, YAML or TOML file
 func (c *command) RegisterFile(f RegisterFileFlags, configPath string) error {
 	if f.APIUrl != "" {
 		apiClient := NewAPIClient(f.APIUrl, f.APITimeout)
@@ -305,7 +585,7 @@ func (c *command) RegisterFile(f RegisterFileFlags, configPath string) error {
 
 // registerFileViaAPI registers a process from file via the daemon API
 func (c *command) registerFileViaAPI(f RegisterFileFlags, apiClient *APIClient) error {
-	// Read and parse the JSON file
+	// Read and parse the file
 	spec, err := c.parseProcessFile(f.FilePath)
 	if err != nil {
 		return err
@@ -314,11 +594,12 @@ func (c *command) registerFileViaAPI(f RegisterFileFlags, apiClient *APIClient)
 	return apiClient.RegisterProcess(spec)
 }
 
-// registerFileLocally validates a JSON file and writes it (wrapped in the
-// {type, spec} shape the daemon's loadProgramEntries expects) to the
-// programs directory.
+// registerFileLocally validates a program file and writes it (wrapped in
+// the {type, spec} shape the daemon's loadProgramEntries expects) to the
+// programs directory, in the same format: a .yaml, .yml or .toml file stays
+// one, anything else is written as JSON.
 func (c *command) registerFileLocally(f RegisterFileFlags, configPath string) error {
-	// Validate and parse the JSON file first
+	// Validate and parse the file first
 	spec, err := c.parseProcessFile(f.FilePath)
 	if err != nil {
 		return err
@@ -327,7 +608,7 @@ func (c *command) registerFileLocally(f RegisterFileFlags, configPath string) er
 	// Extract process name from the parsed spec
 	processName, ok := spec["name"].(string)
 	if !ok || processName == "" {
-		return fmt.Errorf("process name is required in JSON file")
+		return fmt.Errorf("process name is required in the program file")
 	}
 
 	// Get programs directory
@@ -342,10 +623,11 @@ func (c *command) registerFileLocally(f RegisterFileFlags, configPath string) er
 	}
 
 	// Determine target file name
-	targetFile := filepath.Join(programsDir, processName+".json")
+	format := programFileFormatOf(f.FilePath)
+	targetFile := filepath.Join(programsDir, processName+format.ext)
 
-	// Check if process already exists
-	if _, err := os.Stat(targetFile); err == nil {
+	// Check if process already exists, in any format
+	if _, exists := findProgramFile(programsDir, processName); exists {
 		return fmt.Errorf("process '%s' is already registered", processName)
 	}
 
@@ -358,12 +640,12 @@ func (c *command) registerFileLocally(f RegisterFileFlags, configPath string) er
 		"type": "process",
 		"spec": spec,
 	}
-	jsonData, err := json.MarshalIndent(programData, "", "  ")
+	data, err := format.marshal(programData)
 	if err != nil {
 		return fmt.Errorf("failed to marshal program data: %w", err)
 	}
 
-	if err := os.WriteFile(targetFile, jsonData, 0o644); err != nil {
+	if err := os.WriteFile(targetFile, data, 0o644); err != nil {
 		return fmt.Errorf("failed to write program file: %w", err)
 	}
 
@@ -371,7 +653,8 @@ func (c *command) registerFileLocally(f RegisterFileFlags, configPath string) er
 	return nil
 }
 
-// parseProcessFile reads and validates a process configuration file
+// parseProcessFile reads and validates a process configuration file in
+// JSON, YAML or TOML, chosen by its extension (see programFileFormatOf)
 func (c *command) parseProcessFile(filePath string) (map[string]interface{}, error) {
 	// Check if file exists
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
@@ -384,10 +667,11 @@ func (c *command) parseProcessFile(filePath string) (map[string]interface{}, err
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Parse JSON
+	// Parse by extension
+	format := programFileFormatOf(filePath)
 	var spec map[string]interface{}
-	if err := json.Unmarshal(data, &spec); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON file: %w", err)
+	if err := format.unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s file: %w", format.name, err)
 	}
 
 	// Basic validation
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestCommand_GetProgramsDirectory(t *testing.T) {
@@ -271,3 +280,134 @@ func TestCommand_RegisterFileLocally_Integration(t *testing.T) {
 		t.Errorf("expected name 'file-test-app', got %v", copiedSpec["name"])
 	}
 }
+
+func TestStatusExitCodes(t *testing.T) {
+	mockServer := createMockAPIServer(map[string]string{
+		"GET:/api/status?name=web":  `{"name":"web","running":true,"state":"running"}`,
+		"GET:/api/status?name=idle": `{"name":"idle","running":false,"state":"stopped"}`,
+		"GET:/api/status?name=gone": `{"error":"process gone not found"}`,
+	}, map[string]int{"GET:/api/status?name=gone": http.StatusBadRequest})
+	defer mockServer.Close()
+	client := NewAPIClient(mockServer.URL+"/api", 5*time.Second)
+	cmd := &command{}
+
+	tests := []struct {
+		flags StatusFlags
+		code  int
+	}{
+		{StatusFlags{Name: "web", FailIfStopped: true}, exitRunning},
+		{StatusFlags{Name: "idle", FailIfStopped: true}, exitStopped},
+		{StatusFlags{Name: "gone", FailIfStopped: true}, exitNotFound},
+		{StatusFlags{Name: "idle"}, 0}, // plain queries succeed whatever the state
+		{StatusFlags{Name: "idle", WaitFor: "stopped"}, exitRunning},
+		{StatusFlags{Name: "idle", WaitFor: "running", WaitTimeout: 10 * time.Millisecond}, exitStopped},
+	}
+	for _, tt := range tests {
+		tt.flags.Output = "json"
+		err := cmd.statusViaAPI(tt.flags, client)
+		code := 0
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.code
+		} else if err != nil {
+			t.Fatalf("%+v: unexpected error %v", tt.flags, err)
+		}
+		if code != tt.code {
+			t.Errorf("%+v: exit code %d, want %d", tt.flags, code, tt.code)
+		}
+	}
+
+	if err := cmd.statusViaAPI(StatusFlags{FailIfStopped: true, Output: "json"}, client); err == nil {
+		t.Error("expected --fail-if-stopped without --name to fail")
+	}
+}
+
+func TestStatusWaitForRunning(t *testing.T) {
+	defer func(d time.Duration) { statusPollInterval = d }(statusPollInterval)
+	statusPollInterval = time.Millisecond
+
+	// Not registered, then stopped, then running.
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"process web not found"}`))
+		case 2:
+			_, _ = w.Write([]byte(`{"name":"web","running":false}`))
+		default:
+			_, _ = w.Write([]byte(`{"name":"web","running":true}`))
+		}
+	}))
+	defer srv.Close()
+
+	cmd := &command{}
+	err := cmd.statusViaAPI(StatusFlags{Name: "web", WaitFor: "running", WaitTimeout: 5 * time.Second, Output: "json"},
+		NewAPIClient(srv.URL+"/api", 5*time.Second))
+	if err != nil {
+		t.Fatalf("wait for running: %v", err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected 3 status queries, got %d", n)
+	}
+}
+
+func TestWatchStatusRedraws(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Running, then a failed query, then stopped; the watch stops after
+	// the third frame.
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			_, _ = w.Write([]byte(`{"name":"web","running":true,"state":"running"}`))
+		case 2:
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":"daemon restarting"}`))
+		default:
+			cancel()
+			_, _ = w.Write([]byte(`{"name":"web","running":false,"state":"stopped"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	f := StatusFlags{Name: "web", Output: "table", Watch: 5 * time.Millisecond}
+	if err := watchStatus(ctx, f, NewAPIClient(srv.URL+"/api", 5*time.Second), &out); err != nil {
+		t.Fatalf("watchStatus: %v", err)
+	}
+	frames := strings.Split(out.String(), "Every 5ms: provisr status")[1:]
+	if len(frames) != 3 {
+		t.Fatalf("got %d frames, want 3:\n%s", len(frames), out.String())
+	}
+	if !strings.Contains(frames[0], "running") || !strings.Contains(frames[1], "error:") || !strings.Contains(frames[2], "stopped") {
+		t.Fatalf("unexpected frames:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "\033[") {
+		t.Fatal("screen was cleared on output that is not a terminal")
+	}
+}
+
+func TestWatchStatusRejectsExitCodeFlags(t *testing.T) {
+	cmd := &command{}
+	err := cmd.WatchStatus(context.Background(), StatusFlags{Name: "web", FailIfStopped: true, Watch: time.Second}, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "--watch") {
+		t.Fatalf("expected --watch with --fail-if-stopped to fail, got %v", err)
+	}
+}
+
+func TestFitScreen(t *testing.T) {
+	text := "header\nNAME\na\nb\nc\nd\n"
+	if got := fitScreen(text, 0); got != text {
+		t.Fatalf("height 0 changed the text: %q", got)
+	}
+	if got := fitScreen(text, 6); got != text {
+		t.Fatalf("text that fits was changed: %q", got)
+	}
+	want := "header\nNAME\na\n... 3 more lines; narrow the list with --running, --label or --name\n"
+	if got := fitScreen(text, 4); got != want {
+		t.Fatalf("fitScreen(4) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// programFileExtensions are the program file extensions the daemon loads
+// from the programs directory, in the order a name is looked up.
+var programFileExtensions = []string{".json", ".toml", ".yaml", ".yml"}
+
+// programFileFormat reads and writes program files of one format.
+type programFileFormat struct {
+	name      string // for error messages
+	ext       string // of files written in this format
+	unmarshal func([]byte, any) error
+	marshal   func(any) ([]byte, error)
+}
+
+var (
+	jsonProgramFile = programFileFormat{
+		name:      "JSON",
+		ext:       ".json",
+		unmarshal: json.Unmarshal,
+		marshal:   func(v any) ([]byte, error) { return json.MarshalIndent(v, "", "  ") },
+	}
+	yamlProgramFile = programFileFormat{name: "YAML", ext: ".yaml", unmarshal: yaml.Unmarshal, marshal: yaml.Marshal}
+	tomlProgramFile = programFileFormat{name: "TOML", ext: ".toml", unmarshal: toml.Unmarshal, marshal: toml.Marshal}
+)
+
+// programFileFormatOf picks the format of path by its extension. Files
+// that are not .yaml, .yml or .toml are JSON, as register-file always
+// read them. A .yml file keeps its extension.
+func programFileFormatOf(path string) programFileFormat {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		f := yamlProgramFile
+		f.ext = ext
+		return f
+	case ".toml":
+		return tomlProgramFile
+	}
+	return jsonProgramFile
+}
+
+// findProgramFile returns the program file of name in programsDir, under
+// any of programFileExtensions.
+func findProgramFile(programsDir, name string) (string, bool) {
+	for _, ext := range programFileExtensions {
+		path := filepath.Join(programsDir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
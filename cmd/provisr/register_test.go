@@ -6,7 +6,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/loykin/provisr"
 	"github.com/loykin/provisr/internal/config"
 )
 
@@ -41,6 +43,21 @@ func TestCommand_RegisterLocally(t *testing.T) {
 			expectFile: "test-process.json",
 			expectErr:  false,
 		},
+		{
+			name: "registration_with_spec_fields",
+			flags: RegisterFlags{
+				Name:            "tuned-process",
+				Command:         "sleep 10",
+				Env:             []string{"A=1", "B=two"},
+				Instances:       3,
+				Retries:         5,
+				RetryInterval:   2 * time.Second,
+				StartDuration:   500 * time.Millisecond,
+				RestartInterval: time.Minute,
+			},
+			expectFile: "tuned-process.json",
+			expectErr:  false,
+		},
 		{
 			name: "registration_without_optional_fields",
 			flags: RegisterFlags{
@@ -111,6 +128,28 @@ func TestCommand_RegisterLocally(t *testing.T) {
 				t.Errorf("expected work_dir %q, got %q", tt.flags.WorkDir, programData["work_dir"])
 			}
 
+			// Optional spec fields are written only when given, durations
+			// as strings
+			if env, _ := json.Marshal(programData["env"]); len(tt.flags.Env) > 0 && string(env) != `["A=1","B=two"]` ||
+				len(tt.flags.Env) == 0 && string(env) != "null" {
+				t.Errorf("unexpected env %s", env)
+			}
+			optional := map[string]interface{}{}
+			if tt.flags.Instances > 0 {
+				optional["instances"] = float64(tt.flags.Instances)
+				optional["retry_count"] = float64(tt.flags.Retries)
+				optional["retry_interval"] = tt.flags.RetryInterval.String()
+				optional["start_duration"] = tt.flags.StartDuration.String()
+				optional["restart_interval"] = tt.flags.RestartInterval.String()
+			}
+			for _, key := range []string{"instances", "retry_count", "retry_interval", "start_duration", "restart_interval"} {
+				want, set := optional[key]
+				got, exists := programData[key]
+				if exists != set || got != want {
+					t.Errorf("%s: got %v (present %v), want %v (present %v)", key, got, exists, want, set)
+				}
+			}
+
 			// Validate log configuration if provided
 			if tt.flags.LogDir != "" {
 				logConfig, exists := programData["log"]
@@ -718,9 +757,12 @@ func TestCommand_RegisterLocally_LoadsViaConfig(t *testing.T) {
 
 	cmd := &command{mgr: nil}
 	flags := RegisterFlags{
-		Name:    "roundtrip-process",
-		Command: "echo roundtrip",
-		WorkDir: "/app",
+		Name:          "roundtrip-process",
+		Command:       "echo roundtrip",
+		WorkDir:       "/app",
+		Env:           []string{"MODE=test"},
+		Retries:       2,
+		RetryInterval: 1500 * time.Millisecond,
 	}
 	if err := cmd.registerLocally(flags, ""); err != nil {
 		t.Fatalf("registerLocally failed: %v", err)
@@ -746,9 +788,133 @@ func TestCommand_RegisterLocally_LoadsViaConfig(t *testing.T) {
 			if spec.WorkDir != "/app" {
 				t.Errorf("expected work_dir %q, got %q", "/app", spec.WorkDir)
 			}
+			if len(spec.Env) != 1 || spec.Env[0] != "MODE=test" || spec.RetryCount != 2 || spec.RetryInterval != 1500*time.Millisecond {
+				t.Errorf("spec fields not loaded: env %v, retries %d, retry interval %s", spec.Env, spec.RetryCount, spec.RetryInterval)
+			}
 		}
 	}
 	if !found {
 		t.Error("expected roundtrip-process to be loaded from the CLI-registered program file")
 	}
 }
+
+func TestCommand_Register_InvalidFlags(t *testing.T) {
+	cmd := &command{mgr: nil}
+	for _, f := range []RegisterFlags{
+		{Name: "p", Command: "true", Env: []string{"NOVALUE"}},
+		{Name: "p", Command: "true", Env: []string{"=x"}},
+		{Name: "p", Command: "true", Instances: -1},
+	} {
+		if err := cmd.Register(f, ""); err == nil {
+			t.Errorf("expected %+v to be rejected", f)
+		}
+	}
+}
+
+func TestRegisterSpecData_APIDurations(t *testing.T) {
+	spec := registerSpecData(RegisterFlags{Name: "p", Command: "true", RestartInterval: 3 * time.Second},
+		func(d time.Duration) interface{} { return int64(d) })
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded provisr.Spec
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("API spec does not decode: %v", err)
+	}
+	if decoded.RestartInterval != 3*time.Second {
+		t.Fatalf("restart interval: got %s", decoded.RestartInterval)
+	}
+}
+
+func TestCommand_RegisterFileLocally_YAMLAndTOML(t *testing.T) {
+	tempDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(originalWd) }()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+
+	cmd := &command{mgr: nil}
+	sources := map[string]string{
+		"yaml-app.yml": `name: yaml-app
+command: echo yaml
+work_dir: /app
+auto_restart: true
+env:
+  - MODE=yaml
+retry_count: 2
+log:
+  dir: /var/log/yaml
+`,
+		"toml-app.toml": `name = "toml-app"
+command = "echo toml"
+work_dir = "/app"
+auto_restart = true
+env = ["MODE=toml"]
+retry_count = 2
+
+[log]
+dir = "/var/log/toml"
+`,
+	}
+	for file, content := range sources {
+		path := filepath.Join(tempDir, file)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to create %s: %v", file, err)
+		}
+		if err := cmd.registerFileLocally(RegisterFileFlags{FilePath: path}, ""); err != nil {
+			t.Fatalf("registerFileLocally(%s) failed: %v", file, err)
+		}
+		// The program file keeps the source's format.
+		if _, err := os.Stat(filepath.Join(tempDir, "programs", file)); err != nil {
+			t.Fatalf("expected programs/%s: %v", file, err)
+		}
+	}
+
+	// A name registered in one format is taken in every other.
+	dup := filepath.Join(tempDir, "dup.json")
+	if err := os.WriteFile(dup, []byte(`{"name": "yaml-app", "command": "echo json"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.registerFileLocally(RegisterFileFlags{FilePath: dup}, ""); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Fatalf("expected 'already registered' for a name taken by a YAML program, got %v", err)
+	}
+
+	bad := filepath.Join(tempDir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("name: [unclosed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmd.parseProcessFile(bad); err == nil || !strings.Contains(err.Error(), "failed to parse YAML file") {
+		t.Fatalf("expected a YAML parse error, got %v", err)
+	}
+
+	configPath := filepath.Join(tempDir, "config.toml")
+	if err := os.WriteFile(configPath, []byte(`pid_dir = "run"`+"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config.toml: %v", err)
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("config.LoadConfig failed to load the registered program files: %v", err)
+	}
+	loaded := make(map[string]provisr.Spec)
+	for _, spec := range cfg.Specs {
+		loaded[spec.Name] = spec
+	}
+	for _, format := range []string{"yaml", "toml"} {
+		spec, ok := loaded[format+"-app"]
+		if !ok {
+			t.Errorf("%s-app was not loaded", format)
+			continue
+		}
+		if spec.Command != "echo "+format || spec.WorkDir != "/app" || !spec.AutoRestart || spec.RetryCount != 2 {
+			t.Errorf("%s-app fields not loaded: %+v", format, spec)
+		}
+		if len(spec.Env) != 1 || spec.Env[0] != "MODE="+format {
+			t.Errorf("%s-app env = %v", format, spec.Env)
+		}
+		if spec.Log.File.Dir != "/var/log/"+format {
+			t.Errorf("%s-app log dir = %q", format, spec.Log.File.Dir)
+		}
+	}
+}
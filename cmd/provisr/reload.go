@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/loykin/provisr"
+)
+
+// configReloader re-reads the config file of a running daemon (on SIGHUP)
+// and applies it: processes in newly added program files or inline blocks
+// start, removed ones stop, and cron jobs are added or deleted the same way.
+// Processes and cron jobs that stay keep running unchanged, and so do
+// processes the config never declared: job instances and processes
+// registered through the API.
+type configReloader struct {
+	path string
+	mgr  *provisr.Manager
+	cron *provisr.CronScheduler
+	// processes and cronJobs hold the process (base) names and cron jobs
+	// from the last load; only those are removed when they disappear,
+	// never ones created through the API.
+	processes map[string]struct{}
+	cronJobs  map[string]struct{}
+}
+
+func newConfigReloader(path string, mgr *provisr.Manager, cron *provisr.CronScheduler, cfg *provisr.LoadedConfig) *configReloader {
+	r := &configReloader{path: path, mgr: mgr, cron: cron, processes: declaredProcesses(cfg), cronJobs: make(map[string]struct{})}
+	for _, j := range cfg.CronJobs {
+		r.cronJobs[j.Name] = struct{}{}
+	}
+	return r
+}
+
+// declaredProcesses returns the names of the processes cfg declares.
+func declaredProcesses(cfg *provisr.LoadedConfig) map[string]struct{} {
+	names := make(map[string]struct{}, len(cfg.Specs))
+	for _, s := range cfg.Specs {
+		names[s.Name] = struct{}{}
+	}
+	return names
+}
+
+// keeper reports the registered processes a reload must leave running even
+// though the new config doesn't declare them: those launched for jobs and
+// cron jobs, and those the previous config didn't declare either.
+func (r *configReloader) keeper() func(name string) bool {
+	jobProcesses := make(map[string]struct{})
+	for _, name := range r.cron.JobManager().ProcessNames() {
+		jobProcesses[name] = struct{}{}
+	}
+	return func(name string) bool {
+		if _, ok := jobProcesses[name]; ok {
+			return true
+		}
+		base, err := r.mgr.ProcessBase(name)
+		if err != nil {
+			base = name
+		}
+		_, declared := r.processes[base]
+		return !declared
+	}
+}
+
+// reload applies the config file as it is now and returns what happened to
+// the processes. A config that fails to load changes nothing.
+func (r *configReloader) reload() (provisr.ReconcileResult, error) {
+	cfg, err := provisr.LoadConfig(r.path)
+	if err != nil {
+		return provisr.ReconcileResult{}, fmt.Errorf("error loading config: %w", err)
+	}
+	r.mgr.SetInstanceGroups(managerGroups(cfg))
+	result, err := r.mgr.ReconcileWithOptions(cfg.Specs, provisr.ApplyOptions{Keep: r.keeper()})
+	if err != nil {
+		return result, fmt.Errorf("failed to apply config: %w", err)
+	}
+	r.processes = declaredProcesses(cfg)
+
+	desired := make(map[string]struct{}, len(cfg.CronJobs))
+	for _, j := range cfg.CronJobs {
+		desired[j.Name] = struct{}{}
+		if _, ok := r.cron.Get(j.Name); ok {
+			continue
+		}
+		if err := r.cron.Add(provisr.CronJob(j)); err != nil {
+			return result, fmt.Errorf("failed to add cron job %s: %w", j.Name, err)
+		}
+	}
+	for name := range r.cronJobs {
+		if _, ok := desired[name]; ok {
+			continue
+		}
+		if err := r.cron.Delete(name); err != nil {
+			return result, fmt.Errorf("failed to delete cron job %s: %w", name, err)
+		}
+	}
+	r.cronJobs = desired
+	return result, nil
+}
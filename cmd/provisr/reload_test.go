@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr"
+)
+
+func TestConfigReloaderFollowsProgramsDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	programsDir := filepath.Join(dir, "programs")
+	if err := os.WriteFile(configPath, []byte(`
+[[processes]]
+type = "process"
+[processes.spec]
+name = "inline"
+command = "sleep 30"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	createProgramFiles(t, programsDir, map[string]string{
+		"old.toml":  "type = \"process\"\n[spec]\nname = \"old\"\ncommand = \"sleep 30\"\n",
+		"tick.toml": "type = \"cronjob\"\n[spec]\nname = \"tick\"\nschedule = \"@every 1h\"\n[spec.job_template]\nname = \"tick\"\ncommand = \"true\"\n",
+	})
+
+	cfg, err := provisr.LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := provisr.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	cron := provisr.NewCronScheduler(provisr.NewJobManager(mgr))
+	t.Cleanup(func() { _ = cron.Stop() })
+	if err := mgr.ApplyConfig(cfg.Specs); err != nil {
+		t.Fatal(err)
+	}
+	for _, j := range cfg.CronJobs {
+		if err := cron.Add(provisr.CronJob(j)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reloader := newConfigReloader(configPath, mgr, cron, cfg)
+
+	// Swap a program file and drop the cron job, then reload.
+	if err := os.Remove(filepath.Join(programsDir, "old.toml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(programsDir, "tick.toml")); err != nil {
+		t.Fatal(err)
+	}
+	createProgramFiles(t, programsDir, map[string]string{
+		"new.toml": "type = \"process\"\n[spec]\nname = \"new\"\ncommand = \"sleep 30\"\n",
+	})
+	result, err := reloader.reload()
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !reflect.DeepEqual(result.Started, []string{"new"}) || !slices.Contains(result.Stopped, "old") {
+		t.Errorf("reload result = %s, want new started and old stopped", result)
+	}
+	for name, want := range map[string]bool{"inline": true, "new": true, "old": false} {
+		_, err := mgr.Status(name)
+		if registered := err == nil; registered != want {
+			t.Errorf("%s registered = %v, want %v", name, registered, want)
+		}
+	}
+	if _, ok := cron.Get("tick"); ok {
+		t.Error("removed cron job is still scheduled")
+	}
+
+	// A conflicting file is rejected and leaves everything as it was.
+	createProgramFiles(t, programsDir, map[string]string{
+		"dup.toml": "type = \"process\"\n[spec]\nname = \"inline\"\ncommand = \"sleep 30\"\n",
+	})
+	if _, err := reloader.reload(); err == nil {
+		t.Fatal("expected the name conflict to fail the reload")
+	}
+	if _, err := mgr.Status("new"); err != nil {
+		t.Fatalf("failed reload changed the running set: %v", err)
+	}
+}
+
+func TestConfigReloaderKeepsUndeclaredProcesses(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(configPath, []byte(`
+[[processes]]
+type = "process"
+[processes.spec]
+name = "inline"
+command = "sleep 30"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := provisr.LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := provisr.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	cron := provisr.NewCronScheduler(provisr.NewJobManager(mgr))
+	t.Cleanup(func() { _ = cron.Stop() })
+	if err := mgr.ApplyConfig(cfg.Specs); err != nil {
+		t.Fatal(err)
+	}
+	reloader := newConfigReloader(configPath, mgr, cron, cfg)
+
+	// A process registered through the API and a running job instance.
+	if err := mgr.RegisterN(provisr.Spec{Name: "adhoc", Command: "sleep 30"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cron.JobManager().CreateJob(provisr.JobSpec{Name: "batch", Command: "sleep 30"}); err != nil {
+		t.Fatal(err)
+	}
+	var jobProcess string
+	deadline := time.Now().Add(5 * time.Second)
+	for jobProcess == "" {
+		if names := cron.JobManager().ProcessNames(); len(names) > 0 {
+			if st, err := mgr.Status(names[0]); err == nil && st.Running {
+				jobProcess = names[0]
+			}
+		}
+		if jobProcess == "" && time.Now().After(deadline) {
+			t.Fatal("job process did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Dropping the inline process stops only that one.
+	if err := os.WriteFile(configPath, []byte("\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := reloader.reload()
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !reflect.DeepEqual(result.Stopped, []string{"inline"}) {
+		t.Errorf("reload stopped %v, want only inline", result.Stopped)
+	}
+	for _, name := range []string{"adhoc", jobProcess} {
+		if st, err := mgr.Status(name); err != nil || !st.Running {
+			t.Errorf("%s not running after reload: %+v, %v", name, st, err)
+		}
+	}
+}
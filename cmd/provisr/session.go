@@ -2,25 +2,47 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 )
 
+// defaultContextName names the context used before any other is created.
+const defaultContextName = "default"
+
 // Session represents a user session
 type Session struct {
-	Token     string    `json:"token"`
-	TokenType string    `json:"token_type"`
-	ExpiresAt time.Time `json:"expires_at"`
-	Username  string    `json:"username"`
-	UserID    string    `json:"user_id"`
-	Roles     []string  `json:"roles"`
-	ServerURL string    `json:"server_url"`
+	Token        string    `json:"token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Username     string    `json:"username"`
+	UserID       string    `json:"user_id"`
+	Roles        []string  `json:"roles"`
+	ServerURL    string    `json:"server_url"`
+}
+
+// expired reports whether the session's token can no longer be used.
+func (s *Session) expired() bool {
+	return s.Token == "" || time.Now().After(s.ExpiresAt)
 }
 
-// SessionManager handles session storage and retrieval
+// contextsFile is the on-disk form of the saved contexts: one session per
+// named daemon, like kubectl contexts.
+type contextsFile struct {
+	CurrentContext string              `json:"current_context"`
+	Contexts       map[string]*Session `json:"contexts"`
+}
+
+// SessionManager handles session storage and retrieval. Sessions are kept
+// per context; the manager works on the context it was created for, else
+// on $PROVISR_CONTEXT, else on the current context.
 type SessionManager struct {
 	sessionPath string
+	legacyPath  string
+	context     string
 }
 
 // NewSessionManager creates a new session manager
@@ -35,50 +57,140 @@ func NewSessionManager() *SessionManager {
 	_ = os.MkdirAll(sessionDir, 0o700) // Create directory if it doesn't exist
 
 	return &SessionManager{
-		sessionPath: filepath.Join(sessionDir, "session.json"),
+		sessionPath: filepath.Join(sessionDir, "contexts.json"),
+		legacyPath:  filepath.Join(sessionDir, "session.json"),
 	}
 }
 
-// SaveSession saves a session to disk
-func (sm *SessionManager) SaveSession(session *Session) error {
-	data, err := json.MarshalIndent(session, "", "  ")
+// WithContext returns a manager working on the named context; an empty
+// name keeps the default selection.
+func (sm *SessionManager) WithContext(name string) *SessionManager {
+	c := *sm
+	c.context = name
+	return &c
+}
+
+// load reads the contexts file. A session.json left by an older version
+// is read as the default context.
+func (sm *SessionManager) load() (*contextsFile, error) {
+	file := &contextsFile{Contexts: map[string]*Session{}}
+	data, err := os.ReadFile(sm.sessionPath)
+	if os.IsNotExist(err) {
+		legacy, legacyErr := os.ReadFile(sm.legacyPath)
+		if os.IsNotExist(legacyErr) {
+			return file, nil
+		}
+		if legacyErr != nil {
+			return nil, legacyErr
+		}
+		var session Session
+		if err := json.Unmarshal(legacy, &session); err != nil {
+			return nil, err
+		}
+		file.CurrentContext = defaultContextName
+		file.Contexts[defaultContextName] = &session
+		return file, nil
+	}
 	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", sm.sessionPath, err)
+	}
+	if file.Contexts == nil {
+		file.Contexts = map[string]*Session{}
+	}
+	return file, nil
+}
+
+// save writes the contexts file readable by its owner only and drops any
+// legacy session.json, whose session it now holds.
+func (sm *SessionManager) save(file *contextsFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := sm.sessionPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file; tighten it either way.
+	if err := os.Chmod(tmp, 0o600); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, sm.sessionPath); err != nil {
+		_ = os.Remove(tmp)
 		return err
 	}
+	if err := os.Remove(sm.legacyPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
 
-	return os.WriteFile(sm.sessionPath, data, 0o600) // Only user can read/write
+// contextName is the context the manager works on.
+func (sm *SessionManager) contextName(file *contextsFile) string {
+	if sm.context != "" {
+		return sm.context
+	}
+	if name := os.Getenv("PROVISR_CONTEXT"); name != "" {
+		return name
+	}
+	if file.CurrentContext != "" {
+		return file.CurrentContext
+	}
+	return defaultContextName
 }
 
-// LoadSession loads a session from disk
-func (sm *SessionManager) LoadSession() (*Session, error) {
-	data, err := os.ReadFile(sm.sessionPath)
+// SaveSession saves a session to disk under the manager's context. The
+// first context saved becomes the current one.
+func (sm *SessionManager) SaveSession(session *Session) error {
+	file, err := sm.load()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // No session exists
-		}
-		return nil, err
+		return err
+	}
+	name := sm.contextName(file)
+	file.Contexts[name] = session
+	if file.CurrentContext == "" {
+		file.CurrentContext = name
 	}
+	return sm.save(file)
+}
 
-	var session Session
-	if err := json.Unmarshal(data, &session); err != nil {
+// LoadContext returns the saved session of the manager's context, expired
+// or not, or nil when the context does not exist.
+func (sm *SessionManager) LoadContext() (*Session, error) {
+	file, err := sm.load()
+	if err != nil {
 		return nil, err
 	}
+	return file.Contexts[sm.contextName(file)], nil
+}
 
-	// Check if session is expired
-	if time.Now().After(session.ExpiresAt) {
-		_ = sm.ClearSession() // Remove expired session
-		return nil, nil
+// LoadSession loads the session of the manager's context, or nil when it
+// has none or its token has expired.
+func (sm *SessionManager) LoadSession() (*Session, error) {
+	session, err := sm.LoadContext()
+	if err != nil || session == nil || session.expired() {
+		return nil, err
 	}
-
-	return &session, nil
+	return session, nil
 }
 
-// ClearSession removes the session file
+// ClearSession forgets the token of the manager's context and keeps its
+// server URL, so a later login can reuse it.
 func (sm *SessionManager) ClearSession() error {
-	if err := os.Remove(sm.sessionPath); err != nil && !os.IsNotExist(err) {
+	file, err := sm.load()
+	if err != nil {
 		return err
 	}
-	return nil
+	session := file.Contexts[sm.contextName(file)]
+	if session == nil {
+		return nil
+	}
+	file.Contexts[sm.contextName(file)] = &Session{ServerURL: session.ServerURL}
+	return sm.save(file)
 }
 
 // IsLoggedIn checks if there's a valid session
@@ -91,3 +203,64 @@ func (sm *SessionManager) IsLoggedIn() bool {
 func (sm *SessionManager) GetSessionPath() string {
 	return sm.sessionPath
 }
+
+// ContextInfo describes one saved context.
+type ContextInfo struct {
+	Name      string    `json:"name"`
+	Current   bool      `json:"current"`
+	ServerURL string    `json:"server_url"`
+	Username  string    `json:"username,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	LoggedIn  bool      `json:"logged_in"`
+}
+
+// Contexts lists the saved contexts by name.
+func (sm *SessionManager) Contexts() ([]ContextInfo, error) {
+	file, err := sm.load()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]ContextInfo, 0, len(file.Contexts))
+	for name, session := range file.Contexts {
+		infos = append(infos, ContextInfo{
+			Name:      name,
+			Current:   name == file.CurrentContext,
+			ServerURL: session.ServerURL,
+			Username:  session.Username,
+			ExpiresAt: session.ExpiresAt,
+			LoggedIn:  !session.expired(),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// UseContext makes the named context the current one.
+func (sm *SessionManager) UseContext(name string) error {
+	file, err := sm.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Contexts[name]; !ok {
+		return fmt.Errorf("context %q not found", name)
+	}
+	file.CurrentContext = name
+	return sm.save(file)
+}
+
+// DeleteContext removes the named context. Deleting the current context
+// leaves none current until another is used or saved.
+func (sm *SessionManager) DeleteContext(name string) error {
+	file, err := sm.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Contexts[name]; !ok {
+		return fmt.Errorf("context %q not found", name)
+	}
+	delete(file.Contexts, name)
+	if file.CurrentContext == name {
+		file.CurrentContext = ""
+	}
+	return sm.save(file)
+}
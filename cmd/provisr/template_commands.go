@@ -2,19 +2,48 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"text/tabwriter"
 
 	"github.com/loykin/provisr/pkg/template"
 )
 
-// getTemplatesDirectory returns the templates directory path
+// getTemplatesDirectory returns the templates directory path: custom
+// templates are read from it and new templates written to it. It is
+// $PROVISR_TEMPLATES when set.
 func (c *command) getTemplatesDirectory() string {
+	if dir := os.Getenv("PROVISR_TEMPLATES"); dir != "" {
+		return dir
+	}
 	return "templates"
 }
 
+// TemplateList prints the custom templates of the templates directory and
+// the built-in types.
+func (c *command) TemplateList(out io.Writer) error {
+	templates, err := template.NewDirGenerator(c.getTemplatesDirectory()).ListTemplates()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TYPE\tSOURCE")
+	for _, t := range templates {
+		source := t.Path
+		if source == "" {
+			source = "built-in"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", t.Type, source)
+	}
+	return tw.Flush()
+}
+
 // TemplateCreate creates a new process template
 func (c *command) TemplateCreate(f TemplateCreateFlags) error {
+	if f.Type == "" {
+		return fmt.Errorf("template type is required (see provisr template --list)")
+	}
 	// Use provided name or default based on type
 	templateName := f.Name
 	if templateName == "" {
@@ -37,7 +66,7 @@ func (c *command) TemplateCreate(f TemplateCreateFlags) error {
 	}
 
 	// Generate template content based on type
-	generator := template.NewGenerator()
+	generator := template.NewDirGenerator(c.getTemplatesDirectory())
 	templateContent, err := generator.GenerateJSON(template.TemplateType(f.Type), templateName)
 	if err != nil {
 		return fmt.Errorf("failed to generate template: %w", err)
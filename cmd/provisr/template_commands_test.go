@@ -320,3 +320,41 @@ func TestCommand_TemplateCreate_CustomOutputDirectory(t *testing.T) {
 		t.Error("custom template file should have been created")
 	}
 }
+
+func TestCommand_TemplateFromCustomDirectory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PROVISR_TEMPLATES", dir)
+	if err := os.WriteFile(filepath.Join(dir, "edge.tmpl.json"), []byte(`{"command": "./{{.Name}}"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &command{mgr: nil}
+
+	if err := cmd.TemplateCreate(TemplateCreateFlags{Type: "edge", Name: "gateway"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "gateway.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"./gateway"`) {
+		t.Fatalf("unexpected template output: %s", data)
+	}
+
+	var out strings.Builder
+	if err := cmd.TemplateList(&out); err != nil {
+		t.Fatal(err)
+	}
+	sources := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			sources[fields[0]] = fields[1]
+		}
+	}
+	if sources["edge"] != filepath.Join(dir, "edge.tmpl.json") || sources["web"] != "built-in" {
+		t.Fatalf("unexpected list:\n%s", out.String())
+	}
+	// The generated spec is not offered as a template.
+	if _, ok := sources["gateway"]; ok {
+		t.Fatalf("generated spec listed as a template:\n%s", out.String())
+	}
+}
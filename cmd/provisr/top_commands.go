@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/loykin/provisr"
+)
+
+// topSortKeys are the --sort values of top. Every key but name sorts the
+// largest first.
+var topSortKeys = []string{"cpu", "mem", "threads", "fds", "pid", "name"}
+
+// Top shows the processes of the daemon sorted by resource usage, redrawn
+// every f.Interval until ctx is done. It needs the daemon to collect
+// process metrics.
+func (c *command) Top(ctx context.Context, f TopFlags, out io.Writer) error {
+	if err := checkTopFlags(f); err != nil {
+		return err
+	}
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return err
+	}
+	return top(ctx, f, apiClient, out)
+}
+
+func checkTopFlags(f TopFlags) error {
+	if f.Interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	if !slices.Contains(topSortKeys, f.Sort) {
+		return fmt.Errorf("invalid --sort %q: must be one of %s", f.Sort, strings.Join(topSortKeys, ", "))
+	}
+	if _, err := path.Match(f.Filter, ""); err != nil {
+		return fmt.Errorf("invalid --filter %q: %w", f.Filter, err)
+	}
+	return nil
+}
+
+// top draws one frame per f.Interval like watchStatus. Disabled metrics
+// end it with a hint on how to enable them; other failed queries are shown
+// in the frame.
+func top(ctx context.Context, f TopFlags, apiClient *APIClient, out io.Writer) error {
+	tty := isTerminal(out)
+	ticker := time.NewTicker(f.Interval)
+	defer ticker.Stop()
+	for {
+		var frame bytes.Buffer
+		metrics, err := apiClient.ProcessMetrics()
+		if errors.Is(err, errMetricsDisabled) {
+			return fmt.Errorf("%w; enable it with 'enabled = true' under [metrics.process_metrics] in the daemon config", err)
+		}
+		if err != nil {
+			fmt.Fprintf(&frame, "provisr top    %s\n\nerror: %v\n", time.Now().Format(time.DateTime), err)
+		} else {
+			writeTop(&frame, f, metrics, time.Now())
+		}
+
+		text := frame.String()
+		if tty {
+			text = "\033[H\033[2J" + fitScreen(text, terminalHeight(out))
+		} else {
+			text += "\n"
+		}
+		if _, err := io.WriteString(out, text); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeTop renders the processes in metrics that match f.Filter as a
+// table sorted by f.Sort, under a header with their totals.
+func writeTop(w io.Writer, f TopFlags, metrics map[string]provisr.ProcessMetrics, now time.Time) {
+	type row struct {
+		name string
+		provisr.ProcessMetrics
+	}
+	var rows []row
+	var cpu, mem float64
+	for name, m := range metrics {
+		if ok, _ := path.Match(f.Filter, name); f.Filter != "" && !ok {
+			continue
+		}
+		rows = append(rows, row{name, m})
+		cpu += m.CPUPercent
+		mem += m.MemoryMB
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		var less, greater bool
+		switch f.Sort {
+		case "cpu":
+			less, greater = a.CPUPercent < b.CPUPercent, a.CPUPercent > b.CPUPercent
+		case "mem":
+			less, greater = a.MemoryMB < b.MemoryMB, a.MemoryMB > b.MemoryMB
+		case "threads":
+			less, greater = a.NumThreads < b.NumThreads, a.NumThreads > b.NumThreads
+		case "fds":
+			less, greater = a.NumFDs < b.NumFDs, a.NumFDs > b.NumFDs
+		case "pid":
+			less, greater = a.PID < b.PID, a.PID > b.PID
+		}
+		if greater || less {
+			return greater
+		}
+		return a.name < b.name
+	})
+
+	_, _ = fmt.Fprintf(w, "provisr top    %s    %d processes, %.1f%% CPU, %.1f MB, sorted by %s\n\n",
+		now.Format(time.DateTime), len(rows), cpu, mem, f.Sort)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tPID\tCPU%\tMEM(MB)\tTHREADS\tFDS")
+	for _, r := range rows {
+		fds := "-"
+		if r.NumFDs > 0 {
+			fds = strconv.Itoa(int(r.NumFDs))
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%.1f\t%.1f\t%d\t%s\n", r.name, r.PID, r.CPUPercent, r.MemoryMB, r.NumThreads, fds)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr"
+)
+
+func TestWriteTopSortsAndFilters(t *testing.T) {
+	metrics := map[string]provisr.ProcessMetrics{
+		"web-1":  {PID: 11, CPUPercent: 5, MemoryMB: 300, NumThreads: 8},
+		"web-2":  {PID: 12, CPUPercent: 40, MemoryMB: 100, NumThreads: 4},
+		"worker": {PID: 20, CPUPercent: 90, MemoryMB: 50, NumThreads: 2, NumFDs: 9},
+	}
+	names := func(f TopFlags) []string {
+		var out bytes.Buffer
+		writeTop(&out, f, metrics, time.Now())
+		var names []string
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n")[3:] {
+			names = append(names, strings.Fields(line)[0])
+		}
+		return names
+	}
+
+	for sortKey, want := range map[string]string{
+		"cpu":  "worker,web-2,web-1",
+		"mem":  "web-1,web-2,worker",
+		"pid":  "worker,web-2,web-1",
+		"name": "web-1,web-2,worker",
+	} {
+		if got := strings.Join(names(TopFlags{Sort: sortKey}), ","); got != want {
+			t.Errorf("--sort=%s: %s, want %s", sortKey, got, want)
+		}
+	}
+	if got := strings.Join(names(TopFlags{Sort: "cpu", Filter: "web-*"}), ","); got != "web-2,web-1" {
+		t.Errorf("--filter=web-*: %s, want web-2,web-1", got)
+	}
+
+	var out bytes.Buffer
+	writeTop(&out, TopFlags{Sort: "cpu", Filter: "web-*"}, metrics, time.Now())
+	if header := strings.SplitN(out.String(), "\n", 2)[0]; !strings.Contains(header, "2 processes, 45.0% CPU, 400.0 MB") {
+		t.Errorf("header %q does not total the shown processes", header)
+	}
+}
+
+func TestTopRefreshesAndReportsDisabledMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 2 {
+			cancel()
+		}
+		_, _ = w.Write([]byte(`{"web":{"pid":42,"cpu_percent":12.5,"memory_mb":64}}`))
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	f := TopFlags{Sort: "cpu", Interval: 5 * time.Millisecond}
+	if err := top(ctx, f, NewAPIClient(srv.URL+"/api", 5*time.Second), &out); err != nil {
+		t.Fatalf("top: %v", err)
+	}
+	if n := strings.Count(out.String(), "provisr top"); n != 2 {
+		t.Fatalf("got %d frames, want 2:\n%s", n, out.String())
+	}
+	if !strings.Contains(out.String(), "12.5") {
+		t.Fatalf("CPU usage missing from:\n%s", out.String())
+	}
+
+	disabled := createMockAPIServer(map[string]string{
+		"GET:/api/metrics": `{"error":"process metrics collection is disabled"}`,
+	}, map[string]int{"GET:/api/metrics": http.StatusServiceUnavailable})
+	defer disabled.Close()
+	err := top(context.Background(), f, NewAPIClient(disabled.URL+"/api", 5*time.Second), &out)
+	if err == nil || !strings.Contains(err.Error(), "[metrics.process_metrics]") {
+		t.Fatalf("expected a hint to enable process metrics, got %v", err)
+	}
+}
+
+func TestCheckTopFlags(t *testing.T) {
+	for _, f := range []TopFlags{
+		{Sort: "disk", Interval: time.Second},
+		{Sort: "cpu", Interval: 0},
+		{Sort: "cpu", Interval: time.Second, Filter: "web-["},
+	} {
+		if err := checkTopFlags(f); err == nil {
+			t.Errorf("%+v: expected an error", f)
+		}
+	}
+	if err := checkTopFlags(TopFlags{Sort: "mem", Interval: time.Second, Filter: "web-*"}); err != nil {
+		t.Errorf("valid flags rejected: %v", err)
+	}
+}
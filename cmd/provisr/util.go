@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/loykin/provisr"
+	apiwire "github.com/loykin/provisr/pkg/api"
 )
 
 func applyGlobalEnvFromFlags(mgr *provisr.Manager, useOSEnv bool, envKVs []string) {
@@ -102,14 +103,69 @@ func getUptime(st provisr.Status) string {
 		return "Unknown"
 	}
 
-	uptime := time.Since(st.StartedAt)
-	if uptime < time.Minute {
-		return fmt.Sprintf("%ds", int(uptime.Seconds()))
-	} else if uptime < time.Hour {
-		return fmt.Sprintf("%dm", int(uptime.Minutes()))
-	} else {
-		hours := int(uptime.Hours())
-		minutes := int(uptime.Minutes()) % 60
-		return fmt.Sprintf("%dh%dm", hours, minutes)
+	uptime := st.Uptime
+	if uptime <= 0 {
+		uptime = time.Since(st.StartedAt)
 	}
+	return apiwire.FormatUptime(uptime)
+}
+
+// humanizeUptime rewrites numeric "uptime" fields (nanoseconds) in decoded
+// /status JSON into apiwire.FormatUptime strings for CLI output.
+func humanizeUptime(v any) {
+	switch t := v.(type) {
+	case []any:
+		for _, e := range t {
+			humanizeUptime(e)
+		}
+	case map[string]any:
+		if ns, ok := t["uptime"].(float64); ok {
+			if ns > 0 {
+				t["uptime"] = apiwire.FormatUptime(time.Duration(ns))
+			} else {
+				t["uptime"] = "N/A"
+			}
+		}
+	}
+}
+
+// processStatusRows converts a decoded /status response, a single status or
+// a list, into the rows rendered by apiwire.WriteStatusRows.
+func processStatusRows(result any, detailed bool) ([]apiwire.StatusRow, error) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > 0 && raw[0] != '[' {
+		raw = append(append([]byte{'['}, raw...), ']')
+	}
+	if !detailed {
+		var rows []apiwire.StatusRow
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, fmt.Errorf("decode status: %w", err)
+		}
+		return rows, nil
+	}
+	var sts []apiwire.DetailedStatus
+	if err := json.Unmarshal(raw, &sts); err != nil {
+		return nil, fmt.Errorf("decode status: %w", err)
+	}
+	rows := make([]apiwire.StatusRow, len(sts))
+	for i, st := range sts {
+		rows[i] = st.Row()
+	}
+	return rows, nil
+}
+
+// groupStatusRows converts a decoded /group/status response into rows.
+func groupStatusRows(result any) ([]apiwire.StatusRow, error) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var members map[string][]apiwire.StatusRow
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, fmt.Errorf("decode group status: %w", err)
+	}
+	return apiwire.GroupRows(members), nil
 }
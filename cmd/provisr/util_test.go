@@ -219,3 +219,52 @@ func TestGetUptime(t *testing.T) {
 		t.Errorf("Expected hours:minutes format, got %s", uptime)
 	}
 }
+
+func TestHumanizeUptime(t *testing.T) {
+	decoded := []any{
+		map[string]any{"name": "a", "uptime": float64(3*time.Hour + 12*time.Minute)},
+		map[string]any{"name": "b", "uptime": float64(0)},
+	}
+	humanizeUptime(decoded)
+	if got := decoded[0].(map[string]any)["uptime"]; got != "3h12m" {
+		t.Errorf("expected 3h12m, got %v", got)
+	}
+	if got := decoded[1].(map[string]any)["uptime"]; got != "N/A" {
+		t.Errorf("expected N/A for a stopped process, got %v", got)
+	}
+
+	single := map[string]any{"uptime": float64(42 * time.Second)}
+	humanizeUptime(single)
+	if single["uptime"] != "42s" {
+		t.Errorf("expected 42s, got %v", single["uptime"])
+	}
+}
+
+func TestStatusRowsFromDecodedResponses(t *testing.T) {
+	single := map[string]any{"name": "a", "state": "running", "running": true, "pid": float64(7), "uptime": float64(42 * time.Second)}
+	rows, err := processStatusRows(single, false)
+	if err != nil || len(rows) != 1 || rows[0].PID != 7 || rows[0].Uptime != 42*time.Second {
+		t.Fatalf("single status: got %+v, %v", rows, err)
+	}
+	detailed := []any{map[string]any{"name": "b", "running": true, "uptime_seconds": float64(90)}}
+	rows, err = processStatusRows(detailed, true)
+	if err != nil || len(rows) != 1 || rows[0].Uptime != 90*time.Second {
+		t.Fatalf("detailed status: got %+v, %v", rows, err)
+	}
+	group := map[string]any{
+		"web": []any{map[string]any{"name": "web-1"}, map[string]any{"name": "web-2"}},
+		"api": []any{map[string]any{"name": "api"}},
+	}
+	rows, err = groupStatusRows(group)
+	if err != nil || len(rows) != 3 || rows[0].Name != "api" || rows[2].Name != "web-2" {
+		t.Fatalf("group status: got %+v, %v", rows, err)
+	}
+}
+
+func TestStatusViaAPIRejectsUnknownOutput(t *testing.T) {
+	cmd := &command{mgr: &provisr.Manager{}}
+	err := cmd.statusViaAPI(StatusFlags{Output: "xml"}, NewAPIClient("http://127.0.0.1:1/api", time.Second))
+	if err == nil || !strings.Contains(err.Error(), "invalid output format") {
+		t.Fatalf("expected invalid format error, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/loykin/provisr/internal/version"
+	apiwire "github.com/loykin/provisr/pkg/api"
+)
+
+// versionReport is what 'provisr version' prints: the CLI's build, and the
+// daemon's when it could be asked.
+type versionReport struct {
+	Client      apiwire.VersionInfo  `json:"client"`
+	Daemon      *apiwire.VersionInfo `json:"daemon,omitempty"`
+	DaemonError string               `json:"daemon_error,omitempty"`
+	Skewed      bool                 `json:"skewed"`
+}
+
+// Version prints the build information of the CLI and, unless f.Client is
+// set, of the daemon. An unreachable daemon is reported, not an error, so
+// the CLI's version can always be read.
+func (c *command) Version(f VersionFlags, out io.Writer) error {
+	format, err := parseOutputFormat(f.Output, out)
+	if err != nil {
+		return err
+	}
+	report := versionReport{Client: version.Get()}
+	if !f.Client {
+		// The report states any skew itself.
+		versionWarnOnce.Do(func() {})
+		daemon, err := c.daemonVersion(f)
+		if err != nil {
+			report.DaemonError = err.Error()
+		} else {
+			report.Daemon = &daemon
+			report.Skewed = version.Skewed(report.Client.Version, daemon.Version)
+		}
+	}
+	return writeOutput(out, format, report, func(w io.Writer, _ outputFormat) error {
+		_, _ = fmt.Fprintf(w, "Client: %s\n", formatVersion(report.Client))
+		switch {
+		case report.Daemon != nil:
+			_, _ = fmt.Fprintf(w, "Daemon: %s\n", formatVersion(*report.Daemon))
+		case report.DaemonError != "":
+			_, _ = fmt.Fprintf(w, "Daemon: unavailable (%s)\n", report.DaemonError)
+		}
+		if report.Skewed {
+			_, _ = fmt.Fprintln(w, "warning: the CLI and the daemon run different versions; upgrade one of them")
+		}
+		return nil
+	})
+}
+
+func (c *command) daemonVersion(f VersionFlags) (apiwire.VersionInfo, error) {
+	apiClient, err := c.daemonClient(f.APIUrl, f.APITimeout)
+	if err != nil {
+		return apiwire.VersionInfo{}, err
+	}
+	return apiClient.Version()
+}
+
+// formatVersion renders v on one line, e.g.
+// "v1.2.3 (commit 0a1b2c3, built 2026-01-02T03:04:05Z, go1.26.4)".
+func formatVersion(v apiwire.VersionInfo) string {
+	var details []string
+	if v.Commit != "" {
+		details = append(details, "commit "+v.Commit)
+	}
+	if v.BuildDate != "" {
+		details = append(details, "built "+v.BuildDate)
+	}
+	if v.GoVersion != "" {
+		details = append(details, v.GoVersion)
+	}
+	if len(details) == 0 {
+		return v.Version
+	}
+	return fmt.Sprintf("%s (%s)", v.Version, strings.Join(details, ", "))
+}
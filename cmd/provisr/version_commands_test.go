@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr/internal/version"
+	apiwire "github.com/loykin/provisr/pkg/api"
+)
+
+// withVersion sets the CLI's version for one test and resets the once-only
+// skew warning around it.
+func withVersion(t *testing.T, v string) {
+	t.Helper()
+	old := version.Version
+	version.Version = v
+	versionWarnOnce = sync.Once{}
+	t.Cleanup(func() {
+		version.Version = old
+		versionWarnOnce = sync.Once{}
+	})
+}
+
+// versionDaemon serves GET /api/version and stamps every response with v.
+func versionDaemon(v string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(apiwire.VersionHeader, v)
+		if r.URL.Path == "/api/version" {
+			_ = json.NewEncoder(w).Encode(apiwire.VersionInfo{Version: v, Commit: "abc1234", GoVersion: "go1.26.4"})
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+}
+
+func TestVersionCommandReportsSkew(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	withVersion(t, "v1.2.0")
+	srv := versionDaemon("v1.3.0")
+	defer srv.Close()
+	var stderr bytes.Buffer
+	defer func(w io.Writer) { versionWarnOut = w }(versionWarnOut)
+	versionWarnOut = &stderr
+
+	cmd := &command{}
+	var out bytes.Buffer
+	if err := cmd.Version(VersionFlags{Output: "json", APIUrl: srv.URL + "/api", APITimeout: 5 * time.Second}, &out); err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	var report versionReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode %s: %v", out.String(), err)
+	}
+	if report.Client.Version != "v1.2.0" || report.Daemon == nil || report.Daemon.Version != "v1.3.0" || !report.Skewed {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("version repeated the skew warning on stderr: %q", stderr.String())
+	}
+
+	out.Reset()
+	if err := cmd.Version(VersionFlags{Output: "text", APIUrl: srv.URL + "/api", APITimeout: 5 * time.Second}, &out); err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	for _, want := range []string{"Client: v1.2.0 (", "Daemon: v1.3.0 (commit abc1234, go1.26.4)", "warning:"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("output %q lacks %q", out.String(), want)
+		}
+	}
+
+	// An unreachable daemon is reported without failing.
+	out.Reset()
+	if err := cmd.Version(VersionFlags{Output: "text", APIUrl: "http://127.0.0.1:1/api", APITimeout: time.Second}, &out); err != nil {
+		t.Fatalf("Version without a daemon: %v", err)
+	}
+	if !strings.Contains(out.String(), "Daemon: unavailable") {
+		t.Fatalf("output %q does not report the unreachable daemon", out.String())
+	}
+}
+
+func TestClientWarnsOnceAboutVersionSkew(t *testing.T) {
+	var stderr bytes.Buffer
+	defer func(w io.Writer) { versionWarnOut = w }(versionWarnOut)
+	versionWarnOut = &stderr
+
+	for _, tt := range []struct {
+		client, daemon string
+		warn           bool
+	}{
+		{"v1.2.0", "v1.2.0", false},
+		{"dev", "v1.3.0", false},
+		{"v1.2.0", "", false}, // daemons older than the header
+		{"v1.2.0", "v1.3.0", true},
+	} {
+		withVersion(t, tt.client)
+		stderr.Reset()
+		srv := versionDaemon(tt.daemon)
+		client := NewAPIClient(srv.URL+"/api", 5*time.Second)
+		_, _ = client.GetStatus("")
+		_, _ = client.GetStatus("")
+		srv.Close()
+		if got := strings.Count(stderr.String(), "warning:"); got != map[bool]int{false: 0, true: 1}[tt.warn] {
+			t.Errorf("CLI %q, daemon %q: %d warnings: %q", tt.client, tt.daemon, got, stderr.String())
+		}
+	}
+}
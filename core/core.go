@@ -10,10 +10,14 @@
 package core
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"syscall"
 	"time"
 
 	"github.com/loykin/provisr/core/history"
+	"github.com/loykin/provisr/core/instancename"
 	"github.com/loykin/provisr/core/internal/cronjob"
 	"github.com/loykin/provisr/core/internal/detector"
 	"github.com/loykin/provisr/core/internal/job"
@@ -33,6 +37,58 @@ type Spec = process.Spec
 // Status describes the runtime state of a managed process.
 type Status = process.Status
 
+// PortInUseError is returned by a start when ports listed in Spec.Ports are
+// already listening.
+type PortInUseError = process.PortInUseError
+
+// ErrNotRunning is returned (wrapped) by Manager.Signal when the process is
+// not running.
+var ErrNotRunning = process.ErrNotRunning
+
+// ParseSignal parses a signal name such as "HUP" or "SIGUSR1", or a signal
+// number, for Manager.Signal.
+func ParseSignal(s string) (syscall.Signal, error) { return process.ParseSignal(s) }
+
+// ParseLabelSelector parses "key=value" terms, separate or comma-joined,
+// into the labels a process must have to match.
+func ParseLabelSelector(terms ...string) (map[string]string, error) {
+	return process.ParseLabelSelector(terms...)
+}
+
+// ParseStatusFields parses a comma-separated list of Status JSON field
+// names for Status.Project.
+func ParseStatusFields(list string) ([]string, error) { return process.ParseStatusFields(list) }
+
+// ValidateDirOptions checks a directory mode such as "0750" and an owner
+// "user[:group]", as taken by EnsureDir. Both may be empty.
+func ValidateDirOptions(mode, owner string) error {
+	if _, err := process.ParseDirMode(mode); err != nil {
+		return err
+	}
+	return process.ValidateDirOwner(owner)
+}
+
+// EnsureDir creates dir, including missing parents, when it does not exist
+// yet, with mode (default "0750") and, when set, owner "user[:group]". An
+// existing directory is left as is.
+func EnsureDir(dir, mode, owner string) error {
+	m, err := process.ParseDirMode(mode)
+	if err != nil {
+		return err
+	}
+	return process.EnsureDir(dir, m, owner)
+}
+
+// HookSummary aggregates the executions of one lifecycle hook of a process.
+type HookSummary = manager.HookSummary
+
+// AsyncHookInfo describes an async lifecycle hook that is still running.
+type AsyncHookInfo = manager.AsyncHookInfo
+
+// ExitDetails extracts the exit code and terminating signal from a
+// Status.ExitErr.
+func ExitDetails(err error) (code int, signal string) { return process.ExitDetails(err) }
+
 // LogLine is a single captured stdout/stderr line, used by the live-tail API.
 type LogLine = process.LogLine
 
@@ -44,8 +100,10 @@ type DetectorConfig = process.DetectorConfig
 type LogConfig = logger.Config
 type LogFileConfig = logger.FileConfig
 type LogSlogConfig = logger.SlogConfig
+type LogSyslogConfig = logger.SyslogConfig
 type LogLevel = logger.LogLevel
 type LogFormat = logger.Format
+type LogOutputFormat = logger.OutputFormat
 
 const (
 	LogLevelDebug = logger.LevelDebug
@@ -55,6 +113,14 @@ const (
 
 	LogFormatText = logger.FormatText
 	LogFormatJSON = logger.FormatJSON
+
+	LogOutputFormatRaw      = logger.OutputFormatRaw
+	LogOutputFormatJSON     = logger.OutputFormatJSON
+	LogOutputFormatPrefixed = logger.OutputFormatPrefixed
+
+	// LogPrefixTimeLayout is the time layout of LogOutputFormatPrefixed
+	// line prefixes.
+	LogPrefixTimeLayout = logger.PrefixTimeLayout
 )
 
 // DefaultLogConfig returns the default logger configuration.
@@ -94,25 +160,82 @@ type HistorySink = history.Sink
 type HistoryReader = history.Reader
 type HistoryEntry = history.Entry
 type HistoryPruner = history.Pruner
+type HistoryPinger = history.Pinger
+type HistoryEvent = history.Event
+type JobOutput = history.JobOutput
+type JobOutputStore = history.JobOutputStore
+type ProcessMetadataStore = history.ProcessMetadataStore
 
 // --- Manager facade ---
 
 // ManagerInstanceGroup describes a named group of process instances.
 type ManagerInstanceGroup = manager.InstanceGroup
 
+// Transition is one process state transition; see Manager.SubscribeTransitions.
+type Transition = manager.Transition
+
+// EnvProvider computes the environment of each process start; see
+// Manager.SetEnvProvider.
+type EnvProvider = manager.EnvProvider
+
+// StartInterceptor vets or adjusts the spec of each process start; see
+// Manager.SetStartInterceptor.
+type StartInterceptor = manager.StartInterceptor
+
+// ApplyOptions, ApplyPlan and PlanChange describe what ApplyConfigWithOptions
+// does, or would do in a dry run.
+type ApplyOptions = manager.ApplyOptions
+type ApplyPlan = manager.ApplyPlan
+type PlanChange = manager.PlanChange
+
+// ReconcileResult and ReconcileFailure report what Manager.Reconcile did.
+type ReconcileResult = manager.ReconcileResult
+type ReconcileFailure = manager.ReconcileFailure
+
+// PlanChange actions.
+const (
+	PlanStart   = manager.PlanStart
+	PlanRecover = manager.PlanRecover
+	PlanStop    = manager.PlanStop
+	PlanKeep    = manager.PlanKeep
+	PlanAdd     = manager.PlanAdd
+	PlanUpdate  = manager.PlanUpdate
+	PlanRemove  = manager.PlanRemove
+)
+
 // Manager is a thin facade over the internal manager. It provides a stable
 // public API for embedding.
 type Manager struct{ inner *manager.Manager }
 type Observer = observability.Observer
 type ObserverFunc = observability.ObserverFunc
 type ObservationEvent = observability.Event
+type Tracer = observability.Tracer
+type Span = observability.Span
+type TraceAttr = observability.Attr
 
 // New constructs a new Manager.
 func New() *Manager { return &Manager{inner: manager.NewManager()} }
 
-func (m *Manager) SetHistorySinks(sinks ...HistorySink) { m.inner.SetHistorySinks(sinks...) }
-func (m *Manager) SetObservers(observers ...Observer)   { m.inner.SetObservers(observers...) }
-func (m *Manager) SetGlobalEnv(kvs []string)            { m.inner.SetGlobalEnv(kvs) }
+func (m *Manager) SetHistorySinks(sinks ...HistorySink)   { m.inner.SetHistorySinks(sinks...) }
+func (m *Manager) SetObservers(observers ...Observer)     { m.inner.SetObservers(observers...) }
+func (m *Manager) SetTracer(t Tracer)                     { m.inner.SetTracer(t) }
+func (m *Manager) SetGlobalEnv(kvs []string)              { m.inner.SetGlobalEnv(kvs) }
+func (m *Manager) SetEnvProvider(p EnvProvider)           { m.inner.SetEnvProvider(p) }
+func (m *Manager) SetStartInterceptor(i StartInterceptor) { m.inner.SetStartInterceptor(i) }
+func (m *Manager) DefaultEnv(s Spec) []string             { return m.inner.DefaultEnv(s) }
+func (m *Manager) SetLogConfig(cfg LogConfig)             { m.inner.SetLogConfig(cfg) }
+func (m *Manager) SetDefaultHealthCheckInterval(d time.Duration) {
+	m.inner.SetDefaultHealthCheckInterval(d)
+}
+func (m *Manager) SetRestartJitter(d time.Duration) { m.inner.SetRestartJitter(d) }
+func (m *Manager) SetMaxConcurrentStarts(n int)     { m.inner.SetMaxConcurrentStarts(n) }
+func (m *Manager) SetInstanceNaming(scheme *instancename.Scheme) {
+	m.inner.SetInstanceNaming(scheme)
+}
+func (m *Manager) InstanceNaming() *instancename.Scheme { return m.inner.InstanceNaming() }
+func (m *Manager) SetValidateCommands(enabled bool)     { m.inner.SetValidateCommands(enabled) }
+func (m *Manager) SetChildSubreaper() error             { return m.inner.SetChildSubreaper() }
+func (m *Manager) SetDaemonID(id string)                { m.inner.SetDaemonID(id) }
 func (m *Manager) SetInstanceGroups(groups []ManagerInstanceGroup) {
 	m.inner.SetInstanceGroups(groups)
 }
@@ -121,12 +244,41 @@ func (m *Manager) ListInstanceGroups() []ManagerInstanceGroup {
 }
 func (m *Manager) Register(s Spec) error          { return m.inner.Register(s) }
 func (m *Manager) RegisterN(s Spec) error         { return m.inner.RegisterN(s) }
+func (m *Manager) Add(s Spec) error               { return m.inner.Add(s) }
 func (m *Manager) Start(name string) error        { return m.inner.Start(name) }
 func (m *Manager) Recover(s Spec) error           { return m.inner.Recover(s) }
 func (m *Manager) ApplyConfig(specs []Spec) error { return m.inner.ApplyConfig(specs) }
+func (m *Manager) Reconcile(specs []Spec) (ReconcileResult, error) {
+	return m.inner.Reconcile(specs)
+}
+func (m *Manager) ReconcileWithOptions(specs []Spec, opts ApplyOptions) (ReconcileResult, error) {
+	return m.inner.ReconcileWithOptions(specs, opts)
+}
+func (m *Manager) ApplyConfigWithOptions(specs []Spec, opts ApplyOptions) (ApplyPlan, error) {
+	return m.inner.ApplyConfigWithOptions(specs, opts)
+}
+func (m *Manager) PlanInstanceGroups(groups []ManagerInstanceGroup) []PlanChange {
+	return m.inner.PlanInstanceGroups(groups)
+}
 func (m *Manager) Stop(name string, wait time.Duration) error {
 	return m.inner.Stop(name, wait)
 }
+func (m *Manager) StopByPID(pid int, wait time.Duration) error {
+	return m.inner.StopByPID(pid, wait)
+}
+func (m *Manager) StartContext(ctx context.Context, name string) error {
+	return m.inner.StartContext(ctx, name)
+}
+func (m *Manager) StopContext(ctx context.Context, name string, wait time.Duration) error {
+	return m.inner.StopContext(ctx, name, wait)
+}
+func (m *Manager) RestartContext(ctx context.Context, name string, wait time.Duration) error {
+	return m.inner.RestartContext(ctx, name, wait)
+}
+func (m *Manager) Scale(base string, n int) error { return m.inner.Scale(base, n) }
+func (m *Manager) Signal(name string, sig os.Signal) error {
+	return m.inner.Signal(name, sig)
+}
 func (m *Manager) Update(s Spec, wait time.Duration) error {
 	return m.inner.Update(s, wait)
 }
@@ -140,19 +292,50 @@ func (m *Manager) GetSpec(name string) (Spec, error) {
 	return m.inner.GetSpec(name)
 }
 func (m *Manager) ProcessBase(name string) (string, error) { return m.inner.ProcessBase(name) }
+
+// SetMetadataStore makes the manager keep process metadata, including the
+// labels recorded when specs are registered, in store instead of in memory.
+func (m *Manager) SetMetadataStore(store ProcessMetadataStore) { m.inner.SetMetadataStore(store) }
+func (m *Manager) SetProcessMetadata(ctx context.Context, name string, metadata map[string]string) error {
+	return m.inner.SetProcessMetadata(ctx, name, metadata)
+}
+func (m *Manager) ProcessMetadata(ctx context.Context, name string) (map[string]string, error) {
+	return m.inner.ProcessMetadata(ctx, name)
+}
+func (m *Manager) FindProcessesByMetadata(ctx context.Context, selector map[string]string) ([]string, error) {
+	return m.inner.FindProcessesByMetadata(ctx, selector)
+}
+
+// MatchesLabels reports whether the registered process name has every
+// label of selector.
+func (m *Manager) MatchesLabels(name string, selector map[string]string) bool {
+	return m.inner.MatchesLabels(name, selector)
+}
 func (m *Manager) Unregister(name string, wait time.Duration) error {
 	return m.inner.Unregister(name, wait)
 }
 func (m *Manager) StartAll(base string) error                    { return m.inner.StartAll(base) }
 func (m *Manager) StopAll(base string, wait time.Duration) error { return m.inner.StopAll(base, wait) }
+func (m *Manager) StopRegex(pattern string, wait time.Duration) error {
+	return m.inner.StopRegex(pattern, wait)
+}
 func (m *Manager) UnregisterAll(base string, wait time.Duration) error {
 	return m.inner.UnregisterAll(base, wait)
 }
 func (m *Manager) Status(name string) (Status, error) { return m.inner.Status(name) }
+func (m *Manager) HookSummaries(name string) ([]HookSummary, error) {
+	return m.inner.HookSummaries(name)
+}
+func (m *Manager) PendingAsyncHooks(name string) ([]AsyncHookInfo, error) {
+	return m.inner.PendingAsyncHooks(name)
+}
 func (m *Manager) LogsSince(name string, since uint64, limit int) ([]LogLine, uint64, error) {
 	return m.inner.LogsSince(name, since, limit)
 }
 func (m *Manager) StatusAll(base string) ([]Status, error) { return m.inner.StatusAll(base) }
+func (m *Manager) StatusRegex(pattern string) ([]Status, error) {
+	return m.inner.StatusRegex(pattern)
+}
 func (m *Manager) InstanceGroupStatus(groupName string) (map[string][]Status, error) {
 	return m.inner.InstanceGroupStatus(groupName)
 }
@@ -163,6 +346,22 @@ func (m *Manager) InstanceGroupStop(groupName string, wait time.Duration) error
 	return m.inner.InstanceGroupStop(groupName, wait)
 }
 func (m *Manager) Count(base string) (int, error) { return m.inner.Count(base) }
+func (m *Manager) CountRegex(pattern string) (int, error) {
+	return m.inner.CountRegex(pattern)
+}
+
+// SubscribeEvents streams process lifecycle events as they happen; call the
+// returned function to stop. Events are dropped while the buffer is full.
+func (m *Manager) SubscribeEvents(buffer int) (<-chan HistoryEvent, func()) {
+	return m.inner.SubscribeEvents(buffer)
+}
+
+// SubscribeTransitions streams process state transitions (stopped to
+// starting, starting to running, ...) as they happen; call the returned
+// function to stop. Transitions are dropped while the buffer is full.
+func (m *Manager) SubscribeTransitions(buffer int) (<-chan Transition, func()) {
+	return m.inner.SubscribeTransitions(buffer)
+}
 
 // Shutdown gracefully stops all managed processes and releases resources.
 // Call this when the embedding application is shutting down (e.g. on SIGTERM).
@@ -192,6 +391,8 @@ func (m *Manager) SetProcessMetricsCollector(collector ProcessMetricsCollector)
 // --- Group facade ---
 
 type ServiceGroup = pg.ServiceGroup
+type RollingRestartOptions = pg.RollingRestartOptions
+type GroupRestartResult = pg.RestartResult
 
 type Group struct{ inner *pg.Group }
 
@@ -199,15 +400,20 @@ type Group struct{ inner *pg.Group }
 func NewGroup(m *Manager) *Group { return &Group{inner: pg.New(m.inner)} }
 
 func (g *Group) Start(gs ServiceGroup) error                    { return g.inner.Start(gs) }
+func (g *Group) StartAtomic(gs ServiceGroup) error              { return g.inner.StartAtomic(gs) }
 func (g *Group) Stop(gs ServiceGroup, wait time.Duration) error { return g.inner.Stop(gs, wait) }
 func (g *Group) Status(gs ServiceGroup) (map[string][]Status, error) {
 	return g.inner.Status(gs)
 }
+func (g *Group) RollingRestart(gs ServiceGroup, opts RollingRestartOptions) ([]GroupRestartResult, error) {
+	return g.inner.RollingRestart(gs, opts)
+}
 
 // --- Job facade ---
 
 type JobSpec = job.Spec
 type JobStatus = job.JobStatus
+type JobInstanceStatus = job.InstanceStatus
 
 type JobManager struct{ inner *job.Manager }
 
@@ -234,6 +440,9 @@ func (jm *JobManager) GetJobSpec(name string) (JobSpec, bool) {
 	}
 	return j.GetSpec(), true
 }
+func (jm *JobManager) GetJobInstances(name string) ([]JobInstanceStatus, bool) {
+	return jm.inner.GetJobInstances(name)
+}
 func (jm *JobManager) ListJobs() map[string]JobStatus { return jm.inner.GetJobStatus() }
 func (jm *JobManager) ListJobSpecs() map[string]JobSpec {
 	jobs := jm.inner.ListJobs()
@@ -243,11 +452,25 @@ func (jm *JobManager) ListJobSpecs() map[string]JobSpec {
 	}
 	return specs
 }
+
+// ProcessNames returns the names of the processes the manager's jobs,
+// including those of cron jobs, have launched.
+func (jm *JobManager) ProcessNames() []string { return jm.inner.ProcessNames() }
+
 func (jm *JobManager) UpdateJob(name string, spec JobSpec) error {
 	return jm.inner.UpdateJob(name, spec)
 }
-func (jm *JobManager) DeleteJob(name string) error { return jm.inner.DeleteJob(name) }
-func (jm *JobManager) Shutdown() error             { return jm.inner.Shutdown() }
+
+// SetOutputStore makes jobs that capture output save it to store, such as
+// a SQLite or PostgreSQL history store, instead of in memory.
+func (jm *JobManager) SetOutputStore(store JobOutputStore) { jm.inner.SetOutputStore(store) }
+func (jm *JobManager) JobOutput(ctx context.Context, name string, attempt int32) (JobOutput, bool, error) {
+	return jm.inner.JobOutput(ctx, name, attempt)
+}
+func (jm *JobManager) SuspendJob(name string) error { return jm.inner.SuspendJob(name) }
+func (jm *JobManager) ResumeJob(name string) error  { return jm.inner.ResumeJob(name) }
+func (jm *JobManager) DeleteJob(name string) error  { return jm.inner.DeleteJob(name) }
+func (jm *JobManager) Shutdown() error              { return jm.inner.Shutdown() }
 
 // --- CronScheduler facade ---
 
@@ -272,6 +495,10 @@ func (s *CronScheduler) Add(j CronJob) error { _, err := s.inner.CreateCronJob(j
 func (s *CronScheduler) Start() error        { return nil } // CronJobs start automatically when created
 func (s *CronScheduler) Stop() error         { return s.inner.Shutdown() }
 
+// Running reports whether the scheduler still fires cronjobs, i.e. Stop has
+// not been called.
+func (s *CronScheduler) Running() bool { return s.inner.Running() }
+
 // Get returns the spec for a single cronjob, e.g. to prefill an edit form.
 func (s *CronScheduler) Get(name string) (CronJob, bool) {
 	cj, ok := s.inner.GetCronJob(name)
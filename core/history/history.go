@@ -12,8 +12,10 @@ import (
 type EventType string
 
 const (
-	EventStart EventType = "start"
-	EventStop  EventType = "stop"
+	EventStart   EventType = "start"
+	EventStop    EventType = "stop"
+	EventRestart EventType = "restart" // automatic restart after an unexpected exit
+	EventFatal   EventType = "fatal"   // an automatic restart attempt failed
 )
 
 // Record is a minimal process record used for history events.
@@ -23,6 +25,8 @@ type Record struct {
 	LastStatus string    `json:"last_status"`
 	UpdatedAt  time.Time `json:"updated_at"`
 	SpecJSON   string    `json:"spec_json"`
+	ExitCode   *int      `json:"exit_code,omitempty"` // stop events: -1 when killed by a signal
+	Error      string    `json:"error,omitempty"`     // why the process failed, if it did
 }
 
 // Event represents a lifecycle event to be exported to external systems.
@@ -47,6 +51,10 @@ type Entry struct {
 	Name      string    `json:"name"`
 	Status    string    `json:"status"`
 	Error     *string   `json:"error,omitempty"`
+	// Event and ExitCode are empty for entries stored before they were
+	// recorded.
+	Event    EventType `json:"event,omitempty" db:"event"`
+	ExitCode *int      `json:"exit_code,omitempty" db:"exit_code"`
 }
 
 // Reader provides paginated access to stored process lifecycle history.
@@ -57,8 +65,54 @@ type Reader interface {
 	Count(ctx context.Context, name string) (int, error)
 }
 
+// Pinger checks that a persistent history store is reachable. Database
+// adapters implement it so GET /readyz can report an unreachable store.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
 // Pruner deletes history entries older than a cutoff. Persistent history
 // adapters implement this contract so retention remains storage-agnostic.
 type Pruner interface {
 	PruneBefore(ctx context.Context, cutoff time.Time) (int64, error)
 }
+
+// JobOutput is the captured stdout/stderr of one instance of a job, kept
+// after its process is gone. Attempt is the instance's 1-based launch order
+// within the job.
+type JobOutput struct {
+	Job        string    `json:"job" db:"job"`
+	Instance   string    `json:"instance" db:"instance"`
+	Attempt    int32     `json:"attempt" db:"attempt"`
+	Output     string    `json:"output" db:"output"`
+	Truncated  bool      `json:"truncated" db:"truncated"` // the start of the output was dropped
+	CapturedAt time.Time `json:"captured_at" db:"captured_at"`
+}
+
+// JobOutputStore keeps the output of job instances for jobs that enable
+// capture_output. Output is replaced when an attempt is saved again and
+// deleted with its job.
+type JobOutputStore interface {
+	SaveJobOutput(ctx context.Context, out JobOutput) error
+	// JobOutput returns ok=false when nothing was stored for the attempt.
+	JobOutput(ctx context.Context, job string, attempt int32) (out JobOutput, ok bool, err error)
+	DeleteJobOutput(ctx context.Context, job string) error
+}
+
+// ProcessMetadataStore keeps key/value metadata about processes, such as the
+// labels of their specs, so it can be queried after they are gone. Keys are
+// per process name; setting a key again replaces its value.
+type ProcessMetadataStore interface {
+	// SetProcessMetadata sets the given keys of process, leaving its other
+	// keys alone.
+	SetProcessMetadata(ctx context.Context, process string, metadata map[string]string) error
+	// ProcessMetadata returns every key set for process; an unknown process
+	// has none.
+	ProcessMetadata(ctx context.Context, process string) (map[string]string, error)
+	// FindProcessesByMetadata returns the sorted names of the processes with
+	// every key of selector set to its value.
+	FindProcessesByMetadata(ctx context.Context, selector map[string]string) ([]string, error)
+	// DeleteProcessMetadata removes keys of process, or all of its keys when
+	// none are given.
+	DeleteProcessMetadata(ctx context.Context, process string, keys ...string) error
+}
@@ -0,0 +1,193 @@
+// Package instancename builds and parses the names given to the instances of
+// a process started with Instances > 1. The manager names instances with a
+// Scheme, and metrics collectors use the same Scheme to group the series of
+// an instance under its base process name.
+package instancename
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// DefaultFormat names instances base-1, base-2, ...
+const DefaultFormat = "{{.Base}}-{{.Index}}"
+
+// DefaultStart is the index of the first instance under the default scheme.
+const DefaultStart = 1
+
+// Data is what a Format template is executed with.
+type Data struct {
+	Base  string // name of the process set
+	Index string // instance index, zero-padded to the scheme's Padding
+}
+
+// Scheme names the instances of a process set. A nil *Scheme is the default
+// scheme (DefaultFormat, DefaultStart, no padding).
+type Scheme struct {
+	format  string
+	start   int
+	padding int
+	tmpl    *template.Template
+	re      *regexp.Regexp
+}
+
+var defaultScheme = mustNew(DefaultFormat, DefaultStart, 0)
+
+func mustNew(format string, start, padding int) *Scheme {
+	s, err := New(format, start, padding)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// markers stand in for Base and Index when a format is rendered to derive the
+// pattern that parses names back.
+const (
+	baseMarker  = "\x00base\x00"
+	indexMarker = "\x00index\x00"
+)
+
+// New compiles a scheme. format is a text/template over Data that must use
+// both .Base and .Index exactly once (empty selects DefaultFormat); start is
+// the index of the first instance and padding the minimum number of digits
+// of an index, zero-padded.
+func New(format string, start, padding int) (*Scheme, error) {
+	if format == "" {
+		format = DefaultFormat
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("instance start index must be >= 0, got %d", start)
+	}
+	if padding < 0 || padding > 9 {
+		return nil, fmt.Errorf("instance index padding must be between 0 and 9, got %d", padding)
+	}
+	tmpl, err := template.New("instance").Option("missingkey=error").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance name format %q: %w", format, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, Data{Base: baseMarker, Index: indexMarker}); err != nil {
+		return nil, fmt.Errorf("invalid instance name format %q: %w", format, err)
+	}
+	rendered := buf.String()
+	if strings.Count(rendered, baseMarker) != 1 || strings.Count(rendered, indexMarker) != 1 {
+		return nil, fmt.Errorf("instance name format %q must use {{.Base}} and {{.Index}} exactly once", format)
+	}
+
+	// Everything around the markers is literal text; Base takes the longest
+	// match so that "web-api-1" parses as base "web-api".
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for rest := rendered; rest != ""; {
+		bi := strings.Index(rest, baseMarker)
+		ii := strings.Index(rest, indexMarker)
+		switch {
+		case bi < 0 && ii < 0:
+			pattern.WriteString(regexp.QuoteMeta(rest))
+			rest = ""
+		case ii < 0 || (bi >= 0 && bi < ii):
+			pattern.WriteString(regexp.QuoteMeta(rest[:bi]) + "(?P<base>.+)")
+			rest = rest[bi+len(baseMarker):]
+		default:
+			pattern.WriteString(regexp.QuoteMeta(rest[:ii]) + "(?P<index>[0-9]+)")
+			rest = rest[ii+len(indexMarker):]
+		}
+	}
+	pattern.WriteString("$")
+	if strings.Contains(pattern.String(), "(?P<base>.+)(?P<index>") || strings.Contains(pattern.String(), "[0-9]+)(?P<base>") {
+		return nil, fmt.Errorf("instance name format %q must separate {{.Base}} and {{.Index}}", format)
+	}
+
+	return &Scheme{
+		format:  format,
+		start:   start,
+		padding: padding,
+		tmpl:    tmpl,
+		re:      regexp.MustCompile(pattern.String()),
+	}, nil
+}
+
+func (s *Scheme) orDefault() *Scheme {
+	if s == nil {
+		return defaultScheme
+	}
+	return s
+}
+
+// Format returns the template the scheme was built from.
+func (s *Scheme) Format() string { return s.orDefault().format }
+
+// Start returns the index of the first instance.
+func (s *Scheme) Start() int { return s.orDefault().start }
+
+// Padding returns the minimum number of digits of an index.
+func (s *Scheme) Padding() int { return s.orDefault().padding }
+
+// Name returns the name of the i-th instance (0 for the first) of base.
+func (s *Scheme) Name(base string, i int) string {
+	s = s.orDefault()
+	var buf bytes.Buffer
+	index := fmt.Sprintf("%0*d", s.padding, s.start+i)
+	// The template was executed successfully when the scheme was compiled
+	// and Data has no failing fields, so Execute cannot fail here.
+	_ = s.tmpl.Execute(&buf, Data{Base: base, Index: index})
+	return buf.String()
+}
+
+// Names returns the names of a set of n instances of base. A set of one is
+// not numbered: its only name is base.
+func (s *Scheme) Names(base string, n int) []string {
+	if n <= 1 {
+		return []string{base}
+	}
+	names := make([]string, 0, n)
+	for i := range n {
+		names = append(names, s.Name(base, i))
+	}
+	return names
+}
+
+// Parse splits an instance name into its base and index. ok is false when
+// name does not follow the scheme or its index is below Start.
+func (s *Scheme) Parse(name string) (base string, index int, ok bool) {
+	s = s.orDefault()
+	m := s.re.FindStringSubmatch(name)
+	if m == nil {
+		return "", 0, false
+	}
+	base = m[s.re.SubexpIndex("base")]
+	digits := m[s.re.SubexpIndex("index")]
+	if s.padding > 0 && len(digits) < s.padding {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(digits)
+	if err != nil || index < s.start {
+		return "", 0, false
+	}
+	return base, index, true
+}
+
+// Ordinal returns the position of name in the set of n instances of base
+// (0 for the first), or -1 when it is not one of them.
+func (s *Scheme) Ordinal(name, base string, n int) int {
+	if n <= 1 {
+		if name == base {
+			return 0
+		}
+		return -1
+	}
+	b, index, ok := s.Parse(name)
+	if !ok || b != base {
+		return -1
+	}
+	// Parse accepts any digits; only the exact rendered name is an instance
+	if i := index - s.Start(); i < n && s.Name(base, i) == name {
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,94 @@
+package instancename
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDefaultScheme(t *testing.T) {
+	var s *Scheme
+	if got := s.Names("web", 3); !reflect.DeepEqual(got, []string{"web-1", "web-2", "web-3"}) {
+		t.Fatalf("Names = %v", got)
+	}
+	if got := s.Names("web", 1); !reflect.DeepEqual(got, []string{"web"}) {
+		t.Fatalf("Names of a single instance = %v", got)
+	}
+	base, index, ok := s.Parse("web-api-2")
+	if !ok || base != "web-api" || index != 2 {
+		t.Fatalf("Parse(web-api-2) = %q, %d, %v", base, index, ok)
+	}
+	for _, name := range []string{"web", "web-", "web-canary", "-1", "web-0"} {
+		if _, _, ok := s.Parse(name); ok {
+			t.Errorf("Parse(%q) should not match", name)
+		}
+	}
+}
+
+func TestCustomSchemes(t *testing.T) {
+	cases := []struct {
+		format         string
+		start, padding int
+		names          []string
+	}{
+		{"", 0, 0, []string{"web-0", "web-1", "web-2"}},
+		{"", 1, 3, []string{"web-001", "web-002", "web-003"}},
+		{"{{.Base}}_{{.Index}}", 0, 2, []string{"web_00", "web_01", "web_02"}},
+		{"{{.Index}}.{{.Base}}", 1, 0, []string{"1.web", "2.web", "3.web"}},
+		{"{{.Base}}-{{.Index}}.svc", 1, 0, []string{"web-1.svc", "web-2.svc", "web-3.svc"}},
+	}
+	for _, tc := range cases {
+		s, err := New(tc.format, tc.start, tc.padding)
+		if err != nil {
+			t.Fatalf("New(%q, %d, %d): %v", tc.format, tc.start, tc.padding, err)
+		}
+		if got := s.Names("web", 3); !reflect.DeepEqual(got, tc.names) {
+			t.Fatalf("%q: Names = %v, want %v", tc.format, got, tc.names)
+		}
+		for i, name := range tc.names {
+			base, index, ok := s.Parse(name)
+			if !ok || base != "web" || index != tc.start+i {
+				t.Errorf("%q: Parse(%q) = %q, %d, %v", tc.format, name, base, index, ok)
+			}
+			if got := s.Ordinal(name, "web", 3); got != i {
+				t.Errorf("%q: Ordinal(%q) = %d, want %d", tc.format, name, got, i)
+			}
+		}
+		if got := s.Ordinal(tc.names[2], "web", 2); got != -1 {
+			t.Errorf("%q: Ordinal(%q) beyond the set = %d", tc.format, tc.names[2], got)
+		}
+	}
+}
+
+func TestPaddedSchemeRejectsShortIndexes(t *testing.T) {
+	s, err := New("", 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := s.Parse("web-1"); ok {
+		t.Fatal("web-1 should not parse with padding 3")
+	}
+	if got := s.Ordinal("web-0001", "web", 3); got != -1 {
+		t.Fatalf("Ordinal(web-0001) = %d, want -1", got)
+	}
+}
+
+func TestNewRejectsInvalidFormats(t *testing.T) {
+	for _, format := range []string{
+		"{{.Base}}",
+		"{{.Index}}",
+		"{{.Base}}-{{.Index}}-{{.Index}}",
+		"{{.Base}}{{.Index}}",
+		"{{.Base}-{{.Index}}",
+		"{{.Name}}-{{.Index}}",
+	} {
+		if _, err := New(format, 1, 0); err == nil {
+			t.Errorf("New(%q) should fail", format)
+		}
+	}
+	if _, err := New("", -1, 0); err == nil {
+		t.Error("negative start should fail")
+	}
+	if _, err := New("", 1, -1); err == nil {
+		t.Error("negative padding should fail")
+	}
+}
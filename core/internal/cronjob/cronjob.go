@@ -48,17 +48,12 @@ func NewCronJob(spec CronJobSpec, jobs JobRunner) *CronJob {
 	spec.GetDefaults()
 
 	// Create scheduler with timezone support
-	var scheduler *cron.Cron
-	if spec.TimeZone != nil && *spec.TimeZone != "" {
-		if loc, err := time.LoadLocation(*spec.TimeZone); err == nil {
-			scheduler = cron.New(cron.WithLocation(loc))
-		} else {
-			slog.Warn("Invalid timezone, using UTC", "timezone", *spec.TimeZone, "error", err)
-			scheduler = cron.New()
-		}
-	} else {
-		scheduler = cron.New()
+	loc, err := spec.Location()
+	if err != nil {
+		slog.Warn("Invalid timezone, using the local time zone", "timezone", *spec.TimeZone, "error", err)
+		loc = time.Local
 	}
+	scheduler := cron.New(cron.WithLocation(loc), cron.WithParser(scheduleParser))
 
 	return &CronJob{
 		spec:       spec,
@@ -189,7 +184,8 @@ func (c *CronJob) executeJob() {
 		})
 
 		// Update metrics for failed start
-		c.jobs.Observe(observability.Event{Kind: observability.CronJobCompleted, Name: c.spec.Name, Phase: string(job.JobPhaseFailed)})
+		c.status.FailedRuns++
+		c.jobs.Observe(observability.Event{Kind: observability.CronJobCompleted, Name: c.spec.Name, Phase: string(job.JobPhaseFailed), UnixTime: float64(now.Unix())})
 		return
 	}
 
@@ -250,14 +246,17 @@ func (c *CronJob) monitorJob(jobName string, j *job.Job) {
 		phase = job.JobPhaseSucceeded
 		reason = "Job completed successfully"
 		c.status.LastSuccessfulTime = &completionTime
+		c.status.SucceededRuns++
 	} else {
 		phase = job.JobPhaseFailed
 		reason = "Job failed"
+		c.status.FailedRuns++
 	}
 
 	// Update metrics for job completion
 	duration := completionTime.Sub(*status.StartTime).Seconds()
-	c.jobs.Observe(observability.Event{Kind: observability.CronJobCompleted, Name: c.spec.Name, Phase: string(phase), Duration: duration})
+	c.status.LastDurationSeconds = duration
+	c.jobs.Observe(observability.Event{Kind: observability.CronJobCompleted, Name: c.spec.Name, Phase: string(phase), Duration: duration, UnixTime: float64(completionTime.Unix())})
 
 	c.addToHistory(&JobHistoryEntry{
 		Name:           jobName,
@@ -14,6 +14,7 @@ type Manager struct {
 	mu        sync.RWMutex
 	cronJobs  map[string]*CronJob
 	jobRunner JobRunner
+	stopped   bool // set by Shutdown
 }
 
 // NewManagerWithJobManager creates a cronjob manager backed by a shared job
@@ -189,6 +190,7 @@ func (m *Manager) Shutdown() error {
 
 	// Clear map
 	m.cronJobs = make(map[string]*CronJob)
+	m.stopped = true
 
 	if len(errors) > 0 {
 		return fmt.Errorf("shutdown errors: %s", strings.Join(errors, "; "))
@@ -198,6 +200,14 @@ func (m *Manager) Shutdown() error {
 	return nil
 }
 
+// Running reports whether the manager schedules cronjobs, i.e. Shutdown
+// has not been called.
+func (m *Manager) Running() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return !m.stopped
+}
+
 // GetCronJobStatus returns status for all cronjobs
 func (m *Manager) GetCronJobStatus() map[string]CronJobStatus {
 	m.mu.RLock()
@@ -0,0 +1,83 @@
+package cronjob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr/core/internal/job"
+	"github.com/loykin/provisr/core/internal/manager"
+	"github.com/loykin/provisr/core/observability"
+)
+
+// recordingRunner runs jobs for real and records the events cronjobs emit.
+type recordingRunner struct {
+	*job.Manager
+	events chan observability.Event
+}
+
+func (r *recordingRunner) Observe(event observability.Event) {
+	select {
+	case r.events <- event:
+	default:
+	}
+}
+
+func TestCronJob_ScheduledRunsAreCounted(t *testing.T) {
+	jobs := job.NewManager(manager.NewManager())
+	t.Cleanup(func() { _ = jobs.Shutdown() })
+	runner := &recordingRunner{Manager: jobs, events: make(chan observability.Event, 64)}
+
+	cases := []struct {
+		command string
+		phase   job.JobPhase
+	}{
+		{"true", job.JobPhaseSucceeded},
+		{"false", job.JobPhaseFailed},
+	}
+	for _, tc := range cases {
+		backoff := int32(0)
+		cj := NewCronJob(CronJobSpec{
+			Name:     "runs-" + tc.command,
+			Schedule: "@every 1s",
+			JobTemplate: job.Spec{
+				Name:          "runs-" + tc.command,
+				Command:       tc.command,
+				BackoffLimit:  &backoff,
+				RestartPolicy: string(job.RestartPolicyNever),
+			},
+		}, runner)
+		if err := cj.Start(); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+
+		deadline := time.After(10 * time.Second)
+	wait:
+		for {
+			select {
+			case event := <-runner.events:
+				if event.Kind != observability.CronJobCompleted || event.Name != "runs-"+tc.command {
+					continue
+				}
+				if event.Phase != string(tc.phase) {
+					t.Fatalf("%s: completed with phase %s, want %s", tc.command, event.Phase, tc.phase)
+				}
+				if event.UnixTime == 0 {
+					t.Fatalf("%s: completion event carries no time", tc.command)
+				}
+				break wait
+			case <-deadline:
+				t.Fatalf("%s: no scheduled run completed, status %+v", tc.command, cj.GetStatus())
+			}
+		}
+		cj.Stop()
+
+		status := cj.GetStatus()
+		if tc.phase == job.JobPhaseSucceeded {
+			if status.SucceededRuns < 1 || status.LastSuccessfulTime == nil {
+				t.Fatalf("%s: status %+v, want a counted success", tc.command, status)
+			}
+		} else if status.FailedRuns < 1 || status.SucceededRuns != 0 {
+			t.Fatalf("%s: status %+v, want a counted failure only", tc.command, status)
+		}
+	}
+}
@@ -43,6 +43,44 @@ type CronJobStatus struct {
 	Active             []*job.Reference `json:"active,omitempty"`               // List of currently running jobs
 	LastScheduleTime   *time.Time       `json:"last_schedule_time,omitempty"`   // Last time job was scheduled
 	LastSuccessfulTime *time.Time       `json:"last_successful_time,omitempty"` // Last time job completed successfully
+
+	// Run counters since the cronjob was created; a run that failed to
+	// start counts as failed.
+	SucceededRuns       int64   `json:"succeeded_runs"`
+	FailedRuns          int64   `json:"failed_runs"`
+	LastDurationSeconds float64 `json:"last_duration_seconds,omitempty"` // Of the last completed run
+}
+
+// scheduleParser parses Schedule: five fields, six with leading seconds, or a
+// descriptor such as @daily or @every 5s.
+var scheduleParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Location returns the time zone Schedule is evaluated in: the IANA zone
+// TimeZone names, or the daemon's local zone when it is unset.
+func (s *CronJobSpec) Location() (*time.Location, error) {
+	if s.TimeZone == nil || *s.TimeZone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(*s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_zone %q: %w", *s.TimeZone, err)
+	}
+	return loc, nil
+}
+
+// NextRun returns the first time after t that Schedule fires, evaluated in
+// Location, so "0 2 * * *" is 2 AM on that zone's wall clock on both sides
+// of a daylight saving change.
+func (s *CronJobSpec) NextRun(t time.Time) (time.Time, error) {
+	sched, err := scheduleParser.Parse(s.Schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron schedule %q: %w", s.Schedule, err)
+	}
+	loc, err := s.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(t.In(loc)), nil
 }
 
 // GetDefaults applies default values to the spec
@@ -77,10 +115,12 @@ func (s *CronJobSpec) Validate() error {
 	}
 
 	// Validate cron expression
-	parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	if _, err := parser.Parse(s.Schedule); err != nil {
+	if _, err := scheduleParser.Parse(s.Schedule); err != nil {
 		return fmt.Errorf("invalid cron schedule %q: %w", s.Schedule, err)
 	}
+	if _, err := s.Location(); err != nil {
+		return err
+	}
 
 	// Validate concurrency policy. Empty is accepted here (meaning "unset")
 	// since callers may validate before GetDefaults runs — e.g. config
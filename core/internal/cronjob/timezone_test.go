@@ -0,0 +1,91 @@
+package cronjob
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr/core/internal/job"
+)
+
+func TestCronJobSpec_NextRunAcrossDST(t *testing.T) {
+	tz := "America/New_York"
+	spec := CronJobSpec{
+		Name:        "nightly",
+		Schedule:    "0 2 * * *",
+		TimeZone:    &tz,
+		JobTemplate: job.Spec{Name: "nightly", Command: "echo hi"},
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	// 2 AM New York is 07:00 UTC under EST and 06:00 UTC under EDT, which
+	// starts on 2026-03-08.
+	cases := []struct {
+		after time.Time
+		want  time.Time
+	}{
+		{time.Date(2026, 3, 5, 12, 0, 0, 0, loc), time.Date(2026, 3, 6, 7, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 3, 9, 12, 0, 0, 0, loc), time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)},
+		// Back to EST on 2026-11-01.
+		{time.Date(2026, 10, 30, 12, 0, 0, 0, loc), time.Date(2026, 10, 31, 6, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 11, 1, 12, 0, 0, 0, loc), time.Date(2026, 11, 2, 7, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		// The zone of the reference time must not matter.
+		next, err := spec.NextRun(tc.after.UTC())
+		if err != nil {
+			t.Fatalf("next run: %v", err)
+		}
+		if !next.Equal(tc.want) {
+			t.Errorf("next run after %s = %s, want %s", tc.after, next.UTC(), tc.want)
+		}
+		if local := next.In(loc); local.Hour() != 2 || local.Minute() != 0 {
+			t.Errorf("next run after %s is %s in %s, want 02:00", tc.after, local.Format("15:04"), tz)
+		}
+	}
+}
+
+func TestCronJob_ScheduledInTimeZone(t *testing.T) {
+	tz := "Asia/Tokyo"
+	spec := CronJobSpec{
+		Name:        "tokyo",
+		Schedule:    "30 9 * * *",
+		TimeZone:    &tz,
+		JobTemplate: job.Spec{Name: "tokyo", Command: "echo hi"},
+	}
+	cj := NewCronJob(spec, &fakeJobRunner{created: make(chan job.Spec, 1)})
+	if err := cj.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer cj.Stop()
+
+	loc, _ := time.LoadLocation(tz)
+	next := cj.GetNextSchedule().In(loc)
+	if next.Hour() != 9 || next.Minute() != 30 {
+		t.Fatalf("next schedule is %s in %s, want 09:30", next.Format("15:04"), tz)
+	}
+}
+
+func TestCronJobSpec_ValidateTimeZone(t *testing.T) {
+	tz := "Mars/Olympus_Mons"
+	spec := CronJobSpec{
+		Name:        "bad-zone",
+		Schedule:    "@daily",
+		TimeZone:    &tz,
+		JobTemplate: job.Spec{Name: "bad-zone", Command: "echo hi"},
+	}
+	if err := spec.Validate(); err == nil || !strings.Contains(err.Error(), "time_zone") {
+		t.Fatalf("expected a time_zone error, got %v", err)
+	}
+
+	spec.TimeZone = nil
+	if loc, err := spec.Location(); err != nil || loc != time.Local {
+		t.Fatalf("unset time_zone should be the local zone, got %v, %v", loc, err)
+	}
+}
@@ -2,6 +2,7 @@ package env
 
 import (
 	"os"
+	"runtime"
 	"strings"
 	"sync"
 )
@@ -91,7 +92,34 @@ func (e *Env) WithUnset(k string) *Env {
 // Merge composes final environment applying order:
 // base (OS snapshot) -> globals -> perProc overrides. Returns a fresh []string.
 func (e *Env) Merge(perProc []string) []string {
-	base := e.ensureBase()
+	return e.mergeOnto(e.ensureBase(), perProc)
+}
+
+// MergeClean is like Merge but does not inherit the daemon's environment:
+// the result holds only a minimal PATH (see CleanBase), the globals set via
+// WithSet, and perProc. Globals and perProc may still override PATH.
+func (e *Env) MergeClean(perProc []string) []string {
+	return e.mergeOnto(CleanBase(), perProc)
+}
+
+// DefaultCleanPath is the PATH given to processes started with a clean
+// environment on Unix-like systems.
+const DefaultCleanPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// CleanBase returns the minimal base environment used by MergeClean. On
+// Windows SystemRoot is kept as well, since many programs fail without it.
+func CleanBase() Var {
+	if runtime.GOOS == "windows" {
+		root := os.Getenv("SystemRoot")
+		if root == "" {
+			root = `C:\Windows`
+		}
+		return Var{"SystemRoot": root, "PATH": root + `\System32;` + root}
+	}
+	return Var{"PATH": DefaultCleanPath}
+}
+
+func (e *Env) mergeOnto(base Var, perProc []string) []string {
 	m := make(Var, len(base)+len(e.globals)+len(perProc))
 	// Copy base
 	for k, v := range base {
@@ -26,3 +26,28 @@ func TestExpandLargeValueIsLinear(t *testing.T) {
 		t.Fatalf("unexpected expanded length: %d", len(got))
 	}
 }
+
+func TestMergeCleanDropsInheritedEnv(t *testing.T) {
+	t.Setenv("PROVISR_DAEMON_ONLY", "leak")
+	e := New().WithSet("GLOBAL", "g")
+
+	got := map[string]string{}
+	for _, kv := range e.MergeClean([]string{"LOCAL=${GLOBAL}-l"}) {
+		k, v, _ := strings.Cut(kv, "=")
+		got[k] = v
+	}
+	if _, ok := got["PROVISR_DAEMON_ONLY"]; ok {
+		t.Fatalf("clean env inherited daemon variable: %v", got)
+	}
+	if got["GLOBAL"] != "g" || got["LOCAL"] != "g-l" {
+		t.Fatalf("globals and per-process env should still apply: %v", got)
+	}
+	if got["PATH"] == "" {
+		t.Fatalf("clean env should carry a minimal PATH: %v", got)
+	}
+
+	inherited := strings.Join(e.Merge(nil), "\n")
+	if !strings.Contains(inherited, "PROVISR_DAEMON_ONLY=leak") {
+		t.Fatalf("Merge should still inherit the daemon environment")
+	}
+}
@@ -0,0 +1,70 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr/core/internal/manager"
+)
+
+func TestJobInstancesDistinguishFailures(t *testing.T) {
+	jobs := NewManager(manager.NewManager())
+	t.Cleanup(func() { _ = jobs.Shutdown() })
+
+	parallelism := int32(3)
+	completions := int32(3)
+	backoffLimit := int32(1)
+	j, err := jobs.CreateJob(Spec{
+		Name: "partial",
+		// Completion index 1 fails once; its retry gets index 3 and succeeds.
+		Command:        `sh -c 'test "$JOB_COMPLETION_INDEX" != 1 || exit 3'`,
+		Parallelism:    &parallelism,
+		Completions:    &completions,
+		BackoffLimit:   &backoffLimit,
+		CompletionMode: string(CompletionModeIndexed),
+		RestartPolicy:  string(RestartPolicyNever),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-j.Done():
+	case <-time.After(15 * time.Second):
+		t.Fatalf("job did not complete: %+v", j.GetStatus())
+	}
+
+	instances, ok := jobs.GetJobInstances("partial")
+	if !ok {
+		t.Fatal("job not found")
+	}
+	if len(instances) != 4 {
+		t.Fatalf("got %d instances, want 4: %+v", len(instances), instances)
+	}
+	for i, instance := range instances {
+		if instance.Attempt != int32(i+1) {
+			t.Errorf("instance %d has attempt %d", i, instance.Attempt)
+		}
+		if instance.Index == nil || *instance.Index != int32(i) {
+			t.Errorf("instance %s has index %v, want %d", instance.Name, instance.Index, i)
+		}
+		if instance.PID == 0 || instance.EndTime == nil || instance.ExitCode == nil {
+			t.Errorf("instance %s is missing details: %+v", instance.Name, instance)
+			continue
+		}
+		wantState, wantCode := JobProcessStatusSucceeded, 0
+		if i == 1 {
+			wantState, wantCode = JobProcessStatusFailed, 3
+		}
+		if instance.State != wantState || *instance.ExitCode != wantCode {
+			t.Errorf("instance %s = %s exit %d, want %s exit %d", instance.Name, instance.State, *instance.ExitCode, wantState, wantCode)
+		}
+	}
+	if instances[1].Error == "" {
+		t.Error("failed instance should report its error")
+	}
+
+	if _, ok := jobs.GetJobInstances("missing"); ok {
+		t.Fatal("unknown job should not be found")
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/loykin/provisr/core/history"
+	"github.com/loykin/provisr/core/internal/process"
 )
 
 // Job represents a running job instance
@@ -15,6 +19,7 @@ type Job struct {
 	spec    Spec
 	status  JobStatus
 	manager ProcessRunner
+	outputs history.JobOutputStore // where CaptureOutput saves instance output
 
 	// Job execution state
 	ctx       context.Context
@@ -26,12 +31,15 @@ type Job struct {
 	completionCh chan struct{}
 	errorCh      chan error
 	completed    bool
+	suspended    bool
 }
 
 // JobProcess represents a process instance within a job
 type JobProcess struct {
 	Name      string
 	Index     int32 // For indexed completion mode
+	Attempt   int32 // 1-based launch order within the job
+	PID       int
 	Status    JobProcessStatus
 	StartTime time.Time
 	EndTime   *time.Time
@@ -78,13 +86,15 @@ func (j *Job) Start() error {
 	j.mu.Lock()
 	defer j.mu.Unlock()
 
-	if j.status.Phase != JobPhasePending {
+	if j.completed || !j.startTime.IsZero() {
 		return fmt.Errorf("job %q is already started", j.spec.Name)
 	}
 
 	j.startTime = time.Now()
 	j.status.StartTime = &j.startTime
-	j.status.Phase = JobPhaseRunning
+	if !j.suspended {
+		j.status.Phase = JobPhaseRunning
+	}
 
 	// Apply active deadline if specified
 	if j.spec.ActiveDeadlineSeconds != nil {
@@ -98,14 +108,16 @@ func (j *Job) Start() error {
 		parallelism = *j.spec.Parallelism
 	}
 
-	slog.Info("Starting job", "name", j.spec.Name, "parallelism", parallelism)
+	slog.Info("Starting job", "name", j.spec.Name, "parallelism", parallelism, "suspended", j.suspended)
 
-	// Create and start processes
-	for i := int32(0); i < parallelism; i++ {
+	// Create and start processes; a job suspended before it started gets
+	// them from monitor once it is resumed.
+	for i := int32(0); i < parallelism && !j.suspended; i++ {
 		processName := j.generateProcessName(i)
 		jobProcess := &JobProcess{
 			Name:      processName,
 			Index:     i,
+			Attempt:   int32(len(j.processes)) + 1,
 			Status:    JobProcessStatusPending,
 			StartTime: time.Now(),
 		}
@@ -145,6 +157,48 @@ func (j *Job) Stop() error {
 	return nil
 }
 
+// Suspend stops the job from launching new instances. Instances that are
+// already running are left to finish and still count towards completions.
+func (j *Job) Suspend() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.completed {
+		return fmt.Errorf("job %q has already finished", j.spec.Name)
+	}
+	if j.suspended {
+		return nil
+	}
+	j.suspended = true
+	j.status.Phase = JobPhaseSuspended
+	j.addCondition(ConditionSuspended, "True", "JobSuspended", "Job suspended")
+	slog.Info("Job suspended", "name", j.spec.Name, "active", j.status.Active)
+	return nil
+}
+
+// Resume lets a suspended job launch instances again.
+func (j *Job) Resume() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.completed {
+		return fmt.Errorf("job %q has already finished", j.spec.Name)
+	}
+	if !j.suspended {
+		return nil
+	}
+	j.suspended = false
+	if j.startTime.IsZero() {
+		// Still waiting for its dependencies (or for Start).
+		j.status.Phase = JobPhasePending
+	} else {
+		j.status.Phase = JobPhaseRunning
+	}
+	j.addCondition(ConditionSuspended, "False", "JobResumed", "Job resumed")
+	slog.Info("Job resumed", "name", j.spec.Name)
+	return nil
+}
+
 // Wait waits for job completion
 func (j *Job) Wait() error {
 	select {
@@ -164,6 +218,42 @@ func (j *Job) GetStatus() JobStatus {
 	return j.status
 }
 
+// Instances returns the status of every instance launched for the job, in
+// launch order.
+func (j *Job) Instances() []InstanceStatus {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	instances := make([]InstanceStatus, 0, len(j.processes))
+	for _, p := range j.processes {
+		instance := InstanceStatus{
+			Name:      p.Name,
+			Attempt:   p.Attempt,
+			State:     p.Status,
+			PID:       p.PID,
+			StartTime: p.StartTime,
+		}
+		if j.spec.CompletionMode == string(CompletionModeIndexed) {
+			index := p.Index
+			instance.Index = &index
+		}
+		if p.EndTime != nil {
+			end := *p.EndTime
+			instance.EndTime = &end
+		}
+		if p.ExitCode != nil {
+			code := *p.ExitCode
+			instance.ExitCode = &code
+		}
+		if p.Error != nil {
+			instance.Error = p.Error.Error()
+		}
+		instances = append(instances, instance)
+	}
+	sort.Slice(instances, func(a, b int) bool { return instances[a].Attempt < instances[b].Attempt })
+	return instances
+}
+
 // Done returns a channel that is closed when the job completes
 func (j *Job) Done() <-chan struct{} {
 	return j.completionCh
@@ -215,8 +305,14 @@ func (j *Job) runProcess(jobProcess *JobProcess) {
 		return
 	}
 
+	pid := 0
+	if status, err := j.manager.Status(jobProcess.Name); err == nil {
+		pid = status.PID
+	}
+
 	j.mu.Lock()
 	jobProcess.Status = JobProcessStatusRunning
+	jobProcess.PID = pid
 	j.status.Active++
 	j.mu.Unlock()
 
@@ -241,24 +337,24 @@ func (j *Job) runProcess(jobProcess *JobProcess) {
 				jobProcess.EndTime = &endTime
 				j.status.Active--
 
-				// Extract exit code from ExitErr if available
-				exitCode := 0
-				if status.ExitErr != nil {
-					exitCode = 1 // Default non-zero exit code for errors
-				}
+				// A process killed by a signal reports -1
+				exitCode, _ := process.ExitDetails(status.ExitErr)
+				jobProcess.ExitCode = &exitCode
 
-				if exitCode == 0 {
+				if status.ExitErr == nil {
 					jobProcess.Status = JobProcessStatusSucceeded
 					j.status.Succeeded++
 					slog.Info("Job process succeeded", "job", j.spec.Name, "process", jobProcess.Name, "index", jobProcess.Index)
 				} else {
 					jobProcess.Status = JobProcessStatusFailed
-					jobProcess.ExitCode = &exitCode
+					jobProcess.Error = status.ExitErr
 					j.status.Failed++
 					slog.Warn("Job process failed", "job", j.spec.Name, "process", jobProcess.Name, "index", jobProcess.Index, "exitCode", exitCode)
 				}
 				j.mu.Unlock()
 
+				j.captureOutput(jobProcess)
+
 				// Clean up process from manager
 				_ = j.manager.Unregister(jobProcess.Name, 5*time.Second)
 				return
@@ -325,7 +421,7 @@ func (j *Job) checkCompletion() bool {
 	}
 
 	// Start more processes if needed and not exceeding parallelism
-	if totalActive < parallelism && totalActive < totalNeeded && j.status.Failed <= backoffLimit {
+	if !j.suspended && totalActive < parallelism && totalActive < totalNeeded && j.status.Failed <= backoffLimit {
 		j.startAdditionalProcess()
 	}
 
@@ -340,6 +436,7 @@ func (j *Job) startAdditionalProcess() {
 	jobProcess := &JobProcess{
 		Name:      processName,
 		Index:     nextIndex,
+		Attempt:   nextIndex + 1,
 		Status:    JobProcessStatusPending,
 		StartTime: time.Now(),
 	}
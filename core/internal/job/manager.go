@@ -1,12 +1,14 @@
 package job
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/loykin/provisr/core/history"
 	"github.com/loykin/provisr/core/observability"
 )
 
@@ -15,6 +17,7 @@ type Manager struct {
 	mu             sync.RWMutex
 	jobs           map[string]*Job
 	processManager ProcessRunner
+	outputs        history.JobOutputStore
 }
 
 // NewManager creates a new job manager
@@ -22,6 +25,33 @@ func NewManager(processManager ProcessRunner) *Manager {
 	return &Manager{
 		jobs:           make(map[string]*Job),
 		processManager: processManager,
+		outputs:        newMemoryOutputStore(),
+	}
+}
+
+// SetOutputStore makes jobs created from now on save captured output to
+// store rather than in memory.
+func (m *Manager) SetOutputStore(store history.JobOutputStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outputs = store
+}
+
+// JobOutput returns the output captured for an attempt of the named job.
+func (m *Manager) JobOutput(ctx context.Context, name string, attempt int32) (history.JobOutput, bool, error) {
+	m.mu.RLock()
+	store := m.outputs
+	m.mu.RUnlock()
+	return store.JobOutput(ctx, name, attempt)
+}
+
+// deleteOutput removes the captured output of a job that is going away.
+// Callers hold m.mu.
+func (m *Manager) deleteOutput(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), outputSaveTimeout)
+	defer cancel()
+	if err := m.outputs.DeleteJobOutput(ctx, name); err != nil {
+		slog.Warn("Failed to delete job output", "name", name, "error", err)
 	}
 }
 
@@ -41,6 +71,7 @@ func (m *Manager) CreateJob(spec Spec) (*Job, error) {
 	}
 
 	j := NewJob(spec, m.processManager)
+	j.outputs = m.outputs
 	m.jobs[spec.Name] = j
 
 	if len(spec.DependsOn) > 0 {
@@ -99,6 +130,28 @@ func (m *Manager) GetJob(name string) (*Job, bool) {
 	return job, exists
 }
 
+// GetJobInstances returns the status of every instance launched for the
+// named job, in launch order.
+func (m *Manager) GetJobInstances(name string) ([]InstanceStatus, bool) {
+	job, exists := m.GetJob(name)
+	if !exists {
+		return nil, false
+	}
+	return job.Instances(), true
+}
+
+// ProcessNames returns the names of the processes launched for every job
+// the manager holds.
+func (m *Manager) ProcessNames() []string {
+	var names []string
+	for _, job := range m.ListJobs() {
+		for _, instance := range job.Instances() {
+			names = append(names, instance.Name)
+		}
+	}
+	return names
+}
+
 // ListJobs returns all jobs
 func (m *Manager) ListJobs() map[string]*Job {
 	m.mu.RLock()
@@ -150,6 +203,25 @@ func (m *Manager) UpdateJob(name string, spec Spec) error {
 	return nil
 }
 
+// SuspendJob stops the named job from launching new instances until
+// ResumeJob is called. Running instances are not stopped.
+func (m *Manager) SuspendJob(name string) error {
+	job, exists := m.GetJob(name)
+	if !exists {
+		return fmt.Errorf("job %q not found", name)
+	}
+	return job.Suspend()
+}
+
+// ResumeJob lets a suspended job launch instances again.
+func (m *Manager) ResumeJob(name string) error {
+	job, exists := m.GetJob(name)
+	if !exists {
+		return fmt.Errorf("job %q not found", name)
+	}
+	return job.Resume()
+}
+
 // DeleteJob deletes a job
 func (m *Manager) DeleteJob(name string) error {
 	m.mu.Lock()
@@ -170,6 +242,7 @@ func (m *Manager) DeleteJob(name string) error {
 	}
 
 	delete(m.jobs, name)
+	m.deleteOutput(name)
 
 	// Update metrics
 	m.processManager.Observe(observability.Event{Kind: observability.JobDeleted, Name: name})
@@ -190,7 +263,7 @@ func (m *Manager) CleanupCompletedJobs() {
 		status := job.GetStatus()
 
 		// Skip if job is still running
-		if status.Phase == JobPhaseRunning || status.Phase == JobPhasePending {
+		if status.Phase == JobPhaseRunning || status.Phase == JobPhasePending || status.Phase == JobPhaseSuspended {
 			continue
 		}
 
@@ -211,6 +284,7 @@ func (m *Manager) CleanupCompletedJobs() {
 			slog.Warn("Failed to cleanup expired job", "name", name, "error", err)
 		}
 		delete(m.jobs, name)
+		m.deleteOutput(name)
 		slog.Info("Cleaned up expired job", "name", name)
 	}
 }
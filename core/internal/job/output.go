@@ -0,0 +1,101 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loykin/provisr/core/history"
+)
+
+// defaultOutputLimitBytes caps the output kept per instance when the spec
+// does not set OutputLimitBytes.
+const defaultOutputLimitBytes = 64 << 10
+
+// outputSaveTimeout bounds a single write to the output store.
+const outputSaveTimeout = 5 * time.Second
+
+// memoryOutputStore is the output store of a Manager that was not given a
+// persistent one: output is kept for as long as the daemon runs.
+type memoryOutputStore struct {
+	mu      sync.Mutex
+	outputs map[string]map[int32]history.JobOutput // job -> attempt -> output
+}
+
+func newMemoryOutputStore() *memoryOutputStore {
+	return &memoryOutputStore{outputs: make(map[string]map[int32]history.JobOutput)}
+}
+
+func (s *memoryOutputStore) SaveJobOutput(_ context.Context, out history.JobOutput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.outputs[out.Job] == nil {
+		s.outputs[out.Job] = make(map[int32]history.JobOutput)
+	}
+	s.outputs[out.Job][out.Attempt] = out
+	return nil
+}
+
+func (s *memoryOutputStore) JobOutput(_ context.Context, job string, attempt int32) (history.JobOutput, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out, ok := s.outputs[job][attempt]
+	return out, ok, nil
+}
+
+func (s *memoryOutputStore) DeleteJobOutput(_ context.Context, job string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.outputs, job)
+	return nil
+}
+
+// captureOutput saves what an instance wrote to stdout and stderr before its
+// process is unregistered. Only the last OutputLimitBytes are kept.
+func (j *Job) captureOutput(jobProcess *JobProcess) {
+	if !j.spec.CaptureOutput || j.outputs == nil {
+		return
+	}
+	lines, _, err := j.manager.LogsSince(jobProcess.Name, 0, 0)
+	if err != nil {
+		slog.Warn("Failed to read job process output", "job", j.spec.Name, "process", jobProcess.Name, "error", err)
+		return
+	}
+	limit := j.spec.OutputLimitBytes
+	if limit <= 0 {
+		limit = defaultOutputLimitBytes
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line.Text)
+		b.WriteByte('\n')
+	}
+	output := b.String()
+	// The process's buffer drops its oldest lines once full.
+	truncated := len(lines) > 0 && lines[0].Offset > 0
+	if cut := len(output) - limit; cut > 0 {
+		// Start at a line boundary rather than mid-line (or mid-rune).
+		if i := strings.IndexByte(output[cut-1:], '\n'); i >= 0 && cut+i < len(output) {
+			cut += i
+		}
+		output = output[cut:]
+		truncated = true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), outputSaveTimeout)
+	defer cancel()
+	err = j.outputs.SaveJobOutput(ctx, history.JobOutput{
+		Job:        j.spec.Name,
+		Instance:   jobProcess.Name,
+		Attempt:    jobProcess.Attempt,
+		Output:     output,
+		Truncated:  truncated,
+		CapturedAt: time.Now(),
+	})
+	if err != nil {
+		slog.Warn("Failed to save job process output", "job", j.spec.Name, "process", jobProcess.Name, "error", err)
+	}
+}
@@ -0,0 +1,73 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr/core/internal/manager"
+)
+
+func TestCapturedOutputIsKeptAndReapedWithJob(t *testing.T) {
+	jobs := NewManager(manager.NewManager())
+	t.Cleanup(func() { _ = jobs.Shutdown() })
+
+	ttl := int32(0)
+	j, err := jobs.CreateJob(Spec{
+		Name:                    "chatty",
+		Command:                 `sh -c 'echo line1; echo line2; echo line3'`,
+		CaptureOutput:           true,
+		OutputLimitBytes:        12,
+		TTLSecondsAfterFinished: &ttl,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-j.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatalf("job did not complete: %+v", j.GetStatus())
+	}
+
+	ctx := context.Background()
+	deadline := time.Now().Add(5 * time.Second)
+	out, ok, err := jobs.JobOutput(ctx, "chatty", 1)
+	for ; !ok && err == nil && time.Now().Before(deadline); out, ok, err = jobs.JobOutput(ctx, "chatty", 1) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !ok || err != nil {
+		t.Fatalf("output was not captured: %v", err)
+	}
+	// 18 bytes cut to the last 12, which start exactly at a line.
+	if out.Output != "line2\nline3\n" || !out.Truncated || out.Instance == "" {
+		t.Fatalf("output = %+v, want the last two lines", out)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	jobs.CleanupCompletedJobs()
+	if _, ok := jobs.GetJob("chatty"); ok {
+		t.Fatal("expired job was not reaped")
+	}
+	if _, ok, _ := jobs.JobOutput(ctx, "chatty", 1); ok {
+		t.Fatal("output should be deleted with its job")
+	}
+}
+
+func TestOutputNotCapturedByDefault(t *testing.T) {
+	jobs := NewManager(manager.NewManager())
+	t.Cleanup(func() { _ = jobs.Shutdown() })
+
+	j, err := jobs.CreateJob(Spec{Name: "quiet", Command: "go version"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-j.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatalf("job did not complete: %+v", j.GetStatus())
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, ok, _ := jobs.JobOutput(context.Background(), "quiet", 1); ok {
+		t.Fatal("output should only be captured with capture_output")
+	}
+}
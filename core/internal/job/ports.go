@@ -14,5 +14,6 @@ type ProcessRunner interface {
 	Stop(string, time.Duration) error
 	Status(string) (process.Status, error)
 	Unregister(string, time.Duration) error
+	LogsSince(string, uint64, int) ([]process.LogLine, uint64, error)
 	Observe(observability.Event)
 }
@@ -26,6 +26,8 @@ type Spec struct {
 	RestartPolicy           string                 `json:"restart_policy" mapstructure:"restart_policy"`                         // "Never", "OnFailure"
 	Lifecycle               process.LifecycleHooks `json:"lifecycle" mapstructure:"lifecycle"`                                   // Lifecycle hooks for job execution
 	DependsOn               []string               `json:"depends_on,omitempty" mapstructure:"depends_on"`                       // Jobs that must succeed before this one starts
+	CaptureOutput           bool                   `json:"capture_output,omitempty" mapstructure:"capture_output"`               // Keep each instance's stdout/stderr in the output store
+	OutputLimitBytes        int                    `json:"output_limit_bytes,omitempty" mapstructure:"output_limit_bytes"`       // Output kept per instance, the last bytes win (default 64 KiB)
 }
 
 // JobStatus represents the current status of a job
@@ -40,6 +42,19 @@ type JobStatus struct {
 	UncountedTerminatedPods *UncountedTerminatedPods `json:"uncounted_terminated_pods,omitempty"`
 }
 
+// InstanceStatus describes one instance (process) launched for a job.
+type InstanceStatus struct {
+	Name      string           `json:"name"`
+	Index     *int32           `json:"index,omitempty"` // completion index, Indexed mode only
+	Attempt   int32            `json:"attempt"`         // 1 for the first instance launched, 2 for the next, ...
+	State     JobProcessStatus `json:"state"`
+	PID       int              `json:"pid,omitempty"`
+	ExitCode  *int             `json:"exit_code,omitempty"` // -1 when killed by a signal
+	StartTime time.Time        `json:"start_time"`
+	EndTime   *time.Time       `json:"end_time,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
 // JobPhase represents the phase of job execution
 type JobPhase string
 
@@ -48,14 +63,18 @@ const (
 	JobPhaseRunning   JobPhase = "Running"
 	JobPhaseSucceeded JobPhase = "Succeeded"
 	JobPhaseFailed    JobPhase = "Failed"
+	// JobPhaseSuspended is a job that launches no new instances until it is
+	// resumed; instances already running are left to finish.
+	JobPhaseSuspended JobPhase = "Suspended"
 )
 
 // JobConditionType represents the type of job condition
 type ConditionType string
 
 const (
-	ConditionComplete ConditionType = "Complete"
-	ConditionFailed   ConditionType = "Failed"
+	ConditionComplete  ConditionType = "Complete"
+	ConditionFailed    ConditionType = "Failed"
+	ConditionSuspended ConditionType = "Suspended"
 )
 
 // JobCondition describes current condition of a job
@@ -110,6 +129,9 @@ func (j *Spec) Validate() error {
 	if len(j.Args) > 0 && j.Args[0] == "" {
 		return fmt.Errorf("job %q: args[0] must not be empty", j.Name)
 	}
+	if j.OutputLimitBytes < 0 {
+		return fmt.Errorf("job %q: output_limit_bytes must be >= 0", j.Name)
+	}
 	for _, dep := range j.DependsOn {
 		if dep == j.Name {
 			return fmt.Errorf("job %q: cannot depend on itself", j.Name)
@@ -0,0 +1,67 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr/core/internal/manager"
+)
+
+func TestSuspendedJobLaunchesNoNewInstances(t *testing.T) {
+	jobs := NewManager(manager.NewManager())
+	t.Cleanup(func() { _ = jobs.Shutdown() })
+
+	parallelism := int32(1)
+	completions := int32(3)
+	j, err := jobs.CreateJob(Spec{
+		Name:          "suspended-batch",
+		Command:       "go version",
+		Parallelism:   &parallelism,
+		Completions:   &completions,
+		RestartPolicy: string(RestartPolicyNever),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first instance is already launched; it must be left to finish.
+	if err := jobs.SuspendJob("suspended-batch"); err != nil {
+		t.Fatal(err)
+	}
+	if phase := j.GetStatus().Phase; phase != JobPhaseSuspended {
+		t.Fatalf("phase = %s, want %s", phase, JobPhaseSuspended)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for j.GetStatus().Succeeded < 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("running instance did not finish while suspended: %+v", j.GetStatus())
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	// Give the monitor a few ticks to (wrongly) launch a replacement.
+	time.Sleep(3 * time.Second)
+	status := j.GetStatus()
+	if status.Phase != JobPhaseSuspended || status.Active != 0 || status.Succeeded != 1 {
+		t.Fatalf("suspended job status = %+v, want 1 success and nothing active", status)
+	}
+
+	if err := jobs.ResumeJob("suspended-batch"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-j.Done():
+		status := j.GetStatus()
+		if status.Phase != JobPhaseSucceeded || status.Succeeded != completions {
+			t.Fatalf("job status = %+v, want %s with %d successes", status, JobPhaseSucceeded, completions)
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatalf("resumed job did not complete: %+v", j.GetStatus())
+	}
+
+	if err := jobs.SuspendJob("suspended-batch"); err == nil {
+		t.Fatal("suspending a finished job should fail")
+	}
+	if err := jobs.ResumeJob("missing"); err == nil {
+		t.Fatal("resuming an unknown job should fail")
+	}
+}
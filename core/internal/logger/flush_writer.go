@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+)
+
+// flushBufferSize is how much a flushWriter holds before writing through
+// regardless of the interval.
+const flushBufferSize = 64 * 1024
+
+// flushWriter batches writes to a log file and flushes them at most
+// interval after the first unflushed byte, or as soon as the buffer is
+// full. It trades a bounded tailing delay for fewer write syscalls when a
+// child prints many short lines.
+type flushWriter struct {
+	mu       sync.Mutex
+	out      io.WriteCloser
+	buf      *bufio.Writer
+	interval time.Duration
+	timer    *time.Timer
+	err      error // from a background flush, reported by the next call
+	closed   bool
+}
+
+func newFlushWriter(out io.WriteCloser, interval time.Duration) *flushWriter {
+	return &flushWriter{out: out, buf: bufio.NewWriterSize(out, flushBufferSize), interval: interval}
+}
+
+func (w *flushWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.takeErr(); err != nil {
+		return 0, err
+	}
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	n, err := w.buf.Write(p)
+	if err == nil && w.buf.Buffered() > 0 && w.timer == nil {
+		w.timer = time.AfterFunc(w.interval, w.timedFlush)
+	}
+	return n, err
+}
+
+func (w *flushWriter) timedFlush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timer = nil
+	if w.closed {
+		return
+	}
+	if err := w.buf.Flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// Close flushes the buffer and closes the underlying writer.
+func (w *flushWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	err := w.takeErr()
+	if flushErr := w.buf.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := w.out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (w *flushWriter) takeErr() error {
+	err := w.err
+	w.err = nil
+	return err
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestWriters_UnbufferedLineIsVisibleAtOnce(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{File: FileConfig{Dir: dir}}
+	outW, errW, err := cfg.ProcessWriters("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeIf(outW)
+	defer closeIf(errW)
+
+	_, _ = outW.Write([]byte("ready\n"))
+	if got := readLog(t, filepath.Join(dir, "demo.stdout.log")); got != "ready\n" {
+		t.Fatalf("log = %q right after the write, want the line", got)
+	}
+}
+
+func TestWriters_FlushIntervalBatchesWrites(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{File: FileConfig{Dir: dir, FlushInterval: 300 * time.Millisecond}}
+	outW, errW, err := cfg.ProcessWriters("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeIf(errW)
+	path := filepath.Join(dir, "demo.stdout.log")
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, _ = outW.Write([]byte("line\n"))
+	}
+	if got := readLog(t, path); got != "" && time.Since(start) < 300*time.Millisecond {
+		t.Fatalf("log = %q before the flush interval, want nothing yet", got)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for readLog(t, path) != strings.Repeat("line\n", 3) {
+		if time.Now().After(deadline) {
+			t.Fatalf("log = %q, lines not flushed within the interval", readLog(t, path))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Close flushes what is still buffered.
+	_, _ = outW.Write([]byte("last\n"))
+	closeIf(outW)
+	if got := readLog(t, path); !strings.HasSuffix(got, "last\n") {
+		t.Fatalf("log = %q after Close, want the last line", got)
+	}
+	if _, err := outW.Write([]byte("late\n")); err == nil {
+		t.Fatal("Write after Close succeeded")
+	}
+}
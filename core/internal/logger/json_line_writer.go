@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultMaxLineBytes caps how much of a single unterminated line the JSON
+// line writer buffers before emitting it as a partial record.
+const DefaultMaxLineBytes = 64 * 1024
+
+// jsonLine is the record written for every captured line when
+// FileConfig.OutputFormat is OutputFormatJSON.
+type jsonLine struct {
+	TS      string `json:"ts"`
+	Stream  string `json:"stream"`
+	Process string `json:"process"`
+	Line    string `json:"line"`
+	Partial bool   `json:"partial,omitempty"` // line was split because it exceeded the buffer cap or the stream closed mid-line
+}
+
+// lineWriter writes each newline-terminated chunk written to it as one
+// record on the underlying writer, encoded by encode: a JSON object for
+// OutputFormatJSON, a prefixed line for OutputFormatPrefixed. Unterminated
+// input is held until its newline arrives, up to maxLine bytes; beyond
+// that the buffered bytes are flushed as a partial record so a child that
+// never writes a newline can't grow the buffer without bound.
+type lineWriter struct {
+	mu      sync.Mutex
+	out     io.WriteCloser
+	process string
+	stream  string
+	maxLine int
+	pending []byte
+	now     func() time.Time
+	encode  func(w *lineWriter, line []byte, partial bool) ([]byte, error)
+}
+
+func newLineWriter(out io.WriteCloser, process, stream string, maxLine int) *lineWriter {
+	if maxLine <= 0 {
+		maxLine = DefaultMaxLineBytes
+	}
+	return &lineWriter{out: out, process: process, stream: stream, maxLine: maxLine, now: time.Now}
+}
+
+func newJSONLineWriter(out io.WriteCloser, process, stream string, maxLine int) *lineWriter {
+	w := newLineWriter(out, process, stream, maxLine)
+	w.encode = encodeJSONLine
+	return w
+}
+
+// newPrefixedLineWriter writes each line as "[<RFC 3339 time> <stream>] line".
+func newPrefixedLineWriter(out io.WriteCloser, process, stream string, maxLine int) *lineWriter {
+	w := newLineWriter(out, process, stream, maxLine)
+	w.encode = encodePrefixedLine
+	return w
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rest := p
+	for len(rest) > 0 {
+		idx := bytes.IndexByte(rest, '\n')
+		chunk := rest
+		if idx >= 0 {
+			chunk = rest[:idx]
+		}
+		for len(chunk) > 0 {
+			room := w.maxLine - len(w.pending)
+			if len(chunk) <= room {
+				w.pending = append(w.pending, chunk...)
+				break
+			}
+			w.pending = append(w.pending, chunk[:room]...)
+			chunk = chunk[room:]
+			if err := w.emit(w.pending, true); err != nil {
+				return 0, err
+			}
+			w.pending = w.pending[:0]
+		}
+		if idx < 0 {
+			break
+		}
+		rest = rest[idx+1:]
+		if err := w.emit(bytes.TrimRight(w.pending, "\r"), false); err != nil {
+			return 0, err
+		}
+		w.pending = w.pending[:0]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) emit(line []byte, partial bool) error {
+	rec, err := w.encode(w, line, partial)
+	if err != nil {
+		return err
+	}
+	_, err = w.out.Write(append(rec, '\n'))
+	return err
+}
+
+func encodeJSONLine(w *lineWriter, line []byte, partial bool) ([]byte, error) {
+	return json.Marshal(jsonLine{
+		TS:      w.now().UTC().Format(time.RFC3339Nano),
+		Stream:  w.stream,
+		Process: w.process,
+		Line:    string(line),
+		Partial: partial,
+	})
+}
+
+// encodePrefixedLine writes a split long line as several prefixed lines.
+func encodePrefixedLine(w *lineWriter, line []byte, _ bool) ([]byte, error) {
+	rec := make([]byte, 0, len(line)+48)
+	rec = append(rec, '[')
+	rec = w.now().UTC().AppendFormat(rec, PrefixTimeLayout)
+	rec = append(rec, ' ')
+	rec = append(rec, w.stream...)
+	rec = append(rec, "] "...)
+	return append(rec, line...), nil
+}
+
+// Close flushes any buffered partial line and closes the underlying writer.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var flushErr error
+	if len(w.pending) > 0 {
+		flushErr = w.emit(w.pending, true)
+		w.pending = nil
+	}
+	if err := w.out.Close(); err != nil {
+		return err
+	}
+	return flushErr
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeJSONLines(t *testing.T, data string) []jsonLine {
+	t.Helper()
+	var out []jsonLine
+	for _, raw := range strings.Split(strings.TrimRight(data, "\n"), "\n") {
+		if raw == "" {
+			continue
+		}
+		var rec jsonLine
+		if err := json.Unmarshal([]byte(raw), &rec); err != nil {
+			t.Fatalf("invalid JSON record %q: %v", raw, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func TestWriters_RawFormatPassesThrough(t *testing.T) {
+	var out bytes.Buffer
+	cfg := Config{File: FileConfig{StdoutWriter: &out}}
+	outW, _, err := cfg.ProcessWriters("demo")
+	if err != nil {
+		t.Fatalf("ProcessWriters error: %v", err)
+	}
+	_, _ = outW.Write([]byte("hello\npart"))
+	_, _ = outW.Write([]byte("ial\n"))
+	closeIf(outW)
+
+	if got := out.String(); got != "hello\npartial\n" {
+		t.Fatalf("raw format should pass bytes through unchanged, got %q", got)
+	}
+}
+
+func TestWriters_JSONFormatWrapsLines(t *testing.T) {
+	var out, errOut bytes.Buffer
+	cfg := Config{File: FileConfig{StdoutWriter: &out, StderrWriter: &errOut, OutputFormat: OutputFormatJSON}}
+	outW, errW, err := cfg.ProcessWriters("demo")
+	if err != nil {
+		t.Fatalf("ProcessWriters error: %v", err)
+	}
+	_, _ = outW.Write([]byte("hello \"world\"\npart"))
+	_, _ = outW.Write([]byte("ial\r\n"))
+	_, _ = errW.Write([]byte("boom\n"))
+	closeIf(outW)
+	closeIf(errW)
+
+	recs := decodeJSONLines(t, out.String())
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 stdout records, got %d: %q", len(recs), out.String())
+	}
+	if recs[0].Line != `hello "world"` || recs[1].Line != "partial" {
+		t.Fatalf("unexpected lines: %+v", recs)
+	}
+	for _, rec := range recs {
+		if rec.Stream != "stdout" || rec.Process != "demo" || rec.TS == "" || rec.Partial {
+			t.Fatalf("unexpected record fields: %+v", rec)
+		}
+	}
+
+	errRecs := decodeJSONLines(t, errOut.String())
+	if len(errRecs) != 1 || errRecs[0].Stream != "stderr" || errRecs[0].Line != "boom" {
+		t.Fatalf("unexpected stderr records: %+v", errRecs)
+	}
+}
+
+func TestJSONLineWriter_LongLineIsSplit(t *testing.T) {
+	var out bytes.Buffer
+	w := newJSONLineWriter(nopWriteCloser{&out}, "demo", "stdout", 4)
+
+	_, _ = w.Write([]byte("abcdefghij\n"))
+	_ = w.Close()
+
+	recs := decodeJSONLines(t, out.String())
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 records, got %d: %+v", len(recs), recs)
+	}
+	if recs[0].Line != "abcd" || !recs[0].Partial || recs[1].Line != "efgh" || !recs[1].Partial {
+		t.Fatalf("expected capped partial records, got %+v", recs)
+	}
+	if recs[2].Line != "ij" || recs[2].Partial {
+		t.Fatalf("expected final complete record, got %+v", recs[2])
+	}
+	if len(w.pending) != 0 {
+		t.Fatalf("pending buffer should be drained, got %d bytes", len(w.pending))
+	}
+}
+
+func TestJSONLineWriter_UnterminatedLineBoundedAndFlushedOnClose(t *testing.T) {
+	var out bytes.Buffer
+	w := newJSONLineWriter(nopWriteCloser{&out}, "demo", "stdout", 8)
+
+	for i := 0; i < 10; i++ {
+		_, _ = w.Write([]byte("xyz"))
+		if len(w.pending) > 8 {
+			t.Fatalf("pending buffer exceeded cap: %d", len(w.pending))
+		}
+	}
+	_ = w.Close()
+
+	recs := decodeJSONLines(t, out.String())
+	var joined strings.Builder
+	for _, rec := range recs {
+		if !rec.Partial {
+			t.Fatalf("unterminated output should only produce partial records, got %+v", rec)
+		}
+		joined.WriteString(rec.Line)
+	}
+	if joined.String() != strings.Repeat("xyz", 10) {
+		t.Fatalf("records lost data: %q", joined.String())
+	}
+}
+
+func TestWriters_PrefixOnlyWhenEnabled(t *testing.T) {
+	read := func(path string) string {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	for _, format := range []OutputFormat{"", OutputFormatRaw} {
+		dir := t.TempDir()
+		cfg := Config{File: FileConfig{Dir: dir, OutputFormat: format}}
+		outW, errW, err := cfg.ProcessWriters("demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = outW.Write([]byte("hello\n"))
+		closeIf(outW)
+		closeIf(errW)
+		if got := read(filepath.Join(dir, "demo.stdout.log")); got != "hello\n" {
+			t.Fatalf("format %q: log = %q, want the raw line", format, got)
+		}
+	}
+
+	// Both streams in one file, told apart by their prefixes.
+	path := filepath.Join(t.TempDir(), "demo.log")
+	cfg := Config{File: FileConfig{StdoutPath: path, StderrPath: path, OutputFormat: OutputFormatPrefixed}}
+	outW, errW, err := cfg.ProcessWriters("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = outW.Write([]byte("hello\nwor"))
+	_, _ = errW.Write([]byte("boom\n"))
+	_, _ = outW.Write([]byte("ld\n"))
+	closeIf(outW)
+	closeIf(errW)
+
+	pattern := regexp.MustCompile(`^\[(\S+) (stdout|stderr)\] (.*)$`)
+	var got []string
+	for _, line := range strings.Split(strings.TrimSuffix(read(path), "\n"), "\n") {
+		m := pattern.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("line %q has no prefix", line)
+		}
+		if _, err := time.Parse(PrefixTimeLayout, m[1]); err != nil {
+			t.Fatalf("line %q: bad timestamp: %v", line, err)
+		}
+		got = append(got, m[2]+":"+m[3])
+	}
+	if want := "stdout:hello,stderr:boom,stdout:world"; strings.Join(got, ",") != want {
+		t.Fatalf("lines = %v, want %s", got, want)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	lj "gopkg.in/natefinch/lumberjack.v2"
 )
@@ -27,6 +29,19 @@ const (
 	FormatJSON Format = "json"
 )
 
+// OutputFormat selects how captured process stdout/stderr is written to
+// file logs.
+type OutputFormat string
+
+const (
+	OutputFormatRaw      OutputFormat = "raw"      // pass bytes through unchanged (default)
+	OutputFormatJSON     OutputFormat = "json"     // one {"ts","stream","process","line"} object per line
+	OutputFormatPrefixed OutputFormat = "prefixed" // each line prefixed with "[<time> <stream>] "
+)
+
+// PrefixTimeLayout is the time layout of OutputFormatPrefixed line prefixes.
+const PrefixTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
 // Default process logging configuration constants
 const (
 	DefaultMaxSizeMB  = 10 // MB
@@ -46,21 +61,34 @@ type SlogConfig struct {
 
 // FileConfig contains configuration for process file logging
 type FileConfig struct {
-	Dir          string    `json:"dir" mapstructure:"dir"`                 // base directory for logs
-	StdoutPath   string    `json:"stdoutPath" mapstructure:"stdout"`       // explicit stdout path overrides Dir
-	StderrPath   string    `json:"stderrPath" mapstructure:"stderr"`       // explicit stderr path overrides Dir
-	MaxSizeMB    int       `json:"maxSizeMB" mapstructure:"max_size_mb"`   // megabytes before rotation (default 10)
-	MaxBackups   int       `json:"maxBackups" mapstructure:"max_backups"`  // number of backups to keep (default 3)
-	MaxAgeDays   int       `json:"maxAgeDays" mapstructure:"max_age_days"` // days to keep (default 7)
-	Compress     bool      `json:"compress" mapstructure:"compress"`       // Gzip rotated files
-	StdoutWriter io.Writer `json:"-" mapstructure:"-"`                     // inject custom stdout writer (overrides StdoutPath/Dir)
-	StderrWriter io.Writer `json:"-" mapstructure:"-"`                     // inject custom stderr writer (overrides StderrPath/Dir)
+	Dir          string       `json:"dir" mapstructure:"dir"`                               // base directory for logs
+	DirMode      string       `json:"dirMode,omitempty" mapstructure:"dir_mode"`            // octal permissions for a created Dir, e.g. "0750" (default)
+	DirOwner     string       `json:"dirOwner,omitempty" mapstructure:"dir_owner"`          // "user[:group]" to own a created Dir; as root, defaults to the process user
+	StdoutPath   string       `json:"stdoutPath" mapstructure:"stdout"`                     // explicit stdout path overrides Dir
+	StderrPath   string       `json:"stderrPath" mapstructure:"stderr"`                     // explicit stderr path overrides Dir
+	MaxSizeMB    int          `json:"maxSizeMB" mapstructure:"max_size_mb"`                 // megabytes before rotation (default 10)
+	MaxBackups   int          `json:"maxBackups" mapstructure:"max_backups"`                // number of backups to keep (default 3)
+	MaxAgeDays   int          `json:"maxAgeDays" mapstructure:"max_age_days"`               // days to keep (default 7)
+	Compress     bool         `json:"compress" mapstructure:"compress"`                     // Gzip rotated files
+	OutputFormat OutputFormat `json:"outputFormat,omitempty" mapstructure:"output_format"`  // raw (default), json or prefixed
+	MaxLineBytes int          `json:"maxLineBytes,omitempty" mapstructure:"max_line_bytes"` // json format: longest line buffered before a partial record is emitted (default 64KiB)
+	// FlushInterval batches writes to the log files and flushes them at
+	// least this often. 0 (default) writes every chunk of output through
+	// at once, so tailing sees each line immediately.
+	FlushInterval time.Duration `json:"flushInterval,omitempty" mapstructure:"flush_interval"`
+	// CombineStreams writes stdout and stderr interleaved to one file:
+	// StdoutPath, else StderrPath, else <Dir>/<name>.log. Pair it with the
+	// prefixed or json OutputFormat to tell the streams apart.
+	CombineStreams bool      `json:"combineStreams,omitempty" mapstructure:"combine_streams"`
+	StdoutWriter   io.Writer `json:"-" mapstructure:"-"` // inject custom stdout writer (overrides StdoutPath/Dir)
+	StderrWriter   io.Writer `json:"-" mapstructure:"-"` // inject custom stderr writer (overrides StderrPath/Dir)
 }
 
 // Config provides unified configuration by composing SlogConfig and FileConfig
 type Config struct {
-	Slog SlogConfig `json:"slog,omitempty" mapstructure:",squash"`
-	File FileConfig `json:"file,omitempty" mapstructure:",squash"`
+	Slog   SlogConfig   `json:"slog,omitempty" mapstructure:",squash"`
+	File   FileConfig   `json:"file,omitempty" mapstructure:",squash"`
+	Syslog SyslogConfig `json:"syslog,omitempty" mapstructure:"syslog"` // optional tee of structured logs to syslog
 }
 
 func (c *Config) DeepCopy() *Config {
@@ -73,6 +101,27 @@ func (c *Config) DeepCopy() *Config {
 	return &s
 }
 
+// WithOverrides returns a copy of c with the per-process settings from o
+// applied: a non-empty slog level or format, an injected output writer,
+// and an enabled syslog tee. This lets a single process log at a different
+// verbosity than the daemon while otherwise sharing its configuration.
+func (c *Config) WithOverrides(o Config) Config {
+	merged := *c
+	if o.Slog.Level != "" {
+		merged.Slog.Level = o.Slog.Level
+	}
+	if o.Slog.Format != "" {
+		merged.Slog.Format = o.Slog.Format
+	}
+	if o.Slog.Output != nil {
+		merged.Slog.Output = o.Slog.Output
+	}
+	if o.Syslog.Enabled {
+		merged.Syslog = o.Syslog
+	}
+	return merged
+}
+
 // DefaultConfig returns default unified configuration
 func DefaultConfig() Config {
 	return Config{
@@ -100,31 +149,33 @@ func (c *Config) NewSlogger() *slog.Logger {
 		output = os.Stderr
 	}
 
+	opts := &slog.HandlerOptions{
+		Level:     stringToSlogLevel(string(c.Slog.Level)),
+		AddSource: c.Slog.Source,
+	}
+
 	var handler slog.Handler
 
 	switch c.Slog.Format {
 	case FormatJSON:
-		handler = slog.NewJSONHandler(output, &slog.HandlerOptions{
-			Level:     stringToSlogLevel(string(c.Slog.Level)),
-			AddSource: c.Slog.Source,
-		})
+		handler = slog.NewJSONHandler(output, opts)
 	case FormatText:
 		if c.Slog.Color && isTerminal(output) {
-			handler = NewColorTextHandler(output, &slog.HandlerOptions{
-				Level:     stringToSlogLevel(string(c.Slog.Level)),
-				AddSource: c.Slog.Source,
-			}, c.Slog.TimeStamps)
+			handler = NewColorTextHandler(output, opts, c.Slog.TimeStamps)
 		} else {
-			handler = slog.NewTextHandler(output, &slog.HandlerOptions{
-				Level:     stringToSlogLevel(string(c.Slog.Level)),
-				AddSource: c.Slog.Source,
-			})
+			handler = slog.NewTextHandler(output, opts)
 		}
 	default:
-		handler = slog.NewTextHandler(output, &slog.HandlerOptions{
-			Level:     stringToSlogLevel(string(c.Slog.Level)),
-			AddSource: c.Slog.Source,
-		})
+		handler = slog.NewTextHandler(output, opts)
+	}
+
+	if c.Syslog.Enabled {
+		sw, err := sharedSyslogWriter(c.Syslog)
+		if err != nil {
+			slog.New(handler).Warn("Syslog disabled: invalid configuration", "error", err)
+		} else {
+			handler = slog.NewMultiHandler(handler, NewSyslogHandler(sw, opts))
+		}
 	}
 
 	return slog.New(handler)
@@ -135,6 +186,32 @@ type nopWriteCloser struct{ io.Writer }
 
 func (nopWriteCloser) Close() error { return nil }
 
+// FilePaths returns the files processName's stdout and stderr are logged
+// to: the explicit paths, else <Dir>/<name>.stdout.log and .stderr.log.
+// With CombineStreams both are the same file. A stream without a file is "".
+func (c *Config) FilePaths(processName string) (stdout, stderr string) {
+	if c.File.CombineStreams {
+		path := c.File.StdoutPath
+		if path == "" {
+			path = c.File.StderrPath
+		}
+		if path == "" && c.File.Dir != "" {
+			path = filepath.Join(c.File.Dir, processName+".log")
+		}
+		return path, path
+	}
+	stdout, stderr = c.File.StdoutPath, c.File.StderrPath
+	if c.File.Dir != "" {
+		if stdout == "" {
+			stdout = filepath.Join(c.File.Dir, processName+".stdout.log")
+		}
+		if stderr == "" {
+			stderr = filepath.Join(c.File.Dir, processName+".stderr.log")
+		}
+	}
+	return stdout, stderr
+}
+
 // ProcessWriters creates writers for process stdout/stderr.
 // Injected writers (StdoutWriter/StderrWriter) take precedence over file paths.
 func (c *Config) ProcessWriters(processName string) (stdout, stderr io.WriteCloser, err error) {
@@ -146,49 +223,82 @@ func (c *Config) ProcessWriters(processName string) (stdout, stderr io.WriteClos
 	}
 
 	// Injected writers take precedence over file paths
+	outPath, errPath := c.FilePaths(processName)
 	if c.File.StdoutWriter != nil {
 		stdout = nopWriteCloser{c.File.StdoutWriter}
-	} else {
-		var outPath string
-		if c.File.StdoutPath != "" {
-			outPath = c.File.StdoutPath
-		} else if c.File.Dir != "" {
-			outPath = filepath.Join(c.File.Dir, processName+".stdout.log")
-		}
-		if outPath != "" {
-			stdout = &lj.Logger{
-				Filename:   outPath,
-				MaxSize:    c.getMaxSizeMB(),
-				MaxBackups: c.getMaxBackups(),
-				MaxAge:     c.getMaxAgeDays(),
-				Compress:   c.File.Compress,
-			}
-		}
+	} else if outPath != "" {
+		stdout = c.fileWriter(outPath)
 	}
 
 	if c.File.StderrWriter != nil {
 		stderr = nopWriteCloser{c.File.StderrWriter}
-	} else {
-		var errPath string
-		if c.File.StderrPath != "" {
-			errPath = c.File.StderrPath
-		} else if c.File.Dir != "" {
-			errPath = filepath.Join(c.File.Dir, processName+".stderr.log")
+	} else if errPath != "" && errPath == outPath && c.File.StdoutWriter == nil {
+		// Both streams share one file, written through one logger so
+		// their writes append instead of overwriting each other.
+		shared := &sharedWriteCloser{WriteCloser: stdout, refs: 2}
+		stdout, stderr = shared, shared
+	} else if errPath != "" {
+		stderr = c.fileWriter(errPath)
+	}
+
+	var lines func(io.WriteCloser, string, string, int) *lineWriter
+	switch c.File.OutputFormat {
+	case OutputFormatJSON:
+		lines = newJSONLineWriter
+	case OutputFormatPrefixed:
+		lines = newPrefixedLineWriter
+	}
+	if lines != nil {
+		if stdout != nil {
+			stdout = lines(stdout, processName, "stdout", c.File.MaxLineBytes)
 		}
-		if errPath != "" {
-			stderr = &lj.Logger{
-				Filename:   errPath,
-				MaxSize:    c.getMaxSizeMB(),
-				MaxBackups: c.getMaxBackups(),
-				MaxAge:     c.getMaxAgeDays(),
-				Compress:   c.File.Compress,
-			}
+		if stderr != nil {
+			stderr = lines(stderr, processName, "stderr", c.File.MaxLineBytes)
 		}
 	}
 
 	return stdout, stderr, nil
 }
 
+// fileWriter returns the rotating writer of a log file, buffered when
+// FlushInterval is set.
+func (c *Config) fileWriter(path string) io.WriteCloser {
+	f := &lj.Logger{
+		Filename:   path,
+		MaxSize:    c.getMaxSizeMB(),
+		MaxBackups: c.getMaxBackups(),
+		MaxAge:     c.getMaxAgeDays(),
+		Compress:   c.File.Compress,
+	}
+	if c.File.FlushInterval > 0 {
+		return newFlushWriter(f, c.File.FlushInterval)
+	}
+	return f
+}
+
+// sharedWriteCloser is one writer used by both streams; the last Close
+// closes it.
+type sharedWriteCloser struct {
+	io.WriteCloser
+	mu   sync.Mutex
+	refs int
+}
+
+func (s *sharedWriteCloser) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.WriteCloser.Write(p)
+}
+
+func (s *sharedWriteCloser) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refs--; s.refs == 0 {
+		return s.WriteCloser.Close()
+	}
+	return nil
+}
+
 // NewProcessLogger creates a structured logger for a specific process
 func (c *Config) NewProcessLogger(processName string) *slog.Logger {
 	logger := c.NewSlogger()
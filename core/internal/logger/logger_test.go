@@ -1,9 +1,12 @@
 package logger
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	lj "gopkg.in/natefinch/lumberjack.v2"
@@ -133,3 +136,52 @@ func TestWriters_OnlyOneStream(t *testing.T) {
 		t.Fatalf("stderr not created: %v", err)
 	}
 }
+
+func TestWriters_CombineStreams(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{File: FileConfig{Dir: dir, CombineStreams: true}}
+	if out, errPath := cfg.FilePaths("demo"); out != filepath.Join(dir, "demo.log") || errPath != out {
+		t.Fatalf("FilePaths = %q, %q, want both %s", out, errPath, filepath.Join(dir, "demo.log"))
+	}
+	outW, errW, err := cfg.ProcessWriters("demo")
+	if err != nil {
+		t.Fatalf("ProcessWriters error: %v", err)
+	}
+
+	// Both pipes write at once; every line must land whole in the one file.
+	const lines = 200
+	var wg sync.WaitGroup
+	for stream, w := range map[string]io.Writer{"out": outW, "err": errW} {
+		wg.Add(1)
+		go func(stream string, w io.Writer) {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				_, _ = fmt.Fprintf(w, "%s-%03d\n", stream, i)
+			}
+		}(stream, w)
+	}
+	wg.Wait()
+	closeIf(outW)
+	closeIf(errW)
+
+	b, err := os.ReadFile(filepath.Join(dir, "demo.log"))
+	if err != nil {
+		t.Fatalf("combined log not created: %v", err)
+	}
+	next := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+		stream, n, ok := strings.Cut(line, "-")
+		if !ok || fmt.Sprintf("%03d", next[stream]) != n {
+			t.Fatalf("line %q out of order or torn (next %s line is %d)", line, stream, next[stream])
+		}
+		next[stream]++
+	}
+	if next["out"] != lines || next["err"] != lines {
+		t.Fatalf("lines per stream = %v, want %d each", next, lines)
+	}
+	for _, name := range []string{"demo.stdout.log", "demo.stderr.log"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Fatalf("%s exists with streams combined", name)
+		}
+	}
+}
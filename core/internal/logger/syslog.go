@@ -0,0 +1,347 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogConfig configures forwarding of structured logs to syslog.
+// Messages are formatted per RFC 5424. An empty Network dials the local
+// syslog socket (/dev/log); "udp" and "tcp" send to a remote Address.
+type SyslogConfig struct {
+	Enabled  bool      `json:"enabled" mapstructure:"enabled"`
+	Network  string    `json:"network,omitempty" mapstructure:"network"`   // "" (local socket), "udp", or "tcp"
+	Address  string    `json:"address,omitempty" mapstructure:"address"`   // host:port for udp/tcp; socket path override for local
+	Facility string    `json:"facility,omitempty" mapstructure:"facility"` // kern, user, daemon, auth, local0..local7, ... (default daemon)
+	Tag      string    `json:"tag,omitempty" mapstructure:"tag"`           // RFC 5424 APP-NAME (default "provisr")
+	Fallback io.Writer `json:"-" mapstructure:"-"`                         // receives messages while syslog is unreachable (default: os.Stderr)
+}
+
+const (
+	defaultSyslogTag      = "provisr"
+	syslogDialTimeout     = 2 * time.Second
+	syslogWriteTimeout    = 2 * time.Second
+	syslogRedialInterval  = 5 * time.Second
+	syslogQueueSize       = 1024 // messages waiting to be sent before the fallback takes over
+	syslogMaxHostnameSize = 255
+)
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// localSyslogPaths mirrors the candidates log/syslog probes for the local daemon.
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Validate checks the network and facility names.
+func (c *SyslogConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Network {
+	case "", "unix", "unixgram":
+	case "udp", "tcp":
+		if strings.TrimSpace(c.Address) == "" {
+			return fmt.Errorf("syslog address is required for network %q", c.Network)
+		}
+	default:
+		return fmt.Errorf("invalid syslog network %q, must be one of: udp, tcp (or empty for the local socket)", c.Network)
+	}
+	if _, ok := syslogFacilities[c.facilityName()]; !ok {
+		return fmt.Errorf("invalid syslog facility %q", c.Facility)
+	}
+	return nil
+}
+
+func (c *SyslogConfig) facilityName() string {
+	if c.Facility == "" {
+		return "daemon"
+	}
+	return strings.ToLower(c.Facility)
+}
+
+// SyslogWriter sends RFC 5424 messages to a syslog daemon. Messages are
+// queued and sent by a background goroutine, so a slow or unreachable
+// daemon never blocks the caller: a message goes to the fallback writer
+// instead when the queue is full or the daemon can't be reached, and
+// reconnection is retried at most every few seconds.
+type SyslogWriter struct {
+	cfg      SyslogConfig
+	facility int
+	tag      string
+	hostname string
+	dial     func(network, address string, timeout time.Duration) (net.Conn, error)
+
+	mu     sync.Mutex // guards closed and sends on queue
+	closed bool
+	queue  chan string
+	done   chan struct{} // closed when the sender has exited
+
+	// Owned by the sender goroutine.
+	conn     net.Conn
+	lastDial time.Time
+
+	fallbackMu sync.Mutex
+	fallback   io.Writer
+}
+
+// NewSyslogWriter validates cfg and starts connecting in the background. An
+// unreachable daemon is not an error; messages go to the fallback writer
+// until a later reconnect succeeds.
+func NewSyslogWriter(cfg SyslogConfig) (*SyslogWriter, error) {
+	return newSyslogWriter(cfg, net.DialTimeout)
+}
+
+func newSyslogWriter(cfg SyslogConfig, dial func(string, string, time.Duration) (net.Conn, error)) (*SyslogWriter, error) {
+	cfg.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" || len(hostname) > syslogMaxHostnameSize {
+		hostname = "-"
+	}
+	tag := cfg.Tag
+	if tag == "" {
+		tag = defaultSyslogTag
+	}
+	fallback := cfg.Fallback
+	if fallback == nil {
+		fallback = os.Stderr
+	}
+	w := &SyslogWriter{
+		cfg:      cfg,
+		facility: syslogFacilities[cfg.facilityName()],
+		tag:      tag,
+		hostname: hostname,
+		dial:     dial,
+		queue:    make(chan string, syslogQueueSize),
+		done:     make(chan struct{}),
+		fallback: fallback,
+	}
+	go w.send()
+	return w, nil
+}
+
+// send delivers queued messages until the queue is closed.
+func (w *SyslogWriter) send() {
+	defer close(w.done)
+	_ = w.connect()
+	for line := range w.queue {
+		if w.conn == nil && time.Since(w.lastDial) >= syslogRedialInterval {
+			_ = w.connect()
+		}
+		if w.conn != nil {
+			payload := line
+			if w.cfg.Network == "tcp" || w.cfg.Network == "unix" {
+				// RFC 6587 octet-counting framing for stream transports.
+				payload = strconv.Itoa(len(line)) + " " + line
+			}
+			_ = w.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+			if _, err := io.WriteString(w.conn, payload); err == nil {
+				continue
+			}
+			_ = w.conn.Close()
+			w.conn = nil
+		}
+		_ = w.writeFallback(line)
+	}
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+func (w *SyslogWriter) connect() error {
+	w.lastDial = time.Now()
+	switch w.cfg.Network {
+	case "udp", "tcp":
+		conn, err := w.dial(w.cfg.Network, w.cfg.Address, syslogDialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+		return nil
+	default:
+		paths := localSyslogPaths
+		if w.cfg.Address != "" {
+			paths = []string{w.cfg.Address}
+		}
+		var lastErr error
+		for _, path := range paths {
+			for _, network := range []string{"unixgram", "unix"} {
+				if w.cfg.Network != "" && w.cfg.Network != network {
+					continue
+				}
+				conn, err := w.dial(network, path, syslogDialTimeout)
+				if err == nil {
+					w.conn = conn
+					return nil
+				}
+				lastErr = err
+			}
+		}
+		return lastErr
+	}
+}
+
+func (w *SyslogWriter) writeFallback(line string) error {
+	w.fallbackMu.Lock()
+	defer w.fallbackMu.Unlock()
+	_, err := io.WriteString(w.fallback, line+"\n")
+	return err
+}
+
+// severityForLevel maps slog levels onto RFC 5424 severities.
+func severityForLevel(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3 // err
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level >= slog.LevelInfo:
+		return 6 // info
+	default:
+		return 7 // debug
+	}
+}
+
+// WriteMessage queues msg with the severity derived from level. It never
+// waits for the daemon: with the queue full, or the writer closed, msg is
+// written to the fallback writer at once.
+func (w *SyslogWriter) WriteMessage(level slog.Level, t time.Time, msg string) error {
+	pri := w.facility*8 + severityForLevel(level)
+	line := fmt.Sprintf("<%d>1 %s %s %s %s - - %s",
+		pri, t.UTC().Format(time.RFC3339Nano), w.hostname, w.tag, strconv.Itoa(os.Getpid()), msg)
+
+	w.mu.Lock()
+	if !w.closed {
+		select {
+		case w.queue <- line:
+			w.mu.Unlock()
+			return nil
+		default:
+		}
+	}
+	w.mu.Unlock()
+	return w.writeFallback(line)
+}
+
+// Close sends the messages still queued, then releases the connection to
+// the syslog daemon.
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	<-w.done
+	return nil
+}
+
+// syslogWriters shares one connection per distinct syslog destination so
+// building a logger per process doesn't open a socket per process. Loggers
+// are never torn down, so every writer handed out must be cached here; the
+// Fallback of the config that first opened a destination is the one used.
+var (
+	syslogWritersMu sync.Mutex
+	syslogWriters   = map[SyslogConfig]*SyslogWriter{}
+)
+
+func sharedSyslogWriter(cfg SyslogConfig) (*SyslogWriter, error) {
+	key := cfg
+	key.Fallback = nil // not comparable in general, and not part of the destination
+	syslogWritersMu.Lock()
+	defer syslogWritersMu.Unlock()
+	if w, ok := syslogWriters[key]; ok {
+		return w, nil
+	}
+	w, err := NewSyslogWriter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	syslogWriters[key] = w
+	return w, nil
+}
+
+// SyslogHandler is a slog.Handler that renders records in logfmt and sends
+// them to a SyslogWriter, mapping the record level to syslog severity.
+type SyslogHandler struct {
+	w    *SyslogWriter
+	opts slog.HandlerOptions
+	ops  []func(slog.Handler) slog.Handler
+}
+
+// NewSyslogHandler creates a handler writing to w.
+func NewSyslogHandler(w *SyslogWriter, opts *slog.HandlerOptions) *SyslogHandler {
+	h := &SyslogHandler{w: w}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled implements slog.Handler
+func (h *SyslogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle implements slog.Handler
+func (h *SyslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	// Timestamp and severity are carried in the syslog header.
+	var inner slog.Handler = slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level:     slog.LevelDebug,
+		AddSource: h.opts.AddSource,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	for _, op := range h.ops {
+		inner = op(inner)
+	}
+	if err := inner.Handle(ctx, r); err != nil {
+		return err
+	}
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return h.w.WriteMessage(r.Level, t, strings.TrimRight(buf.String(), "\n"))
+}
+
+// WithAttrs implements slog.Handler
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(inner slog.Handler) slog.Handler { return inner.WithAttrs(attrs) })
+}
+
+// WithGroup implements slog.Handler
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(inner slog.Handler) slog.Handler { return inner.WithGroup(name) })
+}
+
+func (h *SyslogHandler) with(op func(slog.Handler) slog.Handler) *SyslogHandler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &SyslogHandler{w: h.w, opts: h.opts, ops: append(ops, op)}
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslog_UDPListenerReceivesRFC5424(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp listener unavailable: %v", err)
+	}
+	defer func() { _ = pc.Close() }()
+
+	var console bytes.Buffer
+	cfg := Config{
+		Slog: SlogConfig{Level: LevelInfo, Format: FormatText, Output: &console},
+		Syslog: SyslogConfig{
+			Enabled:  true,
+			Network:  "udp",
+			Address:  pc.LocalAddr().String(),
+			Facility: "local0",
+			Tag:      "provisr-test",
+		},
+	}
+	cfg.NewProcessLogger("worker").Warn("disk almost full", "pct", 93)
+
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 4096)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("no syslog datagram received: %v", err)
+	}
+	msg := string(buf[:n])
+
+	// local0 (16) * 8 + warning (4) = 132
+	if !strings.HasPrefix(msg, "<132>1 ") {
+		t.Fatalf("unexpected PRI/version header: %q", msg)
+	}
+	for _, want := range []string{" provisr-test ", "disk almost full", "process=worker", "pct=93"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("syslog message %q missing %q", msg, want)
+		}
+	}
+	if strings.Contains(msg, "level=") {
+		t.Fatalf("level should be carried in PRI only, got %q", msg)
+	}
+	if !strings.Contains(console.String(), "disk almost full") {
+		t.Fatalf("primary handler should still receive the record, got %q", console.String())
+	}
+}
+
+func TestSyslog_RespectsLevel(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp listener unavailable: %v", err)
+	}
+	defer func() { _ = pc.Close() }()
+
+	cfg := Config{
+		Slog:   SlogConfig{Level: LevelWarn, Output: &bytes.Buffer{}},
+		Syslog: SyslogConfig{Enabled: true, Network: "udp", Address: pc.LocalAddr().String()},
+	}
+	logger := cfg.NewSlogger()
+	logger.Info("filtered")
+	logger.Error("kept")
+
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 4096)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("no syslog datagram received: %v", err)
+	}
+	if msg := string(buf[:n]); !strings.Contains(msg, "kept") || !strings.HasPrefix(msg, "<27>1 ") {
+		t.Fatalf("expected only the error record (daemon.err), got %q", msg)
+	}
+}
+
+func TestSyslog_FallsBackWhenUnreachable(t *testing.T) {
+	// Reserve a port, then close it so the TCP dial is refused.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("tcp listener unavailable: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	var fallback bytes.Buffer
+	w, err := NewSyslogWriter(SyslogConfig{Network: "tcp", Address: addr, Fallback: &fallback})
+	if err != nil {
+		t.Fatalf("NewSyslogWriter: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_ = w.WriteMessage(slog.LevelInfo, time.Now(), "still logged")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("write blocked while syslog was unreachable")
+	}
+	_ = w.Close()
+	if !strings.Contains(fallback.String(), "still logged") {
+		t.Fatalf("expected fallback output, got %q", fallback.String())
+	}
+}
+
+func TestSyslog_SharedWriterPerDestination(t *testing.T) {
+	var fallback bytes.Buffer
+	cfg := SyslogConfig{Enabled: true, Network: "udp", Address: "127.0.0.1:1", Tag: "shared-test", Fallback: &fallback}
+	first, err := sharedSyslogWriter(cfg)
+	if err != nil {
+		t.Fatalf("sharedSyslogWriter: %v", err)
+	}
+	cfg.Fallback = nil
+	if second, err := sharedSyslogWriter(cfg); err != nil || second != first {
+		t.Fatalf("expected the writer to be shared, got %p and %p (%v)", first, second, err)
+	}
+}
+
+func TestSyslog_WriteDoesNotWaitForDial(t *testing.T) {
+	release := make(chan struct{})
+	dial := func(string, string, time.Duration) (net.Conn, error) {
+		<-release // a blackholed target
+		return nil, errors.New("unreachable")
+	}
+	var fallback bytes.Buffer
+	start := time.Now()
+	w, err := newSyslogWriter(SyslogConfig{Network: "tcp", Address: "192.0.2.1:514", Fallback: &fallback}, dial)
+	if err != nil {
+		t.Fatalf("newSyslogWriter: %v", err)
+	}
+	// More lines than the queue holds: the rest go to the fallback at once.
+	for i := 0; i < syslogQueueSize+10; i++ {
+		if err := w.WriteMessage(slog.LevelInfo, time.Now(), fmt.Sprintf("line %d", i)); err != nil {
+			t.Fatalf("WriteMessage: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("writes took %v while the dial hung", elapsed)
+	}
+
+	close(release)
+	_ = w.Close()
+	for _, want := range []string{"line 0\n", fmt.Sprintf("line %d\n", syslogQueueSize+9)} {
+		if !strings.Contains(fallback.String(), want) {
+			t.Fatalf("fallback missing %q", want)
+		}
+	}
+}
+
+func TestSyslogConfig_Validate(t *testing.T) {
+	cases := []struct {
+		cfg     SyslogConfig
+		wantErr bool
+	}{
+		{SyslogConfig{}, false},
+		{SyslogConfig{Enabled: true}, false},
+		{SyslogConfig{Enabled: true, Network: "udp", Address: "127.0.0.1:514", Facility: "LOCAL3"}, false},
+		{SyslogConfig{Enabled: true, Network: "udp"}, true},
+		{SyslogConfig{Enabled: true, Network: "sctp", Address: "x:1"}, true},
+		{SyslogConfig{Enabled: true, Facility: "nope"}, true},
+	}
+	for i, tc := range cases {
+		err := tc.cfg.Validate()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("case %d: Validate() = %v, wantErr %v", i, err, tc.wantErr)
+		}
+	}
+}
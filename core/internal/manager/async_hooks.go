@@ -0,0 +1,121 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/loykin/provisr/core/internal/process"
+)
+
+// asyncHookKillGrace bounds how long settleAsyncHooks waits for a killed
+// async hook to be reaped.
+const asyncHookKillGrace = time.Second
+
+// AsyncHookInfo describes an async lifecycle hook that is still running.
+type AsyncHookInfo struct {
+	Phase     string    `json:"phase"`
+	Name      string    `json:"name"`
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// asyncHook tracks one running RunModeAsync hook. done is closed once the
+// hook has been reaped.
+type asyncHook struct {
+	info   AsyncHookInfo
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (up *ManagedProcess) trackAsyncHook(info AsyncHookInfo, cancel context.CancelFunc) *asyncHook {
+	ah := &asyncHook{info: info, cancel: cancel, done: make(chan struct{})}
+	up.mu.Lock()
+	up.asyncHooks = append(up.asyncHooks, ah)
+	up.mu.Unlock()
+	return ah
+}
+
+func (up *ManagedProcess) untrackAsyncHook(ah *asyncHook) {
+	up.mu.Lock()
+	for i, h := range up.asyncHooks {
+		if h == ah {
+			up.asyncHooks = append(up.asyncHooks[:i], up.asyncHooks[i+1:]...)
+			break
+		}
+	}
+	up.mu.Unlock()
+	close(ah.done)
+}
+
+// PendingAsyncHooks lists async hooks that have not exited yet.
+func (up *ManagedProcess) PendingAsyncHooks() []AsyncHookInfo {
+	up.mu.RLock()
+	defer up.mu.RUnlock()
+	out := make([]AsyncHookInfo, 0, len(up.asyncHooks))
+	for _, ah := range up.asyncHooks {
+		out = append(out, ah.info)
+	}
+	return out
+}
+
+// settleAsyncHooks gives pending async hooks of the given phases (all
+// phases when none are given) up to wait to finish, then kills the rest.
+func (up *ManagedProcess) settleAsyncHooks(wait time.Duration, phases ...process.LifecyclePhase) {
+	up.mu.RLock()
+	var pending []*asyncHook
+	for _, ah := range up.asyncHooks {
+		if len(phases) == 0 || containsPhase(phases, ah.info.Phase) {
+			pending = append(pending, ah)
+		}
+	}
+	up.mu.RUnlock()
+
+	deadline := time.Now().Add(wait)
+	for _, ah := range pending {
+		if remaining := time.Until(deadline); remaining > 0 {
+			select {
+			case <-ah.done:
+				continue
+			case <-time.After(remaining):
+			}
+		}
+		select {
+		case <-ah.done:
+			continue
+		default:
+		}
+		up.log().Warn("Killing async hook that outlived its process",
+			"phase", ah.info.Phase, "hook", ah.info.Name, "pid", ah.info.PID)
+		ah.cancel()
+		select {
+		case <-ah.done:
+		case <-time.After(asyncHookKillGrace):
+			up.log().Warn("Async hook did not exit after kill", "phase", ah.info.Phase, "hook", ah.info.Name, "pid", ah.info.PID)
+		}
+	}
+}
+
+// awaitAsyncHooks waits for pending async hooks of the given phases to
+// finish. Each is bounded by its own Timeout, after which it is killed.
+func (up *ManagedProcess) awaitAsyncHooks(phases ...process.LifecyclePhase) {
+	up.mu.RLock()
+	var pending []*asyncHook
+	for _, ah := range up.asyncHooks {
+		if containsPhase(phases, ah.info.Phase) {
+			pending = append(pending, ah)
+		}
+	}
+	up.mu.RUnlock()
+	for _, ah := range pending {
+		<-ah.done
+	}
+}
+
+func containsPhase(phases []process.LifecyclePhase, phase string) bool {
+	for _, p := range phases {
+		if p.String() == phase {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/loykin/provisr/core/history"
+	"github.com/loykin/provisr/core/observability"
+)
+
+// eventHub is a history sink that fans lifecycle events out to live
+// subscribers. It never blocks a process: a subscriber whose buffer is full
+// misses the event.
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[chan history.Event]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[chan history.Event]struct{})}
+}
+
+func (h *eventHub) Send(_ context.Context, e history.Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+	return nil
+}
+
+func (h *eventHub) subscribe(buffer int) (<-chan history.Event, func()) {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	ch := make(chan history.Event, buffer)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// SubscribeEvents returns a channel receiving the lifecycle events of every
+// process from now on, whether or not history sinks are configured, and a
+// function that ends the subscription and closes the channel. Events are
+// dropped while the channel's buffer (64 when buffer <= 0) is full.
+func (m *Manager) SubscribeEvents(buffer int) (<-chan history.Event, func()) {
+	return m.events.subscribe(buffer)
+}
+
+// historySinks returns the sinks handed to new processes: the configured
+// ones plus the subscriber hub. Callers must hold m.mu.
+func (m *Manager) historySinks() []history.Sink {
+	return append(append([]history.Sink(nil), m.histSinks...), m.events)
+}
+
+// Transition is one state machine transition of a managed process, e.g.
+// starting to running.
+type Transition struct {
+	Time time.Time
+	Name string
+	From string
+	To   string
+}
+
+// SubscribeTransitions returns a channel receiving every state transition
+// of every process from now on, and a function that ends the subscription
+// and closes the channel. Transitions are dropped while the channel's
+// buffer (64 when buffer <= 0) is full.
+func (m *Manager) SubscribeTransitions(buffer int) (<-chan Transition, func()) {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	ch := make(chan Transition, buffer)
+	var (
+		mu     sync.Mutex
+		closed bool
+	)
+	unsubscribe := m.emitter.Subscribe(observability.ObserverFunc(func(e observability.Event) {
+		if e.Kind != observability.ProcessStateChanged {
+			return
+		}
+		t := Transition{Time: time.Now(), Name: e.Name, From: e.From, To: e.To}
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case ch <- t:
+		default:
+		}
+	}))
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			unsubscribe()
+			mu.Lock()
+			closed = true
+			close(ch)
+			mu.Unlock()
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package manager
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// hookOutputTailLines is how many trailing output lines a failed hook's
+	// error carries.
+	hookOutputTailLines = 20
+	// hookMaxLineBytes bounds a single unterminated output line; longer
+	// lines are emitted in pieces.
+	hookMaxLineBytes = 4096
+	// hookOutputWaitDelay is how long Wait keeps reading a killed hook's
+	// output pipes before closing them.
+	hookOutputWaitDelay = 200 * time.Millisecond
+)
+
+// hookOutput collects a hook's stdout and stderr line by line, forwarding
+// each line to emit as it arrives and keeping the last few for the error
+// reported when the hook fails.
+type hookOutput struct {
+	mu   sync.Mutex
+	emit func(stream, line string)
+	tail []string
+}
+
+func newHookOutput(emit func(stream, line string)) *hookOutput {
+	return &hookOutput{emit: emit}
+}
+
+func (o *hookOutput) line(stream, text string) {
+	o.mu.Lock()
+	o.tail = append(o.tail, text)
+	if len(o.tail) > hookOutputTailLines {
+		o.tail = o.tail[len(o.tail)-hookOutputTailLines:]
+	}
+	o.mu.Unlock()
+	o.emit(stream, text)
+}
+
+// Tail returns the last captured lines joined by newlines.
+func (o *hookOutput) Tail() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return strings.Join(o.tail, "\n")
+}
+
+// writer returns an io.Writer for one stream. Call flush on it after the
+// hook exits to emit a trailing line without a newline.
+func (o *hookOutput) writer(stream string) *hookStreamWriter {
+	return &hookStreamWriter{out: o, stream: stream}
+}
+
+type hookStreamWriter struct {
+	out     *hookOutput
+	stream  string
+	pending []byte
+}
+
+func (w *hookStreamWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		idx := bytes.IndexByte(w.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		w.out.line(w.stream, string(bytes.TrimRight(w.pending[:idx], "\r")))
+		w.pending = w.pending[idx+1:]
+	}
+	for len(w.pending) > hookMaxLineBytes {
+		w.out.line(w.stream, string(w.pending[:hookMaxLineBytes]))
+		w.pending = w.pending[hookMaxLineBytes:]
+	}
+	return len(p), nil
+}
+
+func (w *hookStreamWriter) flush() {
+	if len(w.pending) > 0 {
+		w.out.line(w.stream, string(w.pending))
+		w.pending = nil
+	}
+}
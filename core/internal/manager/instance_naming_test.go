@@ -0,0 +1,33 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr/core/instancename"
+	"github.com/loykin/provisr/core/internal/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomInstanceNaming(t *testing.T) {
+	naming, err := instancename.New("{{.Base}}_{{.Index}}", 0, 2)
+	require.NoError(t, err)
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	mgr.SetInstanceNaming(naming)
+
+	require.NoError(t, mgr.RegisterN(process.Spec{Name: "worker", Command: "sleep 30", Instances: 2}))
+	assert.Equal(t, []string{"worker_00", "worker_01"}, runningNames(t, mgr, "worker"))
+	base, err := mgr.ProcessBase("worker_01")
+	require.NoError(t, err)
+	assert.Equal(t, "worker", base)
+
+	require.NoError(t, mgr.Scale("worker", 3))
+	assert.Equal(t, []string{"worker_00", "worker_01", "worker_02"}, runningNames(t, mgr, "worker"))
+
+	base, err = mgr.UnregisterInstances("worker_02", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "worker", base)
+	assert.Empty(t, runningNames(t, mgr, "worker"))
+}
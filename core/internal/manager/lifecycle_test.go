@@ -1,7 +1,13 @@
 package manager
 
 import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -338,3 +344,295 @@ func TestManagedProcess_AsyncHookExecution(t *testing.T) {
 
 	_ = mp.Shutdown()
 }
+
+func TestManagedProcess_HookRetriesUntilSuccess(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "attempts")
+	// Fails on the first two attempts, succeeds on the third.
+	flaky := fmt.Sprintf(`n=$(cat %q 2>/dev/null || echo 0); n=$((n+1)); echo $n > %q; [ "$n" -ge 3 ]`, counter, counter)
+	retries := 2
+	hook := process.Hook{
+		Name:          "flaky-setup",
+		Command:       flaky,
+		FailureMode:   process.FailureModeRetry,
+		MaxRetries:    &retries,
+		RetryInterval: 50 * time.Millisecond,
+		RetryBackoff:  2,
+	}
+	spec := process.Spec{
+		Name:      "retry-process",
+		Command:   "echo ok",
+		Lifecycle: process.LifecycleHooks{PreStart: []process.Hook{hook}},
+	}
+	mp := NewManagedProcess(spec, func(s process.Spec) []string { return s.Env })
+
+	start := time.Now()
+	if err := mp.executeLifecycleHooks(spec, process.PhasePreStart); err != nil {
+		t.Fatalf("hook should succeed on the third attempt: %v", err)
+	}
+	// 50ms then 100ms between attempts.
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("retries did not wait for the backoff delays, took %v", elapsed)
+	}
+	sums := mp.HookSummaries()
+	if len(sums) != 1 || sums[0].Runs != 3 || sums[0].Failures != 2 || sums[0].LastError != "" {
+		t.Fatalf("unexpected hook summary: %+v", sums)
+	}
+
+	// With a single retry the same hook gives up after two attempts.
+	_ = os.Remove(counter)
+	retries = 1
+	spec.Lifecycle.PreStart = []process.Hook{hook}
+	err := mp.executeLifecycleHooks(spec, process.PhasePreStart)
+	if err == nil || !strings.Contains(err.Error(), "failed after 1 retries") {
+		t.Fatalf("expected failure after 1 retry, got %v", err)
+	}
+
+	// An explicit zero gives up after the first attempt.
+	_ = os.Remove(counter)
+	retries = 0
+	err = mp.executeLifecycleHooks(spec, process.PhasePreStart)
+	if err == nil || !strings.Contains(err.Error(), "failed after 0 retries") {
+		t.Fatalf("expected failure without retries, got %v", err)
+	}
+	if data, _ := os.ReadFile(counter); strings.TrimSpace(string(data)) != "1" {
+		t.Fatalf("hook ran %s times, want 1", strings.TrimSpace(string(data)))
+	}
+}
+
+func TestManagedProcess_HookOutputCaptured(t *testing.T) {
+	var logBuf bytes.Buffer
+	spec := process.Spec{
+		Name:    "hook-output",
+		Command: "echo ok",
+		Lifecycle: process.LifecycleHooks{PreStart: []process.Hook{{
+			Name:    "check-db",
+			Command: `echo "connecting to db"; echo "db unreachable: connection refused" >&2; printf "no newline"; exit 1`,
+		}}},
+	}
+	mp := NewManagedProcess(spec, func(s process.Spec) []string { return s.Env })
+	mp.SetLoggerFactory(func(s process.Spec) *slog.Logger {
+		return slog.New(slog.NewTextHandler(&logBuf, nil)).With("process", s.Name)
+	})
+	mp.refreshLogger(spec)
+
+	err := mp.executeLifecycleHooks(spec, process.PhasePreStart)
+	if err == nil {
+		t.Fatal("expected the hook to fail")
+	}
+	for _, want := range []string{"db unreachable: connection refused", "connecting to db", "no newline"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should include hook output %q, got: %v", want, err)
+		}
+	}
+
+	logged := logBuf.String()
+	for _, want := range []string{"phase=pre_start", "hook=check-db", "stream=stderr", `line="db unreachable: connection refused"`} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("structured log missing %q:\n%s", want, logged)
+		}
+	}
+
+	lines, _ := mp.LogsSince(0, 0)
+	found := false
+	for _, l := range lines {
+		if l.Stream == "hook" && l.Text == "[pre_start/check-db] connecting to db" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("hook output missing from live-tail buffer: %+v", lines)
+	}
+}
+
+func TestHookOutputTailIsBounded(t *testing.T) {
+	out := newHookOutput(func(string, string) {})
+	w := out.writer("stdout")
+	for i := 0; i < hookOutputTailLines+5; i++ {
+		_, _ = fmt.Fprintf(w, "line %d\n", i)
+	}
+	tail := strings.Split(out.Tail(), "\n")
+	if len(tail) != hookOutputTailLines || tail[0] != "line 5" {
+		t.Fatalf("expected the last %d lines, got %d starting with %q", hookOutputTailLines, len(tail), tail[0])
+	}
+}
+
+func TestManagedProcess_HookLeavingBackgroundChildSucceeds(t *testing.T) {
+	spec := process.Spec{
+		Name:    "bg-hook",
+		Command: "echo ok",
+		Lifecycle: process.LifecycleHooks{PreStart: []process.Hook{{
+			Name:    "spawn-helper",
+			Command: "sleep 2 & echo started",
+		}}},
+	}
+	mp := NewManagedProcess(spec, func(s process.Spec) []string { return s.Env })
+	defer func() { _ = mp.Shutdown() }()
+
+	start := time.Now()
+	if err := mp.executeLifecycleHooks(spec, process.PhasePreStart); err != nil {
+		t.Fatalf("hook exited 0 and should succeed despite its background child: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("hook blocked on its background child's output for %v", elapsed)
+	}
+}
+
+func TestManagedProcess_AsyncHookKilledOnShutdown(t *testing.T) {
+	spec := process.Spec{
+		Name:    "async-cleanup",
+		Command: "sleep 30",
+		Lifecycle: process.LifecycleHooks{PostStart: []process.Hook{{
+			Name:    "long-notify",
+			Command: "exec sleep 30",
+			RunMode: process.RunModeAsync,
+		}}},
+	}
+	mp := NewManagedProcess(spec, func(s process.Spec) []string { return s.Env })
+	if err := mp.Start(spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	pending := mp.PendingAsyncHooks()
+	if len(pending) != 1 || pending[0].Name != "long-notify" || pending[0].Phase != "post_start" || pending[0].PID == 0 {
+		t.Fatalf("expected the async hook to be tracked, got %+v", pending)
+	}
+	hookPID := pending[0].PID
+
+	if err := mp.Shutdown(); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if left := mp.PendingAsyncHooks(); len(left) != 0 {
+		t.Fatalf("async hooks left after shutdown: %+v", left)
+	}
+	if p, err := os.FindProcess(hookPID); err == nil {
+		if sigErr := p.Signal(syscall.Signal(0)); sigErr == nil {
+			t.Fatalf("async hook pid %d still alive after shutdown", hookPID)
+		}
+	}
+}
+
+func TestManagedProcess_AsyncHookAwaitedOnStop(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "done")
+	spec := process.Spec{
+		Name:    "async-await",
+		Command: "sleep 30",
+		Lifecycle: process.LifecycleHooks{
+			AsyncWait: 3 * time.Second,
+			PostStart: []process.Hook{{
+				Name:    "slow-notify",
+				Command: fmt.Sprintf("sleep 0.3 && touch %q", marker),
+				RunMode: process.RunModeAsync,
+			}},
+		},
+	}
+	mp := NewManagedProcess(spec, func(s process.Spec) []string { return s.Env })
+	defer func() { _ = mp.Shutdown() }()
+	if err := mp.Start(spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := mp.Stop(time.Second); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("stop should wait for the async hook to finish: %v", err)
+	}
+	if left := mp.PendingAsyncHooks(); len(left) != 0 {
+		t.Fatalf("async hooks left after stop: %+v", left)
+	}
+}
+
+func TestManagedProcess_AsyncPostStopHookCompletesOnShutdown(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "done")
+	spec := process.Spec{
+		Name:    "async-post-stop",
+		Command: "sleep 30",
+		Lifecycle: process.LifecycleHooks{PostStop: []process.Hook{{
+			Name:    "slow-cleanup",
+			Command: fmt.Sprintf("sleep 0.3 && touch %q", marker),
+			RunMode: process.RunModeAsync,
+		}}},
+	}
+	mp := NewManagedProcess(spec, func(s process.Spec) []string { return s.Env })
+	if err := mp.Start(spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := mp.Shutdown(); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("shutdown should let the async post_stop hook finish: %v", err)
+	}
+	if left := mp.PendingAsyncHooks(); len(left) != 0 {
+		t.Fatalf("async hooks left after shutdown: %+v", left)
+	}
+}
+
+func TestManagedProcess_HookEnvCarriesPIDAndExitStatus(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "pid")
+	exitFile := filepath.Join(dir, "exit")
+	spec := process.Spec{
+		Name:    "hook-exit-env",
+		Command: "sleep 30",
+		Lifecycle: process.LifecycleHooks{
+			PostStart: []process.Hook{{
+				Name:    "record-pid",
+				Command: fmt.Sprintf("echo $PROVISR_PID > %q", pidFile),
+			}},
+			PostStop: []process.Hook{{
+				Name:    "record-exit",
+				Command: fmt.Sprintf("echo \"$PROVISR_EXIT_CODE $PROVISR_EXIT_SIGNAL\" > %q", exitFile),
+			}},
+		},
+	}
+	mp := NewManagedProcess(spec, func(s process.Spec) []string { return s.Env })
+	defer func() { _ = mp.Shutdown() }()
+	if err := mp.Start(spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	pid := mp.Status().PID
+	if err := mp.Stop(2 * time.Second); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil || strings.TrimSpace(string(data)) != fmt.Sprint(pid) {
+		t.Fatalf("post_start hook saw PROVISR_PID=%q (err %v), want %d", data, err, pid)
+	}
+	data, err = os.ReadFile(exitFile)
+	if err != nil {
+		t.Fatalf("post_stop hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "-1 terminated" {
+		t.Fatalf("post_stop hook saw exit %q, want \"-1 terminated\"", got)
+	}
+}
+
+func TestManagedProcess_PostStopHookReadsExitCode(t *testing.T) {
+	exitFile := filepath.Join(t.TempDir(), "exit")
+	spec := process.Spec{
+		Name:    "hook-exit-code",
+		Command: "sh -c 'exit 3'",
+		Lifecycle: process.LifecycleHooks{
+			PostStop: []process.Hook{{
+				Name:    "record-exit",
+				Command: fmt.Sprintf("echo \"$PROVISR_EXIT_CODE:$PROVISR_EXIT_SIGNAL\" > %q", exitFile),
+			}},
+		},
+	}
+	mp := NewManagedProcess(spec, func(s process.Spec) []string { return s.Env })
+	defer func() { _ = mp.Shutdown() }()
+	if err := mp.Start(spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := mp.executeLifecycleHooks(spec, process.PhasePostStop); err != nil {
+		t.Fatalf("post_stop hooks: %v", err)
+	}
+	data, err := os.ReadFile(exitFile)
+	if err != nil {
+		t.Fatalf("post_stop hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "3:" {
+		t.Fatalf("post_stop hook saw %q, want \"3:\"", got)
+	}
+}
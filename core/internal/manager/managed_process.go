@@ -3,8 +3,10 @@ package manager
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"os/exec"
 	"sync"
 	"syscall"
@@ -33,10 +35,40 @@ type ManagedProcess struct {
 	doneChan      chan struct{}
 	lastRestartAt time.Time
 	history       []history.Sink
-	envMerger     func(process.Spec) []string
+	envProvider   func(process.Spec) ([]string, error)     // environment of each start; an error aborts it
+	intercept     func(process.Spec) (process.Spec, error) // vets each start first; nil for none
 	emitter       *observability.Emitter
+	newLogger     func(process.Spec) *slog.Logger
+	logger        *slog.Logger
+	healthDefault time.Duration // manager-wide health-check interval; 0 means DefaultHealthCheckInterval
+	restartJitter time.Duration // upper bound of the random delay added before each automatic restart
+	starts        *startLimiter // manager-wide cap on concurrent starts; nil for none
+	stopSignal    chan struct{} // closed and replaced by each Stop and Shutdown; aborts a start queued for a slot
+	healthReset   chan struct{} // wakes the state machine to pick up a new interval
+	hookRuns      []HookSummary // per-hook execution summaries, in first-run order
+	asyncHooks    []*asyncHook  // RunModeAsync hooks that are still running
+	restartFailed bool          // last automatic restart failed; fatal is reported once per streak
+
+	// opCtx is the trace context of the operation the state machine is
+	// handling, so hook spans and state transitions attach to the caller's span.
+	opCtx context.Context
 }
 
+// HookSummary aggregates the executions of one lifecycle hook.
+type HookSummary struct {
+	Phase        string        `json:"phase"`
+	Name         string        `json:"name"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+	LastRunAt    time.Time     `json:"last_run_at"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+}
+
+// DefaultHealthCheckInterval is how often a process is checked when neither
+// its spec nor the manager configures an interval.
+const DefaultHealthCheckInterval = time.Second
+
 // Recover seeds the process with a PID and spec loaded from a PID file and sets state accordingly.
 func (up *ManagedProcess) Recover(spec process.Spec, pid int) {
 	up.mu.Lock()
@@ -47,6 +79,12 @@ func (up *ManagedProcess) Recover(spec process.Spec, pid int) {
 	}
 	up.proc.SeedPID(pid)
 	up.mu.Unlock()
+	if pid > 0 && spec.PIDFile != "" {
+		if n, _, meta, err := process.ReadPIDFile(spec.PIDFile); err == nil && n == pid {
+			up.proc.SetPIDMeta(meta)
+		}
+	}
+	up.refreshLogger(spec)
 
 	alive, _ := up.proc.DetectAlive()
 	if alive {
@@ -56,6 +94,27 @@ func (up *ManagedProcess) Recover(spec process.Spec, pid int) {
 	}
 }
 
+// Adopt seeds the process with an orphan left running by an earlier daemon,
+// like Recover with a PID found by process.FindOrphans.
+func (up *ManagedProcess) Adopt(spec process.Spec, orphan process.Orphan) {
+	up.mu.Lock()
+	if up.proc == nil {
+		up.proc = process.New(spec)
+	} else {
+		up.proc.UpdateSpec(spec)
+	}
+	up.proc.SeedPID(orphan.PID)
+	up.proc.SetPIDMeta(&orphan.Meta)
+	up.mu.Unlock()
+	up.refreshLogger(spec)
+
+	if alive, _ := up.proc.DetectAlive(); alive {
+		up.setState(StateRunning)
+	} else {
+		up.setState(StateStopped)
+	}
+}
+
 type processState int32
 
 const (
@@ -88,6 +147,8 @@ type command struct {
 	spec   process.Spec
 	wait   time.Duration
 	reply  chan error
+	ctx    context.Context // caller's trace context; nil means none
+	stops  <-chan struct{} // for ActionStart, the stopSignal it was sent under
 }
 
 type commandAction int
@@ -110,18 +171,138 @@ func NewManagedProcess(
 		emitter = emitters[0]
 	}
 	up := &ManagedProcess{
-		state:     StateStopped,
-		proc:      process.New(spec),
-		cmdChan:   make(chan command, 16), // Buffered to prevent blocking
-		doneChan:  make(chan struct{}),
-		envMerger: envMerger,
-		emitter:   emitter,
+		state:       StateStopped,
+		proc:        process.New(spec),
+		cmdChan:     make(chan command, 16), // Buffered to prevent blocking
+		doneChan:    make(chan struct{}),
+		envProvider: func(spec process.Spec) ([]string, error) { return envMerger(spec), nil },
+		emitter:     emitter,
+		healthReset: make(chan struct{}, 1),
+		stopSignal:  make(chan struct{}),
 	}
 
 	go up.runStateMachine()
 	return up
 }
 
+// SetLoggerFactory installs the function used to build this process's
+// structured logger from its spec. The logger is rebuilt whenever the spec
+// changes (start, update, recover) so per-process level overrides take
+// effect on update and reload.
+func (up *ManagedProcess) SetLoggerFactory(newLogger func(process.Spec) *slog.Logger) {
+	up.mu.Lock()
+	up.newLogger = newLogger
+	up.logger = nil
+	up.mu.Unlock()
+}
+
+// SetDefaultHealthCheckInterval sets the interval used when the spec leaves
+// HealthCheckInterval unset. d <= 0 restores DefaultHealthCheckInterval.
+func (up *ManagedProcess) SetDefaultHealthCheckInterval(d time.Duration) {
+	up.mu.Lock()
+	up.healthDefault = d
+	up.mu.Unlock()
+	select {
+	case up.healthReset <- struct{}{}:
+	default:
+	}
+}
+
+// SetRestartJitter sets the upper bound of a random delay added before each
+// automatic restart, so processes that crashed together don't all restart
+// at the same instant. 0 restarts on the health check that notices the crash.
+func (up *ManagedProcess) SetRestartJitter(d time.Duration) {
+	up.mu.Lock()
+	up.restartJitter = d
+	up.mu.Unlock()
+}
+
+// setEnvProvider replaces the function computing the environment of each
+// start.
+func (up *ManagedProcess) setEnvProvider(provider func(process.Spec) ([]string, error)) {
+	up.mu.Lock()
+	up.envProvider = provider
+	up.mu.Unlock()
+}
+
+// setStartInterceptor installs the function that vets or adjusts the spec
+// of each start.
+func (up *ManagedProcess) setStartInterceptor(intercept func(process.Spec) (process.Spec, error)) {
+	up.mu.Lock()
+	up.intercept = intercept
+	up.mu.Unlock()
+}
+
+// setDaemonID marks the processes this starts with the daemon ID; see
+// Manager.SetDaemonID.
+func (up *ManagedProcess) setDaemonID(id string) {
+	up.mu.RLock()
+	proc := up.proc
+	up.mu.RUnlock()
+	proc.SetDaemonID(id)
+}
+
+// setStartLimiter makes starts wait for a slot of l first; see
+// Manager.SetMaxConcurrentStarts.
+func (up *ManagedProcess) setStartLimiter(l *startLimiter) {
+	up.mu.Lock()
+	up.starts = l
+	up.mu.Unlock()
+}
+
+// healthCheckInterval resolves the effective interval: spec, then manager
+// default, then DefaultHealthCheckInterval.
+func (up *ManagedProcess) healthCheckInterval() time.Duration {
+	up.mu.RLock()
+	proc, d := up.proc, up.healthDefault
+	up.mu.RUnlock()
+	if proc != nil {
+		if spec := proc.GetSpec(); spec.HealthCheckInterval > 0 {
+			return spec.HealthCheckInterval
+		}
+	}
+	if d > 0 {
+		return d
+	}
+	return DefaultHealthCheckInterval
+}
+
+// refreshLogger rebuilds the logger for spec. The factory is called outside
+// up.mu because it may take the manager lock (see Manager lock hierarchy).
+func (up *ManagedProcess) refreshLogger(spec process.Spec) {
+	up.mu.RLock()
+	newLogger := up.newLogger
+	up.mu.RUnlock()
+	if newLogger == nil {
+		return
+	}
+	l := newLogger(spec)
+	up.mu.Lock()
+	up.logger = l
+	up.mu.Unlock()
+}
+
+// log returns the per-process structured logger. Without a factory it
+// resolves slog.Default() on every call so embedders that replace the
+// default logger after constructing the manager still see these records.
+func (up *ManagedProcess) log() *slog.Logger {
+	up.mu.RLock()
+	l, newLogger := up.logger, up.newLogger
+	up.mu.RUnlock()
+	if l != nil {
+		return l
+	}
+	spec := up.proc.GetSpec()
+	if newLogger == nil {
+		return slog.Default().With(slog.String("process", spec.Name))
+	}
+	up.refreshLogger(*spec)
+	up.mu.RLock()
+	l = up.logger
+	up.mu.RUnlock()
+	return l
+}
+
 // SetHistory configures history sinks (thread-safe)
 func (up *ManagedProcess) SetHistory(sinks ...history.Sink) {
 	up.mu.Lock()
@@ -131,10 +312,16 @@ func (up *ManagedProcess) SetHistory(sinks ...history.Sink) {
 
 // Start initiates process start (non-blocking)
 func (up *ManagedProcess) Start(spec process.Spec) error {
+	return up.StartContext(context.Background(), spec)
+}
+
+// StartContext is Start with a caller context whose trace span becomes the
+// parent of the hook spans and receives the state transitions.
+func (up *ManagedProcess) StartContext(ctx context.Context, spec process.Spec) error {
 	reply := make(chan error, 1)
 
 	select {
-	case up.cmdChan <- command{action: ActionStart, spec: spec, reply: reply}:
+	case up.cmdChan <- command{action: ActionStart, spec: spec, reply: reply, ctx: ctx, stops: up.stops()}:
 		return <-reply
 	case <-up.doneChan:
 		return fmt.Errorf("process manager shutting down")
@@ -143,16 +330,44 @@ func (up *ManagedProcess) Start(spec process.Spec) error {
 
 // Stop initiates process stop (non-blocking)
 func (up *ManagedProcess) Stop(wait time.Duration) error {
+	return up.StopContext(context.Background(), wait)
+}
+
+// StopContext is Stop with a caller trace context; see StartContext.
+func (up *ManagedProcess) StopContext(ctx context.Context, wait time.Duration) error {
 	reply := make(chan error, 1)
+	up.signalStop()
 
 	select {
-	case up.cmdChan <- command{action: ActionStop, wait: wait, reply: reply}:
+	case up.cmdChan <- command{action: ActionStop, wait: wait, reply: reply, ctx: ctx}:
 		return <-reply
 	case <-up.doneChan:
 		return fmt.Errorf("process manager shutting down")
 	}
 }
 
+// Signal sends sig to the running process without going through the state
+// machine: it does not change state, and is refused while starting or
+// stopping.
+func (up *ManagedProcess) Signal(sig syscall.Signal) error {
+	up.mu.RLock()
+	state := up.state
+	proc := up.proc
+	up.mu.RUnlock()
+
+	if proc == nil {
+		return process.ErrNotRunning
+	}
+	if state != StateRunning {
+		return fmt.Errorf("process %s (state: %s): %w", proc.GetName(), state, process.ErrNotRunning)
+	}
+	if err := proc.Signal(sig); err != nil {
+		return err
+	}
+	up.log().Info("sent signal", "signal", sig.String())
+	return nil
+}
+
 // Status returns current status (lock-minimal)
 // LogsSince returns captured stdout/stderr lines for this process since the
 // given offset, plus the offset to pass as `since` on the next poll.
@@ -178,19 +393,21 @@ func (up *ManagedProcess) Status() process.Status {
 		return process.Status{}
 	}
 
-	spec := proc.GetSpec()
-
 	// Get process status (process handles its own locking)
 	status := proc.Snapshot()
 	alive, detectedBy := proc.DetectAlive()
 
-	// Ensure name and state are properly set
-	status.Name = spec.Name
+	// Ensure name and state are properly set. The spec is not copied:
+	// StatusAll runs this for every process.
+	status.Name = proc.GetName()
 	status.Running = alive && state == StateRunning
+	if !status.Running {
+		status.Uptime = 0
+	}
 	status.DetectedBy = detectedBy
 	status.Restarts = restarts
 	status.State = state.String() // Add state machine state
-	status.Provisioned = spec.InlineConfig
+	status.Provisioned = proc.GetInlineConfig()
 
 	return status
 }
@@ -210,6 +427,7 @@ func (up *ManagedProcess) UpdateSpec(spec process.Spec) error {
 // Shutdown gracefully shuts down the process manager
 func (up *ManagedProcess) Shutdown() error {
 	reply := make(chan error, 1)
+	up.signalStop()
 
 	select {
 	case up.cmdChan <- command{action: ActionShutdown, reply: reply}:
@@ -219,51 +437,136 @@ func (up *ManagedProcess) Shutdown() error {
 	}
 }
 
+// stops returns the channel the next Stop or Shutdown closes.
+func (up *ManagedProcess) stops() <-chan struct{} {
+	up.mu.RLock()
+	defer up.mu.RUnlock()
+	return up.stopSignal
+}
+
+// signalStop aborts a start sent before it that is still queued for a
+// start slot, so the stop isn't stuck behind it.
+func (up *ManagedProcess) signalStop() {
+	up.mu.Lock()
+	close(up.stopSignal)
+	up.stopSignal = make(chan struct{})
+	up.mu.Unlock()
+}
+
 // runStateMachine is the core state machine (single goroutine, no races)
 func (up *ManagedProcess) runStateMachine() {
 	defer close(up.doneChan)
 
-	ticker := time.NewTicker(1 * time.Second) // Health check interval
+	checkEvery := up.healthCheckInterval()
+	ticker := time.NewTicker(checkEvery)
 	defer ticker.Stop()
 
+	// A restart delayed by the jitter fires through restartC.
+	var restartTimer *time.Timer
+	var restartC <-chan time.Time
+	defer func() {
+		if restartTimer != nil {
+			restartTimer.Stop()
+		}
+	}()
+
 	for {
 		select {
 		case cmd := <-up.cmdChan:
 			up.handleCommand(cmd)
 
+		case <-up.healthReset:
+
 		case <-ticker.C:
 			up.checkProcessHealth()
 
 			// Auto-restart when process is stopped and autoRestart is enabled
-			if up.proc != nil && up.proc.GetAutoStart() {
-				up.mu.RLock()
-				currentState := up.state
-				proc := up.proc
-				//spec := up.spec
-				spec := proc.GetSpec()
-				last := up.lastRestartAt
-				up.mu.RUnlock()
-
-				if currentState == StateStopped && proc != nil && !proc.StopRequested() {
-					alive, _ := proc.DetectAlive()
-					if !alive {
-						// Respect restart interval from spec (default small delay)
-						interval := spec.RestartInterval
-						if interval <= 0 {
-							interval = 3 * time.Second
-						}
-						if time.Since(last) >= interval {
-							// Attempt restart with last known spec
-							if err := up.doStart(*spec); err == nil {
-								up.mu.Lock()
-								up.lastRestartAt = time.Now()
-								up.restarts++
-								up.mu.Unlock()
-							}
-						}
+			if restartC == nil {
+				if delay, due := up.restartDue(); due {
+					if delay > 0 {
+						restartTimer = time.NewTimer(delay)
+						restartC = restartTimer.C
+					} else {
+						up.autoRestart()
 					}
 				}
 			}
+
+		case <-restartC:
+			restartTimer, restartC = nil, nil
+			// A manual start or stop may have happened while waiting.
+			if _, due := up.restartDue(); due {
+				up.autoRestart()
+			}
+		}
+
+		// The spec (UpdateSpec) or manager default may have changed the interval.
+		if d := up.healthCheckInterval(); d != checkEvery {
+			checkEvery = d
+			ticker.Reset(d)
+		}
+	}
+}
+
+// restartDue reports whether the process should be restarted automatically:
+// auto_restart is set, it died without being asked to stop and its restart
+// interval has passed. delay is the random jitter to wait first.
+func (up *ManagedProcess) restartDue() (delay time.Duration, due bool) {
+	if up.proc == nil || !up.proc.GetAutoStart() {
+		return 0, false
+	}
+	up.mu.RLock()
+	currentState := up.state
+	proc := up.proc
+	last := up.lastRestartAt
+	jitter := up.restartJitter
+	up.mu.RUnlock()
+
+	if currentState != StateStopped || proc == nil || proc.StopRequested() {
+		return 0, false
+	}
+	if alive, _ := proc.DetectAlive(); alive {
+		return 0, false
+	}
+	// Respect restart interval from spec (default small delay)
+	interval := proc.GetSpec().RestartInterval
+	if interval <= 0 {
+		interval = 3 * time.Second
+	}
+	if time.Since(last) < interval {
+		return 0, false
+	}
+	if jitter > 0 {
+		delay = rand.N(jitter)
+	}
+	return delay, true
+}
+
+// autoRestart restarts the process with its last known spec.
+func (up *ManagedProcess) autoRestart() {
+	spec := up.proc.GetSpec()
+	ctx, span := up.emitter.Tracer().Start(context.Background(), "process.restart",
+		observability.String("process.name", spec.Name))
+	up.setOpContext(ctx)
+	err := up.doStart(context.Background(), *spec, history.EventRestart, up.stops())
+	up.setOpContext(nil)
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+	if err == nil {
+		up.mu.Lock()
+		up.lastRestartAt = time.Now()
+		up.restarts++
+		up.restartFailed = false
+		up.mu.Unlock()
+	} else {
+		up.mu.Lock()
+		first := !up.restartFailed
+		up.restartFailed = true
+		up.mu.Unlock()
+		if first {
+			up.persistFatal(err)
 		}
 	}
 }
@@ -272,9 +575,12 @@ func (up *ManagedProcess) runStateMachine() {
 func (up *ManagedProcess) handleCommand(cmd command) {
 	var err error
 
+	up.setOpContext(cmd.ctx)
+	defer up.setOpContext(nil)
+
 	switch cmd.action {
 	case ActionStart:
-		err = up.handleStart(cmd.spec)
+		err = up.handleStart(cmd.ctx, cmd.spec, cmd.stops)
 	case ActionStop:
 		err = up.handleStop(cmd.wait)
 	case ActionUpdateSpec:
@@ -293,7 +599,7 @@ func (up *ManagedProcess) handleCommand(cmd command) {
 }
 
 // handleStart manages start logic with clear state transitions
-func (up *ManagedProcess) handleStart(newSpec process.Spec) error {
+func (up *ManagedProcess) handleStart(ctx context.Context, newSpec process.Spec, stops <-chan struct{}) error {
 	up.mu.Lock()
 	currentState := up.state
 	proc := up.proc
@@ -315,7 +621,7 @@ func (up *ManagedProcess) handleStart(newSpec process.Spec) error {
 		fallthrough
 
 	case StateStopped:
-		return up.doStart(newSpec)
+		return up.doStart(ctx, newSpec, history.EventStart, stops)
 
 	case StateStarting:
 		return fmt.Errorf("process '%s' is already starting, please wait or stop first", name)
@@ -328,58 +634,186 @@ func (up *ManagedProcess) handleStart(newSpec process.Spec) error {
 	}
 }
 
-// doStart performs the actual start operation
-func (up *ManagedProcess) doStart(newSpec process.Spec) error {
+// doStart performs the actual start operation. evt is the history event
+// recorded on success: EventStart, or EventRestart for automatic restarts.
+// Closing stops, or the end of the caller's ctx, abandons the start while it
+// waits for a start slot.
+func (up *ManagedProcess) doStart(ctx context.Context, newSpec process.Spec, evt history.EventType, stops <-chan struct{}) error {
+	up.mu.RLock()
+	starts := up.starts
+	envProvider := up.envProvider
+	intercept := up.intercept
+	up.mu.RUnlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Held while in StateStarting; every failure below leaves it at once.
+	queued, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-stops:
+			cancel()
+		case <-queued.Done():
+		}
+	}()
+	release, err := starts.acquire(queued)
+	cancel()
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("process %q: start abandoned while waiting for a start slot: %w", newSpec.Name, ctx.Err())
+		}
+		return fmt.Errorf("process %q: start aborted by stop while waiting for a start slot", newSpec.Name)
+	}
+	defer release()
 	up.setState(StateStarting)
+	began := time.Now()
+
+	// Before the hooks, so a vetoed start or a failed secret lookup runs
+	// nothing. The intercepted spec is used for this start only; the
+	// process keeps newSpec, so the next start is intercepted afresh.
+	runSpec := newSpec
+	if intercept != nil {
+		spec, err := intercept(newSpec)
+		if err != nil {
+			up.setState(StateStopped)
+			return fmt.Errorf("process %q: start interceptor: %w", newSpec.Name, err)
+		}
+		runSpec = spec
+		runSpec.Name = newSpec.Name
+	}
+	env, err := envProvider(runSpec)
+	if err != nil {
+		up.setState(StateStopped)
+		return fmt.Errorf("process %q: environment: %w", runSpec.Name, err)
+	}
 
 	// Execute PreStart hooks
-	if err := up.executeLifecycleHooks(newSpec, process.PhasePreStart); err != nil {
+	if err := up.executeLifecycleHooks(runSpec, process.PhasePreStart); err != nil {
 		up.setState(StateStopped)
 		return fmt.Errorf("pre_start hooks failed: %w", err)
 	}
 
+	// Checked after pre_start hooks, which may free the ports
+	if err := up.checkPorts(runSpec); err != nil {
+		up.setState(StateStopped)
+		return err
+	}
+
+	if err := process.EnsureWorkDir(runSpec); err != nil {
+		up.setState(StateStopped)
+		return fmt.Errorf("process %q: %w", runSpec.Name, err)
+	}
+	if err := process.EnsureLogDir(runSpec); err != nil {
+		up.setState(StateStopped)
+		return fmt.Errorf("process %q: %w", runSpec.Name, err)
+	}
+
 	// Update spec and process
 	up.mu.Lock()
-	//up.spec = newSpec
 	up.proc.UpdateSpec(newSpec)
 	up.mu.Unlock()
+	up.refreshLogger(newSpec)
 
 	// Start process (this is the heavy operation, done outside critical sections)
-	env := up.envMerger(newSpec)
-	cmd := up.proc.ConfigureCmd(env)
+	cmd := up.proc.ConfigureCmdFor(runSpec, env)
 
-	if err := up.proc.TryStart(cmd); err != nil {
+	if err := up.startWithTimeout(cmd, runSpec); err != nil {
 		up.setState(StateStopped)
-		return fmt.Errorf("failed to start process: %w", err)
-	}
-
-	// Enforce start duration if specified
-	if newSpec.StartDuration > 0 {
-		if err := up.proc.EnforceStartDuration(newSpec.StartDuration); err != nil {
-			up.proc.RemovePIDFile()
-			up.proc.MarkExited(err)
-			up.setState(StateStopped)
-			return fmt.Errorf("process exited before start duration: %w", err)
-		}
+		return err
 	}
 
 	// Successfully started
 	up.setState(StateRunning)
+	release()
+	startDuration := time.Since(began)
 
 	// Execute PostStart hooks (after process is confirmed running)
-	if err := up.executeLifecycleHooks(newSpec, process.PhasePostStart); err != nil {
-		slog.Warn("post_start hooks failed, but process is running", "process", newSpec.Name, "error", err)
+	if err := up.executeLifecycleHooks(runSpec, process.PhasePostStart); err != nil {
+		up.log().Warn("post_start hooks failed, but process is running", "error", err)
 		// Note: We don't stop the process here because it's already running successfully
 		// PostStart hook failures are typically non-critical (like notifications, setup, etc.)
 	}
 
 	// Record metrics and persist
-	up.emitter.Emit(observability.Event{Kind: observability.ProcessStarted, Name: newSpec.Name})
-	up.persistStart()
+	up.emitter.Emit(observability.Event{Kind: observability.ProcessStarted, Name: newSpec.Name, Duration: startDuration.Seconds()})
+	up.persistStart(evt)
 
 	return nil
 }
 
+// checkPorts fails when a port in spec.Ports is already listening, or only
+// logs a warning with PortCheck "warn".
+func (up *ManagedProcess) checkPorts(spec process.Spec) error {
+	if len(spec.Ports) == 0 {
+		return nil
+	}
+	busy := process.PortsInUse(spec.Ports)
+	if len(busy) == 0 {
+		return nil
+	}
+	if spec.PortCheck == process.PortCheckWarn {
+		up.log().Warn("ports already in use, starting anyway", "ports", busy)
+		return nil
+	}
+	return fmt.Errorf("process %q: %w", spec.Name, &process.PortInUseError{Ports: busy})
+}
+
+// startAbortGrace bounds how long a timed-out start waits for the spawn
+// goroutine to notice the kill before giving up on it.
+const startAbortGrace = time.Second
+
+// startWithTimeout spawns cmd and enforces StartDuration. When the spec sets
+// StartTimeout and the two don't finish in time, the spawned child (if any) is
+// killed and an error wrapping process.ErrStartTimeout is returned instead of
+// blocking the state machine on a wedged binary.
+func (up *ManagedProcess) startWithTimeout(cmd *exec.Cmd, spec process.Spec) error {
+	start := func() error {
+		if err := up.proc.TryStart(cmd); err != nil {
+			return fmt.Errorf("failed to start process: %w", err)
+		}
+		if spec.StartDuration > 0 {
+			if err := up.proc.EnforceStartDuration(spec.StartDuration); err != nil {
+				up.proc.RemovePIDFile()
+				up.proc.MarkExited(err)
+				return fmt.Errorf("process exited before start duration: %w", err)
+			}
+		}
+		return nil
+	}
+	if spec.StartTimeout <= 0 {
+		return start()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- start() }()
+
+	timer := time.NewTimer(spec.StartTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	}
+
+	up.log().Warn("start timed out, killing process", "timeout", spec.StartTimeout)
+	_ = up.proc.Kill()
+	select {
+	case <-done:
+	case <-time.After(startAbortGrace):
+		// TryStart itself is wedged; kill the child if it ever gets spawned.
+		go func() {
+			<-done
+			if cmd.Process != nil {
+				_ = cmd.Process.Kill()
+			}
+		}()
+	}
+	err := fmt.Errorf("process did not start within %s: %w", spec.StartTimeout, process.ErrStartTimeout)
+	up.proc.RemovePIDFile()
+	up.proc.MarkExited(err)
+	return err
+}
+
 // handleStop manages stop logic
 func (up *ManagedProcess) handleStop(wait time.Duration) error {
 	up.mu.RLock()
@@ -404,6 +838,7 @@ func (up *ManagedProcess) handleStop(wait time.Duration) error {
 // doStop performs the actual stop operation
 func (up *ManagedProcess) doStop(wait time.Duration) error {
 	up.setState(StateStopping)
+	began := time.Now()
 
 	// Get current spec for hook execution
 	up.mu.RLock()
@@ -413,7 +848,7 @@ func (up *ManagedProcess) doStop(wait time.Duration) error {
 	// Execute PreStop hooks
 	if spec != nil {
 		if err := up.executeLifecycleHooks(*spec, process.PhasePreStop); err != nil {
-			slog.Warn("pre_stop hooks failed, continuing with process stop", "process", spec.Name, "error", err)
+			up.log().Warn("pre_stop hooks failed, continuing with process stop", "error", err)
 			// Note: We continue with stopping the process even if PreStop hooks fail
 			// PreStop hooks are meant for cleanup/preparation, not to block stopping
 		}
@@ -462,19 +897,26 @@ func (up *ManagedProcess) doStop(wait time.Duration) error {
 	}
 
 	up.setState(StateStopped)
+	stopDuration := time.Since(began)
 	up.persistStop()
 
+	// Async hooks launched for the instance that just stopped must not
+	// outlive it indefinitely.
+	if spec != nil {
+		up.settleAsyncHooks(spec.Lifecycle.AsyncWait, process.PhasePreStart, process.PhasePostStart)
+	}
+
 	// Execute PostStop hooks after process has stopped
 	if spec != nil {
 		if err := up.executeLifecycleHooks(*spec, process.PhasePostStop); err != nil {
-			slog.Warn("post_stop hooks failed", "process", spec.Name, "error", err)
+			up.log().Warn("post_stop hooks failed", "error", err)
 			// Note: PostStop hook failures don't affect the stop operation result
 			// The process is already stopped at this point
 		}
 	}
 
 	// Record metrics
-	up.emitter.Emit(observability.Event{Kind: observability.ProcessStopped, Name: up.proc.GetName()})
+	up.emitter.Emit(observability.Event{Kind: observability.ProcessStopped, Name: up.proc.GetName(), Duration: stopDuration.Seconds()})
 
 	return nil
 }
@@ -484,6 +926,7 @@ func (up *ManagedProcess) handleUpdateSpec(newSpec process.Spec) error {
 	up.mu.Lock()
 	up.proc.UpdateSpec(newSpec)
 	up.mu.Unlock()
+	up.refreshLogger(newSpec)
 
 	return nil
 }
@@ -495,6 +938,13 @@ func (up *ManagedProcess) handleShutdown() error {
 		return err
 	}
 
+	// Don't let async hooks outlive the daemon. Stop hooks, such as the
+	// post_stop ones this stop just launched, do cleanup that must not be
+	// cut short: they run until they finish or time out. The start hooks
+	// left get AsyncWait, as on stop.
+	up.awaitAsyncHooks(process.PhasePreStop, process.PhasePostStop)
+	up.settleAsyncHooks(up.proc.GetSpec().Lifecycle.AsyncWait)
+
 	// Clean up resources
 	up.mu.Lock()
 	if up.proc != nil {
@@ -532,8 +982,14 @@ func (up *ManagedProcess) setState(newState processState) {
 	up.state = newState
 	newStateStr := newState.String() // capture string representation while under lock
 	name := up.proc.GetName()        // capture name while under lock
+	ctx := up.opCtx
 	up.mu.Unlock()
 
+	if ctx != nil {
+		up.emitter.Tracer().SpanFromContext(ctx).AddEvent("state_transition",
+			observability.String("from", oldStateStr), observability.String("to", newStateStr))
+	}
+
 	// Record state transition metrics (outside lock to avoid holding lock too long)
 	up.emitter.Emit(observability.Event{Kind: observability.ProcessStateChanged, Name: name, From: oldStateStr, To: newStateStr})
 }
@@ -559,7 +1015,7 @@ func (up *ManagedProcess) checkProcessHealth() {
 	}
 }
 
-func (up *ManagedProcess) persistStart() {
+func (up *ManagedProcess) persistStart(evtType history.EventType) {
 	up.mu.Lock()
 	now := time.Now().UTC()
 	st := up.proc.Snapshot()
@@ -572,7 +1028,7 @@ func (up *ManagedProcess) persistStart() {
 		if b, err := json.Marshal(spec); err == nil {
 			rec.SpecJSON = string(b)
 		}
-		evt := history.Event{Type: history.EventStart, OccurredAt: now, Record: rec}
+		evt := history.Event{Type: evtType, OccurredAt: now, Record: rec}
 		for _, h := range sinks {
 			_ = h.Send(context.Background(), evt)
 		}
@@ -591,7 +1047,11 @@ func (up *ManagedProcess) persistStop() {
 	if st.ExitErr != nil && !stopRequested {
 		lastStatus = StateFailed.String()
 	}
-	rec := history.Record{Name: spec.Name, PID: st.PID, LastStatus: lastStatus, UpdatedAt: now}
+	code, _ := process.ExitDetails(st.ExitErr)
+	rec := history.Record{Name: spec.Name, PID: st.PID, LastStatus: lastStatus, UpdatedAt: now, ExitCode: &code}
+	if lastStatus == StateFailed.String() {
+		rec.Error = st.ExitErr.Error()
+	}
 	if b, err := json.Marshal(spec); err == nil {
 		rec.SpecJSON = string(b)
 	}
@@ -604,6 +1064,29 @@ func (up *ManagedProcess) persistStop() {
 	}
 }
 
+// persistFatal records that an automatic restart attempt failed.
+func (up *ManagedProcess) persistFatal(cause error) {
+	up.mu.RLock()
+	now := time.Now().UTC()
+	sinks := append([]history.Sink(nil), up.history...)
+	st := up.proc.Snapshot()
+	spec := up.proc.GetSpec()
+	up.mu.RUnlock()
+
+	up.log().Error("automatic restart failed", "error", cause)
+	if len(sinks) == 0 {
+		return
+	}
+	rec := history.Record{Name: spec.Name, PID: st.PID, LastStatus: StateFailed.String(), UpdatedAt: now, Error: cause.Error()}
+	if b, err := json.Marshal(spec); err == nil {
+		rec.SpecJSON = string(b)
+	}
+	evt := history.Event{Type: history.EventFatal, OccurredAt: now, Record: rec}
+	for _, h := range sinks {
+		_ = h.Send(context.Background(), evt)
+	}
+}
+
 // executeLifecycleHooks executes hooks for a specific lifecycle phase
 func (up *ManagedProcess) executeLifecycleHooks(spec process.Spec, phase process.LifecyclePhase) error {
 	hooks := spec.Lifecycle.GetHooksForPhase(phase)
@@ -611,27 +1094,23 @@ func (up *ManagedProcess) executeLifecycleHooks(spec process.Spec, phase process
 		return nil
 	}
 
-	slog.Info("Executing lifecycle hooks", "process", spec.Name, "phase", phase.String(), "hook_count", len(hooks))
+	up.log().Info("Executing lifecycle hooks", "phase", phase.String(), "hook_count", len(hooks))
 
 	for i, hook := range hooks {
 		// Apply defaults to hook
 		hook.GetDefaults()
 
-		slog.Debug("Executing hook", "process", spec.Name, "phase", phase.String(), "hook", hook.Name, "index", i)
+		up.log().Debug("Executing hook", "phase", phase.String(), "hook", hook.Name, "index", i)
 
-		if err := up.executeHook(spec, hook, phase); err != nil {
+		if err := up.runHook(spec, hook, phase); err != nil {
 			switch hook.FailureMode {
 			case process.FailureModeIgnore:
-				slog.Warn("Hook failed but continuing due to failure_mode=ignore",
-					"process", spec.Name, "phase", phase.String(), "hook", hook.Name, "error", err)
+				up.log().Warn("Hook failed but continuing due to failure_mode=ignore",
+					"phase", phase.String(), "hook", hook.Name, "error", err)
 				continue
 			case process.FailureModeRetry:
-				// Simple retry logic - retry once after 1 second
-				slog.Warn("Hook failed, retrying once",
-					"process", spec.Name, "phase", phase.String(), "hook", hook.Name, "error", err)
-				time.Sleep(1 * time.Second)
-				if retryErr := up.executeHook(spec, hook, phase); retryErr != nil {
-					return fmt.Errorf("hook %q failed after retry: %w", hook.Name, retryErr)
+				if retryErr := up.retryHook(spec, hook, phase, err); retryErr != nil {
+					return fmt.Errorf("hook %q failed after %d retries: %w", hook.Name, hook.Retries(), retryErr)
 				}
 			case process.FailureModeFail:
 				fallthrough
@@ -639,21 +1118,143 @@ func (up *ManagedProcess) executeLifecycleHooks(spec process.Spec, phase process
 				return fmt.Errorf("hook %q failed: %w", hook.Name, err)
 			}
 		} else {
-			slog.Debug("Hook completed successfully", "process", spec.Name, "phase", phase.String(), "hook", hook.Name)
+			up.log().Debug("Hook completed successfully", "phase", phase.String(), "hook", hook.Name)
 		}
 	}
 
-	slog.Info("All lifecycle hooks completed", "process", spec.Name, "phase", phase.String())
+	up.log().Info("All lifecycle hooks completed", "phase", phase.String())
+	return nil
+}
+
+// retryHook re-runs a failed hook up to hook.Retries() times, waiting
+// hook.RetryDelay between attempts. Each attempt gets its own Timeout.
+func (up *ManagedProcess) retryHook(spec process.Spec, hook process.Hook, phase process.LifecyclePhase, err error) error {
+	for attempt := 1; attempt <= hook.Retries(); attempt++ {
+		delay := hook.RetryDelay(attempt)
+		up.log().Warn("Hook failed, retrying",
+			"phase", phase.String(), "hook", hook.Name, "attempt", attempt, "max_retries", hook.Retries(),
+			"delay", delay, "error", err)
+		time.Sleep(delay)
+		if err = up.runHook(spec, hook, phase); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// runHook executes hook and records the outcome in the hook summaries.
+func (up *ManagedProcess) runHook(spec process.Spec, hook process.Hook, phase process.LifecyclePhase) error {
+	_, span := up.emitter.Tracer().Start(up.opContext(), "lifecycle_hook",
+		observability.String("process.name", spec.Name),
+		observability.String("hook.phase", phase.String()),
+		observability.String("hook.name", hook.Name),
+		observability.String("hook.run_mode", string(hook.RunMode)),
+	)
+	defer span.End()
+
+	start := time.Now()
+	err := up.executeHook(spec, hook, phase)
+	d := time.Since(start)
+	up.recordHookRun(phase, hook.Name, start, d, err)
+	up.emitter.Emit(observability.Event{Kind: observability.HookCompleted, Name: spec.Name, Phase: phase.String(), Duration: d.Seconds()})
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// setOpContext records the trace context of the operation the state
+// machine is currently handling; nil clears it.
+func (up *ManagedProcess) setOpContext(ctx context.Context) {
+	up.mu.Lock()
+	up.opCtx = ctx
+	up.mu.Unlock()
+}
+
+// opContext returns the current operation's trace context, or Background.
+func (up *ManagedProcess) opContext() context.Context {
+	up.mu.RLock()
+	defer up.mu.RUnlock()
+	if up.opCtx == nil {
+		return context.Background()
+	}
+	return up.opCtx
+}
+
+func (up *ManagedProcess) recordHookRun(phase process.LifecyclePhase, name string, at time.Time, d time.Duration, err error) {
+	up.mu.Lock()
+	defer up.mu.Unlock()
+	idx := -1
+	for i := range up.hookRuns {
+		if up.hookRuns[i].Phase == phase.String() && up.hookRuns[i].Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		up.hookRuns = append(up.hookRuns, HookSummary{Phase: phase.String(), Name: name})
+		idx = len(up.hookRuns) - 1
+	}
+	sum := &up.hookRuns[idx]
+	sum.Runs++
+	sum.LastRunAt = at
+	sum.LastDuration = d
+	sum.LastError = ""
+	if err != nil {
+		sum.Failures++
+		sum.LastError = err.Error()
+	}
+}
+
+// HookSummaries returns a copy of the lifecycle hook execution summaries.
+func (up *ManagedProcess) HookSummaries() []HookSummary {
+	up.mu.RLock()
+	defer up.mu.RUnlock()
+	return append([]HookSummary(nil), up.hookRuns...)
+}
+
+// hookExitWait bounds how long a post_stop hook waits for the reaper
+// goroutine to record the exit status once the process is gone.
+const hookExitWait = 500 * time.Millisecond
+
+// hookPhaseEnv returns the phase-specific variables injected into a hook:
+// PROVISR_PID for post_start, and PROVISR_EXIT_CODE/PROVISR_EXIT_SIGNAL for
+// post_stop. The exit variables are omitted when the exit status is unknown,
+// e.g. for a process adopted from a PID file.
+func (up *ManagedProcess) hookPhaseEnv(phase process.LifecyclePhase) []string {
+	switch phase {
+	case process.PhasePostStart:
+		if st := up.proc.Snapshot(); st.PID > 0 {
+			return []string{fmt.Sprintf("PROVISR_PID=%d", st.PID)}
+		}
+	case process.PhasePostStop:
+		deadline := time.Now().Add(hookExitWait)
+		for {
+			st := up.proc.Snapshot()
+			if !st.StartedAt.IsZero() && !st.StoppedAt.Before(st.StartedAt) {
+				code, signal := process.ExitDetails(st.ExitErr)
+				return []string{
+					fmt.Sprintf("PROVISR_EXIT_CODE=%d", code),
+					fmt.Sprintf("PROVISR_EXIT_SIGNAL=%s", signal),
+				}
+			}
+			if up.proc.CopyCmd() == nil || time.Now().After(deadline) {
+				return nil
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
 	return nil
 }
 
 // executeHook executes a single lifecycle hook
 func (up *ManagedProcess) executeHook(spec process.Spec, hook process.Hook, phase process.LifecyclePhase) error {
-	ctx := context.Background()
+	var ctx context.Context
+	var cancel context.CancelFunc
 	if hook.Timeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, hook.Timeout)
-		defer cancel()
+		ctx, cancel = context.WithTimeout(context.Background(), hook.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
 	}
 
 	// Build command
@@ -676,24 +1277,72 @@ func (up *ManagedProcess) executeHook(spec process.Spec, hook process.Hook, phas
 		fmt.Sprintf("PROVISR_HOOK_NAME=%s", hook.Name),
 		fmt.Sprintf("PROVISR_HOOK_PHASE=%s", phase.String()),
 	)
+	env = append(env, up.hookPhaseEnv(phase)...)
 	cmd.Env = env
 
+	// Forward hook output to the process's structured log and live-tail
+	// buffer, tagged with phase and hook name.
+	logger := up.log()
+	output := newHookOutput(func(stream, line string) {
+		logger.Info("hook output", "phase", phase.String(), "hook", hook.Name, "stream", stream, "line", line)
+		up.proc.AppendLog("hook", fmt.Sprintf("[%s/%s] %s", phase, hook.Name, line))
+	})
+	stdout, stderr := output.writer("stdout"), output.writer("stderr")
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	// A background child of the hook can keep the output pipes open after
+	// the hook itself is killed on timeout; don't let that block Wait.
+	cmd.WaitDelay = hookOutputWaitDelay
+	flush := func() {
+		stdout.flush()
+		stderr.flush()
+	}
+
 	start := time.Now()
 
 	// Execute based on run mode
 	if hook.RunMode == process.RunModeAsync {
-		// Async execution - start and don't wait
-		slog.Debug("Starting hook in async mode", "process", spec.Name, "hook", hook.Name)
-		return cmd.Start()
+		// Async execution - start, track and reap in the background. The
+		// context outlives this call so Timeout still applies to the hook.
+		up.log().Debug("Starting hook in async mode", "hook", hook.Name)
+		if err := cmd.Start(); err != nil {
+			cancel()
+			return err
+		}
+		ah := up.trackAsyncHook(AsyncHookInfo{
+			Phase:     phase.String(),
+			Name:      hook.Name,
+			PID:       cmd.Process.Pid,
+			StartedAt: start,
+		}, cancel)
+		go func() {
+			err := cmd.Wait()
+			flush()
+			cancel()
+			up.untrackAsyncHook(ah)
+			if err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+				up.log().Warn("Async hook failed", "phase", phase.String(), "hook", hook.Name,
+					"duration", time.Since(start), "error", err, "output", output.Tail())
+			}
+		}()
+		return nil
 	} else {
 		// Blocking execution - wait for completion
-		if err := cmd.Run(); err != nil {
+		defer cancel()
+		err := cmd.Run()
+		flush()
+		if errors.Is(err, exec.ErrWaitDelay) {
+			err = nil // hook succeeded; a child it left running still holds the pipes
+		}
+		if err != nil {
 			duration := time.Since(start)
+			if tail := output.Tail(); tail != "" {
+				return fmt.Errorf("hook command failed after %v: %w; last output:\n%s", duration, err, tail)
+			}
 			return fmt.Errorf("hook command failed after %v: %w", duration, err)
 		}
 
 		duration := time.Since(start)
-		slog.Debug("Hook completed", "process", spec.Name, "hook", hook.Name, "duration", duration)
+		up.log().Debug("Hook completed", "hook", hook.Name, "duration", duration)
 		return nil
 	}
 }
@@ -1,12 +1,15 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
 
+	"github.com/loykin/provisr/core/history"
 	"github.com/loykin/provisr/core/internal/process"
 )
 
@@ -808,3 +811,211 @@ func waitUntilManagerState(t *testing.T, mgr *Manager, name, want string, timeou
 	}
 	return false
 }
+
+// waitForState polls mp until it reports want or the deadline passes.
+func waitForState(mp *ManagedProcess, want string, within time.Duration) bool {
+	deadline := time.Now().Add(within)
+	for time.Now().Before(deadline) {
+		if mp.Status().State == want {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return mp.Status().State == want
+}
+
+func TestHealthCheckIntervalDetectsCrashOnSchedule(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	spec := process.Spec{
+		Name:                "slow-check",
+		Command:             "sh -c 'sleep 0.2; exit 1'",
+		HealthCheckInterval: 2 * time.Second,
+	}
+	mp := NewManagedProcess(spec, mockEnvMerger)
+	defer func() { _ = mp.Shutdown() }()
+	created := time.Now()
+
+	if err := mp.Start(spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	// The child exits after 200ms, but with a 2s interval the state machine
+	// must not notice before its first tick (the 1s default would have).
+	time.Sleep(time.Until(created.Add(1300 * time.Millisecond)))
+	if st := mp.Status().State; st != "running" {
+		t.Fatalf("crash detected before the first 2s check: state=%s", st)
+	}
+	if !waitForState(mp, "stopped", time.Until(created.Add(3*time.Second))) {
+		t.Fatalf("crash not detected by the 2s check, state=%s", mp.Status().State)
+	}
+}
+
+func TestHealthCheckIntervalReconfiguredOnUpdateSpec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	spec := process.Spec{
+		Name:                "retuned-check",
+		Command:             "sh -c 'sleep 0.2; exit 1'",
+		HealthCheckInterval: time.Hour,
+	}
+	mp := NewManagedProcess(spec, mockEnvMerger)
+	defer func() { _ = mp.Shutdown() }()
+
+	if err := mp.Start(spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	spec.HealthCheckInterval = 100 * time.Millisecond
+	if err := mp.UpdateSpec(spec); err != nil {
+		t.Fatalf("update spec: %v", err)
+	}
+	if !waitForState(mp, "stopped", 2*time.Second) {
+		t.Fatalf("updated interval not applied, state=%s", mp.Status().State)
+	}
+}
+
+func TestManagerDefaultHealthCheckInterval(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	spec := process.Spec{Name: "default-check", Command: "sh -c 'sleep 0.2; exit 1'"}
+	mp := NewManagedProcess(spec, mockEnvMerger)
+	defer func() { _ = mp.Shutdown() }()
+	mp.SetDefaultHealthCheckInterval(100 * time.Millisecond)
+
+	if err := mp.Start(spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if !waitForState(mp, "stopped", 700*time.Millisecond) {
+		t.Fatalf("manager default interval not applied, state=%s", mp.Status().State)
+	}
+}
+
+func countEvents(sink *MockHistorySink, want history.EventType) int {
+	n := 0
+	for _, t := range sink.Types() {
+		if t == want {
+			n++
+		}
+	}
+	return n
+}
+
+func TestAutoRestartRecordsRestartAndFatalEvents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	blocker := filepath.Join(t.TempDir(), "block")
+	spec := process.Spec{
+		Name:                "restart-events",
+		Command:             "sleep 30",
+		AutoRestart:         true,
+		RestartInterval:     50 * time.Millisecond,
+		HealthCheckInterval: 20 * time.Millisecond,
+		Lifecycle: process.LifecycleHooks{
+			PreStart: []process.Hook{{Name: "gate", Command: fmt.Sprintf("test ! -e %q", blocker)}},
+		},
+	}
+	sink := NewMockHistorySink()
+	mp := NewManagedProcess(spec, mockEnvMerger)
+	mp.SetHistory(sink)
+	defer func() { _ = mp.Shutdown() }()
+	if err := mp.Start(spec); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	_ = killProcessByPID(mp.Status().PID)
+	deadline := time.Now().Add(3 * time.Second)
+	for countEvents(sink, history.EventRestart) == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if countEvents(sink, history.EventRestart) != 1 {
+		t.Fatalf("expected a restart event, got %v", sink.Types())
+	}
+
+	// Block further restarts: the failure streak is reported once.
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_ = killProcessByPID(mp.Status().PID)
+	deadline = time.Now().Add(3 * time.Second)
+	for countEvents(sink, history.EventFatal) == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if n := countEvents(sink, history.EventFatal); n != 1 {
+		t.Fatalf("expected exactly one fatal event, got %d: %v", n, sink.Types())
+	}
+}
+
+func TestQueuedStartAbortedByStopShutdownOrCaller(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	limiter := newStartLimiter()
+	limiter.setLimit(1)
+	newProcess := func(name string, startDuration time.Duration) (*ManagedProcess, process.Spec) {
+		spec := process.Spec{Name: name, Command: "sleep 30", StartDuration: startDuration}
+		mp := NewManagedProcess(spec, mockEnvMerger)
+		mp.setStartLimiter(limiter)
+		t.Cleanup(func() { _ = mp.Shutdown() })
+		return mp, spec
+	}
+
+	// holder keeps the only slot while in StateStarting.
+	holder, holderSpec := newProcess("slot-holder", 1500*time.Millisecond)
+	go func() { _ = holder.Start(holderSpec) }()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		holder.mu.RLock()
+		state := holder.state
+		holder.mu.RUnlock()
+		if state == StateStarting {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("holder never entered StateStarting")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, abort := range []string{"stop", "shutdown", "cancel"} {
+		queued, spec := newProcess("queued-"+abort, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan error, 1)
+		go func() { started <- queued.StartContext(ctx, spec) }()
+		time.Sleep(100 * time.Millisecond) // let it queue for the slot
+
+		begin := time.Now()
+		switch abort {
+		case "stop":
+			if err := queued.Stop(time.Second); err != nil {
+				t.Fatalf("stop: %v", err)
+			}
+		case "shutdown":
+			if err := queued.Shutdown(); err != nil {
+				t.Fatalf("shutdown: %v", err)
+			}
+		case "cancel":
+			// The caller gave up, e.g. an API client disconnected.
+			cancel()
+		}
+		if elapsed := time.Since(begin); elapsed > time.Second {
+			t.Fatalf("%s waited %v for the queued start", abort, elapsed)
+		}
+		select {
+		case err := <-started:
+			if err == nil {
+				t.Fatalf("%s: queued start succeeded", abort)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: queued start did not return", abort)
+		}
+		cancel()
+		if queued.Status().Running {
+			t.Fatalf("%s: queued process is running", abort)
+		}
+	}
+}
@@ -3,13 +3,20 @@ package manager
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"runtime"
 	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/loykin/provisr/core/history"
+	"github.com/loykin/provisr/core/instancename"
 	"github.com/loykin/provisr/core/internal/env"
+	"github.com/loykin/provisr/core/internal/logger"
 	"github.com/loykin/provisr/core/internal/process"
 	"github.com/loykin/provisr/core/observability"
 	"github.com/loykin/provisr/core/stats"
@@ -32,15 +39,30 @@ type Manager struct {
 
 	// Shared resources
 	envManager       *env.Env
+	logConfig        *logger.Config
 	histSinks        []history.Sink
+	events           *eventHub
 	metricsCollector stats.Collector
 	metricsCtx       context.Context
 	metricsCancel    context.CancelFunc
 	emitter          *observability.Emitter
+	healthCheckEvery time.Duration        // default health-check interval for specs that don't set one
+	restartJitter    time.Duration        // upper bound of the random delay before an automatic restart
+	naming           *instancename.Scheme // names of numbered instances; nil is the default scheme
+	validateCommands bool                 // resolve the command of registered specs before starting them
+	starts           *startLimiter        // caps processes in StateStarting at once
+	metadata         history.ProcessMetadataStore
+	envProvider      EnvProvider      // replaces DefaultEnv when set
+	startInterceptor StartInterceptor // vets each start; nil for none
+	daemonID         string           // marks started processes; see SetDaemonID
 }
 
 // NewManager creates a new manager
 func NewManager() *Manager {
+	// As PID 1 (in a container) every orphan is reparented to us.
+	if os.Getpid() == 1 {
+		process.StartOrphanReaper()
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Manager{
 		processes:     make(map[string]*ManagedProcess),
@@ -49,6 +71,9 @@ func NewManager() *Manager {
 		metricsCtx:    ctx,
 		metricsCancel: cancel,
 		emitter:       observability.NewEmitter(),
+		events:        newEventHub(),
+		starts:        newStartLimiter(),
+		metadata:      newMemoryMetadataStore(),
 	}
 }
 
@@ -58,6 +83,10 @@ func (m *Manager) SetObservers(observers ...observability.Observer) {
 
 func (m *Manager) Observe(event observability.Event) { m.emitter.Emit(event) }
 
+// SetTracer installs the tracer used for manager operations, lifecycle hooks
+// and state transitions. nil restores the no-op tracer.
+func (m *Manager) SetTracer(t observability.Tracer) { m.emitter.SetTracer(t) }
+
 // NewManagerWithStore has been removed. Use NewManager() and provide specs via Start/StartN as needed.
 
 // SetGlobalEnv configures global environment variables
@@ -78,6 +107,162 @@ func (m *Manager) SetGlobalEnv(kvs []string) {
 
 // SetStore removed: persistence via store is no longer supported.
 
+// SetLogConfig configures the manager-wide structured logging used for
+// per-process loggers. Each process's Spec.Log slog level/format override
+// is applied on top of it (see processLogger). Loggers of already-registered
+// processes are rebuilt so a reloaded level applies immediately.
+func (m *Manager) SetLogConfig(cfg logger.Config) {
+	m.mu.Lock()
+	m.logConfig = &cfg
+	processes := make([]*ManagedProcess, 0, len(m.processes))
+	for _, up := range m.processes {
+		processes = append(processes, up)
+	}
+	m.mu.Unlock()
+
+	for _, up := range processes {
+		up.SetLoggerFactory(m.processLogger)
+	}
+}
+
+// SetDefaultHealthCheckInterval sets how often processes whose spec leaves
+// HealthCheckInterval unset are checked for liveness. d <= 0 restores the
+// 1s default. Already-registered processes pick up the change immediately.
+func (m *Manager) SetDefaultHealthCheckInterval(d time.Duration) {
+	m.mu.Lock()
+	m.healthCheckEvery = d
+	processes := make([]*ManagedProcess, 0, len(m.processes))
+	for _, up := range m.processes {
+		processes = append(processes, up)
+	}
+	m.mu.Unlock()
+
+	for _, up := range processes {
+		up.SetDefaultHealthCheckInterval(d)
+	}
+}
+
+// SetRestartJitter spreads automatic restarts out: each one waits a random
+// delay in [0, d) after the health check that finds the process dead, so
+// processes that crashed together don't restart in lockstep. It adds to each
+// spec's RestartInterval; d <= 0 disables it. The health check interval
+// (SetDefaultHealthCheckInterval) sets how soon a crash is noticed.
+// Already-registered processes pick up the change immediately.
+func (m *Manager) SetRestartJitter(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	m.mu.Lock()
+	m.restartJitter = d
+	processes := make([]*ManagedProcess, 0, len(m.processes))
+	for _, up := range m.processes {
+		processes = append(processes, up)
+	}
+	m.mu.Unlock()
+
+	for _, up := range processes {
+		up.SetRestartJitter(d)
+	}
+}
+
+// SetMaxConcurrentStarts caps how many processes may be starting (from
+// entering StateStarting until running or failed) at the same time; further
+// starts, whether from Register, RegisterN, group starts, ApplyConfig or
+// automatic restarts, queue until a slot frees up; stopping or shutting
+// down a process, or the end of the ctx passed to StartContext, abandons
+// its queued start. n <= 0 means unlimited, the default. It applies to
+// already-registered processes too.
+func (m *Manager) SetMaxConcurrentStarts(n int) {
+	m.starts.setLimit(n)
+}
+
+// SetInstanceNaming sets the scheme numbered instances are named with and
+// hands it to the process metrics collector, if it takes one, so their series
+// are grouped by base name. nil restores the default base-1, base-2, ...
+// names. It is meant to be called before processes are registered: running
+// instances keep the names they were registered under.
+func (m *Manager) SetInstanceNaming(scheme *instancename.Scheme) {
+	m.mu.Lock()
+	m.naming = scheme
+	collector := m.metricsCollector
+	m.mu.Unlock()
+
+	if namer, ok := collector.(stats.InstanceNamer); ok {
+		namer.SetInstanceNaming(scheme)
+	}
+}
+
+// InstanceNaming returns the scheme numbered instances are named with.
+func (m *Manager) InstanceNaming() *instancename.Scheme {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.naming
+}
+
+// SetValidateCommands makes Register and RegisterN check that the command
+// of a spec resolves to a program on PATH (or under its work_dir) and fail
+// with "command not found" before anything is started. It is off by default:
+// a shell script is only checked when it starts with a plain word that is
+// not a known builtin.
+func (m *Manager) SetValidateCommands(enabled bool) {
+	m.mu.Lock()
+	m.validateCommands = enabled
+	m.mu.Unlock()
+}
+
+// SetDaemonID identifies this daemon in the marker of every process it
+// starts, so that after a crash only a daemon with the same ID adopts them
+// (see ApplyConfig). Daemons managing different configs on one host should
+// use different IDs; serve uses a hash of its config path. The default is
+// "".
+func (m *Manager) SetDaemonID(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.daemonID = id
+	for _, up := range m.processes {
+		up.setDaemonID(id)
+	}
+}
+
+// checkCommand resolves the command of spec when command validation is on.
+func (m *Manager) checkCommand(spec process.Spec) error {
+	m.mu.RLock()
+	enabled := m.validateCommands
+	m.mu.RUnlock()
+	if !enabled {
+		return nil
+	}
+	return spec.ResolveCommand()
+}
+
+// SetChildSubreaper makes this process a child subreaper (Linux only), so
+// processes that managed processes leave behind, such as the second fork of
+// a daemonizing program, are reparented to it instead of init and reaped when
+// they exit.
+func (m *Manager) SetChildSubreaper() error {
+	return process.SetChildSubreaper()
+}
+
+// processLogger builds the structured logger for spec from the merged
+// manager-wide and per-process log configuration. Without either, it
+// defers to slog.Default() so embedders keep their own handler.
+func (m *Manager) processLogger(spec process.Spec) *slog.Logger {
+	m.mu.RLock()
+	base := m.logConfig
+	m.mu.RUnlock()
+
+	overridden := spec.Log.Slog.Level != "" || spec.Log.Slog.Format != "" || spec.Log.Slog.Output != nil || spec.Log.Syslog.Enabled
+	if base == nil && !overridden {
+		return slog.Default().With(slog.String("process", spec.Name))
+	}
+	cfg := logger.DefaultConfig()
+	if base != nil {
+		cfg = *base
+	}
+	merged := cfg.WithOverrides(spec.Log)
+	return merged.NewProcessLogger(spec.Name)
+}
+
 // SetHistorySinks configures history sinks
 func (m *Manager) SetHistorySinks(sinks ...history.Sink) {
 	m.mu.Lock()
@@ -89,8 +274,13 @@ func (m *Manager) SetHistorySinks(sinks ...history.Sink) {
 func (m *Manager) SetProcessMetricsCollector(collector stats.Collector) error {
 	m.mu.Lock()
 	m.metricsCollector = collector
+	naming := m.naming
 	m.mu.Unlock()
 
+	if namer, ok := collector.(stats.InstanceNamer); ok {
+		namer.SetInstanceNaming(naming)
+	}
+
 	if collector != nil && collector.IsEnabled() {
 		return collector.Start(m.metricsCtx, m.getProcessPIDs)
 	}
@@ -117,46 +307,107 @@ func (m *Manager) getProcessPIDs() map[string]int32 {
 
 // Register registers and starts a new process
 func (m *Manager) Register(spec process.Spec) error {
+	if err := m.checkCommand(spec); err != nil {
+		return err
+	}
 	up := m.ensureProcess(spec.Name)
-	return up.Start(spec)
+	if err := up.Start(spec); err != nil {
+		return err
+	}
+	m.recordLabels(spec.Name, nil, spec.Labels)
+	return nil
 }
 
 // RegisterN registers and starts N instances of a process
 func (m *Manager) RegisterN(spec process.Spec) error {
+	if err := m.checkCommand(spec); err != nil {
+		return err
+	}
+	return m.registerSpecs(m.instanceSpecs(spec))
+}
+
+// Add registers spec (all of its instances) without starting it. The
+// processes show up in status as stopped until Start is called for them.
+func (m *Manager) Add(spec process.Spec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	if err := m.checkCommand(spec); err != nil {
+		return err
+	}
+	specs := m.instanceSpecs(spec)
+	if _, err := m.addSpecs(specs); err != nil {
+		return err
+	}
+	for _, instanceSpec := range specs {
+		m.recordLabels(instanceSpec.Name, nil, instanceSpec.Labels)
+	}
+	return nil
+}
+
+// instanceSpecs expands spec into one spec per instance.
+func (m *Manager) instanceSpecs(spec process.Spec) []process.Spec {
 	instances := spec.Instances
 	if instances < 1 {
 		instances = 1
 	}
 
 	specs := make([]process.Spec, 0, instances)
-	for i := 1; i <= instances; i++ {
+	for _, name := range m.processInstanceNames(spec.Name, instances) {
 		instanceSpec := spec
+		instanceSpec.Name = name
 		instanceSpec.Instances = instances
-		if instances > 1 {
-			instanceSpec.Name = fmt.Sprintf("%s-%d", spec.Name, i)
-		}
 		specs = append(specs, instanceSpec)
 	}
+	return specs
+}
 
+// addSpecs registers every spec, stopped, as one set: if any name is taken,
+// none of them is registered.
+func (m *Manager) addSpecs(specs []process.Spec) ([]*ManagedProcess, error) {
 	// Reserve the complete name set under one lock. This prevents concurrent
 	// registrations from partially taking ownership of the same process set.
 	m.mu.Lock()
+	defer m.mu.Unlock()
 	for _, instanceSpec := range specs {
 		if _, exists := m.processes[instanceSpec.Name]; exists {
-			m.mu.Unlock()
-			return fmt.Errorf("process %q is already registered", instanceSpec.Name)
+			return nil, fmt.Errorf("process %q is already registered", instanceSpec.Name)
 		}
 	}
 	created := make([]*ManagedProcess, 0, len(specs))
 	for _, instanceSpec := range specs {
-		up := NewManagedProcess(instanceSpec, m.mergeEnv, m.emitter)
-		if len(m.histSinks) > 0 {
-			up.SetHistory(m.histSinks...)
-		}
+		up := m.newManagedProcess(instanceSpec)
 		m.processes[instanceSpec.Name] = up
 		created = append(created, up)
 	}
-	m.mu.Unlock()
+	return created, nil
+}
+
+// newManagedProcess creates a stopped ManagedProcess wired to the manager's
+// logger, health check, restart and history settings. m.mu must be held.
+func (m *Manager) newManagedProcess(spec process.Spec) *ManagedProcess {
+	up := NewManagedProcess(spec, m.DefaultEnv, m.emitter)
+	up.setEnvProvider(m.processEnv)
+	up.setStartInterceptor(m.interceptStart)
+	up.SetLoggerFactory(m.processLogger)
+	if m.healthCheckEvery > 0 {
+		up.SetDefaultHealthCheckInterval(m.healthCheckEvery)
+	}
+	up.SetRestartJitter(m.restartJitter)
+	up.setStartLimiter(m.starts)
+	up.setDaemonID(m.daemonID)
+	// Inject shared history sinks so that events work immediately
+	up.SetHistory(m.historySinks()...)
+	return up
+}
+
+// registerSpecs registers and starts every spec as one set: if a name is
+// taken or a start fails, none of them is left registered.
+func (m *Manager) registerSpecs(specs []process.Spec) error {
+	created, err := m.addSpecs(specs)
+	if err != nil {
+		return err
+	}
 
 	for i, up := range created {
 		if err := up.Start(specs[i]); err != nil {
@@ -173,11 +424,27 @@ func (m *Manager) RegisterN(spec process.Spec) error {
 			return err
 		}
 	}
+	for _, instanceSpec := range specs {
+		m.recordLabels(instanceSpec.Name, nil, instanceSpec.Labels)
+	}
 	return nil
 }
 
 // Start starts an already registered process without creating a new one
 func (m *Manager) Start(name string) error {
+	return m.StartContext(context.Background(), name)
+}
+
+// StartContext is Start traced as a child of the span carried by ctx.
+func (m *Manager) StartContext(ctx context.Context, name string) (err error) {
+	ctx, span := m.emitter.Tracer().Start(ctx, "manager.start", observability.String("process.name", name))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
 	m.mu.RLock()
 	up := m.processes[name]
 	m.mu.RUnlock()
@@ -200,7 +467,7 @@ func (m *Manager) Start(name string) error {
 		return fmt.Errorf("process %q has no spec defined", name)
 	}
 
-	return up.Start(*spec)
+	return up.StartContext(ctx, *spec)
 }
 
 // GetSpec returns the currently-registered spec for name, e.g. so a caller
@@ -269,36 +536,31 @@ func (m *Manager) Update(spec process.Spec, wait time.Duration) error {
 		return fmt.Errorf("process %s not found", spec.Name)
 	}
 
+	previous, _ := m.GetSpec(spec.Name)
 	if err := up.Stop(wait); err != nil {
 		return fmt.Errorf("update %q: stop failed: %w", spec.Name, err)
 	}
 
-	return up.Start(spec)
+	if err := up.Start(spec); err != nil {
+		return err
+	}
+	m.recordLabels(spec.Name, previous.Labels, spec.Labels)
+	return nil
 }
 
-func processBaseName(currentName string, instances int) string {
+func (m *Manager) processBaseName(currentName string, instances int) string {
 	if instances <= 1 {
 		return currentName
 	}
-	for i := 1; i <= instances; i++ {
-		suffix := fmt.Sprintf("-%d", i)
-		if strings.HasSuffix(currentName, suffix) {
-			return strings.TrimSuffix(currentName, suffix)
-		}
+	naming := m.InstanceNaming()
+	if base, _, ok := naming.Parse(currentName); ok && naming.Ordinal(currentName, base, instances) >= 0 {
+		return base
 	}
 	return currentName
 }
 
-func processInstanceNames(base string, instances int) []string {
-	if instances <= 1 {
-		return []string{base}
-	}
-
-	names := make([]string, 0, instances)
-	for i := 1; i <= instances; i++ {
-		names = append(names, fmt.Sprintf("%s-%d", base, i))
-	}
-	return names
+func (m *Manager) processInstanceNames(base string, instances int) []string {
+	return m.InstanceNaming().Names(base, instances)
 }
 
 // unregisterExact removes only the explicitly named processes. Process-set
@@ -336,7 +598,7 @@ func (m *Manager) ProcessBase(name string) (string, error) {
 	if instances < 1 {
 		instances = 1
 	}
-	return processBaseName(name, instances), nil
+	return m.processBaseName(name, instances), nil
 }
 
 // UpdateInstances replaces the process set containing currentName. A single
@@ -358,7 +620,7 @@ func (m *Manager) UpdateInstances(currentName string, spec process.Spec, wait ti
 		desiredInstances = 1
 	}
 
-	base := processBaseName(currentName, currentInstances)
+	base := m.processBaseName(currentName, currentInstances)
 
 	if currentInstances == 1 && desiredInstances == 1 {
 		spec.Name = currentName
@@ -379,13 +641,13 @@ func (m *Manager) UpdateInstances(currentName string, spec process.Spec, wait ti
 	oldSpec.Instances = currentInstances
 	spec.Name = base
 	spec.Instances = desiredInstances
-	oldNames := processInstanceNames(base, currentInstances)
+	oldNames := m.processInstanceNames(base, currentInstances)
 	oldNameSet := make(map[string]struct{}, len(oldNames))
 	for _, name := range oldNames {
 		oldNameSet[name] = struct{}{}
 	}
 	m.mu.RLock()
-	for _, name := range processInstanceNames(base, desiredInstances) {
+	for _, name := range m.processInstanceNames(base, desiredInstances) {
 		if _, belongsToCurrentSet := oldNameSet[name]; belongsToCurrentSet {
 			continue
 		}
@@ -422,8 +684,8 @@ func (m *Manager) UnregisterInstances(currentName string, wait time.Duration) (s
 	if instances < 1 {
 		instances = 1
 	}
-	base := processBaseName(currentName, instances)
-	if err := m.unregisterExact(processInstanceNames(base, instances), wait); err != nil {
+	base := m.processBaseName(currentName, instances)
+	if err := m.unregisterExact(m.processInstanceNames(base, instances), wait); err != nil {
 		return "", err
 	}
 	return base, nil
@@ -431,6 +693,19 @@ func (m *Manager) UnregisterInstances(currentName string, wait time.Duration) (s
 
 // Stop stops a process without unregistering it
 func (m *Manager) Stop(name string, wait time.Duration) error {
+	return m.StopContext(context.Background(), name, wait)
+}
+
+// StopContext is Stop traced as a child of the span carried by ctx.
+func (m *Manager) StopContext(ctx context.Context, name string, wait time.Duration) (err error) {
+	ctx, span := m.emitter.Tracer().Start(ctx, "manager.stop", observability.String("process.name", name))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
 	m.mu.RLock()
 	up := m.processes[name]
 	m.mu.RUnlock()
@@ -439,7 +714,60 @@ func (m *Manager) Stop(name string, wait time.Duration) error {
 		return fmt.Errorf("process %s not found", name)
 	}
 
-	return up.Stop(wait)
+	return up.StopContext(ctx, wait)
+}
+
+// StopByPID stops the managed process currently running as pid, e.g. one
+// spotted in ps or a monitoring alert. It fails if no managed process has
+// that PID.
+func (m *Manager) StopByPID(pid int, wait time.Duration) error {
+	if pid <= 0 {
+		return fmt.Errorf("invalid pid %d", pid)
+	}
+	m.mu.RLock()
+	var name string
+	for n, up := range m.processes {
+		if st := up.Status(); st.Running && st.PID == pid {
+			name = n
+			break
+		}
+	}
+	m.mu.RUnlock()
+
+	if name == "" {
+		return fmt.Errorf("pid %d is not a managed process", pid)
+	}
+	return m.Stop(name, wait)
+}
+
+// RestartContext stops name, waiting up to wait for it to exit, and starts
+// it again under its current spec. A process that isn't running is just
+// started. It returns once the process is running again.
+func (m *Manager) RestartContext(ctx context.Context, name string, wait time.Duration) error {
+	if err := m.StopContext(ctx, name, wait); err != nil {
+		return fmt.Errorf("failed to stop %s: %w", name, err)
+	}
+	return m.StartContext(ctx, name)
+}
+
+// Signal sends sig to the running process name without stopping it, e.g.
+// SIGHUP to make it reload its configuration. Only syscall.Signal values are
+// supported; an error wrapping process.ErrNotRunning is returned when name
+// is not running.
+func (m *Manager) Signal(name string, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return fmt.Errorf("unsupported signal %v", sig)
+	}
+
+	m.mu.RLock()
+	up := m.processes[name]
+	m.mu.RUnlock()
+
+	if up == nil {
+		return fmt.Errorf("process %s not found", name)
+	}
+	return up.Signal(s)
 }
 
 // Unregister stops and removes a process from management
@@ -476,6 +804,30 @@ func (m *Manager) Status(name string) (process.Status, error) {
 	return up.Status(), nil
 }
 
+// HookSummaries returns the lifecycle hook execution summaries for name.
+func (m *Manager) HookSummaries(name string) ([]HookSummary, error) {
+	m.mu.RLock()
+	up := m.processes[name]
+	m.mu.RUnlock()
+
+	if up == nil {
+		return nil, fmt.Errorf("process %s not found", name)
+	}
+	return up.HookSummaries(), nil
+}
+
+// PendingAsyncHooks lists the async lifecycle hooks of name still running.
+func (m *Manager) PendingAsyncHooks(name string) ([]AsyncHookInfo, error) {
+	m.mu.RLock()
+	up := m.processes[name]
+	m.mu.RUnlock()
+
+	if up == nil {
+		return nil, fmt.Errorf("process %s not found", name)
+	}
+	return up.PendingAsyncHooks(), nil
+}
+
 // LogsSince returns captured stdout/stderr lines for name since the given
 // offset, plus the offset to pass as `since` on the next poll.
 func (m *Manager) LogsSince(name string, since uint64, limit int) ([]process.LogLine, uint64, error) {
@@ -518,11 +870,24 @@ func (m *Manager) StartAll(base string) error {
 
 // StopAll stops all processes matching a base name pattern
 func (m *Manager) StopAll(base string, wait time.Duration) error {
+	return m.stopMatching(func(name string) bool { return m.matchesPattern(name, base) }, wait)
+}
+
+// StopRegex stops all processes whose name matches the regular expression.
+func (m *Manager) StopRegex(pattern string, wait time.Duration) error {
+	re, err := CompileSelectorRegex(pattern)
+	if err != nil {
+		return err
+	}
+	return m.stopMatching(re.MatchString, wait)
+}
+
+func (m *Manager) stopMatching(match func(string) bool, wait time.Duration) error {
 	var processes []*ManagedProcess
 
 	m.mu.RLock()
 	for name, up := range m.processes {
-		if m.matchesPattern(name, base) {
+		if match(name) {
 			processes = append(processes, up)
 		}
 	}
@@ -573,28 +938,90 @@ func (m *Manager) UnregisterAll(base string, wait time.Duration) error {
 // sort the same query would return processes in a different order every
 // time — visible as rows shuffling position on every poll in the UI.
 func (m *Manager) StatusAll(base string) ([]process.Status, error) {
-	statuses := make([]process.Status, 0) // Initialize as empty slice instead of nil
+	return m.statusMatching(func(name string) bool { return m.matchesPattern(name, base) }), nil
+}
 
+// StatusRegex returns status for all processes whose name matches the
+// regular expression, sorted by name.
+func (m *Manager) StatusRegex(pattern string) ([]process.Status, error) {
+	re, err := CompileSelectorRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return m.statusMatching(re.MatchString), nil
+}
+
+// parallelStatusMin is the number of processes from which statusMatching
+// snapshots them on several goroutines.
+const parallelStatusMin = 256
+
+// statusMatching snapshots the matching processes, sorted by name. The
+// manager lock is only held to pick them; each snapshot takes its own
+// process locks and probes liveness, so large sets are split across up to
+// GOMAXPROCS goroutines.
+func (m *Manager) statusMatching(match func(string) bool) []process.Status {
+	type entry struct {
+		name string
+		up   *ManagedProcess
+	}
 	m.mu.RLock()
+	entries := make([]entry, 0, len(m.processes))
 	for name, up := range m.processes {
-		if m.matchesPattern(name, base) {
-			statuses = append(statuses, up.Status())
+		if match(name) {
+			entries = append(entries, entry{name, up})
 		}
 	}
 	m.mu.RUnlock()
 
-	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	// Sorting the small entries rather than the statuses avoids moving
+	// whole Status values around.
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
 
-	return statuses, nil
+	statuses := make([]process.Status, len(entries))
+	workers := runtime.GOMAXPROCS(0)
+	if len(entries) < parallelStatusMin || workers < 2 {
+		for i, e := range entries {
+			statuses[i] = e.up.Status()
+		}
+		return statuses
+	}
+	chunk := (len(entries) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(entries); start += chunk {
+		end := min(start+chunk, len(entries))
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				statuses[i] = entries[i].up.Status()
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	return statuses
 }
 
 // Count returns the number of running instances for a base name
 func (m *Manager) Count(base string) (int, error) {
+	return m.countMatching(func(name string) bool { return m.matchesPattern(name, base) }), nil
+}
+
+// CountRegex returns the number of running processes whose name matches the
+// regular expression.
+func (m *Manager) CountRegex(pattern string) (int, error) {
+	re, err := CompileSelectorRegex(pattern)
+	if err != nil {
+		return 0, err
+	}
+	return m.countMatching(re.MatchString), nil
+}
+
+func (m *Manager) countMatching(match func(string) bool) int {
 	count := 0
 
 	m.mu.RLock()
 	for name, up := range m.processes {
-		if m.matchesPattern(name, base) {
+		if match(name) {
 			status := up.Status()
 			if status.Running {
 				count++
@@ -603,7 +1030,7 @@ func (m *Manager) Count(base string) (int, error) {
 	}
 	m.mu.RUnlock()
 
-	return count, nil
+	return count
 }
 
 // Shutdown gracefully shuts down all processes
@@ -621,21 +1048,45 @@ func (m *Manager) Shutdown() error {
 		collector.Stop()
 	}
 
-	// Shut down all processes
+	// Shut down all processes, in the reverse of their start order
 	m.mu.RLock()
-	processes := make([]*ManagedProcess, 0, len(m.processes))
-	for _, up := range m.processes {
-		processes = append(processes, up)
+	names := make([]string, 0, len(m.processes))
+	for name := range m.processes {
+		names = append(names, name)
 	}
 	m.mu.RUnlock()
 
-	for _, up := range processes {
-		_ = up.Shutdown()
+	for _, name := range m.stopOrder(names) {
+		m.mu.RLock()
+		up := m.processes[name]
+		m.mu.RUnlock()
+		if up != nil {
+			_ = up.Shutdown()
+		}
 	}
 
 	return nil
 }
 
+// stopOrder sorts process names in the reverse of their start order:
+// descending Priority, so the processes that come up first go down last.
+// Ties are broken by name to keep the order stable.
+func (m *Manager) stopOrder(names []string) []string {
+	priority := make(map[string]int, len(names))
+	for _, name := range names {
+		if spec, err := m.GetSpec(name); err == nil {
+			priority[name] = spec.Priority
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if pi, pj := priority[names[i]], priority[names[j]]; pi != pj {
+			return pi > pj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
 // ensureProcess gets or creates a ManagedProcess for the given name
 func (m *Manager) ensureProcess(name string) *ManagedProcess {
 	m.mu.RLock()
@@ -651,16 +1102,7 @@ func (m *Manager) ensureProcess(name string) *ManagedProcess {
 	up = m.processes[name]
 	if up == nil {
 		// Create new ManagedProcess with injected dependencies
-		spec := process.Spec{Name: name}
-		up = NewManagedProcess(
-			spec,
-			m.mergeEnv,
-			m.emitter,
-		)
-		// Inject shared history sinks so that events work immediately
-		if len(m.histSinks) > 0 {
-			up.SetHistory(m.histSinks...)
-		}
+		up = m.newManagedProcess(process.Spec{Name: name})
 		m.processes[name] = up
 	}
 	m.mu.Unlock()
@@ -703,44 +1145,224 @@ func (m *Manager) matchesPattern(name, pattern string) bool {
 	if strings.HasPrefix(name, pattern+"-") {
 		return true
 	}
+	if base, _, ok := m.InstanceNaming().Parse(name); ok && base == pattern {
+		return true
+	}
 
 	return false
 }
 
-// mergeEnv merges global and process-specific environment variables
-func (m *Manager) mergeEnv(spec process.Spec) []string {
+// MaxSelectorRegexLen bounds the length of a regex process selector.
+const MaxSelectorRegexLen = 256
+
+// selectorRegexCompileTimeout bounds how long compiling a selector may take.
+// Go's RE2 engine matches in linear time, so the only expensive step an
+// untrusted pattern can force is compilation (e.g. nested counted repeats).
+const selectorRegexCompileTimeout = 100 * time.Millisecond
+
+// CompileSelectorRegex compiles a regex process selector, rejecting patterns
+// that are too long or too slow to compile. Matching is unanchored, as with
+// regexp.MatchString; use ^ and $ to match whole names.
+func CompileSelectorRegex(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("regex selector must not be empty")
+	}
+	if len(pattern) > MaxSelectorRegexLen {
+		return nil, fmt.Errorf("regex selector too long: %d bytes (max %d)", len(pattern), MaxSelectorRegexLen)
+	}
+	type result struct {
+		re  *regexp.Regexp
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		re, err := regexp.Compile(pattern)
+		done <- result{re, err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, fmt.Errorf("invalid regex selector: %w", r.err)
+		}
+		return r.re, nil
+	case <-time.After(selectorRegexCompileTimeout):
+		return nil, fmt.Errorf("regex selector took longer than %s to compile", selectorRegexCompileTimeout)
+	}
+}
+
+// EnvProvider computes the environment a process starts with, as KEY=VALUE
+// pairs. It runs on every start and restart; an error aborts that start.
+type EnvProvider func(process.Spec) ([]string, error)
+
+// SetEnvProvider installs provider in place of DefaultEnv for every start,
+// including processes already registered, e.g. to inject secrets from a
+// secret manager. A provider that extends the default calls DefaultEnv
+// itself. nil restores DefaultEnv.
+func (m *Manager) SetEnvProvider(provider EnvProvider) {
+	m.mu.Lock()
+	m.envProvider = provider
+	m.mu.Unlock()
+}
+
+// processEnv is the environment of a start: the provider's, or DefaultEnv.
+func (m *Manager) processEnv(spec process.Spec) ([]string, error) {
+	m.mu.RLock()
+	provider := m.envProvider
+	m.mu.RUnlock()
+	if provider == nil {
+		return m.DefaultEnv(spec), nil
+	}
+	return provider(spec)
+}
+
+// StartInterceptor is called with the spec of each start, including
+// automatic restarts. An error vetoes the start and is returned from it; the
+// returned spec is the one started, so the interceptor can adjust env or
+// args. The name cannot be changed. The returned spec is used for that start
+// only: the process keeps its registered spec, which is what GetSpec,
+// status and export report and what the next start passes in.
+type StartInterceptor func(process.Spec) (process.Spec, error)
+
+// SetStartInterceptor installs interceptor for every start, including
+// processes already registered; nil removes it. It runs first, before the
+// EnvProvider and the pre_start hooks, which both see the spec it returns.
+func (m *Manager) SetStartInterceptor(interceptor StartInterceptor) {
+	m.mu.Lock()
+	m.startInterceptor = interceptor
+	m.mu.Unlock()
+}
+
+// interceptStart passes spec through the StartInterceptor, if any.
+func (m *Manager) interceptStart(spec process.Spec) (process.Spec, error) {
+	m.mu.RLock()
+	interceptor := m.startInterceptor
+	m.mu.RUnlock()
+	if interceptor == nil {
+		return spec, nil
+	}
+	return interceptor(spec)
+}
+
+// DefaultEnv merges the daemon's environment, the globals set through
+// SetGlobalEnv and the spec's Env; with CleanEnv the daemon's environment
+// is left out (see env.Env.MergeClean).
+func (m *Manager) DefaultEnv(spec process.Spec) []string {
 	m.mu.RLock()
 	envManager := m.envManager
 	m.mu.RUnlock()
 
+	if spec.CleanEnv {
+		return envManager.MergeClean(spec.Env)
+	}
 	return envManager.Merge(spec.Env)
 }
 
 // ApplyConfig loads processes from PID files and reconciles running processes with the given specs.
 // Behavior:
 // 1) For each desired spec (expanding Instances), if a PID file is present and alive, recover it.
-// 2) Otherwise, start the process from the spec.
-// 3) Any managed process whose name is not present in the desired set will be gracefully shut down and cleaned up.
+// 2) Otherwise adopt a process an earlier daemon with the same ID (see SetDaemonID) left running under that name,
+// once that daemon is gone (see process.FindOrphans).
+// 3) Otherwise, start the process from the spec.
+// 4) Any managed process whose name is not present in the desired set will be gracefully shut down and cleaned up.
+// Desired processes are handled in ascending Priority and removed ones are
+// stopped in descending Priority, so a shared dependency with the lowest
+// Priority comes up first and goes down last.
+// Instances that fail to start or stop are not an error; Reconcile reports
+// them.
 func (m *Manager) ApplyConfig(specs []process.Spec) error {
-	// Build desired instances map: name -> instance spec
+	_, err := m.Reconcile(specs)
+	return err
+}
+
+// Reconcile is ApplyConfig reporting what it did. On an error the result
+// covers the instances handled before it.
+func (m *Manager) Reconcile(specs []process.Spec) (ReconcileResult, error) {
+	return m.ReconcileWithOptions(specs, ApplyOptions{})
+}
+
+// ReconcileWithOptions is Reconcile tuned by opts, e.g. to leave processes
+// that specs doesn't declare running.
+func (m *Manager) ReconcileWithOptions(specs []process.Spec, opts ApplyOptions) (ReconcileResult, error) {
+	plan, failed, err := m.applyConfig(specs, opts)
+	return newReconcileResult(plan.Processes, failed), err
+}
+
+// ApplyConfigWithOptions is ApplyConfig returning the plan it carried out,
+// one entry per process instance. With opts.DryRun it only computes the
+// plan: nothing is started, recovered or stopped.
+func (m *Manager) ApplyConfigWithOptions(specs []process.Spec, opts ApplyOptions) (ApplyPlan, error) {
+	plan, _, err := m.applyConfig(specs, opts)
+	return plan, err
+}
+
+// applyConfig carries out ApplyConfigWithOptions and also returns the
+// instances that failed to start or stop.
+func (m *Manager) applyConfig(specs []process.Spec, opts ApplyOptions) (ApplyPlan, []ReconcileFailure, error) {
+	plan := ApplyPlan{Processes: []PlanChange{}, Groups: []PlanChange{}, CronJobs: []PlanChange{}}
+	var failed []ReconcileFailure
+
+	// Build desired instances map: name -> instance spec, and the order to
+	// start them in: ascending Priority, then as given.
 	desired := make(map[string]process.Spec)
+	var startOrder []string
 	for _, s := range specs {
 		if s.Instances <= 1 {
 			ds := s
 			ds.Name = s.Name
+			if _, dup := desired[ds.Name]; !dup {
+				startOrder = append(startOrder, ds.Name)
+			}
 			desired[ds.Name] = ds
 			continue
 		}
-		for i := 1; i <= s.Instances; i++ {
+		for _, name := range m.processInstanceNames(s.Name, s.Instances) {
 			ds := s
-			ds.Name = fmt.Sprintf("%s-%d", s.Name, i)
+			ds.Name = name
+			if _, dup := desired[ds.Name]; !dup {
+				startOrder = append(startOrder, ds.Name)
+			}
 			desired[ds.Name] = ds
 		}
 	}
+	sort.SliceStable(startOrder, func(i, j int) bool {
+		return desired[startOrder[i]].Priority < desired[startOrder[j]].Priority
+	})
+
+	// Orphans are only looked for once, and only when some instance has no
+	// running process after PID-file recovery.
+	var orphans map[string]process.Orphan
+	findOrphans := func() map[string]process.Orphan {
+		if orphans == nil {
+			m.mu.RLock()
+			daemonID := m.daemonID
+			m.mu.RUnlock()
+			orphans = process.FindOrphans(daemonID)
+			if orphans == nil {
+				orphans = map[string]process.Orphan{}
+			}
+		}
+		return orphans
+	}
 
 	// First, ensure desired processes are running or recovered from PID files
-	for name, ds := range desired {
+	for _, name := range startOrder {
+		ds := desired[name]
+		if opts.DryRun {
+			change, err := m.planProcess(ds, findOrphans)
+			if err != nil {
+				return plan, failed, fmt.Errorf("apply config %q: reading PID file: %w", name, err)
+			}
+			plan.Processes = append(plan.Processes, change)
+			continue
+		}
+
 		up := m.ensureProcess(name)
+		change := PlanChange{Name: name, Action: PlanKeep}
+		current, err := m.GetSpec(name)
+		if err == nil {
+			change.Changes = DiffFields(current, ds)
+		}
+		m.recordLabels(name, current.Labels, ds.Labels)
 
 		// Try recover from PID file if configured
 		if ds.PIDFile != "" {
@@ -750,7 +1372,10 @@ func (m *Manager) ApplyConfig(specs []process.Spec) error {
 			// the existing PID file cannot be inspected.
 			pid, specFromFile, err := process.VerifyPIDFile(ds.PIDFile)
 			if err != nil {
-				return fmt.Errorf("apply config %q: reading PID file: %w", name, err)
+				return plan, failed, fmt.Errorf("apply config %q: reading PID file: %w", name, err)
+			}
+			if pid > 0 && !up.Status().Running {
+				change.Action = PlanRecover
 			}
 			if pid > 0 {
 				// Prefer spec from PID file if available (preserve historical details)
@@ -771,32 +1396,73 @@ func (m *Manager) ApplyConfig(specs []process.Spec) error {
 			}
 		}
 
+		if !up.Status().Running {
+			if orphan, ok := findOrphans()[name]; ok {
+				ds.Name = name
+				up.Adopt(ds, orphan)
+				if up.Status().Running {
+					change.Action = PlanRecover
+				}
+			}
+		}
+
 		// Check current status; if not running, register and start it
 		st := up.Status()
 		if !st.Running {
-			_ = up.Start(ds)
+			change.Action = PlanStart
+			if err := up.Start(ds); err != nil {
+				failed = append(failed, ReconcileFailure{Name: name, Action: PlanStart, Error: err.Error()})
+			}
+		} else if change.Action == PlanKeep && len(change.Changes) > 0 {
+			// The running child is left alone; its next restart uses ds.
+			if err := up.UpdateSpec(ds); err != nil {
+				failed = append(failed, ReconcileFailure{Name: name, Action: PlanUpdate, Error: err.Error()})
+			}
 		}
+		plan.Processes = append(plan.Processes, change)
 	}
 
 	// Then, stop and cleanup processes that are no longer desired
 	m.mu.RLock()
-	existing := make(map[string]*ManagedProcess, len(m.processes))
-	for n, up := range m.processes {
-		existing[n] = up
+	var undeclared []string
+	for name := range m.processes {
+		if _, ok := desired[name]; !ok {
+			undeclared = append(undeclared, name)
+		}
 	}
 	m.mu.RUnlock()
+	// Keep may call back into the manager, so it runs without the lock.
+	removed := undeclared[:0]
+	for _, name := range undeclared {
+		if opts.Keep == nil || !opts.Keep(name) {
+			removed = append(removed, name)
+		}
+	}
 
-	for name, up := range existing {
-		if _, ok := desired[name]; !ok {
-			_ = up.Shutdown()
-			// Remove from map
-			m.mu.Lock()
+	for _, name := range m.stopOrder(removed) {
+		plan.Processes = append(plan.Processes, PlanChange{Name: name, Action: PlanStop})
+		if opts.DryRun {
+			continue
+		}
+		m.mu.RLock()
+		up := m.processes[name]
+		m.mu.RUnlock()
+		if up == nil {
+			continue
+		}
+		if err := up.Shutdown(); err != nil {
+			failed = append(failed, ReconcileFailure{Name: name, Action: PlanStop, Error: err.Error()})
+		}
+		// Remove from map
+		m.mu.Lock()
+		if m.processes[name] == up {
 			delete(m.processes, name)
-			m.mu.Unlock()
 		}
+		m.mu.Unlock()
 	}
 
-	return nil
+	SortPlan(plan.Processes)
+	return plan, failed, nil
 }
 
 // InstanceGroup defines a group of processes to be managed together
@@ -886,11 +1552,7 @@ func (m *Manager) InstanceGroupStart(groupName string) error {
 			instances = 1
 		}
 		// Start all instances of this member
-		for i := 1; i <= instances; i++ {
-			instanceName := member.Name
-			if instances > 1 {
-				instanceName = fmt.Sprintf("%s-%d", member.Name, i)
-			}
+		for _, instanceName := range m.processInstanceNames(member.Name, instances) {
 			if err := m.Start(instanceName); err != nil {
 				if firstError == nil {
 					firstError = fmt.Errorf("failed to start %s: %w", instanceName, err)
@@ -1,17 +1,25 @@
 package manager
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/loykin/provisr/core/history"
+	"github.com/loykin/provisr/core/internal/logger"
 	"github.com/loykin/provisr/core/internal/process"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,6 +27,7 @@ import (
 
 // MockHistorySink implements history.Sink for testing
 type MockHistorySink struct {
+	mu     sync.Mutex
 	events []history.Event
 }
 
@@ -29,10 +38,22 @@ func NewMockHistorySink() *MockHistorySink {
 }
 
 func (mhs *MockHistorySink) Send(_ context.Context, event history.Event) error {
+	mhs.mu.Lock()
+	defer mhs.mu.Unlock()
 	mhs.events = append(mhs.events, event)
 	return nil
 }
 
+func (mhs *MockHistorySink) Types() []history.EventType {
+	mhs.mu.Lock()
+	defer mhs.mu.Unlock()
+	types := make([]history.EventType, 0, len(mhs.events))
+	for _, e := range mhs.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
 func (mhs *MockHistorySink) Close() error {
 	return nil
 }
@@ -80,6 +101,39 @@ func TestManagerSetGlobalEnv(t *testing.T) {
 	_ = mgr.Stop("test-env-process", 2*time.Second)
 }
 
+func TestRegisterValidatesCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh scripts")
+	}
+	mgr := NewManager()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	missing := process.Spec{Name: "missing-bin", Command: "provisr-no-such-binary --serve"}
+
+	// Off by default: the missing binary only shows up when it is started.
+	if err := mgr.Register(missing); err != nil && strings.Contains(err.Error(), "command not found") {
+		t.Fatalf("validation should be opt-in, got %v", err)
+	}
+	_ = mgr.Unregister("missing-bin", time.Second)
+
+	mgr.SetValidateCommands(true)
+	missing.Instances = 2
+	for _, register := range []func(process.Spec) error{mgr.Register, mgr.RegisterN} {
+		err := register(missing)
+		if err == nil || !strings.Contains(err.Error(), "command not found: provisr-no-such-binary") {
+			t.Fatalf("expected command not found, got %v", err)
+		}
+	}
+	if n, _ := mgr.Count("missing-bin"); n != 0 {
+		t.Fatalf("rejected spec left %d processes registered", n)
+	}
+
+	// A script starting with a shell builtin is not falsely rejected.
+	builtin := process.Spec{Name: "builtin", Command: "sh -c 'cd / && exec sleep 5'"}
+	if err := mgr.Register(builtin); err != nil {
+		t.Fatalf("builtin script rejected: %v", err)
+	}
+}
+
 func TestListInstanceGroupsReturnsSortedCopy(t *testing.T) {
 	mgr := NewManager()
 	mgr.SetInstanceGroups([]InstanceGroup{
@@ -112,6 +166,45 @@ func TestManagerSetHistorySinks(t *testing.T) {
 	mgr.SetHistorySinks()
 }
 
+func TestPerProcessLogLevelOverride(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	var buf bytes.Buffer
+	mgr.SetLogConfig(logger.Config{Slog: logger.SlogConfig{Level: logger.LevelInfo, Output: &buf}})
+
+	quiet := process.Spec{Name: "quiet-worker", Command: "sleep 5", Log: logger.Config{Slog: logger.SlogConfig{Level: logger.LevelWarn}}}
+	loud := process.Spec{Name: "loud-service", Command: "sleep 5", Log: logger.Config{Slog: logger.SlogConfig{Level: logger.LevelDebug}}}
+	plain := process.Spec{Name: "plain", Command: "sleep 5"}
+	for _, spec := range []process.Spec{quiet, loud, plain} {
+		require.NoError(t, mgr.Register(spec))
+	}
+
+	ctx := context.Background()
+	mgr.mu.RLock()
+	quietUp, loudUp, plainUp := mgr.processes["quiet-worker"], mgr.processes["loud-service"], mgr.processes["plain"]
+	mgr.mu.RUnlock()
+
+	assert.False(t, quietUp.log().Enabled(ctx, slog.LevelInfo), "warn override should suppress info")
+	assert.True(t, quietUp.log().Enabled(ctx, slog.LevelWarn))
+	assert.True(t, loudUp.log().Enabled(ctx, slog.LevelDebug), "debug override should enable debug")
+	assert.False(t, plainUp.log().Enabled(ctx, slog.LevelDebug), "no override inherits manager level")
+	assert.True(t, plainUp.log().Enabled(ctx, slog.LevelInfo))
+
+	// UpdateSpec rebuilds the logger from the new override.
+	quiet.Log.Slog.Level = logger.LevelDebug
+	require.NoError(t, quietUp.UpdateSpec(quiet))
+	assert.True(t, quietUp.log().Enabled(ctx, slog.LevelDebug), "UpdateSpec should apply the new level")
+
+	// Reloading the manager-wide config applies to processes without an override.
+	mgr.SetLogConfig(logger.Config{Slog: logger.SlogConfig{Level: logger.LevelError, Output: &buf}})
+	assert.False(t, plainUp.log().Enabled(ctx, slog.LevelWarn), "reloaded manager level should apply")
+	assert.True(t, loudUp.log().Enabled(ctx, slog.LevelDebug), "override survives manager reload")
+
+	plainUp.log().Error("boom")
+	assert.Contains(t, buf.String(), "process=plain")
+}
+
 func TestManagerStartStop(t *testing.T) {
 	mgr := NewManager()
 	defer func() { _ = mgr.Shutdown() }()
@@ -150,6 +243,457 @@ func TestManagerStartStop(t *testing.T) {
 	}
 }
 
+func TestManagerRestart(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	require.NoError(t, mgr.Register(process.Spec{Name: "restart-me", Command: "sleep 10"}))
+	before, err := mgr.Status("restart-me")
+	require.NoError(t, err)
+	require.True(t, before.Running)
+
+	require.NoError(t, mgr.RestartContext(context.Background(), "restart-me", 3*time.Second))
+	after, err := mgr.Status("restart-me")
+	require.NoError(t, err)
+	assert.True(t, after.Running, "restarted process should be running")
+	assert.NotEqual(t, before.PID, after.PID, "restart should spawn a new process")
+
+	// A stopped process is just started.
+	require.NoError(t, mgr.Stop("restart-me", 3*time.Second))
+	require.NoError(t, mgr.RestartContext(context.Background(), "restart-me", 3*time.Second))
+	started, err := mgr.Status("restart-me")
+	require.NoError(t, err)
+	assert.True(t, started.Running)
+
+	assert.Error(t, mgr.RestartContext(context.Background(), "missing", time.Second))
+}
+
+func TestManagerSubscribeEvents(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	events, cancel := mgr.SubscribeEvents(0)
+	require.NoError(t, mgr.Register(process.Spec{Name: "short-lived", Command: "sh -c 'sleep 0.3; exit 3'"}))
+
+	next := func() history.Event {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("no lifecycle event received")
+			return history.Event{}
+		}
+	}
+	started := next()
+	assert.Equal(t, history.EventStart, started.Type)
+	assert.Equal(t, "short-lived", started.Record.Name)
+
+	stopped := next()
+	assert.Equal(t, history.EventStop, stopped.Type)
+	assert.Equal(t, StateFailed.String(), stopped.Record.LastStatus)
+	require.NotNil(t, stopped.Record.ExitCode)
+	assert.Equal(t, 3, *stopped.Record.ExitCode)
+	assert.Equal(t, "exit status 3", stopped.Record.Error)
+
+	cancel()
+	_, open := <-events
+	assert.False(t, open, "cancel should close the channel")
+	cancel()
+}
+
+func TestStartTimeoutKillsWedgedProcess(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	// The child never "becomes ready" within the timeout: StartDuration
+	// outlasts StartTimeout while the process just sleeps. Spec.Validate
+	// rejects this combination; Register doesn't validate, so it stands in
+	// here for a spawn that wedges.
+	spec := process.Spec{
+		Name:          "wedged",
+		Command:       "sleep 60",
+		StartDuration: 30 * time.Second,
+		StartTimeout:  300 * time.Millisecond,
+	}
+
+	began := time.Now()
+	err := mgr.Register(spec)
+	elapsed := time.Since(began)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, process.ErrStartTimeout)
+	assert.Less(t, elapsed, 5*time.Second, "start should not block past the timeout")
+
+	st, err := mgr.Status("wedged")
+	require.NoError(t, err)
+	assert.False(t, st.Running)
+	if st.PID > 0 {
+		assert.Eventually(t, func() bool {
+			// Fails once the child is gone (and reaped).
+			return killProcessByPID(st.PID) != nil
+		}, 3*time.Second, 50*time.Millisecond, "spawned child should be killed")
+	}
+}
+
+func TestStartRefusesPortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	err = mgr.Register(process.Spec{Name: "port-busy", Command: "sleep 5", Ports: []int{port}})
+	var portErr *process.PortInUseError
+	require.ErrorAs(t, err, &portErr)
+	assert.Equal(t, []int{port}, portErr.Ports)
+	assert.Contains(t, err.Error(), fmt.Sprintf("port %d already in use", port))
+	st, err := mgr.Status("port-busy")
+	require.NoError(t, err)
+	assert.False(t, st.Running)
+
+	// With port_check = "warn" the process starts anyway.
+	require.NoError(t, mgr.Register(process.Spec{Name: "port-warn", Command: "sleep 5", Ports: []int{port}, PortCheck: process.PortCheckWarn}))
+	st, err = mgr.Status("port-warn")
+	require.NoError(t, err)
+	assert.True(t, st.Running)
+}
+
+func TestStartCreatesWorkDir(t *testing.T) {
+	workDir := filepath.Join(t.TempDir(), "missing", "work")
+
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	// Without create_work_dir the missing directory fails the start.
+	err := mgr.Register(process.Spec{Name: "wd-missing", Command: "sleep 5", WorkDir: workDir})
+	require.Error(t, err)
+
+	require.NoError(t, mgr.Register(process.Spec{Name: "wd-create", Command: "sleep 5", WorkDir: workDir, CreateWorkDir: true, WorkDirMode: "0750"}))
+	st, err := mgr.Status("wd-create")
+	require.NoError(t, err)
+	assert.True(t, st.Running)
+	info, err := os.Stat(workDir)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+	}
+}
+
+func TestSignalDeliversToRunningProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell trap")
+	}
+	marker := filepath.Join(t.TempDir(), "usr1")
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	require.NoError(t, mgr.Register(process.Spec{
+		Name:    "sig",
+		Command: fmt.Sprintf(`sh -c 'trap "echo usr1 >> %s" USR1; while :; do sleep 0.1; done'`, marker),
+		// Give the shell time to install the trap before it is signalled.
+		StartDuration: 300 * time.Millisecond,
+	}))
+	before, err := mgr.Status("sig")
+	require.NoError(t, err)
+
+	sig, err := process.ParseSignal("usr1")
+	require.NoError(t, err)
+	require.NoError(t, mgr.Signal("sig", sig))
+	assert.Eventually(t, func() bool {
+		data, _ := os.ReadFile(marker)
+		return strings.Contains(string(data), "usr1")
+	}, 3*time.Second, 50*time.Millisecond)
+
+	after, err := mgr.Status("sig")
+	require.NoError(t, err)
+	assert.True(t, after.Running)
+	assert.Equal(t, before.PID, after.PID)
+
+	require.NoError(t, mgr.Stop("sig", 2*time.Second))
+	assert.ErrorIs(t, mgr.Signal("sig", sig), process.ErrNotRunning)
+	assert.Error(t, mgr.Signal("missing", sig))
+}
+
+func TestApplyConfigAdoptsOrphan(t *testing.T) {
+	if _, err := exec.LookPath("setsid"); err != nil || runtime.GOOS == "windows" {
+		t.Skip("uses sh and setsid")
+	}
+	// An orphan as a crashed daemon leaves it: marked with the daemon ID
+	// and its name, leading its own process group and reparented away from
+	// us once the shell that started it exits.
+	name := fmt.Sprintf("orphan-%d", time.Now().UnixNano())
+	orphan := func(daemonID string) int {
+		out, err := exec.Command("sh", "-c", fmt.Sprintf("setsid env %s=%s/%s sleep 30 >/dev/null 2>&1 & echo $!", process.ManagedEnvVar, daemonID, name)).Output()
+		require.NoError(t, err)
+		pid, err := strconv.Atoi(strings.TrimSpace(string(out)))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = killProcessByPID(pid) })
+		// The marker only shows once setsid has exec'd env and env sleep.
+		marker := process.ManagedEnvVar + "=" + daemonID + "/" + name
+		require.Eventually(t, func() bool {
+			environ, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+			return err != nil || strings.Contains(string(environ), marker)
+		}, 2*time.Second, 10*time.Millisecond)
+		return pid
+	}
+	spec := process.Spec{Name: name, Command: "sleep 30"}
+
+	// Another daemon's process of the same name is left alone.
+	orphan("other")
+	mgr := NewManager()
+	mgr.SetDaemonID("self")
+	plan, err := mgr.ApplyConfigWithOptions([]process.Spec{spec}, ApplyOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, plan.Processes, 1)
+	assert.Equal(t, PlanStart, plan.Processes[0].Action)
+	_ = mgr.Shutdown()
+
+	pid := orphan("self")
+	mgr = NewManager()
+	mgr.SetDaemonID("self")
+	defer func() { _ = mgr.Shutdown() }()
+
+	plan, err = mgr.ApplyConfigWithOptions([]process.Spec{spec}, ApplyOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, plan.Processes, 1)
+	assert.Equal(t, PlanRecover, plan.Processes[0].Action)
+
+	plan, err = mgr.ApplyConfigWithOptions([]process.Spec{spec}, ApplyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, PlanRecover, plan.Processes[0].Action)
+	st, err := mgr.Status(name)
+	require.NoError(t, err)
+	assert.True(t, st.Running)
+	assert.Equal(t, pid, st.PID, "the orphan should be adopted instead of starting a duplicate")
+}
+
+func TestApplyConfigStartsByPriority(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	trace := filepath.Join(t.TempDir(), "order")
+	spec := func(name string, priority int) process.Spec {
+		record := func(event string) []process.Hook {
+			return []process.Hook{{Name: event, Command: fmt.Sprintf("echo %s-%s >> %s", event, name, trace)}}
+		}
+		return process.Spec{
+			Name:      name,
+			Command:   "sleep 30",
+			Priority:  priority,
+			Lifecycle: process.LifecycleHooks{PreStart: record("start"), PreStop: record("stop")},
+		}
+	}
+
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	// Listed out of order; the cache must come up before everything else.
+	specs := []process.Spec{spec("web", 10), spec("cache", -5), spec("api", 0), spec("worker", 10)}
+	_, err := mgr.ApplyConfigWithOptions(specs, ApplyOptions{})
+	require.NoError(t, err)
+
+	// Removing everything stops them in reverse: the cache goes down last.
+	_, err = mgr.ApplyConfigWithOptions(nil, ApplyOptions{})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(trace)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"start-cache", "start-api", "start-web", "start-worker",
+		"stop-web", "stop-worker", "stop-api", "stop-cache",
+	}, strings.Fields(string(data)))
+}
+
+func TestCleanEnvKeepsOnlyConfiguredVars(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv("PROVISR_DAEMON_ONLY", "leak")
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	mgr.SetGlobalEnv([]string{"PROVISR_GLOBAL=g"})
+
+	spec := process.Spec{
+		Name:     "clean-env",
+		Command:  "sh -c 'env; sleep 5'",
+		Env:      []string{"PROVISR_LOCAL=l"},
+		CleanEnv: true,
+		Umask:    "027",
+	}
+	require.NoError(t, mgr.Register(spec))
+
+	var out string
+	require.Eventually(t, func() bool {
+		lines, _, err := mgr.LogsSince(spec.Name, 0, 0)
+		if err != nil {
+			return false
+		}
+		var b strings.Builder
+		for _, l := range lines {
+			b.WriteString(l.Text + "\n")
+		}
+		out = b.String()
+		return strings.Contains(out, "PROVISR_LOCAL=l")
+	}, 3*time.Second, 20*time.Millisecond)
+
+	assert.Contains(t, out, "PROVISR_GLOBAL=g")
+	assert.Contains(t, out, "PATH=")
+	assert.NotContains(t, out, "PROVISR_DAEMON_ONLY")
+}
+
+func TestEnvProviderInjectsVariables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	mgr.SetGlobalEnv([]string{"PROVISR_GLOBAL=g"})
+	mgr.SetEnvProvider(func(spec process.Spec) ([]string, error) {
+		return append(mgr.DefaultEnv(spec), "PROVISR_SECRET=token-for-"+spec.Name), nil
+	})
+
+	spec := process.Spec{Name: "with-secret", Command: "sh -c 'env; sleep 5'"}
+	require.NoError(t, mgr.Register(spec))
+
+	var out string
+	require.Eventually(t, func() bool {
+		lines, _, err := mgr.LogsSince(spec.Name, 0, 0)
+		if err != nil {
+			return false
+		}
+		var b strings.Builder
+		for _, l := range lines {
+			b.WriteString(l.Text + "\n")
+		}
+		out = b.String()
+		return strings.Contains(out, "PROVISR_SECRET=")
+	}, 3*time.Second, 20*time.Millisecond)
+
+	assert.Contains(t, out, "PROVISR_SECRET=token-for-with-secret")
+	assert.Contains(t, out, "PROVISR_GLOBAL=g")
+}
+
+func TestEnvProviderErrorAbortsStart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep and touch")
+	}
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "pre-start-ran")
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	lookupErr := errors.New("secret store unreachable")
+	mgr.SetEnvProvider(func(process.Spec) ([]string, error) { return nil, lookupErr })
+
+	spec := process.Spec{
+		Name:    "no-secret",
+		Command: "sleep 5",
+		Lifecycle: process.LifecycleHooks{PreStart: []process.Hook{
+			{Name: "touch", Command: "touch " + marker, FailureMode: process.FailureModeFail, RunMode: process.RunModeBlocking},
+		}},
+	}
+	err := mgr.Register(spec)
+	require.ErrorIs(t, err, lookupErr)
+
+	st, statusErr := mgr.Status(spec.Name)
+	require.NoError(t, statusErr)
+	assert.False(t, st.Running)
+	_, statErr := os.Stat(marker)
+	assert.True(t, os.IsNotExist(statErr), "pre_start hook ran despite the provider error")
+
+	// Removing the provider restores the default environment.
+	mgr.SetEnvProvider(nil)
+	require.NoError(t, mgr.Start(spec.Name))
+}
+
+func TestStartInterceptorVetoesAndAdjusts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	var seen []string
+	mgr.SetStartInterceptor(func(spec process.Spec) (process.Spec, error) {
+		seen = append(seen, spec.Name)
+		if spec.Name == "blocked" {
+			return spec, errors.New("quota exceeded")
+		}
+		spec.Name = "renamed"
+		spec.Command = "sleep 6"
+		return spec, nil
+	})
+
+	err := mgr.Register(process.Spec{Name: "blocked", Command: "sleep 5"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "quota exceeded")
+	st, err := mgr.Status("blocked")
+	require.NoError(t, err)
+	assert.False(t, st.Running, "a vetoed start must not run the process")
+
+	require.NoError(t, mgr.Register(process.Spec{Name: "allowed", Command: "sleep 5"}))
+	st, err = mgr.Status("allowed")
+	require.NoError(t, err)
+	assert.True(t, st.Running)
+	spec, err := mgr.GetSpec("allowed")
+	require.NoError(t, err)
+	assert.Equal(t, "allowed", spec.Name, "the interceptor cannot rename a process")
+	assert.Equal(t, "sleep 5", spec.Command, "the intercepted spec is not kept")
+	assert.Equal(t, []string{"blocked", "allowed"}, seen)
+}
+
+func TestStartInterceptorSeesRegisteredSpecOnEachStart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	var seenEnv [][]string
+	mgr.SetStartInterceptor(func(spec process.Spec) (process.Spec, error) {
+		seenEnv = append(seenEnv, spec.Env)
+		spec.Env = append(spec.Env, "SECRET=hunter2")
+		return spec, nil
+	})
+	require.NoError(t, mgr.Register(process.Spec{Name: "app", Command: "sleep 5", Env: []string{"MODE=prod"}}))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, mgr.Stop("app", time.Second))
+		require.NoError(t, mgr.Start("app"))
+	}
+
+	want := []string{"MODE=prod"}
+	assert.Equal(t, [][]string{want, want, want}, seenEnv, "each start must intercept the registered spec")
+	spec, err := mgr.GetSpec("app")
+	require.NoError(t, err)
+	assert.Equal(t, want, spec.Env, "injected values must not leak into the spec")
+}
+
+func TestUmaskAppliedToChild(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("umask is Unix only")
+	}
+	dir := t.TempDir()
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	spec := process.Spec{
+		Name:    "umask",
+		Command: "touch " + filepath.Join(dir, "f") + "; sleep 5",
+		Umask:   "077",
+		WorkDir: dir,
+	}
+	require.NoError(t, mgr.Register(spec))
+
+	var info os.FileInfo
+	require.Eventually(t, func() bool {
+		var err error
+		info, err = os.Stat(filepath.Join(dir, "f"))
+		return err == nil
+	}, 3*time.Second, 20*time.Millisecond)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
 func TestManagerStartN(t *testing.T) {
 	mgr := NewManager()
 	defer func() { _ = mgr.Shutdown() }()
@@ -195,6 +739,80 @@ func TestManagerStartN(t *testing.T) {
 	}
 }
 
+func TestAddRegistersWithoutStarting(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	mgr := NewManager()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	if err := mgr.Add(process.Spec{Name: "idle", Command: "sleep 5", Instances: 2}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := mgr.Add(process.Spec{Name: "idle-1", Command: "sleep 5"}); err == nil {
+		t.Fatal("adding a registered name should fail")
+	}
+
+	sts, err := mgr.StatusAll("idle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sts) != 2 {
+		t.Fatalf("expected 2 stopped instances in status, got %+v", sts)
+	}
+	for _, st := range sts {
+		if st.Running || st.State != "stopped" || st.PID != 0 {
+			t.Fatalf("added process should be stopped: %+v", st)
+		}
+	}
+	if sts, _ := mgr.StatusRegex("^idle-"); len(sts) != 2 {
+		t.Fatalf("regex status missed stopped processes: %+v", sts)
+	}
+
+	if err := mgr.Start("idle-1"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if !waitUntilManagerState(t, mgr, "idle-1", "running", 2*time.Second) {
+		t.Fatal("idle-1 did not start")
+	}
+	if st, _ := mgr.Status("idle-2"); st.Running {
+		t.Fatal("idle-2 should still be stopped")
+	}
+}
+
+func TestStopByPID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	mgr := NewManager()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	if err := mgr.RegisterN(process.Spec{Name: "bypid", Command: "sleep 30", Instances: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if !waitUntilManagerState(t, mgr, "bypid-2", "running", 2*time.Second) {
+		t.Fatal("bypid-2 did not start")
+	}
+	st, err := mgr.Status("bypid-2")
+	if err != nil || st.PID == 0 {
+		t.Fatalf("no pid for bypid-2: %+v %v", st, err)
+	}
+
+	if err := mgr.StopByPID(st.PID, 2*time.Second); err != nil {
+		t.Fatalf("stop by pid: %v", err)
+	}
+	if st, _ := mgr.Status("bypid-2"); st.Running {
+		t.Fatal("bypid-2 is still running")
+	}
+	if st, _ := mgr.Status("bypid-1"); !st.Running {
+		t.Fatal("bypid-1 should not be stopped")
+	}
+	if err := mgr.StopByPID(st.PID, time.Second); err == nil || !strings.Contains(err.Error(), "not a managed process") {
+		t.Fatalf("stopped pid should no longer match, got %v", err)
+	}
+	if err := mgr.StopByPID(os.Getpid(), time.Second); err == nil {
+		t.Fatal("an unmanaged pid should be rejected")
+	}
+}
+
 func TestRegisterNFailureRollsBackOnlyReservedProcesses(t *testing.T) {
 	mgr := NewManager()
 	defer func() { _ = mgr.Shutdown() }()
@@ -364,6 +982,72 @@ func TestManagerPatternMatching(t *testing.T) {
 	}
 }
 
+func TestManagerRegexSelectionMatchesGlob(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	for _, name := range []string{"web-server-1", "web-server-2", "worker-1", "worker-2", "database"} {
+		require.NoError(t, mgr.Register(process.Spec{Name: name, Command: "sleep 5"}))
+	}
+
+	names := func(sts []process.Status) []string {
+		out := make([]string, 0, len(sts))
+		for _, st := range sts {
+			out = append(out, st.Name)
+		}
+		return out
+	}
+
+	cases := []struct {
+		glob, regex string
+	}{
+		{"web-server*", "^web-server"},
+		{"*-1", "-1$"},
+		{"*er*", "er"},
+		{"*", ".*"},
+		{"database", "^database$"},
+	}
+	for _, tc := range cases {
+		globSts, err := mgr.StatusAll(tc.glob)
+		require.NoError(t, err)
+		reSts, err := mgr.StatusRegex(tc.regex)
+		require.NoError(t, err)
+		assert.Equal(t, names(globSts), names(reSts), "glob %q vs regex %q", tc.glob, tc.regex)
+
+		globCount, _ := mgr.Count(tc.glob)
+		reCount, err := mgr.CountRegex(tc.regex)
+		require.NoError(t, err)
+		assert.Equal(t, globCount, reCount, "count glob %q vs regex %q", tc.glob, tc.regex)
+	}
+
+	// Regex can express selections glob cannot.
+	sts, err := mgr.StatusRegex(`^w(eb-server|orker)-2$`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web-server-2", "worker-2"}, names(sts))
+
+	require.NoError(t, mgr.StopRegex(`^worker-\d+$`, 2*time.Second))
+	count, err := mgr.CountRegex("^worker")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	count, err = mgr.CountRegex("^web")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestCompileSelectorRegexRejectsBadPatterns(t *testing.T) {
+	_, err := CompileSelectorRegex("")
+	assert.Error(t, err)
+	_, err = CompileSelectorRegex("(unclosed")
+	assert.Error(t, err)
+	_, err = CompileSelectorRegex(strings.Repeat("a", MaxSelectorRegexLen+1))
+	assert.Error(t, err)
+	_, err = CompileSelectorRegex("((a{1000}){1000}){1000}")
+	assert.Error(t, err)
+
+	_, err = NewManager().StatusRegex("(unclosed")
+	assert.Error(t, err)
+}
+
 func TestManagerShutdown(t *testing.T) {
 	mgr := NewManager()
 
@@ -943,6 +1627,117 @@ func TestManagerProcessCoordination(t *testing.T) {
 }
 
 // TestManagerRestartOnly tests that Manager exclusively handles restart logic
+func TestMaxConcurrentStarts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	mgr := NewManager()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	mgr.SetMaxConcurrentStarts(2)
+
+	transitions, cancel := mgr.SubscribeTransitions(256)
+	defer cancel()
+	const n = 6
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := range n {
+		wg.Go(func() {
+			// start_duration keeps each process in StateStarting for a while.
+			errs <- mgr.Register(process.Spec{
+				Name:          fmt.Sprintf("throttled-%d", i),
+				Command:       "sleep 30",
+				StartDuration: 150 * time.Millisecond,
+			})
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	starting, peak, started := 0, 0, 0
+	for started < n {
+		select {
+		case tr := <-transitions:
+			switch {
+			case tr.To == "starting":
+				starting++
+				peak = max(peak, starting)
+			case tr.From == "starting":
+				starting--
+				if tr.To == "running" {
+					started++
+				}
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d of %d processes started", started, n)
+		}
+	}
+	if peak > 2 {
+		t.Fatalf("expected at most 2 processes starting at once, got %d", peak)
+	}
+}
+
+func TestRestartJitterSpreadsRestarts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("kills processes by PID")
+	}
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	mgr.SetRestartJitter(2 * time.Second)
+
+	const n = 10
+	pids := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("herd-%d", i)
+		require.NoError(t, mgr.Register(process.Spec{
+			Name:                name,
+			Command:             "sleep 300",
+			AutoRestart:         true,
+			RestartInterval:     10 * time.Millisecond,
+			HealthCheckInterval: 50 * time.Millisecond,
+		}))
+		st, err := mgr.Status(name)
+		require.NoError(t, err)
+		require.True(t, st.Running)
+		pids = append(pids, st.PID)
+	}
+
+	// Crash them all at once.
+	crashed := time.Now()
+	for _, pid := range pids {
+		require.NoError(t, killProcessByPID(pid))
+	}
+
+	restartedAt := make([]time.Time, n)
+	require.Eventually(t, func() bool {
+		for i := range restartedAt {
+			st, err := mgr.Status(fmt.Sprintf("herd-%d", i))
+			if err != nil || st.Restarts < 1 || !st.Running {
+				return false
+			}
+			restartedAt[i] = st.StartedAt
+		}
+		return true
+	}, 6*time.Second, 20*time.Millisecond, "every process should be restarted")
+
+	first, last := restartedAt[0], restartedAt[0]
+	for _, at := range restartedAt {
+		require.True(t, at.After(crashed))
+		if at.Before(first) {
+			first = at
+		}
+		if at.After(last) {
+			last = at
+		}
+	}
+	// Without jitter all restarts land on the same 50ms health check.
+	assert.Greater(t, last.Sub(first), 500*time.Millisecond, "restarts should be spread out, got %v", restartedAt)
+}
+
 func TestManagerRestartOnly(t *testing.T) {
 	t.Log("=== Manager-Only Restart Test ===")
 
@@ -1346,6 +2141,142 @@ func TestApplyConfig_CleansRemoved(t *testing.T) {
 	}
 }
 
+func TestApplyConfigDryRunPlansWithoutChanges(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	if err := mgr.ApplyConfig([]process.Spec{
+		{Name: "kept", Command: "sleep 5"},
+		{Name: "dropped", Command: "sleep 5"},
+	}); err != nil {
+		t.Fatalf("ApplyConfig: %v", err)
+	}
+	mgr.SetInstanceGroups([]InstanceGroup{{Name: "old", Members: []process.Spec{{Name: "kept"}}}})
+
+	plan, err := mgr.ApplyConfigWithOptions([]process.Spec{
+		{Name: "kept", Command: "sleep 6"},
+		{Name: "fresh", Command: "sleep 5", Instances: 2},
+	}, ApplyOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	want := []PlanChange{
+		{Name: "dropped", Action: PlanStop},
+		{Name: "fresh-1", Action: PlanStart},
+		{Name: "fresh-2", Action: PlanStart},
+		{Name: "kept", Action: PlanKeep, Changes: []string{"command"}},
+	}
+	if !reflect.DeepEqual(plan.Processes, want) {
+		t.Fatalf("plan = %+v, want %+v", plan.Processes, want)
+	}
+
+	for _, name := range []string{"kept", "dropped"} {
+		if st, err := mgr.Status(name); err != nil || !st.Running {
+			t.Fatalf("%s should still be running after a dry run: st=%+v err=%v", name, st, err)
+		}
+	}
+	if _, err := mgr.Status("fresh-1"); err == nil {
+		t.Fatal("dry run registered fresh-1")
+	}
+	if spec, _ := mgr.GetSpec("kept"); spec.Command != "sleep 5" {
+		t.Fatalf("dry run changed the spec of kept: %q", spec.Command)
+	}
+
+	groups := mgr.PlanInstanceGroups([]InstanceGroup{
+		{Name: "new", Members: []process.Spec{{Name: "kept"}}},
+		{Name: "old", Members: []process.Spec{{Name: "kept"}, {Name: "fresh"}}},
+	})
+	wantGroups := []PlanChange{
+		{Name: "new", Action: PlanAdd},
+		{Name: "old", Action: PlanUpdate, Changes: []string{"members"}},
+	}
+	if !reflect.DeepEqual(groups, wantGroups) {
+		t.Fatalf("group plan = %+v, want %+v", groups, wantGroups)
+	}
+	if len(mgr.ListInstanceGroups()) != 1 {
+		t.Fatalf("PlanInstanceGroups changed the groups: %+v", mgr.ListInstanceGroups())
+	}
+}
+
+func TestReconcileKeepsUndeclaredProcesses(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	if _, err := mgr.Reconcile([]process.Spec{{Name: "declared", Command: "sleep 5"}, {Name: "extra", Command: "sleep 5"}}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	result, err := mgr.ReconcileWithOptions(nil, ApplyOptions{Keep: func(name string) bool { return name == "extra" }})
+	if err != nil {
+		t.Fatalf("ReconcileWithOptions: %v", err)
+	}
+	if want := []string{"declared"}; !reflect.DeepEqual(result.Stopped, want) {
+		t.Errorf("Stopped = %v, want %v", result.Stopped, want)
+	}
+	if st, err := mgr.Status("extra"); err != nil || !st.Running {
+		t.Errorf("kept process not running: %+v, %v", st, err)
+	}
+}
+
+func TestReconcileReportsDiff(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+
+	result, err := mgr.Reconcile([]process.Spec{
+		{Name: "kept", Command: "sleep 5"},
+		{Name: "same", Command: "sleep 5"},
+		{Name: "dropped", Command: "sleep 5"},
+	})
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if want := []string{"dropped", "kept", "same"}; !reflect.DeepEqual(result.Started, want) {
+		t.Fatalf("first Started = %v, want %v", result.Started, want)
+	}
+
+	result, err = mgr.Reconcile([]process.Spec{
+		{Name: "kept", Command: "sleep 6"},
+		{Name: "same", Command: "sleep 5"},
+		{Name: "fresh", Command: "sleep 5", Instances: 2},
+		{Name: "broken", Command: "/nonexistent/provisr-reconcile-test"},
+	})
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if want := []string{"fresh-1", "fresh-2"}; !reflect.DeepEqual(result.Started, want) {
+		t.Errorf("Started = %v, want %v", result.Started, want)
+	}
+	if want := []string{"dropped"}; !reflect.DeepEqual(result.Stopped, want) {
+		t.Errorf("Stopped = %v, want %v", result.Stopped, want)
+	}
+	if want := []string{"kept"}; !reflect.DeepEqual(result.Updated, want) {
+		t.Errorf("Updated = %v, want %v", result.Updated, want)
+	}
+	// The kept instance carries the new spec for its next restart.
+	if spec, err := mgr.GetSpec("kept"); err != nil || spec.Command != "sleep 6" {
+		t.Errorf("GetSpec(kept) = %q, %v; want the updated command", spec.Command, err)
+	}
+	if len(result.Recovered) != 0 {
+		t.Errorf("Recovered = %v, want none", result.Recovered)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Name != "broken" || result.Failed[0].Action != PlanStart || result.Failed[0].Error == "" {
+		t.Errorf("Failed = %+v, want broken failing to start", result.Failed)
+	}
+	if !result.Changed() {
+		t.Error("Changed() = false")
+	}
+
+	// The compatibility wrapper keeps returning nil for a failed start.
+	if err := mgr.ApplyConfig([]process.Spec{{Name: "broken", Command: "/nonexistent/provisr-reconcile-test"}}); err != nil {
+		t.Fatalf("ApplyConfig: %v", err)
+	}
+	if result, _ := mgr.Reconcile(nil); !reflect.DeepEqual(result.Stopped, []string{"broken"}) {
+		t.Fatalf("removing everything reported %q", result)
+	}
+	if result, _ := mgr.Reconcile(nil); result.Changed() || result.String() != "no changes" {
+		t.Fatalf("empty reconcile reported %q", result)
+	}
+}
+
 // Mock error type for testing
 type mockError struct {
 	msg string
@@ -1354,3 +2285,32 @@ type mockError struct {
 func (e *mockError) Error() string {
 	return e.msg
 }
+
+func TestStatusReportsUptimeAndResetsOnRestart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+	m := NewManager()
+	defer func() { _ = m.Shutdown() }()
+	require.NoError(t, m.Register(process.Spec{Name: "uptimer", Command: "sleep 10"}))
+
+	time.Sleep(1200 * time.Millisecond)
+	st, err := m.Status("uptimer")
+	require.NoError(t, err)
+	require.True(t, st.Running)
+	assert.False(t, st.StartedAt.IsZero())
+	assert.GreaterOrEqual(t, st.Uptime, time.Second)
+	assert.Less(t, st.Uptime, 5*time.Second)
+	firstStart := st.StartedAt
+
+	require.NoError(t, m.Stop("uptimer", 2*time.Second))
+	st, err = m.Status("uptimer")
+	require.NoError(t, err)
+	assert.Zero(t, st.Uptime, "stopped process must not report uptime")
+
+	require.NoError(t, m.Start("uptimer"))
+	st, err = m.Status("uptimer")
+	require.NoError(t, err)
+	assert.True(t, st.StartedAt.After(firstStart), "restart should reset the start time")
+	assert.Less(t, st.Uptime, time.Second)
+}
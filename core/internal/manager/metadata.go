@@ -0,0 +1,149 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/loykin/provisr/core/history"
+	"github.com/loykin/provisr/core/internal/process"
+)
+
+// metadataWriteTimeout bounds a single write to the metadata store.
+const metadataWriteTimeout = 5 * time.Second
+
+// memoryMetadataStore is the metadata store of a Manager that was not given
+// a persistent one: metadata is kept for as long as the daemon runs.
+type memoryMetadataStore struct {
+	mu       sync.Mutex
+	metadata map[string]map[string]string // process -> key -> value
+}
+
+func newMemoryMetadataStore() *memoryMetadataStore {
+	return &memoryMetadataStore{metadata: make(map[string]map[string]string)}
+}
+
+func (s *memoryMetadataStore) SetProcessMetadata(_ context.Context, name string, metadata map[string]string) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.metadata[name] == nil {
+		s.metadata[name] = make(map[string]string, len(metadata))
+	}
+	for key, value := range metadata {
+		s.metadata[name][key] = value
+	}
+	return nil
+}
+
+func (s *memoryMetadataStore) ProcessMetadata(_ context.Context, name string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metadata := make(map[string]string, len(s.metadata[name]))
+	for key, value := range s.metadata[name] {
+		metadata[key] = value
+	}
+	return metadata, nil
+}
+
+func (s *memoryMetadataStore) FindProcessesByMetadata(_ context.Context, selector map[string]string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0)
+	for name, metadata := range s.metadata {
+		if len(metadata) > 0 && process.MatchLabels(metadata, selector) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *memoryMetadataStore) DeleteProcessMetadata(_ context.Context, name string, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(keys) == 0 {
+		delete(s.metadata, name)
+		return nil
+	}
+	for _, key := range keys {
+		delete(s.metadata[name], key)
+	}
+	if len(s.metadata[name]) == 0 {
+		delete(s.metadata, name)
+	}
+	return nil
+}
+
+// SetMetadataStore makes the manager keep process metadata, including the
+// labels of registered specs, in store rather than in memory.
+func (m *Manager) SetMetadataStore(store history.ProcessMetadataStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metadata = store
+}
+
+func (m *Manager) metadataStore() history.ProcessMetadataStore {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.metadata
+}
+
+// SetProcessMetadata sets metadata keys of the named process. The process
+// does not have to be registered.
+func (m *Manager) SetProcessMetadata(ctx context.Context, name string, metadata map[string]string) error {
+	return m.metadataStore().SetProcessMetadata(ctx, name, metadata)
+}
+
+// ProcessMetadata returns the metadata recorded for the named process.
+func (m *Manager) ProcessMetadata(ctx context.Context, name string) (map[string]string, error) {
+	return m.metadataStore().ProcessMetadata(ctx, name)
+}
+
+// FindProcessesByMetadata returns the names of the processes, registered or
+// not, whose metadata matches selector.
+func (m *Manager) FindProcessesByMetadata(ctx context.Context, selector map[string]string) ([]string, error) {
+	return m.metadataStore().FindProcessesByMetadata(ctx, selector)
+}
+
+// recordLabels writes the labels of a registered spec to the metadata store
+// under its instance name, dropping the keys of previous labels it no
+// longer has. Failures are logged: metadata must not block registration.
+func (m *Manager) recordLabels(name string, previous, labels map[string]string) {
+	var stale []string
+	for key := range previous {
+		if _, ok := labels[key]; !ok {
+			stale = append(stale, key)
+		}
+	}
+	if len(stale) == 0 && len(labels) == 0 {
+		return
+	}
+	store := m.metadataStore()
+	ctx, cancel := context.WithTimeout(context.Background(), metadataWriteTimeout)
+	defer cancel()
+	if len(stale) > 0 {
+		if err := store.DeleteProcessMetadata(ctx, name, stale...); err != nil {
+			slog.Warn("Failed to delete process labels", "name", name, "error", err)
+		}
+	}
+	if err := store.SetProcessMetadata(ctx, name, labels); err != nil {
+		slog.Warn("Failed to record process labels", "name", name, "error", err)
+	}
+}
+
+// MatchesLabels reports whether the registered process name has every
+// label of selector.
+func (m *Manager) MatchesLabels(name string, selector map[string]string) bool {
+	if len(selector) == 0 {
+		return true
+	}
+	spec, err := m.GetSpec(name)
+	return err == nil && process.MatchLabels(spec.Labels, selector)
+}
+
+var _ history.ProcessMetadataStore = (*memoryMetadataStore)(nil)
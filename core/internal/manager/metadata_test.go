@@ -0,0 +1,52 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loykin/provisr/core/internal/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterRecordsLabels(t *testing.T) {
+	mgr := NewManager()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	ctx := context.Background()
+
+	require.NoError(t, mgr.Add(process.Spec{
+		Name: "billing", Command: "sleep 5", Instances: 2,
+		Labels: map[string]string{"team": "payments", "ticket": "OPS-1"},
+	}))
+	require.NoError(t, mgr.Add(process.Spec{Name: "search", Command: "sleep 5", Labels: map[string]string{"team": "search"}}))
+
+	names, err := mgr.FindProcessesByMetadata(ctx, map[string]string{"team": "payments"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing-1", "billing-2"}, names)
+
+	// Metadata set outside the spec sits next to the labels.
+	require.NoError(t, mgr.SetProcessMetadata(ctx, "search", map[string]string{"oncall": "bob"}))
+	metadata, err := mgr.ProcessMetadata(ctx, "search")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "search", "oncall": "bob"}, metadata)
+
+	assert.True(t, mgr.MatchesLabels("billing-1", map[string]string{"team": "payments", "ticket": "OPS-1"}))
+	assert.False(t, mgr.MatchesLabels("billing-1", map[string]string{"team": "search"}))
+	assert.False(t, mgr.MatchesLabels("missing", map[string]string{"team": "payments"}))
+}
+
+func TestUpdateDropsRemovedLabels(t *testing.T) {
+	mgr := NewManager()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	ctx := context.Background()
+
+	require.NoError(t, mgr.Register(process.Spec{Name: "api", Command: "sleep 5", Labels: map[string]string{"team": "payments", "ticket": "OPS-1"}}))
+	require.NoError(t, mgr.Update(process.Spec{Name: "api", Command: "sleep 5", Labels: map[string]string{"team": "checkout"}}, 0))
+
+	metadata, err := mgr.ProcessMetadata(ctx, "api")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "checkout"}, metadata)
+	names, err := mgr.FindProcessesByMetadata(ctx, map[string]string{"team": "payments"})
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
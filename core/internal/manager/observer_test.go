@@ -1,8 +1,12 @@
 package manager
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/loykin/provisr/core/internal/process"
 	"github.com/loykin/provisr/core/observability"
 )
 
@@ -17,3 +21,190 @@ func TestManagersHaveIndependentObservers(t *testing.T) {
 		t.Fatalf("observer counts = %d/%d, want 1/0", firstCount, secondCount)
 	}
 }
+
+// recordingTracer keeps every span it starts; parents are tracked through
+// the context like a real tracer would.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	mu     sync.Mutex
+	name   string
+	parent *recordedSpan
+	attrs  map[string]any
+	events []string
+	err    error
+	ended  bool
+}
+
+type spanKey struct{}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...observability.Attr) (context.Context, observability.Span) {
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	s := &recordedSpan{name: name, parent: parent, attrs: map[string]any{}}
+	s.SetAttributes(attrs...)
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (t *recordingTracer) SpanFromContext(ctx context.Context) observability.Span {
+	if s, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		return s
+	}
+	return &recordedSpan{attrs: map[string]any{}}
+}
+
+func (t *recordingTracer) find(name string) []*recordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []*recordedSpan
+	for _, s := range t.spans {
+		if s.name == name {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...observability.Attr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) AddEvent(name string, attrs ...observability.Attr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event := name
+	for _, a := range attrs {
+		event += " " + a.Key + "=" + a.Value.(string)
+	}
+	s.events = append(s.events, event)
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+func (s *recordedSpan) End() {
+	s.mu.Lock()
+	s.ended = true
+	s.mu.Unlock()
+}
+
+func TestManagerTracesStartStopAndHooks(t *testing.T) {
+	tracer := &recordingTracer{}
+	mgr := NewManager()
+	mgr.SetTracer(tracer)
+
+	spec := process.Spec{
+		Name:    "traced",
+		Command: "sleep 30",
+		Lifecycle: process.LifecycleHooks{
+			PreStart: []process.Hook{{Name: "prepare", Command: "true"}},
+		},
+	}
+	if err := mgr.Register(spec); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	defer func() { _ = mgr.Unregister("traced", time.Second) }()
+	if err := mgr.Stop("traced", time.Second); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	parent, _ := tracer.Start(context.Background(), "http")
+	if err := mgr.StartContext(parent, "traced"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := mgr.StopContext(parent, "traced", time.Second); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	starts := tracer.find("manager.start")
+	if len(starts) == 0 {
+		t.Fatal("no manager.start span")
+	}
+	start := starts[len(starts)-1]
+	if start.parent == nil || start.parent.name != "http" || start.attrs["process.name"] != "traced" || !start.ended {
+		t.Fatalf("unexpected manager.start span: %+v", start)
+	}
+	wantEvents := []string{"state_transition from=stopped to=starting", "state_transition from=starting to=running"}
+	if len(start.events) != 2 || start.events[0] != wantEvents[0] || start.events[1] != wantEvents[1] {
+		t.Fatalf("manager.start events = %v, want %v", start.events, wantEvents)
+	}
+
+	hooks := tracer.find("lifecycle_hook")
+	hook := hooks[len(hooks)-1]
+	if hook.parent != start || hook.attrs["hook.name"] != "prepare" || hook.attrs["hook.phase"] != "pre_start" || !hook.ended {
+		t.Fatalf("hook span not parented to manager.start: %+v", hook)
+	}
+
+	stops := tracer.find("manager.stop")
+	stop := stops[len(stops)-1]
+	if stop.parent == nil || stop.parent.name != "http" || len(stop.events) != 2 {
+		t.Fatalf("unexpected manager.stop span: %+v", stop)
+	}
+
+	if err := mgr.StartContext(parent, "missing"); err == nil {
+		t.Fatal("expected error for unknown process")
+	}
+	failed := tracer.find("manager.start")
+	if last := failed[len(failed)-1]; last.err == nil {
+		t.Fatal("error not recorded on span")
+	}
+}
+
+func TestManagerEmitsLifecycleDurations(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []observability.Event
+	)
+	mgr := NewManager()
+	mgr.SetObservers(observability.ObserverFunc(func(e observability.Event) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}))
+
+	spec := process.Spec{
+		Name:    "timed",
+		Command: "sleep 30",
+		Lifecycle: process.LifecycleHooks{
+			PreStart: []process.Hook{{Name: "prepare", Command: "sleep 0.1"}},
+		},
+	}
+	if err := mgr.Register(spec); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	defer func() { _ = mgr.Unregister("timed", time.Second) }()
+	if err := mgr.Stop("timed", time.Second); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	got := map[observability.Kind]observability.Event{}
+	for _, e := range events {
+		if e.Kind == observability.ProcessStateChanged {
+			continue
+		}
+		got[e.Kind] = e
+	}
+	if e := got[observability.ProcessStarted]; e.Duration < 0.1 {
+		t.Fatalf("start duration %v does not include the pre_start hook", e.Duration)
+	}
+	if e := got[observability.ProcessStopped]; e.Name != "timed" || e.Duration <= 0 {
+		t.Fatalf("unexpected stop event: %+v", e)
+	}
+	if e := got[observability.HookCompleted]; e.Name != "timed" || e.Phase != "pre_start" || e.Duration < 0.1 {
+		t.Fatalf("unexpected hook event: %+v", e)
+	}
+}
@@ -0,0 +1,248 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/loykin/provisr/core/internal/process"
+)
+
+// ApplyOptions tunes ApplyConfigWithOptions.
+type ApplyOptions struct {
+	// DryRun computes the plan without starting, recovering or stopping
+	// anything.
+	DryRun bool
+	// Keep reports registered processes that are missing from the specs
+	// but must be left running, such as ones launched for jobs or
+	// registered through the API. When nil, every such process is stopped.
+	Keep func(name string) bool
+}
+
+// Plan actions. Processes are started, recovered from their PID file or
+// adopted from an earlier daemon, stopped, or kept running; groups and cron jobs are added, updated,
+// removed, or kept.
+const (
+	PlanStart   = "start"
+	PlanRecover = "recover"
+	PlanStop    = "stop"
+	PlanKeep    = "keep"
+	PlanAdd     = "add"
+	PlanUpdate  = "update"
+	PlanRemove  = "remove"
+)
+
+// PlanChange is one entry of an ApplyPlan. Changes lists the JSON names of
+// the spec fields that differ from the current definition. A process that
+// is kept running is not restarted for them: applying the config stores the
+// new spec, and the process runs with it from its next restart.
+type PlanChange struct {
+	Name    string   `json:"name"`
+	Action  string   `json:"action"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// ApplyPlan is what applying a config does, or would do in a dry run.
+// ApplyConfigWithOptions fills Processes; callers that also apply groups
+// and cron jobs fill the other two.
+type ApplyPlan struct {
+	Processes []PlanChange `json:"processes"`
+	Groups    []PlanChange `json:"groups"`
+	CronJobs  []PlanChange `json:"cronjobs"`
+}
+
+// ReconcileFailure is a process instance that Reconcile could not start
+// (Action PlanStart), give its changed spec (Action PlanUpdate) or stop
+// cleanly (Action PlanStop). An instance that failed to stop is still
+// removed.
+type ReconcileFailure struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Error  string `json:"error"`
+}
+
+// ReconcileResult is what Reconcile did, by process instance name, each
+// list sorted. Updated lists instances kept running whose spec changed:
+// Reconcile stores the new spec without restarting them, so it applies from
+// their next restart. Instances kept unchanged are not listed.
+type ReconcileResult struct {
+	Started   []string           `json:"started"`
+	Recovered []string           `json:"recovered"`
+	Stopped   []string           `json:"stopped"`
+	Updated   []string           `json:"updated"`
+	Failed    []ReconcileFailure `json:"failed"`
+}
+
+// newReconcileResult sorts the carried-out changes into a ReconcileResult.
+func newReconcileResult(changes []PlanChange, failed []ReconcileFailure) ReconcileResult {
+	r := ReconcileResult{Started: []string{}, Recovered: []string{}, Stopped: []string{}, Updated: []string{}, Failed: []ReconcileFailure{}}
+	failedNames := make(map[string]bool, len(failed))
+	for _, f := range failed {
+		failedNames[f.Name] = true
+	}
+	for _, c := range changes {
+		if failedNames[c.Name] {
+			continue
+		}
+		switch c.Action {
+		case PlanStart:
+			r.Started = append(r.Started, c.Name)
+		case PlanRecover:
+			r.Recovered = append(r.Recovered, c.Name)
+		case PlanStop:
+			r.Stopped = append(r.Stopped, c.Name)
+		case PlanKeep:
+			if len(c.Changes) > 0 {
+				r.Updated = append(r.Updated, c.Name)
+			}
+		}
+	}
+	r.Failed = append(r.Failed, failed...)
+	sort.Strings(r.Started)
+	sort.Strings(r.Recovered)
+	sort.Strings(r.Stopped)
+	sort.Strings(r.Updated)
+	sort.Slice(r.Failed, func(i, j int) bool { return r.Failed[i].Name < r.Failed[j].Name })
+	return r
+}
+
+// Changed reports whether anything was started, recovered, stopped,
+// updated or failed.
+func (r ReconcileResult) Changed() bool {
+	return len(r.Started)+len(r.Recovered)+len(r.Stopped)+len(r.Updated)+len(r.Failed) > 0
+}
+
+// String summarizes r for a log line, e.g.
+// "started 2 (web-1, web-2), stopped 1 (old), failed 1 (bad: ...)".
+func (r ReconcileResult) String() string {
+	var parts []string
+	for _, list := range []struct {
+		verb  string
+		names []string
+	}{
+		{"started", r.Started}, {"recovered", r.Recovered}, {"stopped", r.Stopped}, {"updated", r.Updated},
+	} {
+		if len(list.names) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d (%s)", list.verb, len(list.names), strings.Join(list.names, ", ")))
+		}
+	}
+	if len(r.Failed) > 0 {
+		failures := make([]string, len(r.Failed))
+		for i, f := range r.Failed {
+			failures[i] = fmt.Sprintf("%s: %s", f.Name, f.Error)
+		}
+		parts = append(parts, fmt.Sprintf("failed %d (%s)", len(r.Failed), strings.Join(failures, "; ")))
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PlanInstanceGroups compares groups with the configured instance groups,
+// as SetInstanceGroups(groups) would replace them, without changing them.
+func (m *Manager) PlanInstanceGroups(groups []InstanceGroup) []PlanChange {
+	current := make(map[string]InstanceGroup)
+	for _, g := range m.ListInstanceGroups() {
+		current[g.Name] = g
+	}
+	changes := make([]PlanChange, 0, len(groups)+len(current))
+	for _, g := range groups {
+		old, ok := current[g.Name]
+		delete(current, g.Name)
+		switch {
+		case !ok:
+			changes = append(changes, PlanChange{Name: g.Name, Action: PlanAdd})
+		case !reflect.DeepEqual(memberNames(old), memberNames(g)):
+			changes = append(changes, PlanChange{Name: g.Name, Action: PlanUpdate, Changes: []string{"members"}})
+		default:
+			changes = append(changes, PlanChange{Name: g.Name, Action: PlanKeep})
+		}
+	}
+	for name := range current {
+		changes = append(changes, PlanChange{Name: name, Action: PlanRemove})
+	}
+	SortPlan(changes)
+	return changes
+}
+
+// SortPlan orders changes by name.
+func SortPlan(changes []PlanChange) {
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+}
+
+func memberNames(g InstanceGroup) []string {
+	names := make([]string, len(g.Members))
+	for i, m := range g.Members {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// DiffFields returns the sorted JSON names of the top-level fields that
+// differ between two values of the same struct type, ignoring "name".
+func DiffFields(current, desired any) []string {
+	a, errA := jsonFields(current)
+	b, errB := jsonFields(desired)
+	if errA != nil || errB != nil {
+		return nil
+	}
+	var changed []string
+	for key, av := range a {
+		if key != "name" && !reflect.DeepEqual(av, b[key]) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok && key != "name" {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func jsonFields(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// planProcess is the action ApplyConfig would take for the desired instance
+// ds, and the spec fields that differ from the registered one. orphans
+// returns the processes left by an earlier daemon.
+func (m *Manager) planProcess(ds process.Spec, orphans func() map[string]process.Orphan) (PlanChange, error) {
+	change := PlanChange{Name: ds.Name, Action: PlanStart}
+	m.mu.RLock()
+	up := m.processes[ds.Name]
+	m.mu.RUnlock()
+	if up != nil {
+		if current, err := m.GetSpec(ds.Name); err == nil {
+			change.Changes = DiffFields(current, ds)
+		}
+		if up.Status().Running {
+			change.Action = PlanKeep
+			return change, nil
+		}
+	}
+	if ds.PIDFile != "" {
+		pid, _, err := process.VerifyPIDFile(ds.PIDFile)
+		if err != nil {
+			return change, err
+		}
+		if pid > 0 {
+			change.Action = PlanRecover
+			return change, nil
+		}
+	}
+	if _, ok := orphans()[ds.Name]; ok {
+		change.Action = PlanRecover
+	}
+	return change, nil
+}
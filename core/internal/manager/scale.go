@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/loykin/provisr/core/internal/process"
+	"github.com/loykin/provisr/core/stats"
+)
+
+// scaleStopWait bounds the graceful stop of each instance removed by Scale.
+const scaleStopWait = 3 * time.Second
+
+// Scale changes the number of instances of the process set base to n. Only
+// the difference is acted on: scaling up registers and starts the new
+// instances, scaling down stops and unregisters the highest-numbered ones,
+// and the instances that remain keep running with their Instances updated.
+// Going from one instance to several, or back, renames the process (base
+// versus numbered instances), so the whole set is replaced as by
+// UpdateInstances.
+func (m *Manager) Scale(base string, n int) error {
+	if n < 1 {
+		return fmt.Errorf("instances must be at least 1, got %d", n)
+	}
+	spec, err := m.GetSpec(base)
+	if err != nil {
+		if spec, err = m.GetSpec(m.InstanceNaming().Name(base, 0)); err != nil {
+			return fmt.Errorf("process %s not found", base)
+		}
+	}
+	current := max(spec.Instances, 1)
+	if m.processBaseName(spec.Name, current) != base {
+		return fmt.Errorf("process %s not found", base)
+	}
+	if n == current {
+		return nil
+	}
+
+	oldNames := m.processInstanceNames(base, current)
+	newNames := m.processInstanceNames(base, n)
+	if current == 1 || n == 1 {
+		spec.Instances = n
+		if _, err := m.UpdateInstances(spec.Name, spec, scaleStopWait); err != nil {
+			return fmt.Errorf("scale %s to %d: %w", base, n, err)
+		}
+		m.forgetMetrics(oldNames)
+		return nil
+	}
+
+	spec.Name = base
+	spec.Instances = n
+	if n > current {
+		added := make([]process.Spec, 0, n-current)
+		for _, name := range newNames[current:] {
+			instanceSpec := spec
+			instanceSpec.Name = name
+			added = append(added, instanceSpec)
+		}
+		if err := m.registerSpecs(added); err != nil {
+			return fmt.Errorf("scale %s to %d: %w", base, n, err)
+		}
+	} else {
+		removed := oldNames[n:]
+		if err := m.unregisterExact(removed, scaleStopWait); err != nil {
+			return fmt.Errorf("scale %s to %d: %w", base, n, err)
+		}
+		m.forgetMetrics(removed)
+	}
+
+	for _, name := range newNames[:min(current, n)] {
+		m.mu.RLock()
+		up := m.processes[name]
+		m.mu.RUnlock()
+		if up == nil {
+			continue
+		}
+		instanceSpec, err := m.GetSpec(name)
+		if err != nil {
+			continue
+		}
+		instanceSpec.Instances = n
+		if err := up.UpdateSpec(instanceSpec); err != nil {
+			return fmt.Errorf("scale %s to %d: %w", base, n, err)
+		}
+	}
+	return nil
+}
+
+// forgetMetrics drops the metric series of removed processes right away when
+// the collector supports it, rather than on its next collection.
+func (m *Manager) forgetMetrics(names []string) {
+	m.mu.RLock()
+	collector := m.metricsCollector
+	m.mu.RUnlock()
+
+	forgetter, ok := collector.(stats.Forgetter)
+	if !ok {
+		return
+	}
+	gone := make([]string, 0, len(names))
+	m.mu.RLock()
+	for _, name := range names {
+		if _, registered := m.processes[name]; !registered {
+			gone = append(gone, name)
+		}
+	}
+	m.mu.RUnlock()
+	for _, name := range gone {
+		forgetter.Forget(name)
+	}
+}
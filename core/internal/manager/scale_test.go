@@ -0,0 +1,103 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/loykin/provisr/core/internal/process"
+	"github.com/loykin/provisr/core/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// forgettingCollector records the names passed to Forget.
+type forgettingCollector struct {
+	mu        sync.Mutex
+	forgotten []string
+}
+
+func (c *forgettingCollector) Start(context.Context, func() map[string]int32) error { return nil }
+func (c *forgettingCollector) Stop()                                                {}
+func (c *forgettingCollector) IsEnabled() bool                                      { return false }
+func (c *forgettingCollector) GetMetrics(string) (stats.ProcessMetrics, bool) {
+	return stats.ProcessMetrics{}, false
+}
+func (c *forgettingCollector) GetHistory(string) ([]stats.ProcessMetrics, bool) { return nil, false }
+func (c *forgettingCollector) GetAllMetrics() map[string]stats.ProcessMetrics   { return nil }
+func (c *forgettingCollector) Forget(name string) {
+	c.mu.Lock()
+	c.forgotten = append(c.forgotten, name)
+	c.mu.Unlock()
+}
+
+func runningNames(t *testing.T, mgr *Manager, base string) []string {
+	t.Helper()
+	sts, err := mgr.StatusAll(base)
+	require.NoError(t, err)
+	var names []string
+	for _, st := range sts {
+		if st.Running {
+			names = append(names, st.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestScale(t *testing.T) {
+	mgr := NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	collector := &forgettingCollector{}
+	require.NoError(t, mgr.SetProcessMetricsCollector(collector))
+
+	require.NoError(t, mgr.RegisterN(process.Spec{Name: "worker", Command: "sleep 30", Instances: 2}))
+	first, err := mgr.Status("worker-1")
+	require.NoError(t, err)
+
+	// Up: only the new instances start.
+	require.NoError(t, mgr.Scale("worker", 4))
+	assert.Equal(t, []string{"worker-1", "worker-2", "worker-3", "worker-4"}, runningNames(t, mgr, "worker"))
+	st, err := mgr.Status("worker-1")
+	require.NoError(t, err)
+	assert.Equal(t, first.PID, st.PID, "existing instances keep running")
+	for _, name := range []string{"worker-1", "worker-4"} {
+		spec, err := mgr.GetSpec(name)
+		require.NoError(t, err)
+		assert.Equal(t, 4, spec.Instances, name)
+	}
+	base, err := mgr.ProcessBase("worker-4")
+	require.NoError(t, err)
+	assert.Equal(t, "worker", base)
+
+	// Down: the highest-numbered instances go away.
+	require.NoError(t, mgr.Scale("worker", 2))
+	assert.Equal(t, []string{"worker-1", "worker-2"}, runningNames(t, mgr, "worker"))
+	_, err = mgr.Status("worker-3")
+	assert.Error(t, err)
+	st, err = mgr.Status("worker-1")
+	require.NoError(t, err)
+	assert.Equal(t, first.PID, st.PID)
+
+	// Down to one: the set becomes the single process "worker".
+	require.NoError(t, mgr.Scale("worker", 1))
+	assert.Equal(t, []string{"worker"}, runningNames(t, mgr, "worker"))
+	spec, err := mgr.GetSpec("worker")
+	require.NoError(t, err)
+	assert.Equal(t, 1, spec.Instances)
+
+	collector.mu.Lock()
+	forgotten := append([]string(nil), collector.forgotten...)
+	collector.mu.Unlock()
+	sort.Strings(forgotten)
+	assert.Equal(t, []string{"worker-1", "worker-2", "worker-3", "worker-4"}, forgotten)
+
+	// And back up again.
+	require.NoError(t, mgr.Scale("worker", 2))
+	assert.Equal(t, []string{"worker-1", "worker-2"}, runningNames(t, mgr, "worker"))
+
+	assert.Error(t, mgr.Scale("worker", 0))
+	assert.Error(t, mgr.Scale("missing", 2))
+	require.NoError(t, mgr.Scale("worker", 2), "same count is a no-op")
+}
@@ -0,0 +1,63 @@
+package manager
+
+import (
+	"context"
+	"sync"
+)
+
+// startLimiter caps how many processes are starting at once, so bringing up
+// hundreds of them (ApplyConfig, a group start, a crash wave of automatic
+// restarts) doesn't overwhelm the host. Processes beyond the limit wait for
+// a slot before they enter StateStarting.
+type startLimiter struct {
+	mu       sync.Mutex
+	limit    int // 0 means unlimited
+	inFlight int
+	freed    chan struct{} // closed and replaced whenever a slot may have freed up
+}
+
+func newStartLimiter() *startLimiter {
+	return &startLimiter{freed: make(chan struct{})}
+}
+
+// setLimit changes the limit; waiting starts proceed at once if it grew.
+func (l *startLimiter) setLimit(n int) {
+	l.mu.Lock()
+	l.limit = max(n, 0)
+	l.wakeLocked()
+	l.mu.Unlock()
+}
+
+// wakeLocked wakes every waiting acquire to check for a free slot.
+func (l *startLimiter) wakeLocked() {
+	close(l.freed)
+	l.freed = make(chan struct{})
+}
+
+// acquire blocks until a start slot is free or ctx is done, and returns the
+// function that frees the slot again; calling that more than once is
+// harmless. A nil limiter never blocks.
+func (l *startLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	for l.limit > 0 && l.inFlight >= l.limit {
+		freed := l.freed
+		l.mu.Unlock()
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		l.mu.Lock()
+	}
+	l.inFlight++
+	l.mu.Unlock()
+	return sync.OnceFunc(func() {
+		l.mu.Lock()
+		l.inFlight--
+		l.wakeLocked()
+		l.mu.Unlock()
+	}), nil
+}
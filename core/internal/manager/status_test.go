@@ -0,0 +1,79 @@
+package manager
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr/core/internal/process"
+)
+
+func TestStatusAllSortsLargeSets(t *testing.T) {
+	mgr := NewManager()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	// Enough processes for statusMatching to snapshot them in parallel.
+	if err := mgr.Add(process.Spec{Name: "svc", Command: "sleep 60", Instances: parallelStatusMin + 44}); err != nil {
+		t.Fatal(err)
+	}
+	sts, err := mgr.StatusAll("svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sts) != parallelStatusMin+44 {
+		t.Fatalf("StatusAll = %d statuses, want %d", len(sts), parallelStatusMin+44)
+	}
+	for i, st := range sts {
+		if st.State != "stopped" || (i > 0 && st.Name <= sts[i-1].Name) {
+			t.Fatalf("status %d = %s (%s) after %s", i, st.Name, st.State, sts[max(i-1, 0)].Name)
+		}
+	}
+}
+
+// BenchmarkStatusAll snapshots 5000 processes; the goal is well under
+// 100ms per StatusAll. Running processes cost a liveness probe each.
+func BenchmarkStatusAll(b *testing.B) {
+	b.Run("stopped", func(b *testing.B) {
+		mgr := NewManager()
+		b.Cleanup(func() { _ = mgr.Shutdown() })
+		for i := 0; i < 50; i++ {
+			spec := process.Spec{Name: fmt.Sprintf("svc%02d", i), Command: "sleep 60", Instances: 100}
+			if err := mgr.Add(spec); err != nil {
+				b.Fatal(err)
+			}
+		}
+		benchmarkStatusAll(b, mgr, 5000)
+	})
+	b.Run("running", func(b *testing.B) {
+		if runtime.GOOS == "windows" {
+			b.Skip("requires Unix sleep")
+		}
+		mgr := NewManager()
+		b.Cleanup(func() { _ = mgr.Shutdown() })
+		for i := 0; i < 5; i++ {
+			spec := process.Spec{Name: fmt.Sprintf("svc%d", i), Command: "sleep 60", Instances: 100}
+			if err := mgr.RegisterN(spec); err != nil {
+				b.Fatal(err)
+			}
+		}
+		deadline := time.Now().Add(30 * time.Second)
+		for n, _ := mgr.Count("*"); n < 500; n, _ = mgr.Count("*") {
+			if time.Now().After(deadline) {
+				b.Fatalf("only %d of 500 instances running", n)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		benchmarkStatusAll(b, mgr, 500)
+	})
+}
+
+func benchmarkStatusAll(b *testing.B, mgr *Manager, want int) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sts, err := mgr.StatusAll("*")
+		if err != nil || len(sts) != want {
+			b.Fatalf("StatusAll = %d statuses, %v", len(sts), err)
+		}
+	}
+}
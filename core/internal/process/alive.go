@@ -0,0 +1,10 @@
+package process
+
+// pidState is what a liveness probe learned about a PID.
+type pidState int
+
+const (
+	pidGone   pidState = iota // no such process, or a zombie
+	pidAlive                  // the process is running
+	pidReused                 // a different process now has the PID
+)
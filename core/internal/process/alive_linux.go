@@ -0,0 +1,43 @@
+package process
+
+import (
+	"os"
+	"sync"
+)
+
+// procMounted reports whether /proc can be read at all; without it the
+// probe falls back to signal 0.
+var procMounted = sync.OnceValue(func() bool {
+	_, err := os.Stat("/proc/self/stat")
+	return err == nil
+})
+
+// probePID checks pid through /proc/<pid>/stat. A zombie or dead ('X')
+// process is gone, and one whose start time differs from startTicks
+// (when known) has reused the PID.
+func probePID(pid int, startTicks int64) pidState {
+	if !procMounted() {
+		if killProcess(pid, 0) == nil {
+			return pidAlive
+		}
+		return pidGone
+	}
+	state, start, ok := procState(pid)
+	switch {
+	case !ok, state == 'Z', state == 'X', state == 'x':
+		return pidGone
+	case startTicks > 0 && start != startTicks:
+		return pidReused
+	}
+	return pidAlive
+}
+
+// procStartTicks returns the start time of pid in clock ticks since boot,
+// or 0 when it can't be read.
+func procStartTicks(pid int) int64 {
+	_, start, ok := procState(pid)
+	if !ok {
+		return 0
+	}
+	return start
+}
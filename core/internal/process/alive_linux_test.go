@@ -0,0 +1,60 @@
+package process
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestDetectAliveKnownDeadPID(t *testing.T) {
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// Reaped by Run, so the PID names no process until it is reused.
+	pid := cmd.Process.Pid
+	if got := probePID(pid, 0); got != pidGone {
+		t.Fatalf("probePID(dead %d) = %v, want pidGone", pid, got)
+	}
+	r := New(Spec{Name: "dead"})
+	r.SeedPID(pid)
+	if alive, how := r.DetectAlive(); alive || how != "not-found" {
+		t.Fatalf("dead pid: alive=%v via %s, want not-found", alive, how)
+	}
+}
+
+func TestProbePIDChecksStartTime(t *testing.T) {
+	self := os.Getpid()
+	start := procStartTicks(self)
+	if start == 0 {
+		t.Fatal("no start time for this process")
+	}
+	if got := probePID(self, start); got != pidAlive {
+		t.Fatalf("probePID(self, start) = %v, want pidAlive", got)
+	}
+	if got := probePID(self, 0); got != pidAlive {
+		t.Fatalf("probePID(self, unknown start) = %v, want pidAlive", got)
+	}
+	if got := probePID(self, start+1); got != pidReused {
+		t.Fatalf("probePID(self, other start) = %v, want pidReused", got)
+	}
+}
+
+func TestDetectAliveNoticesReusedSpawnedPID(t *testing.T) {
+	r := New(Spec{Name: "reused", Command: "sleep 5"})
+	if err := r.TryStart(r.ConfigureCmd(nil)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Kill() })
+	if alive, how := r.DetectAlive(); !alive || how != "exec:pid" {
+		t.Fatalf("fresh process: alive=%v via %s", alive, how)
+	}
+
+	// Pretend the PID now belongs to a process started at another time.
+	r.mu.Lock()
+	r.startTicks++
+	r.mu.Unlock()
+	if alive, how := r.DetectAlive(); alive || how != "pid-reused" {
+		t.Fatalf("reused pid: alive=%v via %s, want pid-reused", alive, how)
+	}
+}
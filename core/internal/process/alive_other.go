@@ -0,0 +1,15 @@
+//go:build !linux
+
+package process
+
+// probePID falls back to signal 0, which cannot tell a reused PID from
+// the original process.
+func probePID(pid int, _ int64) pidState {
+	if killProcess(pid, 0) == nil {
+		return pidAlive
+	}
+	return pidGone
+}
+
+// procStartTicks is only known on Linux.
+func procStartTicks(int) int64 { return 0 }
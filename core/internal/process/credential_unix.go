@@ -0,0 +1,159 @@
+//go:build !windows
+
+package process
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// checkCredentialSupport resolves the spec's user and groups so unknown
+// names are rejected when the spec is registered rather than at start.
+func checkCredentialSupport(spec Spec) error {
+	_, err := resolveCredential(spec)
+	return err
+}
+
+// resolveCredential turns the spec's User, Group and SupplementaryGroups
+// (names or numeric IDs) into a credential for SysProcAttr. It returns nil
+// when none of them are set, so the child inherits the daemon's identity.
+//
+// When User is set, Group defaults to the user's primary group and the
+// supplementary groups default to the user's group memberships, the same
+// as a login would; an explicit SupplementaryGroups list replaces them.
+func resolveCredential(spec Spec) (*syscall.Credential, error) {
+	if spec.User == "" && spec.Group == "" && len(spec.SupplementaryGroups) == 0 {
+		return nil, nil
+	}
+	cred := &syscall.Credential{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid())}
+
+	var u *user.User
+	if spec.User != "" {
+		uid, found, err := lookupUser(spec.User)
+		if err != nil {
+			return nil, err
+		}
+		cred.Uid = uid
+		u = found
+		if u != nil {
+			gid, err := strconv.ParseUint(u.Gid, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("user %q has non-numeric primary group %q", spec.User, u.Gid)
+			}
+			cred.Gid = uint32(gid)
+		}
+	}
+	if spec.Group != "" {
+		gid, err := lookupGroup(spec.Group)
+		if err != nil {
+			return nil, err
+		}
+		cred.Gid = gid
+	}
+
+	switch {
+	case len(spec.SupplementaryGroups) > 0:
+		for _, g := range spec.SupplementaryGroups {
+			gid, err := lookupGroup(g)
+			if err != nil {
+				return nil, err
+			}
+			cred.Groups = append(cred.Groups, gid)
+		}
+	case u != nil:
+		ids, err := u.GroupIds()
+		if err != nil {
+			return nil, fmt.Errorf("list groups of user %q: %w", spec.User, err)
+		}
+		for _, id := range ids {
+			gid, err := strconv.ParseUint(id, 10, 32)
+			if err != nil {
+				continue
+			}
+			cred.Groups = append(cred.Groups, uint32(gid))
+		}
+	}
+	return cred, nil
+}
+
+// lookupUser resolves a user name or numeric UID. A numeric UID without a
+// passwd entry is accepted and returns a nil *user.User.
+func lookupUser(name string) (uint32, *user.User, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		if id, convErr := strconv.ParseUint(name, 10, 32); convErr == nil {
+			if byID, idErr := user.LookupId(name); idErr == nil {
+				return uint32(id), byID, nil
+			}
+			return uint32(id), nil, nil
+		}
+		return 0, nil, fmt.Errorf("unknown user %q: %w", name, err)
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, nil, fmt.Errorf("user %q has non-numeric uid %q", name, u.Uid)
+	}
+	return uint32(uid), u, nil
+}
+
+// lookupGroup resolves a group name or numeric GID.
+func lookupGroup(name string) (uint32, error) {
+	if g, err := user.LookupGroup(name); err == nil {
+		gid, convErr := strconv.ParseUint(g.Gid, 10, 32)
+		if convErr != nil {
+			return 0, fmt.Errorf("group %q has non-numeric gid %q", name, g.Gid)
+		}
+		return uint32(gid), nil
+	}
+	if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+	return 0, fmt.Errorf("unknown group %q", name)
+}
+
+// resolveOwner turns "user[:group]" (names or numeric IDs) into a uid and
+// gid. The group defaults to the user's primary group, or is left unchanged
+// (-1) for a numeric UID without a passwd entry.
+func resolveOwner(owner string) (int, int, error) {
+	name, group, _ := strings.Cut(owner, ":")
+	uid, u, err := lookupUser(name)
+	if err != nil {
+		return -1, -1, err
+	}
+	gid := -1
+	if group != "" {
+		g, err := lookupGroup(group)
+		if err != nil {
+			return -1, -1, err
+		}
+		gid = int(g)
+	} else if u != nil {
+		g, err := strconv.ParseUint(u.Gid, 10, 32)
+		if err != nil {
+			return -1, -1, fmt.Errorf("user %q has non-numeric primary group %q", name, u.Gid)
+		}
+		gid = int(g)
+	}
+	return int(uid), gid, nil
+}
+
+// logDirOwner returns the owner for a log directory created for spec: the
+// log dir_owner when set, else the process's user and group when the daemon
+// runs as root, else -1, -1 to keep the daemon's.
+func logDirOwner(spec Spec) (int, int, error) {
+	if owner := spec.Log.File.DirOwner; owner != "" {
+		return resolveOwner(owner)
+	}
+	if spec.User == "" || os.Geteuid() != 0 {
+		return -1, -1, nil
+	}
+	cred, err := resolveCredential(spec)
+	if err != nil {
+		return -1, -1, err
+	}
+	return int(cred.Uid), int(cred.Gid), nil
+}
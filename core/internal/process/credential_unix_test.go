@@ -0,0 +1,84 @@
+//go:build !windows
+
+package process
+
+import (
+	"os"
+	"os/user"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveCredential_NoIdentity(t *testing.T) {
+	cred, err := resolveCredential(Spec{Name: "p"})
+	if err != nil || cred != nil {
+		t.Fatalf("expected no credential without user/group, got %+v, %v", cred, err)
+	}
+}
+
+func TestResolveCredential_UnknownNames(t *testing.T) {
+	if _, err := resolveCredential(Spec{User: "provisr-no-such-user"}); err == nil {
+		t.Fatal("expected error for unknown user")
+	}
+	if _, err := resolveCredential(Spec{Group: "provisr-no-such-group"}); err == nil {
+		t.Fatal("expected error for unknown group")
+	}
+	if err := (&Spec{Name: "p", Command: "true", User: "provisr-no-such-user"}).Validate(); err == nil {
+		t.Fatal("Validate should reject an unknown user")
+	}
+}
+
+func TestResolveCredential_NumericIDs(t *testing.T) {
+	cred, err := resolveCredential(Spec{User: "65000", Group: "65001", SupplementaryGroups: []string{"65002"}})
+	if err != nil {
+		t.Fatalf("numeric ids should resolve without passwd entries: %v", err)
+	}
+	if cred.Uid != 65000 || cred.Gid != 65001 || len(cred.Groups) != 1 || cred.Groups[0] != 65002 {
+		t.Fatalf("unexpected credential: %+v", cred)
+	}
+}
+
+func TestRunAsUser(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("requires root to switch user")
+	}
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skipf("no nobody user: %v", err)
+	}
+
+	r := New(Spec{Name: "as-nobody", Command: "sh -c 'id -u; id -g'", User: "nobody"})
+	cmd := r.ConfigureCmd(nil)
+	if err := r.TryStart(cmd); err != nil {
+		t.Fatalf("TryStart: %v", err)
+	}
+	want := nobody.Uid + "\n" + nobody.Gid
+	var got string
+	waitUntil(3*time.Second, 20*time.Millisecond, func() bool {
+		lines, _ := r.LogsSince(0, 0)
+		parts := make([]string, 0, len(lines))
+		for _, l := range lines {
+			parts = append(parts, strings.TrimSpace(l.Text))
+		}
+		got = strings.Join(parts, "\n")
+		return got == want
+	})
+	if got != want {
+		t.Fatalf("child ran as %q, want %q", got, want)
+	}
+}
+
+func TestRunAsUserWithoutPermission(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root may switch to any user")
+	}
+	r := New(Spec{Name: "as-root", Command: "true", User: "0"})
+	err := r.TryStart(r.ConfigureCmd(nil))
+	if err == nil {
+		t.Fatal("expected start to fail without permission to setuid")
+	}
+	if !strings.Contains(err.Error(), "lacks permission") {
+		t.Fatalf("expected a clear permission error, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package process
+
+import "errors"
+
+// checkCredentialSupport reports an error when the spec asks to switch
+// identity: Windows has no setuid/setgid equivalent for child processes.
+func checkCredentialSupport(spec Spec) error {
+	if spec.User != "" || spec.Group != "" || len(spec.SupplementaryGroups) > 0 {
+		return errors.New("user/group are not supported on Windows")
+	}
+	return nil
+}
+
+// resolveOwner reports an error: directory ownership is not configurable on
+// Windows.
+func resolveOwner(string) (int, int, error) {
+	return -1, -1, errors.New("directory owner is not supported on Windows")
+}
+
+// logDirOwner keeps the daemon's ownership unless dir_owner asks otherwise,
+// which Windows does not support.
+func logDirOwner(spec Spec) (int, int, error) {
+	if spec.Log.File.DirOwner != "" {
+		return resolveOwner(spec.Log.File.DirOwner)
+	}
+	return -1, -1, nil
+}
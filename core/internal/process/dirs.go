@@ -0,0 +1,101 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// defaultDirMode is the permission of a log or PID directory created
+// without an explicit mode.
+const defaultDirMode = 0o750
+
+// ParseDirMode parses an octal directory mode such as "0750". Empty is the
+// 0750 default.
+func ParseDirMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return defaultDirMode, nil
+	}
+	v, err := parsePermBits("directory mode", s)
+	if err != nil {
+		return 0, err
+	}
+	return os.FileMode(v), nil
+}
+
+// ValidateDirOwner checks that owner, "user[:group]" by name or numeric ID,
+// resolves. Empty is valid and keeps the daemon's ownership.
+func ValidateDirOwner(owner string) error {
+	if owner == "" {
+		return nil
+	}
+	_, _, err := resolveOwner(owner)
+	return err
+}
+
+// EnsureDir creates dir, including missing parents, when it does not exist
+// yet, then gives it mode and, when owner is set, that owner. An existing
+// directory is left as is.
+func EnsureDir(dir string, mode os.FileMode, owner string) error {
+	uid, gid := -1, -1
+	if owner != "" {
+		var err error
+		if uid, gid, err = resolveOwner(owner); err != nil {
+			return err
+		}
+	}
+	return ensureDir(dir, mode, uid, gid)
+}
+
+func ensureDir(dir string, mode os.FileMode, uid, gid int) error {
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("%s exists and is not a directory", dir)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, mode); err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("cannot create directory %s: permission denied (create it ahead of time or run the daemon as a user allowed to): %w", dir, err)
+		}
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	// MkdirAll is subject to the daemon's umask; apply the mode as given
+	if err := os.Chmod(dir, mode); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", dir, err)
+	}
+	if uid >= 0 || gid >= 0 {
+		if err := os.Chown(dir, uid, gid); err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				return fmt.Errorf("cannot change owner of %s to %d:%d: permission denied (only root can give a directory away): %w", dir, uid, gid, err)
+			}
+			return fmt.Errorf("failed to change owner of %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// EnsureLogDir creates the spec's log directory when it does not exist yet,
+// with the log dir_mode (default 0750) and dir_owner. Without dir_owner, a
+// daemon running as root gives the directory to the process's User, so it
+// matches the identity the child runs as.
+func EnsureLogDir(spec Spec) error {
+	dir := spec.Log.File.Dir
+	if dir == "" || spec.Detached {
+		return nil
+	}
+	mode, err := ParseDirMode(spec.Log.File.DirMode)
+	if err != nil {
+		return fmt.Errorf("log dir_mode: %w", err)
+	}
+	uid, gid, err := logDirOwner(spec)
+	if err != nil {
+		return fmt.Errorf("log dir_owner: %w", err)
+	}
+	if err := ensureDir(dir, mode, uid, gid); err != nil {
+		return fmt.Errorf("log dir: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirOptionsValidation(t *testing.T) {
+	if _, err := ParseDirMode("0899"); err == nil {
+		t.Fatal("invalid mode accepted")
+	}
+	if _, err := ParseDirMode("01777"); err == nil {
+		t.Fatal("mode with special bits accepted")
+	}
+	spec := Spec{Name: "web", Command: "true"}
+	spec.Log.File.DirMode = "rwx"
+	if err := spec.Validate(); err == nil || !strings.Contains(err.Error(), "log dir_mode") {
+		t.Fatalf("expected a dir_mode error, got %v", err)
+	}
+	spec.Log.File.DirMode = ""
+	spec.Log.File.DirOwner = "provisr-no-such-user"
+	if err := spec.Validate(); err == nil || !strings.Contains(err.Error(), "log dir_owner") {
+		t.Fatalf("expected a dir_owner error, got %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureDir(file, 0o750, ""); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Fatalf("expected a not a directory error, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+//go:build !windows
+
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestEnsureLogDirAppliesMode(t *testing.T) {
+	// A restrictive umask must not eat into the configured mode.
+	old := syscall.Umask(0o077)
+	defer syscall.Umask(old)
+
+	dir := filepath.Join(t.TempDir(), "logs", "web")
+	spec := Spec{Name: "web", Command: "true"}
+	spec.Log.File.Dir = dir
+	spec.Log.File.DirMode = "0755"
+	if err := spec.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureLogDir(spec); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Fatalf("mode = %v, want 0755", info.Mode().Perm())
+	}
+
+	// An existing directory keeps its mode.
+	spec.Log.File.DirMode = "0700"
+	if err := EnsureLogDir(spec); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(dir); info.Mode().Perm() != 0o755 {
+		t.Fatalf("existing dir mode changed to %v", info.Mode().Perm())
+	}
+
+	// Without dir_mode, a created directory gets 0750.
+	spec.Log.File.Dir = filepath.Join(t.TempDir(), "default")
+	spec.Log.File.DirMode = ""
+	if err := EnsureLogDir(spec); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(spec.Log.File.Dir); info.Mode().Perm() != defaultDirMode {
+		t.Fatalf("default mode = %v, want %v", info.Mode().Perm(), os.FileMode(defaultDirMode))
+	}
+}
+
+func TestEnsureDirOwner(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run")
+	self := strconv.Itoa(os.Getuid()) + ":" + strconv.Itoa(os.Getgid())
+	if err := EnsureDir(dir, 0o700, self); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		t.Fatalf("owner uid = %d, want %d", st.Uid, os.Getuid())
+	}
+
+	if os.Geteuid() != 0 {
+		err := EnsureDir(filepath.Join(t.TempDir(), "other"), 0o750, "0:0")
+		if err == nil || !strings.Contains(err.Error(), "permission denied") {
+			t.Fatalf("expected a permission error giving a directory away, got %v", err)
+		}
+	}
+}
@@ -2,11 +2,56 @@ package process
 
 import (
 	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 )
 
+// ErrStartTimeout is returned (wrapped) when a start does not complete
+// within Spec.StartTimeout.
+var ErrStartTimeout = errors.New("start timeout exceeded")
+
+// ErrNotRunning is returned (wrapped) by operations that need a running
+// process, such as sending it a signal.
+var ErrNotRunning = errors.New("process is not running")
+
 func fmtErrorString(s string) error { return errors.New(s) }
 
 func errBeforeStart(d time.Duration) error {
 	return fmtErrorString("process exited before start duration " + d.String())
 }
+
+// ParseUmask parses an octal umask such as "027" or "0o027".
+func ParseUmask(s string) (uint32, error) {
+	return parsePermBits("umask", s)
+}
+
+// parsePermBits parses octal permission bits such as "0750" or "0o750",
+// naming what in the error when s is not between 000 and 777.
+func parsePermBits(what, s string) (uint32, error) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O"), 8, 32)
+	if err != nil || v > 0o777 {
+		return 0, fmt.Errorf("invalid %s %q: must be an octal value between 000 and 777", what, s)
+	}
+	return uint32(v), nil
+}
+
+// ExitDetails extracts the exit code and terminating signal from the error
+// returned by cmd.Wait. A nil error is a clean exit (code 0). code is -1
+// when the process was killed by a signal or the error isn't an exit status.
+func ExitDetails(err error) (code int, signal string) {
+	if err == nil {
+		return 0, ""
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return -1, ""
+	}
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return -1, ws.Signal().String()
+	}
+	return exitErr.ExitCode(), ""
+}
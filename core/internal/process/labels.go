@@ -0,0 +1,73 @@
+package process
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// labelKeyPattern restricts label keys to what a "key=value" selector can
+// carry: no '=', ',' or whitespace.
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_./-]{0,62}[A-Za-z0-9])?$`)
+
+// maxLabelValueLen bounds a label value.
+const maxLabelValueLen = 256
+
+// ValidateLabels checks label keys and values.
+func ValidateLabels(labels map[string]string) error {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid label key %q: use up to 64 letters, digits, '_', '.', '/' or '-', starting and ending with a letter or digit", key)
+		}
+		value := labels[key]
+		if len(value) > maxLabelValueLen {
+			return fmt.Errorf("label %q: value longer than %d bytes", key, maxLabelValueLen)
+		}
+		if strings.ContainsAny(value, ",\n\r") {
+			return fmt.Errorf("label %q: value must not contain ',' or line breaks", key)
+		}
+	}
+	return nil
+}
+
+// ParseLabelSelector parses "key=value" terms, each either a separate
+// element of terms or joined with commas, into the labels a process must
+// have.
+func ParseLabelSelector(terms ...string) (map[string]string, error) {
+	selector := make(map[string]string)
+	for _, term := range terms {
+		for _, part := range strings.Split(term, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(part, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return nil, fmt.Errorf("invalid label selector %q: want key=value", part)
+			}
+			key = strings.TrimSpace(key)
+			if prev, dup := selector[key]; dup && prev != value {
+				return nil, fmt.Errorf("invalid label selector: %q given twice", key)
+			}
+			selector[key] = value
+		}
+	}
+	return selector, nil
+}
+
+// MatchLabels reports whether labels has every key of selector set to its
+// value. An empty selector matches any labels.
+func MatchLabels(labels, selector map[string]string) bool {
+	for key, want := range selector {
+		if got, ok := labels[key]; !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
@@ -12,19 +12,35 @@ type LifecycleHooks struct {
 	PostStart []Hook `json:"post_start" mapstructure:"post_start"` // After process starts successfully
 	PreStop   []Hook `json:"pre_stop" mapstructure:"pre_stop"`     // Before process stops
 	PostStop  []Hook `json:"post_stop" mapstructure:"post_stop"`   // After process stops
+
+	// AsyncWait is how long stop and shutdown wait for still-running async
+	// pre_start and post_start hooks before killing them; 0 kills them
+	// immediately. Shutdown waits for async pre_stop and post_stop hooks
+	// until they finish or reach their Timeout.
+	AsyncWait time.Duration `json:"async_wait" mapstructure:"async_wait"`
 }
 
 // Hook represents a single lifecycle hook command
 type Hook struct {
-	Name        string        `json:"name" mapstructure:"name"`                 // Hook name for identification
-	Command     string        `json:"command" mapstructure:"command"`           // Command to execute
-	WorkDir     string        `json:"work_dir" mapstructure:"work_dir"`         // Working directory (optional)
-	Env         []string      `json:"env" mapstructure:"env"`                   // Additional environment variables
-	Timeout     time.Duration `json:"timeout" mapstructure:"timeout"`           // Execution timeout (default: 30s)
-	FailureMode FailureMode   `json:"failure_mode" mapstructure:"failure_mode"` // How to handle failures
-	RunMode     RunMode       `json:"run_mode" mapstructure:"run_mode"`         // Blocking or async execution
+	Name          string        `json:"name" mapstructure:"name"`                         // Hook name for identification
+	Command       string        `json:"command" mapstructure:"command"`                   // Command to execute
+	WorkDir       string        `json:"work_dir" mapstructure:"work_dir"`                 // Working directory (optional)
+	Env           []string      `json:"env" mapstructure:"env"`                           // Additional environment variables
+	Timeout       time.Duration `json:"timeout" mapstructure:"timeout"`                   // Execution timeout (default: 30s)
+	FailureMode   FailureMode   `json:"failure_mode" mapstructure:"failure_mode"`         // How to handle failures
+	RunMode       RunMode       `json:"run_mode" mapstructure:"run_mode"`                 // Blocking or async execution
+	MaxRetries    *int          `json:"max_retries,omitempty" mapstructure:"max_retries"` // Retries for failure_mode=retry; unset means 1, 0 means none
+	RetryInterval time.Duration `json:"retry_interval" mapstructure:"retry_interval"`     // Delay before the first retry (default: 1s)
+	RetryBackoff  float64       `json:"retry_backoff" mapstructure:"retry_backoff"`       // Delay multiplier per retry; 0 or 1 keeps it constant
 }
 
+const (
+	defaultHookMaxRetries    = 1
+	defaultHookRetryInterval = time.Second
+	maxHookRetries           = 100
+	maxHookRetryDelay        = 5 * time.Minute
+)
+
 // FailureMode defines how to handle hook execution failures
 type FailureMode string
 
@@ -44,6 +60,10 @@ const (
 
 // Validate validates the lifecycle hooks configuration
 func (lh *LifecycleHooks) Validate() error {
+	if lh.AsyncWait < 0 {
+		return fmt.Errorf("async_wait cannot be negative")
+	}
+
 	// Check for duplicate hook names across all phases
 	hookNames := make(map[string]string) // name -> phase
 
@@ -137,6 +157,16 @@ func (h *Hook) Validate() error {
 		return fmt.Errorf("hook %q: timeout too long (max 1 hour)", name)
 	}
 
+	if h.MaxRetries != nil && (*h.MaxRetries < 0 || *h.MaxRetries > maxHookRetries) {
+		return fmt.Errorf("hook %q: max_retries must be between 0 and %d", name, maxHookRetries)
+	}
+	if h.RetryInterval < 0 {
+		return fmt.Errorf("hook %q: retry_interval cannot be negative", name)
+	}
+	if h.RetryBackoff != 0 && h.RetryBackoff < 1 {
+		return fmt.Errorf("hook %q: retry_backoff must be >= 1 (or 0 to disable)", name)
+	}
+
 	// Validate working directory if specified
 	if h.WorkDir != "" {
 		workDir := strings.TrimSpace(h.WorkDir)
@@ -183,6 +213,40 @@ func (h *Hook) GetDefaults() {
 	if h.Timeout == 0 {
 		h.Timeout = 30 * time.Second // Default 30 second timeout
 	}
+
+	if h.MaxRetries == nil {
+		retries := defaultHookMaxRetries // Historically retry mode retried exactly once
+		h.MaxRetries = &retries
+	}
+
+	if h.RetryInterval == 0 {
+		h.RetryInterval = defaultHookRetryInterval
+	}
+}
+
+// Retries returns how many times failure_mode=retry re-runs the hook:
+// MaxRetries when set, otherwise the default of one.
+func (h *Hook) Retries() int {
+	if h.MaxRetries == nil {
+		return defaultHookMaxRetries
+	}
+	return *h.MaxRetries
+}
+
+// RetryDelay returns how long to wait before retry number attempt (1-based):
+// RetryInterval grown by RetryBackoff per previous retry, capped at 5m.
+func (h *Hook) RetryDelay(attempt int) time.Duration {
+	delay := h.RetryInterval
+	if delay <= 0 {
+		delay = defaultHookRetryInterval
+	}
+	for i := 1; i < attempt && h.RetryBackoff > 1; i++ {
+		delay = time.Duration(float64(delay) * h.RetryBackoff)
+		if delay >= maxHookRetryDelay {
+			return maxHookRetryDelay
+		}
+	}
+	return min(delay, maxHookRetryDelay)
 }
 
 // HasAnyHooks returns true if there are any hooks defined
@@ -232,6 +296,7 @@ func (lh *LifecycleHooks) DeepCopy() LifecycleHooks {
 		PostStart: copyHooks(lh.PostStart),
 		PreStop:   copyHooks(lh.PreStop),
 		PostStop:  copyHooks(lh.PostStop),
+		AsyncWait: lh.AsyncWait,
 	}
 
 	return hooks
@@ -254,12 +319,18 @@ func copyHooks(hooks []Hook) []Hook {
 // DeepCopy creates a deep copy of a Hook
 func (h *Hook) DeepCopy() Hook {
 	hook := Hook{
-		Name:        h.Name,
-		Command:     h.Command,
-		WorkDir:     h.WorkDir,
-		Timeout:     h.Timeout,
-		FailureMode: h.FailureMode,
-		RunMode:     h.RunMode,
+		Name:          h.Name,
+		Command:       h.Command,
+		WorkDir:       h.WorkDir,
+		Timeout:       h.Timeout,
+		FailureMode:   h.FailureMode,
+		RunMode:       h.RunMode,
+		RetryInterval: h.RetryInterval,
+		RetryBackoff:  h.RetryBackoff,
+	}
+	if h.MaxRetries != nil {
+		retries := *h.MaxRetries
+		hook.MaxRetries = &retries
 	}
 
 	// Copy environment variables slice
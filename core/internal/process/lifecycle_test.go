@@ -7,6 +7,7 @@ import (
 )
 
 func TestHook_Validate(t *testing.T) {
+	negative := -1
 	tests := []struct {
 		name    string
 		hook    Hook
@@ -159,6 +160,18 @@ func TestHook_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "is reserved (PROVISR_ prefix)",
 		},
+		{
+			name:    "negative max retries",
+			hook:    Hook{Name: "test", Command: "echo test", MaxRetries: &negative},
+			wantErr: true,
+			errMsg:  "max_retries must be between",
+		},
+		{
+			name:    "shrinking backoff",
+			hook:    Hook{Name: "test", Command: "echo test", RetryBackoff: 0.5},
+			wantErr: true,
+			errMsg:  "retry_backoff must be >= 1",
+		},
 	}
 
 	for _, tt := range tests {
@@ -198,6 +211,40 @@ func TestHook_GetDefaults(t *testing.T) {
 	if hook.Timeout != 30*time.Second {
 		t.Errorf("GetDefaults() Timeout = %v, want %v", hook.Timeout, 30*time.Second)
 	}
+	if hook.MaxRetries == nil || *hook.MaxRetries != 1 || hook.RetryInterval != time.Second {
+		t.Errorf("GetDefaults() retries = %d every %v, want 1 every 1s", hook.Retries(), hook.RetryInterval)
+	}
+
+	// An explicit zero means no retries and is kept.
+	none := 0
+	explicit := &Hook{Name: "test", Command: "echo test", MaxRetries: &none}
+	if err := explicit.Validate(); err != nil {
+		t.Fatalf("Validate() with max_retries = 0: %v", err)
+	}
+	explicit.GetDefaults()
+	if explicit.Retries() != 0 {
+		t.Errorf("GetDefaults() replaced max_retries = 0 with %d", explicit.Retries())
+	}
+}
+
+func TestHook_RetryDelay(t *testing.T) {
+	constant := Hook{RetryInterval: 200 * time.Millisecond}
+	for attempt := 1; attempt <= 3; attempt++ {
+		if d := constant.RetryDelay(attempt); d != 200*time.Millisecond {
+			t.Errorf("constant RetryDelay(%d) = %v, want 200ms", attempt, d)
+		}
+	}
+
+	backoff := Hook{RetryInterval: 100 * time.Millisecond, RetryBackoff: 2}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}
+	for i, w := range want {
+		if d := backoff.RetryDelay(i + 1); d != w {
+			t.Errorf("backoff RetryDelay(%d) = %v, want %v", i+1, d, w)
+		}
+	}
+	if d := backoff.RetryDelay(50); d != 5*time.Minute {
+		t.Errorf("RetryDelay should cap at 5m, got %v", d)
+	}
 }
 
 func TestLifecycleHooks_Validate(t *testing.T) {
@@ -394,14 +441,18 @@ func TestLifecycleHooks_DeepCopy(t *testing.T) {
 }
 
 func TestHook_DeepCopy(t *testing.T) {
+	retries := 3
 	original := Hook{
-		Name:        "test",
-		Command:     "echo test",
-		WorkDir:     "/app",
-		Env:         []string{"KEY1=value1", "KEY2=value2"},
-		Timeout:     30 * time.Second,
-		FailureMode: FailureModeFail,
-		RunMode:     RunModeBlocking,
+		Name:          "test",
+		Command:       "echo test",
+		WorkDir:       "/app",
+		Env:           []string{"KEY1=value1", "KEY2=value2"},
+		Timeout:       30 * time.Second,
+		FailureMode:   FailureModeFail,
+		RunMode:       RunModeBlocking,
+		MaxRetries:    &retries,
+		RetryInterval: 2 * time.Second,
+		RetryBackoff:  1.5,
 	}
 
 	copied := original.DeepCopy()
@@ -434,6 +485,13 @@ func TestHook_DeepCopy(t *testing.T) {
 	if copied.RunMode != original.RunMode {
 		t.Error("DeepCopy() did not copy RunMode correctly")
 	}
+	*copied.MaxRetries = 5
+	if *original.MaxRetries != 3 {
+		t.Error("DeepCopy() did not create independent copy of MaxRetries")
+	}
+	if copied.Retries() != 5 || copied.RetryInterval != original.RetryInterval || copied.RetryBackoff != original.RetryBackoff {
+		t.Error("DeepCopy() did not copy retry settings correctly")
+	}
 }
 
 func TestLifecyclePhase_String(t *testing.T) {
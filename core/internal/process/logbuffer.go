@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"sync"
+	"time"
 )
 
 // defaultLogBufferCapacity bounds memory use per process: only the most
@@ -13,9 +14,10 @@ const defaultLogBufferCapacity = 500
 // LogLine is a single captured line of stdout/stderr output, exposed to
 // the live-tail polling API.
 type LogLine struct {
-	Offset uint64 `json:"offset"`
-	Stream string `json:"stream"` // "stdout" or "stderr"
-	Text   string `json:"text"`
+	Offset uint64    `json:"offset"`
+	Stream string    `json:"stream"` // "stdout", "stderr", or "hook" for lifecycle hook output
+	Text   string    `json:"text"`
+	Time   time.Time `json:"time"` // when the line was captured
 }
 
 // logRingBuffer is a fixed-capacity, thread-safe ring buffer of captured
@@ -38,7 +40,7 @@ func newLogRingBuffer(capacity int) *logRingBuffer {
 func (b *logRingBuffer) append(stream, text string) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	b.lines = append(b.lines, LogLine{Offset: b.nextOff, Stream: stream, Text: text})
+	b.lines = append(b.lines, LogLine{Offset: b.nextOff, Stream: stream, Text: text, Time: time.Now()})
 	b.nextOff++
 	if len(b.lines) > b.capacity {
 		b.lines = b.lines[len(b.lines)-b.capacity:]
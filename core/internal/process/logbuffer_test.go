@@ -24,6 +24,9 @@ func TestLogRingBuffer_SinceAndEviction(t *testing.T) {
 	if next != 5 {
 		t.Fatalf("expected next offset 5, got %d", next)
 	}
+	if all[0].Time.IsZero() || all[2].Time.Before(all[0].Time) {
+		t.Fatalf("expected capture times in order, got %v and %v", all[0].Time, all[2].Time)
+	}
 
 	// since an offset older than everything buffered still returns
 	// everything currently available, not an error.
@@ -0,0 +1,116 @@
+package process
+
+import (
+	"os"
+	"strings"
+
+	gopsproc "github.com/shirou/gopsutil/v4/process"
+)
+
+// ManagedEnvVar is set by ConfigureCmd in the environment of every process
+// provisr starts, to "<daemon>/<name>": the daemon ID (see SetDaemonID) and
+// the process name. It outlives the daemon, so a daemon restarted after a
+// crash can find the children of its predecessor.
+const ManagedEnvVar = "PROVISR_MANAGED"
+
+// managedMarker is the ManagedEnvVar value of process name started by the
+// daemon with ID daemonID.
+func managedMarker(daemonID, name string) string { return daemonID + "/" + name }
+
+// Orphan is a running process started by an earlier provisr daemon.
+type Orphan struct {
+	Name string // process name from ManagedEnvVar
+	PID  int
+	Meta PIDMeta // identity, for DetectAlive to catch PID reuse
+}
+
+// FindOrphans returns the running processes marked with ManagedEnvVar by a
+// daemon with ID daemonID, by name. A process tree such as a shell and the
+// program it runs inherits the marker, so only its topmost process is
+// returned; when several trees share a name, the oldest wins. A tree is
+// only an orphan once its daemon is gone: its parent must be init (PID 1)
+// or a process running another executable than this one, such as a
+// subreaper, and not the current process. Processes whose environment
+// cannot be read (other users' processes, or platforms gopsutil cannot read
+// it on) are ignored.
+func FindOrphans(daemonID string) map[string]Orphan {
+	procs, err := gopsproc.Processes()
+	if err != nil {
+		return nil
+	}
+	self := int32(os.Getpid())
+
+	type candidate struct {
+		Orphan
+		ppid int32
+	}
+	byPID := make(map[int32]candidate)
+	for _, p := range procs {
+		if p.Pid == self {
+			continue
+		}
+		env, err := p.Environ()
+		if err != nil {
+			continue
+		}
+		id, name, ok := markerOf(env)
+		if !ok || id != daemonID {
+			continue
+		}
+		ppid, err := p.Ppid()
+		if err != nil || ppid == self {
+			continue
+		}
+		pid := int(p.Pid)
+		byPID[p.Pid] = candidate{
+			Orphan: Orphan{Name: name, PID: pid, Meta: PIDMeta{StartUnix: getProcStartUnix(pid), Command: getProcCmdline(pid)}},
+			ppid:   ppid,
+		}
+	}
+
+	selfExe := executable(self)
+	orphans := make(map[string]Orphan)
+	for _, c := range byPID {
+		if parent, ok := byPID[c.ppid]; ok && parent.Name == c.Name {
+			continue // inside a marked tree of the same name
+		}
+		if c.ppid != 1 {
+			// A parent running provisr is a live daemon of the same ID,
+			// e.g. a second one started on the same config; its processes
+			// are not ours to take. An unreadable parent counts as one.
+			if exe := executable(c.ppid); exe == "" || exe == selfExe {
+				continue
+			}
+		}
+		if prev, ok := orphans[c.Name]; ok && prev.Meta.StartUnix <= c.Meta.StartUnix {
+			continue
+		}
+		orphans[c.Name] = c.Orphan
+	}
+	return orphans
+}
+
+// markerOf returns the daemon ID and process name of the ManagedEnvVar
+// value in env.
+func markerOf(env []string) (daemonID, name string, ok bool) {
+	for _, kv := range env {
+		if v, found := strings.CutPrefix(kv, ManagedEnvVar+"="); found {
+			return strings.Cut(v, "/")
+		}
+	}
+	return "", "", false
+}
+
+// executable returns the path of the program pid runs, or "" when it
+// cannot be read.
+func executable(pid int32) string {
+	p, err := gopsproc.NewProcess(pid)
+	if err != nil {
+		return ""
+	}
+	exe, err := p.Exe()
+	if err != nil {
+		return ""
+	}
+	return exe
+}
@@ -0,0 +1,78 @@
+//go:build linux
+
+package process
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestFindOrphansSkipsLiveDaemon runs this test binary as a stand-in daemon
+// that starts a marked process and keeps running: its process is not an
+// orphan even to a daemon of the same ID.
+func TestFindOrphansSkipsLiveDaemon(t *testing.T) {
+	if os.Getenv("PROVISR_TEST_DAEMON") == "1" {
+		cmd := exec.Command("sleep", "30")
+		cmd.Env = append(os.Environ(), ManagedEnvVar+"="+managedMarker("live", "held"))
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println(cmd.Process.Pid)
+		_ = cmd.Wait()
+		return
+	}
+
+	daemon := exec.Command(os.Args[0], "-test.run=^TestFindOrphansSkipsLiveDaemon$")
+	daemon.Env = append(os.Environ(), "PROVISR_TEST_DAEMON=1")
+	out, err := daemon.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := daemon.Start(); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading child PID: %v", err)
+	}
+	child, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = syscall.Kill(child, syscall.SIGKILL)
+		_ = daemon.Process.Kill()
+		_ = daemon.Wait()
+	})
+
+	if o, ok := FindOrphans("live")["held"]; ok {
+		t.Fatalf("adopted %d from a running daemon", o.PID)
+	}
+
+	// Once the daemon is gone, the process is reparented and adoptable.
+	_ = daemon.Process.Kill()
+	_ = daemon.Wait()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if o, ok := FindOrphans("live")["held"]; ok {
+			if o.PID != child {
+				t.Fatalf("orphan PID %d, want %d", o.PID, child)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("orphan of a dead daemon was not found")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, ok := FindOrphans("other")["held"]; ok {
+		t.Fatal("orphan found under another daemon ID")
+	}
+}
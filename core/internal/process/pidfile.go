@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -12,7 +13,55 @@ import (
 // PIDMeta holds additional identity information for a PID to avoid PID reuse issues.
 // StartUnix is the process start time in Unix seconds (UTC/local agnostic for equality checks).
 type PIDMeta struct {
-	StartUnix int64 `json:"start_unix"`
+	StartUnix int64  `json:"start_unix"`
+	Command   string `json:"command,omitempty"` // argv the process was started with, space-joined
+}
+
+// shellNames are programs that commonly exec into another program, so a
+// recorded shell command line can't be compared against the live one.
+var shellNames = map[string]bool{"sh": true, "bash": true, "dash": true, "zsh": true, "ash": true, "ksh": true, "cmd": true, "cmd.exe": true, "powershell": true, "powershell.exe": true}
+
+// identityMatches reports whether the live process pid is still the one
+// described by meta. When both start times are known they decide on their
+// own: a program can legitimately run under an interpreter or rewrite its
+// process title, so its command line is no proof of reuse. Only when the
+// start time can't be compared is the command line consulted, and then any
+// live argv element naming the recorded program counts as a match. Checks the
+// platform can't perform are skipped rather than failed.
+func identityMatches(pid int, meta PIDMeta) bool {
+	if meta.StartUnix > 0 {
+		if cur := getProcStartUnix(pid); cur > 0 {
+			return cur == meta.StartUnix
+		}
+	}
+	if meta.Command != "" {
+		if cur := getProcCmdline(pid); cur != "" && !sameProgram(meta.Command, cur) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameProgram reports whether the live command line current could belong to
+// a process started as recorded: the recorded argv[0] base name must appear
+// as the base name of some live argv element, which covers shebang scripts
+// run through their interpreter. Recorded shell commands always match since
+// shells commonly exec into another program.
+func sameProgram(recorded, current string) bool {
+	recFields, curFields := strings.Fields(recorded), strings.Fields(current)
+	if len(recFields) == 0 || len(curFields) == 0 {
+		return true
+	}
+	rec := filepath.Base(recFields[0])
+	if shellNames[rec] {
+		return true
+	}
+	for _, f := range curFields {
+		if filepath.Base(f) == rec {
+			return true
+		}
+	}
+	return false
 }
 
 // ReadPIDFile reads the canonical three-line PID file written by Process:
@@ -65,10 +114,10 @@ func ReadPIDFile(path string) (int, *Spec, *PIDMeta, error) {
 //   - (0,    spec, nil) — file absent, invalid content, or PID identity mismatch (not an error for callers)
 //   - (0,    nil,  err) — OS-level I/O error (permissions, disk fault)
 //
-// Identity check: when meta.StartUnix > 0 and getProcStartUnix returns a
-// non-zero value that differs from meta.StartUnix, the PID is considered reused
-// and pid=0 is returned.  When getProcStartUnix returns 0 (platform limitation
-// or early boot), the check is skipped and the raw PID is returned as-is.
+// Identity check: when the live process's start time differs from
+// meta.StartUnix (or, if the start time can't be read, its command line
+// doesn't name the program in meta.Command), the PID is considered reused and
+// pid=0 is returned (see identityMatches).
 func VerifyPIDFile(path string) (int, *Spec, error) {
 	rawPID, spec, meta, err := ReadPIDFile(path)
 	if err != nil {
@@ -87,12 +136,8 @@ func VerifyPIDFile(path string) (int, *Spec, error) {
 	if rawPID <= 0 {
 		return 0, spec, nil
 	}
-	if meta != nil && meta.StartUnix > 0 {
-		cur := getProcStartUnix(rawPID)
-		if cur > 0 && cur != meta.StartUnix {
-			return 0, spec, nil // PID reused
-		}
-		// cur==0 → platform can't determine start time; skip check and trust the PID.
+	if meta != nil && !identityMatches(rawPID, *meta) {
+		return 0, spec, nil // PID reused
 	}
 	return rawPID, spec, nil
 }
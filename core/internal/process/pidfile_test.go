@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -209,3 +210,80 @@ func TestVerifyPIDFile_PIDReuse(t *testing.T) {
 		t.Errorf("expected pid=0 when start_unix mismatch (PID reuse), got %d", pid)
 	}
 }
+
+// TestVerifyPIDFile_StartTimeDecides verifies that a matching start time
+// keeps the PID even when the live command line no longer names the recorded
+// program, as happens with interpreters and rewritten process titles.
+func TestVerifyPIDFile_StartTimeDecides(t *testing.T) {
+	requireUnix(t)
+	dir := t.TempDir()
+	pidfile := filepath.Join(dir, "retitled.pid")
+
+	livePID := os.Getpid()
+	start := getProcStartUnix(livePID)
+	if start <= 0 {
+		t.Skip("start time not readable on this platform")
+	}
+	meta, _ := json.Marshal(PIDMeta{StartUnix: start, Command: "/usr/bin/definitely-not-this-binary --flag"})
+	content := fmt.Sprintf("%d\n{}\n%s\n", livePID, meta)
+	if err := os.WriteFile(pidfile, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	pid, _, err := VerifyPIDFile(pidfile)
+	if err != nil {
+		t.Fatalf("VerifyPIDFile: %v", err)
+	}
+	if pid != livePID {
+		t.Errorf("expected pid=%d when start time matches, got %d", livePID, pid)
+	}
+}
+
+func TestSameProgram(t *testing.T) {
+	cases := []struct {
+		recorded, current string
+		want              bool
+	}{
+		{"./run.sh --port 80", "/bin/bash ./run.sh --port 80", true},
+		{"/usr/sbin/nginx -g daemon", "nginx: master process /usr/sbin/nginx -g daemon", true},
+		{"sh -c 'exec my-server'", "my-server", true},
+		{"my-server --port 80", "sleep 5", false},
+	}
+	for _, c := range cases {
+		if got := sameProgram(c.recorded, c.current); got != c.want {
+			t.Errorf("sameProgram(%q, %q) = %v, want %v", c.recorded, c.current, got, c.want)
+		}
+	}
+}
+
+// TestDetectAlive_RecoveredPIDReused simulates a recovered PID that has been
+// reused by an unrelated process: the PID is alive but its identity differs
+// from the PID file metadata, so the process must be reported as stopped.
+func TestDetectAlive_RecoveredPIDReused(t *testing.T) {
+	requireUnix(t)
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start sleep: %v", err)
+	}
+	t.Cleanup(func() { _ = cmd.Process.Kill(); _ = cmd.Wait() })
+	pid := cmd.Process.Pid
+
+	r := New(Spec{Name: "reused", Command: "sleep 5"})
+	r.SeedPID(pid)
+	r.SetPIDMeta(&PIDMeta{StartUnix: getProcStartUnix(pid), Command: "sleep 5"})
+	if alive, src := r.DetectAlive(); !alive {
+		t.Fatalf("expected matching identity to be alive, got %s", src)
+	}
+
+	r.SetPIDMeta(&PIDMeta{StartUnix: 1, Command: "sleep 5"})
+	if alive, src := r.DetectAlive(); alive || src != "pid-reused" {
+		t.Fatalf("expected pid-reused on start time mismatch, got alive=%v src=%s", alive, src)
+	}
+
+	if getProcCmdline(pid) != "" {
+		r.SetPIDMeta(&PIDMeta{Command: "my-server --port 80"})
+		if alive, src := r.DetectAlive(); alive || src != "pid-reused" {
+			t.Fatalf("expected pid-reused on command mismatch, got alive=%v src=%s", alive, src)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// PortInUseError is returned when ports listed in Spec.Ports are already
+// listening before the process starts.
+type PortInUseError struct {
+	Ports []int
+}
+
+func (e *PortInUseError) Error() string {
+	if len(e.Ports) == 1 {
+		return fmt.Sprintf("port %d already in use", e.Ports[0])
+	}
+	ports := make([]string, len(e.Ports))
+	for i, p := range e.Ports {
+		ports[i] = strconv.Itoa(p)
+	}
+	return fmt.Sprintf("ports %s already in use", strings.Join(ports, ", "))
+}
+
+// Port check modes for Spec.PortCheck.
+const (
+	PortCheckError = "error" // refuse to start (the default)
+	PortCheckWarn  = "warn"  // log a warning and start anyway
+)
+
+// portDialTimeout bounds the loopback connect used when a port cannot be
+// bound for a reason other than being in use.
+const portDialTimeout = 200 * time.Millisecond
+
+// PortsInUse returns the TCP ports of ports that something is already
+// listening on. A port counts as free when it can be bound on all
+// interfaces; when binding fails for another reason, such as a privileged
+// port, it is in use only if a loopback connection to it succeeds.
+func PortsInUse(ports []int) []int {
+	var busy []int
+	for _, port := range ports {
+		if portInUse(port) {
+			busy = append(busy, port)
+		}
+	}
+	return busy
+}
+
+func portInUse(port int) bool {
+	addr := ":" + strconv.Itoa(port)
+	ln, err := net.Listen("tcp", addr)
+	if err == nil {
+		_ = ln.Close()
+		return false
+	}
+	if errors.Is(err, syscall.EADDRINUSE) {
+		return true
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), portDialTimeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
@@ -0,0 +1,37 @@
+package process
+
+import (
+	"net"
+	"testing"
+)
+
+func TestPortsInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	busy := ln.Addr().(*net.TCPAddr).Port
+
+	// A port that was free a moment ago.
+	probe, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	free := probe.Addr().(*net.TCPAddr).Port
+	_ = probe.Close()
+
+	if got := PortsInUse([]int{free, busy}); len(got) != 1 || got[0] != busy {
+		t.Fatalf("PortsInUse = %v, want [%d]", got, busy)
+	}
+	_ = ln.Close()
+	if got := PortsInUse([]int{busy}); len(got) != 0 {
+		t.Fatalf("closed port still reported in use: %v", got)
+	}
+
+	if msg := (&PortInUseError{Ports: []int{80}}).Error(); msg != "port 80 already in use" {
+		t.Errorf("single port message: %q", msg)
+	}
+	if msg := (&PortInUseError{Ports: []int{80, 443}}).Error(); msg != "ports 80, 443 already in use" {
+		t.Errorf("multiple ports message: %q", msg)
+	}
+}
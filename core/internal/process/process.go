@@ -3,12 +3,14 @@ package process
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -24,11 +26,14 @@ type Process struct {
 	stopping   bool // true when Stop has been requested; suppress autorestart
 	outCloser  io.WriteCloser
 	errCloser  io.WriteCloser
-	pid        int    // Process ID for safe detection
-	generation uint64 // incremented on each TryStart; guards stale cmd.Wait() goroutines
-	exited     bool   // Track if process has exited
-	exitErr    error  // Exit error if any
+	pid        int      // Process ID for safe detection
+	generation uint64   // incremented on each TryStart; guards stale cmd.Wait() goroutines
+	exited     bool     // Track if process has exited
+	exitErr    error    // Exit error if any
+	pidMeta    *PIDMeta // identity of a recovered (not spawned by us) PID
+	startTicks int64    // start time of pid in clock ticks (Linux only; 0 when unknown)
 	logs       *logRingBuffer
+	daemonID   string // identifies the starting daemon in ManagedEnvVar
 }
 
 func New(spec Spec) *Process {
@@ -42,6 +47,12 @@ func (r *Process) LogsSince(since uint64, limit int) ([]LogLine, uint64) {
 	return r.logs.since(since, limit)
 }
 
+// AppendLog adds a line to the live-tail buffer on behalf of something other
+// than the child itself, such as a lifecycle hook.
+func (r *Process) AppendLog(stream, text string) {
+	r.logs.append(stream, text)
+}
+
 // UpdateSpec replaces the internal spec under lock.
 func (r *Process) UpdateSpec(s Spec) {
 	r.mu.Lock()
@@ -56,14 +67,27 @@ func (r *Process) ConfigureCmd(mergedEnv []string) *exec.Cmd {
 	r.mu.Lock()
 	spec := r.spec // Create a copy to avoid holding lock during I/O operations
 	r.mu.Unlock()
+	return r.ConfigureCmdFor(spec, mergedEnv)
+}
+
+// ConfigureCmdFor is ConfigureCmd building the command of spec rather than
+// the process's own, for a start whose spec was adjusted just for that run.
+func (r *Process) ConfigureCmdFor(spec Spec, mergedEnv []string) *exec.Cmd {
+	r.mu.Lock()
+	daemonID := r.daemonID
+	r.mu.Unlock()
 
 	cmd := spec.BuildCommand()
+	applyUmask(cmd, spec)
 	if spec.WorkDir != "" {
 		cmd.Dir = spec.WorkDir
 	}
-	if len(mergedEnv) > 0 {
-		cmd.Env = mergedEnv
+	// Mark the child so a restarted daemon can adopt it; see FindOrphans
+	env := mergedEnv
+	if len(env) == 0 {
+		env = os.Environ()
 	}
+	cmd.Env = append(env[:len(env):len(env)], ManagedEnvVar+"="+managedMarker(daemonID, spec.Name))
 	// Configure platform-specific process attributes (detached, process group, etc.)
 	configureSysProcAttr(cmd, spec)
 
@@ -77,10 +101,8 @@ func (r *Process) ConfigureCmd(mergedEnv []string) *exec.Cmd {
 	if !spec.Detached {
 		var ow, ew io.WriteCloser
 		if spec.Log.File.Dir != "" || spec.Log.File.StdoutPath != "" || spec.Log.File.StderrPath != "" || spec.Log.File.StdoutWriter != nil || spec.Log.File.StderrWriter != nil {
-			if spec.Log.File.Dir != "" {
-				if err := os.MkdirAll(spec.Log.File.Dir, 0o750); err != nil {
-					slog.Warn("Failed to create log directory", "dir", spec.Log.File.Dir, "error", err)
-				}
+			if err := EnsureLogDir(spec); err != nil {
+				slog.Warn("Failed to create log directory", "dir", spec.Log.File.Dir, "error", err)
 			}
 			// Use unified config for both structured logging and file writers
 			outW, errW, _ := spec.Log.ProcessWriters(spec.Name)
@@ -116,6 +138,9 @@ func (r *Process) CopyCmd() *exec.Cmd {
 func (r *Process) SeedPID(pid int) {
 	r.mu.Lock()
 	if pid > 0 {
+		if pid != r.pid || r.startTicks == 0 {
+			r.startTicks = procStartTicks(pid)
+		}
 		r.pid = pid
 		r.status.PID = pid
 		if startUnix := getProcStartUnix(pid); startUnix > 0 {
@@ -127,6 +152,23 @@ func (r *Process) SeedPID(pid int) {
 	r.mu.Unlock()
 }
 
+// SetDaemonID sets the ID of the daemon starting the process, which marks
+// it in ManagedEnvVar so only a daemon with the same ID adopts it; see
+// FindOrphans.
+func (r *Process) SetDaemonID(id string) {
+	r.mu.Lock()
+	r.daemonID = id
+	r.mu.Unlock()
+}
+
+// SetPIDMeta records the identity of a PID recovered from a PID file so
+// DetectAlive can tell the original process from one that reused its PID.
+func (r *Process) SetPIDMeta(meta *PIDMeta) {
+	r.mu.Lock()
+	r.pidMeta = meta
+	r.mu.Unlock()
+}
+
 func (r *Process) SetStarted(cmd *exec.Cmd) uint64 {
 	r.mu.Lock()
 	r.generation++
@@ -140,6 +182,8 @@ func (r *Process) SetStarted(cmd *exec.Cmd) uint64 {
 
 	// Store PID for race-free detection
 	r.pid = cmd.Process.Pid
+	r.startTicks = procStartTicks(r.pid)
+	r.pidMeta = nil
 	r.exited = false
 	r.exitErr = nil
 	r.mu.Unlock()
@@ -150,9 +194,23 @@ func (r *Process) SetStarted(cmd *exec.Cmd) uint64 {
 // It encapsulates cmd.Start + SetStarted + WritePIDFile to reduce races.
 func (r *Process) TryStart(cmd *exec.Cmd) error {
 	// SysProcAttr must already be configured by ConfigureCmd; do not override here.
+	r.mu.Lock()
+	spec := r.spec
+	r.mu.Unlock()
 	if err := cmd.Start(); err != nil {
+		if errors.Is(err, syscall.EPERM) && (spec.User != "" || spec.Group != "" || len(spec.SupplementaryGroups) > 0) {
+			return fmt.Errorf("cannot run as user %q group %q: the daemon lacks permission to switch identity (run it as root or grant CAP_SETUID/CAP_SETGID): %w", spec.User, spec.Group, err)
+		}
 		return err
 	}
+	if spec.Nice != 0 || len(spec.CPUAffinity) > 0 {
+		if err := applySchedAttrs(cmd.Process.Pid, spec); err != nil {
+			// Don't leave a child running with the wrong scheduling attributes.
+			_ = signalGroup(cmd.Process.Pid, syscall.SIGKILL)
+			_ = cmd.Wait()
+			return err
+		}
+	}
 	// After successful start, record state and write PID file under lock-ordered ops.
 	gen := r.SetStarted(cmd)
 	// Write PID file synchronously to ensure availability immediately after Start returns.
@@ -282,7 +340,7 @@ func (r *Process) WritePIDFile() {
 		slog.Warn("Failed to encode PID file spec", "error", err)
 		return
 	}
-	metaJSON, err := json.Marshal(PIDMeta{StartUnix: startUnix})
+	metaJSON, err := json.Marshal(PIDMeta{StartUnix: startUnix, Command: strings.Join(specCopy.BuildCommand().Args, " ")})
 	if err != nil {
 		slog.Warn("Failed to encode PID file metadata", "error", err)
 		return
@@ -307,11 +365,14 @@ func (r *Process) RemovePIDFile() {
 	}
 }
 
-// Snapshot returns a copy of the current status.
+// Snapshot returns a copy of the current status with Uptime computed.
 func (r *Process) Snapshot() Status {
 	r.mu.Lock()
 	s := r.status
 	r.mu.Unlock()
+	if !s.StartedAt.IsZero() && s.StoppedAt.Before(s.StartedAt) {
+		s.Uptime = time.Since(s.StartedAt)
+	}
 	return s
 }
 
@@ -332,6 +393,14 @@ func (r *Process) GetSpec() *Spec {
 	return s
 }
 
+// GetInlineConfig reports Spec.InlineConfig without copying the spec.
+func (r *Process) GetInlineConfig() bool {
+	r.mu.Lock()
+	v := r.spec.InlineConfig
+	r.mu.Unlock()
+	return v
+}
+
 func (r *Process) GetAutoStart() bool {
 	r.mu.Lock()
 	v := r.spec.AutoRestart
@@ -345,6 +414,9 @@ func (r *Process) DetectAlive() (bool, string) {
 	pid := r.pid
 	exited := r.exited
 	spec := r.spec
+	meta := r.pidMeta
+	startTicks := r.startTicks
+	owned := r.cmd != nil
 	r.mu.Unlock()
 
 	// If we already detected exit, process is dead
@@ -352,10 +424,19 @@ func (r *Process) DetectAlive() (bool, string) {
 		return false, "exit-detected"
 	}
 
-	// If we have a PID, prefer checking it directly first
+	// If we have a PID, prefer checking it directly first. On Linux the
+	// probe reads /proc, so a zombie counts as gone and a start time other
+	// than the one recorded means the PID was reused; elsewhere it is
+	// signal 0. A recovered PID must also match its PID file identity.
 	if pid > 0 {
-		if killProcess(pid, 0) == nil {
+		switch probePID(pid, startTicks) {
+		case pidAlive:
+			if !owned && meta != nil && !identityMatches(pid, *meta) {
+				return false, "pid-reused"
+			}
 			return true, "exec:pid"
+		case pidReused:
+			return false, "pid-reused"
 		}
 	}
 
@@ -365,8 +446,12 @@ func (r *Process) DetectAlive() (bool, string) {
 		if ok {
 			// Best-effort: if a PID file is configured, read it and seed internal PID for later signaling
 			if spec.PIDFile != "" {
-				if n, _, _, err := ReadPIDFile(spec.PIDFile); err == nil {
+				if n, _, m, err := ReadPIDFile(spec.PIDFile); err == nil {
+					if !identityMatches(n, *m) {
+						continue
+					}
 					r.SeedPID(n)
+					r.SetPIDMeta(m)
 				}
 			}
 			return true, d.Describe()
@@ -419,7 +504,9 @@ func (r *Process) EnforceStartDuration(d time.Duration) error {
 }
 
 // StopWithSignal sends the provided signal to the process group. It does not wait.
-// If sending the signal fails, it falls back to Kill().
+// If sending the signal fails, it falls back to Kill(). On Windows the signal
+// is mapped by signalGroup (SIGINT/SIGTERM → CTRL_BREAK_EVENT, SIGKILL → tree
+// termination).
 func (r *Process) StopWithSignal(sig syscall.Signal) error {
 	alive, _ := r.DetectAlive()
 	if !alive {
@@ -428,7 +515,7 @@ func (r *Process) StopWithSignal(sig syscall.Signal) error {
 	cmd := r.CopyCmd()
 	if cmd != nil && cmd.Process != nil {
 		pid := cmd.Process.Pid
-		if err := killProcess(-pid, sig); err != nil {
+		if err := signalGroup(pid, sig); err != nil {
 			slog.Warn("Failed to send signal to process group, falling back to SIGKILL",
 				"pid", pid, "signal", sig, "error", err)
 			// Fall back to SIGKILL best-effort; upper layers manage further retries
@@ -441,11 +528,11 @@ func (r *Process) StopWithSignal(sig syscall.Signal) error {
 	pid := r.pid
 	r.mu.Unlock()
 	if pid > 0 {
-		if err := killProcess(-pid, sig); err != nil {
+		if err := signalGroup(pid, sig); err != nil {
 			slog.Warn("Failed to send signal to stored PID, falling back to SIGKILL",
 				"pid", pid, "signal", sig, "error", err)
 			// Fall back to SIGKILL on the same PID
-			if killErr := killProcess(-pid, syscall.SIGKILL); killErr != nil {
+			if killErr := signalGroup(pid, syscall.SIGKILL); killErr != nil {
 				slog.Warn("Failed to kill process with SIGKILL fallback", "pid", pid, "error", killErr)
 			}
 		}
@@ -453,14 +540,39 @@ func (r *Process) StopWithSignal(sig syscall.Signal) error {
 	return nil
 }
 
-// Kill sends SIGKILL to the process group and attempts to reap promptly.
+// Signal sends sig to the process itself without stopping it, e.g. SIGHUP to
+// reload its configuration. Unlike StopWithSignal there is no fallback to
+// Kill; an error wrapping ErrNotRunning is returned when it is not alive.
+func (r *Process) Signal(sig syscall.Signal) error {
+	if alive, _ := r.DetectAlive(); !alive {
+		return fmt.Errorf("process %s: %w", r.GetName(), ErrNotRunning)
+	}
+	pid := 0
+	if cmd := r.CopyCmd(); cmd != nil && cmd.Process != nil {
+		pid = cmd.Process.Pid
+	} else {
+		r.mu.Lock()
+		pid = r.pid
+		r.mu.Unlock()
+	}
+	if pid <= 0 {
+		return fmt.Errorf("process %s: %w", r.GetName(), ErrNotRunning)
+	}
+	if err := signalProcess(pid, sig); err != nil {
+		return fmt.Errorf("failed to send %s to process %s (pid %d): %w", sig, r.GetName(), pid, err)
+	}
+	return nil
+}
+
+// Kill sends SIGKILL to the process group (the whole process tree on Windows)
+// and attempts to reap promptly.
 func (r *Process) Kill() error {
 	cmd := r.CopyCmd()
 	if cmd == nil || cmd.Process == nil {
 		return nil
 	}
 	pid := cmd.Process.Pid
-	if err := killProcess(-pid, syscall.SIGKILL); err != nil {
+	if err := signalGroup(pid, syscall.SIGKILL); err != nil {
 		if errors.Is(err, syscall.ESRCH) {
 			return nil // process already dead — goal achieved
 		}
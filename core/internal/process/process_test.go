@@ -1,6 +1,7 @@
 package process
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -73,7 +74,8 @@ func TestConfigureCmdAppliesEnvWorkdirLogging(t *testing.T) {
 	if cmd.Dir != work {
 		t.Fatalf("workdir not applied: got %q want %q", cmd.Dir, work)
 	}
-	if len(cmd.Env) != len(mergedEnv) || cmd.Env[0] != "FOO=bar" {
+	// The merged env plus the marker that lets a restarted daemon adopt it
+	if len(cmd.Env) != 2 || cmd.Env[0] != "FOO=bar" || cmd.Env[1] != ManagedEnvVar+"=/cfg" {
 		t.Fatalf("env not applied: got %#v", cmd.Env)
 	}
 	// Check SysProcAttr (platform-specific)
@@ -108,6 +110,33 @@ func TestConfigureCmdAppliesEnvWorkdirLogging(t *testing.T) {
 	}
 }
 
+func TestChildOutputReachesLogFilePromptly(t *testing.T) {
+	requireUnix(t)
+	logs := t.TempDir()
+	for _, interval := range []time.Duration{0, 100 * time.Millisecond} {
+		name := fmt.Sprintf("tail-%d", interval.Milliseconds())
+		r := New(Spec{
+			Name:    name,
+			Command: "sh -c 'echo ready; sleep 5'",
+			Log:     logger.Config{File: logger.FileConfig{Dir: logs, FlushInterval: interval}},
+		})
+		if err := r.TryStart(r.ConfigureCmd(nil)); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		path := filepath.Join(logs, name+".stdout.log")
+		// The child is still running, so only a prompt write or a timed
+		// flush can have put the line there.
+		ok := waitUntil(time.Second, 10*time.Millisecond, func() bool {
+			b, _ := os.ReadFile(path)
+			return string(b) == "ready\n"
+		})
+		_ = r.Kill()
+		if !ok {
+			t.Fatalf("flush_interval=%s: line not in %s within 1s", interval, path)
+		}
+	}
+}
+
 // IsBeforeStartErr reports whether the error indicates the process exited before start duration elapsed.
 func IsBeforeStartErr(err error) bool {
 	if err == nil {
@@ -3,8 +3,11 @@
 package process
 
 import (
+	"fmt"
 	"os/exec"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func checkSysProcAttrs(t *testing.T, cmd *exec.Cmd) {
@@ -13,3 +16,60 @@ func checkSysProcAttrs(t *testing.T, cmd *exec.Cmd) {
 		t.Fatalf("SysProcAttr Setpgid not set")
 	}
 }
+
+func TestSignalGroupReachesGrandchildren(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "sleep 30 & echo $!; wait")
+	configureSysProcAttr(cmd, Spec{})
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	var grandchild int
+	if _, err := fmt.Fscan(out, &grandchild); err != nil {
+		t.Fatalf("read grandchild pid: %v", err)
+	}
+	if err := signalGroup(cmd.Process.Pid, syscall.SIGTERM); err != nil {
+		t.Fatalf("signalGroup: %v", err)
+	}
+	_ = cmd.Wait()
+	if !waitUntil(3*time.Second, 20*time.Millisecond, func() bool { return !processExists(grandchild) }) {
+		_ = syscall.Kill(grandchild, syscall.SIGKILL)
+		t.Fatalf("grandchild %d survived group signal", grandchild)
+	}
+}
+
+func TestExitDetails(t *testing.T) {
+	if code, sig := ExitDetails(nil); code != 0 || sig != "" {
+		t.Fatalf("nil error: got code=%d sig=%q", code, sig)
+	}
+	err := exec.Command("sh", "-c", "exit 7").Run()
+	if code, sig := ExitDetails(err); code != 7 || sig != "" {
+		t.Fatalf("exit 7: got code=%d sig=%q", code, sig)
+	}
+	err = exec.Command("sh", "-c", "kill -TERM $$").Run()
+	if code, sig := ExitDetails(err); code != -1 || sig != syscall.SIGTERM.String() {
+		t.Fatalf("SIGTERM: got code=%d sig=%q", code, sig)
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	for in, want := range map[string]syscall.Signal{
+		"HUP":     syscall.SIGHUP,
+		"sigusr1": syscall.SIGUSR1,
+		" TERM ":  syscall.SIGTERM,
+		"9":       syscall.SIGKILL,
+	} {
+		got, err := ParseSignal(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSignal(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "NOPE", "0", "-1", "999"} {
+		if _, err := ParseSignal(in); err == nil {
+			t.Errorf("ParseSignal(%q) should fail", in)
+		}
+	}
+}
@@ -4,9 +4,33 @@ package process
 
 import (
 	"os/exec"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func checkSysProcAttrs(t *testing.T, cmd *exec.Cmd) {
 	t.Helper()
+	if cmd.SysProcAttr == nil || cmd.SysProcAttr.CreationFlags&CREATE_NEW_PROCESS_GROUP == 0 {
+		t.Fatalf("SysProcAttr CREATE_NEW_PROCESS_GROUP not set")
+	}
+}
+
+func TestSignalGroupKillTerminatesTree(t *testing.T) {
+	cmd := exec.Command("cmd", "/c", "ping -n 30 127.0.0.1 >NUL")
+	configureSysProcAttr(cmd, Spec{})
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	pid := cmd.Process.Pid
+	if err := signalGroup(pid, syscall.SIGKILL); err != nil {
+		t.Fatalf("signalGroup SIGKILL: %v", err)
+	}
+	done := make(chan struct{})
+	go func() { _ = cmd.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("process still running after SIGKILL")
+	}
 }
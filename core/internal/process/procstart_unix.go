@@ -100,3 +100,27 @@ func getProcStartUnixLinux(pid int) int64 {
 
 	return btime + (startTicks / int64(clk))
 }
+
+// getProcCmdline returns the process's command line with arguments joined by
+// spaces, or "" when it can't be read.
+func getProcCmdline(pid int) string {
+	if pid <= 0 || pid > 4194304 {
+		return ""
+	}
+	if runtime.GOOS == "linux" {
+		b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline")
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(strings.ReplaceAll(string(b), "\x00", " "))
+	}
+	p, err := gopsproc.NewProcess(int32(pid))
+	if err != nil {
+		return ""
+	}
+	cmdline, err := p.Cmdline()
+	if err != nil {
+		return ""
+	}
+	return cmdline
+}
@@ -38,3 +38,7 @@ func getProcStartUnix(pid int) int64 {
 	const epochDiff = 11644473600
 	return secs - epochDiff
 }
+
+// getProcCmdline is not implemented on Windows; identity checks fall back to
+// the process start time alone.
+func getProcCmdline(int) string { return "" }
@@ -0,0 +1,189 @@
+//go:build linux
+
+package process
+
+import (
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Every process provisr starts is reaped by the goroutine blocked in its
+// cmd.Wait. Processes it never started can still become its children: when
+// it runs as PID 1 in a container, or as a child subreaper, the orphaned
+// (e.g. double-forked) descendants of managed processes are reparented to
+// it, and nobody waits for them when they exit. The orphan reaper collects
+// those zombies. It only reaps a zombie that has stayed unreaped for
+// orphanReapGrace, so it never takes an exit status from a cmd.Wait that is
+// about to collect one of provisr's own children.
+const orphanReapGrace = time.Second
+
+var reaperOnce sync.Once
+
+// StartOrphanReaper starts the background orphan reaper. It is safe to call
+// more than once; only the first call starts it.
+func StartOrphanReaper() {
+	reaperOnce.Do(func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGCHLD)
+		go reapOrphans(sigs)
+	})
+}
+
+// SetChildSubreaper makes the current process a child subreaper, so orphaned
+// descendants of the processes it starts are reparented to it rather than to
+// init, and reaped by the orphan reaper.
+func SetChildSubreaper() error {
+	if err := unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 1, 0, 0, 0); err != nil {
+		return err
+	}
+	StartOrphanReaper()
+	return nil
+}
+
+// zombieKey identifies a zombie across scans; the start time guards
+// against a reused PID.
+type zombieKey struct {
+	pid   int
+	start int64
+}
+
+func reapOrphans(sigs <-chan os.Signal) {
+	pending := make(map[zombieKey]time.Time)
+	// The first scan picks up zombies adopted before the reaper started.
+	timer := time.NewTimer(0)
+	for {
+		select {
+		case <-sigs:
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		next := make(map[zombieKey]time.Time)
+		var wait time.Duration
+		for _, key := range zombieChildren() {
+			seen, ok := pending[key]
+			if !ok {
+				seen = now
+			}
+			if left := orphanReapGrace - now.Sub(seen); left > 0 {
+				next[key] = seen
+				if wait == 0 || left < wait {
+					wait = left
+				}
+				continue
+			}
+			var ws unix.WaitStatus
+			_, _ = unix.Wait4(key.pid, &ws, unix.WNOHANG, nil)
+		}
+		pending = next
+		if wait > 0 {
+			timer.Reset(wait)
+		}
+	}
+}
+
+// zombieChildren lists the children of this process that have exited but
+// not been waited for.
+func zombieChildren() []zombieKey {
+	var zombies []zombieKey
+	for _, pid := range childPIDs() {
+		if state, start, ok := procState(pid); ok && state == 'Z' {
+			zombies = append(zombies, zombieKey{pid: pid, start: start})
+		}
+	}
+	return zombies
+}
+
+// childPIDs lists the children of this process from
+// /proc/self/task/*/children, falling back to a scan of /proc for kernels
+// built without it.
+func childPIDs() []int {
+	if pids, ok := taskChildren(); ok {
+		return pids
+	}
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	self := os.Getpid()
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if ppid, ok := procParent(pid); ok && ppid == self {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+func taskChildren() ([]int, bool) {
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return nil, false
+	}
+	var pids []int
+	for _, task := range tasks {
+		b, err := os.ReadFile("/proc/self/task/" + task.Name() + "/children")
+		if err != nil {
+			return nil, false
+		}
+		for _, field := range strings.Fields(string(b)) {
+			if pid, err := strconv.Atoi(field); err == nil {
+				pids = append(pids, pid)
+			}
+		}
+	}
+	return pids, true
+}
+
+// procStatFields returns the fields of /proc/<pid>/stat after the command
+// name, starting with the state.
+func procStatFields(pid int) ([]string, bool) {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return nil, false
+	}
+	line := string(b)
+	// The command name is in parentheses and may itself contain spaces.
+	end := strings.LastIndex(line, ") ")
+	if end == -1 {
+		return nil, false
+	}
+	return strings.Fields(line[end+2:]), true
+}
+
+// procState returns the state letter and start time (in clock ticks) of pid.
+func procState(pid int) (byte, int64, bool) {
+	fields, ok := procStatFields(pid)
+	if !ok || len(fields) < 20 || fields[0] == "" {
+		return 0, 0, false
+	}
+	start, _ := strconv.ParseInt(fields[19], 10, 64)
+	return fields[0][0], start, true
+}
+
+func procParent(pid int) (int, bool) {
+	fields, ok := procStatFields(pid)
+	if !ok || len(fields) < 2 {
+		return 0, false
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	return ppid, err == nil
+}
+
+// isZombie reports whether pid has exited and only awaits being reaped.
+// Signal 0 still succeeds on such a process.
+func isZombie(pid int) bool {
+	state, _, ok := procState(pid)
+	return ok && state == 'Z'
+}
@@ -0,0 +1,67 @@
+//go:build linux
+
+package process
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectAliveTreatsZombieAsDead(t *testing.T) {
+	cmd := exec.Command("true")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	// Not waited for until the end, so it stays a zombie meanwhile.
+	defer func() { _ = cmd.Wait() }()
+	pid := cmd.Process.Pid
+
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for !isZombie(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("pid %d never became a zombie", pid)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	r := New(Spec{Name: "zombie"})
+	r.SeedPID(pid)
+	if alive, how := r.DetectAlive(); alive {
+		t.Fatalf("zombie reported alive via %s", how)
+	}
+}
+
+func TestOrphanReaperCollectsAdoptedChildren(t *testing.T) {
+	if err := SetChildSubreaper(); err != nil {
+		t.Skipf("cannot become a child subreaper: %v", err)
+	}
+	// The shell exits right away, leaving its background child to us.
+	out, err := exec.Command("/bin/sh", "-c", "sleep 0.2 >/dev/null & echo $!").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphan, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if ppid, ok := procParent(orphan); !ok || ppid != os.Getpid() {
+		t.Fatalf("orphan %d was not adopted (parent %d)", orphan, ppid)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, _, ok := procState(orphan); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("orphan %d was not reaped", orphan)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if zombies := zombieChildren(); len(zombies) != 0 {
+		t.Fatalf("defunct children remain: %v", zombies)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package process
+
+import "errors"
+
+// StartOrphanReaper is a no-op outside Linux, where provisr cannot adopt
+// orphaned processes; everything it starts is reaped by its cmd.Wait.
+func StartOrphanReaper() {}
+
+// SetChildSubreaper is only supported on Linux.
+func SetChildSubreaper() error {
+	return errors.New("child subreaper is only supported on Linux")
+}
@@ -0,0 +1,35 @@
+//go:build linux
+
+package process
+
+import (
+	"fmt"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// checkSchedSupport reports whether the spec's scheduling attributes can be
+// applied on this platform. Linux supports both nice and CPU affinity.
+func checkSchedSupport(Spec) error { return nil }
+
+// applySchedAttrs sets the niceness and CPU affinity of a freshly spawned
+// child. Go cannot run code between fork and exec, so this runs right after
+// cmd.Start; anything the child forks afterwards inherits both settings.
+func applySchedAttrs(pid int, spec Spec) error {
+	if spec.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, spec.Nice); err != nil {
+			return fmt.Errorf("set nice %d: %w", spec.Nice, err)
+		}
+	}
+	if len(spec.CPUAffinity) > 0 {
+		var set unix.CPUSet
+		for _, cpu := range spec.CPUAffinity {
+			set.Set(cpu)
+		}
+		if err := unix.SchedSetaffinity(pid, &set); err != nil {
+			return fmt.Errorf("set cpu affinity %v: %w", spec.CPUAffinity, err)
+		}
+	}
+	return nil
+}
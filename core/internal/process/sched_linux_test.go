@@ -0,0 +1,63 @@
+//go:build linux
+
+package process
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// procNice reads the nice value (field 19) from /proc/<pid>/stat.
+func procNice(t *testing.T, pid int) int {
+	t.Helper()
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		t.Fatalf("read stat: %v", err)
+	}
+	line := string(b)
+	fields := strings.Fields(line[strings.LastIndex(line, ")")+2:])
+	// fields[0] is field 3 (state), so field 19 is at index 16.
+	n, err := strconv.Atoi(fields[16])
+	if err != nil {
+		t.Fatalf("parse nice %q: %v", fields[16], err)
+	}
+	return n
+}
+
+func TestTryStartAppliesNiceAndAffinity(t *testing.T) {
+	r := New(Spec{Name: "sched", Command: "sleep 5", Nice: 7, CPUAffinity: []int{0}})
+	cmd := r.ConfigureCmd(nil)
+	if err := r.TryStart(cmd); err != nil {
+		t.Fatalf("TryStart: %v", err)
+	}
+	defer func() { _ = r.Kill() }()
+	pid := cmd.Process.Pid
+
+	if got := procNice(t, pid); got != 7 {
+		t.Fatalf("nice = %d, want 7", got)
+	}
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(pid, &set); err != nil {
+		t.Fatalf("SchedGetaffinity: %v", err)
+	}
+	if set.Count() != 1 || !set.IsSet(0) {
+		t.Fatalf("affinity = %d cpus (cpu0 set: %v), want only cpu0", set.Count(), set.IsSet(0))
+	}
+}
+
+func TestTryStartFailsOnInvalidAffinity(t *testing.T) {
+	// No machine has this CPU, so sched_setaffinity rejects the empty mask.
+	r := New(Spec{Name: "sched-bad", Command: "sleep 5", CPUAffinity: []int{1000}})
+	cmd := r.ConfigureCmd(nil)
+	if err := r.TryStart(cmd); err == nil {
+		_ = r.Kill()
+		t.Fatal("expected TryStart to fail for an unusable cpu_affinity")
+	}
+	if alive, _ := r.DetectAlive(); alive {
+		t.Fatal("child should be killed when scheduling attributes can't be applied")
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !linux && !windows
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// checkSchedSupport reports whether the spec's scheduling attributes can be
+// applied on this platform. Non-Linux Unix systems have no per-process CPU
+// affinity API.
+func checkSchedSupport(spec Spec) error {
+	if len(spec.CPUAffinity) > 0 {
+		return errors.New("cpu_affinity is only supported on Linux")
+	}
+	return nil
+}
+
+// applySchedAttrs sets the niceness of a freshly spawned child. Go cannot
+// run code between fork and exec, so this runs right after cmd.Start.
+func applySchedAttrs(pid int, spec Spec) error {
+	if err := checkSchedSupport(spec); err != nil {
+		return err
+	}
+	if spec.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, spec.Nice); err != nil {
+			return fmt.Errorf("set nice %d: %w", spec.Nice, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package process
+
+import "errors"
+
+// checkSchedSupport reports whether the spec's scheduling attributes can be
+// applied on this platform. Windows uses priority classes rather than nice
+// values, and neither setting is mapped yet.
+func checkSchedSupport(spec Spec) error {
+	if spec.Nice != 0 {
+		return errors.New("nice is not supported on Windows")
+	}
+	if len(spec.CPUAffinity) > 0 {
+		return errors.New("cpu_affinity is not supported on Windows")
+	}
+	return nil
+}
+
+func applySchedAttrs(_ int, spec Spec) error { return checkSchedSupport(spec) }
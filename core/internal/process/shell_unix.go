@@ -4,10 +4,14 @@ package process
 
 import "os/exec"
 
-// getShellCommand returns a shell command for Unix systems
-func getShellCommand(script string) *exec.Cmd {
+// getShellCommand returns a shell command for Unix systems, run by shellPath
+// or /bin/sh when it is empty.
+func getShellCommand(shellPath, script string) *exec.Cmd {
+	if shellPath == "" {
+		shellPath = "/bin/sh"
+	}
 	// #nosec G204
-	return exec.Command("/bin/sh", "-c", script)
+	return exec.Command(shellPath, "-c", script)
 }
 
 // getTrueCommand returns a command that always succeeds on Unix systems
@@ -15,3 +19,15 @@ func getTrueCommand() *exec.Cmd {
 	// #nosec G204
 	return exec.Command("/bin/true")
 }
+
+// shellBuiltins are the words a /bin/sh script can start with that need not
+// exist as a program on PATH.
+var shellBuiltins = map[string]bool{
+	".": true, ":": true, "[": true, "alias": true, "break": true, "cd": true,
+	"command": true, "continue": true, "eval": true, "exec": true, "exit": true,
+	"export": true, "read": true, "readonly": true, "return": true, "set": true,
+	"shift": true, "source": true, "test": true, "times": true, "trap": true,
+	"type": true, "ulimit": true, "umask": true, "unset": true, "wait": true,
+	"echo": true, "printf": true, "true": true, "false": true, "pwd": true,
+	"if": true, "for": true, "while": true, "until": true, "case": true,
+}
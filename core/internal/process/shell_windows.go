@@ -2,12 +2,25 @@
 
 package process
 
-import "os/exec"
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
 
-// getShellCommand returns a shell command for Windows systems
-func getShellCommand(script string) *exec.Cmd {
+// getShellCommand returns a shell command for Windows systems, run by
+// shellPath or cmd when it is empty. Shells other than cmd get "-c", which
+// sh, bash and pwsh all accept.
+func getShellCommand(shellPath, script string) *exec.Cmd {
+	if shellPath == "" {
+		shellPath = "cmd"
+	}
+	flag := "-c"
+	if base := strings.ToLower(filepath.Base(shellPath)); base == "cmd" || base == "cmd.exe" {
+		flag = "/c"
+	}
 	// #nosec G204
-	return exec.Command("cmd", "/c", script)
+	return exec.Command(shellPath, flag, script)
 }
 
 // getTrueCommand returns a command that always succeeds on Windows systems
@@ -15,3 +28,14 @@ func getTrueCommand() *exec.Cmd {
 	// #nosec G204
 	return exec.Command("cmd", "/c", "rem")
 }
+
+// shellBuiltins are the words a cmd script can start with that need not
+// exist as a program on PATH.
+var shellBuiltins = map[string]bool{
+	"call": true, "cd": true, "chdir": true, "cls": true, "copy": true,
+	"del": true, "dir": true, "echo": true, "erase": true, "exit": true,
+	"for": true, "if": true, "md": true, "mkdir": true, "move": true,
+	"pushd": true, "popd": true, "rd": true, "rem": true, "ren": true,
+	"rename": true, "rmdir": true, "set": true, "setlocal": true,
+	"start": true, "type": true, "ver": true,
+}
@@ -2,13 +2,51 @@
 
 package process
 
-import "syscall"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
 
 // killProcess sends a signal to a Unix process
 func killProcess(pid int, signal syscall.Signal) error {
 	return syscall.Kill(pid, signal)
 }
 
+// signalGroup sends sig to the process group led by pid. Every non-detached
+// child is started in its own group (see configureSysProcAttr), so this
+// reaches grandchildren spawned through a shell as well.
+func signalGroup(pid int, sig syscall.Signal) error {
+	return syscall.Kill(-pid, sig)
+}
+
+// signalProcess sends sig to pid alone, not to the rest of its group.
+func signalProcess(pid int, sig syscall.Signal) error {
+	return syscall.Kill(pid, sig)
+}
+
+// ParseSignal parses a signal name with or without the SIG prefix, in any
+// case ("HUP", "sigusr1"), or a signal number.
+func ParseSignal(s string) (syscall.Signal, error) {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(name); err == nil {
+		if n > 0 && unix.SignalName(syscall.Signal(n)) != "" {
+			return syscall.Signal(n), nil
+		}
+		return 0, fmt.Errorf("unknown signal %q", s)
+	}
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	if sig := unix.SignalNum(name); sig != 0 {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("unknown signal %q", s)
+}
+
 // processExists checks if a process exists.
 func processExists(pid int) bool {
 	return syscall.Kill(pid, 0) == nil
@@ -3,21 +3,95 @@
 package process
 
 import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
 	"syscall"
 )
 
 var (
-	kernel32             = syscall.NewLazyDLL("kernel32.dll")
-	procOpenProcess      = kernel32.NewProc("OpenProcess")
-	procTerminateProcess = kernel32.NewProc("TerminateProcess")
-	procCloseHandle      = kernel32.NewProc("CloseHandle")
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procOpenProcess              = kernel32.NewProc("OpenProcess")
+	procTerminateProcess         = kernel32.NewProc("TerminateProcess")
+	procCloseHandle              = kernel32.NewProc("CloseHandle")
+	procGenerateConsoleCtrlEvent = kernel32.NewProc("GenerateConsoleCtrlEvent")
 )
 
 const (
 	PROCESS_TERMINATE         = 0x0001
 	PROCESS_QUERY_INFORMATION = 0x0400
+
+	CTRL_BREAK_EVENT = 1
 )
 
+// signalGroup maps Unix stop signals onto Windows process control.
+// Children are started with CREATE_NEW_PROCESS_GROUP (see
+// configureSysProcAttr), so the child's PID is also its console process
+// group ID:
+//   - signal 0 checks that the process exists
+//   - SIGINT/SIGTERM deliver CTRL_BREAK_EVENT to the group, the closest
+//     thing to a graceful stop a console program can observe; it fails for
+//     detached processes that have no console, and callers fall back to
+//     Kill as they would for a failed signal on Unix
+//   - SIGKILL (and anything else) terminates the whole tree via taskkill,
+//     falling back to TerminateProcess on the child itself
+func signalGroup(pid int, sig syscall.Signal) error {
+	if pid <= 0 {
+		return nil
+	}
+	switch sig {
+	case 0:
+		return checkProcessExists(pid)
+	case syscall.SIGINT, syscall.SIGTERM:
+		ret, _, err := procGenerateConsoleCtrlEvent.Call(uintptr(CTRL_BREAK_EVENT), uintptr(pid))
+		if ret == 0 {
+			return err
+		}
+		return nil
+	default:
+		// #nosec G204 -- fixed binary, numeric PID argument
+		if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid)).Run(); err == nil {
+			return nil
+		}
+		return killProcess(pid, syscall.SIGKILL)
+	}
+}
+
+// signalProcess sends sig to pid. Windows cannot deliver signals to a single
+// process, so it is mapped like signalGroup.
+func signalProcess(pid int, sig syscall.Signal) error {
+	return signalGroup(pid, sig)
+}
+
+// windowsSignals are the signals signalGroup can map on Windows.
+var windowsSignals = map[string]syscall.Signal{
+	"SIGINT":  syscall.SIGINT,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGKILL": syscall.SIGKILL,
+}
+
+// ParseSignal parses a signal name with or without the SIG prefix, in any
+// case, or a signal number. Only INT, TERM and KILL exist on Windows.
+func ParseSignal(s string) (syscall.Signal, error) {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(name); err == nil {
+		for _, sig := range windowsSignals {
+			if int(sig) == n {
+				return sig, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown signal %q", s)
+	}
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	if sig, ok := windowsSignals[name]; ok {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("unknown signal %q: only INT, TERM and KILL are supported on Windows", s)
+}
+
 // killProcess terminates a Windows process by PID
 func killProcess(pid int, signal syscall.Signal) error {
 	// Handle negative PID (process group on Unix) - on Windows, just use absolute value
@@ -3,6 +3,7 @@ package process
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -23,21 +24,53 @@ type Spec struct {
 	Name            string              `json:"name" mapstructure:"name"`
 	Command         string              `json:"command" mapstructure:"command"`                   // command to start the process (shell string); mutually exclusive with Args
 	Args            []string            `json:"args" mapstructure:"args"`                         // command as argv slice; when set, Command is ignored and no shell is invoked
+	Shell           *bool               `json:"shell,omitempty" mapstructure:"shell"`             // wrap Command in a shell: true always, false never (exec its words directly); unset decides from metacharacters
+	ShellPath       string              `json:"shell_path,omitempty" mapstructure:"shell_path"`   // shell Command runs through; default /bin/sh (cmd on Windows)
 	WorkDir         string              `json:"work_dir" mapstructure:"work_dir"`                 // optional working dir
+	CreateWorkDir   bool                `json:"create_work_dir" mapstructure:"create_work_dir"`   // create WorkDir (and missing parents) before each start if it does not exist
+	WorkDirMode     string              `json:"work_dir_mode" mapstructure:"work_dir_mode"`       // octal permissions for a created WorkDir, e.g. "0750"; default "0755"
 	Env             []string            `json:"env" mapstructure:"env"`                           // optional extra env
 	PIDFile         string              `json:"pid_file" mapstructure:"pid_file"`                 // optional pidfile path; if set a PIDFileDetector will be used
-	Priority        int                 `json:"priority" mapstructure:"priority"`                 // startup priority (lower numbers start first, default 0)
+	Priority        int                 `json:"priority" mapstructure:"priority"`                 // startup priority: lower numbers start first and stop last (default 0)
 	RetryCount      uint32              `json:"retry_count" mapstructure:"retry_count"`           // number of retries on start failure
 	RetryInterval   time.Duration       `json:"retry_interval" mapstructure:"retry_interval"`     // interval between retries
 	StartDuration   time.Duration       `json:"start_duration" mapstructure:"start_duration"`     // minimum time the process must stay up to be considered started
+	StartTimeout    time.Duration       `json:"start_timeout" mapstructure:"start_timeout"`       // abort the start (and kill the child) if spawning plus StartDuration takes longer than this; 0 disables
 	AutoRestart     bool                `json:"auto_restart" mapstructure:"auto_restart"`         // restart automatically if the process dies unexpectedly
 	RestartInterval time.Duration       `json:"restart_interval" mapstructure:"restart_interval"` // wait before attempting an auto-restart
 	Instances       int                 `json:"instances" mapstructure:"instances"`               // number of instances to run concurrently (default 1)
 	Detached        bool                `json:"detached" mapstructure:"detached"`                 // run in detached mode
+	Nice            int                 `json:"nice" mapstructure:"nice"`                         // scheduling niceness (-20..19); 0 leaves the inherited value
+	CPUAffinity     []int               `json:"cpu_affinity" mapstructure:"cpu_affinity"`         // CPUs the process may run on (Linux only); empty means no restriction
 	Detectors       []detector.Detector `json:"-" mapstructure:"-"`                               // excluded from mapstructure
 	DetectorConfigs []DetectorConfig    `json:"detectors" mapstructure:"detectors"`               // for config parsing
 	Log             logger.Config       `json:"log" mapstructure:"log"`                           // unified slog-based logging configuration
 	Lifecycle       LifecycleHooks      `json:"lifecycle" mapstructure:"lifecycle"`               // lifecycle hooks for pre/post operations
+	Umask           string              `json:"umask" mapstructure:"umask"`                       // octal file mode creation mask for the child, e.g. "027" (Unix only; set through /bin/sh, so not with args or shell = false); empty inherits the daemon's
+	CleanEnv        bool                `json:"clean_env" mapstructure:"clean_env"`               // start from a minimal PATH instead of the daemon's environment; global and per-process env still apply
+
+	// Labels are free-form key/value pairs describing the process (owner
+	// team, ticket, environment). Status queries can select processes by
+	// label, and registration records them in the metadata store.
+	Labels map[string]string `json:"labels,omitempty" mapstructure:"labels"`
+
+	// TCP ports the process listens on. When set, each start first checks
+	// that none of them is already listening; PortCheck chooses between
+	// failing the start ("error", the default) and logging a warning
+	// ("warn"). Every instance of a multi-instance process checks the same
+	// ports.
+	Ports     []int  `json:"ports,omitempty" mapstructure:"ports"`
+	PortCheck string `json:"port_check,omitempty" mapstructure:"port_check"`
+
+	// How often the supervisor checks liveness and considers an auto-restart.
+	// 0 uses the manager default (1s unless configured otherwise).
+	HealthCheckInterval time.Duration `json:"health_check_interval" mapstructure:"health_check_interval"`
+
+	// Identity to run the child as (Unix only). Switching requires the
+	// daemon to run as root or hold CAP_SETUID/CAP_SETGID.
+	User                string   `json:"user" mapstructure:"user"`                                 // user name or numeric uid
+	Group               string   `json:"group" mapstructure:"group"`                               // group name or numeric gid; defaults to User's primary group
+	SupplementaryGroups []string `json:"supplementary_groups" mapstructure:"supplementary_groups"` // replaces the supplementary groups; defaults to User's memberships
 
 	// InlineConfig marks a spec declared directly in the main config file's
 	// `[[processes]]` array, as opposed to a file in the programs directory
@@ -64,6 +97,69 @@ func (s *Spec) Validate() error {
 	if len(s.Args) > 0 && s.Args[0] == "" {
 		return fmt.Errorf("process %q: args[0] must not be empty", s.Name)
 	}
+	if len(s.Args) > 0 && (s.Shell != nil || s.ShellPath != "") {
+		return fmt.Errorf("process %q: shell and shell_path apply to command, not args", s.Name)
+	}
+	if s.Shell != nil && !*s.Shell {
+		if s.ShellPath != "" {
+			return fmt.Errorf("process %q: shell_path requires shell to be enabled", s.Name)
+		}
+		if _, err := splitWords(s.Command); err != nil {
+			return fmt.Errorf("process %q: command: %w", s.Name, err)
+		}
+	}
+	if s.StartTimeout < 0 {
+		return fmt.Errorf("process %q: start_timeout cannot be negative", s.Name)
+	}
+	if s.StartTimeout > 0 && s.StartDuration > 0 && s.StartTimeout <= s.StartDuration {
+		return fmt.Errorf("process %q: start_timeout (%s) must be longer than start_duration (%s), or every start times out", s.Name, s.StartTimeout, s.StartDuration)
+	}
+	if s.HealthCheckInterval < 0 {
+		return fmt.Errorf("process %q: health_check_interval cannot be negative", s.Name)
+	}
+	if s.Nice < -20 || s.Nice > 19 {
+		return fmt.Errorf("process %q: nice must be between -20 and 19, got %d", s.Name, s.Nice)
+	}
+	if err := s.validateWorkDir(); err != nil {
+		return fmt.Errorf("process %q: %w", s.Name, err)
+	}
+	for _, port := range s.Ports {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("process %q: ports entries must be between 1 and 65535, got %d", s.Name, port)
+		}
+	}
+	switch s.PortCheck {
+	case "", PortCheckError, PortCheckWarn:
+	default:
+		return fmt.Errorf("process %q: invalid port_check %q, must be one of: error, warn", s.Name, s.PortCheck)
+	}
+	if err := ValidateLabels(s.Labels); err != nil {
+		return fmt.Errorf("process %q: %w", s.Name, err)
+	}
+	for _, cpu := range s.CPUAffinity {
+		if cpu < 0 {
+			return fmt.Errorf("process %q: cpu_affinity entries must be non-negative, got %d", s.Name, cpu)
+		}
+	}
+	if err := checkSchedSupport(*s); err != nil {
+		return fmt.Errorf("process %q: %w", s.Name, err)
+	}
+	if err := checkCredentialSupport(*s); err != nil {
+		return fmt.Errorf("process %q: %w", s.Name, err)
+	}
+	if s.Umask != "" {
+		if _, err := ParseUmask(s.Umask); err != nil {
+			return fmt.Errorf("process %q: %w", s.Name, err)
+		}
+		if err := checkUmaskSupport(); err != nil {
+			return fmt.Errorf("process %q: %w", s.Name, err)
+		}
+		// applyUmask sets it through /bin/sh, which args and shell = false
+		// promise never to run.
+		if len(s.Args) > 0 || (s.Shell != nil && !*s.Shell) {
+			return fmt.Errorf("process %q: umask starts the command through /bin/sh; it cannot be combined with args or shell = false", s.Name)
+		}
+	}
 	// Detached mode must not configure file logging, because manager-supplied
 	// writers may hold the child process via open fds. Enforce mutual exclusion.
 	if s.Detached {
@@ -72,6 +168,33 @@ func (s *Spec) Validate() error {
 		}
 	}
 
+	if _, err := ParseDirMode(s.Log.File.DirMode); err != nil {
+		return fmt.Errorf("process %q: log dir_mode: %w", s.Name, err)
+	}
+	if err := ValidateDirOwner(s.Log.File.DirOwner); err != nil {
+		return fmt.Errorf("process %q: log dir_owner: %w", s.Name, err)
+	}
+
+	switch s.Log.Slog.Level {
+	case "", logger.LevelDebug, logger.LevelInfo, logger.LevelWarn, logger.LevelError:
+	default:
+		return fmt.Errorf("process %q: invalid log level %q, must be one of: debug, info, warn, error", s.Name, s.Log.Slog.Level)
+	}
+	switch s.Log.File.OutputFormat {
+	case "", logger.OutputFormatRaw, logger.OutputFormatJSON, logger.OutputFormatPrefixed:
+	default:
+		return fmt.Errorf("process %q: invalid log output_format %q, must be one of: raw, json, prefixed", s.Name, s.Log.File.OutputFormat)
+	}
+	if s.Log.File.MaxLineBytes < 0 {
+		return fmt.Errorf("process %q: log max_line_bytes cannot be negative", s.Name)
+	}
+	if s.Log.File.FlushInterval < 0 {
+		return fmt.Errorf("process %q: log flush_interval cannot be negative", s.Name)
+	}
+	if err := s.Log.Syslog.Validate(); err != nil {
+		return fmt.Errorf("process %q: %w", s.Name, err)
+	}
+
 	// Validate lifecycle hooks
 	if err := s.Lifecycle.Validate(); err != nil {
 		return fmt.Errorf("process %q: lifecycle validation failed: %w", s.Name, err)
@@ -95,6 +218,30 @@ func (s *Spec) DeepCopy() *Spec {
 		copySpec.Env = append([]string(nil), s.Env...)
 	}
 
+	if s.Shell != nil {
+		shell := *s.Shell
+		copySpec.Shell = &shell
+	}
+
+	if s.CPUAffinity != nil {
+		copySpec.CPUAffinity = append([]int(nil), s.CPUAffinity...)
+	}
+
+	if s.Ports != nil {
+		copySpec.Ports = append([]int(nil), s.Ports...)
+	}
+
+	if s.Labels != nil {
+		copySpec.Labels = make(map[string]string, len(s.Labels))
+		for k, v := range s.Labels {
+			copySpec.Labels[k] = v
+		}
+	}
+
+	if s.SupplementaryGroups != nil {
+		copySpec.SupplementaryGroups = append([]string(nil), s.SupplementaryGroups...)
+	}
+
 	// Copy DetectorConfigs slice
 	if s.DetectorConfigs != nil {
 		copySpec.DetectorConfigs = append([]DetectorConfig(nil), s.DetectorConfigs...)
@@ -108,9 +255,13 @@ func (s *Spec) DeepCopy() *Spec {
 	return &copySpec
 }
 
+// shellMetachars makes a Command run through a shell when Shell is unset.
+const shellMetachars = "|&;<>*?`$\"'(){}[]~"
+
 // BuildCommand constructs an *exec.Cmd for the given spec.
 // When Args is set, it is used directly without invoking a shell.
-// Otherwise Command (shell string) is parsed as before.
+// Otherwise Command is run through a shell or split into words as Shell
+// decides (see shellScript).
 func (s *Spec) BuildCommand() *exec.Cmd {
 	if len(s.Args) > 0 {
 		// #nosec G204
@@ -120,24 +271,75 @@ func (s *Spec) BuildCommand() *exec.Cmd {
 	if cmdStr == "" {
 		return getTrueCommand()
 	}
-	// If the command already explicitly uses a shell, honor it without adding another layer.
-	if _, afterC, ok := parseExplicitShell(cmdStr); ok {
+	if script, ok := s.shellScript(cmdStr); ok {
 		// Use platform-specific shell command
-		return getShellCommand(afterC)
-	}
-	// Fallback: when metacharacters are present, use shell
-	if strings.ContainsAny(cmdStr, "|&;<>*?`$\"'(){}[]~") {
-		return getShellCommand(cmdStr)
+		return getShellCommand(s.ShellPath, script)
 	}
-	parts := strings.Fields(cmdStr)
-	name := parts[0]
-	var args []string
-	if len(parts) > 1 {
-		args = parts[1:]
+	// Validate rejects commands that do not split; fall back to plain fields.
+	parts, err := splitWords(cmdStr)
+	if err != nil || len(parts) == 0 {
+		parts = strings.Fields(cmdStr)
 	}
 	// ok: intentional execution, input is validated and safe
 	// #nosec G204
-	return exec.Command(name, args...)
+	return exec.Command(parts[0], parts[1:]...)
+}
+
+// shellScript reports whether cmdStr runs through a shell and, if so, the
+// script handed to it. Shell=false never uses one. Otherwise a command that
+// already invokes "sh -c" is honored without adding another layer, and the
+// whole command is the script when Shell=true or it contains metacharacters.
+func (s *Spec) shellScript(cmdStr string) (string, bool) {
+	if s.Shell != nil && !*s.Shell {
+		return "", false
+	}
+	if _, afterC, ok := parseExplicitShell(cmdStr); ok {
+		return afterC, true
+	}
+	if (s.Shell != nil && *s.Shell) || strings.ContainsAny(cmdStr, shellMetachars) {
+		return cmdStr, true
+	}
+	return "", false
+}
+
+// splitWords splits a command into words on whitespace. Single or double
+// quotes group a word and are removed; there is no other escaping, so
+// Windows paths keep their backslashes.
+func splitWords(cmdStr string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quoting rune
+	)
+	for _, r := range cmdStr {
+		switch {
+		case quoting != 0:
+			if r == quoting {
+				quoting = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quoting, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quoting != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quoting)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
 }
 
 // parseExplicitShell detects patterns like "sh -c <ARG>" or "/bin/sh -c <ARG>" at the
@@ -162,3 +364,46 @@ func parseExplicitShell(cmdStr string) (string, string, bool) {
 	}
 	return "", "", false
 }
+
+// ResolveCommand checks that the program the spec runs can be found: Args[0],
+// the first word of Command or, when Command runs through a shell, the first
+// word of the script. Relative paths are resolved against WorkDir. A script
+// starting with a shell builtin or with anything other than a plain word is
+// not checked, since only the shell can tell what it runs.
+func (s *Spec) ResolveCommand() error {
+	program := ""
+	if len(s.Args) > 0 {
+		program = s.Args[0]
+	} else {
+		cmdStr := strings.TrimSpace(s.Command)
+		if cmdStr == "" {
+			return nil
+		}
+		script, shell := s.shellScript(cmdStr)
+		if shell && s.ShellPath != "" {
+			if _, err := exec.LookPath(s.ShellPath); err != nil {
+				return fmt.Errorf("process %q: shell not found: %s", s.Name, s.ShellPath)
+			}
+		}
+		var fields []string
+		if shell {
+			fields = strings.Fields(script)
+		} else {
+			fields, _ = splitWords(cmdStr)
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+		program = fields[0]
+		if shell && (shellBuiltins[strings.ToLower(program)] || strings.ContainsAny(program, shellMetachars+"=")) {
+			return nil
+		}
+	}
+	if s.WorkDir != "" && !filepath.IsAbs(program) && strings.ContainsAny(program, `/\`) {
+		program = filepath.Join(s.WorkDir, program)
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return fmt.Errorf("process %q: command not found: %s", s.Name, program)
+	}
+	return nil
+}
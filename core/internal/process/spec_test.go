@@ -1,9 +1,13 @@
 package process
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/loykin/provisr/core/internal/logger"
 )
@@ -45,7 +49,124 @@ func TestBuildCommand_MetacharTriggersShell(t *testing.T) {
 	}
 }
 
+func TestBuildCommand_ShellMode(t *testing.T) {
+	requireUnixSpec(t)
+	on, off := true, false
+	tests := []struct {
+		name string
+		spec Spec
+		want []string
+	}{
+		{
+			name: "forced shell without metacharacters",
+			spec: Spec{Command: "ulimit -n", Shell: &on},
+			want: []string{"/bin/sh", "-c", "ulimit -n"},
+		},
+		{
+			name: "custom shell path",
+			spec: Spec{Command: "echo hi | wc -c", ShellPath: "/bin/bash"},
+			want: []string{"/bin/bash", "-c", "echo hi | wc -c"},
+		},
+		{
+			name: "explicit sh -c is not double-wrapped when forced",
+			spec: Spec{Command: "sh -c 'echo hi'", Shell: &on},
+			want: []string{"/bin/sh", "-c", "echo hi"},
+		},
+		{
+			name: "shell disabled keeps metacharacters literal",
+			spec: Spec{Command: `printf '%s|%s' "a b" $HOME`, Shell: &off},
+			want: []string{"printf", "%s|%s", "a b", "$HOME"},
+		},
+		{
+			name: "shell disabled with explicit sh -c runs sh directly",
+			spec: Spec{Command: "sh -c 'echo hi; echo there'", Shell: &off},
+			want: []string{"sh", "-c", "echo hi; echo there"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Name = "svc"
+			if err := tt.spec.Validate(); err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			if got := tt.spec.BuildCommand().Args; !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("argv = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCommand_ShellDisabledRunsWithoutShell(t *testing.T) {
+	requireUnixSpec(t)
+	off := false
+	s := Spec{Name: "literal", Command: `echo "a  b" '$HOME' |`, Shell: &off}
+	out, err := s.BuildCommand().Output()
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if string(out) != "a  b $HOME |\n" {
+		t.Fatalf("output = %q", out)
+	}
+}
+
+func TestSpec_ValidateStartTimeout(t *testing.T) {
+	for _, timeout := range []time.Duration{time.Second, 500 * time.Millisecond} {
+		s := Spec{Name: "svc", Command: "true", StartDuration: time.Second, StartTimeout: timeout}
+		if err := s.Validate(); err == nil || !strings.Contains(err.Error(), "must be longer than start_duration") {
+			t.Fatalf("start_timeout %s: expected error, got %v", timeout, err)
+		}
+	}
+	s := Spec{Name: "svc", Command: "true", StartDuration: time.Second, StartTimeout: 2 * time.Second}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("start_timeout past start_duration: %v", err)
+	}
+	s = Spec{Name: "svc", Command: "true", StartTimeout: time.Second}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("start_timeout without start_duration: %v", err)
+	}
+}
+
+func TestSpec_ValidateShellOptions(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name string
+		spec Spec
+		want string
+	}{
+		{"shell with args", Spec{Args: []string{"true"}, Shell: &on}, "apply to command, not args"},
+		{"shell_path with args", Spec{Args: []string{"true"}, ShellPath: "/bin/bash"}, "apply to command, not args"},
+		{"shell_path with shell disabled", Spec{Command: "true", Shell: &off, ShellPath: "/bin/bash"}, "requires shell to be enabled"},
+		{"unterminated quote", Spec{Command: `echo "oops`, Shell: &off}, "unterminated \" quote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Name = "svc"
+			if err := tt.spec.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// The auto mode does not split the command itself, so quotes there are
+	// left to the shell.
+	s := Spec{Name: "svc", Command: `echo "oops`}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("auto mode should not validate quoting: %v", err)
+	}
+}
+
+func TestSpec_DeepCopyShell(t *testing.T) {
+	on := true
+	s := Spec{Name: "svc", Command: "true", Shell: &on}
+	c := s.DeepCopy()
+	*c.Shell = false
+	if !*s.Shell {
+		t.Fatal("DeepCopy shares the Shell pointer")
+	}
+}
+
 func TestSpec_Validate(t *testing.T) {
+	off := false
 	tests := []struct {
 		name        string
 		spec        Spec
@@ -96,6 +217,35 @@ func TestSpec_Validate(t *testing.T) {
 			expectErr:   true,
 			errContains: "requires command",
 		},
+		{
+			name: "valid labels",
+			spec: Spec{
+				Name:    "test-process",
+				Command: "echo hello",
+				Labels:  map[string]string{"team": "payments", "example.com/ticket": "OPS-42", "empty": ""},
+			},
+			expectErr: false,
+		},
+		{
+			name: "label key with equals sign",
+			spec: Spec{
+				Name:    "test-process",
+				Command: "echo hello",
+				Labels:  map[string]string{"team=x": "payments"},
+			},
+			expectErr:   true,
+			errContains: "invalid label key",
+		},
+		{
+			name: "label value with comma",
+			spec: Spec{
+				Name:    "test-process",
+				Command: "echo hello",
+				Labels:  map[string]string{"team": "a,b"},
+			},
+			expectErr:   true,
+			errContains: "must not contain",
+		},
 		{
 			name: "detached with file logging should fail",
 			spec: Spec{
@@ -168,6 +318,72 @@ func TestSpec_Validate(t *testing.T) {
 			expectErr:   true,
 			errContains: "mutually exclusive",
 		},
+		{
+			name:        "nice out of range should fail",
+			spec:        Spec{Name: "p", Command: "echo hi", Nice: 20},
+			expectErr:   true,
+			errContains: "nice must be between",
+		},
+		{
+			name:        "negative cpu in affinity should fail",
+			spec:        Spec{Name: "p", Command: "echo hi", CPUAffinity: []int{-1}},
+			expectErr:   true,
+			errContains: "cpu_affinity entries must be non-negative",
+		},
+		{
+			name:        "out of range port should fail",
+			spec:        Spec{Name: "p", Command: "echo hi", Ports: []int{8080, 70000}},
+			expectErr:   true,
+			errContains: "ports entries must be between 1 and 65535",
+		},
+		{
+			name:        "unknown port check should fail",
+			spec:        Spec{Name: "p", Command: "echo hi", Ports: []int{8080}, PortCheck: "ignore"},
+			expectErr:   true,
+			errContains: "invalid port_check",
+		},
+		{
+			name:        "create_work_dir without work_dir should fail",
+			spec:        Spec{Name: "p", Command: "echo hi", CreateWorkDir: true},
+			expectErr:   true,
+			errContains: "create_work_dir requires work_dir",
+		},
+		{
+			name:        "created work_dir with traversal should fail",
+			spec:        Spec{Name: "p", Command: "echo hi", WorkDir: "/srv/app/../../etc", CreateWorkDir: true},
+			expectErr:   true,
+			errContains: "path traversal",
+		},
+		{
+			name:        "invalid work_dir_mode should fail",
+			spec:        Spec{Name: "p", Command: "echo hi", WorkDir: "/srv/app", CreateWorkDir: true, WorkDirMode: "rwx"},
+			expectErr:   true,
+			errContains: "invalid work_dir_mode",
+		},
+		{
+			name:        "non-octal umask should fail",
+			spec:        Spec{Name: "p", Command: "echo hi", Umask: "089"},
+			expectErr:   true,
+			errContains: "invalid umask",
+		},
+		{
+			name:        "umask with args should fail",
+			spec:        Spec{Name: "p", Args: []string{"echo", "hi"}, Umask: "027"},
+			expectErr:   true,
+			errContains: "cannot be combined with args or shell = false",
+		},
+		{
+			name:        "umask with shell disabled should fail",
+			spec:        Spec{Name: "p", Command: "echo hi", Shell: &off, Umask: "027"},
+			expectErr:   true,
+			errContains: "cannot be combined with args or shell = false",
+		},
+		{
+			name:        "negative health check interval should fail",
+			spec:        Spec{Name: "p", Command: "echo hi", HealthCheckInterval: -1},
+			expectErr:   true,
+			errContains: "health_check_interval cannot be negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -190,6 +406,28 @@ func TestSpec_Validate(t *testing.T) {
 	}
 }
 
+func TestParseLabelSelector(t *testing.T) {
+	selector, err := ParseLabelSelector("team=payments", "env=prod, tier=", "")
+	if err != nil {
+		t.Fatalf("ParseLabelSelector: %v", err)
+	}
+	want := map[string]string{"team": "payments", "env": "prod", "tier": ""}
+	if !reflect.DeepEqual(selector, want) {
+		t.Fatalf("selector = %v, want %v", selector, want)
+	}
+	if !MatchLabels(map[string]string{"team": "payments", "env": "prod", "tier": "", "x": "y"}, selector) {
+		t.Error("labels with every selected pair should match")
+	}
+	if MatchLabels(map[string]string{"team": "payments", "env": "prod"}, selector) {
+		t.Error("labels missing a selected key should not match")
+	}
+	for _, bad := range []string{"team", "=payments", "team=a,team=b"} {
+		if _, err := ParseLabelSelector(bad); err == nil {
+			t.Errorf("ParseLabelSelector(%q) succeeded", bad)
+		}
+	}
+}
+
 func TestSpec_DeepCopy(t *testing.T) {
 	original := &Spec{
 		Name:    "test-process",
@@ -412,3 +650,43 @@ func TestParseExplicitShell(t *testing.T) {
 		})
 	}
 }
+
+func TestSpec_ResolveCommand(t *testing.T) {
+	requireUnixSpec(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "worker"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		spec    Spec
+		missing string
+	}{
+		{name: "program on PATH", spec: Spec{Command: "sleep 1"}},
+		{name: "args", spec: Spec{Args: []string{"sleep", "1"}}},
+		{name: "missing binary", spec: Spec{Command: "provisr-no-such-binary --flag"}, missing: "provisr-no-such-binary"},
+		{name: "missing args binary", spec: Spec{Args: []string{"/nonexistent/worker"}}, missing: "/nonexistent/worker"},
+		{name: "missing binary in sh -c", spec: Spec{Command: "sh -c 'provisr-no-such-binary | cat'"}, missing: "provisr-no-such-binary"},
+		{name: "shell builtin", spec: Spec{Command: "sh -c 'cd /tmp && exec sleep 1'"}},
+		{name: "builtin with metacharacters", spec: Spec{Command: "export A=1; sleep 1"}},
+		{name: "assignment prefix", spec: Spec{Command: "A=1 sleep 1; true"}},
+		{name: "relative to work_dir", spec: Spec{Command: "./worker", WorkDir: dir}},
+		{name: "missing relative to work_dir", spec: Spec{Command: "./absent", WorkDir: dir}, missing: filepath.Join(dir, "absent")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Name = "svc"
+			err := tt.spec.ResolveCommand()
+			if tt.missing == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "command not found: "+tt.missing) {
+				t.Fatalf("expected command not found for %s, got %v", tt.missing, err)
+			}
+		})
+	}
+}
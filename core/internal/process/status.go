@@ -1,17 +1,116 @@
 package process
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Status mirrors process.Status to avoid import cycle; kept minimal for internal use.
 type Status struct {
-	Name        string    `json:"name"`
-	Running     bool      `json:"running"`
-	PID         int       `json:"pid"`
-	StartedAt   time.Time `json:"started_at"`
-	StoppedAt   time.Time `json:"stopped_at"`
-	ExitErr     error     `json:"exit_error,omitempty"`
-	DetectedBy  string    `json:"detected_by"`
-	Restarts    uint32    `json:"restarts"`
-	State       string    `json:"state"`       // State machine state: stopped, starting, running, stopping
-	Provisioned bool      `json:"provisioned"` // declared in the main config file's [[processes]] array; see Spec.InlineConfig
+	Name        string        `json:"name"`
+	Running     bool          `json:"running"`
+	PID         int           `json:"pid"`
+	StartedAt   time.Time     `json:"started_at"`
+	StoppedAt   time.Time     `json:"stopped_at"`
+	Uptime      time.Duration `json:"uptime"` // time since StartedAt while running (nanoseconds in JSON); 0 otherwise
+	ExitErr     error         `json:"exit_error,omitempty"`
+	DetectedBy  string        `json:"detected_by"`
+	Restarts    uint32        `json:"restarts"`
+	State       string        `json:"state"`       // State machine state: stopped, starting, running, stopping
+	Provisioned bool          `json:"provisioned"` // declared in the main config file's [[processes]] array; see Spec.InlineConfig
+}
+
+// StatusFields are the JSON names of the Status fields, in struct order.
+var StatusFields = []string{
+	"name", "running", "pid", "started_at", "stopped_at", "uptime",
+	"exit_error", "detected_by", "restarts", "state", "provisioned",
+}
+
+// ParseStatusFields parses a comma-separated list of StatusFields, as taken
+// by Status.Project. Duplicates are dropped; an empty list selects none.
+func ParseStatusFields(list string) ([]string, error) {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(list, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" || seen[field] {
+			continue
+		}
+		if _, ok := (Status{}).field(field); !ok {
+			return nil, fmt.Errorf("unknown status field %q (known: %s)", field, strings.Join(StatusFields, ", "))
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Project returns the named fields of s keyed by their JSON names, for
+// encoding a trimmed status; no fields means all of them. omitEmpty drops
+// fields holding their zero value. Unknown names are ignored, so validate
+// them with ParseStatusFields. exit_error is the error's message.
+func (s Status) Project(fields []string, omitEmpty bool) map[string]any {
+	if len(fields) == 0 {
+		fields = StatusFields
+	}
+	out := make(map[string]any, len(fields))
+	for _, name := range fields {
+		value, ok := s.field(name)
+		if !ok || (omitEmpty && isZeroStatusValue(value)) {
+			continue
+		}
+		out[name] = value
+	}
+	return out
+}
+
+// field returns the value Project encodes for the field with JSON name.
+func (s Status) field(name string) (any, bool) {
+	switch name {
+	case "name":
+		return s.Name, true
+	case "running":
+		return s.Running, true
+	case "pid":
+		return s.PID, true
+	case "started_at":
+		return s.StartedAt, true
+	case "stopped_at":
+		return s.StoppedAt, true
+	case "uptime":
+		return s.Uptime, true
+	case "exit_error":
+		if s.ExitErr == nil {
+			return "", true
+		}
+		return s.ExitErr.Error(), true
+	case "detected_by":
+		return s.DetectedBy, true
+	case "restarts":
+		return s.Restarts, true
+	case "state":
+		return s.State, true
+	case "provisioned":
+		return s.Provisioned, true
+	}
+	return nil, false
+}
+
+func isZeroStatusValue(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case uint32:
+		return v == 0
+	case time.Duration:
+		return v == 0
+	case time.Time:
+		return v.IsZero()
+	}
+	return false
 }
@@ -0,0 +1,48 @@
+package process
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseStatusFields(t *testing.T) {
+	fields, err := ParseStatusFields(" name, PID,,name ,state")
+	if err != nil {
+		t.Fatalf("ParseStatusFields: %v", err)
+	}
+	if want := []string{"name", "pid", "state"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("fields = %v, want %v", fields, want)
+	}
+	if fields, err := ParseStatusFields(""); err != nil || len(fields) != 0 {
+		t.Fatalf("empty list = %v, %v", fields, err)
+	}
+	if _, err := ParseStatusFields("name,spec"); err == nil {
+		t.Fatal("expected an error for an unknown field")
+	}
+	for _, field := range StatusFields {
+		if _, ok := (Status{}).field(field); !ok {
+			t.Errorf("StatusFields lists %q, which Project does not know", field)
+		}
+	}
+}
+
+func TestStatusProject(t *testing.T) {
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	st := Status{Name: "web", Running: true, PID: 42, StartedAt: started, ExitErr: errors.New("boom")}
+
+	got := st.Project([]string{"name", "pid", "restarts"}, false)
+	if want := map[string]any{"name": "web", "pid": 42, "restarts": uint32(0)}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Project = %v, want %v", got, want)
+	}
+
+	got = st.Project(nil, true)
+	want := map[string]any{"name": "web", "running": true, "pid": 42, "started_at": started, "exit_error": "boom"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Project(all, omitEmpty) = %v, want %v", got, want)
+	}
+	if all := st.Project(nil, false); len(all) != len(StatusFields) {
+		t.Fatalf("Project(all) has %d fields, want %d", len(all), len(StatusFields))
+	}
+}
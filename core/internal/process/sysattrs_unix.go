@@ -11,6 +11,8 @@ import (
 // If spec.Detached is true, we create a new session (setsid) so the child is
 // detached from the controlling terminal and survives parent exit cleanly.
 // Otherwise, we place it in a new process group for signal handling.
+// User/Group are applied as a credential; if they can't be resolved the
+// error is stored in cmd.Err so that cmd.Start fails with it.
 func configureSysProcAttr(cmd *exec.Cmd, spec Spec) {
 	attrs := &syscall.SysProcAttr{}
 	if spec.Detached {
@@ -18,5 +20,10 @@ func configureSysProcAttr(cmd *exec.Cmd, spec Spec) {
 	} else {
 		attrs.Setpgid = true // create a new process group for group signaling
 	}
+	cred, err := resolveCredential(spec)
+	if err != nil {
+		cmd.Err = err
+	}
+	attrs.Credential = cred
 	cmd.SysProcAttr = attrs
 }
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package process
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// checkUmaskSupport reports whether Spec.Umask can be applied here.
+func checkUmaskSupport() error { return nil }
+
+// applyUmask re-execs the command through /bin/sh so the umask is set in
+// the child right before exec. The daemon's own umask is process-wide and
+// can't be changed for the duration of a fork without affecting files other
+// goroutines create at the same time. The program and its arguments reach
+// the shell as "$@", so they are not interpreted; Validate still rejects
+// umask for args and shell = false, which promise no shell at all.
+func applyUmask(cmd *exec.Cmd, spec Spec) {
+	if spec.Umask == "" {
+		return
+	}
+	mask, err := ParseUmask(spec.Umask)
+	if err != nil {
+		cmd.Err = err
+		return
+	}
+	args := []string{"/bin/sh", "-c", fmt.Sprintf(`umask %03o && exec "$@"`, mask), "sh", cmd.Path}
+	cmd.Path = "/bin/sh"
+	cmd.Args = append(args, cmd.Args[1:]...)
+}
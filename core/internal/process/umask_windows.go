@@ -0,0 +1,13 @@
+//go:build windows
+
+package process
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// checkUmaskSupport reports whether Spec.Umask can be applied here.
+func checkUmaskSupport() error { return errors.New("umask is not supported on Windows") }
+
+func applyUmask(*exec.Cmd, Spec) {}
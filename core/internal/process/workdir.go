@@ -0,0 +1,81 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultWorkDirMode is the permission of a WorkDir created without
+// WorkDirMode.
+const defaultWorkDirMode = 0o755
+
+// validateWorkDir checks the work_dir settings used by EnsureWorkDir.
+func (s *Spec) validateWorkDir() error {
+	if s.WorkDirMode != "" {
+		if !s.CreateWorkDir {
+			return fmt.Errorf("work_dir_mode requires create_work_dir")
+		}
+		if _, err := parseWorkDirMode(s.WorkDirMode); err != nil {
+			return err
+		}
+	}
+	if !s.CreateWorkDir {
+		return nil
+	}
+	workDir := strings.TrimSpace(s.WorkDir)
+	if workDir == "" {
+		return fmt.Errorf("create_work_dir requires work_dir")
+	}
+	for _, elem := range strings.FieldsFunc(filepath.ToSlash(workDir), func(r rune) bool { return r == '/' }) {
+		if elem == ".." {
+			return fmt.Errorf("work_dir %q cannot contain '..' path traversal when create_work_dir is set", s.WorkDir)
+		}
+	}
+	return nil
+}
+
+func parseWorkDirMode(s string) (os.FileMode, error) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O"), 8, 32)
+	if err != nil || v > 0o777 {
+		return 0, fmt.Errorf("invalid work_dir_mode %q: must be an octal value between 000 and 777", s)
+	}
+	return os.FileMode(v), nil
+}
+
+// EnsureWorkDir creates spec.WorkDir, including missing parents, when
+// CreateWorkDir is set and it does not exist yet. An existing directory is
+// left as is; an existing file at that path is an error.
+func EnsureWorkDir(spec Spec) error {
+	if !spec.CreateWorkDir || spec.WorkDir == "" {
+		return nil
+	}
+	info, err := os.Stat(spec.WorkDir)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("work_dir %s exists and is not a directory", spec.WorkDir)
+		}
+		return nil
+	}
+	mode := os.FileMode(defaultWorkDirMode)
+	if spec.WorkDirMode != "" {
+		if mode, err = parseWorkDirMode(spec.WorkDirMode); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(spec.WorkDir, mode); err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("cannot create work_dir %s: permission denied (create it ahead of time or run the daemon as a user allowed to): %w", spec.WorkDir, err)
+		}
+		return fmt.Errorf("failed to create work_dir %s: %w", spec.WorkDir, err)
+	}
+	// MkdirAll is subject to the daemon's umask; apply the mode as given
+	if err := os.Chmod(spec.WorkDir, mode); err != nil {
+		return fmt.Errorf("failed to set permissions on work_dir %s: %w", spec.WorkDir, err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEnsureWorkDir(t *testing.T) {
+	base := t.TempDir()
+	dir := filepath.Join(base, "a", "b")
+
+	// Not requested: nothing is created.
+	if err := EnsureWorkDir(Spec{Name: "p", WorkDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("work dir created without create_work_dir: %v", err)
+	}
+
+	spec := Spec{Name: "p", WorkDir: dir, CreateWorkDir: true}
+	if err := EnsureWorkDir(spec); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("work dir not created: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != defaultWorkDirMode {
+		t.Fatalf("unexpected mode %v", info.Mode().Perm())
+	}
+	// Existing directory is fine.
+	if err := EnsureWorkDir(spec); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(base, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err = EnsureWorkDir(Spec{Name: "p", WorkDir: file, CreateWorkDir: true})
+	if err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Fatalf("expected not a directory error, got %v", err)
+	}
+}
+
+func TestEnsureWorkDirPermissionDenied(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("needs a non-root Unix user")
+	}
+	parent := filepath.Join(t.TempDir(), "ro")
+	if err := os.Mkdir(parent, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	err := EnsureWorkDir(Spec{Name: "p", WorkDir: filepath.Join(parent, "work"), CreateWorkDir: true})
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("expected permission denied error, got %v", err)
+	}
+}
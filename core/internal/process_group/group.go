@@ -1,7 +1,9 @@
 package process_group
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/loykin/provisr/core/internal/manager"
@@ -25,25 +27,47 @@ type Group struct {
 
 func New(mgr *manager.Manager) *Group { return &Group{mgr: mgr} }
 
-// Start starts all members. If any start fails, it stops any members that
-// have already been started in this call and returns the error.
+// Start starts all members in declaration order. If any start fails, it
+// stops any members that have already been started in this call and
+// returns the error, joined with any rollback failures.
 func (g *Group) Start(gs ServiceGroup) error {
-	started := make([]process.Spec, 0, len(gs.Members))
-	for _, m := range gs.Members {
+	return g.startAll(gs.Name, gs.Members)
+}
+
+// StartAtomic starts members in dependency order (ascending Priority, ties
+// keep declaration order) with all-or-nothing semantics: if any member fails
+// to start, every member already started by this call is stopped in reverse
+// order. The returned error joins the start failure with any rollback
+// failures, so a member that could not be stopped is not silently left up.
+func (g *Group) StartAtomic(gs ServiceGroup) error {
+	members := make([]process.Spec, len(gs.Members))
+	copy(members, gs.Members)
+	sort.SliceStable(members, func(i, j int) bool { return members[i].Priority < members[j].Priority })
+	return g.startAll(gs.Name, members)
+}
+
+// startAll starts members in order and, when one fails, stops those it
+// started in reverse order.
+func (g *Group) startAll(group string, members []process.Spec) error {
+	started := make([]process.Spec, 0, len(members))
+	for _, m := range members {
 		var err error
 		if m.Instances > 1 {
 			err = g.mgr.RegisterN(m)
 		} else {
 			err = g.mgr.Register(m)
 		}
-		if err != nil {
-			// rollback: stop previously started members
-			for i := len(started) - 1; i >= 0; i-- {
-				_ = g.mgr.StopAll(started[i].Name, 2*time.Second)
+		if err == nil {
+			started = append(started, m)
+			continue
+		}
+		errs := []error{fmt.Errorf("group %s start failed on %s: %w", group, m.Name, err)}
+		for i := len(started) - 1; i >= 0; i-- {
+			if stopErr := g.mgr.StopAll(started[i].Name, 2*time.Second); stopErr != nil {
+				errs = append(errs, fmt.Errorf("group %s rollback of %s failed: %w", group, started[i].Name, stopErr))
 			}
-			return fmt.Errorf("group %s start failed on %s: %w", gs.Name, m.Name, err)
 		}
-		started = append(started, m)
+		return errors.Join(errs...)
 	}
 	return nil
 }
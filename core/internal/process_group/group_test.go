@@ -2,6 +2,7 @@ package process_group
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -73,6 +74,64 @@ func TestGroupRollbackOnFailure(t *testing.T) {
 	}
 }
 
+func TestGroupStartAtomicRollsBackOnInvalidCommand(t *testing.T) {
+	mgr := mgrpkg.NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	g := New(mgr)
+	gs := ServiceGroup{
+		Name: "grp-atomic",
+		Members: []process.Spec{
+			{Name: "db", Command: "sleep 5", Instances: 2},
+			{Name: "api", Command: "/nonexistent/provisr-test-binary", Priority: 1},
+			{Name: "never", Command: "sleep 5", Priority: 2},
+		},
+	}
+
+	err := g.StartAtomic(gs)
+	if err == nil {
+		t.Fatalf("expected error starting group with invalid member")
+	}
+	if !strings.Contains(err.Error(), "api") {
+		t.Fatalf("error should name the failing member, got: %v", err)
+	}
+	if cnt, _ := mgr.Count("db"); cnt != 0 {
+		t.Fatalf("expected db instances stopped by rollback, got %d running", cnt)
+	}
+	if sts, _ := mgr.StatusAll("never"); len(sts) != 0 {
+		t.Fatalf("member after the failure should not have been started: %v", toJSON(sts))
+	}
+}
+
+func TestGroupStartAtomicOrdersByPriority(t *testing.T) {
+	mgr := mgrpkg.NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	g := New(mgr)
+	gs := ServiceGroup{
+		Name: "grp-order",
+		Members: []process.Spec{
+			{Name: "web", Command: "sleep 5", Priority: 10},
+			{Name: "bad", Command: "/nonexistent/provisr-test-binary", Priority: 5},
+			{Name: "db", Command: "sleep 5", Priority: 0},
+		},
+	}
+
+	if err := g.StartAtomic(gs); err == nil {
+		t.Fatalf("expected error from bad member")
+	}
+	if sts, _ := mgr.StatusAll("web"); len(sts) != 0 {
+		t.Fatalf("web (priority 10) should not start before bad (priority 5): %v", toJSON(sts))
+	}
+	sts, _ := mgr.StatusAll("db")
+	if len(sts) == 0 {
+		t.Fatalf("db (priority 0) should have been started before the failure")
+	}
+	for _, st := range sts {
+		if st.Running {
+			t.Fatalf("db should be stopped by rollback")
+		}
+	}
+}
+
 func TestGroupWithInstances(t *testing.T) {
 	mgr := mgrpkg.NewManager()
 	g := New(mgr)
@@ -118,4 +177,111 @@ func TestGroupWithInstances(t *testing.T) {
 	}
 }
 
+func TestGroupRollingRestartKeepsInstancesUp(t *testing.T) {
+	mgr := mgrpkg.NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	g := New(mgr)
+	gs := ServiceGroup{
+		Name: "rolling",
+		Members: []process.Spec{
+			{Name: "web", Command: "sleep 30", Instances: 3, StartDuration: 200 * time.Millisecond},
+			{Name: "cache", Command: "sleep 30"},
+		},
+	}
+	if err := g.Start(gs); err != nil {
+		t.Fatalf("start group: %v", err)
+	}
+	before := map[string]int{}
+	for _, sts := range mustStatus(t, g, gs) {
+		for _, st := range sts {
+			before[st.Name] = st.PID
+		}
+	}
+
+	// Sample the running instance count while the rollout is in progress.
+	for _, tc := range []struct {
+		maxUnavailable int
+		minRunning     int
+	}{
+		{maxUnavailable: 1, minRunning: 3},
+		{maxUnavailable: 10, minRunning: 1}, // capped so one instance stays up
+	} {
+		done := make(chan struct{})
+		lowest := make(chan int, 1)
+		go func() {
+			low := len(before)
+			for {
+				select {
+				case <-done:
+					lowest <- low
+					return
+				default:
+				}
+				webs, _ := mgr.Count("web")
+				caches, _ := mgr.Count("cache")
+				low = min(low, webs+caches)
+				time.Sleep(5 * time.Millisecond)
+			}
+		}()
+		results, err := g.RollingRestart(gs, RollingRestartOptions{MaxUnavailable: tc.maxUnavailable, Wait: time.Second})
+		close(done)
+		if err != nil {
+			t.Fatalf("rolling restart (max unavailable %d): %v", tc.maxUnavailable, err)
+		}
+		if low := <-lowest; low < tc.minRunning {
+			t.Fatalf("max unavailable %d: only %d of %d instances were running at some point", tc.maxUnavailable, low, len(before))
+		}
+		if len(results) != len(before) {
+			t.Fatalf("expected %d results, got %+v", len(before), results)
+		}
+		for _, res := range results {
+			if res.Err != nil || res.PID == 0 || res.PID == before[res.Name] {
+				t.Fatalf("%s not restarted: %+v (old pid %d)", res.Name, res, before[res.Name])
+			}
+			before[res.Name] = res.PID
+		}
+	}
+}
+
+func TestGroupRollingRestartStopsOnFailure(t *testing.T) {
+	mgr := mgrpkg.NewManager()
+	defer func() { _ = mgr.Shutdown() }()
+	g := New(mgr)
+	gs := ServiceGroup{
+		Name: "rolling-fail",
+		Members: []process.Spec{
+			{Name: "first", Command: "sleep 30"},
+			{Name: "second", Command: "sleep 30"},
+		},
+	}
+	if err := g.Start(gs); err != nil {
+		t.Fatalf("start group: %v", err)
+	}
+	second, _ := mgr.Status("second")
+	// first now exits right away, so its restart fails and second is kept.
+	if err := mgr.Update(process.Spec{Name: "first", Command: "false", StartDuration: 100 * time.Millisecond}, time.Second); err == nil {
+		t.Fatal("expected update to a failing command to fail")
+	}
+
+	results, err := g.RollingRestart(gs, RollingRestartOptions{})
+	if err == nil || !strings.Contains(err.Error(), "first") {
+		t.Fatalf("expected rollout to stop at first, got %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "first" || results[0].Err == nil {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if st, _ := mgr.Status("second"); !st.Running || st.PID != second.PID {
+		t.Fatalf("second should be untouched: %+v", st)
+	}
+}
+
+func mustStatus(t *testing.T, g *Group, gs ServiceGroup) map[string][]process.Status {
+	t.Helper()
+	stmap, err := g.Status(gs)
+	if err != nil {
+		t.Fatalf("status group: %v", err)
+	}
+	return stmap
+}
+
 func toJSON(v any) string { b, _ := json.Marshal(v); return string(b) }
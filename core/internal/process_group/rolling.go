@@ -0,0 +1,113 @@
+package process_group
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RollingRestartOptions tunes Group.RollingRestart. Zero values pick the
+// defaults.
+type RollingRestartOptions struct {
+	// MaxUnavailable is how many instances are restarted at once (default
+	// 1). It is capped so that at least one instance of the group keeps
+	// running while the others restart.
+	MaxUnavailable int
+	// Wait bounds the graceful stop of each instance (default 2s).
+	Wait time.Duration
+	// ReadyTimeout bounds how long a restarted instance may take to be
+	// reported running again before the rollout is aborted (default 30s).
+	ReadyTimeout time.Duration
+}
+
+// RestartResult is the outcome of restarting one instance.
+type RestartResult struct {
+	Name string
+	PID  int // of the restarted instance
+	Err  error
+}
+
+// readyPollInterval is how often RollingRestart checks a restarted instance.
+const readyPollInterval = 50 * time.Millisecond
+
+// RollingRestart restarts the instances of every member one batch of
+// opts.MaxUnavailable at a time, in member order, and waits for each batch
+// to be running again before moving on, so the group keeps serving
+// throughout. Instances that weren't running are just started. The first
+// batch with a failure stops the rollout: its results are returned along
+// with an error, and later instances are left untouched.
+func (g *Group) RollingRestart(gs ServiceGroup, opts RollingRestartOptions) ([]RestartResult, error) {
+	if opts.Wait <= 0 {
+		opts.Wait = 2 * time.Second
+	}
+	if opts.ReadyTimeout <= 0 {
+		opts.ReadyTimeout = 30 * time.Second
+	}
+
+	var names []string
+	for _, m := range gs.Members {
+		sts, err := g.mgr.StatusAll(m.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range sts {
+			names = append(names, st.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("group %s has no registered instances", gs.Name)
+	}
+
+	batch := opts.MaxUnavailable
+	if batch < 1 {
+		batch = 1
+	}
+	if len(names) > 1 && batch >= len(names) {
+		batch = len(names) - 1
+	}
+
+	results := make([]RestartResult, 0, len(names))
+	for start := 0; start < len(names); start += batch {
+		end := min(start+batch, len(names))
+		batchResults := make([]RestartResult, end-start)
+		var wg sync.WaitGroup
+		for i, name := range names[start:end] {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				batchResults[i] = g.restartInstance(name, opts)
+			}()
+		}
+		wg.Wait()
+
+		results = append(results, batchResults...)
+		for _, res := range batchResults {
+			if res.Err != nil {
+				return results, fmt.Errorf("group %s rolling restart stopped at %s: %w", gs.Name, res.Name, res.Err)
+			}
+		}
+	}
+	return results, nil
+}
+
+// restartInstance restarts name and waits until it is reported running.
+func (g *Group) restartInstance(name string, opts RollingRestartOptions) RestartResult {
+	if err := g.mgr.RestartContext(context.Background(), name, opts.Wait); err != nil {
+		return RestartResult{Name: name, Err: err}
+	}
+	deadline := time.Now().Add(opts.ReadyTimeout)
+	for {
+		st, err := g.mgr.Status(name)
+		if err != nil {
+			return RestartResult{Name: name, Err: err}
+		}
+		if st.Running {
+			return RestartResult{Name: name, PID: st.PID}
+		}
+		if time.Now().After(deadline) {
+			return RestartResult{Name: name, Err: fmt.Errorf("not running %s after restart", opts.ReadyTimeout)}
+		}
+		time.Sleep(readyPollInterval)
+	}
+}
@@ -11,6 +11,7 @@ const (
 	ProcessStarted       Kind = "process.started"
 	ProcessStopped       Kind = "process.stopped"
 	ProcessStateChanged  Kind = "process.state_changed"
+	HookCompleted        Kind = "hook.completed"
 	JobStarted           Kind = "job.started"
 	JobDeleted           Kind = "job.deleted"
 	CronJobActivated     Kind = "cronjob.activated"
@@ -41,8 +42,12 @@ func (f ObserverFunc) Observe(event Event) { f(event) }
 type Emitter struct {
 	mu        sync.RWMutex
 	observers []Observer
+	subs      map[*subscription]struct{}
+	tracer    Tracer
 }
 
+type subscription struct{ observer Observer }
+
 func NewEmitter(observers ...Observer) *Emitter {
 	emitter := &Emitter{}
 	emitter.SetObservers(observers...)
@@ -55,12 +60,32 @@ func (e *Emitter) SetObservers(observers ...Observer) {
 	e.mu.Unlock()
 }
 
+// Subscribe adds observer next to the ones given to SetObservers, which
+// leaves it in place, until the returned function is called.
+func (e *Emitter) Subscribe(observer Observer) func() {
+	sub := &subscription{observer: observer}
+	e.mu.Lock()
+	if e.subs == nil {
+		e.subs = make(map[*subscription]struct{})
+	}
+	e.subs[sub] = struct{}{}
+	e.mu.Unlock()
+	return func() {
+		e.mu.Lock()
+		delete(e.subs, sub)
+		e.mu.Unlock()
+	}
+}
+
 func (e *Emitter) Emit(event Event) {
 	if e == nil {
 		return
 	}
 	e.mu.RLock()
 	observers := append([]Observer(nil), e.observers...)
+	for sub := range e.subs {
+		observers = append(observers, sub.observer)
+	}
 	e.mu.RUnlock()
 	for _, observer := range observers {
 		if observer != nil {
@@ -0,0 +1,72 @@
+package observability
+
+import "context"
+
+// Attr is a span attribute. Value should be a string, bool, int, int64 or
+// float64; adapters may stringify anything else.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attr    { return Attr{Key: key, Value: value} }
+func Int(key string, value int) Attr   { return Attr{Key: key, Value: value} }
+func Bool(key string, value bool) Attr { return Attr{Key: key, Value: value} }
+
+// Span is a unit of traced work started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attr)
+	AddEvent(name string, attrs ...Attr)
+	RecordError(err error)
+	End()
+}
+
+// Tracer is the outbound tracing port used by the process core. Adapters
+// map it onto OpenTelemetry or another tracing system; NoopTracer is used
+// when none is configured.
+type Tracer interface {
+	// Start begins a span as a child of any span carried by ctx and returns
+	// a context carrying the new span.
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+	// SpanFromContext returns the span carried by ctx, or a no-op span.
+	SpanFromContext(ctx context.Context) Span
+}
+
+// NoopTracer discards all spans.
+var NoopTracer Tracer = noopTracer{}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attr) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+func (noopTracer) SpanFromContext(context.Context) Span { return noopSpan{} }
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attr)    {}
+func (noopSpan) AddEvent(string, ...Attr) {}
+func (noopSpan) RecordError(error)        {}
+func (noopSpan) End()                     {}
+
+// SetTracer installs the tracer used by everything sharing this emitter.
+// A nil tracer restores NoopTracer.
+func (e *Emitter) SetTracer(t Tracer) {
+	e.mu.Lock()
+	e.tracer = t
+	e.mu.Unlock()
+}
+
+// Tracer returns the configured tracer, or NoopTracer.
+func (e *Emitter) Tracer() Tracer {
+	if e == nil {
+		return NoopTracer
+	}
+	e.mu.RLock()
+	t := e.tracer
+	e.mu.RUnlock()
+	if t == nil {
+		return NoopTracer
+	}
+	return t
+}
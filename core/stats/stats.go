@@ -5,6 +5,8 @@ package stats
 import (
 	"context"
 	"time"
+
+	"github.com/loykin/provisr/core/instancename"
 )
 
 type ProcessMetrics struct {
@@ -28,3 +30,17 @@ type Collector interface {
 	GetHistory(string) ([]ProcessMetrics, bool)
 	GetAllMetrics() map[string]ProcessMetrics
 }
+
+// Forgetter is implemented by collectors that can drop the series of a
+// process right away, such as an instance removed by scaling down, instead
+// of on their next collection.
+type Forgetter interface {
+	Forget(name string)
+}
+
+// InstanceNamer is implemented by collectors that label series by base
+// process and instance, so they parse instance names with the manager's
+// scheme.
+type InstanceNamer interface {
+	SetInstanceNaming(*instancename.Scheme)
+}
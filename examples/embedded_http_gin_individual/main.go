@@ -37,13 +37,11 @@ func loggingMiddleware() gin.HandlerFunc {
 	}
 }
 
-// Rate limiting middleware (example)
+// Rate limiting middleware: 5 requests/second per client IP, bursts of 10.
+var rateLimiter = provisr.NewRateLimiter(provisr.RateLimitConfig{RequestsPerSecond: 5, Burst: 10})
+
 func rateLimitMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Simple rate limiting logic (in production, use proper rate limiting)
-		log.Printf("Rate limit middleware: Processing %s %s", c.Request.Method, c.Request.URL.Path)
-		c.Next()
-	}
+	return rateLimiter.Middleware()
 }
 
 func main() {
@@ -58,6 +58,8 @@ func devExample() {
 	if tlsConfig.AutoGen != nil {
 		fmt.Printf("  - Common Name: %s\n", tlsConfig.AutoGen.CommonName)
 		fmt.Printf("  - DNS Names: %v\n", tlsConfig.AutoGen.DNSNames)
+		fmt.Printf("  - IP Addresses: %v\n", tlsConfig.AutoGen.IPAddresses)
+		fmt.Printf("  - Key Type: %s\n", tlsConfig.AutoGen.KeyType)
 		fmt.Printf("  - Valid Days: %d days\n", tlsConfig.AutoGen.ValidDays)
 	}
 
@@ -0,0 +1,116 @@
+// Package audit records who did what through the control plane: every
+// mutating API call and every authentication event. With the hash chain
+// enabled, each entry carries the hash of the one before it, so editing or
+// removing a past entry breaks the chain and Verify reports where.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/loykin/provisr/internal/auth/store"
+)
+
+// Entry is one audit record.
+type Entry = store.AuditEntry
+
+// Results recorded in Entry.Result.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Sink persists entries in the order they are recorded.
+type Sink interface {
+	Append(ctx context.Context, entry *Entry) error
+	// Last returns the newest persisted entry, or nil when there is none.
+	Last(ctx context.Context) (*Entry, error)
+	Close() error
+}
+
+// Logger records entries to a sink, chaining their hashes when enabled.
+type Logger struct {
+	mu        sync.Mutex
+	sink      Sink
+	hashChain bool
+	lastHash  string
+}
+
+// New returns a Logger writing to sink. With hashChain, the chain continues
+// from the newest entry already in the sink.
+func New(sink Sink, hashChain bool) (*Logger, error) {
+	l := &Logger{sink: sink, hashChain: hashChain}
+	if hashChain {
+		last, err := sink.Last(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("read last audit entry: %w", err)
+		}
+		if last != nil {
+			l.lastHash = last.Hash
+		}
+	}
+	return l, nil
+}
+
+// Record stamps entry with the current time when it has none, links it to
+// the previous entry when the hash chain is enabled, and persists it.
+func (l *Logger) Record(ctx context.Context, entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+	entry.PrevHash, entry.Hash = "", ""
+	if l.hashChain {
+		entry.PrevHash = l.lastHash
+		entry.Hash = Hash(entry)
+	}
+	if err := l.sink.Append(ctx, &entry); err != nil {
+		return err
+	}
+	if l.hashChain {
+		l.lastHash = entry.Hash
+	}
+	return nil
+}
+
+// Close closes the sink.
+func (l *Logger) Close() error { return l.sink.Close() }
+
+// Hash returns the chain hash of entry: SHA-256 over its JSON encoding
+// without the Hash field, which includes PrevHash.
+func Hash(entry Entry) string {
+	entry.Hash = ""
+	data, _ := json.Marshal(entry)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks the hash chain of entries, oldest first. It reports the
+// first entry whose hash does not match its content or whose PrevHash does
+// not match the entry before it.
+func Verify(entries []*Entry) error {
+	prev := ""
+	for i, entry := range entries {
+		if entry.Hash == "" {
+			return fmt.Errorf("audit entry %d has no hash; was the hash chain enabled?", i+1)
+		}
+		if entry.PrevHash != prev {
+			if i == 0 {
+				return fmt.Errorf("audit entry 1 links to an earlier entry that is missing")
+			}
+			return fmt.Errorf("audit entry %d: previous hash does not match entry %d", i+1, i)
+		}
+		if Hash(*entry) != entry.Hash {
+			return fmt.Errorf("audit entry %d: hash does not match its content", i+1)
+		}
+		prev = entry.Hash
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFileLogHashChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	record := func(actions ...string) {
+		t.Helper()
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger, err := New(sink, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = logger.Close() }()
+		for _, action := range actions {
+			if err := logger.Record(context.Background(), Entry{Actor: "alice", Action: action, Result: ResultSuccess}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// A reopened log continues the chain of the entries already in it.
+	record("POST /start", "POST /stop")
+	record("DELETE /jobs/:name")
+
+	entries, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if err := Verify(entries); err != nil {
+		t.Fatalf("untouched log: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+			t.Fatalf("audit log mode = %v, %v; want 0600", info.Mode().Perm(), err)
+		}
+	}
+
+	edited := *entries[1]
+	edited.Actor = "mallory"
+	for name, tampered := range map[string][]*Entry{
+		"edited":       {entries[0], &edited, entries[2]},
+		"removed":      {entries[0], entries[2]},
+		"head removed": {entries[1], entries[2]},
+	} {
+		if err := Verify(tampered); err == nil {
+			t.Errorf("%s entry not detected", name)
+		}
+	}
+}
+
+func TestVerifyRejectsUnchainedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := New(sink, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Record(context.Background(), Entry{Actor: "alice", Action: "POST /start", Result: ResultSuccess}); err != nil {
+		t.Fatal(err)
+	}
+	_ = logger.Close()
+
+	entries, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Hash != "" || entries[0].Time.IsZero() {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if err := Verify(entries); err == nil || !strings.Contains(err.Error(), "no hash") {
+		t.Fatalf("expected an unchained log to fail verification, got %v", err)
+	}
+}
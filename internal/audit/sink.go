@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/loykin/provisr/internal/auth/store"
+)
+
+// FileSink appends entries to a file as JSON lines.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// NewFileSink opens path for appending, creating it and its directory when
+// missing. The file is readable by its owner only.
+func NewFileSink(path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &FileSink{path: path, f: f}, nil
+}
+
+func (s *FileSink) Append(_ context.Context, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSink) Last(context.Context) (*Entry, error) {
+	entries, err := ReadFile(s.path)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return entries[len(entries)-1], nil
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// ReadFile reads the entries of a FileSink log, oldest first.
+func ReadFile(path string) ([]*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return readEntries(f)
+}
+
+func readEntries(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// StoreSink keeps entries in the auth store's audit_log table. The store
+// stays owned by the caller; Close does not close it.
+type StoreSink struct {
+	store store.AuditStore
+}
+
+// NewStoreSink returns a sink writing to s.
+func NewStoreSink(s store.AuditStore) *StoreSink { return &StoreSink{store: s} }
+
+func (s *StoreSink) Append(ctx context.Context, entry *Entry) error {
+	return s.store.AppendAuditEntry(ctx, entry)
+}
+
+func (s *StoreSink) Last(ctx context.Context) (*Entry, error) { return s.store.LastAuditEntry(ctx) }
+
+func (s *StoreSink) Close() error { return nil }
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 )
 
@@ -18,16 +19,11 @@ func NewCLIHelper(authService *AuthService) *CLIHelper {
 	}
 }
 
-// ListUsers lists all users
-func (cli *CLIHelper) ListUsers(ctx context.Context) error {
-	users, total, err := cli.authService.store.ListUsers(ctx, 0, 100)
-	if err != nil {
-		return fmt.Errorf("failed to list users: %w", err)
-	}
-
-	fmt.Printf("Users (%d total):\n", total)
-	fmt.Printf("%-20s %-30s %-20s %-10s %s\n", "ID", "Username", "Email", "Active", "Roles")
-	fmt.Printf("%s\n", "─────────────────────────────────────────────────────────────────────────────────")
+// WriteUsers prints users as a table headed by the total user count.
+func WriteUsers(w io.Writer, users []*User, total int) error {
+	fmt.Fprintf(w, "Users (%d total):\n", total)
+	fmt.Fprintf(w, "%-20s %-30s %-20s %-10s %s\n", "ID", "Username", "Email", "Active", "Roles")
+	fmt.Fprintf(w, "%s\n", "─────────────────────────────────────────────────────────────────────────────────")
 
 	for _, user := range users {
 		email := user.Email
@@ -36,7 +32,9 @@ func (cli *CLIHelper) ListUsers(ctx context.Context) error {
 		}
 
 		active := "Yes"
-		if !user.Active {
+		if user.DeletedAt != nil {
+			active = "Deleted"
+		} else if !user.Active {
 			active = "No"
 		}
 
@@ -45,43 +43,87 @@ func (cli *CLIHelper) ListUsers(ctx context.Context) error {
 			roles = "-"
 		}
 
-		fmt.Printf("%-20s %-30s %-20s %-10s %s\n",
-			user.ID, user.Username, email, active, roles)
+		if _, err := fmt.Fprintf(w, "%-20s %-30s %-20s %-10s %s\n",
+			user.ID, user.Username, email, active, roles); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// DeleteUser deletes a user by username or ID
-func (cli *CLIHelper) DeleteUser(ctx context.Context, identifier string) error {
-	// Try to get user by username first
-	user, err := cli.authService.store.GetUserByUsername(ctx, identifier)
+// findUser looks a user up by username, then by ID. GetUserByUsername only
+// sees active users, so inactive and deleted ones are matched by scanning
+// the full list.
+func (cli *CLIHelper) findUser(ctx context.Context, identifier string) (*User, error) {
+	if user, err := cli.authService.store.GetUserByUsername(ctx, identifier); err == nil {
+		return user, nil
+	}
+	if user, err := cli.authService.store.GetUser(ctx, identifier); err == nil {
+		return user, nil
+	}
+	users, _, err := cli.authService.store.ListUsers(ctx, UserFilter{IncludeDeleted: true}, 0, int(^uint(0)>>1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	for _, user := range users {
+		if user.Username == identifier {
+			return user, nil
+		}
+	}
+	return nil, fmt.Errorf("user not found: %s", identifier)
+}
+
+// DeleteUser soft-deletes a user by username or ID, or removes it
+// permanently when hard is set.
+func (cli *CLIHelper) DeleteUser(ctx context.Context, identifier string, hard bool) error {
+	user, err := cli.findUser(ctx, identifier)
 	if err != nil {
-		// Try by ID
-		user, err = cli.authService.store.GetUser(ctx, identifier)
-		if err != nil {
-			return fmt.Errorf("user not found: %s", identifier)
+		return err
+	}
+
+	if hard {
+		if err := cli.authService.PurgeUser(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
 		}
+		fmt.Printf("User '%s' deleted permanently\n", user.Username)
+		return nil
 	}
 
-	if err := cli.authService.store.DeleteUser(ctx, user.ID); err != nil {
+	if user.DeletedAt != nil {
+		return fmt.Errorf("user '%s' is already deleted; use --hard to remove it permanently", user.Username)
+	}
+	if err := cli.authService.DeleteUser(ctx, user.ID); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
-	fmt.Printf("User '%s' deleted successfully\n", user.Username)
+	fmt.Printf("User '%s' deleted successfully (restore with 'provisr auth user restore')\n", user.Username)
+	return nil
+}
+
+// RestoreUser reactivates a soft-deleted user by username or ID
+func (cli *CLIHelper) RestoreUser(ctx context.Context, identifier string) error {
+	user, err := cli.findUser(ctx, identifier)
+	if err != nil {
+		return err
+	}
+	if user.DeletedAt == nil {
+		return fmt.Errorf("user '%s' is not deleted", user.Username)
+	}
+
+	if _, err := cli.authService.RestoreUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	fmt.Printf("User '%s' restored successfully\n", user.Username)
 	return nil
 }
 
 // ResetUserPassword resets a user's password
 func (cli *CLIHelper) ResetUserPassword(ctx context.Context, identifier, newPassword string) error {
-	// Try to get user by username first
-	user, err := cli.authService.store.GetUserByUsername(ctx, identifier)
+	user, err := cli.findUser(ctx, identifier)
 	if err != nil {
-		// Try by ID
-		user, err = cli.authService.store.GetUser(ctx, identifier)
-		if err != nil {
-			return fmt.Errorf("user not found: %s", identifier)
-		}
+		return err
 	}
 
 	if err := cli.authService.UpdateUserPassword(ctx, user.ID, newPassword); err != nil {
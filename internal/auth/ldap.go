@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPConfig checks basic-auth passwords with an LDAP simple bind. Local
+// users remain a fallback when the bind fails or the server is down.
+type LDAPConfig struct {
+	Enabled bool `mapstructure:"enabled" toml:"enabled" yaml:"enabled" json:"enabled"`
+	// URL is ldaps://host[:636], or ldap://host[:389] with StartTLS or
+	// Insecure.
+	URL string `mapstructure:"url" toml:"url" yaml:"url" json:"url"`
+	// BindDN is the DN to bind as, with {username} replaced by the escaped
+	// username, e.g. "uid={username},ou=people,dc=example,dc=com".
+	BindDN string `mapstructure:"bind_dn" toml:"bind_dn" yaml:"bind_dn" json:"bind_dn"`
+	// Roles are granted to LDAP users without a local user of the same
+	// name; a local user's roles take precedence.
+	Roles []string `mapstructure:"roles" toml:"roles,omitempty" yaml:"roles,omitempty" json:"roles,omitempty"`
+	// StartTLS upgrades an ldap:// connection to TLS before the bind.
+	StartTLS bool `mapstructure:"start_tls" toml:"start_tls,omitempty" yaml:"start_tls,omitempty" json:"start_tls,omitempty"`
+	// Insecure allows binding over plain ldap://, which sends passwords in
+	// the clear.
+	Insecure bool `mapstructure:"insecure" toml:"insecure,omitempty" yaml:"insecure,omitempty" json:"insecure,omitempty"`
+	// CAFile verifies the server for ldaps and StartTLS; default the
+	// system roots.
+	CAFile string `mapstructure:"ca_file" toml:"ca_file,omitempty" yaml:"ca_file,omitempty" json:"ca_file,omitempty"`
+	// Timeout bounds the connection and the bind; default 10s.
+	Timeout time.Duration `mapstructure:"timeout" toml:"timeout,omitempty" yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// Validate checks the settings an enabled LDAPConfig needs.
+func (c LDAPConfig) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		return fmt.Errorf("url must be an ldap:// or ldaps:// URL, got %q", c.URL)
+	}
+	if u.Scheme == "ldaps" && c.StartTLS {
+		return fmt.Errorf("start_tls needs an ldap:// url")
+	}
+	if u.Scheme == "ldap" && !c.StartTLS && !c.Insecure {
+		return fmt.Errorf("ldap:// sends passwords in the clear; use ldaps://, start_tls or insecure")
+	}
+	if !strings.Contains(c.BindDN, "{username}") {
+		return fmt.Errorf("bind_dn must contain {username}")
+	}
+	if len(c.Roles) > 0 {
+		if err := ValidateRoles(c.Roles); err != nil {
+			return fmt.Errorf("roles: %w", err)
+		}
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	return nil
+}
+
+// ldapAuthenticator binds to the directory as the user to check a
+// password. It holds no connection between logins.
+type ldapAuthenticator struct {
+	cfg LDAPConfig
+	tls *tls.Config // for ldaps and StartTLS; nil for plain ldap
+}
+
+func newLDAPAuthenticator(cfg LDAPConfig) (*ldapAuthenticator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("ldap: %w", err)
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	u, _ := url.Parse(cfg.URL)
+	a := &ldapAuthenticator{cfg: cfg}
+	if u.Scheme == "ldaps" || cfg.StartTLS {
+		a.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("ldap: read ca_file: %w", err)
+			}
+			a.tls.RootCAs = x509.NewCertPool()
+			if !a.tls.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("ldap: no certificates in %s", cfg.CAFile)
+			}
+		}
+	}
+	return a, nil
+}
+
+// bind checks password for username. A rejected password is
+// ErrInvalidCredentials; any other error means the directory could not
+// answer.
+func (a *ldapAuthenticator) bind(ctx context.Context, username, password string) error {
+	// An empty password would be an unauthenticated bind, which servers
+	// accept for any DN.
+	if username == "" || password == "" {
+		return ErrInvalidCredentials
+	}
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.Timeout)
+	defer cancel()
+
+	opts := []ldap.DialOpt{ldap.DialWithDialer(&net.Dialer{Timeout: a.cfg.Timeout})}
+	if a.tls != nil && !a.cfg.StartTLS {
+		opts = append(opts, ldap.DialWithTLSConfig(a.tls))
+	}
+	conn, err := ldap.DialURL(a.cfg.URL, opts...)
+	if err != nil {
+		return fmt.Errorf("ldap: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	conn.SetTimeout(a.cfg.Timeout)
+	// Closing the connection fails a pending request at once.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	if a.cfg.StartTLS {
+		if err := conn.StartTLS(a.tls); err != nil {
+			return fmt.Errorf("ldap: %w", err)
+		}
+	}
+	dn := strings.ReplaceAll(a.cfg.BindDN, "{username}", escapeDNValue(username))
+	if err := conn.Bind(dn, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return ErrInvalidCredentials
+		}
+		return fmt.Errorf("ldap: %w", err)
+	}
+	// Best effort; the connection is closed either way.
+	_ = conn.Unbind()
+	return nil
+}
+
+// escapeDNValue escapes an attribute value for a DN (RFC 4514), so a
+// username cannot add RDNs to the bind DN.
+func escapeDNValue(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case strings.ContainsRune(`,+"\<>;=`, r),
+			i == 0 && (r == ' ' || r == '#'),
+			i == len(s)-1 && r == ' ':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == 0:
+			b.WriteString(`\00`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// authenticateLDAP logs in a user whose password the directory accepts.
+// A local user of the same name supplies the roles and must be active, not
+// deactivated or soft-deleted; without one the user gets LDAPConfig.Roles.
+func (s *AuthService) authenticateLDAP(ctx context.Context, username, password string) (*AuthResult, error) {
+	if err := s.ldap.bind(ctx, username, password); err != nil {
+		return nil, err
+	}
+	user, err := s.store.GetUserByUsername(ctx, username)
+	switch {
+	case err == nil:
+		if !user.Active {
+			return nil, ErrInvalidCredentials
+		}
+	case errors.Is(err, ErrUserNotFound):
+		if len(s.ldap.cfg.Roles) == 0 {
+			return nil, ErrInvalidCredentials
+		}
+		// GetUserByUsername skips inactive users, whom the directory must
+		// not bring back.
+		disabled, _, err := s.store.ListUsers(ctx, UserFilter{Username: username, IncludeDeleted: true}, 0, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if len(disabled) > 0 {
+			return nil, ErrInvalidCredentials
+		}
+		user = &User{
+			ID:       "ldap:" + username,
+			Username: username,
+			Roles:    s.ldap.cfg.Roles,
+			Metadata: map[string]string{"auth_source": "ldap"},
+			Active:   true,
+		}
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return s.loginResult(user)
+}
@@ -0,0 +1,287 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// mockLDAP answers simple binds, accepting the passwords in accounts keyed
+// by DN, and StartTLS requests when it has a TLS config.
+type mockLDAP struct {
+	accounts map[string]string
+	tls      *tls.Config
+}
+
+// serve starts the server and returns its ldap:// URL.
+func (m *mockLDAP) serve(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go m.handle(conn)
+		}
+	}()
+	return "ldap://" + ln.Addr().String()
+}
+
+func (m *mockLDAP) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id := packet.Children[0].Value
+		op := packet.Children[1]
+		reply := func(tag ber.Tag, code int) {
+			response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+			response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+			response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+			response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+			envelope := ber.NewSequence("")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+			envelope.AppendChild(response)
+			_, _ = conn.Write(envelope.Bytes())
+		}
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			code := ldap.LDAPResultInvalidCredentials
+			dn, _ := op.Children[1].Value.(string)
+			if want, ok := m.accounts[dn]; ok && want == op.Children[2].Data.String() {
+				code = ldap.LDAPResultSuccess
+			}
+			reply(ldap.ApplicationBindResponse, code)
+		case ldap.ApplicationExtendedRequest:
+			if m.tls == nil {
+				reply(ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError)
+				continue
+			}
+			reply(ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess)
+			conn = tls.Server(conn, m.tls)
+		default:
+			return
+		}
+	}
+}
+
+// serveMockLDAP serves accounts over plain ldap://.
+func serveMockLDAP(t *testing.T, accounts map[string]string) string {
+	t.Helper()
+	return (&mockLDAP{accounts: accounts}).serve(t)
+}
+
+func newLDAPTestService(t *testing.T, url string) *AuthService {
+	t.Helper()
+	service, err := NewAuthService(AuthConfig{
+		Store: StoreConfig{Type: "sqlite", Path: t.TempDir() + "/auth.db"},
+		LDAP: &LDAPConfig{
+			Enabled:  true,
+			URL:      url,
+			BindDN:   "uid={username},ou=people,dc=example,dc=com",
+			Roles:    []string{"viewer"},
+			Insecure: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	t.Cleanup(func() { _ = service.Close() })
+	return service
+}
+
+func TestLDAPBindWithLocalFallback(t *testing.T) {
+	url := serveMockLDAP(t, map[string]string{
+		"uid=carol,ou=people,dc=example,dc=com": "directory-pw",
+		"uid=dave,ou=people,dc=example,dc=com":  "directory-pw",
+	})
+	service := newLDAPTestService(t, url)
+	ctx := context.Background()
+	if _, err := service.CreateUser(ctx, "dave", "local-pw", "", []string{"operator"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	login := func(username, password string) (*AuthResult, error) {
+		return service.Authenticate(ctx, LoginRequest{Method: AuthMethodBasic, Username: username, Password: password})
+	}
+
+	// A directory-only user gets the configured roles.
+	result, err := login("carol", "directory-pw")
+	if err != nil || !result.Success || result.Token == nil {
+		t.Fatalf("ldap login: result=%+v err=%v", result, err)
+	}
+	if len(result.Roles) != 1 || result.Roles[0] != "viewer" || result.UserID != "ldap:carol" {
+		t.Fatalf("ldap user = %s %v, want ldap:carol [viewer]", result.UserID, result.Roles)
+	}
+
+	// A local user of the same name supplies the roles.
+	result, err = login("dave", "directory-pw")
+	if err != nil || len(result.Roles) != 1 || result.Roles[0] != "operator" {
+		t.Fatalf("ldap login with local roles: result=%+v err=%v", result, err)
+	}
+
+	// The local password still works when the directory rejects it.
+	if result, err := login("dave", "local-pw"); err != nil || !result.Success {
+		t.Fatalf("local fallback: result=%+v err=%v", result, err)
+	}
+	if _, err := login("carol", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password: err = %v, want ErrInvalidCredentials", err)
+	}
+	// A DN-injection attempt binds as an escaped, unknown DN.
+	if _, err := login("carol,ou=people,dc=example,dc=com", "directory-pw"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("DN injection: err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLDAPStartTLS(t *testing.T) {
+	// httptest's certificate is valid for 127.0.0.1.
+	https := httptest.NewUnstartedServer(nil)
+	https.StartTLS()
+	serverTLS := https.TLS.Clone()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: https.Certificate().Raw})
+	https.Close()
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	url := (&mockLDAP{
+		accounts: map[string]string{"uid=erin,ou=people,dc=example,dc=com": "directory-pw"},
+		tls:      serverTLS,
+	}).serve(t)
+
+	a, err := newLDAPAuthenticator(LDAPConfig{
+		Enabled:  true,
+		URL:      url,
+		BindDN:   "uid={username},ou=people,dc=example,dc=com",
+		StartTLS: true,
+		CAFile:   caFile,
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := a.bind(ctx, "erin", "directory-pw"); err != nil {
+		t.Fatalf("bind over StartTLS: %v", err)
+	}
+	if err := a.bind(ctx, "erin", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password over StartTLS: err = %v, want ErrInvalidCredentials", err)
+	}
+
+	// Without the CA the server is not trusted.
+	a.tls.RootCAs = nil
+	if err := a.bind(ctx, "erin", "directory-pw"); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("bind to an untrusted server: err = %v", err)
+	}
+}
+
+func TestLDAPConfigRequiresTLSOrInsecure(t *testing.T) {
+	cfg := LDAPConfig{Enabled: true, URL: "ldap://ldap.example.com", BindDN: "uid={username}"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "clear") {
+		t.Fatalf("plain ldap:// accepted: %v", err)
+	}
+	for _, ok := range []LDAPConfig{
+		{URL: "ldaps://ldap.example.com", BindDN: "uid={username}"},
+		{URL: "ldap://ldap.example.com", BindDN: "uid={username}", StartTLS: true},
+		{URL: "ldap://ldap.example.com", BindDN: "uid={username}", Insecure: true},
+	} {
+		if err := ok.Validate(); err != nil {
+			t.Errorf("Validate(%+v): %v", ok, err)
+		}
+	}
+	cfg = LDAPConfig{URL: "ldaps://ldap.example.com", BindDN: "uid={username}", StartTLS: true}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("start_tls accepted with ldaps://")
+	}
+}
+
+func TestLDAPDeniesDisabledLocalUsers(t *testing.T) {
+	url := serveMockLDAP(t, map[string]string{
+		"uid=frank,ou=people,dc=example,dc=com": "directory-pw",
+		"uid=grace,ou=people,dc=example,dc=com": "directory-pw",
+	})
+	service := newLDAPTestService(t, url)
+	ctx := context.Background()
+	for _, name := range []string{"frank", "grace"} {
+		if _, err := service.CreateUser(ctx, name, "local-pw", "", []string{"admin"}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frank, err := service.store.GetUserByUsername(ctx, "frank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.DeleteUser(ctx, frank.ID); err != nil {
+		t.Fatal(err)
+	}
+	grace, err := service.store.GetUserByUsername(ctx, "grace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grace.Active = false
+	if err := service.store.UpdateUser(ctx, grace); err != nil {
+		t.Fatal(err)
+	}
+
+	// The directory still knows them, but they must not fall back to the
+	// default LDAP roles.
+	for _, name := range []string{"frank", "grace"} {
+		_, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodBasic, Username: name, Password: "directory-pw"})
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: err = %v, want ErrInvalidCredentials", name, err)
+		}
+	}
+}
+
+func TestLDAPUnreachableFallsBackToLocalUsers(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	service := newLDAPTestService(t, "ldap://"+addr)
+	ctx := context.Background()
+	if _, err := service.CreateUser(ctx, "admin", "local-pw", "", []string{"admin"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	result, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodBasic, Username: "admin", Password: "local-pw"})
+	if err != nil || !result.Success {
+		t.Fatalf("local login with LDAP down: result=%+v err=%v", result, err)
+	}
+}
+
+func TestEscapeDNValue(t *testing.T) {
+	cases := map[string]string{
+		"alice":      "alice",
+		"a,b":        `a\,b`,
+		" lead":      `\ lead`,
+		"trail ":     `trail\ `,
+		"#hash":      `\#hash`,
+		`x=y+z"<>;\`: `x\=y\+z\"\<\>\;\\`,
+	}
+	for in, want := range cases {
+		if got := escapeDNValue(in); got != want {
+			t.Errorf("escapeDNValue(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
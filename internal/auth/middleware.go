@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"net/http"
 	"strings"
 
@@ -162,10 +164,32 @@ func (m *Middleware) HTTPRequirePermission(resource, action string) func(http.Ha
 	}
 }
 
-// authenticate extracts and validates authentication from HTTP request
+// authenticate extracts and validates authentication from HTTP request.
+// Without an Authorization header, a verified TLS client certificate
+// identifies the user.
 func (m *Middleware) authenticate(r *http.Request) (*AuthResult, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" && r.TLS != nil {
+		if cert := verifiedClientCert(*r.TLS); cert != nil {
+			return m.authService.AuthenticateCertificate(r.Context(), cert)
+		}
+	}
+	return m.authenticateHeader(r.Context(), header)
+}
+
+// verifiedClientCert returns the leaf of the first client certificate chain
+// the handshake verified, or nil if there is none.
+func verifiedClientCert(state tls.ConnectionState) *x509.Certificate {
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+// authenticateHeader validates an Authorization header value carrying
+// either a Bearer token or Basic credentials.
+func (m *Middleware) authenticateHeader(ctx context.Context, authHeader string) (*AuthResult, error) {
 	// Try Authorization header first (Bearer token)
-	authHeader := r.Header.Get("Authorization")
 	if authHeader != "" {
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
@@ -173,20 +197,27 @@ func (m *Middleware) authenticate(r *http.Request) (*AuthResult, error) {
 				Method: AuthMethodJWT,
 				Token:  parts[1],
 			}
-			return m.authService.Authenticate(r.Context(), req)
+			return m.authService.Authenticate(ctx, req)
 		}
 	}
 
 	// Try Basic Authentication
-	username, password, ok := r.BasicAuth()
+	username, password, ok := parseBasicAuth(authHeader)
 	if ok {
 		req := LoginRequest{
 			Method:   AuthMethodBasic,
 			Username: username,
 			Password: password,
 		}
-		return m.authService.Authenticate(r.Context(), req)
+		return m.authService.Authenticate(ctx, req)
 	}
 
 	return &AuthResult{Success: false}, ErrInvalidCredentials
 }
+
+// parseBasicAuth decodes a "Basic" Authorization header value, as
+// http.Request.BasicAuth does.
+func parseBasicAuth(authHeader string) (username, password string, ok bool) {
+	r := http.Request{Header: http.Header{"Authorization": []string{authHeader}}}
+	return r.BasicAuth()
+}
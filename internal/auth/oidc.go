@@ -0,0 +1,354 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCConfig accepts ID tokens issued by an external OpenID Connect
+// provider. Their claims are mapped to provisr roles; no local user is
+// needed.
+type OIDCConfig struct {
+	Enabled bool `mapstructure:"enabled" toml:"enabled" yaml:"enabled" json:"enabled"`
+	// IssuerURL is the provider's issuer; its discovery document is read
+	// from IssuerURL/.well-known/openid-configuration.
+	IssuerURL string `mapstructure:"issuer_url" toml:"issuer_url" yaml:"issuer_url" json:"issuer_url"`
+	// ClientID must be among the token's audiences.
+	ClientID string `mapstructure:"client_id" toml:"client_id" yaml:"client_id" json:"client_id"`
+	// UsernameClaim names the user; default preferred_username, falling
+	// back to sub when the token lacks it.
+	UsernameClaim string `mapstructure:"username_claim" toml:"username_claim,omitempty" yaml:"username_claim,omitempty" json:"username_claim,omitempty"`
+	// RolesClaim holds the string or list of strings RoleMapping maps;
+	// default groups.
+	RolesClaim string `mapstructure:"roles_claim" toml:"roles_claim,omitempty" yaml:"roles_claim,omitempty" json:"roles_claim,omitempty"`
+	// RoleMapping maps a RolesClaim value to the provisr roles it grants.
+	RoleMapping map[string][]string `mapstructure:"role_mapping" toml:"role_mapping,omitempty" yaml:"role_mapping,omitempty" json:"role_mapping,omitempty"`
+	// DefaultRoles are granted when no RolesClaim value is mapped. Without
+	// them such tokens are rejected.
+	DefaultRoles []string `mapstructure:"default_roles" toml:"default_roles,omitempty" yaml:"default_roles,omitempty" json:"default_roles,omitempty"`
+	// JWKSCacheTTL is how long the provider's signing keys are cached;
+	// default 1h. A token signed with an unknown key refreshes them sooner.
+	JWKSCacheTTL time.Duration `mapstructure:"jwks_cache_ttl" toml:"jwks_cache_ttl,omitempty" yaml:"jwks_cache_ttl,omitempty" json:"jwks_cache_ttl,omitempty"`
+}
+
+// Validate checks the settings an enabled OIDCConfig needs.
+func (c OIDCConfig) Validate() error {
+	if u, err := url.Parse(c.IssuerURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("issuer_url must be an http or https URL, got %q", c.IssuerURL)
+	}
+	if c.ClientID == "" {
+		return fmt.Errorf("client_id is required")
+	}
+	if c.JWKSCacheTTL < 0 {
+		return fmt.Errorf("jwks_cache_ttl must not be negative")
+	}
+	for value, roles := range c.RoleMapping {
+		if err := ValidateRoles(roles); err != nil {
+			return fmt.Errorf("role_mapping %q: %w", value, err)
+		}
+	}
+	if len(c.DefaultRoles) > 0 {
+		if err := ValidateRoles(c.DefaultRoles); err != nil {
+			return fmt.Errorf("default_roles: %w", err)
+		}
+	}
+	return nil
+}
+
+// oidcKeyRefreshInterval limits how often a token with an unknown key ID
+// makes the provider refetch its keys.
+const oidcKeyRefreshInterval = 10 * time.Second
+
+// oidcSigningMethods are the asymmetric algorithms accepted from the
+// provider; HMAC would let anyone holding the client secret mint tokens.
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// oidcProvider verifies ID tokens against a provider's discovery document
+// and signing keys, both fetched on first use and cached.
+type oidcProvider struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	issuer    string
+	jwksURI   string
+	keys      map[string]any
+	fetchedAt time.Time
+}
+
+func newOIDCProvider(cfg OIDCConfig) *oidcProvider {
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "preferred_username"
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "groups"
+	}
+	if cfg.JWKSCacheTTL == 0 {
+		cfg.JWKSCacheTTL = time.Hour
+	}
+	return &oidcProvider{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// authenticate verifies an ID token and maps its claims to a result.
+func (p *oidcProvider) authenticate(ctx context.Context, raw string) (*AuthResult, error) {
+	issuer, err := p.discover(ctx)
+	if err != nil {
+		return &AuthResult{Success: false}, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return &AuthResult{Success: false}, ErrInvalidCredentials
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return &AuthResult{Success: false}, ErrInvalidCredentials
+	}
+	username, _ := claims[p.cfg.UsernameClaim].(string)
+	if username == "" {
+		username = subject
+	}
+	roles := p.roles(claims[p.cfg.RolesClaim])
+	if len(roles) == 0 {
+		return &AuthResult{Success: false}, ErrInvalidCredentials
+	}
+
+	return &AuthResult{
+		Success:  true,
+		UserID:   "oidc:" + subject,
+		Username: username,
+		Roles:    roles,
+		Metadata: map[string]string{"auth_source": "oidc", "issuer": issuer},
+	}, nil
+}
+
+// roles maps the roles claim, a string or a list of strings, through
+// RoleMapping, falling back to DefaultRoles.
+func (p *oidcProvider) roles(claim any) []string {
+	var values []string
+	switch v := claim.(type) {
+	case string:
+		values = []string{v}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	var roles []string
+	seen := map[string]bool{}
+	for _, value := range values {
+		for _, role := range p.cfg.RoleMapping[value] {
+			if !seen[role] {
+				seen[role] = true
+				roles = append(roles, role)
+			}
+		}
+	}
+	if len(roles) == 0 {
+		return append([]string(nil), p.cfg.DefaultRoles...)
+	}
+	return roles
+}
+
+// discover reads the discovery document once and returns the issuer.
+func (p *oidcProvider) discover(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.issuer != "" {
+		return p.issuer, nil
+	}
+
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	discoveryURL := strings.TrimSuffix(p.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, discoveryURL, &doc); err != nil {
+		return "", fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(p.cfg.IssuerURL, "/") {
+		return "", fmt.Errorf("oidc discovery: issuer %q does not match issuer_url %q", doc.Issuer, p.cfg.IssuerURL)
+	}
+	if doc.JWKSURI == "" {
+		return "", fmt.Errorf("oidc discovery: no jwks_uri")
+	}
+	p.issuer, p.jwksURI = doc.Issuer, doc.JWKSURI
+	return p.issuer, nil
+}
+
+// key returns the signing key with the given ID, refreshing the cached
+// keys when they are stale or, at most every oidcKeyRefreshInterval, when
+// the ID is unknown, as after a key rotation. An empty ID matches the only
+// key of a single-key set.
+func (p *oidcProvider) key(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	age := time.Since(p.fetchedAt)
+	if p.keys == nil || age > p.cfg.JWKSCacheTTL {
+		if err := p.refreshKeys(ctx); err != nil {
+			return nil, err
+		}
+	} else if p.lookup(kid) == nil && age > oidcKeyRefreshInterval {
+		if err := p.refreshKeys(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if key := p.lookup(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+}
+
+func (p *oidcProvider) lookup(kid string) any {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+func (p *oidcProvider) refreshKeys(ctx context.Context) error {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.jwksURI, &set); err != nil {
+		return fmt.Errorf("oidc jwks: %w", err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Keys of unsupported types do not spoil the rest of the set.
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	p.keys, p.fetchedAt = keys, time.Now()
+	return nil
+}
+
+func (p *oidcProvider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// jsonWebKey is the subset of RFC 7517 needed for RSA and EC public keys.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		size := (curve.Params().BitSize + 7) / 8
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return nil, errors.New("invalid EC point")
+		}
+		// Parsing the uncompressed point also checks that it is on the curve.
+		return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// authenticateOIDC exchanges an ID token of the OIDC provider for a token
+// of this service carrying the mapped roles.
+func (s *AuthService) authenticateOIDC(ctx context.Context, idToken string) (*AuthResult, error) {
+	if s.oidc == nil {
+		return &AuthResult{Success: false}, fmt.Errorf("oidc authentication is not enabled")
+	}
+	result, err := s.oidc.authenticate(ctx, idToken)
+	if err != nil {
+		return result, err
+	}
+	return s.loginResult(&User{
+		ID:       result.UserID,
+		Username: result.Username,
+		Roles:    result.Roles,
+		Metadata: result.Metadata,
+		Active:   true,
+	})
+}
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockOIDCProvider serves a discovery document and a JWKS, and signs ID
+// tokens with the key it currently publishes.
+type mockOIDCProvider struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	kid      string
+	key      *rsa.PrivateKey
+	jwksHits int
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+	p := &mockOIDCProvider{}
+	p.rotate(t, "key-1")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   p.server.URL,
+			"jwks_uri": p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.jwksHits++
+		pub := p.key.PublicKey
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": p.kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}}})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *mockOIDCProvider) rotate(t *testing.T, kid string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.mu.Lock()
+	p.kid, p.key = kid, key
+	p.mu.Unlock()
+}
+
+func (p *mockOIDCProvider) hits() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jwksHits
+}
+
+func (p *mockOIDCProvider) idToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	base := jwt.MapClaims{
+		"iss": p.server.URL,
+		"aud": "provisr",
+		"sub": "user-42",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	for k, v := range claims {
+		base[k] = v
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, base)
+	token.Header["kid"] = p.kid
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func newOIDCTestService(t *testing.T, provider *mockOIDCProvider) *AuthService {
+	t.Helper()
+	service, err := NewAuthService(AuthConfig{
+		Store: StoreConfig{Type: "sqlite", Path: t.TempDir() + "/auth.db"},
+		OIDC: &OIDCConfig{
+			Enabled:   true,
+			IssuerURL: provider.server.URL,
+			ClientID:  "provisr",
+			RoleMapping: map[string][]string{
+				"ops":    {"operator"},
+				"admins": {"admin"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	t.Cleanup(func() { _ = service.Close() })
+	return service
+}
+
+func TestOIDCTokenMapsClaimsToRoles(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	service := newOIDCTestService(t, provider)
+	ctx := context.Background()
+
+	raw := provider.idToken(t, jwt.MapClaims{"preferred_username": "alice", "groups": []string{"ops", "unmapped"}})
+	result, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodJWT, Token: raw})
+	if err != nil || !result.Success {
+		t.Fatalf("bearer ID token rejected: result=%+v err=%v", result, err)
+	}
+	if result.Username != "alice" || result.UserID != "oidc:user-42" {
+		t.Fatalf("identity = %q/%q, want alice/oidc:user-42", result.Username, result.UserID)
+	}
+	if len(result.Roles) != 1 || result.Roles[0] != "operator" {
+		t.Fatalf("roles = %v, want [operator]", result.Roles)
+	}
+
+	// Logging in with the ID token issues a provisr token with the roles.
+	login, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodOIDC, Token: raw})
+	if err != nil || !login.Success || login.Token == nil {
+		t.Fatalf("oidc login failed: result=%+v err=%v", login, err)
+	}
+	local, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodJWT, Token: login.Token.Value})
+	if err != nil || !local.Success || !service.HasPermission(local.Roles, "process", "write") {
+		t.Fatalf("issued token: result=%+v err=%v", local, err)
+	}
+}
+
+func TestOIDCRejectsInvalidTokens(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	service := newOIDCTestService(t, provider)
+	ctx := context.Background()
+
+	cases := map[string]jwt.MapClaims{
+		"wrong audience": {"aud": "someone-else", "groups": "ops"},
+		"wrong issuer":   {"iss": "https://evil.example.com", "groups": "ops"},
+		"expired":        {"exp": time.Now().Add(-time.Minute).Unix(), "groups": "ops"},
+		"no mapped role": {"groups": []string{"unmapped"}},
+	}
+	for name, claims := range cases {
+		raw := provider.idToken(t, claims)
+		if _, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodJWT, Token: raw}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: err = %v, want ErrInvalidCredentials", name, err)
+		}
+	}
+
+	// A token signed by a key the provider never published.
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": provider.server.URL, "aud": "provisr", "sub": "x", "groups": "admins",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = "key-1"
+	raw, err := forged.SignedString(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodJWT, Token: raw}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("forged token: err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestOIDCKeysAreCachedAndRefreshedOnRotation(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	service := newOIDCTestService(t, provider)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		raw := provider.idToken(t, jwt.MapClaims{"groups": "ops"})
+		if _, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodJWT, Token: raw}); err != nil {
+			t.Fatalf("login %d: %v", i, err)
+		}
+	}
+	if hits := provider.hits(); hits != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1 while cached", hits)
+	}
+
+	provider.rotate(t, "key-2")
+	// Pretend the unknown-key refresh limit has passed.
+	service.oidc.mu.Lock()
+	service.oidc.fetchedAt = time.Now().Add(-2 * oidcKeyRefreshInterval)
+	service.oidc.mu.Unlock()
+	raw := provider.idToken(t, jwt.MapClaims{"groups": "ops"})
+	if _, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodJWT, Token: raw}); err != nil {
+		t.Fatalf("token signed with the rotated key: %v", err)
+	}
+	if hits := provider.hits(); hits != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2 after rotation", hits)
+	}
+}
@@ -3,9 +3,13 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/x509"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,6 +24,8 @@ type AuthService struct {
 	tokenTTL   time.Duration
 	bcryptCost int
 	userMu     sync.Mutex
+	oidc       *oidcProvider
+	ldap       *ldapAuthenticator
 }
 
 // AuthConfig represents configuration for the auth service
@@ -28,6 +34,10 @@ type AuthConfig struct {
 	JWTSecret  string        `toml:"jwt_secret" yaml:"jwt_secret" json:"jwt_secret"`
 	TokenTTL   time.Duration `toml:"token_ttl" yaml:"token_ttl" json:"token_ttl"`
 	BcryptCost int           `toml:"bcrypt_cost" yaml:"bcrypt_cost" json:"bcrypt_cost"`
+	// OIDC and LDAP, when enabled, authenticate users the store does not
+	// hold; local users keep working alongside them.
+	OIDC *OIDCConfig `toml:"oidc,omitempty" yaml:"oidc,omitempty" json:"oidc,omitempty"`
+	LDAP *LDAPConfig `toml:"ldap,omitempty" yaml:"ldap,omitempty" json:"ldap,omitempty"`
 }
 
 // Claims represents JWT claims
@@ -84,12 +94,26 @@ func NewAuthService(config AuthConfig) (*AuthService, error) {
 		bcryptCost = bcrypt.DefaultCost
 	}
 
-	return &AuthService{
+	service := &AuthService{
 		store:      store,
 		jwtSecret:  jwtSecret,
 		tokenTTL:   tokenTTL,
 		bcryptCost: bcryptCost,
-	}, nil
+	}
+	if config.OIDC != nil && config.OIDC.Enabled {
+		if err := config.OIDC.Validate(); err != nil {
+			return nil, errors.Join(fmt.Errorf("oidc: %w", err), store.Close())
+		}
+		service.oidc = newOIDCProvider(*config.OIDC)
+	}
+	if config.LDAP != nil && config.LDAP.Enabled {
+		ldap, err := newLDAPAuthenticator(*config.LDAP)
+		if err != nil {
+			return nil, errors.Join(err, store.Close())
+		}
+		service.ldap = ldap
+	}
+	return service, nil
 }
 
 // Authenticate performs authentication based on the login request
@@ -99,6 +123,8 @@ func (s *AuthService) Authenticate(ctx context.Context, req LoginRequest) (*Auth
 		return s.authenticateBasic(ctx, req.Username, req.Password)
 	case AuthMethodJWT:
 		return s.authenticateJWT(ctx, req.Token)
+	case AuthMethodOIDC:
+		return s.authenticateOIDC(ctx, req.Token)
 	default:
 		return &AuthResult{Success: false}, fmt.Errorf("unsupported auth method: %s", req.Method)
 	}
@@ -110,6 +136,16 @@ func (s *AuthService) authenticateBasic(ctx context.Context, username, password
 		return &AuthResult{Success: false}, ErrInvalidCredentials
 	}
 
+	if s.ldap != nil {
+		result, err := s.authenticateLDAP(ctx, username, password)
+		if err == nil {
+			return result, nil
+		}
+		if !errors.Is(err, ErrInvalidCredentials) {
+			slog.Warn("LDAP bind failed, trying local users", "username", username, "error", err)
+		}
+	}
+
 	user, err := s.store.GetUserByUsername(ctx, username)
 	if err != nil {
 		if err == ErrUserNotFound {
@@ -125,7 +161,44 @@ func (s *AuthService) authenticateBasic(ctx context.Context, username, password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
 		return &AuthResult{Success: false}, ErrInvalidCredentials
 	}
+	s.upgradePasswordHash(ctx, user, password)
+
+	return s.loginResult(user)
+}
+
+// upgradePasswordHash re-hashes the password user just logged in with when
+// its stored hash uses a lower cost than the service, so raising
+// BcryptCost reaches existing users. Failures only cost the upgrade, not
+// the login.
+func (s *AuthService) upgradePasswordHash(ctx context.Context, user *User, password string) {
+	cost, err := bcrypt.Cost([]byte(user.PasswordHash))
+	if err != nil || cost >= s.bcryptCost {
+		return
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
+	if err != nil {
+		slog.Warn("failed to upgrade password hash", "username", user.Username, "error", err)
+		return
+	}
+
+	s.userMu.Lock()
+	defer s.userMu.Unlock()
+	// Re-read so a concurrent profile or password change is kept.
+	current, err := s.store.GetUser(ctx, user.ID)
+	if err != nil || current.PasswordHash != user.PasswordHash {
+		return
+	}
+	current.PasswordHash = string(passwordHash)
+	current.UpdatedAt = time.Now().UTC()
+	if err := s.store.UpdateUser(ctx, current); err != nil {
+		slog.Warn("failed to upgrade password hash", "username", user.Username, "error", err)
+		return
+	}
+	user.PasswordHash = current.PasswordHash
+}
 
+// loginResult is the successful result of logging user in, with a token.
+func (s *AuthService) loginResult(user *User) (*AuthResult, error) {
 	token, err := s.generateJWT(user)
 	if err != nil {
 		return &AuthResult{Success: false}, fmt.Errorf("failed to generate token: %w", err)
@@ -141,8 +214,57 @@ func (s *AuthService) authenticateBasic(ctx context.Context, username, password
 	}, nil
 }
 
-// authenticateJWT validates a JWT token
-func (s *AuthService) authenticateJWT(_ context.Context, tokenString string) (*AuthResult, error) {
+// AuthenticateCertificate maps a client certificate the TLS handshake has
+// already verified to the active user named by its common name or, failing
+// that, by its first DNS, email or URI SAN that names one. Callers must not
+// pass certificates that weren't verified against the client CA.
+func (s *AuthService) AuthenticateCertificate(ctx context.Context, cert *x509.Certificate) (*AuthResult, error) {
+	if cert == nil {
+		return &AuthResult{Success: false}, ErrInvalidCredentials
+	}
+	names := []string{cert.Subject.CommonName}
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		names = append(names, u.String())
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		user, err := s.store.GetUserByUsername(ctx, name)
+		if errors.Is(err, ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return &AuthResult{Success: false}, fmt.Errorf("failed to get user: %w", err)
+		}
+		if !user.Active {
+			return &AuthResult{Success: false}, ErrInvalidCredentials
+		}
+		return &AuthResult{
+			Success:  true,
+			UserID:   user.ID,
+			Username: user.Username,
+			Roles:    user.Roles,
+			Metadata: user.Metadata,
+		}, nil
+	}
+	return &AuthResult{Success: false}, ErrInvalidCredentials
+}
+
+// authenticateJWT validates a token issued by this service or, failing
+// that, an ID token of the OIDC provider.
+func (s *AuthService) authenticateJWT(ctx context.Context, tokenString string) (*AuthResult, error) {
+	result, err := s.authenticateLocalJWT(tokenString)
+	if err != nil && s.oidc != nil && tokenString != "" {
+		return s.oidc.authenticate(ctx, tokenString)
+	}
+	return result, err
+}
+
+// authenticateLocalJWT validates a JWT token issued by this service
+func (s *AuthService) authenticateLocalJWT(tokenString string) (*AuthResult, error) {
 	if tokenString == "" {
 		return &AuthResult{Success: false}, ErrInvalidCredentials
 	}
@@ -244,7 +366,7 @@ func (s *AuthService) CreateUser(ctx context.Context, username, password, email
 // decide whether the frontend should show a first-run "create the admin
 // account" form instead of a login form.
 func (s *AuthService) HasAnyUsers(ctx context.Context) (bool, error) {
-	users, _, err := s.store.ListUsers(ctx, 0, 1)
+	users, _, err := s.store.ListUsers(ctx, UserFilter{IncludeDeleted: true}, 0, 1)
 	if err != nil {
 		return false, fmt.Errorf("failed to check for existing users: %w", err)
 	}
@@ -294,6 +416,8 @@ func (s *AuthService) UpdateUserPassword(ctx context.Context, userID, newPasswor
 		return fmt.Errorf("password cannot be empty")
 	}
 
+	s.userMu.Lock()
+	defer s.userMu.Unlock()
 	user, err := s.store.GetUser(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
@@ -334,6 +458,29 @@ var rolePermissions = map[string][]Permission{
 	},
 }
 
+// Roles returns the known role names, sorted.
+func Roles() []string {
+	roles := make([]string, 0, len(rolePermissions))
+	for role := range rolePermissions {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// ValidateRoles checks that roles is non-empty and names only known roles.
+func ValidateRoles(roles []string) error {
+	if len(roles) == 0 {
+		return fmt.Errorf("at least one role is required")
+	}
+	for _, role := range roles {
+		if _, ok := rolePermissions[role]; !ok {
+			return fmt.Errorf("%w %q (allowed: %s)", ErrUnknownRole, role, strings.Join(Roles(), ", "))
+		}
+	}
+	return nil
+}
+
 // HasPermission checks if a user has a specific permission
 func (s *AuthService) HasPermission(userRoles []string, resource, action string) bool {
 	for _, role := range userRoles {
@@ -368,6 +515,9 @@ func (s *AuthService) UpdateUserWithPassword(ctx context.Context, user *User, ne
 	if err != nil {
 		return err
 	}
+	if current.DeletedAt != nil {
+		return ErrUserDeleted
+	}
 	if isActiveAdmin(current) && !isActiveAdmin(user) {
 		last, err := s.isLastActiveAdmin(ctx, current.ID)
 		if err != nil {
@@ -388,10 +538,60 @@ func (s *AuthService) UpdateUserWithPassword(ctx context.Context, user *User, ne
 	return s.store.UpdateUser(ctx, user)
 }
 
-// DeleteUser deletes a user
+// SetUserRoles replaces the roles of the named user. Tokens issued from
+// then on carry the new roles; tokens already issued keep their old roles
+// until they expire.
+func (s *AuthService) SetUserRoles(ctx context.Context, username string, roles []string) (*User, error) {
+	if err := ValidateRoles(roles); err != nil {
+		return nil, err
+	}
+	user, err := s.store.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	user.Roles = append([]string(nil), roles...)
+	user.UpdatedAt = time.Now().UTC()
+	if err := s.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser soft-deletes a user: it is deactivated and marked deleted,
+// and can be brought back with RestoreUser. PurgeUser removes it for good.
 func (s *AuthService) DeleteUser(ctx context.Context, id string) error {
 	s.userMu.Lock()
 	defer s.userMu.Unlock()
+	if err := s.checkNotLastActiveAdmin(ctx, id); err != nil {
+		return err
+	}
+	return s.store.SoftDeleteUser(ctx, id, time.Now())
+}
+
+// PurgeUser deletes a user permanently, whether or not it was
+// soft-deleted first.
+func (s *AuthService) PurgeUser(ctx context.Context, id string) error {
+	s.userMu.Lock()
+	defer s.userMu.Unlock()
+	if err := s.checkNotLastActiveAdmin(ctx, id); err != nil {
+		return err
+	}
+	return s.store.DeleteUser(ctx, id)
+}
+
+// RestoreUser reactivates a soft-deleted user with its old roles and
+// password.
+func (s *AuthService) RestoreUser(ctx context.Context, id string) (*User, error) {
+	s.userMu.Lock()
+	defer s.userMu.Unlock()
+	if err := s.store.RestoreUser(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.store.GetUser(ctx, id)
+}
+
+// checkNotLastActiveAdmin refuses to remove the only active admin.
+func (s *AuthService) checkNotLastActiveAdmin(ctx context.Context, id string) error {
 	current, err := s.store.GetUser(ctx, id)
 	if err != nil {
 		return err
@@ -405,7 +605,7 @@ func (s *AuthService) DeleteUser(ctx context.Context, id string) error {
 			return ErrLastActiveAdmin
 		}
 	}
-	return s.store.DeleteUser(ctx, id)
+	return nil
 }
 
 func isActiveAdmin(user *User) bool {
@@ -421,7 +621,7 @@ func isActiveAdmin(user *User) bool {
 }
 
 func (s *AuthService) isLastActiveAdmin(ctx context.Context, exceptID string) (bool, error) {
-	users, _, err := s.store.ListUsers(ctx, 0, int(^uint(0)>>1))
+	users, _, err := s.store.ListUsers(ctx, UserFilter{ActiveOnly: true}, 0, int(^uint(0)>>1))
 	if err != nil {
 		return false, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -433,9 +633,14 @@ func (s *AuthService) isLastActiveAdmin(ctx context.Context, exceptID string) (b
 	return true, nil
 }
 
-// ListUsers lists users with pagination
+// ListUsers lists users that are not soft-deleted, with pagination
 func (s *AuthService) ListUsers(ctx context.Context, offset, limit int) ([]*User, int, error) {
-	return s.store.ListUsers(ctx, offset, limit)
+	return s.ListUsersWithFilter(ctx, UserFilter{}, offset, limit)
+}
+
+// ListUsersWithFilter lists the users matching filter, with pagination
+func (s *AuthService) ListUsersWithFilter(ctx context.Context, filter UserFilter, offset, limit int) ([]*User, int, error) {
+	return s.store.ListUsers(ctx, filter, offset, limit)
 }
 
 // Store returns the underlying store (for CLI operations)
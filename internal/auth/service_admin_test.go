@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func newTestAuthService(t *testing.T) *AuthService {
@@ -98,6 +100,87 @@ func TestAdminCanBeRemovedWhenAnotherActiveAdminExists(t *testing.T) {
 	}
 }
 
+func TestDeleteUserIsSoftAndRestorable(t *testing.T) {
+	service := newTestAuthService(t)
+	ctx := context.Background()
+	if _, err := service.CreateUser(ctx, "admin", "password123", "", []string{"admin"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	user, err := service.CreateUser(ctx, "operator", "password123", "", []string{"operator"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	login := func() error {
+		_, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodBasic, Username: "operator", Password: "password123"})
+		return err
+	}
+
+	if err := service.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	if err := login(); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("login after delete: err = %v, want ErrInvalidCredentials", err)
+	}
+	if users, total, err := service.ListUsers(ctx, 0, 10); err != nil || total != 1 || users[0].Username != "admin" {
+		t.Fatalf("ListUsers after delete = %d users, err %v; want only admin", total, err)
+	}
+	if _, total, err := service.ListUsersWithFilter(ctx, UserFilter{IncludeDeleted: true}, 0, 10); err != nil || total != 2 {
+		t.Fatalf("ListUsers with deleted = %d users, err %v; want 2", total, err)
+	}
+	deleted, err := service.GetUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted.Active = true
+	if err := service.UpdateUser(ctx, deleted); !errors.Is(err, ErrUserDeleted) {
+		t.Fatalf("update of deleted user: err = %v, want ErrUserDeleted", err)
+	}
+
+	restored, err := service.RestoreUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if !restored.Active || restored.DeletedAt != nil || restored.Roles[0] != "operator" {
+		t.Fatalf("restored user = %+v", restored)
+	}
+	if err := login(); err != nil {
+		t.Fatalf("login after restore: %v", err)
+	}
+
+	if err := service.PurgeUser(ctx, user.ID); err != nil {
+		t.Fatalf("hard delete: %v", err)
+	}
+	if _, err := service.RestoreUser(ctx, user.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("restore after hard delete: err = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestCLIHelperRestoresDeletedUserByUsername(t *testing.T) {
+	service := newTestAuthService(t)
+	ctx := context.Background()
+	user, err := service.CreateUser(ctx, "operator", "password123", "", []string{"operator"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := NewCLIHelper(service)
+	if err := cli.DeleteUser(ctx, "operator", false); err != nil {
+		t.Fatalf("delete by username: %v", err)
+	}
+	if err := cli.RestoreUser(ctx, "operator"); err != nil {
+		t.Fatalf("restore by username: %v", err)
+	}
+	restored, err := service.GetUser(ctx, user.ID)
+	if err != nil || !restored.Active || restored.DeletedAt != nil {
+		t.Fatalf("restored user = %+v, err %v", restored, err)
+	}
+	if err := cli.DeleteUser(ctx, "operator", true); err != nil {
+		t.Fatalf("hard delete by username: %v", err)
+	}
+	if err := cli.RestoreUser(ctx, "operator"); err == nil {
+		t.Fatal("restore after hard delete succeeded")
+	}
+}
+
 func TestUpdateUserWithPasswordCommitsProfileAndPasswordTogether(t *testing.T) {
 	service := newTestAuthService(t)
 	ctx := context.Background()
@@ -123,3 +206,100 @@ func TestUpdateUserWithPasswordCommitsProfileAndPasswordTogether(t *testing.T) {
 		t.Fatalf("new password did not authenticate: result=%+v err=%v", result, err)
 	}
 }
+
+func TestSetUserRolesAppliesToNewTokens(t *testing.T) {
+	service := newTestAuthService(t)
+	ctx := context.Background()
+	if _, err := service.CreateUser(ctx, "alice", "password123", "", []string{"viewer"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	login := func() *AuthResult {
+		t.Helper()
+		result, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodBasic, Username: "alice", Password: "password123"})
+		if err != nil || !result.Success || result.Token == nil {
+			t.Fatalf("login failed: result=%+v err=%v", result, err)
+		}
+		jwtResult, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodJWT, Token: result.Token.Value})
+		if err != nil || !jwtResult.Success {
+			t.Fatalf("token rejected: result=%+v err=%v", jwtResult, err)
+		}
+		return jwtResult
+	}
+
+	if before := login(); service.HasPermission(before.Roles, "process", "write") {
+		t.Fatalf("viewer token can write: roles=%v", before.Roles)
+	}
+
+	if _, err := service.SetUserRoles(ctx, "alice", []string{"operator"}); err != nil {
+		t.Fatalf("SetUserRoles: %v", err)
+	}
+	after := login()
+	if len(after.Roles) != 1 || after.Roles[0] != "operator" {
+		t.Fatalf("new token roles = %v, want [operator]", after.Roles)
+	}
+	if !service.HasPermission(after.Roles, "process", "write") {
+		t.Fatal("operator token cannot write processes")
+	}
+
+	if _, err := service.SetUserRoles(ctx, "alice", []string{"superuser"}); !errors.Is(err, ErrUnknownRole) {
+		t.Fatalf("unknown role error = %v, want ErrUnknownRole", err)
+	}
+	if _, err := service.SetUserRoles(ctx, "nobody", []string{"viewer"}); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("missing user error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestBasicLoginUpgradesBcryptCost(t *testing.T) {
+	storeConfig := StoreConfig{Type: "sqlite", Path: t.TempDir() + "/auth.db"}
+	ctx := context.Background()
+
+	old, err := NewAuthService(AuthConfig{Store: storeConfig, BcryptCost: bcrypt.MinCost})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := old.CreateUser(ctx, "alice", "password123", "", []string{"viewer"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = old.Close()
+
+	const newCost = bcrypt.MinCost + 2
+	service, err := NewAuthService(AuthConfig{Store: storeConfig, BcryptCost: newCost})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = service.Close() })
+
+	login := func(password string) error {
+		_, err := service.Authenticate(ctx, LoginRequest{Method: AuthMethodBasic, Username: "alice", Password: password})
+		return err
+	}
+	cost := func() int {
+		t.Helper()
+		stored, err := service.GetUser(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := bcrypt.Cost([]byte(stored.PasswordHash))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if err := login("wrong-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password: err = %v", err)
+	}
+	if got := cost(); got != bcrypt.MinCost {
+		t.Fatalf("failed login changed the hash cost to %d", got)
+	}
+	if err := login("password123"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if got := cost(); got != newCost {
+		t.Fatalf("hash cost after login = %d, want %d", got, newCost)
+	}
+	if err := login("password123"); err != nil {
+		t.Fatalf("login with the upgraded hash: %v", err)
+	}
+}
@@ -10,13 +10,16 @@ var (
 	ErrInvalidCredentials  = errors.New("invalid credentials")
 	ErrAlreadyBootstrapped = errors.New("an admin user already exists")
 	ErrLastActiveAdmin     = errors.New("at least one active admin must remain")
+	ErrUnknownRole         = errors.New("unknown role")
+	ErrUserDeleted         = errors.New("user is deleted")
 )
 
 // Auth uses the store contracts as its persistence boundary.
 type (
-	User      = store.User
-	UserStore = store.UserStore
-	Store     = store.AuthStore
+	User       = store.User
+	UserFilter = store.UserFilter
+	UserStore  = store.UserStore
+	Store      = store.AuthStore
 )
 
 var (
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AuditEntry is one record of the control-plane audit log.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"`
+	Result    string    `json:"result"`
+	Status    int       `json:"status,omitempty"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	PrevHash  string    `json:"prev_hash,omitempty"`
+	Hash      string    `json:"hash,omitempty"`
+}
+
+// AuditStore keeps audit entries in the order they were appended.
+type AuditStore interface {
+	AppendAuditEntry(ctx context.Context, entry *AuditEntry) error
+	// LastAuditEntry returns the newest entry, or nil when there is none.
+	LastAuditEntry(ctx context.Context) (*AuditEntry, error)
+	// ListAuditEntries returns entries oldest first, and the total count.
+	ListAuditEntries(ctx context.Context, offset, limit int) ([]*AuditEntry, int, error)
+}
+
+// auditRow mirrors the audit_log table. Time is stored as RFC 3339 text
+// with nanoseconds so it reads back exactly as hashed.
+type auditRow struct {
+	Time      string `db:"time"`
+	Actor     string `db:"actor"`
+	Action    string `db:"action"`
+	Target    string `db:"target"`
+	Result    string `db:"result"`
+	Status    int    `db:"status"`
+	ClientIP  string `db:"client_ip"`
+	UserAgent string `db:"user_agent"`
+	RequestID string `db:"request_id"`
+	PrevHash  string `db:"prev_hash"`
+	Hash      string `db:"hash"`
+}
+
+func (r auditRow) toEntry() (*AuditEntry, error) {
+	t, err := time.Parse(time.RFC3339Nano, r.Time)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit entry time %q: %w", r.Time, err)
+	}
+	return &AuditEntry{
+		Time: t, Actor: r.Actor, Action: r.Action, Target: r.Target, Result: r.Result, Status: r.Status,
+		ClientIP: r.ClientIP, UserAgent: r.UserAgent, RequestID: r.RequestID, PrevHash: r.PrevHash, Hash: r.Hash,
+	}, nil
+}
+
+const auditColumns = `time, actor, action, target, result, status, client_ip, user_agent, request_id, prev_hash, hash`
+
+func (s *authStore) AppendAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	return s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		query := db.Rebind(`INSERT INTO audit_log (` + auditColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+		_, err := db.ExecContext(ctx, query,
+			entry.Time.UTC().Format(time.RFC3339Nano), entry.Actor, entry.Action, entry.Target, entry.Result,
+			entry.Status, entry.ClientIP, entry.UserAgent, entry.RequestID, entry.PrevHash, entry.Hash)
+		if err != nil {
+			return fmt.Errorf("failed to append audit entry: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *authStore) LastAuditEntry(ctx context.Context) (*AuditEntry, error) {
+	var row auditRow
+	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		return db.GetContext(ctx, &row, `SELECT `+auditColumns+` FROM audit_log ORDER BY seq DESC LIMIT 1`)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last audit entry: %w", err)
+	}
+	return row.toEntry()
+}
+
+func (s *authStore) ListAuditEntries(ctx context.Context, offset, limit int) ([]*AuditEntry, int, error) {
+	var total int
+	var rows []auditRow
+	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		if err := db.GetContext(ctx, &total, `SELECT COUNT(*) FROM audit_log`); err != nil {
+			return fmt.Errorf("failed to get audit entry count: %w", err)
+		}
+		query := db.Rebind(`SELECT ` + auditColumns + ` FROM audit_log ORDER BY seq LIMIT ? OFFSET ?`)
+		return db.SelectContext(ctx, &rows, query, limit, offset)
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	entries := make([]*AuditEntry, len(rows))
+	for i, row := range rows {
+		entry, err := row.toEntry()
+		if err != nil {
+			return nil, 0, err
+		}
+		entries[i] = entry
+	}
+	return entries, total, nil
+}
@@ -28,6 +28,17 @@ type User struct {
 	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
 	Active       bool              `json:"active" db:"active"`
+	// DeletedAt is set while the user is soft-deleted; such users are
+	// inactive until restored.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// UserFilter narrows ListUsers. The zero value lists every user that is
+// not soft-deleted, active or not.
+type UserFilter struct {
+	ActiveOnly     bool   // leave out deactivated users
+	IncludeDeleted bool   // also list soft-deleted users
+	Username       string // only the user of this name, if set
 }
 
 // UserStore defines the interface for user storage operations
@@ -37,8 +48,13 @@ type UserStore interface {
 	GetUser(ctx context.Context, id string) (*User, error)
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
 	UpdateUser(ctx context.Context, user *User) error
+	// DeleteUser removes the user permanently.
 	DeleteUser(ctx context.Context, id string) error
-	ListUsers(ctx context.Context, offset, limit int) ([]*User, int, error)
+	// SoftDeleteUser deactivates the user and marks it deleted at at.
+	SoftDeleteUser(ctx context.Context, id string, at time.Time) error
+	// RestoreUser reactivates a soft-deleted user.
+	RestoreUser(ctx context.Context, id string) error
+	ListUsers(ctx context.Context, filter UserFilter, offset, limit int) ([]*User, int, error)
 }
 
 // CreateFirstUser inserts user only when the users table is still empty. The
@@ -101,6 +117,13 @@ func (s *authStore) CreateFirstUser(ctx context.Context, user *User) error {
 type AuthStore interface {
 	Store
 	UserStore
+	AuditStore
+}
+
+// SupportedTypes lists the store types NewAuthStore accepts; "postgres"
+// is also accepted as an alias of "postgresql".
+func SupportedTypes() []string {
+	return []string{"sqlite", "postgresql", "memory"}
 }
 
 // NewAuthStore creates a new auth store based on the configuration
@@ -110,8 +133,10 @@ func NewAuthStore(config Config) (AuthStore, error) {
 		return NewSQLiteAuthStore(config)
 	case "postgresql", "postgres":
 		return NewPostgreSQLAuthStore(config)
+	case "memory":
+		return NewMemoryAuthStore(), nil
 	default:
-		return nil, fmt.Errorf("unsupported auth store type: %s", config.Type)
+		return nil, fmt.Errorf("unsupported auth store type %q (supported: %s)", config.Type, strings.Join(SupportedTypes(), ", "))
 	}
 }
 
@@ -133,6 +158,7 @@ type userRow struct {
 	CreatedAt    time.Time      `db:"created_at"`
 	UpdatedAt    time.Time      `db:"updated_at"`
 	Active       bool           `db:"active"`
+	DeletedAt    sql.NullTime   `db:"deleted_at"`
 }
 
 func (r userRow) toUser() *User {
@@ -145,6 +171,10 @@ func (r userRow) toUser() *User {
 		UpdatedAt:    r.UpdatedAt,
 		Active:       r.Active,
 	}
+	if r.DeletedAt.Valid {
+		deletedAt := r.DeletedAt.Time
+		u.DeletedAt = &deletedAt
+	}
 	if r.Roles.Valid {
 		_ = json.Unmarshal([]byte(r.Roles.String), &u.Roles)
 	}
@@ -178,7 +208,7 @@ func (s *authStore) CreateUser(ctx context.Context, user *User) error {
 func (s *authStore) GetUser(ctx context.Context, id string) (*User, error) {
 	var row userRow
 	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
-		query := db.Rebind(`SELECT id, username, password_hash, email, roles, metadata, created_at, updated_at, active
+		query := db.Rebind(`SELECT id, username, password_hash, email, roles, metadata, created_at, updated_at, active, deleted_at
 			FROM users WHERE id = ?`)
 		return db.GetContext(ctx, &row, query, id)
 	})
@@ -194,7 +224,7 @@ func (s *authStore) GetUser(ctx context.Context, id string) (*User, error) {
 func (s *authStore) GetUserByUsername(ctx context.Context, username string) (*User, error) {
 	var row userRow
 	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
-		query := db.Rebind(`SELECT id, username, password_hash, email, roles, metadata, created_at, updated_at, active
+		query := db.Rebind(`SELECT id, username, password_hash, email, roles, metadata, created_at, updated_at, active, deleted_at
 			FROM users WHERE username = ? AND active = true`)
 		return db.GetContext(ctx, &row, query, username)
 	})
@@ -251,16 +281,64 @@ func (s *authStore) DeleteUser(ctx context.Context, id string) error {
 	})
 }
 
-func (s *authStore) ListUsers(ctx context.Context, offset, limit int) ([]*User, int, error) {
+// SoftDeleteUser deactivates a user that is not already deleted.
+func (s *authStore) SoftDeleteUser(ctx context.Context, id string, at time.Time) error {
+	return s.updateDeleted(ctx, `UPDATE users SET active = false, deleted_at = ?, updated_at = ?
+			WHERE id = ? AND deleted_at IS NULL`, at.UTC(), time.Now().UTC(), id)
+}
+
+// RestoreUser reactivates a soft-deleted user.
+func (s *authStore) RestoreUser(ctx context.Context, id string) error {
+	return s.updateDeleted(ctx, `UPDATE users SET active = true, deleted_at = NULL, updated_at = ?
+			WHERE id = ? AND deleted_at IS NOT NULL`, time.Now().UTC(), id)
+}
+
+// updateDeleted runs a soft-delete or restore statement, which matches no
+// row when the user is missing or already in the target state.
+func (s *authStore) updateDeleted(ctx context.Context, query string, args ...any) error {
+	return s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		result, err := db.ExecContext(ctx, db.Rebind(query), args...)
+		if err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if affected == 0 {
+			return ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+func (s *authStore) ListUsers(ctx context.Context, filter UserFilter, offset, limit int) ([]*User, int, error) {
+	var conditions []string
+	var args []any
+	if filter.ActiveOnly {
+		conditions = append(conditions, "active = true")
+	}
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if filter.Username != "" {
+		conditions = append(conditions, "username = ?")
+		args = append(args, filter.Username)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
 	var total int
 	var rows []userRow
 	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
-		if err := db.GetContext(ctx, &total, `SELECT COUNT(*) FROM users`); err != nil {
+		if err := db.GetContext(ctx, &total, db.Rebind(`SELECT COUNT(*) FROM users`+where), args...); err != nil {
 			return fmt.Errorf("failed to get user count: %w", err)
 		}
-		query := db.Rebind(`SELECT id, username, password_hash, email, roles, metadata, created_at, updated_at, active
-			FROM users ORDER BY created_at DESC LIMIT ? OFFSET ?`)
-		return db.SelectContext(ctx, &rows, query, limit, offset)
+		query := db.Rebind(`SELECT id, username, password_hash, email, roles, metadata, created_at, updated_at, active, deleted_at
+			FROM users` + where + ` ORDER BY created_at DESC LIMIT ? OFFSET ?`)
+		return db.SelectContext(ctx, &rows, query, append(args, limit, offset)...)
 	})
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list users: %w", err)
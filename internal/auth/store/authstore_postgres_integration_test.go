@@ -95,7 +95,7 @@ func TestPostgreSQLAuthStore_UserAndClientCRUD(t *testing.T) {
 	require.Equal(t, []string{"user"}, updated.Roles)
 	require.Equal(t, "hash-updated", updated.PasswordHash)
 
-	list, total, err := s.ListUsers(ctx, 0, 10)
+	list, total, err := s.ListUsers(ctx, UserFilter{}, 0, 10)
 	require.NoError(t, err)
 	require.Equal(t, 1, total)
 	require.Len(t, list, 1)
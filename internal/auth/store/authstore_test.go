@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,7 +24,7 @@ func TestSQLiteAuthStoreCanSkipMigrations(t *testing.T) {
 		t.Fatalf("NewSQLiteAuthStore() error: %v", err)
 	}
 	t.Cleanup(func() { _ = s.Close() })
-	if _, _, err := s.ListUsers(context.Background(), 0, 10); err == nil {
+	if _, _, err := s.ListUsers(context.Background(), UserFilter{}, 0, 10); err == nil {
 		t.Fatal("ListUsers() succeeded without a pre-migrated schema")
 	}
 }
@@ -95,7 +96,7 @@ func TestSQLiteAuthStore_UserCRUD(t *testing.T) {
 		t.Fatalf("CreateUser(second) error: %v", err)
 	}
 
-	list, total, err := s.ListUsers(ctx, 0, 10)
+	list, total, err := s.ListUsers(ctx, UserFilter{}, 0, 10)
 	if err != nil {
 		t.Fatalf("ListUsers() error: %v", err)
 	}
@@ -114,6 +115,74 @@ func TestSQLiteAuthStore_UserCRUD(t *testing.T) {
 	}
 }
 
+func TestSQLiteAuthStore_SoftDeleteAndRestore(t *testing.T) {
+	s := newTestSQLiteAuthStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, name := range []string{"alice", "bob", "carol"} {
+		at := now.Add(time.Duration(i) * time.Second)
+		user := &User{ID: name, Username: name, PasswordHash: "hash", CreatedAt: at, UpdatedAt: at, Active: name != "carol"}
+		if err := s.CreateUser(ctx, user); err != nil {
+			t.Fatalf("CreateUser(%s) error: %v", name, err)
+		}
+	}
+
+	if err := s.SoftDeleteUser(ctx, "alice", now); err != nil {
+		t.Fatalf("SoftDeleteUser() error: %v", err)
+	}
+	if err := s.SoftDeleteUser(ctx, "alice", now); err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound on second soft delete, got %v", err)
+	}
+	deleted, err := s.GetUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetUser() of soft-deleted user error: %v", err)
+	}
+	if deleted.Active || deleted.DeletedAt == nil || !deleted.DeletedAt.Equal(now) {
+		t.Fatalf("soft delete not persisted: active=%v deleted_at=%v", deleted.Active, deleted.DeletedAt)
+	}
+
+	names := func(filter UserFilter) string {
+		t.Helper()
+		list, total, err := s.ListUsers(ctx, filter, 0, 10)
+		if err != nil {
+			t.Fatalf("ListUsers(%+v) error: %v", filter, err)
+		}
+		if total != len(list) {
+			t.Fatalf("ListUsers(%+v) total=%d, len=%d", filter, total, len(list))
+		}
+		out := make([]string, len(list))
+		for i, u := range list {
+			out[i] = u.Username
+		}
+		return strings.Join(out, ",")
+	}
+	cases := map[UserFilter]string{
+		{}:                                       "carol,bob",
+		{ActiveOnly: true}:                       "bob",
+		{IncludeDeleted: true}:                   "carol,bob,alice",
+		{IncludeDeleted: true, ActiveOnly: true}: "bob",
+	}
+	for filter, want := range cases {
+		if got := names(filter); got != want {
+			t.Errorf("ListUsers(%+v) = %s, want %s", filter, got, want)
+		}
+	}
+
+	if err := s.RestoreUser(ctx, "alice"); err != nil {
+		t.Fatalf("RestoreUser() error: %v", err)
+	}
+	if err := s.RestoreUser(ctx, "bob"); err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound restoring a live user, got %v", err)
+	}
+	restored, err := s.GetUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetUser() after restore error: %v", err)
+	}
+	if !restored.Active || restored.DeletedAt != nil {
+		t.Fatalf("restore not persisted: active=%v deleted_at=%v", restored.Active, restored.DeletedAt)
+	}
+}
+
 func TestSQLiteAuthStore_PingAndClose(t *testing.T) {
 	s := newTestSQLiteAuthStore(t)
 	if err := s.Ping(context.Background()); err != nil {
@@ -128,6 +197,15 @@ func TestNewAuthStore_Dispatch(t *testing.T) {
 	}
 	_ = s.Close()
 
+	s, err = NewAuthStore(Config{Type: "memory"})
+	if err != nil {
+		t.Fatalf("NewAuthStore(memory) error: %v", err)
+	}
+	if _, ok := s.(*MemoryAuthStore); !ok {
+		t.Fatalf("NewAuthStore(memory) = %T, want *MemoryAuthStore", s)
+	}
+	_ = s.Close()
+
 	if _, err := NewAuthStore(Config{Type: "unsupported"}); err == nil {
 		t.Fatal("expected error for unsupported store type")
 	}
@@ -0,0 +1,291 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryAuthStore implements AuthStore in process memory. Nothing survives
+// Close, so it suits tests and embedding provisr without a database. It
+// is safe for concurrent use and follows the semantics of the SQL stores.
+type MemoryAuthStore struct {
+	mu     sync.RWMutex
+	users  map[string]*User
+	audit  []*AuditEntry
+	closed bool
+}
+
+// NewMemoryAuthStore creates an empty in-memory auth store.
+func NewMemoryAuthStore() *MemoryAuthStore {
+	return &MemoryAuthStore{users: make(map[string]*User)}
+}
+
+// Close drops all users and audit entries; later calls fail.
+func (s *MemoryAuthStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users, s.audit, s.closed = nil, nil, true
+	return nil
+}
+
+func (s *MemoryAuthStore) Ping(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.checkOpen()
+}
+
+func (s *MemoryAuthStore) checkOpen() error {
+	if s.closed {
+		return fmt.Errorf("memory auth store is closed")
+	}
+	return nil
+}
+
+// copyUser returns a deep copy, so callers never share the stored user.
+func copyUser(u *User) *User {
+	c := *u
+	if u.Roles != nil {
+		c.Roles = append([]string(nil), u.Roles...)
+	}
+	if u.Metadata != nil {
+		c.Metadata = make(map[string]string, len(u.Metadata))
+		for k, v := range u.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+	if u.DeletedAt != nil {
+		deletedAt := *u.DeletedAt
+		c.DeletedAt = &deletedAt
+	}
+	return &c
+}
+
+// usernameTaken reports whether another user than id has username.
+func (s *MemoryAuthStore) usernameTaken(username, id string) bool {
+	for _, u := range s.users {
+		if u.Username == username && u.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryAuthStore) insert(user *User) error {
+	if _, ok := s.users[user.ID]; ok || s.usernameTaken(user.Username, user.ID) {
+		return ErrUserAlreadyExists
+	}
+	stored := copyUser(user)
+	stored.DeletedAt = nil
+	s.users[user.ID] = stored
+	return nil
+}
+
+func (s *MemoryAuthStore) CreateUser(ctx context.Context, user *User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	return s.insert(user)
+}
+
+// CreateFirstUser inserts user only when the store holds no user yet.
+func (s *MemoryAuthStore) CreateFirstUser(ctx context.Context, user *User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	if len(s.users) > 0 {
+		return ErrUserAlreadyExists
+	}
+	return s.insert(user)
+}
+
+func (s *MemoryAuthStore) GetUser(ctx context.Context, id string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+	u, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return copyUser(u), nil
+}
+
+// GetUserByUsername finds an active user, like the SQL stores.
+func (s *MemoryAuthStore) GetUserByUsername(ctx context.Context, username string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+	for _, u := range s.users {
+		if u.Username == username && u.Active {
+			return copyUser(u), nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (s *MemoryAuthStore) UpdateUser(ctx context.Context, user *User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	existing, ok := s.users[user.ID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if s.usernameTaken(user.Username, user.ID) {
+		return fmt.Errorf("failed to update user: %w", ErrUserAlreadyExists)
+	}
+	user.UpdatedAt = time.Now().UTC()
+	stored := copyUser(user)
+	// CreatedAt and DeletedAt are not columns UpdateUser writes.
+	stored.CreatedAt, stored.DeletedAt = existing.CreatedAt, existing.DeletedAt
+	s.users[user.ID] = stored
+	return nil
+}
+
+func (s *MemoryAuthStore) DeleteUser(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	if _, ok := s.users[id]; !ok {
+		return ErrUserNotFound
+	}
+	delete(s.users, id)
+	return nil
+}
+
+// SoftDeleteUser deactivates a user that is not already deleted.
+func (s *MemoryAuthStore) SoftDeleteUser(ctx context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	u, ok := s.users[id]
+	if !ok || u.DeletedAt != nil {
+		return ErrUserNotFound
+	}
+	deletedAt := at.UTC()
+	u.Active, u.DeletedAt, u.UpdatedAt = false, &deletedAt, time.Now().UTC()
+	return nil
+}
+
+// RestoreUser reactivates a soft-deleted user.
+func (s *MemoryAuthStore) RestoreUser(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	u, ok := s.users[id]
+	if !ok || u.DeletedAt == nil {
+		return ErrUserNotFound
+	}
+	u.Active, u.DeletedAt, u.UpdatedAt = true, nil, time.Now().UTC()
+	return nil
+}
+
+// ListUsers returns the users matching filter, newest first.
+func (s *MemoryAuthStore) ListUsers(ctx context.Context, filter UserFilter, offset, limit int) ([]*User, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkOpen(); err != nil {
+		return nil, 0, err
+	}
+	var matched []*User
+	for _, u := range s.users {
+		if filter.ActiveOnly && !u.Active {
+			continue
+		}
+		if !filter.IncludeDeleted && u.DeletedAt != nil {
+			continue
+		}
+		if filter.Username != "" && u.Username != filter.Username {
+			continue
+		}
+		matched = append(matched, u)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	page := paginate(len(matched), offset, limit)
+	users := make([]*User, 0, len(page))
+	for _, i := range page {
+		users = append(users, copyUser(matched[i]))
+	}
+	return users, len(matched), nil
+}
+
+func (s *MemoryAuthStore) AppendAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	stored := *entry
+	stored.Time = entry.Time.UTC()
+	s.audit = append(s.audit, &stored)
+	return nil
+}
+
+func (s *MemoryAuthStore) LastAuditEntry(ctx context.Context) (*AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+	if len(s.audit) == 0 {
+		return nil, nil
+	}
+	last := *s.audit[len(s.audit)-1]
+	return &last, nil
+}
+
+func (s *MemoryAuthStore) ListAuditEntries(ctx context.Context, offset, limit int) ([]*AuditEntry, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkOpen(); err != nil {
+		return nil, 0, err
+	}
+	page := paginate(len(s.audit), offset, limit)
+	entries := make([]*AuditEntry, 0, len(page))
+	for _, i := range page {
+		entry := *s.audit[i]
+		entries = append(entries, &entry)
+	}
+	return entries, len(s.audit), nil
+}
+
+// paginate returns the indexes of a LIMIT/OFFSET page over n rows. A
+// negative limit means no limit, as in SQLite.
+func paginate(n, offset, limit int) []int {
+	if offset < 0 {
+		offset = 0
+	}
+	end := n
+	if limit >= 0 && offset+limit < end {
+		end = offset + limit
+	}
+	var page []int
+	for i := offset; i < end; i++ {
+		page = append(page, i)
+	}
+	return page
+}
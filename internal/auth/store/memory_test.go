@@ -0,0 +1,237 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+var _ AuthStore = (*MemoryAuthStore)(nil)
+
+func TestMemoryAuthStore_UserCRUD(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryAuthStore()
+	t.Cleanup(func() { _ = s.Close() })
+
+	now := time.Now().UTC().Truncate(time.Second)
+	user := &User{
+		ID:           "u1",
+		Username:     "alice",
+		PasswordHash: "hash1",
+		Roles:        []string{"admin", "user"},
+		Metadata:     map[string]string{"team": "core"},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Active:       true,
+	}
+	if err := s.CreateFirstUser(ctx, user); err != nil {
+		t.Fatalf("CreateFirstUser() error: %v", err)
+	}
+	if err := s.CreateFirstUser(ctx, &User{ID: "u9", Username: "mallory"}); err != ErrUserAlreadyExists {
+		t.Fatalf("expected ErrUserAlreadyExists on second first user, got %v", err)
+	}
+	if err := s.CreateUser(ctx, user); err != ErrUserAlreadyExists {
+		t.Fatalf("expected ErrUserAlreadyExists on duplicate id, got %v", err)
+	}
+	if err := s.CreateUser(ctx, &User{ID: "u3", Username: "alice"}); err != ErrUserAlreadyExists {
+		t.Fatalf("expected ErrUserAlreadyExists on duplicate username, got %v", err)
+	}
+
+	// Stored users are copies of what the caller passed and gets back.
+	user.Roles[0] = "viewer"
+	got, err := s.GetUser(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetUser() error: %v", err)
+	}
+	if got.Roles[0] != "admin" || got.Metadata["team"] != "core" {
+		t.Fatalf("stored user changed through the caller's copy: %+v", got)
+	}
+	got.Metadata["team"] = "other"
+	if again, _ := s.GetUser(ctx, "u1"); again.Metadata["team"] != "core" {
+		t.Fatalf("stored user changed through a returned copy: %+v", again)
+	}
+
+	byName, err := s.GetUserByUsername(ctx, "alice")
+	if err != nil || byName.ID != "u1" {
+		t.Fatalf("GetUserByUsername() = %+v, %v", byName, err)
+	}
+	if _, err := s.GetUser(ctx, "missing"); err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+
+	got.Email = "alice@example.com"
+	got.Active = false
+	if err := s.UpdateUser(ctx, got); err != nil {
+		t.Fatalf("UpdateUser() error: %v", err)
+	}
+	updated, _ := s.GetUser(ctx, "u1")
+	if updated.Email != "alice@example.com" || updated.Active || !updated.CreatedAt.Equal(now) || !updated.UpdatedAt.After(now) {
+		t.Fatalf("update did not persist: %+v", updated)
+	}
+	if _, err := s.GetUserByUsername(ctx, "alice"); err != ErrUserNotFound {
+		t.Fatalf("expected inactive user to be hidden by username, got %v", err)
+	}
+	if err := s.UpdateUser(ctx, &User{ID: "missing"}); err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound on update of missing user, got %v", err)
+	}
+
+	second := &User{ID: "u2", Username: "bob", CreatedAt: now.Add(time.Second), UpdatedAt: now.Add(time.Second), Active: true}
+	if err := s.CreateUser(ctx, second); err != nil {
+		t.Fatalf("CreateUser(second) error: %v", err)
+	}
+	second.Username = "alice"
+	if err := s.UpdateUser(ctx, second); !errors.Is(err, ErrUserAlreadyExists) {
+		t.Fatalf("expected ErrUserAlreadyExists renaming onto a taken username, got %v", err)
+	}
+
+	list, total, err := s.ListUsers(ctx, UserFilter{}, 0, 10)
+	if err != nil {
+		t.Fatalf("ListUsers() error: %v", err)
+	}
+	if total != 2 || len(list) != 2 || list[0].Username != "bob" {
+		t.Fatalf("ListUsers() = %d users (total %d), want bob first", len(list), total)
+	}
+	page, total, _ := s.ListUsers(ctx, UserFilter{}, 1, 1)
+	if total != 2 || len(page) != 1 || page[0].Username != "alice" {
+		t.Fatalf("second page = %v (total %d), want [alice]", page, total)
+	}
+	if page, _, _ := s.ListUsers(ctx, UserFilter{ActiveOnly: true}, 0, 10); len(page) != 1 || page[0].ID != "u2" {
+		t.Fatalf("active users = %v, want [u2]", page)
+	}
+
+	if err := s.DeleteUser(ctx, "u1"); err != nil {
+		t.Fatalf("DeleteUser() error: %v", err)
+	}
+	if err := s.DeleteUser(ctx, "u1"); err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound on second delete, got %v", err)
+	}
+}
+
+func TestMemoryAuthStore_SoftDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryAuthStore()
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, name := range []string{"alice", "bob"} {
+		at := now.Add(time.Duration(i) * time.Second)
+		if err := s.CreateUser(ctx, &User{ID: name, Username: name, CreatedAt: at, UpdatedAt: at, Active: true}); err != nil {
+			t.Fatalf("CreateUser(%s) error: %v", name, err)
+		}
+	}
+
+	if err := s.SoftDeleteUser(ctx, "alice", now); err != nil {
+		t.Fatalf("SoftDeleteUser() error: %v", err)
+	}
+	if err := s.SoftDeleteUser(ctx, "alice", now); err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound on second soft delete, got %v", err)
+	}
+	deleted, _ := s.GetUser(ctx, "alice")
+	if deleted.Active || deleted.DeletedAt == nil || !deleted.DeletedAt.Equal(now) {
+		t.Fatalf("soft delete not applied: active=%v deleted_at=%v", deleted.Active, deleted.DeletedAt)
+	}
+	if list, total, _ := s.ListUsers(ctx, UserFilter{}, 0, 10); total != 1 || list[0].ID != "bob" {
+		t.Fatalf("deleted user listed by default: %v", list)
+	}
+	if _, total, _ := s.ListUsers(ctx, UserFilter{IncludeDeleted: true}, 0, 10); total != 2 {
+		t.Fatalf("IncludeDeleted total = %d, want 2", total)
+	}
+
+	if err := s.RestoreUser(ctx, "alice"); err != nil {
+		t.Fatalf("RestoreUser() error: %v", err)
+	}
+	if err := s.RestoreUser(ctx, "bob"); err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound restoring a live user, got %v", err)
+	}
+	restored, _ := s.GetUser(ctx, "alice")
+	if !restored.Active || restored.DeletedAt != nil {
+		t.Fatalf("restore not applied: active=%v deleted_at=%v", restored.Active, restored.DeletedAt)
+	}
+}
+
+func TestMemoryAuthStore_AuditEntries(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryAuthStore()
+	if last, err := s.LastAuditEntry(ctx); err != nil || last != nil {
+		t.Fatalf("LastAuditEntry() on empty store = %+v, %v", last, err)
+	}
+	base := time.Now()
+	for i := 0; i < 3; i++ {
+		entry := &AuditEntry{Time: base.Add(time.Duration(i) * time.Second), Actor: "admin", Action: fmt.Sprintf("action-%d", i), Result: "success"}
+		if err := s.AppendAuditEntry(ctx, entry); err != nil {
+			t.Fatalf("AppendAuditEntry() error: %v", err)
+		}
+	}
+
+	last, err := s.LastAuditEntry(ctx)
+	if err != nil || last.Action != "action-2" || last.Time.Location() != time.UTC {
+		t.Fatalf("LastAuditEntry() = %+v, %v", last, err)
+	}
+	entries, total, err := s.ListAuditEntries(ctx, 1, 5)
+	if err != nil {
+		t.Fatalf("ListAuditEntries() error: %v", err)
+	}
+	if total != 3 || len(entries) != 2 || entries[0].Action != "action-1" || entries[1].Action != "action-2" {
+		t.Fatalf("ListAuditEntries(1, 5) = %d entries (total %d), want action-1, action-2", len(entries), total)
+	}
+	if entries, _, _ := s.ListAuditEntries(ctx, 5, 5); len(entries) != 0 {
+		t.Fatalf("page past the end = %d entries, want 0", len(entries))
+	}
+}
+
+func TestMemoryAuthStore_PingAndClose(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryAuthStore()
+	if err := s.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if err := s.Ping(ctx); err == nil {
+		t.Fatal("Ping() succeeded after Close")
+	}
+	if _, _, err := s.ListUsers(ctx, UserFilter{}, 0, 10); err == nil {
+		t.Fatal("ListUsers() succeeded after Close")
+	}
+}
+
+func TestMemoryAuthStore_ConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryAuthStore()
+	var wg sync.WaitGroup
+	var firstUsers sync.Map
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("u%d", i)
+			if s.CreateFirstUser(ctx, &User{ID: id + "-first", Username: id + "-first", Active: true}) == nil {
+				firstUsers.Store(id, true)
+			}
+			if err := s.CreateUser(ctx, &User{ID: id, Username: id, Active: true}); err != nil {
+				t.Errorf("CreateUser(%s) error: %v", id, err)
+				return
+			}
+			_ = s.AppendAuditEntry(ctx, &AuditEntry{Time: time.Now(), Actor: id, Action: "create", Result: "success"})
+			_, _, _ = s.ListUsers(ctx, UserFilter{}, 0, 100)
+			_ = s.SoftDeleteUser(ctx, id, time.Now())
+			_ = s.RestoreUser(ctx, id)
+		}(i)
+	}
+	wg.Wait()
+
+	first := 0
+	firstUsers.Range(func(_, _ any) bool { first++; return true })
+	// Another goroutine's CreateUser may win before any first user.
+	if first > 1 {
+		t.Fatalf("%d goroutines created the first user, want at most 1", first)
+	}
+	if _, total, _ := s.ListUsers(ctx, UserFilter{ActiveOnly: true}, 0, 100); total != 20+first {
+		t.Fatalf("active users = %d, want %d", total, 20+first)
+	}
+	if _, total, _ := s.ListAuditEntries(ctx, 0, 100); total != 20 {
+		t.Fatalf("audit entries = %d, want 20", total)
+	}
+}
@@ -10,6 +10,7 @@ type AuthMethod string
 const (
 	AuthMethodBasic AuthMethod = "basic" // username/password
 	AuthMethodJWT   AuthMethod = "jwt"   // JWT token
+	AuthMethodOIDC  AuthMethod = "oidc"  // OIDC ID token, exchanged for a provisr token
 )
 
 // User type is imported from the store package
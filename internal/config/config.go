@@ -2,10 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,25 +15,58 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/core/instancename"
 	"github.com/loykin/provisr/internal/auth"
+	authstore "github.com/loykin/provisr/internal/auth/store"
+	"github.com/loykin/provisr/internal/unixsock"
 	metricsadapter "github.com/loykin/provisr/pkg/metrics"
 )
 
 type Config struct {
+	// Include lists further config files (glob patterns, relative to this
+	// file) merged underneath this one; see parseConfigFile.
+	Include           []string        `mapstructure:"include"`
 	UseOSEnv          bool            `mapstructure:"use_os_env"`
 	EnvFiles          []string        `mapstructure:"env_files"`
 	Env               []string        `mapstructure:"env"`
 	ProgramsDirectory string          `mapstructure:"programs_directory"`
 	PIDDir            string          `mapstructure:"pid_dir"`
+	PIDDirMode        string          `mapstructure:"pid_dir_mode"`  // octal mode for a created pid_dir; default "0750"
+	PIDDirOwner       string          `mapstructure:"pid_dir_owner"` // "user[:group]" to own a created pid_dir
 	Groups            []GroupConfig   `mapstructure:"groups"`
 	History           *HistoryConfig  `mapstructure:"history"`
 	Metrics           *MetricsConfig  `mapstructure:"metrics"`
 	Log               *core.LogConfig `mapstructure:"log"`
 	Daemon            *DaemonConfig   `mapstructure:"daemon"`
 	Server            *ServerConfig   `mapstructure:"server"`
+	Tracing           *TracingConfig  `mapstructure:"tracing"`
 
 	// Inline processes parsed as discriminated union entries
 	Processes []ProcessConfig `mapstructure:"processes"`
+
+	// Manager-wide default for processes that don't set
+	// health_check_interval; 0 keeps the built-in 1s.
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"`
+
+	// RestartJitter is the upper bound of a random delay added before each
+	// automatic restart; 0 disables it.
+	RestartJitter time.Duration `mapstructure:"restart_jitter"`
+
+	// MaxConcurrentStarts caps how many processes start at the same time;
+	// 0 means unlimited.
+	MaxConcurrentStarts int `mapstructure:"max_concurrent_starts"`
+
+	// InstanceNaming names the instances of processes with instances > 1;
+	// unset keeps base-1, base-2, ...
+	InstanceNaming *InstanceNamingConfig `mapstructure:"instance_naming"`
+
+	// ValidateCommands rejects a process whose command is not found on
+	// PATH when it is registered, instead of when it fails to start.
+	ValidateCommands bool `mapstructure:"validate_commands"`
+
+	// ChildSubreaper adopts and reaps the orphaned descendants of managed
+	// processes (Linux only).
+	ChildSubreaper bool `mapstructure:"child_subreaper"`
 }
 
 type LoadedConfig struct {
@@ -51,9 +86,11 @@ type GroupConfig struct {
 }
 
 type HistoryConfig struct {
-	Enabled bool                `mapstructure:"enabled"`
-	Primary string              `mapstructure:"primary"`
-	Stores  HistoryStoresConfig `mapstructure:"stores"`
+	Enabled bool                  `mapstructure:"enabled"`
+	Primary string                `mapstructure:"primary"`
+	Stores  HistoryStoresConfig   `mapstructure:"stores"`
+	Webhook *WebhookHistoryConfig `mapstructure:"webhook"`
+	NATS    *NATSHistoryConfig    `mapstructure:"nats"`
 }
 
 type HistoryStoresConfig struct {
@@ -93,14 +130,88 @@ type OpenSearchHistoryStoreConfig struct {
 	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
 }
 
+// WebhookHistoryConfig POSTs lifecycle events to an HTTP endpoint. Unlike
+// the stores it is write-only, so it can't be the history primary.
+type WebhookHistoryConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	URL           string        `mapstructure:"url"`
+	Secret        string        `mapstructure:"secret"`
+	Events        []string      `mapstructure:"events"` // start, stop, restart, fatal; empty sends all
+	Timeout       time.Duration `mapstructure:"timeout"`
+	MaxRetries    int           `mapstructure:"max_retries"`
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+	QueueSize     int           `mapstructure:"queue_size"`
+}
+
+// NATSHistoryConfig publishes lifecycle events to a NATS subject. It needs a
+// provisr binary built with the "nats" build tag.
+type NATSHistoryConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	URL       string `mapstructure:"url"`
+	Subject   string `mapstructure:"subject"`    // may contain {type} and {name}
+	QueueSize int    `mapstructure:"queue_size"` // events beyond this are dropped and counted
+}
+
+// TracingConfig enables OpenTelemetry spans for manager operations,
+// lifecycle hooks and REST handlers, exported over OTLP/HTTP.
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Endpoint    string  `mapstructure:"endpoint"`     // collector host:port; empty honours OTEL_EXPORTER_OTLP_* (default localhost:4318)
+	Insecure    bool    `mapstructure:"insecure"`     // plain HTTP instead of HTTPS
+	ServiceName string  `mapstructure:"service_name"` // default "provisr"
+	SampleRatio float64 `mapstructure:"sample_ratio"` // 0 < ratio <= 1; 0 samples everything
+}
+
+// InstanceNamingConfig is the [instance_naming] section: Format is a
+// text/template over .Base and .Index (default "{{.Base}}-{{.Index}}"),
+// Start the index of the first instance (default 1) and Padding the number
+// of digits indexes are zero-padded to.
+type InstanceNamingConfig struct {
+	Format  string `mapstructure:"format"`
+	Start   *int   `mapstructure:"start"`
+	Padding int    `mapstructure:"padding"`
+}
+
+// Scheme compiles the section; a nil section is the default scheme.
+func (c *InstanceNamingConfig) Scheme() (*instancename.Scheme, error) {
+	if c == nil {
+		return nil, nil
+	}
+	start := instancename.DefaultStart
+	if c.Start != nil {
+		start = *c.Start
+	}
+	return instancename.New(c.Format, start, c.Padding)
+}
+
 type MetricsConfig struct {
 	Enabled        bool                  `mapstructure:"enabled"`
 	Listen         string                `mapstructure:"listen"`
 	ProcessMetrics *ProcessMetricsConfig `mapstructure:"process_metrics"`
+	// Labels are constant labels (e.g. host, env, cluster) added to every
+	// metric, so several daemons can share one Prometheus.
+	Labels map[string]string `mapstructure:"labels"`
+	// Push sends metrics to a Pushgateway in addition to (or instead of)
+	// serving them on Listen.
+	Push *MetricsPushConfig `mapstructure:"push"`
+	// Path is where Listen serves metrics (default /metrics).
+	Path string             `mapstructure:"path"`
+	Auth *MetricsAuthConfig `mapstructure:"auth"`
+}
+
+// MetricsAuthConfig protects the metrics endpoint, since process metrics
+// reveal what runs on the host. Scrapers send BearerToken when it is set;
+// otherwise they authenticate as a [server.auth] user with process read
+// permission.
+type MetricsAuthConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	BearerToken string `mapstructure:"bearer_token"`
 }
 
 type ProcessMetricsConfig = metricsadapter.ProcessMetricsConfig
 
+type MetricsPushConfig = metricsadapter.PushConfig
+
 type DaemonConfig struct {
 	PIDFile string `mapstructure:"pid_file"`
 	LogFile string `mapstructure:"log_file"`
@@ -111,17 +222,116 @@ type ServerConfig struct {
 	BasePath string      `mapstructure:"base_path"`
 	TLS      *TLSConfig  `mapstructure:"tls"`
 	Auth     *AuthConfig `mapstructure:"auth"`
+	GRPC     *GRPCConfig `mapstructure:"grpc"`
+	CORS     *CORSConfig `mapstructure:"cors"`
+
+	// SocketMode is the octal file mode (e.g. "0660") of the Unix socket
+	// created when Listen is a unix:///path address; default "0600".
+	SocketMode string `mapstructure:"socket_mode"`
+
+	// AccessLog enables one structured log line per REST request.
+	AccessLog *AccessLogConfig `mapstructure:"access_log"`
+
+	// RateLimit throttles REST clients with a token bucket each.
+	RateLimit *RateLimitConfig `mapstructure:"rate_limit"`
+
+	// Audit records every mutating REST call and auth event.
+	Audit *AuditConfig `mapstructure:"audit"`
+}
+
+// AuditConfig writes the control-plane audit log: who (the authenticated
+// user, or "anonymous"), what action on which target, when, and whether it
+// succeeded. Sink "file" (default) appends JSON lines to Path; "store"
+// writes to the [server.auth] store. HashChain links every entry to the
+// previous one so tampering can be detected with `provisr audit verify`.
+type AuditConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Sink      string `mapstructure:"sink"` // "file" or "store"
+	Path      string `mapstructure:"path"`
+	HashChain bool   `mapstructure:"hash_chain"`
+}
+
+// RateLimitConfig gives every client a token bucket refilled at
+// RequestsPerSecond and holding up to Burst requests. Key selects what a
+// client is: "ip" (default) limits every route by remote address;
+// "client" limits authenticated routes by username, falling back to the
+// remote address when auth is disabled, and limits failed authentication
+// by remote address. Either way the gRPC server limits failed
+// authentication by peer address.
+type RateLimitConfig struct {
+	Enabled           bool    `mapstructure:"enabled"`
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"` // default: RequestsPerSecond rounded up
+	Key               string  `mapstructure:"key"`   // "ip" or "client"
+}
+
+// AccessLogConfig enables request logging through the daemon's [log]
+// slog settings. Every request gets an ID, taken from RequestIDHeader when
+// the caller sends one and generated otherwise, echoed in the response.
+type AccessLogConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`
+	RequestIDHeader string `mapstructure:"request_id_header"` // default "X-Request-ID"
+}
+
+// SocketFileMode parses SocketMode, returning 0 (the listener default) when
+// it is unset.
+func (s ServerConfig) SocketFileMode() (os.FileMode, error) {
+	if s.SocketMode == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(s.SocketMode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("server.socket_mode must be an octal permission like 0660, got %q", s.SocketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// CORSConfig lets browsers on other origins call the REST API. Disabled
+// unless Enabled is set, and then only the listed origins are allowed.
+type CORSConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"` // exact origins, or "*" for any (not with credentials)
+	AllowedMethods   []string      `mapstructure:"allowed_methods"` // default GET, POST, DELETE
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"` // default Authorization, Content-Type
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age"` // preflight cache lifetime; 0 leaves it to the browser
+}
+
+// GRPCConfig enables the gRPC API on its own listen address. It shares the
+// server's auth and TLS settings.
+type GRPCConfig struct {
+	Listen string `mapstructure:"listen"`
 }
 
 type TLSConfig struct {
 	Enabled      bool        `mapstructure:"enabled"`
-	MinVersion   string      `mapstructure:"min_version"`
-	MaxVersion   string      `mapstructure:"max_version"`
+	MinVersion   string      `mapstructure:"min_version"`   // 1.2 (default) or 1.3
+	MaxVersion   string      `mapstructure:"max_version"`   // 1.3 (default) or 1.2
+	CipherSuites []string    `mapstructure:"cipher_suites"` // TLS 1.2 suites; default DefaultCipherSuites
 	CertFile     string      `mapstructure:"cert_file"`
 	KeyFile      string      `mapstructure:"key_file"`
 	Dir          string      `mapstructure:"dir"`
 	AutoGenerate bool        `mapstructure:"auto_generate"`
 	AutoGen      *AutoGenTLS `mapstructure:"auto_gen"`
+	// ClientAuth is none (default), request, require or require_and_verify.
+	// Client certificates verified against ClientCAFile authenticate as the
+	// auth user named by their CN or a SAN.
+	ClientAuth   string `mapstructure:"client_auth"`
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// ACME, when enabled, obtains and renews the certificate from an ACME
+	// CA such as Let's Encrypt instead of using the cert files or dir.
+	ACME *ACMEConfig `mapstructure:"acme"`
+}
+
+// ACMEConfig obtains server certificates from an ACME CA. Enabling it
+// accepts the CA's terms of service.
+type ACMEConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Domains      []string `mapstructure:"domains"`       // the only host names certificates are requested for
+	Email        string   `mapstructure:"email"`         // optional contact for expiry and account notices
+	CacheDir     string   `mapstructure:"cache_dir"`     // account key and certificates; required
+	DirectoryURL string   `mapstructure:"directory_url"` // default Let's Encrypt production
+	HTTPListen   string   `mapstructure:"http_listen"`   // HTTP-01 challenge address, default ":80"
 }
 
 type AutoGenTLS struct {
@@ -130,6 +340,7 @@ type AutoGenTLS struct {
 	DNSNames     []string `mapstructure:"dns_names"`
 	IPAddresses  []string `mapstructure:"ip_addresses"`
 	ValidDays    int      `mapstructure:"valid_days"`
+	KeyType      string   `mapstructure:"key_type"` // rsa2048 (default), rsa4096 or ecdsa-p256
 }
 
 type AuthConfig struct {
@@ -138,13 +349,23 @@ type AuthConfig struct {
 	JWTSecret  string          `mapstructure:"jwt_secret"`
 	TokenTTL   time.Duration   `mapstructure:"token_ttl"`
 	BcryptCost int             `mapstructure:"bcrypt_cost"`
+	OIDC       *AuthOIDCConfig `mapstructure:"oidc"`
+	LDAP       *AuthLDAPConfig `mapstructure:"ldap"`
 }
 
-type AuthStoreConfig = auth.StoreConfig
+type (
+	AuthStoreConfig = auth.StoreConfig
+	AuthOIDCConfig  = auth.OIDCConfig
+	AuthLDAPConfig  = auth.LDAPConfig
+)
 
 type ProcessConfig struct {
 	Type string         `mapstructure:"type"` // process, cronjob
 	Spec map[string]any `mapstructure:"spec"` // specific config
+
+	// source is the included file declaring the entry; empty for the main
+	// config file.
+	source string
 }
 
 // helper to decode map[string]any to a target type using mapstructure
@@ -234,6 +455,12 @@ func decodeProcessEntry(pc ProcessConfig, ctx string) (core.Spec, *core.CronJob,
 }
 
 func LoadConfig(configPath string) (*LoadedConfig, error) {
+	// Every relative path in the config resolves against the config file's
+	// directory. Anchor it now so the loaded specs carry absolute paths and
+	// do not depend on the daemon's working directory.
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
 	var raw Config
 
 	if err := parseConfigFile(configPath, &raw); err != nil {
@@ -249,20 +476,32 @@ func LoadConfig(configPath string) (*LoadedConfig, error) {
 	config.Specs = make([]core.Spec, 0)
 	config.CronJobs = []core.CronJob{}
 
+	// sources maps every process name to the file declaring it, so a name
+	// defined twice is reported with both places.
+	sources := make(map[string]string)
+
 	// 1) Inline processes: discriminated union decoding (refactored)
 	for _, pc := range config.Processes {
-		spec, job, err := decodeProcessEntry(pc, "inline processes")
+		source, ctx := configPath, "inline processes"
+		if pc.source != "" {
+			source, ctx = pc.source, pc.source
+		}
+		spec, job, err := decodeProcessEntry(pc, ctx)
 		if err != nil {
 			return nil, err
 		}
+		if err := claimProcessName(sources, spec.Name, source); err != nil {
+			return nil, err
+		}
 		if job != nil {
-			resolveCronJobPaths(job, filepath.Dir(configPath))
+			resolveCronJobPaths(job, filepath.Dir(source))
 			spec = *job.JobTemplate.ToProcessSpec()
 		} else {
-			resolveSpecPaths(&spec, filepath.Dir(configPath))
+			resolveSpecPaths(&spec, filepath.Dir(source))
 		}
-		// Mark as declared in the main config file, not a programs-directory
-		// file or an API registration — see process.Spec.InlineConfig.
+		// Mark as declared in the config file (or one it includes), not a
+		// programs-directory file or an API registration — see
+		// process.Spec.InlineConfig.
 		spec.InlineConfig = true
 		// convert detectors after decode
 		if err := convertDetectorConfigs(&spec); err != nil {
@@ -293,7 +532,7 @@ func LoadConfig(configPath string) (*LoadedConfig, error) {
 
 	config.ResolvedProgramsDirectory = programsDir
 
-	if specs, jobs, err := loadProgramEntries(programsDir); err != nil {
+	if specs, jobs, err := loadProgramEntries(programsDir, sources); err != nil {
 		return nil, fmt.Errorf("failed to load programs from %s: %w", programsDir, err)
 	} else {
 		// convert detectors per program spec for consistency
@@ -385,10 +624,27 @@ func resolveConfigPaths(cfg *Config, baseDir string) {
 		cfg.Daemon.LogFile = resolve(cfg.Daemon.LogFile)
 	}
 	if cfg.Server != nil {
+		resolveSocket := func(addr string) string {
+			if path, ok := unixsock.Path(addr); ok {
+				return unixsock.Scheme + resolve(path)
+			}
+			return addr
+		}
+		cfg.Server.Listen = resolveSocket(cfg.Server.Listen)
+		if cfg.Server.GRPC != nil {
+			cfg.Server.GRPC.Listen = resolveSocket(cfg.Server.GRPC.Listen)
+		}
 		if cfg.Server.TLS != nil {
 			cfg.Server.TLS.CertFile = resolve(cfg.Server.TLS.CertFile)
 			cfg.Server.TLS.KeyFile = resolve(cfg.Server.TLS.KeyFile)
 			cfg.Server.TLS.Dir = resolve(cfg.Server.TLS.Dir)
+			cfg.Server.TLS.ClientCAFile = resolve(cfg.Server.TLS.ClientCAFile)
+			if cfg.Server.TLS.ACME != nil {
+				cfg.Server.TLS.ACME.CacheDir = resolve(cfg.Server.TLS.ACME.CacheDir)
+			}
+		}
+		if cfg.Server.Audit != nil {
+			cfg.Server.Audit.Path = resolve(cfg.Server.Audit.Path)
 		}
 		if cfg.Server.Auth != nil && strings.EqualFold(cfg.Server.Auth.Store.Type, "sqlite") {
 			if cfg.Server.Auth.Store.Path != ":memory:" {
@@ -404,25 +660,227 @@ func resolveConfigPaths(cfg *Config, baseDir string) {
 	}
 }
 
-func parseConfigFile(configPath string, out interface{}) error {
+// parseConfigFile reads configPath and the files its include patterns
+// match. Included files are applied in order and the main file last, each
+// setting it sets replacing an earlier one (a table such as [server] as a
+// whole), while lists such as processes, groups and env are concatenated.
+// Relative paths in an included file resolve against that file's
+// directory. Included files may not include others.
+func parseConfigFile(configPath string, out *Config) error {
+	main, err := readConfigFile(configPath)
+	if err != nil {
+		return err
+	}
+	includes, err := resolveIncludes(configPath, main.Include)
+	if err != nil {
+		return err
+	}
+
+	var merged Config
+	for _, path := range includes {
+		inc, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("include %s: %w", path, err)
+		}
+		if len(inc.Include) > 0 {
+			return fmt.Errorf("include %s: nested include is not supported", path)
+		}
+		for i := range inc.Processes {
+			inc.Processes[i].source = path
+		}
+		resolveConfigPaths(inc, filepath.Dir(path))
+		overlayConfig(&merged, inc)
+	}
+	overlayConfig(&merged, main)
+	merged.Include = main.Include
+	*out = merged
+	return nil
+}
+
+// readConfigFile parses a single config file, substituting environment
+// variables (see expandConfigEnv).
+func readConfigFile(path string) (*Config, error) {
 	v := viper.New()
-	v.SetConfigFile(configPath)
+	v.SetConfigFile(path)
 
 	if err := v.ReadInConfig(); err != nil {
-		return fmt.Errorf("failed to read config file: %w", err)
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := expandConfigEnv(v); err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := v.UnmarshalExact(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// resolveIncludes expands the include patterns of configPath, relative to
+// its directory, into the files to read in order. A pattern without glob
+// characters must name an existing file; a glob may match nothing.
+func resolveIncludes(configPath string, patterns []string) ([]string, error) {
+	self, _ := filepath.Abs(configPath)
+	seen := make(map[string]struct{})
+	var files []string
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("include: empty pattern")
+		}
+		if !isConfigAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(configPath), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("include %q: file not found", pattern)
+		}
+		for _, match := range matches {
+			abs, _ := filepath.Abs(match)
+			if abs == self {
+				continue
+			}
+			if _, dup := seen[abs]; dup {
+				continue
+			}
+			seen[abs] = struct{}{}
+			files = append(files, match)
+		}
+	}
+	return files, nil
+}
+
+// overlayConfig applies src on top of dst: slices are appended, and any
+// other field src sets replaces dst's.
+func overlayConfig(dst, src *Config) {
+	dv := reflect.ValueOf(dst).Elem()
+	sv := reflect.ValueOf(src).Elem()
+	for i := 0; i < dv.NumField(); i++ {
+		df, sf := dv.Field(i), sv.Field(i)
+		switch {
+		case df.Kind() == reflect.Slice:
+			df.Set(reflect.AppendSlice(df, sf))
+		case !sf.IsZero():
+			df.Set(sf)
+		}
+	}
+}
+
+// expandConfigEnv substitutes ${VAR} and ${VAR:-default} in the string
+// values of v from the daemon's environment. Processes and env are left
+// alone: their ${VAR} references are expanded when a process starts,
+// against the process environment.
+func expandConfigEnv(v *viper.Viper) error {
+	for _, key := range v.AllKeys() {
+		top, _, _ := strings.Cut(key, ".")
+		if top == "processes" || top == "env" {
+			continue
+		}
+		val, changed, err := expandEnvValue(v.Get(key))
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if changed {
+			v.Set(key, val)
+		}
+	}
+	return nil
+}
+
+func expandEnvValue(val any) (any, bool, error) {
+	switch x := val.(type) {
+	case string:
+		s, err := expandEnv(x)
+		return s, s != x, err
+	case []any:
+		out := make([]any, len(x))
+		changed := false
+		for i, elem := range x {
+			e, c, err := expandEnvValue(elem)
+			if err != nil {
+				return nil, false, err
+			}
+			out[i], changed = e, changed || c
+		}
+		return out, changed, nil
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		changed := false
+		for k, elem := range x {
+			e, c, err := expandEnvValue(elem)
+			if err != nil {
+				return nil, false, err
+			}
+			out[k], changed = e, changed || c
+		}
+		return out, changed, nil
 	}
+	return val, false, nil
+}
 
-	if err := v.UnmarshalExact(out); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+// expandEnv replaces ${VAR} in s with the value of VAR, which must be set,
+// and ${VAR:-default} with default when VAR is unset or empty. $${ is a
+// literal ${.
+func expandEnv(s string) (string, error) {
+	var b strings.Builder
+	rest := s
+	for {
+		i := strings.Index(rest, "${")
+		if i < 0 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		if i > 0 && rest[i-1] == '$' {
+			b.WriteString(rest[:i-1])
+			b.WriteString("${")
+			rest = rest[i+2:]
+			continue
+		}
+		end := strings.IndexByte(rest[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated ${ in %q", s)
+		}
+		name, def, hasDef := strings.Cut(rest[i+2:i+end], ":-")
+		if name == "" {
+			return "", fmt.Errorf("empty variable name in %q", s)
+		}
+		val, ok := os.LookupEnv(name)
+		if !ok || (hasDef && val == "") {
+			if !hasDef {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+			val = def
+		}
+		b.WriteString(rest[:i])
+		b.WriteString(val)
+		rest = rest[i+end+1:]
 	}
+}
 
+// claimProcessName records that source declares name, failing if another
+// file (or the same one) already did.
+func claimProcessName(sources map[string]string, name, source string) error {
+	if prev, ok := sources[name]; ok {
+		if prev == source {
+			return fmt.Errorf("process %q is defined more than once in %s", name, source)
+		}
+		return fmt.Errorf("process %q in %s is already defined in %s", name, source, prev)
+	}
+	sources[name] = source
 	return nil
 }
 
 // loadProgramEntries loads program entries from the programs directory using the same
 // discriminated-union format as inline [[processes]] blocks: {type, spec}.
 // Supported file extensions: toml, yaml/yml, json. Files use the discriminated process format.
-func loadProgramEntries(programsDir string) ([]core.Spec, []core.CronJob, error) {
+// sources holds the names declared so far (see claimProcessName); it may be nil.
+func loadProgramEntries(programsDir string, sources map[string]string) ([]core.Spec, []core.CronJob, error) {
+	if sources == nil {
+		sources = make(map[string]string)
+	}
 	infos, err := os.ReadDir(programsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -469,6 +927,9 @@ func loadProgramEntries(programsDir string) ([]core.Spec, []core.CronJob, error)
 		if err != nil {
 			return nil, nil, err
 		}
+		if err := claimProcessName(sources, sp.Name, full); err != nil {
+			return nil, nil, err
+		}
 		if jb != nil {
 			resolveCronJobPaths(jb, filepath.Dir(full))
 			sp = *jb.JobTemplate.ToProcessSpec()
@@ -526,21 +987,109 @@ func resolveLifecyclePaths(hooks *core.LifecycleHooks, resolve func(string) stri
 }
 
 func validateConfig(cfg *Config) error {
+	if t := cfg.Tracing; t != nil && t.Enabled && (t.SampleRatio < 0 || t.SampleRatio > 1) {
+		return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+	}
+	if cfg.HealthCheckInterval < 0 {
+		return fmt.Errorf("health_check_interval cannot be negative")
+	}
+	if cfg.RestartJitter < 0 {
+		return fmt.Errorf("restart_jitter cannot be negative")
+	}
+	if cfg.MaxConcurrentStarts < 0 {
+		return fmt.Errorf("max_concurrent_starts cannot be negative")
+	}
+	if _, err := cfg.InstanceNaming.Scheme(); err != nil {
+		return fmt.Errorf("instance_naming: %w", err)
+	}
+	if err := core.ValidateDirOptions(cfg.PIDDirMode, cfg.PIDDirOwner); err != nil {
+		return fmt.Errorf("pid_dir: %w", err)
+	}
+	if cfg.Log != nil {
+		if err := core.ValidateDirOptions(cfg.Log.File.DirMode, cfg.Log.File.DirOwner); err != nil {
+			return fmt.Errorf("log: %w", err)
+		}
+	}
 	if cfg.Server != nil {
 		if cfg.Server.TLS != nil {
-			validTLSVersion := func(value string) bool {
-				switch value {
-				case "", "default", "1.2", "1.3", "TLS1.2", "TLS1.3", "tls1.2", "tls1.3":
-					return true
-				default:
-					return false
+			if _, _, err := cfg.Server.TLS.Versions(); err != nil {
+				return fmt.Errorf("server.tls: %w", err)
+			}
+			if _, err := cfg.Server.TLS.CipherSuiteIDs(); err != nil {
+				return fmt.Errorf("server.tls.cipher_suites: %w", err)
+			}
+			if autoGen := cfg.Server.TLS.AutoGen; autoGen != nil {
+				if err := autoGen.Validate(); err != nil {
+					return fmt.Errorf("server.tls.auto_gen: %w", err)
 				}
 			}
-			if !validTLSVersion(cfg.Server.TLS.MinVersion) || !validTLSVersion(cfg.Server.TLS.MaxVersion) {
-				return fmt.Errorf("server.tls min_version and max_version must be 1.2 or 1.3")
+			switch cfg.Server.TLS.ClientAuth {
+			case "", "none", "request", "require":
+			case "require_and_verify":
+				if cfg.Server.TLS.ClientCAFile == "" {
+					return fmt.Errorf("server.tls.client_ca_file is required when client_auth is require_and_verify")
+				}
+			default:
+				return fmt.Errorf("server.tls.client_auth must be none, request, require or require_and_verify")
 			}
-			if cfg.Server.TLS.MinVersion == "1.3" && cfg.Server.TLS.MaxVersion == "1.2" {
-				return fmt.Errorf("server.tls.max_version must not be lower than min_version")
+			if acme := cfg.Server.TLS.ACME; acme != nil && acme.Enabled {
+				if len(acme.Domains) == 0 {
+					return fmt.Errorf("server.tls.acme.domains is required when acme is enabled")
+				}
+				if acme.CacheDir == "" {
+					return fmt.Errorf("server.tls.acme.cache_dir is required when acme is enabled")
+				}
+			}
+		}
+		if _, err := cfg.Server.SocketFileMode(); err != nil {
+			return err
+		}
+		if path, ok := unixsock.Path(cfg.Server.Listen); ok && path == "" {
+			return fmt.Errorf("server.listen: unix socket path is empty")
+		}
+		if cors := cfg.Server.CORS; cors != nil && cors.Enabled {
+			if len(cors.AllowedOrigins) == 0 {
+				return fmt.Errorf("server.cors.allowed_origins is required when cors is enabled")
+			}
+			for _, origin := range cors.AllowedOrigins {
+				if origin == "*" && cors.AllowCredentials {
+					return fmt.Errorf("server.cors: allowed_origins \"*\" cannot be combined with allow_credentials")
+				}
+			}
+		}
+		if rl := cfg.Server.RateLimit; rl != nil && rl.Enabled {
+			if rl.RequestsPerSecond <= 0 {
+				return fmt.Errorf("server.rate_limit.requests_per_second must be positive")
+			}
+			if rl.Burst < 0 {
+				return fmt.Errorf("server.rate_limit.burst must not be negative")
+			}
+			switch rl.Key {
+			case "", "ip", "client":
+			default:
+				return fmt.Errorf("server.rate_limit.key must be ip or client, got %q", rl.Key)
+			}
+		}
+		if g := cfg.Server.GRPC; g != nil {
+			if strings.TrimSpace(g.Listen) == "" {
+				return fmt.Errorf("server.grpc.listen is required")
+			}
+			if g.Listen == cfg.Server.Listen {
+				return fmt.Errorf("server.grpc.listen must differ from server.listen")
+			}
+		}
+		if a := cfg.Server.Audit; a != nil && a.Enabled {
+			switch a.Sink {
+			case "", "file":
+				if strings.TrimSpace(a.Path) == "" {
+					return fmt.Errorf("server.audit.path is required for the file sink")
+				}
+			case "store":
+				if cfg.Server.Auth == nil || !cfg.Server.Auth.Enabled {
+					return fmt.Errorf("server.audit.sink store needs server.auth enabled")
+				}
+			default:
+				return fmt.Errorf("server.audit.sink must be file or store, got %q", a.Sink)
 			}
 		}
 		if auth := cfg.Server.Auth; auth != nil && auth.Enabled {
@@ -553,12 +1102,50 @@ func validateConfig(cfg *Config) error {
 				if strings.TrimSpace(auth.Store.Database) == "" {
 					return fmt.Errorf("server.auth.store.database is required for postgresql")
 				}
+			case "memory":
 			default:
-				return fmt.Errorf("server.auth.store.type must be sqlite or postgresql")
+				return fmt.Errorf("server.auth.store.type must be one of %s", strings.Join(authstore.SupportedTypes(), ", "))
 			}
 			if auth.Store.MaxOpenConns > 0 && auth.Store.MaxIdleConns > auth.Store.MaxOpenConns {
 				return fmt.Errorf("server.auth.store.max_idle_conns must not exceed max_open_conns")
 			}
+			if oidc := auth.OIDC; oidc != nil && oidc.Enabled {
+				if err := oidc.Validate(); err != nil {
+					return fmt.Errorf("server.auth.oidc: %w", err)
+				}
+			}
+			if ldap := auth.LDAP; ldap != nil && ldap.Enabled {
+				if err := ldap.Validate(); err != nil {
+					return fmt.Errorf("server.auth.ldap: %w", err)
+				}
+			}
+		}
+	}
+	if cfg.Log != nil {
+		if err := cfg.Log.Syslog.Validate(); err != nil {
+			return fmt.Errorf("log.syslog: %w", err)
+		}
+	}
+	if cfg.Metrics != nil {
+		for name := range cfg.Metrics.Labels {
+			if !validLabelName(name) {
+				return fmt.Errorf("metrics.labels: invalid label name %q", name)
+			}
+		}
+		if push := cfg.Metrics.Push; push != nil {
+			if u, err := url.Parse(push.URL); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("metrics.push: url must be an absolute URL, got %q", push.URL)
+			}
+			if push.Interval < 0 {
+				return fmt.Errorf("metrics.push: interval must not be negative")
+			}
+		}
+		if path := cfg.Metrics.Path; path != "" && !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("metrics.path must start with /, got %q", path)
+		}
+		if a := cfg.Metrics.Auth; a != nil && a.Enabled && a.BearerToken == "" &&
+			(cfg.Server == nil || cfg.Server.Auth == nil || !cfg.Server.Auth.Enabled) {
+			return fmt.Errorf("metrics.auth needs a bearer_token or an enabled server.auth")
 		}
 	}
 	if cfg.Metrics != nil && cfg.Metrics.ProcessMetrics != nil {
@@ -606,10 +1193,27 @@ func validateConfig(cfg *Config) error {
 			return fmt.Errorf("history.stores.opensearch retention durations must not be negative")
 		}
 	}
-	if len(enabled) == 0 {
-		return fmt.Errorf("history.enabled requires at least one enabled store")
+	publisherEnabled := false
+	if hook := cfg.History.Webhook; hook != nil && hook.Enabled {
+		publisherEnabled = true
+		if err := validateWebhookConfig(hook); err != nil {
+			return err
+		}
+	}
+	if nats := cfg.History.NATS; nats != nil && nats.Enabled {
+		publisherEnabled = true
+		if nats.QueueSize < 0 {
+			return fmt.Errorf("history.nats.queue_size must not be negative")
+		}
+	}
+	if len(enabled) == 0 && !publisherEnabled {
+		return fmt.Errorf("history.enabled requires at least one enabled store, webhook or nats publisher")
 	}
 	if strings.TrimSpace(cfg.History.Primary) == "" {
+		// A publisher-only setup (webhook, NATS) has nothing to read history from.
+		if len(enabled) == 0 {
+			return nil
+		}
 		return fmt.Errorf("history.primary is required")
 	}
 	if !enabled[cfg.History.Primary] {
@@ -618,6 +1222,24 @@ func validateConfig(cfg *Config) error {
 	return nil
 }
 
+func validateWebhookConfig(hook *WebhookHistoryConfig) error {
+	u, err := url.Parse(strings.TrimSpace(hook.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("history.webhook.url must be an http(s) URL")
+	}
+	for _, name := range hook.Events {
+		switch name {
+		case "start", "stop", "restart", "fatal":
+		default:
+			return fmt.Errorf("history.webhook.events: unknown event type %q", name)
+		}
+	}
+	if hook.Timeout < 0 || hook.RetryInterval < 0 || hook.MaxRetries < 0 || hook.QueueSize < 0 {
+		return fmt.Errorf("history.webhook timeout, max_retries, retry_interval and queue_size must not be negative")
+	}
+	return nil
+}
+
 func computeGlobalEnv(useOSEnv bool, envFiles []string, env []string) ([]string, error) {
 	envMap := make(map[string]string)
 
@@ -699,6 +1321,27 @@ func applyGlobalLogDefaults(cfg *LoadedConfig) error {
 		if sp.Log.File.MaxAgeDays == 0 && cfg.Log.File.MaxAgeDays > 0 {
 			sp.Log.File.MaxAgeDays = cfg.Log.File.MaxAgeDays
 		}
+		if sp.Log.File.OutputFormat == "" {
+			sp.Log.File.OutputFormat = cfg.Log.File.OutputFormat
+		}
+		if sp.Log.File.DirMode == "" {
+			sp.Log.File.DirMode = cfg.Log.File.DirMode
+		}
+		if sp.Log.File.DirOwner == "" {
+			sp.Log.File.DirOwner = cfg.Log.File.DirOwner
+		}
+		if sp.Log.File.MaxLineBytes == 0 && cfg.Log.File.MaxLineBytes > 0 {
+			sp.Log.File.MaxLineBytes = cfg.Log.File.MaxLineBytes
+		}
+		if sp.Log.File.FlushInterval == 0 && cfg.Log.File.FlushInterval > 0 {
+			sp.Log.File.FlushInterval = cfg.Log.File.FlushInterval
+		}
+		if noPathsSet && !sp.Log.File.CombineStreams {
+			sp.Log.File.CombineStreams = cfg.Log.File.CombineStreams
+		}
+		if !sp.Log.Syslog.Enabled {
+			sp.Log.Syslog = cfg.Log.Syslog
+		}
 		// Compress default copies boolean as-is only when any path configured
 		if noPathsSet {
 			// If we just set paths above, respect global Compress
@@ -825,3 +1468,20 @@ func convertDetectorConfigs(spec *core.Spec) error {
 
 	return nil
 }
+
+// validLabelName reports whether name is a Prometheus label name that is
+// not reserved for internal use ([a-zA-Z_][a-zA-Z0-9_]*, no "__" prefix).
+func validLabelName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "__") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
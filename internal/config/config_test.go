@@ -106,6 +106,738 @@ base_path = "/api"
 	}
 }
 
+func TestLoadConfigServerGRPC(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write(`
+[server]
+listen = ":8080"
+[server.grpc]
+listen = ":9090"
+`)
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if config.Server.GRPC == nil || config.Server.GRPC.Listen != ":9090" {
+		t.Fatalf("unexpected grpc config: %+v", config.Server.GRPC)
+	}
+
+	write(`
+[server]
+listen = ":8080"
+[server.grpc]
+listen = ":8080"
+`)
+	if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "server.grpc.listen") {
+		t.Fatalf("expected shared listen address to be rejected, got %v", err)
+	}
+}
+
+func TestLoadConfigServerCORS(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write(`
+[server]
+listen = ":8080"
+[server.cors]
+enabled = true
+allowed_origins = ["https://ui.example.com"]
+allow_credentials = true
+max_age = "10m"
+`)
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cors := config.Server.CORS
+	if cors == nil || !cors.Enabled || len(cors.AllowedOrigins) != 1 || !cors.AllowCredentials || cors.MaxAge != 10*time.Minute {
+		t.Fatalf("unexpected cors config: %+v", cors)
+	}
+
+	write(`
+[server]
+listen = ":8080"
+[server.cors]
+enabled = true
+allowed_origins = ["*"]
+allow_credentials = true
+`)
+	if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "server.cors") {
+		t.Fatalf("expected wildcard origin with credentials to be rejected, got %v", err)
+	}
+}
+
+func TestLoadConfigServerTLSClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write(`
+[server]
+listen = ":8443"
+[server.tls]
+enabled = true
+dir = "tls"
+client_auth = "require_and_verify"
+client_ca_file = "clients-ca.crt"
+`)
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if tls := config.Server.TLS; tls.ClientAuth != "require_and_verify" || tls.ClientCAFile != filepath.Join(dir, "clients-ca.crt") {
+		t.Fatalf("unexpected tls config: %+v", tls)
+	}
+
+	for _, body := range []string{
+		"client_auth = \"require_and_verify\"",
+		"client_auth = \"always\"",
+	} {
+		write("[server]\nlisten = \":8443\"\n[server.tls]\nenabled = true\ndir = \"tls\"\n" + body + "\n")
+		if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "server.tls.client_") {
+			t.Fatalf("%s: expected a client auth error, got %v", body, err)
+		}
+	}
+}
+
+func TestLoadConfigServerTLSProtocol(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	load := func(tlsBody string) (*LoadedConfig, error) {
+		t.Helper()
+		data := "[server]\nlisten = \":8443\"\n[server.tls]\nenabled = true\ndir = \"tls\"\n" + tlsBody + "\n"
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+		return LoadConfig(file)
+	}
+
+	config, err := load(`min_version = "1.2"
+cipher_suites = ["TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"]`)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if ids, err := config.Server.TLS.CipherSuiteIDs(); err != nil || len(ids) != 1 {
+		t.Fatalf("cipher suites = %v, %v", ids, err)
+	}
+
+	for body, want := range map[string]string{
+		`min_version = "1.0"`: "server.tls",
+		`min_version = "1.3"` + "\n" + `max_version = "TLS1.2"`:                                    "max_version",
+		`cipher_suites = ["TLS_RSA_WITH_RC4_128_SHA"]`:                                             "insecure",
+		"[server.tls.auto_gen]\nkey_type = \"dsa\"":                                                "key_type",
+		"[server.tls.auto_gen]\nip_addresses = [\"10.0.0.300\"]":                                   "ip_addresses",
+		`cipher_suites = ["TLS_NOT_A_SUITE"]`:                                                      "unknown",
+		`min_version = "1.3"` + "\n" + `cipher_suites = ["TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"]`: "TLS 1.2",
+	} {
+		if _, err := load(body); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want an error mentioning %q", body, err, want)
+		}
+	}
+}
+
+func TestLoadConfigServerTLSACME(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write(`
+[server]
+listen = ":443"
+[server.tls]
+enabled = true
+[server.tls.acme]
+enabled = true
+domains = ["provisr.example.com"]
+email = "ops@example.com"
+cache_dir = "acme"
+http_listen = ":8080"
+`)
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	acme := config.Server.TLS.ACME
+	if acme == nil || !acme.Enabled || acme.CacheDir != filepath.Join(dir, "acme") || acme.HTTPListen != ":8080" || len(acme.Domains) != 1 {
+		t.Fatalf("unexpected acme config: %+v", acme)
+	}
+
+	write(`
+[server]
+listen = ":443"
+[server.tls]
+enabled = true
+[server.tls.acme]
+enabled = true
+cache_dir = "acme"
+`)
+	if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "server.tls.acme.domains") {
+		t.Fatalf("expected missing domains to be rejected, got %v", err)
+	}
+}
+
+func TestLoadConfigServerRateLimit(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write(`
+[server]
+listen = ":8080"
+[server.rate_limit]
+enabled = true
+requests_per_second = 2.5
+burst = 5
+key = "client"
+`)
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if rl := config.Server.RateLimit; rl == nil || rl.RequestsPerSecond != 2.5 || rl.Burst != 5 || rl.Key != "client" {
+		t.Fatalf("unexpected rate limit config: %+v", rl)
+	}
+
+	for _, body := range []string{"requests_per_second = 0", "requests_per_second = 1\nkey = \"token\""} {
+		write("[server]\nlisten = \":8080\"\n[server.rate_limit]\nenabled = true\n" + body + "\n")
+		if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "server.rate_limit") {
+			t.Fatalf("%q: expected validation error, got %v", body, err)
+		}
+	}
+}
+
+func TestLoadConfigMetricsLabels(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write("[metrics]\nenabled = true\nlabels = { host = \"node-a\", env = \"prod\" }\n")
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if l := config.Metrics.Labels; l["host"] != "node-a" || l["env"] != "prod" {
+		t.Fatalf("unexpected metrics labels: %v", l)
+	}
+
+	for _, name := range []string{"__name", "1host", "\"data-center\""} {
+		write("[metrics]\nenabled = true\nlabels = { " + name + " = \"x\" }\n")
+		if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "metrics.labels") {
+			t.Fatalf("%s: expected validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestLoadConfigMetricsPush(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write("[metrics]\nenabled = true\n[metrics.push]\nurl = \"http://pushgateway:9091\"\njob = \"batch\"\ninterval = \"30s\"\n")
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if p := config.Metrics.Push; p == nil || p.URL != "http://pushgateway:9091" || p.Job != "batch" || p.Interval != 30*time.Second {
+		t.Fatalf("unexpected push config: %+v", p)
+	}
+
+	for _, body := range []string{"url = \"pushgateway:9091\"", "url = \"http://pushgateway:9091\"\ninterval = \"-1s\""} {
+		write("[metrics]\nenabled = true\n[metrics.push]\n" + body + "\n")
+		if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "metrics.push") {
+			t.Fatalf("%q: expected validation error, got %v", body, err)
+		}
+	}
+}
+
+func TestLoadConfigMetricsAuth(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write("[metrics]\nenabled = true\npath = \"/internal/metrics\"\n[metrics.auth]\nenabled = true\nbearer_token = \"s3cret\"\n")
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if m := config.Metrics; m.Path != "/internal/metrics" || m.Auth == nil || !m.Auth.Enabled || m.Auth.BearerToken != "s3cret" {
+		t.Fatalf("unexpected metrics config: %+v", m)
+	}
+
+	for _, body := range []string{"path = \"metrics\"", "[metrics.auth]\nenabled = true"} {
+		write("[metrics]\nenabled = true\n" + body + "\n")
+		if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "metrics.") {
+			t.Fatalf("%q: expected validation error, got %v", body, err)
+		}
+	}
+}
+
+func TestLoadConfigServerAudit(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write("[server]\nlisten = \":8080\"\n[server.audit]\nenabled = true\npath = \"logs/audit.log\"\nhash_chain = true\n")
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if a := config.Server.Audit; a == nil || !a.HashChain || !filepath.IsAbs(a.Path) || filepath.Base(a.Path) != "audit.log" {
+		t.Fatalf("unexpected audit config: %+v", a)
+	}
+
+	for _, body := range []string{"enabled = true", "enabled = true\nsink = \"store\"", "enabled = true\nsink = \"syslog\""} {
+		write("[server]\nlisten = \":8080\"\n[server.audit]\n" + body + "\n")
+		if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "server.audit") {
+			t.Fatalf("%q: expected validation error, got %v", body, err)
+		}
+	}
+}
+
+func TestLoadConfigServerAuthProviders(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+	const prefix = "[server]\nlisten = \":8080\"\n[server.auth]\nenabled = true\n[server.auth.store]\ntype = \"sqlite\"\npath = \"auth.db\"\n"
+
+	write(prefix + `
+[server.auth.oidc]
+enabled = true
+issuer_url = "https://idp.example.com/realms/ops"
+client_id = "provisr"
+default_roles = ["viewer"]
+[server.auth.oidc.role_mapping]
+ops = ["operator"]
+
+[server.auth.ldap]
+enabled = true
+url = "ldaps://ldap.example.com"
+bind_dn = "uid={username},ou=people,dc=example,dc=com"
+roles = ["viewer"]
+`)
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if o := config.Server.Auth.OIDC; o == nil || o.ClientID != "provisr" || o.RoleMapping["ops"][0] != "operator" {
+		t.Fatalf("unexpected oidc config: %+v", o)
+	}
+	if l := config.Server.Auth.LDAP; l == nil || l.URL != "ldaps://ldap.example.com" || len(l.Roles) != 1 {
+		t.Fatalf("unexpected ldap config: %+v", l)
+	}
+
+	for _, body := range []string{
+		"[server.auth.oidc]\nenabled = true\nclient_id = \"provisr\"",
+		"[server.auth.oidc]\nenabled = true\nissuer_url = \"https://idp\"",
+		"[server.auth.oidc]\nenabled = true\nissuer_url = \"https://idp\"\nclient_id = \"p\"\n[server.auth.oidc.role_mapping]\nops = [\"root\"]",
+		"[server.auth.ldap]\nenabled = true\nurl = \"http://ldap\"\nbind_dn = \"uid={username}\"",
+		"[server.auth.ldap]\nenabled = true\nurl = \"ldap://ldap\"\nbind_dn = \"uid=fixed\"",
+	} {
+		write(prefix + body + "\n")
+		if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "server.auth.") {
+			t.Fatalf("%q: expected validation error, got %v", body, err)
+		}
+	}
+}
+
+func TestLoadConfigInstanceNaming(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write(`
+[instance_naming]
+format = "{{.Base}}_{{.Index}}"
+start = 0
+padding = 2
+`)
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	naming, err := config.InstanceNaming.Scheme()
+	if err != nil {
+		t.Fatalf("scheme: %v", err)
+	}
+	if got := naming.Names("web", 2); len(got) != 2 || got[0] != "web_00" || got[1] != "web_01" {
+		t.Fatalf("unexpected instance names: %v", got)
+	}
+
+	write("[instance_naming]\nformat = \"{{.Base}}\"\n")
+	if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "instance_naming") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestLoadConfigDirModes(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("write toml: %v", err)
+		}
+	}
+
+	write(`
+pid_dir = "./run"
+pid_dir_mode = "0700"
+
+[log]
+dir = "./logs"
+dir_mode = "0755"
+
+[[processes]]
+type = "process"
+[processes.spec]
+name = "web"
+command = "sleep 1"
+`)
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if config.PIDDirMode != "0700" {
+		t.Fatalf("pid_dir_mode = %q", config.PIDDirMode)
+	}
+	if len(config.Specs) != 1 || config.Specs[0].Log.File.DirMode != "0755" {
+		t.Fatalf("log dir_mode not applied to the process: %+v", config.Specs)
+	}
+
+	for _, body := range []string{"pid_dir_mode = \"0999\"\n", "[log]\ndir_mode = \"rw\"\n", "pid_dir_owner = \"provisr-no-such-user\"\n"} {
+		write(body)
+		if _, err := LoadConfig(file); err == nil {
+			t.Fatalf("%q: expected validation error", body)
+		}
+	}
+}
+
+func TestLoadConfigMergesProgramsDirectory(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	programs := filepath.Join(dir, "services")
+	if err := os.Mkdir(programs, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	program := func(name string) string {
+		return "type = \"process\"\n[spec]\nname = \"" + name + "\"\ncommand = \"sleep 1\"\n"
+	}
+
+	write(file, `
+programs_directory = "services"
+
+[[processes]]
+type = "process"
+[processes.spec]
+name = "web"
+command = "sleep 1"
+`)
+	write(filepath.Join(programs, "worker.toml"), program("worker"))
+	write(filepath.Join(programs, "cache.json"), `{"type":"process","spec":{"name":"cache","command":"sleep 1"}}`)
+	write(filepath.Join(programs, "notes.txt"), "ignored")
+
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	got := map[string]bool{}
+	for _, spec := range config.Specs {
+		got[spec.Name] = spec.InlineConfig
+	}
+	if len(got) != 3 || !got["web"] || got["worker"] || got["cache"] {
+		t.Fatalf("expected inline web plus worker and cache from files, got %v", got)
+	}
+	if config.ResolvedProgramsDirectory != programs {
+		t.Fatalf("programs directory = %q", config.ResolvedProgramsDirectory)
+	}
+
+	// A program file reusing an inline name names both places.
+	clash := filepath.Join(programs, "web.toml")
+	write(clash, program("web"))
+	_, err = LoadConfig(file)
+	if err == nil || !strings.Contains(err.Error(), clash) || !strings.Contains(err.Error(), file) {
+		t.Fatalf("expected a conflict naming both files, got %v", err)
+	}
+	_ = os.Remove(clash)
+
+	// So does a name shared by two program files.
+	write(filepath.Join(programs, "worker2.toml"), program("worker"))
+	_, err = LoadConfig(file)
+	if err == nil || !strings.Contains(err.Error(), "worker.toml") || !strings.Contains(err.Error(), "worker2.toml") {
+		t.Fatalf("expected a conflict naming both program files, got %v", err)
+	}
+}
+
+func TestLoadConfigIncludes(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	extra := filepath.Join(dir, "extra")
+	if err := os.Mkdir(extra, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	write(file, `
+include = ["extra/*.toml"]
+pid_dir = "./run"
+
+[[processes]]
+type = "process"
+[processes.spec]
+name = "web"
+command = "sleep 1"
+`)
+	write(filepath.Join(extra, "a.toml"), `
+pid_dir = "./ignored"
+restart_jitter = "1s"
+
+[[processes]]
+type = "process"
+[processes.spec]
+name = "worker"
+command = "sleep 1"
+work_dir = "data"
+`)
+	write(filepath.Join(extra, "b.toml"), `
+restart_jitter = "2s"
+`)
+
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if config.PIDDir != filepath.Join(dir, "run") {
+		t.Fatalf("main file should win: pid_dir = %q", config.PIDDir)
+	}
+	if config.RestartJitter != 2*time.Second {
+		t.Fatalf("later include should win: restart_jitter = %v", config.RestartJitter)
+	}
+	byName := map[string]core.Spec{}
+	for _, spec := range config.Specs {
+		byName[spec.Name] = spec
+	}
+	if len(byName) != 2 || !byName["worker"].InlineConfig {
+		t.Fatalf("expected web and the included worker, got %+v", byName)
+	}
+	if got := byName["worker"].WorkDir; got != filepath.Join(extra, "data") {
+		t.Fatalf("included work_dir should resolve against its file: %q", got)
+	}
+
+	// The same name in the main file and an include names both.
+	dup := filepath.Join(extra, "c.toml")
+	write(dup, "[[processes]]\ntype = \"process\"\n[processes.spec]\nname = \"web\"\ncommand = \"sleep 1\"\n")
+	_, err = LoadConfig(file)
+	if err == nil || !strings.Contains(err.Error(), dup) || !strings.Contains(err.Error(), file) {
+		t.Fatalf("expected a conflict naming both files, got %v", err)
+	}
+	_ = os.Remove(dup)
+
+	for name, body := range map[string]string{
+		"missing": `include = ["extra/missing.toml"]`,
+		"nested":  `include = ["nested.toml"]`,
+	} {
+		write(filepath.Join(dir, "nested.toml"), `include = ["extra/a.toml"]`)
+		write(file, body)
+		if _, err := LoadConfig(file); err == nil {
+			t.Fatalf("%s include: expected an error", name)
+		}
+	}
+	// A glob matching nothing is fine.
+	write(file, `include = ["none/*.toml"]`)
+	if _, err := LoadConfig(file); err != nil {
+		t.Fatalf("empty glob: %v", err)
+	}
+}
+
+func TestLoadConfigEnvSubstitution(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	t.Setenv("PROVISR_TEST_LISTEN", "127.0.0.1:9999")
+	t.Setenv("PROVISR_TEST_SECRET", "s3cret")
+	if err := os.WriteFile(file, []byte(`
+env = ["CHAIN=${GLOBAL_NAME}-x"]
+
+[server]
+listen = "${PROVISR_TEST_LISTEN}"
+base_path = "${PROVISR_TEST_UNSET:-/api}"
+
+[server.auth]
+enabled = false
+jwt_secret = "prefix-${PROVISR_TEST_SECRET}-$${LITERAL}"
+
+[[processes]]
+type = "process"
+[processes.spec]
+name = "web"
+command = "sh -c 'echo ${HOSTNAME_AT_RUNTIME}'"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if config.Server.Listen != "127.0.0.1:9999" || config.Server.BasePath != "/api" {
+		t.Fatalf("server not substituted: %+v", config.Server)
+	}
+	if got := config.Server.Auth.JWTSecret; got != "prefix-s3cret-${LITERAL}" {
+		t.Fatalf("jwt_secret = %q", got)
+	}
+	// Process specs and env keep their references for start time.
+	if config.Env[0] != "CHAIN=${GLOBAL_NAME}-x" || !strings.Contains(config.Specs[0].Command, "${HOSTNAME_AT_RUNTIME}") {
+		t.Fatalf("runtime references were expanded: %v %q", config.Env, config.Specs[0].Command)
+	}
+
+	if err := os.WriteFile(file, []byte("[server]\nlisten = \"${PROVISR_TEST_UNSET}\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "PROVISR_TEST_UNSET is not set") {
+		t.Fatalf("expected an unset variable error, got %v", err)
+	}
+}
+
+func TestLoadConfigResolvesPathsAgainstConfigDir(t *testing.T) {
+	// The working directory is compared by path, so resolve symlinked temp dirs.
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	etc := filepath.Join(root, "etc")
+	if err := os.MkdirAll(filepath.Join(etc, "programs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(etc, "config.toml"), []byte(`
+pid_dir = "run"
+
+[log]
+dir = "logs"
+
+[[processes]]
+type = "process"
+[processes.spec]
+name = "web"
+command = "sleep 1"
+work_dir = "srv"
+[processes.spec.log]
+stdout = "out/web.log"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(etc, "programs", "worker.toml"), []byte(`
+type = "process"
+[spec]
+name = "worker"
+command = "sleep 1"
+pid_file = "worker.pid"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Load through a relative path from an unrelated working directory.
+	cwd := filepath.Join(root, "elsewhere")
+	if err := os.Mkdir(cwd, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(cwd)
+	config, err := LoadConfig(filepath.Join("..", "etc", "config.toml"))
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	byName := map[string]core.Spec{}
+	for _, spec := range config.Specs {
+		byName[spec.Name] = spec
+	}
+	want := map[string]string{
+		"pid_dir":            filepath.Join(etc, "run"),
+		"programs_directory": filepath.Join(etc, "programs"),
+		"web work_dir":       filepath.Join(etc, "srv"),
+		"web stdout":         filepath.Join(etc, "out", "web.log"),
+		"web pid_file":       filepath.Join(etc, "run", "web.pid"),
+		"worker pid_file":    filepath.Join(etc, "programs", "worker.pid"),
+		"worker log dir":     filepath.Join(etc, "logs"),
+	}
+	got := map[string]string{
+		"pid_dir":            config.PIDDir,
+		"programs_directory": config.ResolvedProgramsDirectory,
+		"web work_dir":       byName["web"].WorkDir,
+		"web stdout":         byName["web"].Log.File.StdoutPath,
+		"web pid_file":       byName["web"].PIDFile,
+		"worker pid_file":    byName["worker"].PIDFile,
+		"worker log dir":     byName["worker"].Log.File.Dir,
+	}
+	for key, path := range want {
+		if got[key] != path {
+			t.Errorf("%s = %q, want %q", key, got[key], path)
+		}
+	}
+}
+
 func TestLoadConfigPreservesSQLiteMemoryDSN(t *testing.T) {
 	file := filepath.Join(t.TempDir(), "config.toml")
 	data := `
@@ -174,6 +906,40 @@ cleanup_interval = "1h"
 	}
 }
 
+func TestLoadConfigHistoryWebhook(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	data := `
+[history]
+enabled = true
+
+[history.webhook]
+enabled = true
+url = "https://hooks.example.com/provisr"
+secret = "s3cret"
+events = ["restart", "fatal"]
+retry_interval = "2s"
+`
+	if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	hook := cfg.History.Webhook
+	if hook == nil || hook.URL != "https://hooks.example.com/provisr" || len(hook.Events) != 2 || hook.RetryInterval != 2*time.Second {
+		t.Fatalf("unexpected webhook config: %+v", hook)
+	}
+
+	bad := strings.Replace(data, `"fatal"`, `"crash"`, 1)
+	if err := os.WriteFile(file, []byte(bad), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := LoadConfig(file); err == nil || !strings.Contains(err.Error(), "crash") {
+		t.Fatalf("expected unknown webhook event to be rejected, got %v", err)
+	}
+}
+
 func TestLoadConfigRejectsFlatHistoryConfig(t *testing.T) {
 	file := filepath.Join(t.TempDir(), "config.toml")
 	data := `
@@ -195,6 +961,7 @@ func TestRepositorySampleConfigs(t *testing.T) {
 		filepath.Join("..", "..", "config", "config.toml"),
 		filepath.Join("..", "..", "config", "process_metrics_demo.toml"),
 		filepath.Join("..", "..", "examples", "embedded_client", "daemon-config.toml"),
+		filepath.Join("..", "..", "examples", "history_nats", "config.toml"),
 	}
 	for _, path := range paths {
 		t.Run(filepath.Base(path), func(t *testing.T) {
@@ -593,7 +1360,7 @@ func TestLoadEnvFile(t *testing.T) {
 
 func TestLoadProgramEntries_Coverage(t *testing.T) {
 	// Test with non-existent directory
-	specs, jobs, err := loadProgramEntries("/nonexistent/directory")
+	specs, jobs, err := loadProgramEntries("/nonexistent/directory", nil)
 	if err != nil {
 		t.Errorf("expected no error for non-existent directory, got: %v", err)
 	}
@@ -603,7 +1370,7 @@ func TestLoadProgramEntries_Coverage(t *testing.T) {
 
 	// Test with empty directory
 	tmpDir := t.TempDir()
-	specs, jobs, err = loadProgramEntries(tmpDir)
+	specs, jobs, err = loadProgramEntries(tmpDir, nil)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+// DefaultCipherSuites are the TLS 1.2 cipher suites used when
+// cipher_suites is unset: ECDHE key exchange with AEAD ciphers only.
+// TLS 1.3 suites are not configurable and always secure.
+var DefaultCipherSuites = []string{
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+}
+
+// Key types of auto-generated certificates.
+const (
+	KeyTypeRSA2048   = "rsa2048"
+	KeyTypeRSA4096   = "rsa4096"
+	KeyTypeECDSAP256 = "ecdsa-p256"
+)
+
+// Validate checks the key type and IP addresses of a.
+func (a AutoGenTLS) Validate() error {
+	switch a.KeyType {
+	case "", KeyTypeRSA2048, KeyTypeRSA4096, KeyTypeECDSAP256:
+	default:
+		return fmt.Errorf("invalid key_type %q: must be %s, %s or %s", a.KeyType, KeyTypeRSA2048, KeyTypeRSA4096, KeyTypeECDSAP256)
+	}
+	for _, ip := range a.IPAddresses {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid ip_addresses entry %q", ip)
+		}
+	}
+	return nil
+}
+
+// ParseTLSVersion parses a min_version/max_version value: "1.2" or "1.3",
+// optionally prefixed with "TLS" or "tls". "" and "default" return 0.
+func ParseTLSVersion(s string) (uint16, error) {
+	switch strings.TrimPrefix(strings.TrimPrefix(s, "TLS"), "tls") {
+	case "", "default":
+		return 0, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid TLS version %q: must be 1.2 or 1.3", s)
+	}
+}
+
+// Versions returns the protocol versions t allows: TLS 1.2 through 1.3
+// unless min_version or max_version narrow the range.
+func (t TLSConfig) Versions() (minVer, maxVer uint16, err error) {
+	if minVer, err = ParseTLSVersion(t.MinVersion); err != nil {
+		return 0, 0, err
+	}
+	if maxVer, err = ParseTLSVersion(t.MaxVersion); err != nil {
+		return 0, 0, err
+	}
+	if minVer == 0 {
+		minVer = tls.VersionTLS12
+	}
+	if maxVer == 0 {
+		maxVer = tls.VersionTLS13
+	}
+	if maxVer < minVer {
+		return 0, 0, fmt.Errorf("max_version must not be lower than min_version")
+	}
+	return minVer, maxVer, nil
+}
+
+// CipherSuiteIDs returns the IDs of t's TLS 1.2 cipher suites, or of
+// DefaultCipherSuites when none are set. Unknown names, suites crypto/tls
+// considers insecure and suites set while TLS 1.2 is disabled are errors.
+func (t TLSConfig) CipherSuiteIDs() ([]uint16, error) {
+	names := t.CipherSuites
+	if len(names) == 0 {
+		names = DefaultCipherSuites
+	} else if minVer, _, err := t.Versions(); err == nil && minVer == tls.VersionTLS13 {
+		return nil, fmt.Errorf("cipher_suites only apply to TLS 1.2, which min_version 1.3 disables")
+	}
+	known := make(map[string]uint16)
+	for _, cs := range tls.CipherSuites() {
+		// TLS 1.3 suites can't be chosen; crypto/tls always enables them.
+		if slices.Contains(cs.SupportedVersions, tls.VersionTLS12) {
+			known[cs.Name] = cs.ID
+		}
+	}
+	insecure := make(map[string]bool)
+	for _, cs := range tls.InsecureCipherSuites() {
+		insecure[cs.Name] = true
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		switch {
+		case insecure[name]:
+			return nil, fmt.Errorf("cipher suite %s is insecure", name)
+		case !ok:
+			return nil, fmt.Errorf("unknown TLS 1.2 cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -75,13 +75,19 @@ func NewWithOptions(dsn, table string, options Options) (*Sink, error) {
 			occurred_at DateTime64(9, 'UTC'),
 			record_name String,
 			record_pid Int32,
-			record_status String
+			record_status String,
+			record_exit_code Nullable(Int32),
+			record_error String
 		) ENGINE = MergeTree() ORDER BY occurred_at`, table)); err != nil {
 			return err
 		}
-		_, err := db.ExecContext(ctx, fmt.Sprintf(
-			`ALTER TABLE %s ADD COLUMN IF NOT EXISTS record_status String`, table))
-		return err
+		for _, column := range []string{"record_status String", "record_exit_code Nullable(Int32)", "record_error String"} {
+			if _, err := db.ExecContext(ctx, fmt.Sprintf(
+				`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s`, table, column)); err != nil {
+				return err
+			}
+		}
+		return nil
 	}); err != nil {
 		adapter.Close()
 		return nil, fmt.Errorf("clickhouse: ping: %w", err)
@@ -99,11 +105,11 @@ func (s *Sink) Close() error {
 // Send writes a lifecycle event to ClickHouse.
 func (s *Sink) Send(ctx context.Context, e corehistory.Event) error {
 	query := fmt.Sprintf(
-		`INSERT INTO %s (type, occurred_at, record_name, record_pid, record_status) VALUES (?, ?, ?, ?, ?)`,
+		`INSERT INTO %s (type, occurred_at, record_name, record_pid, record_status, record_exit_code, record_error) VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		s.table,
 	)
 	return s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
-		_, err := db.ExecContext(ctx, query, string(e.Type), e.OccurredAt, e.Record.Name, e.Record.PID, e.Record.LastStatus)
+		_, err := db.ExecContext(ctx, query, string(e.Type), e.OccurredAt, e.Record.Name, e.Record.PID, e.Record.LastStatus, e.Record.ExitCode, e.Record.Error)
 		return err
 	})
 }
@@ -122,11 +128,11 @@ func (s *Sink) List(ctx context.Context, name string, limit, offset int) ([]core
 	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
 		if name == "" {
 			return db.SelectContext(ctx, &rows,
-				fmt.Sprintf(`SELECT occurred_at AS timestamp, record_pid AS pid, record_name AS name, record_status AS status, NULL AS error FROM %s ORDER BY occurred_at DESC LIMIT ? OFFSET ?`, s.table),
+				fmt.Sprintf(`SELECT occurred_at AS timestamp, record_pid AS pid, record_name AS name, record_status AS status, nullIf(record_error, '') AS error, type AS event, record_exit_code AS exit_code FROM %s ORDER BY occurred_at DESC LIMIT ? OFFSET ?`, s.table),
 				limit, offset)
 		}
 		return db.SelectContext(ctx, &rows,
-			fmt.Sprintf(`SELECT occurred_at AS timestamp, record_pid AS pid, record_name AS name, record_status AS status, NULL AS error FROM %s WHERE positionCaseInsensitive(record_name, ?) > 0 ORDER BY occurred_at DESC LIMIT ? OFFSET ?`, s.table),
+			fmt.Sprintf(`SELECT occurred_at AS timestamp, record_pid AS pid, record_name AS name, record_status AS status, nullIf(record_error, '') AS error, type AS event, record_exit_code AS exit_code FROM %s WHERE positionCaseInsensitive(record_name, ?) > 0 ORDER BY occurred_at DESC LIMIT ? OFFSET ?`, s.table),
 			name, limit, offset)
 	})
 	return rows, err
@@ -5,8 +5,10 @@ import corehistory "github.com/loykin/provisr/core/history"
 type EventType = corehistory.EventType
 
 const (
-	EventStart = corehistory.EventStart
-	EventStop  = corehistory.EventStop
+	EventStart   = corehistory.EventStart
+	EventStop    = corehistory.EventStop
+	EventRestart = corehistory.EventRestart
+	EventFatal   = corehistory.EventFatal
 )
 
 type Record = corehistory.Record
@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corehistory "github.com/loykin/provisr/core/history"
+)
+
+// Sink keeps history events, job output and process metadata in process
+// memory. Nothing survives Close, so it suits tests and embedding provisr
+// without a database. It is safe for concurrent use and follows the
+// semantics of the SQL sinks.
+type Sink struct {
+	mu       sync.RWMutex
+	entries  []corehistory.Entry
+	outputs  map[outputKey]corehistory.JobOutput
+	metadata map[string]map[string]string
+	closed   bool
+}
+
+type outputKey struct {
+	job     string
+	attempt int32
+}
+
+// New creates an empty in-memory history sink.
+func New() *Sink {
+	return &Sink{
+		outputs:  make(map[outputKey]corehistory.JobOutput),
+		metadata: make(map[string]map[string]string),
+	}
+}
+
+func (s *Sink) checkOpen() error {
+	if s.closed {
+		return errors.New("memory history sink is closed")
+	}
+	return nil
+}
+
+func (s *Sink) Send(ctx context.Context, e corehistory.Event) error {
+	rec := e.Record
+	entry := corehistory.Entry{
+		Timestamp: e.OccurredAt.UTC(),
+		PID:       rec.PID,
+		Name:      rec.Name,
+		Status:    rec.LastStatus,
+		Event:     e.Type,
+	}
+	if rec.Error != "" {
+		msg := rec.Error
+		entry.Error = &msg
+	}
+	if rec.ExitCode != nil {
+		code := *rec.ExitCode
+		entry.ExitCode = &code
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// matching returns the entries whose name contains name, ignoring case,
+// newest first. Entries with the same timestamp keep the newest send first.
+func (s *Sink) matching(name string) []corehistory.Entry {
+	name = strings.ToLower(strings.TrimSpace(name))
+	var rows []corehistory.Entry
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if name == "" || strings.Contains(strings.ToLower(s.entries[i].Name), name) {
+			rows = append(rows, s.entries[i])
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Timestamp.After(rows[j].Timestamp) })
+	return rows
+}
+
+// List returns history rows newest-first, paginated by limit/offset. If name
+// is empty, rows for all processes are returned. Otherwise name is treated as
+// a case-insensitive contains filter. limit is capped at 500 (defaults to
+// 100); offset must be >= 0.
+func (s *Sink) List(ctx context.Context, name string, limit, offset int) ([]corehistory.Entry, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+	rows := s.matching(name)
+	if offset >= len(rows) {
+		return nil, nil
+	}
+	rows = rows[offset:]
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+// Count returns the total number of history rows, optionally filtered by
+// name contains search, so callers can compute page counts for List.
+func (s *Sink) Count(ctx context.Context, name string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkOpen(); err != nil {
+		return 0, err
+	}
+	return len(s.matching(name)), nil
+}
+
+// PruneBefore deletes history entries, and captured job output, older than
+// cutoff.
+func (s *Sink) PruneBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return 0, err
+	}
+	var deleted int64
+	kept := s.entries[:0]
+	for _, entry := range s.entries {
+		if entry.Timestamp.Before(cutoff) {
+			deleted++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	s.entries = kept
+	for key, out := range s.outputs {
+		if out.CapturedAt.Before(cutoff) {
+			delete(s.outputs, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// SaveJobOutput stores the output of a job attempt, replacing any output
+// already stored for it.
+func (s *Sink) SaveJobOutput(ctx context.Context, out corehistory.JobOutput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	out.CapturedAt = out.CapturedAt.UTC()
+	s.outputs[outputKey{job: out.Job, attempt: out.Attempt}] = out
+	return nil
+}
+
+// JobOutput returns the stored output of a job attempt.
+func (s *Sink) JobOutput(ctx context.Context, job string, attempt int32) (corehistory.JobOutput, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkOpen(); err != nil {
+		return corehistory.JobOutput{}, false, err
+	}
+	out, ok := s.outputs[outputKey{job: job, attempt: attempt}]
+	return out, ok, nil
+}
+
+// DeleteJobOutput removes the output of every attempt of job.
+func (s *Sink) DeleteJobOutput(ctx context.Context, job string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	for key := range s.outputs {
+		if key.job == job {
+			delete(s.outputs, key)
+		}
+	}
+	return nil
+}
+
+// SetProcessMetadata sets the given metadata keys of process.
+func (s *Sink) SetProcessMetadata(ctx context.Context, process string, metadata map[string]string) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	stored := s.metadata[process]
+	if stored == nil {
+		stored = make(map[string]string, len(metadata))
+		s.metadata[process] = stored
+	}
+	for key, value := range metadata {
+		stored[key] = value
+	}
+	return nil
+}
+
+// ProcessMetadata returns every metadata key set for process.
+func (s *Sink) ProcessMetadata(ctx context.Context, process string) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+	stored := s.metadata[process]
+	metadata := make(map[string]string, len(stored))
+	for key, value := range stored {
+		metadata[key] = value
+	}
+	return metadata, nil
+}
+
+// FindProcessesByMetadata returns the processes that have every key of
+// selector set to its value, sorted by name. An empty selector matches
+// every process with metadata.
+func (s *Sink) FindProcessesByMetadata(ctx context.Context, selector map[string]string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for process, stored := range s.metadata {
+		matches := true
+		for key, value := range selector {
+			if got, ok := stored[key]; !ok || got != value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			names = append(names, process)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// DeleteProcessMetadata removes the given keys of process, or all of them.
+func (s *Sink) DeleteProcessMetadata(ctx context.Context, process string, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		delete(s.metadata, process)
+		return nil
+	}
+	stored := s.metadata[process]
+	for _, key := range keys {
+		delete(stored, key)
+	}
+	if len(stored) == 0 {
+		delete(s.metadata, process)
+	}
+	return nil
+}
+
+// Ping fails once the sink is closed.
+func (s *Sink) Ping(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.checkOpen()
+}
+
+// Close drops everything stored; later calls fail.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries, s.outputs, s.metadata, s.closed = nil, nil, nil, true
+	return nil
+}
+
+var _ corehistory.Reader = (*Sink)(nil)
+var _ corehistory.Pruner = (*Sink)(nil)
+var _ corehistory.Pinger = (*Sink)(nil)
+var _ corehistory.JobOutputStore = (*Sink)(nil)
+var _ corehistory.ProcessMetadataStore = (*Sink)(nil)
@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corehistory "github.com/loykin/provisr/core/history"
+)
+
+func TestSinkSendAndList(t *testing.T) {
+	sink := New()
+	t.Cleanup(func() { _ = sink.Close() })
+
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	exitCode := 1
+	events := []corehistory.Event{
+		{Type: corehistory.EventStart, OccurredAt: base, Record: corehistory.Record{Name: "svc-a", PID: 100, LastStatus: "running"}},
+		{Type: corehistory.EventStart, OccurredAt: base.Add(2 * time.Minute), Record: corehistory.Record{Name: "svc-b", PID: 200, LastStatus: "running"}},
+		// Sent out of order: List still sorts by timestamp.
+		{Type: corehistory.EventStop, OccurredAt: base.Add(time.Minute), Record: corehistory.Record{Name: "SVC-A", PID: 100, LastStatus: "failed", ExitCode: &exitCode, Error: "exit status 1"}},
+	}
+	for _, e := range events {
+		if err := sink.Send(ctx, e); err != nil {
+			t.Fatalf("Send() error: %v", err)
+		}
+	}
+
+	all, err := sink.List(ctx, "", 0, 0)
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(all) != 3 || all[0].Name != "svc-b" {
+		t.Fatalf("expected 3 rows with svc-b newest, got %+v", all)
+	}
+
+	filtered, err := sink.List(ctx, " svc-a ", 0, 0)
+	if err != nil {
+		t.Fatalf("List(svc-a) error: %v", err)
+	}
+	if len(filtered) != 2 {
+		t.Fatalf("expected 2 rows for svc-a, got %d", len(filtered))
+	}
+	stop, start := filtered[0], filtered[1]
+	if stop.Event != corehistory.EventStop || stop.ExitCode == nil || *stop.ExitCode != 1 || stop.Error == nil || *stop.Error != "exit status 1" {
+		t.Errorf("stop row lost its event details: %+v", stop)
+	}
+	if start.Event != corehistory.EventStart || start.ExitCode != nil || start.Error != nil {
+		t.Errorf("unexpected start row details: %+v", start)
+	}
+
+	page, err := sink.List(ctx, "", 1, 1)
+	if err != nil || len(page) != 1 || page[0].Status != "failed" {
+		t.Fatalf("List(limit=1, offset=1) = %+v, %v", page, err)
+	}
+	if page, err := sink.List(ctx, "", 10, 5); err != nil || len(page) != 0 {
+		t.Fatalf("List past the end = %+v, %v", page, err)
+	}
+	if total, err := sink.Count(ctx, "svc-a"); err != nil || total != 2 {
+		t.Fatalf("Count(svc-a) = %d, %v; want 2, nil", total, err)
+	}
+}
+
+func TestSinkPruneBefore(t *testing.T) {
+	sink := New()
+	t.Cleanup(func() { _ = sink.Close() })
+
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, occurredAt := range []time.Time{base, base.Add(24 * time.Hour)} {
+		if err := sink.Send(ctx, corehistory.Event{OccurredAt: occurredAt, Record: corehistory.Record{Name: "svc"}}); err != nil {
+			t.Fatalf("Send() error: %v", err)
+		}
+	}
+	for attempt, capturedAt := range []time.Time{base, base.Add(24 * time.Hour)} {
+		out := corehistory.JobOutput{Job: "migrate", Attempt: int32(attempt + 1), Output: "done\n", CapturedAt: capturedAt}
+		if err := sink.SaveJobOutput(ctx, out); err != nil {
+			t.Fatalf("SaveJobOutput() error: %v", err)
+		}
+	}
+
+	deleted, err := sink.PruneBefore(ctx, base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("PruneBefore() error: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("deleted = %d, want 2 (one history entry, one job output)", deleted)
+	}
+	if total, err := sink.Count(ctx, ""); err != nil || total != 1 {
+		t.Fatalf("Count() = %d, %v; want 1, nil", total, err)
+	}
+	if _, ok, err := sink.JobOutput(ctx, "migrate", 1); ok || err != nil {
+		t.Fatalf("expired job output still stored: ok=%v err=%v", ok, err)
+	}
+	if _, ok, err := sink.JobOutput(ctx, "migrate", 2); !ok || err != nil {
+		t.Fatalf("recent job output pruned: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestSinkJobOutput(t *testing.T) {
+	sink := New()
+	t.Cleanup(func() { _ = sink.Close() })
+	ctx := context.Background()
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, out := range []corehistory.JobOutput{
+		{Job: "migrate", Instance: "migrate-1", Attempt: 1, Output: "first try\n", CapturedAt: at},
+		{Job: "migrate", Instance: "migrate-1", Attempt: 1, Output: "replaced\n", Truncated: true, CapturedAt: at},
+		{Job: "migrate", Instance: "migrate-2", Attempt: 2, Output: "second\n", CapturedAt: at},
+		{Job: "report", Instance: "report-1", Attempt: 1, Output: "report\n", CapturedAt: at},
+	} {
+		if err := sink.SaveJobOutput(ctx, out); err != nil {
+			t.Fatalf("SaveJobOutput() error: %v", err)
+		}
+	}
+
+	got, ok, err := sink.JobOutput(ctx, "migrate", 1)
+	if err != nil || !ok {
+		t.Fatalf("JobOutput() = %v, %v", ok, err)
+	}
+	if got.Output != "replaced\n" || !got.Truncated || got.Instance != "migrate-1" || !got.CapturedAt.Equal(at) {
+		t.Fatalf("unexpected output: %+v", got)
+	}
+	if _, ok, err := sink.JobOutput(ctx, "migrate", 3); ok || err != nil {
+		t.Fatalf("missing attempt: ok=%v err=%v", ok, err)
+	}
+
+	if err := sink.DeleteJobOutput(ctx, "migrate"); err != nil {
+		t.Fatalf("DeleteJobOutput() error: %v", err)
+	}
+	if _, ok, _ := sink.JobOutput(ctx, "migrate", 2); ok {
+		t.Fatal("output of a deleted job should be gone")
+	}
+	if _, ok, _ := sink.JobOutput(ctx, "report", 1); !ok {
+		t.Fatal("output of other jobs should be kept")
+	}
+}
+
+func TestSinkProcessMetadata(t *testing.T) {
+	sink := New()
+	t.Cleanup(func() { _ = sink.Close() })
+	ctx := context.Background()
+
+	for name, metadata := range map[string]map[string]string{
+		"billing-1": {"team": "payments", "env": "prod"},
+		"billing-2": {"team": "payments", "env": "staging"},
+		"search":    {"team": "search", "env": "prod"},
+	} {
+		if err := sink.SetProcessMetadata(ctx, name, metadata); err != nil {
+			t.Fatalf("SetProcessMetadata(%s) error: %v", name, err)
+		}
+	}
+	if err := sink.SetProcessMetadata(ctx, "search", map[string]string{"env": "staging", "ticket": "OPS-7"}); err != nil {
+		t.Fatalf("SetProcessMetadata() error: %v", err)
+	}
+	got, err := sink.ProcessMetadata(ctx, "search")
+	if err != nil {
+		t.Fatalf("ProcessMetadata() error: %v", err)
+	}
+	if want := map[string]string{"team": "search", "env": "staging", "ticket": "OPS-7"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ProcessMetadata() = %v, want %v", got, want)
+	}
+	// The returned map is a copy.
+	got["team"] = "changed"
+	if again, _ := sink.ProcessMetadata(ctx, "search"); again["team"] != "search" {
+		t.Fatalf("ProcessMetadata() shares the stored map: %v", again)
+	}
+
+	cases := []struct {
+		selector map[string]string
+		want     []string
+	}{
+		{map[string]string{"team": "payments"}, []string{"billing-1", "billing-2"}},
+		{map[string]string{"team": "payments", "env": "prod"}, []string{"billing-1"}},
+		{map[string]string{"env": "staging"}, []string{"billing-2", "search"}},
+		{map[string]string{"team": "nobody"}, []string{}},
+		{nil, []string{"billing-1", "billing-2", "search"}},
+	}
+	for _, tc := range cases {
+		names, err := sink.FindProcessesByMetadata(ctx, tc.selector)
+		if err != nil {
+			t.Fatalf("FindProcessesByMetadata(%v) error: %v", tc.selector, err)
+		}
+		if !reflect.DeepEqual(names, tc.want) {
+			t.Errorf("FindProcessesByMetadata(%v) = %v, want %v", tc.selector, names, tc.want)
+		}
+	}
+
+	if err := sink.DeleteProcessMetadata(ctx, "search", "ticket"); err != nil {
+		t.Fatalf("DeleteProcessMetadata(key) error: %v", err)
+	}
+	if got, _ := sink.ProcessMetadata(ctx, "search"); len(got) != 2 {
+		t.Fatalf("after deleting one key: %v", got)
+	}
+	if err := sink.DeleteProcessMetadata(ctx, "search"); err != nil {
+		t.Fatalf("DeleteProcessMetadata() error: %v", err)
+	}
+	if got, _ := sink.ProcessMetadata(ctx, "search"); len(got) != 0 {
+		t.Fatalf("after deleting every key: %v", got)
+	}
+}
+
+func TestSinkPing(t *testing.T) {
+	sink := New()
+	if err := sink.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error: %v", err)
+	}
+	_ = sink.Close()
+	if err := sink.Ping(context.Background()); err == nil {
+		t.Fatal("Ping() succeeded after Close")
+	}
+	if err := sink.Send(context.Background(), corehistory.Event{}); err == nil {
+		t.Fatal("Send() succeeded after Close")
+	}
+}
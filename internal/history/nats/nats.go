@@ -0,0 +1,176 @@
+//go:build nats
+
+// Package nats publishes history events to a NATS subject. It is compiled
+// only with the "nats" build tag so default builds don't link the client.
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+
+	corehistory "github.com/loykin/provisr/core/history"
+)
+
+const (
+	// DefaultSubject is used when Options.Subject is empty.
+	DefaultSubject   = "provisr.history.{type}"
+	defaultQueueSize = 1024
+	closeTimeout     = 5 * time.Second
+)
+
+// ErrQueueFull is returned by Send when the publish queue is full and the
+// event was dropped.
+var ErrQueueFull = errors.New("nats: publish queue full, event dropped")
+
+// Options configures a NATS Sink.
+type Options struct {
+	URL string
+	// Subject may contain {type} and {name}, replaced with the event type
+	// and process name so subscribers can filter with NATS wildcards.
+	Subject   string
+	QueueSize int
+	// OnDrop is called for every event dropped because the queue was full.
+	OnDrop func(corehistory.Event)
+	// Conn overrides the connection; Close does not close a supplied Conn.
+	Conn *natsgo.Conn
+}
+
+// Sink publishes each event as its JSON encoding. Publishing happens on a
+// background goroutine fed by a bounded queue, so a slow or unreachable
+// server never blocks the caller; overflow is dropped and counted.
+type Sink struct {
+	conn      *natsgo.Conn
+	ownsConn  bool
+	subject   string
+	onDrop    func(corehistory.Event)
+	dropped   atomic.Uint64
+	published atomic.Uint64
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan corehistory.Event
+	done   chan struct{}
+}
+
+// New connects to opts.URL and starts the publisher. An unreachable server
+// is not an error: the client keeps reconnecting in the background.
+func New(opts Options) (*Sink, error) {
+	if opts.Subject == "" {
+		opts.Subject = DefaultSubject
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	conn, owns := opts.Conn, false
+	if conn == nil {
+		url := opts.URL
+		if url == "" {
+			url = natsgo.DefaultURL
+		}
+		var err error
+		conn, err = natsgo.Connect(url,
+			natsgo.Name("provisr"),
+			natsgo.MaxReconnects(-1),
+			natsgo.RetryOnFailedConnect(true),
+		)
+		if err != nil {
+			return nil, err
+		}
+		owns = true
+	}
+	s := &Sink{
+		conn:     conn,
+		ownsConn: owns,
+		subject:  opts.Subject,
+		onDrop:   opts.OnDrop,
+		queue:    make(chan corehistory.Event, opts.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Send enqueues e for publishing without blocking.
+func (s *Sink) Send(_ context.Context, e corehistory.Event) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return errors.New("nats: sink closed")
+	}
+	select {
+	case s.queue <- e:
+		return nil
+	default:
+		s.dropped.Add(1)
+		if s.onDrop != nil {
+			s.onDrop(e)
+		}
+		return ErrQueueFull
+	}
+}
+
+// Dropped returns how many events were discarded because the queue was full.
+func (s *Sink) Dropped() uint64 { return s.dropped.Load() }
+
+// Published returns how many events were handed to the NATS client.
+func (s *Sink) Published() uint64 { return s.published.Load() }
+
+// Close stops accepting events, publishes what is queued and flushes the
+// connection, giving up after a short grace period.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-time.After(closeTimeout):
+	}
+	err := s.conn.FlushTimeout(closeTimeout)
+	if s.ownsConn {
+		s.conn.Close()
+	}
+	if errors.Is(err, natsgo.ErrConnectionClosed) {
+		err = nil
+	}
+	return err
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+	for e := range s.queue {
+		data, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		if err := s.conn.Publish(Subject(s.subject, e), data); err == nil {
+			s.published.Add(1)
+		}
+	}
+}
+
+// subjectToken makes a process name safe to use as a single subject token.
+var subjectToken = strings.NewReplacer(".", "_", " ", "_", "*", "_", ">", "_")
+
+// Subject expands the {type} and {name} placeholders in pattern for e.
+func Subject(pattern string, e corehistory.Event) string {
+	return strings.NewReplacer(
+		"{type}", string(e.Type),
+		"{name}", subjectToken.Replace(e.Record.Name),
+	).Replace(pattern)
+}
+
+// compile-time check that Sink satisfies corehistory.Sink
+var _ corehistory.Sink = (*Sink)(nil)
@@ -0,0 +1,132 @@
+//go:build nats
+
+package nats
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	corehistory "github.com/loykin/provisr/core/history"
+)
+
+type published struct {
+	subject string
+	payload []byte
+}
+
+// fakeServer speaks just enough of the NATS client protocol to accept a
+// connection and report PUB messages.
+func fakeServer(t *testing.T) (string, <-chan published) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("tcp listener unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	msgs := make(chan published, 16)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = io.WriteString(conn, `INFO {"server_id":"fake","version":"2.10.0","max_payload":1048576}`+"\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			switch fields[0] {
+			case "PING":
+				_, _ = io.WriteString(conn, "PONG\r\n")
+			case "PUB":
+				size, _ := strconv.Atoi(fields[len(fields)-1])
+				buf := make([]byte, size+2)
+				if _, err := io.ReadFull(r, buf); err != nil {
+					return
+				}
+				msgs <- published{subject: fields[1], payload: buf[:size]}
+			}
+		}
+	}()
+	return "nats://" + ln.Addr().String(), msgs
+}
+
+func TestSink_PublishesEventsToSubject(t *testing.T) {
+	url, msgs := fakeServer(t)
+	sink, err := New(Options{URL: url, Subject: "ops.{type}.{name}"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	evt := corehistory.Event{
+		Type:       corehistory.EventRestart,
+		OccurredAt: time.Now().UTC(),
+		Record:     corehistory.Record{Name: "api.v2", PID: 7, LastStatus: "running"},
+	}
+	if err := sink.Send(context.Background(), evt); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	select {
+	case msg := <-msgs:
+		if msg.subject != "ops.restart.api_v2" {
+			t.Fatalf("subject = %q", msg.subject)
+		}
+		var got corehistory.Event
+		if err := json.Unmarshal(msg.payload, &got); err != nil {
+			t.Fatalf("payload %q: %v", msg.payload, err)
+		}
+		if got.Type != evt.Type || got.Record.Name != "api.v2" || got.Record.PID != 7 {
+			t.Fatalf("unexpected event: %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message published")
+	}
+	if sink.Published() != 1 || sink.Dropped() != 0 {
+		t.Fatalf("published=%d dropped=%d", sink.Published(), sink.Dropped())
+	}
+}
+
+func TestSink_DropsWhenQueueFull(t *testing.T) {
+	var dropped int
+	// No publisher goroutine, so the queue stays full after one event.
+	sink := &Sink{
+		queue:  make(chan corehistory.Event, 1),
+		onDrop: func(corehistory.Event) { dropped++ },
+	}
+	if err := sink.Send(context.Background(), corehistory.Event{Type: corehistory.EventStart}); err != nil {
+		t.Fatalf("first Send: %v", err)
+	}
+	if err := sink.Send(context.Background(), corehistory.Event{Type: corehistory.EventStop}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if dropped != 1 || sink.Dropped() != 1 {
+		t.Fatalf("drop not counted: callback=%d counter=%d", dropped, sink.Dropped())
+	}
+}
+
+func TestSubject(t *testing.T) {
+	e := corehistory.Event{Type: corehistory.EventFatal, Record: corehistory.Record{Name: "web *1"}}
+	if got := Subject(DefaultSubject, e); got != "provisr.history.fatal" {
+		t.Fatalf("default subject = %q", got)
+	}
+	if got := Subject("x.{name}", e); got != "x.web__1" {
+		t.Fatalf("name subject = %q", got)
+	}
+}
@@ -58,7 +58,7 @@ func NewWithOptions(baseURL, index string, options Options) (*Sink, error) {
 			if err == nil && resp.StatusCode == 200 {
 				return nil
 			}
-			body := strings.NewReader(`{"mappings":{"properties":{"occurred_at":{"type":"date"},"record":{"properties":{"name":{"type":"keyword"},"pid":{"type":"integer"},"last_status":{"type":"keyword"}}}}}}`)
+			body := strings.NewReader(`{"mappings":{"properties":{"occurred_at":{"type":"date"},"record":{"properties":{"name":{"type":"keyword"},"pid":{"type":"integer"},"last_status":{"type":"keyword"},"exit_code":{"type":"integer"}}},"type":{"type":"keyword"}}}}`)
 			_, err = c.Indices.Create(ctx, opensearchapi.IndicesCreateReq{Index: index, Body: body})
 			return err
 		})
@@ -129,12 +129,18 @@ func (s *Sink) List(ctx context.Context, name string, limit, offset int) ([]core
 			if err := json.Unmarshal(hit.Source, &e); err != nil {
 				return err
 			}
-			entries = append(entries, corehistory.Entry{
+			entry := corehistory.Entry{
 				Timestamp: e.OccurredAt,
 				PID:       e.Record.PID,
 				Name:      e.Record.Name,
 				Status:    e.Record.LastStatus,
-			})
+				Event:     e.Type,
+				ExitCode:  e.Record.ExitCode,
+			}
+			if e.Record.Error != "" {
+				entry.Error = &e.Record.Error
+			}
+			entries = append(entries, entry)
 		}
 		return nil
 	})
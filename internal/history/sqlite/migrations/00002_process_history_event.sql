@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE process_history ADD COLUMN event TEXT NOT NULL DEFAULT '';
+ALTER TABLE process_history ADD COLUMN exit_code INTEGER;
+
+-- +goose Down
+ALTER TABLE process_history DROP COLUMN exit_code;
+ALTER TABLE process_history DROP COLUMN event;
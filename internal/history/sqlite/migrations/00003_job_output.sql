@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS job_output(
+    job TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    instance TEXT NOT NULL,
+    output TEXT NOT NULL,
+    truncated BOOLEAN NOT NULL DEFAULT 0,
+    captured_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (job, attempt)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS job_output;
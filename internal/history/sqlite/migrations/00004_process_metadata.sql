@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS process_metadata(
+    process TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (process, key)
+);
+CREATE INDEX IF NOT EXISTS idx_process_metadata_key_value ON process_metadata(key, value);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_process_metadata_key_value;
+DROP TABLE IF EXISTS process_metadata;
@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 	"embed"
 	"errors"
 	"fmt"
@@ -98,12 +99,20 @@ func (s *Sink) Send(ctx context.Context, e corehistory.Event) error {
 	rec := e.Record
 	return s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
 		_, err := db.ExecContext(ctx,
-			`INSERT INTO process_history(timestamp, pid, name, status, error) VALUES(?, ?, ?, ?, NULL)`,
-			e.OccurredAt.UTC(), rec.PID, rec.Name, rec.LastStatus)
+			`INSERT INTO process_history(timestamp, pid, name, status, error, event, exit_code) VALUES(?, ?, ?, ?, ?, ?, ?)`,
+			e.OccurredAt.UTC(), rec.PID, rec.Name, rec.LastStatus, nullString(rec.Error), string(e.Type), rec.ExitCode)
 		return err
 	})
 }
 
+// nullString stores an empty error as NULL.
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 func containsPattern(value string) string {
 	var b strings.Builder
 	b.Grow(len(value) + 2)
@@ -135,10 +144,10 @@ func (s *Sink) List(ctx context.Context, name string, limit, offset int) ([]core
 	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
 		if name == "" {
 			return db.SelectContext(ctx, &rows,
-				`SELECT timestamp, pid, name, status, error FROM process_history ORDER BY timestamp DESC LIMIT ? OFFSET ?`, limit, offset)
+				`SELECT timestamp, pid, name, status, error, event, exit_code FROM process_history ORDER BY timestamp DESC LIMIT ? OFFSET ?`, limit, offset)
 		}
 		return db.SelectContext(ctx, &rows,
-			`SELECT timestamp, pid, name, status, error
+			`SELECT timestamp, pid, name, status, error, event, exit_code
 			 FROM process_history
 			 WHERE name LIKE ? ESCAPE '\'
 			 ORDER BY timestamp DESC
@@ -162,17 +171,152 @@ func (s *Sink) Count(ctx context.Context, name string) (int, error) {
 	return total, err
 }
 
+// PruneBefore deletes history entries, and captured job output, older than
+// cutoff. Job output is normally deleted with its job; this also clears the
+// output of jobs the daemon forgot across a restart.
 func (s *Sink) PruneBefore(ctx context.Context, cutoff time.Time) (int64, error) {
 	var deleted int64
 	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
-		result, err := db.ExecContext(ctx, `DELETE FROM process_history WHERE timestamp < ?`, cutoff.UTC())
+		for _, query := range []string{
+			`DELETE FROM process_history WHERE timestamp < ?`,
+			`DELETE FROM job_output WHERE captured_at < ?`,
+		} {
+			result, err := db.ExecContext(ctx, query, cutoff.UTC())
+			if err != nil {
+				return err
+			}
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			deleted += rows
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// SaveJobOutput stores the output of a job attempt, replacing any output
+// already stored for it.
+func (s *Sink) SaveJobOutput(ctx context.Context, out corehistory.JobOutput) error {
+	return s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO job_output(job, attempt, instance, output, truncated, captured_at) VALUES(?, ?, ?, ?, ?, ?)
+			 ON CONFLICT(job, attempt) DO UPDATE SET instance = excluded.instance, output = excluded.output,
+			 truncated = excluded.truncated, captured_at = excluded.captured_at`,
+			out.Job, out.Attempt, out.Instance, out.Output, out.Truncated, out.CapturedAt.UTC())
+		return err
+	})
+}
+
+// JobOutput returns the stored output of a job attempt.
+func (s *Sink) JobOutput(ctx context.Context, job string, attempt int32) (corehistory.JobOutput, bool, error) {
+	var out corehistory.JobOutput
+	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		return db.GetContext(ctx, &out,
+			`SELECT job, attempt, instance, output, truncated, captured_at FROM job_output WHERE job = ? AND attempt = ?`, job, attempt)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return corehistory.JobOutput{}, false, nil
+	}
+	return out, err == nil, err
+}
+
+// DeleteJobOutput removes the output of every attempt of job.
+func (s *Sink) DeleteJobOutput(ctx context.Context, job string) error {
+	return s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		_, err := db.ExecContext(ctx, `DELETE FROM job_output WHERE job = ?`, job)
+		return err
+	})
+}
+
+// SetProcessMetadata sets the given metadata keys of process in one
+// transaction.
+func (s *Sink) SetProcessMetadata(ctx context.Context, process string, metadata map[string]string) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	return s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		tx, err := db.BeginTxx(ctx, nil)
 		if err != nil {
 			return err
 		}
-		deleted, err = result.RowsAffected()
-		return err
+		defer func() { _ = tx.Rollback() }()
+		for key, value := range metadata {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO process_metadata(process, key, value, updated_at) VALUES(?, ?, ?, ?)
+				 ON CONFLICT(process, key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+				process, key, value, now); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	})
+}
+
+// ProcessMetadata returns every metadata key set for process.
+func (s *Sink) ProcessMetadata(ctx context.Context, process string) (map[string]string, error) {
+	var rows []struct {
+		Key   string `db:"key"`
+		Value string `db:"value"`
+	}
+	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		return db.SelectContext(ctx, &rows, `SELECT key, value FROM process_metadata WHERE process = ?`, process)
+	})
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(map[string]string, len(rows))
+	for _, row := range rows {
+		metadata[row.Key] = row.Value
+	}
+	return metadata, nil
+}
+
+// FindProcessesByMetadata returns the processes that have every key of
+// selector set to its value, sorted by name. An empty selector matches
+// every process with metadata.
+func (s *Sink) FindProcessesByMetadata(ctx context.Context, selector map[string]string) ([]string, error) {
+	query := `SELECT DISTINCT process FROM process_metadata ORDER BY process`
+	var args []any
+	if len(selector) > 0 {
+		conditions := make([]string, 0, len(selector))
+		for key, value := range selector {
+			conditions = append(conditions, "(key = ? AND value = ?)")
+			args = append(args, key, value)
+		}
+		query = fmt.Sprintf(`SELECT process FROM process_metadata WHERE %s
+			GROUP BY process HAVING COUNT(*) = %d ORDER BY process`, strings.Join(conditions, " OR "), len(selector))
+	}
+	names := make([]string, 0)
+	err := s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		return db.SelectContext(ctx, &names, query, args...)
+	})
+	return names, err
+}
+
+// DeleteProcessMetadata removes the given keys of process, or all of them.
+func (s *Sink) DeleteProcessMetadata(ctx context.Context, process string, keys ...string) error {
+	return s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		if len(keys) == 0 {
+			_, err := db.ExecContext(ctx, `DELETE FROM process_metadata WHERE process = ?`, process)
+			return err
+		}
+		for _, key := range keys {
+			if _, err := db.ExecContext(ctx, `DELETE FROM process_metadata WHERE process = ? AND key = ?`, process, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Ping checks that the database answers.
+func (s *Sink) Ping(ctx context.Context) error {
+	return s.Run(ctx, func(ctx context.Context, db *sqlx.DB) error {
+		return db.PingContext(ctx)
 	})
-	return deleted, err
 }
 
 func (s *Sink) Close() error {
@@ -182,3 +326,6 @@ func (s *Sink) Close() error {
 
 var _ corehistory.Reader = (*Sink)(nil)
 var _ corehistory.Pruner = (*Sink)(nil)
+var _ corehistory.Pinger = (*Sink)(nil)
+var _ corehistory.JobOutputStore = (*Sink)(nil)
+var _ corehistory.ProcessMetadataStore = (*Sink)(nil)
@@ -3,6 +3,7 @@ package sqlite
 import (
 	"context"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -20,9 +21,10 @@ func TestSinkSendAndList(t *testing.T) {
 	ctx := context.Background()
 	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
 
+	exitCode := 1
 	events := []corehistory.Event{
 		{Type: corehistory.EventStart, OccurredAt: base, Record: corehistory.Record{Name: "svc-a", PID: 100, LastStatus: "running"}},
-		{Type: corehistory.EventStop, OccurredAt: base.Add(time.Minute), Record: corehistory.Record{Name: "svc-a", PID: 100, LastStatus: "stopped"}},
+		{Type: corehistory.EventStop, OccurredAt: base.Add(time.Minute), Record: corehistory.Record{Name: "svc-a", PID: 100, LastStatus: "failed", ExitCode: &exitCode, Error: "exit status 1"}},
 		{Type: corehistory.EventStart, OccurredAt: base.Add(2 * time.Minute), Record: corehistory.Record{Name: "svc-b", PID: 200, LastStatus: "running"}},
 	}
 	for _, e := range events {
@@ -50,9 +52,16 @@ func TestSinkSendAndList(t *testing.T) {
 	if len(filtered) != 2 {
 		t.Fatalf("expected 2 rows for svc-a, got %d", len(filtered))
 	}
-	if filtered[0].Status != "stopped" || filtered[1].Status != "running" {
+	if filtered[0].Status != "failed" || filtered[1].Status != "running" {
 		t.Errorf("unexpected order/status for svc-a rows: %+v", filtered)
 	}
+	stop, start := filtered[0], filtered[1]
+	if stop.Event != corehistory.EventStop || stop.ExitCode == nil || *stop.ExitCode != 1 || stop.Error == nil || *stop.Error != "exit status 1" {
+		t.Errorf("stop row lost its event details: %+v", stop)
+	}
+	if start.Event != corehistory.EventStart || start.ExitCode != nil || start.Error != nil {
+		t.Errorf("unexpected start row details: %+v", start)
+	}
 }
 
 func TestSinkListFiltersByNameContains(t *testing.T) {
@@ -138,15 +147,147 @@ func TestSinkPruneBefore(t *testing.T) {
 		}
 	}
 
+	for attempt, capturedAt := range []time.Time{base, base.Add(24 * time.Hour)} {
+		out := corehistory.JobOutput{Job: "migrate", Attempt: int32(attempt + 1), Output: "done\n", CapturedAt: capturedAt}
+		if err := sink.SaveJobOutput(ctx, out); err != nil {
+			t.Fatalf("SaveJobOutput() error: %v", err)
+		}
+	}
+
 	deleted, err := sink.PruneBefore(ctx, base.Add(time.Hour))
 	if err != nil {
 		t.Fatalf("PruneBefore() error: %v", err)
 	}
-	if deleted != 1 {
-		t.Fatalf("deleted = %d, want 1", deleted)
+	if deleted != 2 {
+		t.Fatalf("deleted = %d, want 2 (one history entry, one job output)", deleted)
 	}
 	total, err := sink.Count(ctx, "")
 	if err != nil || total != 1 {
 		t.Fatalf("Count() = %d, %v; want 1, nil", total, err)
 	}
+	if _, ok, err := sink.JobOutput(ctx, "migrate", 1); ok || err != nil {
+		t.Fatalf("expired job output still stored: ok=%v err=%v", ok, err)
+	}
+	if _, ok, err := sink.JobOutput(ctx, "migrate", 2); !ok || err != nil {
+		t.Fatalf("recent job output pruned: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestSinkJobOutput(t *testing.T) {
+	sink, err := New(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	t.Cleanup(func() { _ = sink.Close() })
+	ctx := context.Background()
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, out := range []corehistory.JobOutput{
+		{Job: "migrate", Instance: "migrate-1", Attempt: 1, Output: "first try\n", CapturedAt: at},
+		{Job: "migrate", Instance: "migrate-1", Attempt: 1, Output: "replaced\n", Truncated: true, CapturedAt: at},
+		{Job: "migrate", Instance: "migrate-2", Attempt: 2, Output: "second\n", CapturedAt: at},
+		{Job: "report", Instance: "report-1", Attempt: 1, Output: "report\n", CapturedAt: at},
+	} {
+		if err := sink.SaveJobOutput(ctx, out); err != nil {
+			t.Fatalf("SaveJobOutput() error: %v", err)
+		}
+	}
+
+	got, ok, err := sink.JobOutput(ctx, "migrate", 1)
+	if err != nil || !ok {
+		t.Fatalf("JobOutput() = %v, %v", ok, err)
+	}
+	if got.Output != "replaced\n" || !got.Truncated || got.Instance != "migrate-1" || !got.CapturedAt.Equal(at) {
+		t.Fatalf("unexpected output: %+v", got)
+	}
+	if _, ok, err := sink.JobOutput(ctx, "migrate", 3); ok || err != nil {
+		t.Fatalf("missing attempt: ok=%v err=%v", ok, err)
+	}
+
+	if err := sink.DeleteJobOutput(ctx, "migrate"); err != nil {
+		t.Fatalf("DeleteJobOutput() error: %v", err)
+	}
+	if _, ok, _ := sink.JobOutput(ctx, "migrate", 2); ok {
+		t.Fatal("output of a deleted job should be gone")
+	}
+	if _, ok, _ := sink.JobOutput(ctx, "report", 1); !ok {
+		t.Fatal("output of other jobs should be kept")
+	}
+}
+
+func TestSinkProcessMetadata(t *testing.T) {
+	sink, err := New(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	t.Cleanup(func() { _ = sink.Close() })
+	ctx := context.Background()
+
+	for name, metadata := range map[string]map[string]string{
+		"billing-1": {"team": "payments", "env": "prod"},
+		"billing-2": {"team": "payments", "env": "staging"},
+		"search":    {"team": "search", "env": "prod"},
+	} {
+		if err := sink.SetProcessMetadata(ctx, name, metadata); err != nil {
+			t.Fatalf("SetProcessMetadata(%s) error: %v", name, err)
+		}
+	}
+	// Setting a key again replaces its value and keeps the others.
+	if err := sink.SetProcessMetadata(ctx, "search", map[string]string{"env": "staging", "ticket": "OPS-7"}); err != nil {
+		t.Fatalf("SetProcessMetadata() error: %v", err)
+	}
+	got, err := sink.ProcessMetadata(ctx, "search")
+	if err != nil {
+		t.Fatalf("ProcessMetadata() error: %v", err)
+	}
+	if want := map[string]string{"team": "search", "env": "staging", "ticket": "OPS-7"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ProcessMetadata() = %v, want %v", got, want)
+	}
+
+	cases := []struct {
+		selector map[string]string
+		want     []string
+	}{
+		{map[string]string{"team": "payments"}, []string{"billing-1", "billing-2"}},
+		{map[string]string{"team": "payments", "env": "prod"}, []string{"billing-1"}},
+		{map[string]string{"env": "staging"}, []string{"billing-2", "search"}},
+		{map[string]string{"team": "nobody"}, []string{}},
+		{nil, []string{"billing-1", "billing-2", "search"}},
+	}
+	for _, tc := range cases {
+		names, err := sink.FindProcessesByMetadata(ctx, tc.selector)
+		if err != nil {
+			t.Fatalf("FindProcessesByMetadata(%v) error: %v", tc.selector, err)
+		}
+		if !reflect.DeepEqual(names, tc.want) {
+			t.Errorf("FindProcessesByMetadata(%v) = %v, want %v", tc.selector, names, tc.want)
+		}
+	}
+
+	if err := sink.DeleteProcessMetadata(ctx, "search", "ticket"); err != nil {
+		t.Fatalf("DeleteProcessMetadata(key) error: %v", err)
+	}
+	if got, _ := sink.ProcessMetadata(ctx, "search"); len(got) != 2 {
+		t.Fatalf("after deleting one key: %v", got)
+	}
+	if err := sink.DeleteProcessMetadata(ctx, "search"); err != nil {
+		t.Fatalf("DeleteProcessMetadata() error: %v", err)
+	}
+	if got, _ := sink.ProcessMetadata(ctx, "search"); len(got) != 0 {
+		t.Fatalf("after deleting every key: %v", got)
+	}
+}
+
+func TestSinkPing(t *testing.T) {
+	sink, err := New(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := sink.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error: %v", err)
+	}
+	_ = sink.Close()
+	if err := sink.Ping(context.Background()); err == nil {
+		t.Fatal("Ping() succeeded after Close")
+	}
 }
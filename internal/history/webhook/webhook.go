@@ -0,0 +1,297 @@
+// Package webhook delivers history events to an HTTP endpoint so lifecycle
+// changes can be wired into chat or paging tools without polling.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	corehistory "github.com/loykin/provisr/core/history"
+)
+
+const (
+	defaultTimeout       = 5 * time.Second
+	defaultMaxRetries    = 3
+	defaultRetryInterval = time.Second
+	defaultQueueSize     = 256
+	maxRetryInterval     = 30 * time.Second
+	closeTimeout         = 5 * time.Second
+
+	// SignatureHeader carries "sha256=<hex HMAC-SHA256 of the body>" when a
+	// secret is configured.
+	SignatureHeader = "X-Provisr-Signature"
+	// EventHeader carries the event type, e.g. "restart".
+	EventHeader = "X-Provisr-Event"
+)
+
+// ErrQueueFull is returned by Send when the delivery queue is full and the
+// event was dropped.
+var ErrQueueFull = errors.New("webhook: delivery queue full, event dropped")
+
+// EventTypes lists the event types a webhook can subscribe to.
+var EventTypes = []corehistory.EventType{
+	corehistory.EventStart,
+	corehistory.EventStop,
+	corehistory.EventRestart,
+	corehistory.EventFatal,
+}
+
+// Options configures a webhook Sink. Zero values select the defaults.
+type Options struct {
+	URL           string
+	Secret        string   // HMAC-SHA256 key used to sign request bodies; empty disables signing
+	Events        []string // event types to deliver; empty means all of EventTypes
+	Timeout       time.Duration
+	MaxRetries    int           // retries after the first failed attempt
+	RetryInterval time.Duration // delay before the first retry, doubled for each subsequent one
+	QueueSize     int
+	Client        *http.Client
+}
+
+// Payload is the JSON body POSTed for each event. Text is a one-line summary
+// so endpoints such as Slack incoming webhooks can display it as-is.
+type Payload struct {
+	Event      corehistory.EventType `json:"event"`
+	OccurredAt time.Time             `json:"occurred_at"`
+	Process    string                `json:"process"`
+	PID        int                   `json:"pid"`
+	Status     string                `json:"status"`
+	Text       string                `json:"text"`
+}
+
+// Sink queues events and POSTs them from a background goroutine, so a slow
+// or failing endpoint never blocks the caller. Events that arrive while the
+// queue is full are dropped.
+type Sink struct {
+	opts   Options
+	events map[corehistory.EventType]bool
+	client *http.Client
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan corehistory.Event
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Validate checks the URL and event names.
+func (o Options) Validate() error {
+	u, err := url.Parse(o.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook: invalid url %q", o.URL)
+	}
+	for _, name := range o.Events {
+		if !knownEvent(corehistory.EventType(name)) {
+			return fmt.Errorf("webhook: unknown event type %q", name)
+		}
+	}
+	if o.Timeout < 0 || o.RetryInterval < 0 || o.MaxRetries < 0 || o.QueueSize < 0 {
+		return errors.New("webhook: timeout, retries, retry interval and queue size must not be negative")
+	}
+	return nil
+}
+
+func knownEvent(t corehistory.EventType) bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// New validates opts and starts the delivery goroutine.
+func New(opts Options) (*Sink, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	if opts.RetryInterval == 0 {
+		opts.RetryInterval = defaultRetryInterval
+	}
+	if opts.QueueSize == 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	events := make(map[corehistory.EventType]bool, len(EventTypes))
+	if len(opts.Events) == 0 {
+		for _, t := range EventTypes {
+			events[t] = true
+		}
+	}
+	for _, name := range opts.Events {
+		events[corehistory.EventType(name)] = true
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Sink{
+		opts:   opts,
+		events: events,
+		client: client,
+		queue:  make(chan corehistory.Event, opts.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Send enqueues e for delivery. It never blocks: events not subscribed to
+// are ignored, and ErrQueueFull is returned when the queue has no room.
+func (s *Sink) Send(_ context.Context, e corehistory.Event) error {
+	if !s.events[e.Type] {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return errors.New("webhook: sink closed")
+	}
+	select {
+	case s.queue <- e:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting events and waits for queued ones to be delivered.
+// Deliveries still pending after a short grace period are abandoned.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-time.After(closeTimeout):
+		s.cancel()
+		<-s.done
+	}
+	s.cancel()
+	return nil
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+	for e := range s.queue {
+		_ = s.deliver(e)
+	}
+}
+
+// deliver POSTs e, retrying with exponential backoff on transport errors
+// and non-2xx responses.
+func (s *Sink) deliver(e corehistory.Event) error {
+	body, err := json.Marshal(newPayload(e))
+	if err != nil {
+		return err
+	}
+	delay := s.opts.RetryInterval
+	for attempt := 0; ; attempt++ {
+		err = s.post(e.Type, body)
+		if err == nil || attempt >= s.opts.MaxRetries {
+			return err
+		}
+		select {
+		case <-s.ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryInterval)
+	}
+}
+
+func (s *Sink) post(evt corehistory.EventType, body []byte) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(evt))
+	if s.opts.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(s.opts.Secret, body))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the SignatureHeader value for body signed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newPayload(e corehistory.Event) Payload {
+	text := fmt.Sprintf("provisr: process %q %s", e.Record.Name, describe(e))
+	if e.Record.PID > 0 {
+		text += fmt.Sprintf(" (pid %d)", e.Record.PID)
+	}
+	return Payload{
+		Event:      e.Type,
+		OccurredAt: e.OccurredAt,
+		Process:    e.Record.Name,
+		PID:        e.Record.PID,
+		Status:     e.Record.LastStatus,
+		Text:       text,
+	}
+}
+
+func describe(e corehistory.Event) string {
+	switch e.Type {
+	case corehistory.EventStart:
+		return "started"
+	case corehistory.EventStop:
+		if e.Record.LastStatus == "failed" {
+			return "exited unexpectedly"
+		}
+		return "stopped"
+	case corehistory.EventRestart:
+		return "restarted"
+	case corehistory.EventFatal:
+		return "failed to restart"
+	default:
+		return string(e.Type)
+	}
+}
+
+// compile-time check that Sink satisfies corehistory.Sink
+var _ corehistory.Sink = (*Sink)(nil)
@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corehistory "github.com/loykin/provisr/core/history"
+)
+
+func event(t corehistory.EventType, name string) corehistory.Event {
+	return corehistory.Event{
+		Type:       t,
+		OccurredAt: time.Now().UTC(),
+		Record:     corehistory.Record{Name: name, PID: 42, LastStatus: "running"},
+	}
+}
+
+func TestSink_DeliversSignedFilteredEvents(t *testing.T) {
+	received := make(chan Payload, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.Header.Get(SignatureHeader), Sign("s3cret", body); got != want {
+			t.Errorf("signature = %q, want %q", got, want)
+		}
+		var p Payload
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Errorf("invalid payload %q: %v", body, err)
+		}
+		if r.Header.Get(EventHeader) != string(p.Event) {
+			t.Errorf("event header %q does not match payload %q", r.Header.Get(EventHeader), p.Event)
+		}
+		received <- p
+	}))
+	defer srv.Close()
+
+	sink, err := New(Options{URL: srv.URL, Secret: "s3cret", Events: []string{"restart", "fatal"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_ = sink.Send(context.Background(), event(corehistory.EventStart, "web"))
+	_ = sink.Send(context.Background(), event(corehistory.EventRestart, "web"))
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	close(received)
+
+	var got []Payload
+	for p := range received {
+		got = append(got, p)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected only the restart event, got %+v", got)
+	}
+	if got[0].Event != corehistory.EventRestart || got[0].Process != "web" || got[0].PID != 42 {
+		t.Fatalf("unexpected payload: %+v", got[0])
+	}
+	if got[0].Text != `provisr: process "web" restarted (pid 42)` {
+		t.Fatalf("unexpected text: %q", got[0].Text)
+	}
+}
+
+func TestSink_RetriesFailedDelivery(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	sink, err := New(Options{URL: srv.URL, RetryInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_ = sink.Send(context.Background(), event(corehistory.EventFatal, "db"))
+	_ = sink.Close()
+
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected 2 failures and a successful retry, got %d calls", n)
+	}
+}
+
+func TestSink_SlowEndpointDoesNotBlockSend(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	sink, err := New(Options{URL: srv.URL, QueueSize: 1, Timeout: 100 * time.Millisecond, MaxRetries: 1, RetryInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+
+	start := time.Now()
+	var dropped bool
+	for i := 0; i < 10; i++ {
+		if err := sink.Send(context.Background(), event(corehistory.EventStop, "slow")); errors.Is(err, ErrQueueFull) {
+			dropped = true
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("Send blocked for %v on a slow endpoint", elapsed)
+	}
+	if !dropped {
+		t.Fatal("expected events to be dropped once the queue filled up")
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	cases := []struct {
+		opts    Options
+		wantErr bool
+	}{
+		{Options{URL: "https://hooks.example.com/x"}, false},
+		{Options{URL: "http://localhost:9000", Events: []string{"start", "stop", "restart", "fatal"}}, false},
+		{Options{URL: ""}, true},
+		{Options{URL: "ftp://example.com"}, true},
+		{Options{URL: "http://example.com", Events: []string{"crash"}}, true},
+		{Options{URL: "http://example.com", MaxRetries: -1}, true},
+	}
+	for i, tc := range cases {
+		if err := tc.opts.Validate(); (err != nil) != tc.wantErr {
+			t.Fatalf("case %d: Validate() = %v, wantErr %v", i, err, tc.wantErr)
+		}
+	}
+}
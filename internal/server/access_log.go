@@ -0,0 +1,76 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/loykin/provisr/internal/auth"
+	"github.com/loykin/provisr/internal/config"
+)
+
+const defaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds caller-supplied request IDs so a client can't
+// inflate every log line.
+const maxRequestIDLen = 128
+
+// requestIDKey is the gin context key holding the request ID.
+const requestIDKey = "request_id"
+
+// accessLogMiddleware assigns each request an ID, echoes it in the
+// response, and logs one line per request once it completes. A nil logger
+// uses slog.Default(), which the daemon configures from [log].
+func accessLogMiddleware(cfg config.AccessLogConfig, logger *slog.Logger) gin.HandlerFunc {
+	header := cfg.RequestIDHeader
+	if header == "" {
+		header = defaultRequestIDHeader
+	}
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := c.GetHeader(header)
+		if id == "" || len(id) > maxRequestIDLen {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(header, id)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("request_id", id),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if v, ok := c.Get(string(auth.ResultKey)); ok {
+			if result, ok := v.(*auth.AuthResult); ok && result.Success {
+				attrs = append(attrs, slog.String("client_id", result.Username))
+			}
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		l := logger
+		if l == nil {
+			l = slog.Default()
+		}
+		l.LogAttrs(c.Request.Context(), level, "http request", attrs...)
+	}
+}
+
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/internal/config"
+)
+
+func TestAccessLogRecordsRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authCfg := &config.AuthConfig{
+		Enabled: true,
+		Store:   config.AuthStoreConfig{Type: "sqlite", Path: filepath.Join(t.TempDir(), "auth.db")},
+	}
+	r, err := newRouterFromConfig(core.New(), "/api", authCfg, "", nil, nil)
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	t.Cleanup(func() { _ = r.authService.Close() })
+	if _, err := r.authService.CreateUser(context.Background(), "ops", "password123", "", []string{"viewer"}, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	var buf bytes.Buffer
+	r.SetAccessLog(&config.AccessLogConfig{Enabled: true}, slog.New(slog.NewJSONHandler(&buf, nil)))
+	h := r.Handler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/status?name=missing", nil)
+	req.SetBasicAuth("ops", "password123")
+	req.Header.Set("X-Request-ID", "req-42")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get("X-Request-ID"); got != "req-42" {
+		t.Fatalf("response request id = %q", got)
+	}
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("decode log line %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"msg":        "http request",
+		"request_id": "req-42",
+		"method":     "GET",
+		"path":       "/api/status",
+		"route":      "/api/status",
+		"status":     float64(http.StatusBadRequest),
+		"client_id":  "ops",
+	}
+	for k, v := range want {
+		if line[k] != v {
+			t.Errorf("log %s = %v, want %v", k, line[k], v)
+		}
+	}
+	if _, ok := line["duration"]; !ok {
+		t.Error("log line has no duration")
+	}
+
+	// Without an incoming ID one is generated and echoed.
+	buf.Reset()
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/auth/status", nil))
+	id := rec.Header().Get("X-Request-ID")
+	if id == "" || !strings.Contains(buf.String(), `"request_id":"`+id+`"`) {
+		t.Fatalf("generated id %q not logged: %s", id, buf.String())
+	}
+	if strings.Contains(buf.String(), "client_id") {
+		t.Fatalf("anonymous request logged a client id: %s", buf.String())
+	}
+}
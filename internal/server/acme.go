@@ -0,0 +1,34 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// startACMEChallengeServer answers ACME HTTP-01 challenges on addr and
+// redirects every other plain HTTP request to HTTPS.
+func startACMEChallengeServer(addr string, m *autocert.Manager) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("acme http-01 listener: %w", err)
+	}
+	srv := &http.Server{
+		Handler:           m.HTTPHandler(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("ACME challenge server stopped", "addr", addr, "error", err)
+		}
+	}()
+	return srv, nil
+}
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/loykin/provisr/internal/audit"
+	"github.com/loykin/provisr/internal/auth"
+	"github.com/loykin/provisr/internal/config"
+	pb "github.com/loykin/provisr/pkg/client/grpc"
+)
+
+// Gin context keys handlers set when the audited actor or target is in the
+// request body rather than the route or query.
+const (
+	auditActorKey  = "audit_actor"
+	auditTargetKey = "audit_target"
+)
+
+// setAuditTarget names what a request acts on for the audit log.
+func setAuditTarget(c *gin.Context, target string) { c.Set(auditTargetKey, target) }
+
+// newAuditLogger opens the audit log described by cfg; the store sink uses
+// the store of authService.
+func newAuditLogger(cfg config.AuditConfig, authService *auth.AuthService) (*audit.Logger, error) {
+	var sink audit.Sink
+	switch cfg.Sink {
+	case "", "file":
+		fileSink, err := audit.NewFileSink(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		sink = fileSink
+	case "store":
+		if authService == nil {
+			return nil, fmt.Errorf("audit store sink needs server.auth enabled")
+		}
+		sink = audit.NewStoreSink(authService.Store())
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", cfg.Sink)
+	}
+	logger, err := audit.New(sink, cfg.HashChain)
+	if err != nil {
+		_ = sink.Close()
+		return nil, err
+	}
+	return logger, nil
+}
+
+// NewAuditLogger opens the audit log of cfg, or returns nil when it is
+// absent or disabled. The store sink writes through authService. Servers
+// given the same logger append to one hash chain; the caller closes it
+// after them and before authService.
+func NewAuditLogger(cfg *config.AuditConfig, authService *auth.AuthService) (*audit.Logger, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+	logger, err := newAuditLogger(*cfg, authService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return logger, nil
+}
+
+// setAuditFromConfig opens the audit log when cfg enables it. Call it after
+// the auth service is set up, which the store sink writes through.
+func (r *Router) setAuditFromConfig(cfg *config.AuditConfig) error {
+	logger, err := NewAuditLogger(cfg, r.authService)
+	if err != nil {
+		return err
+	}
+	r.audit = logger
+	r.ownsAudit = logger != nil
+	return nil
+}
+
+// closeAuthAndAudit releases the audit log and then the auth service, whose
+// store the audit log may write to, each if the Router opened it.
+func (r *Router) closeAuthAndAudit() {
+	if r.audit != nil && r.ownsAudit {
+		_ = r.audit.Close()
+	}
+	if r.authService != nil && r.ownsAuth {
+		_ = r.authService.Close()
+	}
+}
+
+// auditMiddleware records every request that changes something, plus
+// reads rejected by authentication or authorization. Requests to unknown
+// routes are left to the access log.
+func auditMiddleware(logger *audit.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		status := c.Writer.Status()
+		method := c.Request.Method
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+		denied := status == http.StatusUnauthorized || status == http.StatusForbidden
+		if !denied && (method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions) {
+			return
+		}
+
+		entry := audit.Entry{
+			Actor:     auditActor(c),
+			Action:    method + " " + route,
+			Target:    auditTarget(c),
+			Result:    audit.ResultSuccess,
+			Status:    status,
+			ClientIP:  c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: c.GetString(requestIDKey),
+		}
+		if status >= http.StatusBadRequest {
+			entry.Result = audit.ResultFailure
+		}
+		if err := logger.Record(c.Request.Context(), entry); err != nil {
+			slog.Error("failed to write audit entry", "action", entry.Action, "error", err)
+		}
+	}
+}
+
+// auditActor is the authenticated user, the user a login or bootstrap was
+// attempted for, or "anonymous".
+func auditActor(c *gin.Context) string {
+	if v, ok := c.Get(string(auth.ResultKey)); ok {
+		if result, ok := v.(*auth.AuthResult); ok && result.Success {
+			return result.Username
+		}
+	}
+	if actor := c.GetString(auditActorKey); actor != "" {
+		return actor
+	}
+	return "anonymous"
+}
+
+// auditTarget is the target a handler set, else the route's :name or :id,
+// else the query's name or, as key=value, its other selector.
+func auditTarget(c *gin.Context) string {
+	if target := c.GetString(auditTargetKey); target != "" {
+		return target
+	}
+	for _, v := range []string{c.Param("name"), c.Param("id"), c.Query("name")} {
+		if v != "" {
+			return v
+		}
+	}
+	for _, key := range []string{"base", "group", "wildcard", "regex", "pid"} {
+		if v := c.Query(key); v != "" {
+			return key + "=" + v
+		}
+	}
+	return ""
+}
+
+// grpcAuditInterceptor is the gRPC counterpart of auditMiddleware: it
+// records every call of a method that needs write permission, plus calls
+// rejected by authentication or authorization. It must run before the auth
+// interceptor to see those rejections.
+func grpcAuditInterceptor(logger *audit.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, authResult := auth.WithGRPCResultSlot(ctx)
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		denied := code == codes.Unauthenticated || code == codes.PermissionDenied
+		if !denied && grpcPermissions[info.FullMethod].Action != "write" {
+			return resp, err
+		}
+		entry := audit.Entry{
+			Actor:  "anonymous",
+			Action: info.FullMethod,
+			Target: grpcAuditTarget(req),
+			Result: audit.ResultSuccess,
+		}
+		if result := authResult(); result != nil && result.Username != "" {
+			entry.Actor = result.Username
+		}
+		if err != nil {
+			entry.Result = audit.ResultFailure
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			entry.ClientIP = p.Addr.String()
+			if host, _, err := net.SplitHostPort(entry.ClientIP); err == nil {
+				entry.ClientIP = host
+			}
+		}
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if ua := md.Get("user-agent"); len(ua) > 0 {
+				entry.UserAgent = ua[0]
+			}
+		}
+		if err := logger.Record(ctx, entry); err != nil {
+			slog.Error("failed to write audit entry", "action", entry.Action, "error", err)
+		}
+		return resp, err
+	}
+}
+
+// grpcAuditTarget names what a gRPC request acts on, in the form
+// auditTarget gives the REST request doing the same.
+func grpcAuditTarget(req any) string {
+	switch req := req.(type) {
+	case *pb.RegisterRequest:
+		var spec struct {
+			Name string `json:"name"`
+		}
+		_ = json.Unmarshal(req.GetSpecJson(), &spec)
+		return spec.Name
+	case interface{ GetSelector() *pb.Selector }:
+		sel := req.GetSelector()
+		switch {
+		case sel.GetName() != "":
+			return sel.GetName()
+		case sel.GetBase() != "":
+			return "base=" + sel.GetBase()
+		case sel.GetWildcard() != "":
+			return "wildcard=" + sel.GetWildcard()
+		case sel.GetRegex() != "":
+			return "regex=" + sel.GetRegex()
+		}
+	case *pb.GroupRequest:
+		return "group=" + req.GetGroup()
+	case *pb.CronJobRequest:
+		return req.GetName()
+	}
+	return ""
+}
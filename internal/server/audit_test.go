@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/internal/audit"
+	"github.com/loykin/provisr/internal/config"
+	grpcclient "github.com/loykin/provisr/pkg/client/grpc"
+)
+
+func TestAuditRecordsControlOperations(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	path := filepath.Join(t.TempDir(), "audit.log")
+	r := NewRouter(mgr, "/api")
+	if err := r.setAuditFromConfig(&config.AuditConfig{Enabled: true, Path: path, HashChain: true}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.closeAuthAndAudit)
+	h := r.Handler()
+
+	if rec := doReq(t, h, http.MethodPost, "/api/register", core.Spec{Name: "audited", Command: "sleep 30"}); rec.Code != http.StatusOK {
+		t.Fatalf("register: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := doReq(t, h, http.MethodGet, "/api/status?name=audited", nil); rec.Code != http.StatusOK {
+		t.Fatalf("status: %d", rec.Code)
+	}
+	if rec := doReq(t, h, http.MethodPost, "/api/stop?name=audited&wait=1s", nil); rec.Code != http.StatusOK {
+		t.Fatalf("stop: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := doReq(t, h, http.MethodPost, "/api/start?name=missing", nil); rec.Code < http.StatusBadRequest {
+		t.Fatalf("start of an unknown process: %d", rec.Code)
+	}
+
+	entries, err := audit.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ action, target, result string }{
+		{"POST /api/register", "audited", audit.ResultSuccess},
+		{"POST /api/stop", "audited", audit.ResultSuccess},
+		{"POST /api/start", "missing", audit.ResultFailure},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d audit entries, want %d (reads are not audited): %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Actor != "anonymous" || e.Action != w.action || e.Target != w.target || e.Result != w.result || e.Time.IsZero() {
+			t.Errorf("entry %d = %+v, want %s %s %s by anonymous", i, e, w.action, w.target, w.result)
+		}
+	}
+	if err := audit.Verify(entries); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
+
+func TestAuditRecordsAuthEventsInStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authCfg := &config.AuthConfig{
+		Enabled: true,
+		Store:   config.AuthStoreConfig{Type: "sqlite", Path: filepath.Join(t.TempDir(), "auth.db")},
+	}
+	r, err := newRouterFromConfig(core.New(), "/api", authCfg, "", nil, nil)
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	if err := r.setAuditFromConfig(&config.AuditConfig{Enabled: true, Sink: "store", HashChain: true}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.closeAuthAndAudit)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := r.authService.CreateUser(ctx, "ops", "password123", "", []string{"operator"}, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	h := r.Handler()
+
+	if rec := doReq(t, h, http.MethodPost, "/api/auth/login", map[string]string{"method": "basic", "username": "ops", "password": "wrong"}); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bad login: %d", rec.Code)
+	}
+	if rec := doReq(t, h, http.MethodPost, "/api/auth/login", map[string]string{"method": "basic", "username": "ops", "password": "password123"}); rec.Code != http.StatusOK {
+		t.Fatalf("login: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := doReq(t, h, http.MethodGet, "/api/status?base=web", nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous status: %d", rec.Code)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/stop?name=missing", nil)
+	req.SetBasicAuth("ops", "password123")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries, total, err := r.authService.Store().ListAuditEntries(ctx, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ actor, action, target, result string }{
+		{"ops", "POST /api/auth/login", "", audit.ResultFailure},
+		{"ops", "POST /api/auth/login", "", audit.ResultSuccess},
+		{"anonymous", "GET /api/status", "base=web", audit.ResultFailure},
+		{"ops", "POST /api/stop", "missing", audit.ResultFailure},
+	}
+	if total != len(want) {
+		t.Fatalf("got %d audit entries, want %d: %+v", total, len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Actor != w.actor || e.Action != w.action || e.Target != w.target || e.Result != w.result {
+			t.Errorf("entry %d = %+v, want %+v", i, e, w)
+		}
+	}
+	if err := audit.Verify(entries); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
+
+func TestAuditRecordsGRPCCallsInSharedLog(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	gin.SetMode(gin.TestMode)
+	authService, err := NewAuthService(&config.AuthConfig{Enabled: true, Store: config.AuthStoreConfig{Type: "memory"}})
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	t.Cleanup(func() { _ = authService.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := authService.CreateUser(ctx, "ops", "password123", "", []string{"operator"}, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := authService.CreateUser(ctx, "watcher", "password123", "", []string{"viewer"}, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := NewAuditLogger(&config.AuditConfig{Enabled: true, Path: path, HashChain: true}, authService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = logger.Close() })
+
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	cronScheduler := core.NewCronScheduler(core.NewJobManager(mgr))
+	r := newRouterWithAuth(mgr, "/api", authService, t.TempDir(), cronScheduler, nil)
+	r.SetAuditLogger(logger)
+	h := r.Handler()
+	srv, err := NewGRPCServerWithAuth(config.ServerConfig{GRPC: &config.GRPCConfig{Listen: "127.0.0.1:0"}}, mgr, cronScheduler, authService, logger, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewGRPCServerWithAuth: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	})
+
+	ops := dialGRPC(t, srv, grpcclient.Config{Username: "ops", Password: "password123"})
+	specJSON, _ := json.Marshal(core.Spec{Name: "grpc-audited", Command: "sleep 30"})
+	if _, err := ops.Register(ctx, &grpcclient.RegisterRequest{SpecJson: specJSON}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := ops.Status(ctx, &grpcclient.StatusRequest{Selector: &grpcclient.Selector{Name: "grpc-audited"}}); err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if _, err := ops.Stop(ctx, &grpcclient.StopRequest{Selector: &grpcclient.Selector{Name: "grpc-audited"}}); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if _, err := ops.Start(ctx, &grpcclient.StartRequest{Selector: &grpcclient.Selector{Name: "grpc-audited"}}); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := ops.GroupStop(ctx, &grpcclient.GroupRequest{Group: "missing"}); err == nil {
+		t.Fatal("GroupStop of an unknown group succeeded")
+	}
+	if _, err := ops.SuspendCronJob(ctx, &grpcclient.CronJobRequest{Name: "missing"}); err == nil {
+		t.Fatal("SuspendCronJob of an unknown job succeeded")
+	}
+	watcher := dialGRPC(t, srv, grpcclient.Config{Username: "watcher", Password: "password123"})
+	if _, err := watcher.Stop(ctx, &grpcclient.StopRequest{Selector: &grpcclient.Selector{Base: "grpc"}}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("viewer Stop: got %v, want PermissionDenied", err)
+	}
+	anonymous := dialGRPC(t, srv, grpcclient.Config{})
+	if _, err := anonymous.Status(ctx, &grpcclient.StatusRequest{Selector: &grpcclient.Selector{Base: "grpc"}}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous Status: got %v, want Unauthenticated", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/stop?name=grpc-audited&wait=1s", nil)
+	req.SetBasicAuth("ops", "password123")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries, err := audit.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ actor, action, target, result string }{
+		{"ops", grpcclient.Provisr_Register_FullMethodName, "grpc-audited", audit.ResultSuccess},
+		{"ops", grpcclient.Provisr_Stop_FullMethodName, "grpc-audited", audit.ResultSuccess},
+		{"ops", grpcclient.Provisr_Start_FullMethodName, "grpc-audited", audit.ResultSuccess},
+		{"ops", grpcclient.Provisr_GroupStop_FullMethodName, "group=missing", audit.ResultFailure},
+		{"ops", grpcclient.Provisr_SuspendCronJob_FullMethodName, "missing", audit.ResultFailure},
+		{"watcher", grpcclient.Provisr_Stop_FullMethodName, "base=grpc", audit.ResultFailure},
+		{"anonymous", grpcclient.Provisr_Status_FullMethodName, "base=grpc", audit.ResultFailure},
+		{"ops", "POST /api/stop", "grpc-audited", audit.ResultSuccess},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d audit entries, want %d (reads are not audited): %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Actor != w.actor || e.Action != w.action || e.Target != w.target || e.Result != w.result {
+			t.Errorf("entry %d = %+v, want %+v", i, e, w)
+		}
+	}
+	if entries[0].ClientIP != "127.0.0.1" {
+		t.Errorf("client IP = %q, want 127.0.0.1", entries[0].ClientIP)
+	}
+	// Both servers append to one hash chain.
+	if err := audit.Verify(entries); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
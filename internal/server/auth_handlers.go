@@ -2,6 +2,7 @@ package server
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -105,6 +106,7 @@ func (api *AuthAPI) RegisterAuthEndpoints(
 		group.GET("/users/:id", authGin, userReadPerm, api.getUser)
 		group.PUT("/users/:id", authGin, userWritePerm, api.updateUser)
 		group.DELETE("/users/:id", authGin, userWritePerm, api.deleteUser)
+		group.POST("/users/:id/restore", authGin, userWritePerm, api.restoreUser)
 		group.PUT("/users/:id/password", authGin, userWritePerm, api.updateUserPassword)
 	}
 }
@@ -116,6 +118,7 @@ func (api *AuthAPI) login(c *gin.Context) {
 		handleBindingError(c, err)
 		return
 	}
+	c.Set(auditActorKey, req.Username)
 
 	result, err := api.authService.Authenticate(c.Request.Context(), req)
 	if err != nil {
@@ -144,6 +147,7 @@ func (api *AuthAPI) bootstrap(c *gin.Context) {
 		handleBindingError(c, err)
 		return
 	}
+	c.Set(auditActorKey, req.Username)
 
 	result, err := api.authService.BootstrapFirstAdmin(c.Request.Context(), req.Username, req.Password)
 	if err != nil {
@@ -172,6 +176,7 @@ func (api *AuthAPI) createUser(c *gin.Context) {
 		handleBindingError(c, err)
 		return
 	}
+	setAuditTarget(c, req.Username)
 
 	user, err := api.authService.CreateUser(
 		c.Request.Context(),
@@ -201,7 +206,17 @@ func (api *AuthAPI) listUsers(c *gin.Context) {
 		return
 	}
 
-	users, total, err := api.authService.ListUsers(c.Request.Context(), pagination.Offset, pagination.Limit)
+	var filter auth.UserFilter
+	if filter.IncludeDeleted, err = queryBool(c, "include_deleted"); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_filter", err.Error())
+		return
+	}
+	if filter.ActiveOnly, err = queryBool(c, "active"); err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_filter", err.Error())
+		return
+	}
+
+	users, total, err := api.authService.ListUsersWithFilter(c.Request.Context(), filter, pagination.Offset, pagination.Limit)
 	if err != nil {
 		respondError(c, http.StatusInternalServerError, "list_failed", err.Error())
 		return
@@ -270,9 +285,12 @@ func (api *AuthAPI) updateUser(c *gin.Context) {
 	}
 
 	if err := api.authService.UpdateUserWithPassword(c.Request.Context(), user, req.Password); err != nil {
-		if errors.Is(err, auth.ErrLastActiveAdmin) {
+		switch {
+		case errors.Is(err, auth.ErrLastActiveAdmin):
 			respondError(c, http.StatusConflict, "last_active_admin", err.Error())
-		} else {
+		case errors.Is(err, auth.ErrUserDeleted):
+			respondError(c, http.StatusConflict, "user_deleted", err.Error())
+		default:
 			respondError(c, http.StatusInternalServerError, "update_failed", err.Error())
 		}
 		return
@@ -283,11 +301,20 @@ func (api *AuthAPI) updateUser(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-// deleteUser deletes a user
+// deleteUser soft-deletes a user, or removes it permanently with ?hard=true
 func (api *AuthAPI) deleteUser(c *gin.Context) {
 	id := c.Param("id")
+	hard, err := queryBool(c, "hard")
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
 
-	if err := api.authService.DeleteUser(c.Request.Context(), id); err != nil {
+	remove := api.authService.DeleteUser
+	if hard {
+		remove = api.authService.PurgeUser
+	}
+	if err := remove(c.Request.Context(), id); err != nil {
 		if errors.Is(err, auth.ErrLastActiveAdmin) {
 			respondError(c, http.StatusConflict, "last_active_admin", err.Error())
 			return
@@ -301,6 +328,31 @@ func (api *AuthAPI) deleteUser(c *gin.Context) {
 	})
 }
 
+// restoreUser reactivates a soft-deleted user
+func (api *AuthAPI) restoreUser(c *gin.Context) {
+	user, err := api.authService.RestoreUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleAuthServiceError(c, err, auth.ErrUserNotFound, "user_not_found", "restore_failed")
+		return
+	}
+
+	user.PasswordHash = ""
+	c.JSON(http.StatusOK, user)
+}
+
+// queryBool parses an optional boolean query parameter.
+func queryBool(c *gin.Context, key string) (bool, error) {
+	v := c.Query(key)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %q", key, v)
+	}
+	return b, nil
+}
+
 // updateUserPassword updates a user's password
 func (api *AuthAPI) updateUserPassword(c *gin.Context) {
 	id := c.Param("id")
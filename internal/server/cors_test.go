@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/internal/config"
+)
+
+func corsRequest(h http.Handler, method, path, origin string, preflight bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Origin", origin)
+	if preflight {
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSAllowedOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(core.New(), "/api")
+	r.SetCORS(&config.CORSConfig{
+		Enabled:          true,
+		AllowedOrigins:   []string{"https://ui.example.com"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+	h := r.Handler()
+
+	// Preflight for a process route and for the auth routes.
+	for _, path := range []string{"/api/start", "/api/auth/login", "/api/auth/status"} {
+		rec := corsRequest(h, http.MethodOptions, path, "https://ui.example.com", true)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("preflight %s: status %d", path, rec.Code)
+		}
+		want := map[string]string{
+			"Access-Control-Allow-Origin":      "https://ui.example.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     "GET, POST, DELETE",
+			"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+			"Access-Control-Max-Age":           "600",
+			"Vary":                             "Origin",
+		}
+		for k, v := range want {
+			if got := rec.Header().Get(k); got != v {
+				t.Errorf("preflight %s: %s = %q, want %q", path, k, got, v)
+			}
+		}
+	}
+
+	rec := corsRequest(h, http.MethodGet, "/api/status", "https://ui.example.com", false)
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "https://ui.example.com" {
+		t.Fatalf("simple request: status %d, allow-origin %q", rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+	if rec.Header().Get("Access-Control-Allow-Methods") != "" {
+		t.Fatal("preflight-only headers set on a simple request")
+	}
+}
+
+func TestCORSRejectsOtherOriginsAndIsOffByDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(core.New(), "/api")
+	r.SetCORS(&config.CORSConfig{Enabled: true, AllowedOrigins: []string{"https://ui.example.com"}})
+	h := r.Handler()
+
+	if rec := corsRequest(h, http.MethodOptions, "/api/start", "https://evil.example.com", true); rec.Code != http.StatusForbidden {
+		t.Fatalf("preflight from other origin: status %d", rec.Code)
+	}
+	rec := corsRequest(h, http.MethodGet, "/api/status", "https://evil.example.com", false)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("other origin got Access-Control-Allow-Origin %q", got)
+	}
+
+	rec = corsRequest(NewRouter(core.New(), "/api").Handler(), http.MethodGet, "/api/status", "https://ui.example.com", false)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("cors disabled but got Access-Control-Allow-Origin %q", got)
+	}
+}
@@ -0,0 +1,340 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corehistory "github.com/loykin/provisr/core/history"
+	apiwire "github.com/loykin/provisr/pkg/api"
+)
+
+const (
+	// eventsDefaultLimit and eventsMaxLimit bound how many stored events
+	// GET /events returns.
+	eventsDefaultLimit = 50
+	eventsMaxLimit     = 500
+	// eventsMaxScan caps the stored rows read to fill one response, so a
+	// filter matching little doesn't walk the whole history.
+	eventsMaxScan = 10000
+	// eventsKeepAlive is how often an idle event stream sends a comment so
+	// proxies don't close it.
+	eventsKeepAlive = 15 * time.Second
+	// maxTransitionStreams bounds the open GET /debug/transitions streams.
+	maxTransitionStreams = 16
+)
+
+// eventFilter selects the lifecycle events GET /events returns.
+type eventFilter struct {
+	name  string // a process or the base name of its instances; "" for all
+	types map[corehistory.EventType]bool
+	since time.Time
+	until time.Time
+}
+
+// parseEventFilter reads the name, type, since and until query params.
+// since and until take an RFC 3339 time or a duration back from now.
+func parseEventFilter(c *gin.Context, now time.Time) (eventFilter, error) {
+	f := eventFilter{name: strings.TrimSpace(c.Query("name"))}
+	if f.name != "" && !isSafeName(f.name) {
+		return f, fmt.Errorf("invalid name")
+	}
+	if v := c.Query("type"); v != "" {
+		f.types = make(map[corehistory.EventType]bool)
+		for _, t := range strings.Split(v, ",") {
+			switch typ := corehistory.EventType(strings.TrimSpace(t)); typ {
+			case corehistory.EventStart, corehistory.EventStop, corehistory.EventRestart, corehistory.EventFatal:
+				f.types[typ] = true
+			default:
+				return f, fmt.Errorf("invalid type %q: must be start, stop, restart or fatal", t)
+			}
+		}
+	}
+	var err error
+	if f.since, err = parseEventTime(c.Query("since"), now); err != nil {
+		return f, fmt.Errorf("invalid since: %w", err)
+	}
+	if f.until, err = parseEventTime(c.Query("until"), now); err != nil {
+		return f, fmt.Errorf("invalid until: %w", err)
+	}
+	if !f.since.IsZero() && !f.until.IsZero() && f.until.Before(f.since) {
+		return f, fmt.Errorf("until must not be before since")
+	}
+	return f, nil
+}
+
+func parseEventTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a duration", v)
+	}
+	return t, nil
+}
+
+func (f eventFilter) match(e apiwire.LifecycleEvent) bool {
+	if f.name != "" && e.Name != f.name && !isInstanceName(e.Name, f.name) {
+		return false
+	}
+	if f.types != nil && !f.types[e.Type] {
+		return false
+	}
+	if !f.since.IsZero() && e.Time.Before(f.since) {
+		return false
+	}
+	return f.until.IsZero() || !e.Time.After(f.until)
+}
+
+// isInstanceName reports whether name is an instance (base-1, base-2, ...)
+// of base.
+func isInstanceName(name, base string) bool {
+	n, ok := strings.CutPrefix(name, base+"-")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(n)
+	return err == nil
+}
+
+func lifecycleEvent(e corehistory.Event) apiwire.LifecycleEvent {
+	return apiwire.LifecycleEvent{
+		Time:     e.OccurredAt,
+		Type:     e.Type,
+		Name:     e.Record.Name,
+		PID:      e.Record.PID,
+		Status:   e.Record.LastStatus,
+		ExitCode: e.Record.ExitCode,
+		Error:    e.Record.Error,
+	}
+}
+
+func storedLifecycleEvent(row corehistory.Entry) apiwire.LifecycleEvent {
+	e := apiwire.LifecycleEvent{
+		Time:     row.Timestamp,
+		Type:     row.Event,
+		Name:     row.Name,
+		PID:      row.PID,
+		Status:   row.Status,
+		ExitCode: row.ExitCode,
+	}
+	if row.Error != nil {
+		e.Error = *row.Error
+	}
+	// Rows stored before event types were recorded only have a status.
+	if e.Type == "" {
+		e.Type = corehistory.EventStop
+		if row.Status == "running" {
+			e.Type = corehistory.EventStart
+		}
+	}
+	return e
+}
+
+// storedEvents returns the newest limit stored events matching f, oldest
+// first.
+func (r *Router) storedEvents(c *gin.Context, f eventFilter, limit int) ([]apiwire.LifecycleEvent, error) {
+	events := make([]apiwire.LifecycleEvent, 0, limit)
+	for offset := 0; offset < eventsMaxScan && len(events) < limit; offset += eventsMaxLimit {
+		// List filters by substring; the exact name match happens below.
+		rows, err := r.historyReader.List(c.Request.Context(), f.name, eventsMaxLimit, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			e := storedLifecycleEvent(row)
+			if f.match(e) {
+				events = append(events, e)
+				if len(events) == limit {
+					break
+				}
+			}
+		}
+		// Rows come newest first, so the rest are all too old.
+		if len(rows) < eventsMaxLimit || (!f.since.IsZero() && rows[len(rows)-1].Timestamp.Before(f.since)) {
+			break
+		}
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// handleEvents returns recent process lifecycle events (start, stop,
+// restart, fatal) from the history store, oldest first. Query params: name
+// (a process, or a base name for all its instances), type (comma-separated),
+// since and until (RFC 3339 or a duration ago), limit (default 50, max 500)
+// and follow. With follow=true the events are streamed as server-sent
+// events and new ones follow as they happen; without a history store only
+// new ones are sent.
+func (r *Router) handleEvents(c *gin.Context) {
+	f, err := parseEventFilter(c, time.Now())
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+		return
+	}
+	limit := eventsDefaultLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSON(c, http.StatusBadRequest, errorResp{Error: "limit must be a non-negative number"})
+			return
+		}
+		limit = min(n, eventsMaxLimit)
+	}
+	follow := c.Query("follow") == "true"
+	if follow && !f.until.IsZero() {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "until can't be combined with follow"})
+		return
+	}
+	if !follow {
+		if r.historyReader == nil {
+			writeJSON(c, http.StatusServiceUnavailable, errorResp{Error: "no history store is configured; only follow=true is available"})
+			return
+		}
+		events, err := r.storedEvents(c, f, limit)
+		if err != nil {
+			writeJSON(c, http.StatusInternalServerError, errorResp{Error: err.Error()})
+			return
+		}
+		writeJSON(c, http.StatusOK, apiwire.EventsResponse{Events: events})
+		return
+	}
+
+	// Subscribe before reading the store so nothing falls in between.
+	live, cancel := r.mgr.SubscribeEvents(256)
+	defer cancel()
+	var stored []apiwire.LifecycleEvent
+	if r.historyReader != nil && limit > 0 {
+		if stored, err = r.storedEvents(c, f, limit); err != nil {
+			writeJSON(c, http.StatusInternalServerError, errorResp{Error: err.Error()})
+			return
+		}
+	}
+
+	// The server's write timeout would cut the stream off.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	var last time.Time
+	for _, e := range stored {
+		if !writeSSE(c, e) {
+			return
+		}
+		last = e.Time
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-r.streamsDone:
+			return
+		case <-keepAlive.C:
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+		case he, ok := <-live:
+			if !ok {
+				return
+			}
+			e := lifecycleEvent(he)
+			// Already sent from the store.
+			if !e.Time.After(last) || !f.match(e) {
+				continue
+			}
+			if !writeSSE(c, e) {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// handleDebugTransitions streams every state transition (stopped to
+// starting, starting to running, ...) as a server-sent event as it happens,
+// to watch a flapping process in real time. name limits it to a process or
+// the instances of a base name. At most maxTransitionStreams streams are
+// open at once.
+func (r *Router) handleDebugTransitions(c *gin.Context) {
+	name := strings.TrimSpace(c.Query("name"))
+	if name != "" && !isSafeName(name) {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid name"})
+		return
+	}
+	if r.transitionStreams.Add(1) > maxTransitionStreams {
+		r.transitionStreams.Add(-1)
+		writeJSON(c, http.StatusServiceUnavailable, errorResp{Error: "too many open transition streams"})
+		return
+	}
+	defer r.transitionStreams.Add(-1)
+	live, cancel := r.mgr.SubscribeTransitions(256)
+	defer cancel()
+
+	// The server's write timeout would cut the stream off.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-r.streamsDone:
+			return
+		case <-keepAlive.C:
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+		case t, ok := <-live:
+			if !ok {
+				return
+			}
+			if name != "" && t.Name != name && !isInstanceName(t.Name, name) {
+				continue
+			}
+			if !writeSSE(c, apiwire.StateTransition{Time: t.Time, Name: t.Name, From: t.From, To: t.To}) {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeSSE writes v as the JSON data of one server-sent event.
+func writeSSE(c *gin.Context, v any) bool {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	_, err = fmt.Fprintf(c.Writer, "data: %s\n\n", b)
+	return err == nil
+}
+
+// closeStreams ends open event streams; it runs on server shutdown.
+func (r *Router) closeStreams() {
+	r.closeStreamsOnce.Do(func() {
+		if r.streamsDone != nil {
+			close(r.streamsDone)
+		}
+	})
+}
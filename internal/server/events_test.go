@@ -0,0 +1,229 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/loykin/provisr/core"
+	corehistory "github.com/loykin/provisr/core/history"
+	apiwire "github.com/loykin/provisr/pkg/api"
+)
+
+func TestEventsFromHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	exitCode := 1
+	failure := "exit status 1"
+	r := NewRouter(core.New(), "")
+	r.SetHistoryReader(fakeHistoryReader{rows: []corehistory.Entry{
+		{Timestamp: base.Add(3 * time.Minute), Name: "web-1", PID: 11, Status: "failed", Event: corehistory.EventStop, ExitCode: &exitCode, Error: &failure},
+		{Timestamp: base.Add(2 * time.Minute), Name: "webhook", PID: 20, Status: "running", Event: corehistory.EventStart},
+		{Timestamp: base.Add(time.Minute), Name: "web-1", PID: 11, Status: "running", Event: corehistory.EventStart},
+		// Stored before event types were recorded.
+		{Timestamp: base, Name: "web", PID: 5, Status: "stopped"},
+	}})
+	h := r.Handler()
+
+	get := func(query string) []apiwire.LifecycleEvent {
+		t.Helper()
+		rec := doReq(t, h, http.MethodGet, "/events?"+query, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var resp apiwire.EventsResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Events
+	}
+	types := func(events []apiwire.LifecycleEvent) string {
+		var parts []string
+		for _, e := range events {
+			parts = append(parts, e.Name+":"+string(e.Type))
+		}
+		return strings.Join(parts, ",")
+	}
+
+	if got := types(get("name=web")); got != "web:stop,web-1:start,web-1:stop" {
+		t.Fatalf("name=web returned %s", got)
+	}
+	events := get("name=web-1&type=stop")
+	if len(events) != 1 || events[0].ExitCode == nil || *events[0].ExitCode != 1 || events[0].Error != failure {
+		t.Fatalf("stop event lost its details: %+v", events)
+	}
+	if got := types(get("type=start&since=" + base.Add(90*time.Second).Format(time.RFC3339))); got != "webhook:start" {
+		t.Fatalf("since filter returned %s", got)
+	}
+	if got := types(get("until=" + base.Add(time.Minute).Format(time.RFC3339))); got != "web:stop,web-1:start" {
+		t.Fatalf("until filter returned %s", got)
+	}
+	if got := types(get("limit=1")); got != "web-1:stop" {
+		t.Fatalf("limit=1 returned %s", got)
+	}
+
+	for _, query := range []string{"type=crash", "since=yesterday", "limit=x", "name=../etc", "follow=true&until=1h"} {
+		if rec := doReq(t, h, http.MethodGet, "/events?"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+
+	noStore := NewRouter(core.New(), "").Handler()
+	if rec := doReq(t, noStore, http.MethodGet, "/events", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("without a history store expected 503, got %d", rec.Code)
+	}
+}
+
+func TestEventsFollow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	r := NewRouter(mgr, "")
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events?follow=true&name=followed", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	received := make(chan apiwire.LifecycleEvent, 4)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var e apiwire.LifecycleEvent
+			if json.Unmarshal([]byte(data), &e) == nil {
+				received <- e
+			}
+		}
+		close(received)
+	}()
+
+	if err := mgr.Register(core.Spec{Name: "ignored", Command: "sleep 10"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Register(core.Spec{Name: "followed", Command: "sleep 10"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-received:
+		if e.Name != "followed" || e.Type != corehistory.EventStart || e.PID == 0 {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event streamed")
+	}
+
+	// Shutting the server down ends the stream.
+	r.closeStreams()
+	select {
+	case _, ok := <-received:
+		if ok {
+			t.Fatal("unexpected extra event")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream stayed open after shutdown")
+	}
+}
+
+func TestDebugTransitionsStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	r := NewRouter(mgr, "")
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	if rec := doReq(t, r.Handler(), http.MethodGet, "/debug/transitions?name=../x", nil); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid name: expected 400, got %d", rec.Code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/debug/transitions?name=flappy", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	received := make(chan apiwire.StateTransition, 16)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var tr apiwire.StateTransition
+			if json.Unmarshal([]byte(data), &tr) == nil {
+				received <- tr
+			}
+		}
+		close(received)
+	}()
+
+	if err := mgr.Register(core.Spec{Name: "other", Command: "sleep 10"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Register(core.Spec{Name: "flappy", Command: "sleep 10"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Stop("flappy", 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"stopped->starting", "starting->running", "running->stopping", "stopping->stopped"}
+	for _, step := range want {
+		select {
+		case tr := <-received:
+			if tr.Name != "flappy" || tr.From+"->"+tr.To != step || tr.Time.IsZero() {
+				t.Fatalf("got %+v, want %s", tr, step)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no transition streamed, want %s", step)
+		}
+	}
+
+	// The stream count is bounded.
+	r.transitionStreams.Store(maxTransitionStreams)
+	if rec := doReq(t, r.Handler(), http.MethodGet, "/debug/transitions", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 over the stream limit, got %d", rec.Code)
+	}
+	r.transitionStreams.Store(1) // the stream opened above
+
+	// Disconnecting releases the stream's slot.
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for r.transitionStreams.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("stream not released after disconnect: %d open", r.transitionStreams.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,299 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/loykin/provisr/core"
+	apiwire "github.com/loykin/provisr/pkg/api"
+)
+
+// importWait bounds how long POST /import waits for a replaced process to
+// stop before restarting it under the imported spec.
+const importWait = 5 * time.Second
+
+// handleExport returns every process, group and cron job definition as one
+// apiwire.ExportDocument.
+func (r *Router) handleExport(c *gin.Context) {
+	writeJSON(c, http.StatusOK, r.exportDocument())
+}
+
+func (r *Router) exportDocument() apiwire.ExportDocument {
+	doc := apiwire.ExportDocument{
+		Version:    apiwire.ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Processes:  []core.Spec{},
+		Groups:     []apiwire.ExportGroup{},
+		CronJobs:   []core.CronJob{},
+	}
+	if r.cronScheduler != nil {
+		for _, cj := range r.cronScheduler.List() {
+			doc.CronJobs = append(doc.CronJobs, cj)
+		}
+		sort.Slice(doc.CronJobs, func(i, j int) bool { return doc.CronJobs[i].Name < doc.CronJobs[j].Name })
+	}
+
+	statuses, _ := r.mgr.StatusAll("*")
+	jobProcesses := r.jobProcesses()
+	seen := make(map[string]bool, len(statuses))
+	for _, st := range statuses {
+		if jobProcesses[st.Name] {
+			continue
+		}
+		spec, err := r.mgr.GetSpec(st.Name)
+		if err != nil {
+			continue
+		}
+		base, err := r.mgr.ProcessBase(st.Name)
+		if err != nil || seen[base] {
+			continue
+		}
+		seen[base] = true
+		spec.Name = base
+		doc.Processes = append(doc.Processes, spec)
+	}
+	sort.Slice(doc.Processes, func(i, j int) bool { return doc.Processes[i].Name < doc.Processes[j].Name })
+
+	for _, g := range r.mgr.ListInstanceGroups() {
+		members := make([]string, 0, len(g.Members))
+		for _, m := range g.Members {
+			members = append(members, m.Name)
+		}
+		doc.Groups = append(doc.Groups, apiwire.ExportGroup{Name: g.Name, Members: members})
+	}
+	sort.Slice(doc.Groups, func(i, j int) bool { return doc.Groups[i].Name < doc.Groups[j].Name })
+	return doc
+}
+
+// jobProcesses returns the processes run on behalf of jobs and cron jobs;
+// those are recreated by their owner, not exported on their own.
+func (r *Router) jobProcesses() map[string]bool {
+	managers := []*core.JobManager{r.jobManager}
+	if r.cronScheduler != nil && r.cronScheduler.JobManager() != r.jobManager {
+		managers = append(managers, r.cronScheduler.JobManager())
+	}
+	owned := make(map[string]bool)
+	for _, jm := range managers {
+		if jm == nil {
+			continue
+		}
+		for _, name := range jm.ProcessNames() {
+			owned[name] = true
+		}
+	}
+	return owned
+}
+
+// handleImport recreates the definitions of an apiwire.ExportDocument.
+// Existing processes, cron jobs and groups are a 409 unless force=true, in
+// which case they are replaced; entries declared in the main config file
+// are skipped either way. Each entry is applied, and rolled back on failure,
+// on its own: an error part-way leaves earlier entries in place.
+// query: force=true (optional).
+func (r *Router) handleImport(c *gin.Context) {
+	var doc apiwire.ExportDocument
+	if err := c.ShouldBindJSON(&doc); err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid JSON: " + err.Error()})
+		return
+	}
+	force := false
+	if v := c.Query("force"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid force flag"})
+			return
+		}
+		force = b
+	}
+	result, status, err := r.importDocument(doc, force)
+	if err != nil {
+		writeJSON(c, status, errorResp{Error: err.Error()})
+		return
+	}
+	writeJSON(c, http.StatusOK, result)
+}
+
+func (r *Router) importDocument(doc apiwire.ExportDocument, force bool) (apiwire.ImportResult, int, error) {
+	result := apiwire.ImportResult{Created: []string{}, Replaced: []string{}, Skipped: []string{}}
+	if err := r.validateImport(&doc); err != nil {
+		return result, http.StatusBadRequest, err
+	}
+
+	var conflicts []string
+	for _, spec := range doc.Processes {
+		if _, ok := r.registeredName(spec.Name); ok {
+			conflicts = append(conflicts, "process/"+spec.Name)
+		}
+	}
+	for _, cj := range doc.CronJobs {
+		if _, ok := r.cronScheduler.Get(cj.Name); ok {
+			conflicts = append(conflicts, "cronjob/"+cj.Name)
+		}
+	}
+	groups := r.mgr.ListInstanceGroups()
+	for _, g := range doc.Groups {
+		if groupIndex(groups, g.Name) >= 0 {
+			conflicts = append(conflicts, "group/"+g.Name)
+		}
+	}
+	if len(conflicts) > 0 && !force {
+		return result, http.StatusConflict, fmt.Errorf("already defined: %s; import with force=true to replace them", strings.Join(conflicts, ", "))
+	}
+
+	for _, spec := range doc.Processes {
+		entry := "process/" + spec.Name
+		current, exists := r.registeredName(spec.Name)
+		if !exists {
+			if status, err := r.registerSpec(spec); err != nil {
+				return result, status, fmt.Errorf("%s: %w", entry, err)
+			}
+			result.Created = append(result.Created, entry)
+			continue
+		}
+		status, err := r.updateSpec(current, spec, importWait)
+		switch {
+		case errors.Is(err, errInlineProcess):
+			result.Skipped = append(result.Skipped, entry)
+		case err != nil:
+			return result, status, fmt.Errorf("%s: %w", entry, err)
+		default:
+			result.Replaced = append(result.Replaced, entry)
+		}
+	}
+
+	for _, cj := range doc.CronJobs {
+		entry := "cronjob/" + cj.Name
+		if _, exists := r.cronScheduler.Get(cj.Name); exists {
+			if r.isInlineConfiguredCronJob(cj.Name) {
+				result.Skipped = append(result.Skipped, entry)
+				continue
+			}
+			if err := r.cronScheduler.Update(cj.Name, cj); err != nil {
+				return result, http.StatusBadRequest, fmt.Errorf("%s: %w", entry, err)
+			}
+			if err := r.persistCronJobFile(cj); err != nil {
+				return result, http.StatusInternalServerError, fmt.Errorf("%s: %w", entry, err)
+			}
+			result.Replaced = append(result.Replaced, entry)
+			continue
+		}
+		// Persist before scheduling, same rationale as handleRegister.
+		if err := r.persistCronJobFile(cj); err != nil {
+			return result, http.StatusInternalServerError, fmt.Errorf("%s: %w", entry, err)
+		}
+		if err := r.cronScheduler.Add(cj); err != nil {
+			_ = r.removeProgramFile(cj.Name)
+			return result, http.StatusBadRequest, fmt.Errorf("%s: %w", entry, err)
+		}
+		result.Created = append(result.Created, entry)
+	}
+
+	// Groups live in the main config file, so imported ones only apply to
+	// the running daemon.
+	for _, g := range doc.Groups {
+		entry := "group/" + g.Name
+		group := core.ManagerInstanceGroup{Name: g.Name}
+		for _, member := range g.Members {
+			name, _ := r.registeredName(member)
+			spec, err := r.mgr.GetSpec(name)
+			if err != nil {
+				return result, http.StatusBadRequest, fmt.Errorf("%s: member %q: %w", entry, member, err)
+			}
+			spec.Name = member
+			group.Members = append(group.Members, spec)
+		}
+		if i := groupIndex(groups, g.Name); i >= 0 {
+			groups[i] = group
+			result.Replaced = append(result.Replaced, entry)
+		} else {
+			groups = append(groups, group)
+			result.Created = append(result.Created, entry)
+		}
+	}
+	if len(doc.Groups) > 0 {
+		r.mgr.SetInstanceGroups(groups)
+	}
+	return result, http.StatusOK, nil
+}
+
+// validateImport checks every entry of doc before anything is applied.
+func (r *Router) validateImport(doc *apiwire.ExportDocument) error {
+	if doc.Version > apiwire.ExportVersion {
+		return fmt.Errorf("unsupported export version %d (newest supported is %d)", doc.Version, apiwire.ExportVersion)
+	}
+	processes := make(map[string]bool, len(doc.Processes))
+	for _, spec := range doc.Processes {
+		if err := validateSpec(spec); err != nil {
+			return fmt.Errorf("process/%s: %w", spec.Name, err)
+		}
+		if processes[spec.Name] {
+			return fmt.Errorf("process/%s: listed twice", spec.Name)
+		}
+		processes[spec.Name] = true
+	}
+	if len(doc.CronJobs) > 0 && r.cronScheduler == nil {
+		return errors.New("cron jobs cannot be imported: no cron scheduler is running")
+	}
+	cronJobs := make(map[string]bool, len(doc.CronJobs))
+	for i := range doc.CronJobs {
+		cj := &doc.CronJobs[i]
+		if !isSafeName(cj.Name) {
+			return fmt.Errorf("cronjob/%s: invalid name: allowed [A-Za-z0-9._-] and no '..' or path separators", cj.Name)
+		}
+		if cronJobs[cj.Name] {
+			return fmt.Errorf("cronjob/%s: listed twice", cj.Name)
+		}
+		cronJobs[cj.Name] = true
+		// Same default as bindAndValidateCronJob.
+		if cj.JobTemplate.Name == "" {
+			cj.JobTemplate.Name = cj.Name
+		}
+	}
+	groups := make(map[string]bool, len(doc.Groups))
+	for _, g := range doc.Groups {
+		if !isSafeName(g.Name) {
+			return fmt.Errorf("group/%s: invalid name: allowed [A-Za-z0-9._-] and no '..' or path separators", g.Name)
+		}
+		if groups[g.Name] {
+			return fmt.Errorf("group/%s: listed twice", g.Name)
+		}
+		groups[g.Name] = true
+		if len(g.Members) == 0 {
+			return fmt.Errorf("group/%s: requires members", g.Name)
+		}
+		for _, member := range g.Members {
+			if _, ok := r.registeredName(member); !ok && !processes[member] {
+				return fmt.Errorf("group/%s: unknown member %q", g.Name, member)
+			}
+		}
+	}
+	return nil
+}
+
+// registeredName returns the name of a registered process with base name
+// base: base itself, or its first instance for multi-instance processes.
+func (r *Router) registeredName(base string) (string, bool) {
+	if _, err := r.mgr.GetSpec(base); err == nil {
+		return base, true
+	}
+	first := r.mgr.InstanceNaming().Name(base, 0)
+	if b, err := r.mgr.ProcessBase(first); err == nil && b == base {
+		return first, true
+	}
+	return "", false
+}
+
+func groupIndex(groups []core.ManagerInstanceGroup, name string) int {
+	for i, g := range groups {
+		if g.Name == name {
+			return i
+		}
+	}
+	return -1
+}
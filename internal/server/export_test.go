@@ -0,0 +1,190 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/loykin/provisr/core"
+	apiwire "github.com/loykin/provisr/pkg/api"
+)
+
+func newExportTestRouter(t *testing.T) (*Router, *core.Manager, *core.CronScheduler, string) {
+	t.Helper()
+	mgr := core.New()
+	cron := core.NewCronScheduler(core.NewJobManager(mgr))
+	t.Cleanup(func() {
+		_ = cron.Stop()
+		_ = mgr.Shutdown()
+	})
+	programsDir := t.TempDir()
+	r, err := newRouterFromConfig(mgr, "", nil, programsDir, cron, nil)
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	return r, mgr, cron, programsDir
+}
+
+func exportDoc(t *testing.T, h http.Handler) apiwire.ExportDocument {
+	t.Helper()
+	rec := doReq(t, h, http.MethodGet, "/export", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export: %d %s", rec.Code, rec.Body.String())
+	}
+	var doc apiwire.ExportDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	return doc
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	gin.SetMode(gin.TestMode)
+	src, srcMgr, srcCron, _ := newExportTestRouter(t)
+	logDir := t.TempDir()
+	web := core.Spec{
+		Name:    "web",
+		Command: "sleep 5",
+		Log:     core.LogConfig{File: core.LogFileConfig{Dir: logDir, MaxSizeMB: 5, MaxBackups: 2}},
+		Lifecycle: core.LifecycleHooks{
+			PreStart:  []core.Hook{{Name: "warm", Command: "true", Timeout: 2 * time.Second}},
+			PostStop:  []core.Hook{{Name: "notify", Command: "true", FailureMode: core.FailureModeIgnore}},
+			AsyncWait: time.Second,
+		},
+	}
+	if err := srcMgr.RegisterN(web); err != nil {
+		t.Fatalf("register web: %v", err)
+	}
+	if err := srcMgr.RegisterN(core.Spec{Name: "worker", Command: "sleep 5", Instances: 2}); err != nil {
+		t.Fatalf("register worker: %v", err)
+	}
+	srcMgr.SetInstanceGroups([]core.ManagerInstanceGroup{{Name: "stack", Members: []core.Spec{web}}})
+	if err := srcCron.Add(core.CronJob{Name: "nightly", Schedule: "0 3 * * *", JobTemplate: core.JobSpec{Name: "nightly", Command: "true"}}); err != nil {
+		t.Fatalf("add cronjob: %v", err)
+	}
+
+	exported := exportDoc(t, src.Handler())
+	if len(exported.Processes) != 2 || len(exported.CronJobs) != 1 || len(exported.Groups) != 1 {
+		t.Fatalf("unexpected export: %+v", exported)
+	}
+
+	dst, dstMgr, dstCron, dstPrograms := newExportTestRouter(t)
+	h := dst.Handler()
+	rec := doReq(t, h, http.MethodPost, "/import", exported)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("import: %d %s", rec.Code, rec.Body.String())
+	}
+	var result apiwire.ImportResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Created) != 4 || len(result.Replaced) != 0 {
+		t.Fatalf("unexpected import result: %+v", result)
+	}
+
+	got, err := dstMgr.GetSpec("web")
+	if err != nil {
+		t.Fatalf("web not imported: %v", err)
+	}
+	if len(got.Lifecycle.PreStart) != 1 || got.Lifecycle.PreStart[0].Timeout != 2*time.Second ||
+		len(got.Lifecycle.PostStop) != 1 || got.Lifecycle.AsyncWait != time.Second {
+		t.Fatalf("lifecycle hooks not preserved: %+v", got.Lifecycle)
+	}
+	if got.Log.File.Dir != logDir || got.Log.File.MaxSizeMB != 5 || got.Log.File.MaxBackups != 2 {
+		t.Fatalf("log config not preserved: %+v", got.Log.File)
+	}
+	if _, err := dstMgr.GetSpec("worker-2"); err != nil {
+		t.Fatalf("worker instances not imported: %v", err)
+	}
+	if _, ok := dstCron.Get("nightly"); !ok {
+		t.Fatal("cronjob not imported")
+	}
+	if groups := dstMgr.ListInstanceGroups(); len(groups) != 1 || groups[0].Name != "stack" {
+		t.Fatalf("group not imported: %+v", groups)
+	}
+	for _, name := range []string{"web", "worker", "nightly"} {
+		if _, err := os.Stat(filepath.Join(dstPrograms, name+".json")); err != nil {
+			t.Fatalf("program file for %s not written: %v", name, err)
+		}
+	}
+
+	reexported := exportDoc(t, h)
+	exported.ExportedAt, reexported.ExportedAt = time.Time{}, time.Time{}
+	want, _ := json.Marshal(exported)
+	have, _ := json.Marshal(reexported)
+	if string(want) != string(have) {
+		t.Fatalf("round trip changed the document:\nwant %s\nhave %s", want, have)
+	}
+
+	if rec := doReq(t, h, http.MethodPost, "/import", exported); rec.Code != http.StatusConflict {
+		t.Fatalf("import over existing entries: expected 409, got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = doReq(t, h, http.MethodPost, "/import?force=true", exported)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("forced import: %d %s", rec.Code, rec.Body.String())
+	}
+	result = apiwire.ImportResult{}
+	_ = json.Unmarshal(rec.Body.Bytes(), &result)
+	if len(result.Replaced) != 4 || len(result.Created) != 0 {
+		t.Fatalf("unexpected forced import result: %+v", result)
+	}
+}
+
+func TestExportKeepsProcessesNamedLikeJobs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	gin.SetMode(gin.TestMode)
+	r, mgr, cron, _ := newExportTestRouter(t)
+	if err := cron.Add(core.CronJob{Name: "backup", Schedule: "0 3 * * *", JobTemplate: core.JobSpec{Name: "backup", Command: "true"}}); err != nil {
+		t.Fatalf("add cronjob: %v", err)
+	}
+	if err := mgr.RegisterN(core.Spec{Name: "backup-db", Command: "sleep 5"}); err != nil {
+		t.Fatalf("register backup-db: %v", err)
+	}
+	if err := cron.JobManager().CreateJob(core.JobSpec{Name: "batch", Command: "sleep 5"}); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		instances, _ := cron.JobManager().GetJobInstances("batch")
+		if len(instances) > 0 {
+			if _, err := mgr.GetSpec(instances[0].Name); err == nil {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("job process did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	doc := exportDoc(t, r.Handler())
+	if len(doc.Processes) != 1 || doc.Processes[0].Name != "backup-db" {
+		t.Fatalf("exported processes %+v, want only backup-db", doc.Processes)
+	}
+}
+
+func TestImportRejectsInvalidDocuments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, _, _, _ := newExportTestRouter(t)
+	h := r.Handler()
+	for name, doc := range map[string]apiwire.ExportDocument{
+		"newer version":  {Version: apiwire.ExportVersion + 1},
+		"unsafe name":    {Processes: []core.Spec{{Name: "../x", Command: "true"}}},
+		"unknown member": {Groups: []apiwire.ExportGroup{{Name: "g", Members: []string{"ghost"}}}},
+	} {
+		if rec := doReq(t, h, http.MethodPost, "/import", doc); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d %s", name, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/internal/audit"
+	"github.com/loykin/provisr/internal/auth"
+	"github.com/loykin/provisr/internal/config"
+	tlsutil "github.com/loykin/provisr/internal/tls"
+	pb "github.com/loykin/provisr/pkg/client/grpc"
+)
+
+// grpcPermissions mirrors the permissions the REST routes require.
+var grpcPermissions = map[string]auth.Permission{
+	pb.Provisr_Register_FullMethodName:       {Resource: "process", Action: "write"},
+	pb.Provisr_Start_FullMethodName:          {Resource: "process", Action: "write"},
+	pb.Provisr_Stop_FullMethodName:           {Resource: "process", Action: "write"},
+	pb.Provisr_Status_FullMethodName:         {Resource: "process", Action: "read"},
+	pb.Provisr_GroupStatus_FullMethodName:    {Resource: "process", Action: "read"},
+	pb.Provisr_GroupStart_FullMethodName:     {Resource: "process", Action: "write"},
+	pb.Provisr_GroupStop_FullMethodName:      {Resource: "process", Action: "write"},
+	pb.Provisr_ListCronJobs_FullMethodName:   {Resource: "job", Action: "read"},
+	pb.Provisr_TriggerCronJob_FullMethodName: {Resource: "job", Action: "write"},
+	pb.Provisr_SuspendCronJob_FullMethodName: {Resource: "job", Action: "write"},
+	pb.Provisr_ResumeCronJob_FullMethodName:  {Resource: "job", Action: "write"},
+}
+
+// GRPCServer serves the gRPC API on its own listener, sharing the Manager
+// and cron scheduler with the HTTP server.
+type GRPCServer struct {
+	server    *grpc.Server
+	listener  net.Listener
+	closeAuth func() // releases an auth service and audit log the server opened itself
+}
+
+// NewGRPCServer starts the gRPC API on serverConfig.GRPC.Listen, a TCP
+// address or unix:///path socket. It uses the same auth store, audit log,
+// TLS settings and socket mode as the HTTP server, but its own auth service
+// and audit logger; use NewGRPCServerWithAuth to share the HTTP server's,
+// which a hash-chained audit log needs to stay one chain.
+func NewGRPCServer(serverConfig config.ServerConfig, mgr *core.Manager, cronScheduler *core.CronScheduler, programsDirectory string) (*GRPCServer, error) {
+	if serverConfig.GRPC == nil || serverConfig.GRPC.Listen == "" {
+		return nil, errors.New("server.grpc.listen is required")
+	}
+	r, err := newRouterFromConfig(mgr, serverConfig.BasePath, serverConfig.Auth, programsDirectory, cronScheduler, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.setAuditFromConfig(serverConfig.Audit); err != nil {
+		r.closeAuthAndAudit()
+		return nil, err
+	}
+	return startGRPCServer(serverConfig, r)
+}
+
+// NewGRPCServerWithAuth is NewGRPCServer authenticating with authService
+// (see NewAuthService), so tokens issued by the HTTP server sharing it are
+// accepted, and auditing to auditLogger (see NewAuditLogger). The caller
+// closes both after the server.
+func NewGRPCServerWithAuth(serverConfig config.ServerConfig, mgr *core.Manager, cronScheduler *core.CronScheduler, authService *auth.AuthService, auditLogger *audit.Logger, programsDirectory string) (*GRPCServer, error) {
+	if serverConfig.GRPC == nil || serverConfig.GRPC.Listen == "" {
+		return nil, errors.New("server.grpc.listen is required")
+	}
+	r := newRouterWithAuth(mgr, serverConfig.BasePath, authService, programsDirectory, cronScheduler, nil)
+	r.SetAuditLogger(auditLogger)
+	return startGRPCServer(serverConfig, r)
+}
+
+func startGRPCServer(serverConfig config.ServerConfig, r *Router) (*GRPCServer, error) {
+	tlsConfig, err := tlsutil.SetupTLS(serverConfig)
+	if err != nil {
+		r.closeAuthAndAudit()
+		return nil, fmt.Errorf("failed to setup TLS: %w", err)
+	}
+	mw := auth.NewMiddleware(r.authService, r.authService != nil)
+	var interceptors []grpc.UnaryServerInterceptor
+	if r.audit != nil {
+		interceptors = append(interceptors, grpcAuditInterceptor(r.audit))
+	}
+	// Failed credentials are limited by peer address, as on the HTTP server.
+	if rl := serverConfig.RateLimit; rl != nil && rl.Enabled {
+		interceptors = append(interceptors, NewRateLimiter(*rl).GRPCFailureInterceptor())
+	}
+	interceptors = append(interceptors, mw.GRPCUnaryInterceptor(grpcPermissions))
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(interceptors...)}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	listener, err := listenServer(config.ServerConfig{Listen: serverConfig.GRPC.Listen, SocketMode: serverConfig.SocketMode})
+	if err != nil {
+		r.closeAuthAndAudit()
+		return nil, err
+	}
+	srv := grpc.NewServer(opts...)
+	pb.RegisterProvisrServer(srv, &grpcService{r: r})
+	go func() { _ = srv.Serve(listener) }()
+
+	return &GRPCServer{server: srv, listener: listener, closeAuth: r.closeAuthAndAudit}, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *GRPCServer) Addr() net.Addr { return s.listener.Addr() }
+
+// Shutdown stops accepting calls and waits for in-flight ones until ctx is
+// done, after which remaining calls are cancelled.
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.server.Stop()
+		err = ctx.Err()
+	}
+	s.closeAuth()
+	return err
+}
+
+// grpcService implements the generated ProvisrServer on top of the Router
+// so both front ends share validation and program-file persistence.
+type grpcService struct {
+	pb.UnimplementedProvisrServer
+	r *Router
+}
+
+// grpcError maps the HTTP status the REST handler would answer with onto
+// the closest gRPC code.
+func grpcError(httpStatus int, err error) error {
+	code := codes.Unknown
+	switch httpStatus {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.FailedPrecondition
+	case http.StatusInternalServerError:
+		code = codes.Internal
+	}
+	return status.Error(code, err.Error())
+}
+
+func selectorFromProto(sel *pb.Selector, wait time.Duration) (*processSelector, error) {
+	return newProcessSelector(sel.GetName(), sel.GetBase(), sel.GetWildcard(), sel.GetRegex(), wait)
+}
+
+// durationOr returns d, or def when d is unset.
+func durationOr(d *durationpb.Duration, def time.Duration) time.Duration {
+	if d == nil {
+		return def
+	}
+	return d.AsDuration()
+}
+
+func (s *grpcService) Register(_ context.Context, req *pb.RegisterRequest) (*emptypb.Empty, error) {
+	var spec core.Spec
+	if err := json.Unmarshal(req.GetSpecJson(), &spec); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid JSON: "+err.Error())
+	}
+	if err := validateSpec(spec); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if code, err := s.r.registerSpec(spec); err != nil {
+		return nil, grpcError(code, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *grpcService) Start(ctx context.Context, req *pb.StartRequest) (*emptypb.Empty, error) {
+	sel, err := selectorFromProto(req.GetSelector(), 0)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	switch {
+	case sel.regex != "":
+		return nil, status.Error(codes.InvalidArgument, errRegexSelectorUnsupported)
+	case sel.name != "":
+		err = s.r.mgr.StartContext(ctx, sel.name)
+	case sel.base != "":
+		err = s.r.mgr.StartAll(sel.base)
+	default:
+		err = s.r.mgr.StartAll(sel.wild)
+	}
+	if err != nil {
+		return nil, grpcError(http.StatusBadRequest, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *grpcService) Stop(ctx context.Context, req *pb.StopRequest) (*emptypb.Empty, error) {
+	sel, err := selectorFromProto(req.GetSelector(), durationOr(req.GetWait(), 2*time.Second))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	switch {
+	case sel.base != "":
+		err = s.r.mgr.StopAll(sel.base, sel.wait)
+	case sel.regex != "":
+		err = s.r.mgr.StopRegex(sel.regex, sel.wait)
+	case sel.wild != "":
+		err = s.r.mgr.StopAll(sel.wild, sel.wait)
+	default:
+		err = s.r.mgr.StopContext(ctx, sel.name, sel.wait)
+	}
+	if err != nil {
+		return nil, grpcError(http.StatusBadRequest, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *grpcService) Status(_ context.Context, req *pb.StatusRequest) (*pb.StatusResponse, error) {
+	sel, err := selectorFromProto(req.GetSelector(), 0)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var sts []core.Status
+	switch {
+	case sel.name != "":
+		var st core.Status
+		st, err = s.r.mgr.Status(sel.name)
+		sts = []core.Status{st}
+	case sel.regex != "":
+		sts, err = s.r.mgr.StatusRegex(sel.regex)
+	case sel.base != "":
+		sts, err = s.r.mgr.StatusAll(sel.base)
+	default:
+		sts, err = s.r.mgr.StatusAll(sel.wild)
+	}
+	if err != nil {
+		return nil, grpcError(http.StatusBadRequest, err)
+	}
+	return &pb.StatusResponse{Processes: statusesToProto(sts)}, nil
+}
+
+func (s *grpcService) GroupStatus(_ context.Context, req *pb.GroupRequest) (*pb.GroupStatusResponse, error) {
+	if err := validateGroupName(req.GetGroup()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	groupStatus, err := s.r.mgr.InstanceGroupStatus(req.GetGroup())
+	if err != nil {
+		return nil, grpcError(http.StatusNotFound, err)
+	}
+	members := make([]string, 0, len(groupStatus))
+	for member := range groupStatus {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	resp := &pb.GroupStatusResponse{Members: make([]*pb.GroupMemberStatus, 0, len(members))}
+	for _, member := range members {
+		resp.Members = append(resp.Members, &pb.GroupMemberStatus{
+			Member:    member,
+			Instances: statusesToProto(groupStatus[member]),
+		})
+	}
+	return resp, nil
+}
+
+func (s *grpcService) GroupStart(_ context.Context, req *pb.GroupRequest) (*emptypb.Empty, error) {
+	if err := validateGroupName(req.GetGroup()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.r.mgr.InstanceGroupStart(req.GetGroup()); err != nil {
+		return nil, grpcError(http.StatusBadRequest, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *grpcService) GroupStop(_ context.Context, req *pb.GroupRequest) (*emptypb.Empty, error) {
+	if err := validateGroupName(req.GetGroup()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.r.mgr.InstanceGroupStop(req.GetGroup(), durationOr(req.GetWait(), 3*time.Second)); err != nil {
+		return nil, grpcError(http.StatusBadRequest, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func validateGroupName(group string) error {
+	if group == "" {
+		return errors.New("group parameter required")
+	}
+	// Validate group name to avoid path traversal
+	if !isSafeName(group) {
+		return errors.New("invalid group name: allowed [A-Za-z0-9._-] and no '..' or path separators")
+	}
+	return nil
+}
+
+func (s *grpcService) ListCronJobs(context.Context, *emptypb.Empty) (*pb.ListCronJobsResponse, error) {
+	if s.r.cronScheduler == nil {
+		return nil, status.Error(codes.Unimplemented, "cron scheduler is not available")
+	}
+	specs := s.r.cronScheduler.List()
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp := &pb.ListCronJobsResponse{Cronjobs: make([]*pb.CronJob, 0, len(names))}
+	for _, name := range names {
+		cj, ok := s.r.cronJobResponse(name)
+		if !ok {
+			continue
+		}
+		resp.Cronjobs = append(resp.Cronjobs, &pb.CronJob{
+			Name:               cj.Name,
+			Schedule:           cj.Schedule,
+			Suspended:          cj.Suspend != nil && *cj.Suspend,
+			Provisioned:        cj.Provisioned,
+			Active:             int32(len(cj.Status.Active)),
+			LastScheduleTime:   timestampOrNil(cj.Status.LastScheduleTime),
+			LastSuccessfulTime: timestampOrNil(cj.Status.LastSuccessfulTime),
+			NextSchedule:       timestampOrNil(cj.NextSchedule),
+		})
+	}
+	return resp, nil
+}
+
+func (s *grpcService) TriggerCronJob(_ context.Context, req *pb.CronJobRequest) (*emptypb.Empty, error) {
+	if s.r.cronScheduler == nil {
+		return nil, status.Error(codes.Unimplemented, "cron scheduler is not available")
+	}
+	if err := s.r.cronScheduler.Trigger(req.GetName()); err != nil {
+		return nil, grpcError(http.StatusBadRequest, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *grpcService) SuspendCronJob(_ context.Context, req *pb.CronJobRequest) (*emptypb.Empty, error) {
+	return s.setCronJobSuspended(req.GetName(), s.r.cronScheduler.Suspend)
+}
+
+func (s *grpcService) ResumeCronJob(_ context.Context, req *pb.CronJobRequest) (*emptypb.Empty, error) {
+	return s.setCronJobSuspended(req.GetName(), s.r.cronScheduler.Resume)
+}
+
+// setCronJobSuspended applies suspend or resume and persists the result,
+// as the REST suspend/resume handlers do.
+func (s *grpcService) setCronJobSuspended(name string, apply func(string) error) (*emptypb.Empty, error) {
+	if s.r.cronScheduler == nil {
+		return nil, status.Error(codes.Unimplemented, "cron scheduler is not available")
+	}
+	if s.r.isInlineConfiguredCronJob(name) {
+		return nil, grpcError(http.StatusConflict, errors.New(errInlineConfigured("cronjob", name).Error))
+	}
+	if err := apply(name); err != nil {
+		return nil, grpcError(http.StatusBadRequest, err)
+	}
+	if spec, ok := s.r.cronScheduler.Get(name); ok {
+		_ = s.r.persistCronJobFile(spec)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func statusesToProto(sts []core.Status) []*pb.ProcessStatus {
+	out := make([]*pb.ProcessStatus, len(sts))
+	for i, st := range sts {
+		ps := &pb.ProcessStatus{
+			Name:        st.Name,
+			State:       st.State,
+			Running:     st.Running,
+			Pid:         int64(st.PID),
+			Uptime:      durationpb.New(st.Uptime),
+			Restarts:    st.Restarts,
+			DetectedBy:  st.DetectedBy,
+			Provisioned: st.Provisioned,
+		}
+		if !st.StartedAt.IsZero() {
+			ps.StartedAt = timestamppb.New(st.StartedAt)
+		}
+		if !st.StoppedAt.IsZero() {
+			ps.StoppedAt = timestamppb.New(st.StoppedAt)
+		}
+		if st.ExitErr != nil {
+			ps.ExitError = st.ExitErr.Error()
+		}
+		out[i] = ps
+	}
+	return out
+}
+
+func timestampOrNil(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
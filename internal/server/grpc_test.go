@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/internal/auth"
+	"github.com/loykin/provisr/internal/config"
+	grpcclient "github.com/loykin/provisr/pkg/client/grpc"
+)
+
+func startGRPC(t *testing.T, cfg config.ServerConfig, mgr *core.Manager) *GRPCServer {
+	t.Helper()
+	cfg.GRPC = &config.GRPCConfig{Listen: "127.0.0.1:0"}
+	srv, err := NewGRPCServer(cfg, mgr, core.NewCronScheduler(core.NewJobManager(mgr)), t.TempDir())
+	if err != nil {
+		t.Fatalf("NewGRPCServer: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	})
+	return srv
+}
+
+func dialGRPC(t *testing.T, srv *GRPCServer, cfg grpcclient.Config) *grpcclient.Client {
+	t.Helper()
+	cfg.Target = srv.Addr().String()
+	client, err := grpcclient.New(cfg)
+	if err != nil {
+		t.Fatalf("grpcclient.New: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestGRPCRegisterStatusStop(t *testing.T) {
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	client := dialGRPC(t, startGRPC(t, config.ServerConfig{}, mgr), grpcclient.Config{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	specJSON, _ := json.Marshal(core.Spec{Name: "grpc-demo", Command: "sleep 5"})
+	if _, err := client.Register(ctx, &grpcclient.RegisterRequest{SpecJson: specJSON}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := client.Register(ctx, &grpcclient.RegisterRequest{SpecJson: specJSON}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("duplicate Register: got %v, want InvalidArgument", err)
+	}
+
+	resp, err := client.Status(ctx, &grpcclient.StatusRequest{Selector: &grpcclient.Selector{Name: "grpc-demo"}})
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(resp.GetProcesses()) != 1 || !resp.GetProcesses()[0].GetRunning() || resp.GetProcesses()[0].GetPid() == 0 {
+		t.Fatalf("unexpected status: %+v", resp.GetProcesses())
+	}
+
+	if _, err := client.Stop(ctx, &grpcclient.StopRequest{
+		Selector: &grpcclient.Selector{Name: "grpc-demo"},
+		Wait:     durationpb.New(2 * time.Second),
+	}); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	resp, err = client.Status(ctx, &grpcclient.StatusRequest{Selector: &grpcclient.Selector{Base: "grpc-demo"}})
+	if err != nil {
+		t.Fatalf("Status after stop: %v", err)
+	}
+	if len(resp.GetProcesses()) != 1 || resp.GetProcesses()[0].GetRunning() {
+		t.Fatalf("process still running: %+v", resp.GetProcesses())
+	}
+
+	_, err = client.Start(ctx, &grpcclient.StartRequest{Selector: &grpcclient.Selector{Regex: "grpc-.*"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Start with regex: got %v, want InvalidArgument", err)
+	}
+	_, err = client.Status(ctx, &grpcclient.StatusRequest{Selector: &grpcclient.Selector{Name: "a", Base: "b"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Status with two selectors: got %v, want InvalidArgument", err)
+	}
+	if _, err := client.ListCronJobs(ctx, &emptypb.Empty{}); err != nil {
+		t.Fatalf("ListCronJobs: %v", err)
+	}
+}
+
+func TestGRPCAuthInterceptor(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "auth.db")
+	authCfg := &config.AuthConfig{Enabled: true, Store: config.AuthStoreConfig{Type: "sqlite", Path: dbPath}}
+	seed, err := auth.NewAuthService(auth.AuthConfig{Store: authCfg.Store})
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := seed.CreateUser(ctx, "watcher", "password123", "", []string{"viewer"}, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	_ = seed.Close()
+
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	srv := startGRPC(t, config.ServerConfig{Auth: authCfg}, mgr)
+	statusReq := &grpcclient.StatusRequest{Selector: &grpcclient.Selector{Base: "nothing"}}
+
+	anonymous := dialGRPC(t, srv, grpcclient.Config{})
+	if _, err := anonymous.Status(ctx, statusReq); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous Status: got %v, want Unauthenticated", err)
+	}
+	wrong := dialGRPC(t, srv, grpcclient.Config{Username: "watcher", Password: "nope"})
+	if _, err := wrong.Status(ctx, statusReq); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("bad password Status: got %v, want Unauthenticated", err)
+	}
+
+	viewer := dialGRPC(t, srv, grpcclient.Config{Username: "watcher", Password: "password123"})
+	if _, err := viewer.Status(ctx, statusReq); err != nil {
+		t.Fatalf("viewer Status: %v", err)
+	}
+	_, err = viewer.Stop(ctx, &grpcclient.StopRequest{Selector: &grpcclient.Selector{Name: "x"}})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("viewer Stop: got %v, want PermissionDenied", err)
+	}
+}
+
+func TestGRPCRateLimitsFailedAuthentication(t *testing.T) {
+	authService, err := NewAuthService(&config.AuthConfig{Enabled: true, Store: config.AuthStoreConfig{Type: "memory"}})
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	t.Cleanup(func() { _ = authService.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := authService.CreateUser(ctx, "watcher", "password123", "", []string{"viewer"}, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	cfg := config.ServerConfig{
+		GRPC:      &config.GRPCConfig{Listen: "127.0.0.1:0"},
+		RateLimit: &config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.5, Burst: 3, Key: "client"},
+	}
+	srv, err := NewGRPCServerWithAuth(cfg, mgr, core.NewCronScheduler(core.NewJobManager(mgr)), authService, nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewGRPCServerWithAuth: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	})
+	statusReq := &grpcclient.StatusRequest{Selector: &grpcclient.Selector{Base: "nothing"}}
+
+	viewer := dialGRPC(t, srv, grpcclient.Config{Username: "watcher", Password: "password123"})
+	for i := 0; i < 3; i++ {
+		if _, err := viewer.Status(ctx, statusReq); err != nil {
+			t.Fatalf("viewer Status %d: %v", i+1, err)
+		}
+	}
+	wrong := dialGRPC(t, srv, grpcclient.Config{Username: "watcher", Password: "nope"})
+	for i := 0; i < 3; i++ {
+		if _, err := wrong.Status(ctx, statusReq); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("bad password Status %d: got %v, want Unauthenticated", i+1, err)
+		}
+	}
+	// The address is out of failures: even the right password waits.
+	if _, err := wrong.Status(ctx, statusReq); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("bad password after failures: got %v, want ResourceExhausted", err)
+	}
+	if _, err := viewer.Status(ctx, statusReq); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("viewer after failures: got %v, want ResourceExhausted", err)
+	}
+}
+
+func TestGRPCAcceptsTokensOfSharedAuthService(t *testing.T) {
+	authService, err := NewAuthService(&config.AuthConfig{Enabled: true, Store: config.AuthStoreConfig{Type: "memory"}})
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	t.Cleanup(func() { _ = authService.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := authService.CreateUser(ctx, "watcher", "password123", "", []string{"viewer"}, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	cronScheduler := core.NewCronScheduler(core.NewJobManager(mgr))
+	h := newRouterWithAuth(mgr, "/api", authService, t.TempDir(), cronScheduler, nil).Handler()
+	rec := doReq(t, h, http.MethodPost, "/api/auth/login", map[string]string{"method": "basic", "username": "watcher", "password": "password123"})
+	var login auth.AuthResult
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &login) != nil || login.Token == nil {
+		t.Fatalf("login: %d %s", rec.Code, rec.Body.String())
+	}
+
+	srv, err := NewGRPCServerWithAuth(config.ServerConfig{GRPC: &config.GRPCConfig{Listen: "127.0.0.1:0"}}, mgr, cronScheduler, authService, nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewGRPCServerWithAuth: %v", err)
+	}
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelShutdown()
+	client := dialGRPC(t, srv, grpcclient.Config{Token: login.Token.Value})
+	if _, err := client.Status(ctx, &grpcclient.StatusRequest{Selector: &grpcclient.Selector{Base: "nothing"}}); err != nil {
+		t.Fatalf("Status with HTTP token: %v", err)
+	}
+
+	// The gRPC server leaves the shared service open for the HTTP server.
+	_ = srv.Shutdown(shutdownCtx)
+	if rec := doReq(t, h, http.MethodPost, "/api/auth/login", map[string]string{"method": "basic", "username": "watcher", "password": "password123"}); rec.Code != http.StatusOK {
+		t.Fatalf("login after gRPC shutdown: %d %s", rec.Code, rec.Body.String())
+	}
+}
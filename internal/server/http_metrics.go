@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/loykin/provisr/pkg/metrics"
+)
+
+// unmatchedRoute labels requests that matched no route (404s, probes), so
+// arbitrary paths never become label values.
+const unmatchedRoute = "unmatched"
+
+// httpMetricsMiddleware records provisr_http_* metrics for every request,
+// labelled by route template. It is a no-op until metrics are registered.
+func httpMetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(metricsMethod(c.Request.Method), route, c.Writer.Status(), time.Since(start).Seconds())
+	}
+}
+
+// metricsMethod folds non-standard methods into one label value.
+func metricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/pkg/metrics"
+)
+
+func TestHTTPMetricsUseRouteTemplates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := metrics.Register(prometheus.DefaultRegisterer); err != nil {
+		t.Fatalf("register metrics: %v", err)
+	}
+	h := NewRouter(core.New(), "/api").Handler()
+
+	for _, path := range []string{"/api/processes/alpha/spec", "/api/processes/beta/spec", "/does/not/exist/1", "/does/not/exist/2"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	paths := map[string]float64{}
+	var sawHistogram bool
+	for _, mf := range mfs {
+		switch mf.GetName() {
+		case "provisr_http_requests_total":
+			for _, m := range mf.GetMetric() {
+				for _, l := range m.GetLabel() {
+					if l.GetName() == "path" {
+						paths[l.GetValue()] += m.GetCounter().GetValue()
+					}
+				}
+			}
+		case "provisr_http_request_duration_seconds":
+			sawHistogram = len(mf.GetMetric()) > 0
+		}
+	}
+	if paths["/api/processes/:name/spec"] < 2 {
+		t.Fatalf("expected /api/processes/:name/spec counted twice, got %v", paths)
+	}
+	if paths[unmatchedRoute] < 2 {
+		t.Fatalf("expected unmatched requests under %q, got %v", unmatchedRoute, paths)
+	}
+	for p := range paths {
+		if p == "/api/processes/alpha/spec" || p == "/does/not/exist/1" {
+			t.Fatalf("raw path %q used as label", p)
+		}
+	}
+	if !sawHistogram {
+		t.Fatal("expected provisr_http_request_duration_seconds samples")
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/loykin/provisr/internal/auth"
+	"github.com/loykin/provisr/internal/config"
+	"github.com/loykin/provisr/pkg/metrics"
+)
+
+// NewMetricsHandler serves the Prometheus metrics of the default registry
+// at metricsConfig.Path (default /metrics). With metricsConfig.Auth enabled,
+// scrapers must send its bearer token or, without one, credentials or a
+// token of an API user of authService with process read permission. Pass
+// the API server's auth service (see NewAuthService) so its tokens and
+// users are accepted here too.
+func NewMetricsHandler(metricsConfig config.MetricsConfig, authService *auth.AuthService) (http.Handler, error) {
+	path := metricsConfig.Path
+	if path == "" {
+		path = "/metrics"
+	}
+	var handler http.Handler = metrics.Handler()
+
+	if a := metricsConfig.Auth; a != nil && a.Enabled {
+		switch {
+		case a.BearerToken != "":
+			handler = requireBearerToken(a.BearerToken, handler)
+		case authService != nil:
+			mw := auth.NewMiddleware(authService, true)
+			handler = mw.HTTPAuth(mw.HTTPRequirePermission("process", "read")(handler))
+		default:
+			return nil, errors.New("metrics.auth needs a bearer_token or an enabled server.auth")
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+	return mux, nil
+}
+
+// requireBearerToken rejects requests without "Authorization: Bearer <token>".
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"authentication_failed","message":"Authentication required"}`))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr/internal/auth"
+	"github.com/loykin/provisr/internal/config"
+)
+
+func scrapeMetrics(t *testing.T, h http.Handler, path string, setAuth func(*http.Request)) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if setAuth != nil {
+		setAuth(req)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestMetricsHandlerBearerToken(t *testing.T) {
+	h, err := NewMetricsHandler(config.MetricsConfig{
+		Path: "/internal/metrics",
+		Auth: &config.MetricsAuthConfig{Enabled: true, BearerToken: "s3cret"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code := scrapeMetrics(t, h, "/internal/metrics", nil); code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated scrape: got %d, want 401", code)
+	}
+	wrong := func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }
+	if code := scrapeMetrics(t, h, "/internal/metrics", wrong); code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: got %d, want 401", code)
+	}
+	right := func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }
+	if code := scrapeMetrics(t, h, "/internal/metrics", right); code != http.StatusOK {
+		t.Fatalf("authenticated scrape: got %d, want 200", code)
+	}
+	if code := scrapeMetrics(t, h, "/metrics", right); code != http.StatusNotFound {
+		t.Fatalf("default path: got %d, want 404", code)
+	}
+}
+
+func TestMetricsHandlerAPIAuth(t *testing.T) {
+	// No jwt_secret and a memory store: only the very service that issued a
+	// token or holds a user can accept it, so the handler must share it.
+	authService, err := NewAuthService(&config.AuthConfig{Enabled: true, Store: config.AuthStoreConfig{Type: "memory"}})
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	defer func() { _ = authService.Close() }()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := authService.CreateUser(ctx, "scraper", "password123", "", []string{"viewer"}, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	login, err := authService.Authenticate(ctx, auth.LoginRequest{Method: auth.AuthMethodBasic, Username: "scraper", Password: "password123"})
+	if err != nil || login.Token == nil {
+		t.Fatalf("Authenticate: %+v, %v", login, err)
+	}
+
+	h, err := NewMetricsHandler(config.MetricsConfig{Auth: &config.MetricsAuthConfig{Enabled: true}}, authService)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code := scrapeMetrics(t, h, "/metrics", nil); code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated scrape: got %d, want 401", code)
+	}
+	basic := func(r *http.Request) { r.SetBasicAuth("scraper", "password123") }
+	if code := scrapeMetrics(t, h, "/metrics", basic); code != http.StatusOK {
+		t.Fatalf("authenticated scrape: got %d, want 200", code)
+	}
+	bearer := func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+login.Token.Value) }
+	if code := scrapeMetrics(t, h, "/metrics", bearer); code != http.StatusOK {
+		t.Fatalf("scrape with an API login token: got %d, want 200", code)
+	}
+
+	if _, err := NewMetricsHandler(config.MetricsConfig{Auth: &config.MetricsAuthConfig{Enabled: true}}, nil); err == nil {
+		t.Fatal("expected an error without a bearer token or API auth")
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/internal/config"
+	tlsutil "github.com/loykin/provisr/internal/tls"
+)
+
+// testCert issues a client (or, with isCA, CA) certificate for commonName,
+// signed by parent or self-signed when parent is nil. It returns the TLS
+// certificate plus the parsed certificate and key for signing further ones.
+func testCert(t *testing.T, commonName string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (tls.Certificate, *x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	if isCA {
+		tmpl.IsCA = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+		tmpl.ExtKeyUsage = nil
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, cert, key
+}
+
+func startMTLSServer(t *testing.T, clientAuth string, caCert *x509.Certificate) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "client_ca.crt")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}), 0o600); err != nil {
+		t.Fatalf("write CA: %v", err)
+	}
+	tlsConfig, err := tlsutil.SetupTLS(config.ServerConfig{TLS: &config.TLSConfig{
+		Enabled:      true,
+		Dir:          filepath.Join(dir, "server"),
+		AutoGenerate: true,
+		ClientAuth:   clientAuth,
+		ClientCAFile: caFile,
+	}})
+	if err != nil {
+		t.Fatalf("SetupTLS: %v", err)
+	}
+
+	authCfg := &config.AuthConfig{
+		Enabled: true,
+		Store:   config.AuthStoreConfig{Type: "sqlite", Path: filepath.Join(dir, "auth.db")},
+	}
+	r, err := newRouterFromConfig(core.New(), "/api", authCfg, "", nil, nil)
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	t.Cleanup(func() { _ = r.authService.Close() })
+	if _, err := r.authService.CreateUser(context.Background(), "ops", "password123", "", []string{"viewer"}, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	ts := httptest.NewUnstartedServer(r.Handler())
+	ts.TLS = tlsConfig
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// mtlsGet sends a GET to the server presenting cert, if any, and returns
+// the status code.
+func mtlsGet(ts *httptest.Server, path string, cert *tls.Certificate, basicAuth bool) (int, error) {
+	transport := ts.Client().Transport.(*http.Transport).Clone()
+	if cert != nil {
+		// Always present cert, even when the server didn't list its issuer
+		// as acceptable, so rejection happens on the server side.
+		transport.TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cert, nil
+		}
+	}
+	client := &http.Client{Transport: transport, Timeout: 5 * time.Second}
+	defer transport.CloseIdleConnections()
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+	if basicAuth {
+		req.SetBasicAuth("ops", "password123")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestMTLSRequireAndVerify(t *testing.T) {
+	_, ca, caKey := testCert(t, "provisr test CA", true, nil, nil)
+	ops, _, _ := testCert(t, "ops", false, ca, caKey)
+	stranger, _, _ := testCert(t, "stranger", false, ca, caKey)
+	selfSigned, _, _ := testCert(t, "ops", false, nil, nil)
+	ts := startMTLSServer(t, "require_and_verify", ca)
+
+	if code, err := mtlsGet(ts, "/api/status", &ops, false); err != nil || code != http.StatusOK {
+		t.Fatalf("CA-signed client cert: code=%d err=%v, want 200", code, err)
+	}
+	if code, err := mtlsGet(ts, "/api/status", &stranger, false); err != nil || code != http.StatusUnauthorized {
+		t.Fatalf("cert for unknown user: code=%d err=%v, want 401", code, err)
+	}
+	if _, err := mtlsGet(ts, "/api/status", &selfSigned, false); err == nil {
+		t.Fatal("self-signed client cert was accepted")
+	}
+	if _, err := mtlsGet(ts, "/api/status", nil, true); err == nil {
+		t.Fatal("request without a client cert was accepted")
+	}
+}
+
+func TestMTLSRequestFallsBackToHeaderAuth(t *testing.T) {
+	_, ca, caKey := testCert(t, "provisr test CA", true, nil, nil)
+	ops, _, _ := testCert(t, "ops", false, ca, caKey)
+	selfSigned, _, _ := testCert(t, "ops", false, nil, nil)
+	ts := startMTLSServer(t, "request", ca)
+
+	if code, err := mtlsGet(ts, "/api/status", &ops, false); err != nil || code != http.StatusOK {
+		t.Fatalf("CA-signed client cert: code=%d err=%v, want 200", code, err)
+	}
+	if code, err := mtlsGet(ts, "/api/status", nil, true); err != nil || code != http.StatusOK {
+		t.Fatalf("basic auth without cert: code=%d err=%v, want 200", code, err)
+	}
+	if code, err := mtlsGet(ts, "/api/status", nil, false); err != nil || code != http.StatusUnauthorized {
+		t.Fatalf("no credentials: code=%d err=%v, want 401", code, err)
+	}
+	if _, err := mtlsGet(ts, "/api/status", &selfSigned, false); err == nil {
+		t.Fatal("self-signed client cert was accepted")
+	}
+}
@@ -265,6 +265,65 @@ func TestProcessMetricsDisabled(t *testing.T) {
 	})
 }
 
+func TestProcessMetricsGroupMembership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mgr := core.New()
+	collector := metricsadapter.NewProcessMetricsCollector(metricsadapter.ProcessMetricsConfig{Enabled: true, Interval: time.Second})
+	require.NoError(t, mgr.SetProcessMetricsCollector(collector))
+	// web-server-* and web-canary share the "web-" prefix but are not
+	// instances of web.
+	for name, cpu := range map[string]float64{
+		"web-1": 10, "web-2": 20, "web-3": 60,
+		"web-server-1": 5, "web-server-2": 7, "web-canary": 100,
+		"db": 30,
+	} {
+		collector.AddToHistoryForTesting(name, core.ProcessMetrics{Name: name, CPUPercent: cpu, MemoryMB: cpu * 2, Timestamp: time.Now()})
+	}
+	mgr.SetInstanceGroups([]core.ManagerInstanceGroup{{
+		Name:    "frontend",
+		Members: []core.Spec{{Name: "web", Instances: 3}, {Name: "db"}},
+	}})
+
+	router := NewRouter(mgr, "/api")
+	get := func(query string) (int, map[string]interface{}) {
+		t.Helper()
+		rec := doReq(t, router.Handler(), http.MethodGet, "/api/metrics/group?"+query, "")
+		var result map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		return rec.Code, result
+	}
+
+	code, result := get("base=web")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(3), result["process_count"])
+	assert.Equal(t, float64(90), result["total_cpu"])
+	assert.Equal(t, float64(10), result["min_cpu"])
+	assert.Equal(t, float64(60), result["max_cpu"])
+	assert.Equal(t, float64(20), result["p50_cpu"])
+	assert.Equal(t, float64(60), result["p90_cpu"])
+	assert.Equal(t, float64(120), result["max_memory"])
+	processes := result["processes"].(map[string]interface{})
+	assert.NotContains(t, processes, "web-server-1")
+	assert.NotContains(t, processes, "web-canary")
+
+	code, result = get("base=web-server")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(2), result["process_count"])
+
+	code, result = get("group=frontend")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "frontend", result["group"])
+	assert.Equal(t, float64(4), result["process_count"])
+	assert.Equal(t, float64(120), result["total_cpu"])
+	assert.Equal(t, float64(20), result["p50_cpu"])
+
+	code, _ = get("group=missing")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = get("base=web&group=frontend")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
 func TestAPIEndpointsProcessMetrics(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
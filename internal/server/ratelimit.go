@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/loykin/provisr/internal/auth"
+	"github.com/loykin/provisr/internal/config"
+)
+
+// rateLimiterIdleTTL is how long an unused bucket is kept; a client idle
+// this long has refilled its bucket anyway.
+const rateLimiterIdleTTL = 10 * time.Minute
+
+// RateLimiter keeps one token bucket per client key. Its middleware answers
+// 429 Too Many Requests with a Retry-After header once a bucket is empty.
+type RateLimiter struct {
+	limit rate.Limit
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*rateBucket
+	lastSweep time.Time
+}
+
+type rateBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter creates a limiter for cfg.RequestsPerSecond and cfg.Burst;
+// a zero Burst allows one second's worth of requests.
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(cfg.RequestsPerSecond))
+	}
+	return &RateLimiter{
+		limit:   rate.Limit(cfg.RequestsPerSecond),
+		burst:   max(burst, 1),
+		now:     time.Now,
+		buckets: make(map[string]*rateBucket),
+	}
+}
+
+// Middleware limits requests by remote address.
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return l.middleware(remoteKey)
+}
+
+// remoteKey is the bucket key of the peer that sent the request. It is
+// taken from the connection, not gin's ClientIP: that trusts
+// X-Forwarded-For from any peer, which would hand every client a fresh
+// bucket per header value.
+func remoteKey(c *gin.Context) string {
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		host = c.Request.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// ClientMiddleware limits requests by authenticated username, falling back
+// to the remote address. It must run after the auth middleware.
+func (l *RateLimiter) ClientMiddleware() gin.HandlerFunc {
+	return l.middleware(func(c *gin.Context) string {
+		if v, ok := c.Get(string(auth.ResultKey)); ok {
+			if result, ok := v.(*auth.AuthResult); ok && result.Success && result.Username != "" {
+				return "user:" + result.Username
+			}
+		}
+		return remoteKey(c)
+	})
+}
+
+// FailureMiddleware limits failed authentication by remote address: each
+// response of 401 Unauthorized takes a token, and an address whose bucket
+// is empty is turned away before it can try again. Run in front of the auth
+// middleware and the login endpoint, it stops password guessing without
+// throttling the clients that authenticate from a shared address.
+func (l *RateLimiter) FailureMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := remoteKey(c)
+		if wait, ok := l.peek(key); !ok {
+			tooManyRequests(c, wait)
+			return
+		}
+		c.Next()
+		if c.Writer.Status() == http.StatusUnauthorized {
+			l.allow(key)
+		}
+	}
+}
+
+// GRPCFailureInterceptor is the gRPC counterpart of FailureMiddleware:
+// each call that ends Unauthenticated takes a token from the peer
+// address's bucket, and a peer whose bucket is empty gets
+// ResourceExhausted. It must run before the auth interceptor.
+func (l *RateLimiter) GRPCFailureInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		key := peerKey(ctx)
+		if wait, ok := l.peek(key); !ok {
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded; retry in %ds", int(math.Ceil(wait.Seconds())))
+		}
+		resp, err := handler(ctx, req)
+		if status.Code(err) == codes.Unauthenticated {
+			l.allow(key)
+		}
+		return resp, err
+	}
+}
+
+// peerKey is the bucket key of the peer of a gRPC call.
+func peerKey(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "ip:"
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return "ip:" + host
+	}
+	return "ip:" + addr
+}
+
+func (l *RateLimiter) middleware(key func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if wait, ok := l.allow(key(c)); !ok {
+			tooManyRequests(c, wait)
+			return
+		}
+		c.Next()
+	}
+}
+
+func tooManyRequests(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	writeJSON(c, http.StatusTooManyRequests, errorResp{Error: "rate limit exceeded"})
+	c.Abort()
+}
+
+// allow takes a token from key's bucket. When none is available it returns
+// false and how long until one will be.
+func (l *RateLimiter) allow(key string) (time.Duration, bool) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := l.bucketLocked(key, now).ReserveN(now, 1)
+	if wait := r.DelayFrom(now); wait > 0 {
+		r.CancelAt(now)
+		return wait, false
+	}
+	return 0, true
+}
+
+// peek reports whether key's bucket holds a token without taking it, and if
+// not, how long until it will.
+func (l *RateLimiter) peek(key string) (time.Duration, bool) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tokens := l.bucketLocked(key, now).TokensAt(now)
+	if tokens >= 1 {
+		return 0, true
+	}
+	return time.Duration((1 - tokens) / float64(l.limit) * float64(time.Second)), false
+}
+
+// bucketLocked returns key's bucket, creating it if needed, and drops
+// buckets that have been idle for rateLimiterIdleTTL.
+func (l *RateLimiter) bucketLocked(key string, now time.Time) *rate.Limiter {
+	if now.Sub(l.lastSweep) > time.Minute {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > rateLimiterIdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &rateBucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b.limiter
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/internal/config"
+)
+
+func TestRateLimitRejectsBurstBeyondLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(core.New(), "/api")
+	r.SetRateLimit(&config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.5, Burst: 3})
+	h := r.Handler()
+
+	get := func(remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	for i := 0; i < 3; i++ {
+		if rec := get("10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d within burst: status %d", i+1, rec.Code)
+		}
+	}
+	rec := get("10.0.0.1:1234")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request beyond burst: status %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("Retry-After = %q, want 2", got)
+	}
+	// Another client has its own bucket.
+	if rec := get("10.0.0.2:1234"); rec.Code != http.StatusOK {
+		t.Fatalf("other client: status %d", rec.Code)
+	}
+}
+
+func TestRateLimitIgnoresForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(core.New(), "/api")
+	r.SetRateLimit(&config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.5, Burst: 3})
+	h := r.Handler()
+
+	limited := 0
+	for i := 0; i < 10; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("192.0.2.%d", i))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code == http.StatusTooManyRequests {
+			limited++
+		}
+	}
+	if limited != 7 {
+		t.Fatalf("%d of 10 requests limited with a rotating X-Forwarded-For, want 7", limited)
+	}
+}
+
+func TestClientRateLimitThrottlesFailedLogins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authCfg := &config.AuthConfig{
+		Enabled: true,
+		Store:   config.AuthStoreConfig{Type: "sqlite", Path: filepath.Join(t.TempDir(), "auth.db")},
+	}
+	r, err := newRouterFromConfig(core.New(), "/api", authCfg, "", nil, nil)
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	t.Cleanup(r.closeAuthAndAudit)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := r.authService.CreateUser(ctx, "ops", "password123", "", []string{"operator"}, nil); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	r.SetRateLimit(&config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.5, Burst: 3, Key: "client"})
+	h := r.Handler()
+
+	forwarded := 0
+	serve := func(req *http.Request, remote string) int {
+		req.RemoteAddr = remote
+		// A forged X-Forwarded-For per request must not reset the bucket.
+		forwarded++
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("192.0.2.%d", forwarded))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	login := func(remote, password string) int {
+		body := strings.NewReader(`{"method":"basic","username":"ops","password":"` + password + `"}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", body)
+		req.Header.Set("Content-Type", "application/json")
+		return serve(req, remote)
+	}
+	status := func(remote, password string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+		req.SetBasicAuth("ops", password)
+		return serve(req, remote)
+	}
+
+	// Successful requests from one address do not use up its failures.
+	for i := 0; i < 3; i++ {
+		if code := status("10.0.0.1:1234", "password123"); code != http.StatusOK {
+			t.Fatalf("status %d: %d", i+1, code)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if code := login("10.0.0.1:1234", "wrong"); code != http.StatusUnauthorized {
+			t.Fatalf("bad login %d: %d", i+1, code)
+		}
+	}
+	if code := status("10.0.0.1:1234", "wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("bad credentials: %d", code)
+	}
+	// The address is out of failures: even the right password waits.
+	if code := login("10.0.0.1:1234", "password123"); code != http.StatusTooManyRequests {
+		t.Fatalf("login after failures: %d, want 429", code)
+	}
+	if code := status("10.0.0.1:1234", "wrong"); code != http.StatusTooManyRequests {
+		t.Fatalf("credentials after failures: %d, want 429", code)
+	}
+	if code := login("10.0.0.2:1234", "password123"); code != http.StatusOK {
+		t.Fatalf("login from another address: %d", code)
+	}
+}
+
+func TestRateLimiterRefillsAndForgetsIdleClients(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := NewRateLimiter(config.RateLimitConfig{RequestsPerSecond: 1})
+	l.now = func() time.Time { return now }
+
+	if _, ok := l.allow("a"); !ok {
+		t.Fatal("first request rejected")
+	}
+	if wait, ok := l.allow("a"); ok || wait <= 0 || wait > time.Second {
+		t.Fatalf("second request: ok=%v wait=%v", ok, wait)
+	}
+	now = now.Add(time.Second)
+	if _, ok := l.allow("a"); !ok {
+		t.Fatal("request after refill rejected")
+	}
+
+	now = now.Add(rateLimiterIdleTTL + 2*time.Minute)
+	l.allow("b")
+	if _, ok := l.buckets["a"]; ok {
+		t.Fatal("idle bucket was not evicted")
+	}
+}
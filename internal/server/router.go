@@ -1,24 +1,34 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/loykin/provisr/core"
 	corehistory "github.com/loykin/provisr/core/history"
+	"github.com/loykin/provisr/internal/audit"
 	"github.com/loykin/provisr/internal/auth"
 	"github.com/loykin/provisr/internal/config"
 	tlsutil "github.com/loykin/provisr/internal/tls"
 	"github.com/loykin/provisr/internal/ui"
+	"github.com/loykin/provisr/internal/unixsock"
+	"github.com/loykin/provisr/internal/version"
 	apiwire "github.com/loykin/provisr/pkg/api"
 	templatepkg "github.com/loykin/provisr/pkg/template"
 )
@@ -26,7 +36,7 @@ import (
 // Router provides embeddable HTTP handlers for managing processes.
 // Endpoints:
 //   POST {basePath}/start        body: Spec JSON
-//   POST {basePath}/stop         query: name=...&wait=1s (wait optional)
+//   POST {basePath}/stop         query: name=...&wait=1s (wait optional), or pid=...
 //   GET  {basePath}/status       query: name=... (instance) OR base=... (list)
 // If both name and base are empty, returns 400.
 // If base provided without name, returns list of statuses for base.
@@ -37,10 +47,26 @@ type Router struct {
 	mgr           *core.Manager
 	basePath      string
 	authService   *auth.AuthService
+	ownsAuth      bool // authService was opened for this Router and is closed with it
 	historyReader corehistory.Reader
 	programsDir   string
 	cronScheduler *core.CronScheduler
 	jobManager    *core.JobManager
+	cors          *config.CORSConfig
+	accessLog     *config.AccessLogConfig
+	accessLogger  *slog.Logger
+	rateLimit     *config.RateLimitConfig
+	audit         *audit.Logger
+	ownsAudit     bool // audit was opened for this Router and is closed with it
+
+	// streamsDone is closed when the server shuts down, ending open
+	// GET /events?follow=true and GET /debug/transitions streams.
+	streamsDone      chan struct{}
+	closeStreamsOnce sync.Once
+	// transitionStreams counts open GET /debug/transitions streams.
+	transitionStreams atomic.Int32
+	// shuttingDown fails GET /readyz once the server starts shutting down.
+	shuttingDown atomic.Bool
 }
 
 // APIEndpoints provides individual access to API handlers for custom registration
@@ -53,9 +79,28 @@ type APIEndpoints struct {
 // Example basePath: "/abc" results in /abc/start, /abc/stop, /abc/status.
 func NewRouter(mgr *core.Manager, basePath string) *Router {
 	bp := sanitizeBase(basePath)
-	return &Router{mgr: mgr, basePath: bp, jobManager: core.NewJobManager(mgr)}
+	return &Router{mgr: mgr, basePath: bp, jobManager: core.NewJobManager(mgr), streamsDone: make(chan struct{})}
 }
 
+// SetCORS enables CORS for the handler returned by Handler. A nil or
+// disabled config leaves CORS off.
+func (r *Router) SetCORS(cfg *config.CORSConfig) { r.cors = cfg }
+
+// SetAccessLog enables per-request access logging to logger, or to
+// slog.Default() when logger is nil. A nil or disabled config leaves it off.
+func (r *Router) SetAccessLog(cfg *config.AccessLogConfig, logger *slog.Logger) {
+	r.accessLog = cfg
+	r.accessLogger = logger
+}
+
+// SetAuditLogger records mutating requests and auth events to logger; nil
+// turns auditing off.
+func (r *Router) SetAuditLogger(logger *audit.Logger) { r.audit = logger }
+
+// SetRateLimit enables per-client rate limiting. A nil or disabled config
+// leaves it off.
+func (r *Router) SetRateLimit(cfg *config.RateLimitConfig) { r.rateLimit = cfg }
+
 // SetHistoryReader attaches a backend-neutral history reader to the Router.
 // Adapter construction and lifetime belong to the composition root.
 func (r *Router) SetHistoryReader(reader corehistory.Reader) { r.historyReader = reader }
@@ -63,7 +108,21 @@ func (r *Router) SetHistoryReader(reader corehistory.Reader) { r.historyReader =
 // newRouterFromConfig constructs a Router and wires up an AuthService
 // (if authCfg is present and enabled) and a history reader (if historyCfg
 // enables in-store history) so their endpoints are mounted by Handler().
+// The Router owns the AuthService and closes it on shutdown.
 func newRouterFromConfig(mgr *core.Manager, basePath string, authCfg *config.AuthConfig, programsDir string, cronScheduler *core.CronScheduler, historyReader corehistory.Reader) (*Router, error) {
+	authService, err := NewAuthService(authCfg)
+	if err != nil {
+		return nil, err
+	}
+	r := newRouterWithAuth(mgr, basePath, authService, programsDir, cronScheduler, historyReader)
+	r.ownsAuth = true
+	return r, nil
+}
+
+// newRouterWithAuth constructs a Router that authenticates with
+// authService, which may be nil for no auth. The caller keeps ownership of
+// authService.
+func newRouterWithAuth(mgr *core.Manager, basePath string, authService *auth.AuthService, programsDir string, cronScheduler *core.CronScheduler, historyReader corehistory.Reader) *Router {
 	r := NewRouter(mgr, basePath)
 	r.programsDir = programsDir
 	r.cronScheduler = cronScheduler
@@ -71,23 +130,36 @@ func newRouterFromConfig(mgr *core.Manager, basePath string, authCfg *config.Aut
 		r.jobManager = cronScheduler.JobManager()
 	}
 	r.historyReader = historyReader
+	r.authService = authService
+	return r
+}
 
+// NewAuthService opens the auth service of the [server.auth] section, or
+// returns nil when authCfg is absent or disabled. serve opens one and
+// shares it between the HTTP, gRPC and metrics servers, so they accept
+// each other's tokens and see the same users; the caller closes it.
+func NewAuthService(authCfg *config.AuthConfig) (*auth.AuthService, error) {
 	if authCfg == nil || !authCfg.Enabled {
-		return r, nil
+		return nil, nil
+	}
+	authService, err := auth.NewAuthService(authServiceConfig(authCfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth service: %w", err)
 	}
+	return authService, nil
+}
 
-	authService, err := auth.NewAuthService(auth.AuthConfig{
+// authServiceConfig is the auth service configuration of the [server.auth]
+// section.
+func authServiceConfig(authCfg *config.AuthConfig) auth.AuthConfig {
+	return auth.AuthConfig{
 		Store:      authCfg.Store,
 		JWTSecret:  authCfg.JWTSecret,
 		TokenTTL:   authCfg.TokenTTL,
 		BcryptCost: authCfg.BcryptCost,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create auth service: %w", err)
+		OIDC:       authCfg.OIDC,
+		LDAP:       authCfg.LDAP,
 	}
-
-	r.authService = authService
-	return r, nil
 }
 
 // NewAPIEndpoints constructs APIEndpoints for individual handler registration.
@@ -104,7 +176,31 @@ func noopMiddleware(c *gin.Context) { c.Next() }
 // Handler returns an http.Handler powered by gin that can be mounted in any server/mux.
 func (r *Router) Handler() http.Handler {
 	g := gin.New()
-	g.Use(gin.Recovery())
+	// Outermost, so requests that panic are still logged with their 500.
+	if r.accessLog != nil && r.accessLog.Enabled {
+		g.Use(accessLogMiddleware(*r.accessLog, r.accessLogger))
+	}
+	if r.audit != nil {
+		g.Use(auditMiddleware(r.audit))
+	}
+	// Outside Recovery so panics are counted as the 500 they become.
+	g.Use(httpMetricsMiddleware(), gin.Recovery(), tracingMiddleware(), versionHeaderMiddleware())
+	if r.cors != nil && r.cors.Enabled {
+		g.Use(corsMiddleware(*r.cors))
+	}
+	// limit runs after auth on each route when clients are keyed by user,
+	// so failed logins and credentials are limited by address ahead of auth;
+	// keyed by address, the limiter covers every route instead.
+	limit := gin.HandlerFunc(noopMiddleware)
+	if r.rateLimit != nil && r.rateLimit.Enabled {
+		limiter := NewRateLimiter(*r.rateLimit)
+		if r.rateLimit.Key == "client" {
+			limit = limiter.ClientMiddleware()
+			g.Use(NewRateLimiter(*r.rateLimit).FailureMiddleware())
+		} else {
+			g.Use(limiter.Middleware())
+		}
+	}
 	group := g.Group(r.basePath)
 
 	authGin := gin.HandlerFunc(noopMiddleware)
@@ -119,29 +215,41 @@ func (r *Router) Handler() http.Handler {
 		settingsReadPerm = mw.GinRequirePermission("settings", "read")
 	}
 
-	group.POST("/register", authGin, writePerm, r.handleRegister)
-	group.POST("/update", authGin, writePerm, r.handleUpdate)
-	group.POST("/start", authGin, writePerm, r.handleStart)
-	group.POST("/stop", authGin, writePerm, r.handleStop)
-	group.POST("/unregister", authGin, writePerm, r.handleUnregister)
-	group.GET("/status", authGin, readPerm, r.handleStatus)
-	group.GET("/groups", authGin, readPerm, r.handleGroups)
-	group.GET("/group/status", authGin, readPerm, r.handleGroupStatus)
-	group.POST("/group/start", authGin, writePerm, r.handleGroupStart)
-	group.POST("/group/stop", authGin, writePerm, r.handleGroupStop)
-	group.GET("/debug/processes", authGin, readPerm, r.handleDebugProcesses)
-	group.GET("/metrics", authGin, readPerm, r.handleProcessMetrics)
-	group.GET("/metrics/history", authGin, readPerm, r.handleProcessMetricsHistory)
-	group.GET("/metrics/group", authGin, readPerm, r.handleProcessMetricsGroup)
-	group.GET("/processes/:name/logs", authGin, readPerm, r.handleProcessLogs)
-	group.GET("/processes/:name/spec", authGin, readPerm, r.handleGetSpec)
-	group.GET("/settings/status", authGin, settingsReadPerm, r.handleRuntimeStatus)
-	group.GET("/templates", authGin, readPerm, r.handleTemplateTypes)
-	group.GET("/templates/:kind", authGin, readPerm, r.handleTemplatePreview)
+	group.POST("/register", authGin, limit, writePerm, r.handleRegister)
+	group.POST("/update", authGin, limit, writePerm, r.handleUpdate)
+	group.POST("/start", authGin, limit, writePerm, r.handleStart)
+	group.POST("/stop", authGin, limit, writePerm, r.handleStop)
+	group.POST("/restart", authGin, limit, writePerm, r.handleRestart)
+	group.POST("/signal", authGin, limit, writePerm, r.handleSignal)
+	group.POST("/scale", authGin, limit, writePerm, r.handleScale)
+	group.POST("/unregister", authGin, limit, writePerm, r.handleUnregister)
+	group.POST("/batch/start", authGin, limit, writePerm, r.handleBatchStart)
+	group.POST("/batch/stop", authGin, limit, writePerm, r.handleBatchStop)
+	group.GET("/status", authGin, limit, readPerm, r.handleStatus)
+	group.GET("/groups", authGin, limit, readPerm, r.handleGroups)
+	group.GET("/group/status", authGin, limit, readPerm, r.handleGroupStatus)
+	group.POST("/group/start", authGin, limit, writePerm, r.handleGroupStart)
+	group.POST("/group/stop", authGin, limit, writePerm, r.handleGroupStop)
+	group.POST("/group/rolling-restart", authGin, limit, writePerm, r.handleGroupRollingRestart)
+	group.GET("/debug/processes", authGin, limit, readPerm, r.handleDebugProcesses)
+	group.GET("/debug/transitions", authGin, limit, readPerm, r.handleDebugTransitions)
+	group.GET("/metrics", authGin, limit, readPerm, r.handleProcessMetrics)
+	group.GET("/metrics/history", authGin, limit, readPerm, r.handleProcessMetricsHistory)
+	group.GET("/metrics/group", authGin, limit, readPerm, r.handleProcessMetricsGroup)
+	group.GET("/processes/:name/logs", authGin, limit, readPerm, r.handleProcessLogs)
+	group.GET("/processes/:name/spec", authGin, limit, readPerm, r.handleGetSpec)
+	group.GET("/settings/status", authGin, limit, settingsReadPerm, r.handleRuntimeStatus)
+	group.GET("/templates", authGin, limit, readPerm, r.handleTemplateTypes)
+	group.GET("/templates/:kind", authGin, limit, readPerm, r.handleTemplatePreview)
+	group.GET("/events", authGin, limit, readPerm, r.handleEvents)
+	group.GET("/version", authGin, limit, r.handleVersion)
+	// Probes for load balancers and Kubernetes, which send no credentials.
+	group.GET("/healthz", r.handleHealthz)
+	group.GET("/readyz", r.handleReadyz)
 
 	// Add history endpoint if a history reader is available
 	if r.historyReader != nil {
-		group.GET("/history", authGin, readPerm, r.handleHistory)
+		group.GET("/history", authGin, limit, readPerm, r.handleHistory)
 	}
 
 	jobReadPerm := gin.HandlerFunc(noopMiddleware)
@@ -152,25 +260,33 @@ func (r *Router) Handler() http.Handler {
 		jobWritePerm = mw.GinRequirePermission("job", "write")
 	}
 
+	// Export and import cover cron jobs too, so they need both permissions.
+	group.GET("/export", authGin, limit, readPerm, jobReadPerm, r.handleExport)
+	group.POST("/import", authGin, limit, writePerm, jobWritePerm, r.handleImport)
+
 	if r.jobManager != nil {
-		group.GET("/jobs", authGin, jobReadPerm, r.handleListJobs)
-		group.POST("/jobs", authGin, jobWritePerm, r.handleCreateJob)
-		group.GET("/jobs/:name", authGin, jobReadPerm, r.handleGetJob)
-		group.POST("/jobs/:name", authGin, jobWritePerm, r.handleUpdateJob)
-		group.DELETE("/jobs/:name", authGin, jobWritePerm, r.handleDeleteJob)
+		group.GET("/jobs", authGin, limit, jobReadPerm, r.handleListJobs)
+		group.POST("/jobs", authGin, limit, jobWritePerm, r.handleCreateJob)
+		group.GET("/jobs/:name", authGin, limit, jobReadPerm, r.handleGetJob)
+		group.POST("/jobs/:name", authGin, limit, jobWritePerm, r.handleUpdateJob)
+		group.DELETE("/jobs/:name", authGin, limit, jobWritePerm, r.handleDeleteJob)
+		group.GET("/jobs/:name/instances", authGin, limit, jobReadPerm, r.handleJobInstances)
+		group.GET("/jobs/:name/instances/:id/logs", authGin, limit, jobReadPerm, r.handleJobInstanceLogs)
+		group.POST("/jobs/:name/suspend", authGin, limit, jobWritePerm, r.handleSuspendJob)
+		group.POST("/jobs/:name/resume", authGin, limit, jobWritePerm, r.handleResumeJob)
 	}
 
 	// Add cronjob endpoints if a scheduler is available.
 	if r.cronScheduler != nil {
-		group.GET("/cronjobs", authGin, jobReadPerm, r.handleListCronJobs)
-		group.POST("/cronjobs", authGin, jobWritePerm, r.handleCreateCronJob)
-		group.GET("/cronjobs/:name", authGin, jobReadPerm, r.handleGetCronJob)
-		group.POST("/cronjobs/:name", authGin, jobWritePerm, r.handleUpdateCronJob)
-		group.DELETE("/cronjobs/:name", authGin, jobWritePerm, r.handleDeleteCronJob)
-		group.GET("/cronjobs/:name/history", authGin, jobReadPerm, r.handleCronJobHistory)
-		group.POST("/cronjobs/:name/suspend", authGin, jobWritePerm, r.handleSuspendCronJob)
-		group.POST("/cronjobs/:name/resume", authGin, jobWritePerm, r.handleResumeCronJob)
-		group.POST("/cronjobs/:name/trigger", authGin, jobWritePerm, r.handleTriggerCronJob)
+		group.GET("/cronjobs", authGin, limit, jobReadPerm, r.handleListCronJobs)
+		group.POST("/cronjobs", authGin, limit, jobWritePerm, r.handleCreateCronJob)
+		group.GET("/cronjobs/:name", authGin, limit, jobReadPerm, r.handleGetCronJob)
+		group.POST("/cronjobs/:name", authGin, limit, jobWritePerm, r.handleUpdateCronJob)
+		group.DELETE("/cronjobs/:name", authGin, limit, jobWritePerm, r.handleDeleteCronJob)
+		group.GET("/cronjobs/:name/history", authGin, limit, jobReadPerm, r.handleCronJobHistory)
+		group.POST("/cronjobs/:name/suspend", authGin, limit, jobWritePerm, r.handleSuspendCronJob)
+		group.POST("/cronjobs/:name/resume", authGin, limit, jobWritePerm, r.handleResumeCronJob)
+		group.POST("/cronjobs/:name/trigger", authGin, limit, jobWritePerm, r.handleTriggerCronJob)
 	}
 
 	// Unauthenticated, always-mounted: lets the UI tell whether it should
@@ -221,6 +337,28 @@ func NewServerWithHistoryReader(serverConfig config.ServerConfig, mgr *core.Mana
 	if err != nil {
 		return nil, err
 	}
+	if err := r.setAuditFromConfig(serverConfig.Audit); err != nil {
+		r.closeAuthAndAudit()
+		return nil, err
+	}
+	return startServer(serverConfig, r)
+}
+
+// NewServerWithAuth is NewServerWithHistoryReader authenticating with
+// authService (see NewAuthService) and auditing to auditLogger (see
+// NewAuditLogger) instead of opening its own from serverConfig.Auth and
+// serverConfig.Audit. The caller closes both after the server.
+func NewServerWithAuth(serverConfig config.ServerConfig, mgr *core.Manager, cronScheduler *core.CronScheduler, historyReader corehistory.Reader, authService *auth.AuthService, auditLogger *audit.Logger, programsDirectory string) (*http.Server, error) {
+	r := newRouterWithAuth(mgr, serverConfig.BasePath, authService, programsDirectory, cronScheduler, historyReader)
+	r.SetAuditLogger(auditLogger)
+	return startServer(serverConfig, r)
+}
+
+// startServer serves r over HTTP on serverConfig.Listen.
+func startServer(serverConfig config.ServerConfig, r *Router) (*http.Server, error) {
+	r.SetCORS(serverConfig.CORS)
+	r.SetRateLimit(serverConfig.RateLimit)
+	r.SetAccessLog(serverConfig.AccessLog, nil)
 	server := &http.Server{
 		Addr:              serverConfig.Listen,
 		Handler:           r.Handler(),
@@ -229,14 +367,19 @@ func NewServerWithHistoryReader(serverConfig config.ServerConfig, mgr *core.Mana
 		WriteTimeout:      15 * time.Second,
 		IdleTimeout:       60 * time.Second,
 	}
-	if r.authService != nil {
-		server.RegisterOnShutdown(func() { _ = r.authService.Close() })
+	listener, err := listenServer(serverConfig)
+	if err != nil {
+		r.closeAuthAndAudit()
+		return nil, err
 	}
+	server.RegisterOnShutdown(r.markShuttingDown)
+	server.RegisterOnShutdown(r.closeStreams)
+	server.RegisterOnShutdown(r.closeAuthAndAudit)
 
 	// Start the server in a goroutine and handle potential errors
 	serverErrCh := make(chan error, 1)
 	go func() {
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			serverErrCh <- err
 		}
 		close(serverErrCh)
@@ -262,10 +405,31 @@ func NewTLSServerWithHistoryReader(serverConfig config.ServerConfig, mgr *core.M
 	if err != nil {
 		return nil, err
 	}
+	if err := r.setAuditFromConfig(serverConfig.Audit); err != nil {
+		r.closeAuthAndAudit()
+		return nil, err
+	}
+	return startTLSServer(serverConfig, r)
+}
+
+// NewTLSServerWithAuth is the TLS equivalent of NewServerWithAuth.
+func NewTLSServerWithAuth(serverConfig config.ServerConfig, mgr *core.Manager, cronScheduler *core.CronScheduler, historyReader corehistory.Reader, authService *auth.AuthService, auditLogger *audit.Logger, programsDirectory string) (*http.Server, error) {
+	r := newRouterWithAuth(mgr, serverConfig.BasePath, authService, programsDirectory, cronScheduler, historyReader)
+	r.SetAuditLogger(auditLogger)
+	return startTLSServer(serverConfig, r)
+}
+
+// startTLSServer serves r over HTTPS (or HTTP without a TLS config) on
+// serverConfig.Listen.
+func startTLSServer(serverConfig config.ServerConfig, r *Router) (*http.Server, error) {
+	r.SetCORS(serverConfig.CORS)
+	r.SetRateLimit(serverConfig.RateLimit)
+	r.SetAccessLog(serverConfig.AccessLog, nil)
 
 	// Setup TLS configuration
-	tlsConfig, err := tlsutil.SetupTLS(serverConfig)
+	tlsConfig, acmeManager, err := tlsutil.SetupTLSWithACME(serverConfig)
 	if err != nil {
+		r.closeAuthAndAudit()
 		return nil, fmt.Errorf("failed to setup TLS: %w", err)
 	}
 
@@ -278,9 +442,23 @@ func NewTLSServerWithHistoryReader(serverConfig config.ServerConfig, mgr *core.M
 		WriteTimeout:      15 * time.Second,
 		IdleTimeout:       60 * time.Second,
 	}
-	if r.authService != nil {
-		server.RegisterOnShutdown(func() { _ = r.authService.Close() })
+	listener, err := listenServer(serverConfig)
+	if err != nil {
+		r.closeAuthAndAudit()
+		return nil, err
 	}
+	if acmeManager != nil {
+		challenge, err := startACMEChallengeServer(tlsutil.ACMEHTTPListen(serverConfig.TLS.ACME), acmeManager)
+		if err != nil {
+			_ = listener.Close()
+			r.closeAuthAndAudit()
+			return nil, err
+		}
+		server.RegisterOnShutdown(func() { _ = challenge.Close() })
+	}
+	server.RegisterOnShutdown(r.markShuttingDown)
+	server.RegisterOnShutdown(r.closeStreams)
+	server.RegisterOnShutdown(r.closeAuthAndAudit)
 
 	// Start the server in a goroutine and handle potential errors
 	serverErrCh := make(chan error, 1)
@@ -288,10 +466,10 @@ func NewTLSServerWithHistoryReader(serverConfig config.ServerConfig, mgr *core.M
 		var err error
 		if tlsConfig != nil {
 			// Use HTTPS
-			err = server.ListenAndServeTLS("", "")
+			err = server.ServeTLS(listener, "", "")
 		} else {
 			// Use HTTP
-			err = server.ListenAndServe()
+			err = server.Serve(listener)
 		}
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			serverErrCh <- err
@@ -312,6 +490,16 @@ func NewTLSServerWithHistoryReader(serverConfig config.ServerConfig, mgr *core.M
 	return server, nil
 }
 
+// listenServer binds serverConfig.Listen, which may be a TCP address or a
+// unix:///path socket created with serverConfig.SocketMode.
+func listenServer(serverConfig config.ServerConfig) (net.Listener, error) {
+	mode, err := serverConfig.SocketFileMode()
+	if err != nil {
+		return nil, err
+	}
+	return unixsock.Listen(serverConfig.Listen, mode)
+}
+
 // --- APIEndpoints Individual Handler Registration ---
 
 // RegisterHandler returns the gin.HandlerFunc for process registration
@@ -339,6 +527,24 @@ func (e *APIEndpoints) StopHandler() gin.HandlerFunc {
 	return r.handleStop
 }
 
+// RestartHandler returns the gin.HandlerFunc for restarting processes
+func (e *APIEndpoints) RestartHandler() gin.HandlerFunc {
+	r := &Router{mgr: e.mgr, basePath: e.basePath}
+	return r.handleRestart
+}
+
+// SignalHandler returns the gin.HandlerFunc for signalling processes
+func (e *APIEndpoints) SignalHandler() gin.HandlerFunc {
+	r := &Router{mgr: e.mgr, basePath: e.basePath}
+	return r.handleSignal
+}
+
+// ScaleHandler returns the gin.HandlerFunc for scaling process instances
+func (e *APIEndpoints) ScaleHandler() gin.HandlerFunc {
+	r := &Router{mgr: e.mgr, basePath: e.basePath}
+	return r.handleScale
+}
+
 // StatusHandler returns the gin.HandlerFunc for getting process status
 func (e *APIEndpoints) StatusHandler() gin.HandlerFunc {
 	r := &Router{mgr: e.mgr, basePath: e.basePath}
@@ -363,6 +569,24 @@ func (e *APIEndpoints) GroupStopHandler() gin.HandlerFunc {
 	return r.handleGroupStop
 }
 
+// GroupRollingRestartHandler returns the gin.HandlerFunc for rolling restarts of process groups
+func (e *APIEndpoints) GroupRollingRestartHandler() gin.HandlerFunc {
+	r := &Router{mgr: e.mgr, basePath: e.basePath}
+	return r.handleGroupRollingRestart
+}
+
+// BatchStartHandler returns the gin.HandlerFunc for starting a list of processes
+func (e *APIEndpoints) BatchStartHandler() gin.HandlerFunc {
+	r := &Router{mgr: e.mgr, basePath: e.basePath}
+	return r.handleBatchStart
+}
+
+// BatchStopHandler returns the gin.HandlerFunc for stopping a list of processes
+func (e *APIEndpoints) BatchStopHandler() gin.HandlerFunc {
+	r := &Router{mgr: e.mgr, basePath: e.basePath}
+	return r.handleBatchStop
+}
+
 // GroupStatusHandler returns the gin.HandlerFunc for getting group status
 func (e *APIEndpoints) GroupStatusHandler() gin.HandlerFunc {
 	r := &Router{mgr: e.mgr, basePath: e.basePath}
@@ -405,6 +629,13 @@ func (e *APIEndpoints) DebugProcessesHandler() gin.HandlerFunc {
 	return r.handleDebugProcesses
 }
 
+// DebugTransitionsHandler returns the gin.HandlerFunc streaming process
+// state transitions
+func (e *APIEndpoints) DebugTransitionsHandler() gin.HandlerFunc {
+	r := &Router{mgr: e.mgr, basePath: e.basePath}
+	return r.handleDebugTransitions
+}
+
 // ProcessMetricsHandler returns the gin.HandlerFunc for getting process metrics
 func (e *APIEndpoints) ProcessMetricsHandler() gin.HandlerFunc {
 	r := &Router{mgr: e.mgr, basePath: e.basePath}
@@ -432,17 +663,24 @@ func (e *APIEndpoints) RegisterAll(group *gin.RouterGroup) {
 	group.POST("/update", e.UpdateHandler())
 	group.POST("/start", e.StartHandler())
 	group.POST("/stop", e.StopHandler())
+	group.POST("/restart", e.RestartHandler())
+	group.POST("/signal", e.SignalHandler())
+	group.POST("/scale", e.ScaleHandler())
 	group.POST("/unregister", e.UnregisterHandler())
+	group.POST("/batch/start", e.BatchStartHandler())
+	group.POST("/batch/stop", e.BatchStopHandler())
 	group.GET("/status", e.StatusHandler())
 	group.GET("/groups", e.GroupsHandler())
 	group.GET("/group/status", e.GroupStatusHandler())
 	group.POST("/group/start", e.GroupStartHandler())
 	group.POST("/group/stop", e.GroupStopHandler())
+	group.POST("/group/rolling-restart", e.GroupRollingRestartHandler())
 	group.GET("/processes/:name/logs", e.ProcessLogsHandler())
 	group.GET("/processes/:name/spec", e.ProcessSpecHandler())
 	group.GET("/templates", e.TemplateTypesHandler())
 	group.GET("/templates/:kind", e.TemplatePreviewHandler())
 	group.GET("/debug/processes", e.DebugProcessesHandler())
+	group.GET("/debug/transitions", e.DebugTransitionsHandler())
 	group.GET("/metrics", e.ProcessMetricsHandler())
 	group.GET("/metrics/history", e.ProcessMetricsHistoryHandler())
 	group.GET("/metrics/group", e.ProcessMetricsGroupHandler())
@@ -491,6 +729,79 @@ func (r *Router) handleGroups(c *gin.Context) {
 	writeJSON(c, http.StatusOK, response)
 }
 
+// readinessTimeout bounds each dependency check of GET /readyz.
+const readinessTimeout = 2 * time.Second
+
+// handleHealthz is the liveness probe: the daemon answers requests.
+func (r *Router) handleHealthz(c *gin.Context) {
+	writeJSON(c, http.StatusOK, okResp{OK: true})
+}
+
+// handleReadyz is the readiness probe: 200 when the daemon is not shutting
+// down, its cron scheduler runs and its auth and history stores answer,
+// else 503. Checks for features that are off are left out.
+func (r *Router) handleReadyz(c *gin.Context) {
+	type check struct {
+		name string
+		run  func(context.Context) error
+	}
+	checks := []check{{"shutdown", func(context.Context) error {
+		if r.shuttingDown.Load() {
+			return errors.New("shutting down")
+		}
+		return nil
+	}}}
+	if r.cronScheduler != nil {
+		checks = append(checks, check{"cron_scheduler", func(context.Context) error {
+			if !r.cronScheduler.Running() {
+				return errors.New("stopped")
+			}
+			return nil
+		}})
+	}
+	if r.authService != nil {
+		checks = append(checks, check{"auth_store", r.authService.Store().Ping})
+	}
+	if pinger, ok := r.historyReader.(corehistory.Pinger); ok {
+		checks = append(checks, check{"history_store", pinger.Ping})
+	}
+
+	resp := apiwire.Readiness{Ready: true, Checks: make(map[string]string, len(checks))}
+	for _, chk := range checks {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		err := chk.run(ctx)
+		cancel()
+		if err != nil {
+			resp.Ready = false
+			resp.Checks[chk.name] = err.Error()
+		} else {
+			resp.Checks[chk.name] = "ok"
+		}
+	}
+	code := http.StatusOK
+	if !resp.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(c, code, resp)
+}
+
+func (r *Router) markShuttingDown() { r.shuttingDown.Store(true) }
+
+// versionHeaderMiddleware stamps responses with the daemon's version.
+func versionHeaderMiddleware() gin.HandlerFunc {
+	v := version.Get().Version
+	return func(c *gin.Context) {
+		c.Header(apiwire.VersionHeader, v)
+		c.Next()
+	}
+}
+
+// handleVersion returns the build information of the daemon to any
+// authenticated user.
+func (r *Router) handleVersion(c *gin.Context) {
+	writeJSON(c, http.StatusOK, version.Get())
+}
+
 func (r *Router) handleRuntimeStatus(c *gin.Context) {
 	writeJSON(c, http.StatusOK, apiwire.RuntimeStatus{
 		AuthEnabled:          r.authService != nil,
@@ -529,17 +840,23 @@ func (r *Router) handleTemplatePreview(c *gin.Context) {
 
 // processSelector holds the parsed query parameters for process selection
 type processSelector struct {
-	name string
-	base string
-	wild string
-	wait time.Duration
+	name  string
+	base  string
+	wild  string
+	regex string
+	wait  time.Duration
 }
 
+// errRegexSelectorUnsupported is returned by endpoints that accept the
+// process selector but not its regex form.
+const errRegexSelectorUnsupported = "regex selector is only supported for status and stop"
+
 // parseProcessSelector extracts and validates process selector parameters from the request
 func parseProcessSelector(c *gin.Context) (*processSelector, error) {
 	name := c.Query("name")
 	base := c.Query("base")
 	wild := c.Query("wildcard")
+	regex := c.Query("regex")
 	waitStr := c.Query("wait")
 	wait := 2 * time.Second
 	if waitStr != "" {
@@ -547,7 +864,13 @@ func parseProcessSelector(c *gin.Context) (*processSelector, error) {
 			wait = d
 		}
 	}
+	return newProcessSelector(name, base, wild, regex, wait)
+}
 
+// newProcessSelector validates a selector given by its parts; exactly one
+// of name, base, wild and regex must be set. Shared by the REST and gRPC
+// front ends.
+func newProcessSelector(name, base, wild, regex string, wait time.Duration) (*processSelector, error) {
 	// ensure exactly one selector is provided
 	selCount := 0
 	if name != "" {
@@ -559,11 +882,14 @@ func parseProcessSelector(c *gin.Context) (*processSelector, error) {
 	if wild != "" {
 		selCount++
 	}
+	if regex != "" {
+		selCount++
+	}
 	if selCount == 0 {
-		return nil, fmt.Errorf("one of name, base, wildcard query param required")
+		return nil, fmt.Errorf("one of name, base, wildcard, regex query param required")
 	}
 	if selCount > 1 {
-		return nil, fmt.Errorf("exactly one of name, base, or wildcard must be provided")
+		return nil, fmt.Errorf("exactly one of name, base, wildcard, or regex must be provided")
 	}
 
 	// Validate process identifiers to avoid path traversal
@@ -575,10 +901,11 @@ func parseProcessSelector(c *gin.Context) (*processSelector, error) {
 	}
 
 	return &processSelector{
-		name: name,
-		base: base,
-		wild: wild,
-		wait: wait,
+		name:  name,
+		base:  base,
+		wild:  wild,
+		regex: regex,
+		wait:  wait,
 	}, nil
 }
 
@@ -590,36 +917,42 @@ func bindAndValidateSpec(c *gin.Context) (spec core.Spec, ok bool) {
 		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid JSON: " + err.Error()})
 		return spec, false
 	}
-	if spec.Name == "" {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: "spec.name required"})
+	setAuditTarget(c, spec.Name)
+	if err := validateSpec(spec); err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
 		return spec, false
 	}
+	return spec, true
+}
+
+// validateSpec checks the name and path-like fields of a spec received over
+// an API, then the full spec invariants.
+func validateSpec(spec core.Spec) error {
+	if spec.Name == "" {
+		return errors.New("spec.name required")
+	}
 	// Validate process name and any path-like fields to avoid uncontrolled path usage
 	if !isSafeName(spec.Name) {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid spec.name: allowed [A-Za-z0-9._-] and no '..' or path separators"})
-		return spec, false
+		return errors.New("invalid spec.name: allowed [A-Za-z0-9._-] and no '..' or path separators")
 	}
 	if !isSafeAbsPath(spec.WorkDir) {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid work_dir: must be absolute path without traversal"})
-		return spec, false
+		return errors.New("invalid work_dir: must be absolute path without traversal")
 	}
 	if !isSafeAbsPath(spec.PIDFile) {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid pid_file: must be absolute path without traversal"})
-		return spec, false
+		return errors.New("invalid pid_file: must be absolute path without traversal")
 	}
 	if !isSafeAbsPath(spec.Log.File.Dir) {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid log.dir: must be absolute path without traversal"})
-		return spec, false
+		return errors.New("invalid log.dir: must be absolute path without traversal")
 	}
 	if !isSafeAbsPath(spec.Log.File.StdoutPath) {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid log.stdoutPath: must be absolute path without traversal"})
-		return spec, false
+		return errors.New("invalid log.stdoutPath: must be absolute path without traversal")
 	}
 	if !isSafeAbsPath(spec.Log.File.StderrPath) {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid log.stderrPath: must be absolute path without traversal"})
-		return spec, false
+		return errors.New("invalid log.stderrPath: must be absolute path without traversal")
 	}
-	return spec, true
+	// Full spec invariants, including resolving user/group names so an
+	// unknown account is rejected here rather than on every start attempt.
+	return spec.Validate()
 }
 
 // programFileExtensions lists every extension loadProgramEntries accepts
@@ -767,42 +1100,41 @@ func (r *Router) handleRegister(c *gin.Context) {
 	if !ok {
 		return
 	}
-	registrationNames := []string{spec.Name}
-	if spec.Instances > 1 {
-		registrationNames = make([]string, 0, spec.Instances)
-		for i := 1; i <= spec.Instances; i++ {
-			registrationNames = append(registrationNames, fmt.Sprintf("%s-%d", spec.Name, i))
-		}
+	if status, err := r.registerSpec(spec); err != nil {
+		writeJSON(c, status, errorResp{Error: err.Error()})
+		return
 	}
+	writeJSON(c, http.StatusOK, okResp{OK: true})
+}
+
+// registerSpec persists and registers a validated spec, rolling both back on
+// failure. On error it also returns the HTTP status that describes it.
+func (r *Router) registerSpec(spec core.Spec) (int, error) {
+	registrationNames := r.mgr.InstanceNaming().Names(spec.Name, spec.Instances)
 	for _, name := range registrationNames {
 		if _, err := r.mgr.GetSpec(name); err == nil {
-			writeJSON(c, http.StatusBadRequest, errorResp{Error: fmt.Sprintf("process %q is already registered", name)})
-			return
+			return http.StatusBadRequest, fmt.Errorf("process %q is already registered", name)
 		}
 	}
 	backup, err := r.backupProgramFile(spec.Name)
 	if err != nil {
-		writeJSON(c, http.StatusInternalServerError, errorResp{Error: err.Error()})
-		return
+		return http.StatusInternalServerError, err
 	}
 	// Persist before registering: a filesystem error here should not leave a
 	// process running that a restart would then fail to recreate silently.
 	if err := r.persistProgramFile(spec); err != nil {
-		writeJSON(c, http.StatusInternalServerError, errorResp{Error: err.Error()})
-		return
+		return http.StatusInternalServerError, err
 	}
 	if err := r.mgr.RegisterN(spec); err != nil {
 		for _, name := range registrationNames {
 			_ = r.mgr.Unregister(name, 5*time.Second)
 		}
 		if restoreErr := r.restoreProgramFile(spec.Name, backup); restoreErr != nil {
-			writeJSON(c, http.StatusInternalServerError, errorResp{Error: fmt.Sprintf("%v; rollback failed: %v", err, restoreErr)})
-			return
+			return http.StatusInternalServerError, fmt.Errorf("%v; rollback failed: %v", err, restoreErr)
 		}
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
-		return
+		return http.StatusBadRequest, err
 	}
-	writeJSON(c, http.StatusOK, okResp{OK: true})
+	return http.StatusOK, nil
 }
 
 // handleUpdate replaces the spec of an already-registered process and
@@ -821,41 +1153,59 @@ func (r *Router) handleUpdate(c *gin.Context) {
 		}
 		wait = d
 	}
-	currentName := spec.Name
+	if status, err := r.updateSpec(spec.Name, spec, wait); err != nil {
+		if status == http.StatusConflict {
+			base, _ := r.mgr.ProcessBase(spec.Name)
+			writeJSON(c, status, errInlineConfigured("process", base))
+			return
+		}
+		writeJSON(c, status, errorResp{Error: err.Error()})
+		return
+	}
+	writeJSON(c, http.StatusOK, okResp{OK: true})
+}
+
+// errInlineProcess is returned by updateSpec for processes declared in the
+// main config file, which the API must not rewrite.
+var errInlineProcess = errors.New("process is defined in the main config file")
+
+// updateSpec persists spec under the base name of the registered process
+// currentName and restarts its instances under it, restoring the previous
+// program file on failure. On error it also returns the HTTP status that
+// describes it; http.StatusConflict means errInlineProcess.
+func (r *Router) updateSpec(currentName string, spec core.Spec, wait time.Duration) (int, error) {
 	base, err := r.mgr.ProcessBase(currentName)
 	if err != nil {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
-		return
+		return http.StatusBadRequest, err
 	}
 	spec.Name = base
 	if r.isInlineConfiguredProcess(base) {
-		writeJSON(c, http.StatusConflict, errInlineConfigured("process", base))
-		return
+		return http.StatusConflict, errInlineProcess
 	}
 	backup, err := r.backupProgramFile(base)
 	if err != nil {
-		writeJSON(c, http.StatusInternalServerError, errorResp{Error: err.Error()})
-		return
+		return http.StatusInternalServerError, err
 	}
 	if err := r.persistProgramFile(spec); err != nil {
-		writeJSON(c, http.StatusInternalServerError, errorResp{Error: err.Error()})
-		return
+		return http.StatusInternalServerError, err
 	}
 	if _, err := r.mgr.UpdateInstances(currentName, spec, wait); err != nil {
 		if restoreErr := r.restoreProgramFile(base, backup); restoreErr != nil {
-			writeJSON(c, http.StatusInternalServerError, errorResp{Error: fmt.Sprintf("%v; persistence rollback failed: %v", err, restoreErr)})
-			return
+			return http.StatusInternalServerError, fmt.Errorf("%v; persistence rollback failed: %v", err, restoreErr)
 		}
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
-		return
+		return http.StatusBadRequest, err
 	}
 	if currentName != base {
 		_ = r.removeProgramFile(currentName)
 	}
-	writeJSON(c, http.StatusOK, okResp{OK: true})
+	return http.StatusOK, nil
 }
 
 func (r *Router) handleStop(c *gin.Context) {
+	if pidStr := c.Query("pid"); pidStr != "" {
+		r.stopByPID(c, pidStr)
+		return
+	}
 	selector, err := parseProcessSelector(c)
 	if err != nil {
 		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
@@ -864,24 +1214,252 @@ func (r *Router) handleStop(c *gin.Context) {
 
 	if selector.base != "" {
 		err = r.mgr.StopAll(selector.base, selector.wait)
+	} else if selector.regex != "" {
+		err = r.mgr.StopRegex(selector.regex, selector.wait)
 	} else if selector.wild != "" {
 		err = r.mgr.StopAll(selector.wild, selector.wait)
 	} else {
 		// single process by name
-		err = r.mgr.Stop(selector.name, selector.wait)
+		err = r.mgr.StopContext(c.Request.Context(), selector.name, selector.wait)
+	}
+
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+		return
+	}
+	writeJSON(c, http.StatusOK, okResp{OK: true})
+}
+
+// stopByPID answers POST /stop?pid=, which stops the managed process
+// running as that PID; no other selector may be given with it.
+func (r *Router) stopByPID(c *gin.Context, pidStr string) {
+	if c.Query("name") != "" || c.Query("base") != "" || c.Query("wildcard") != "" || c.Query("regex") != "" {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "pid cannot be combined with name, base, wildcard or regex"})
+		return
+	}
+	pid, err := strconv.Atoi(pidStr)
+	if err != nil || pid <= 0 {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid pid"})
+		return
+	}
+	wait := 2 * time.Second
+	if d, err := time.ParseDuration(c.Query("wait")); err == nil {
+		wait = d
+	}
+	if err := r.mgr.StopByPID(pid, wait); err != nil {
+		writeJSON(c, http.StatusNotFound, errorResp{Error: err.Error()})
+		return
+	}
+	writeJSON(c, http.StatusOK, okResp{OK: true})
+}
+
+// handleRestart stops and starts again the processes picked by a name, base
+// or wildcard selector, or every member of group, and reports their new
+// PIDs. Selected processes are restarted one at a time, so other instances
+// of a base keep running meanwhile; a group is stopped as a whole first.
+// Processes that weren't running are just started.
+// query: name|base|wildcard|group, wait=<duration> (stop timeout, default 2s).
+func (r *Router) handleRestart(c *gin.Context) {
+	if groupName := c.Query("group"); groupName != "" {
+		r.restartGroup(c, groupName)
+		return
+	}
+	selector, err := parseProcessSelector(c)
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+		return
+	}
+	if selector.regex != "" {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: errRegexSelectorUnsupported})
+		return
+	}
+
+	var names []string
+	if selector.name != "" {
+		names = append(names, selector.name)
+	} else {
+		pattern := selector.base
+		if pattern == "" {
+			pattern = selector.wild
+		}
+		statuses, _ := r.mgr.StatusAll(pattern)
+		for _, st := range statuses {
+			names = append(names, st.Name)
+		}
+		if len(names) == 0 {
+			writeJSON(c, http.StatusNotFound, errorResp{Error: "no processes match " + pattern})
+			return
+		}
+	}
+
+	results := make([]apiwire.RestartResult, 0, len(names))
+	for _, name := range names {
+		err := r.mgr.RestartContext(c.Request.Context(), name, selector.wait)
+		results = append(results, r.restartResult(name, err))
+	}
+	writeRestartResults(c, results)
+}
+
+// restartGroup stops every member of groupName, then starts them again.
+func (r *Router) restartGroup(c *gin.Context, groupName string) {
+	if c.Query("name") != "" || c.Query("base") != "" || c.Query("wildcard") != "" || c.Query("regex") != "" {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "group cannot be combined with name, base, wildcard or regex"})
+		return
+	}
+	if !isSafeName(groupName) {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid group name: allowed [A-Za-z0-9._-] and no '..' or path separators"})
+		return
+	}
+	wait := 2 * time.Second
+	if d, err := time.ParseDuration(c.Query("wait")); err == nil {
+		wait = d
+	}
+
+	groupStatus, err := r.mgr.InstanceGroupStatus(groupName)
+	if err != nil {
+		writeJSON(c, http.StatusNotFound, errorResp{Error: err.Error()})
+		return
+	}
+	if err := r.mgr.InstanceGroupStop(groupName, wait); err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+		return
+	}
+	var names []string
+	for _, sts := range groupStatus {
+		for _, st := range sts {
+			names = append(names, st.Name)
+		}
+	}
+	sort.Strings(names)
+
+	results := make([]apiwire.RestartResult, 0, len(names))
+	for _, name := range names {
+		err := r.mgr.StartContext(c.Request.Context(), name)
+		results = append(results, r.restartResult(name, err))
+	}
+	writeRestartResults(c, results)
+}
+
+func (r *Router) restartResult(name string, err error) apiwire.RestartResult {
+	if err != nil {
+		return apiwire.RestartResult{Name: name, Error: err.Error()}
+	}
+	result := apiwire.RestartResult{Name: name, OK: true}
+	if st, err := r.mgr.Status(name); err == nil {
+		result.PID = st.PID
+	}
+	return result
+}
+
+func writeRestartResults(c *gin.Context, results []apiwire.RestartResult) {
+	status := http.StatusOK
+	for _, res := range results {
+		if !res.OK {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+	writeJSON(c, status, apiwire.RestartResponse{Results: results})
+}
+
+// handleScale changes how many instances of a process set run, starting or
+// stopping only the difference, and persists the new count to its program
+// file. query: name (the base name), instances=<n>.
+func (r *Router) handleScale(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "name query param required"})
+		return
+	}
+	if !isSafeName(name) {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid name: allowed [A-Za-z0-9._-] and no '..' or path separators"})
+		return
+	}
+	n, err := strconv.Atoi(c.Query("instances"))
+	if err != nil || n < 1 {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "instances must be a positive integer"})
+		return
+	}
+	spec, err := r.mgr.GetSpec(name)
+	if err != nil {
+		if spec, err = r.mgr.GetSpec(r.mgr.InstanceNaming().Name(name, 0)); err != nil {
+			writeJSON(c, http.StatusNotFound, errorResp{Error: "process " + name + " not found"})
+			return
+		}
+	}
+	base, err := r.mgr.ProcessBase(spec.Name)
+	if err != nil || base != name {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: fmt.Sprintf("%s is an instance of %s; scale the base name", name, base)})
+		return
+	}
+	if spec.InlineConfig {
+		writeJSON(c, http.StatusConflict, errInlineConfigured("process", base))
+		return
 	}
 
+	backup, err := r.backupProgramFile(base)
 	if err != nil {
+		writeJSON(c, http.StatusInternalServerError, errorResp{Error: err.Error()})
+		return
+	}
+	spec.Name = base
+	spec.Instances = n
+	if err := r.persistProgramFile(spec); err != nil {
+		writeJSON(c, http.StatusInternalServerError, errorResp{Error: err.Error()})
+		return
+	}
+	if err := r.mgr.Scale(base, n); err != nil {
+		if restoreErr := r.restoreProgramFile(base, backup); restoreErr != nil {
+			err = fmt.Errorf("%v; persistence rollback failed: %v", err, restoreErr)
+		}
 		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
 		return
 	}
 	writeJSON(c, http.StatusOK, okResp{OK: true})
 }
 
+// handleSignal sends a signal to a running process without stopping it.
+// query: name, signal (e.g. HUP, SIGUSR1 or a number).
+func (r *Router) handleSignal(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "name query param required"})
+		return
+	}
+	if !isSafeName(name) {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid name: allowed [A-Za-z0-9._-] and no '..' or path separators"})
+		return
+	}
+	sig, err := core.ParseSignal(c.Query("signal"))
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+		return
+	}
+	if _, err := r.mgr.Status(name); err != nil {
+		writeJSON(c, http.StatusNotFound, errorResp{Error: err.Error()})
+		return
+	}
+	if err := r.mgr.Signal(name, sig); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, core.ErrNotRunning) {
+			status = http.StatusConflict
+		}
+		writeJSON(c, status, errorResp{Error: err.Error()})
+		return
+	}
+	writeJSON(c, http.StatusOK, okResp{OK: true})
+}
+
 func (r *Router) handleStatus(c *gin.Context) {
 	name := c.Query("name")
 	base := c.Query("base")
 	wild := c.Query("wildcard")
+	regex := c.Query("regex")
+	format, err := apiwire.NegotiateFormat(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+		return
+	}
 	// ensure exactly one selector is provided
 	selCount := 0
 	if name != "" {
@@ -893,31 +1471,115 @@ func (r *Router) handleStatus(c *gin.Context) {
 	if wild != "" {
 		selCount++
 	}
-	if selCount == 0 {
-		// readiness/health probe: no selector provided
-		writeJSON(c, http.StatusOK, okResp{OK: true})
+	if regex != "" {
+		selCount++
+	}
+	labels, err := core.ParseLabelSelector(c.QueryArray("label")...)
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
 		return
 	}
+	if selCount == 0 {
+		if len(labels) == 0 {
+			// Health probe of older clients; see GET /healthz and /readyz.
+			writeJSON(c, http.StatusOK, okResp{OK: true})
+			return
+		}
+		// label on its own selects among every process
+		wild = "*"
+	}
 	if selCount > 1 {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: "only one of name, base, wildcard must be provided"})
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "only one of name, base, wildcard, regex must be provided"})
 		return
 	}
-	if base != "" {
-		sts, err := r.mgr.StatusAll(base)
+	if name != "" && len(labels) > 0 {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "label filters the process list and cannot be combined with name"})
+		return
+	}
+	detailed := false
+	if v := c.Query("detailed"); v != "" {
+		b, err := strconv.ParseBool(v)
 		if err != nil {
-			writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+			writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid detailed flag"})
 			return
 		}
-		writeJSON(c, http.StatusOK, sts)
+		detailed = b
+	}
+	running := false
+	if v := c.Query("running"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid running flag"})
+			return
+		}
+		running = b
+	}
+	fields, err := core.ParseStatusFields(c.Query("fields"))
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
 		return
 	}
-	if wild != "" {
-		sts, err := r.mgr.StatusAll(wild)
+	omitEmpty := false
+	if v := c.Query("omit_empty"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid omit_empty flag"})
+			return
+		}
+		omitEmpty = b
+	}
+	offset, limit, err := statusPage(c)
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+		return
+	}
+	project := len(fields) > 0 || omitEmpty
+	if project && detailed {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "fields and omit_empty cannot be combined with detailed"})
+		return
+	}
+	if name == "" {
+		var sts []core.Status
+		var err error
+		switch {
+		case regex != "":
+			sts, err = r.mgr.StatusRegex(regex)
+		case base != "":
+			sts, err = r.mgr.StatusAll(base)
+		default:
+			sts, err = r.mgr.StatusAll(wild)
+		}
 		if err != nil {
 			writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
 			return
 		}
-		writeJSON(c, http.StatusOK, sts)
+		if running {
+			sts = onlyRunning(sts)
+		}
+		if len(labels) > 0 {
+			sts = r.withLabels(sts, labels)
+		}
+		if offset > 0 || limit > 0 {
+			c.Header("X-Total-Count", strconv.Itoa(len(sts)))
+			sts = pageOf(sts, offset, limit)
+		}
+		if detailed {
+			out := make([]apiwire.DetailedStatus, len(sts))
+			for i, st := range sts {
+				out[i] = r.detailedStatus(st)
+			}
+			writeStatus(c, format, out, statusRows(sts))
+			return
+		}
+		if project {
+			out := make([]map[string]any, len(sts))
+			for i, st := range sts {
+				out[i] = st.Project(fields, omitEmpty)
+			}
+			writeStatus(c, format, out, statusRows(sts))
+			return
+		}
+		writeStatus(c, format, sts, statusRows(sts))
 		return
 	}
 	st, err := r.mgr.Status(name)
@@ -925,15 +1587,161 @@ func (r *Router) handleStatus(c *gin.Context) {
 		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
 		return
 	}
-	writeJSON(c, http.StatusOK, st)
+	if detailed {
+		writeStatus(c, format, r.detailedStatus(st), statusRows([]core.Status{st}))
+		return
+	}
+	if project {
+		writeStatus(c, format, st.Project(fields, omitEmpty), statusRows([]core.Status{st}))
+		return
+	}
+	writeStatus(c, format, st, statusRows([]core.Status{st}))
+}
+
+// statusPage parses the optional offset and limit of a status list. A
+// limit of 0 means no limit.
+func statusPage(c *gin.Context) (offset, limit int, err error) {
+	for _, p := range []struct {
+		key string
+		dst *int
+	}{{"offset", &offset}, {"limit", &limit}} {
+		v := c.Query(p.key)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("%s must be a non-negative number", p.key)
+		}
+		*p.dst = n
+	}
+	return offset, limit, nil
+}
+
+// pageOf returns the statuses from offset, at most limit of them when
+// limit is positive.
+func pageOf(sts []core.Status, offset, limit int) []core.Status {
+	if offset >= len(sts) {
+		return []core.Status{}
+	}
+	sts = sts[offset:]
+	if limit > 0 && limit < len(sts) {
+		sts = sts[:limit]
+	}
+	return sts
+}
+
+// withLabels keeps the statuses of processes whose spec has every label of
+// selector, as given by label=key=value.
+func (r *Router) withLabels(sts []core.Status, selector map[string]string) []core.Status {
+	out := make([]core.Status, 0, len(sts))
+	for _, st := range sts {
+		if r.mgr.MatchesLabels(st.Name, selector) {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+// onlyRunning keeps the statuses of running processes. Status lists every
+// registered process, stopped ones included, unless running=true asks for this.
+func onlyRunning(sts []core.Status) []core.Status {
+	out := make([]core.Status, 0, len(sts))
+	for _, st := range sts {
+		if st.Running {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+// writeStatus answers a status request with v as JSON, or with rows in the
+// negotiated table or text rendering.
+func writeStatus(c *gin.Context, format apiwire.Format, v any, rows []apiwire.StatusRow) {
+	if format == apiwire.FormatJSON {
+		writeJSON(c, http.StatusOK, v)
+		return
+	}
+	c.Header("Content-Type", format.ContentType())
+	c.Status(http.StatusOK)
+	_ = apiwire.WriteStatusRows(c.Writer, format, rows)
+}
+
+func statusRows(sts []core.Status) []apiwire.StatusRow {
+	rows := make([]apiwire.StatusRow, len(sts))
+	for i, st := range sts {
+		rows[i] = apiwire.StatusRow{
+			Name:       st.Name,
+			State:      st.State,
+			Running:    st.Running,
+			PID:        st.PID,
+			Restarts:   st.Restarts,
+			Uptime:     st.Uptime,
+			DetectedBy: st.DetectedBy,
+		}
+	}
+	return rows
+}
+
+// detailedStatus expands st with uptime, last exit, the latest resource
+// sample (when metrics are enabled) and lifecycle hook summaries.
+func (r *Router) detailedStatus(st core.Status) apiwire.DetailedStatus {
+	out := apiwire.DetailedStatus{
+		Name:           st.Name,
+		State:          st.State,
+		Running:        st.Running,
+		PID:            st.PID,
+		StartedAt:      st.StartedAt,
+		StoppedAt:      st.StoppedAt,
+		Restarts:       st.Restarts,
+		DetectedBy:     st.DetectedBy,
+		MetricsEnabled: r.mgr.IsProcessMetricsEnabled(),
+		Hooks:          []apiwire.HookSummary{},
+	}
+	if st.Running && !st.StartedAt.IsZero() {
+		out.UptimeSeconds = time.Since(st.StartedAt).Seconds()
+	}
+	if !st.StoppedAt.IsZero() && !st.StoppedAt.Before(st.StartedAt) {
+		code, sig := core.ExitDetails(st.ExitErr)
+		out.LastExit = &apiwire.ExitInfo{At: st.StoppedAt, Code: code, Signal: sig}
+		if st.ExitErr != nil {
+			out.LastExit.Error = st.ExitErr.Error()
+		}
+	}
+	if out.MetricsEnabled {
+		if m, ok := r.mgr.GetProcessMetrics(st.Name); ok {
+			out.Resources = &apiwire.ResourceSnapshot{
+				SampledAt:  m.Timestamp,
+				CPUPercent: m.CPUPercent,
+				MemoryRSS:  m.MemoryRSS,
+				MemoryMB:   m.MemoryMB,
+				NumThreads: m.NumThreads,
+			}
+		}
+	}
+	if hooks, err := r.mgr.HookSummaries(st.Name); err == nil {
+		for _, h := range hooks {
+			out.Hooks = append(out.Hooks, apiwire.HookSummary{
+				Phase:          h.Phase,
+				Name:           h.Name,
+				Runs:           h.Runs,
+				Failures:       h.Failures,
+				LastRunAt:      h.LastRunAt,
+				LastDurationMS: h.LastDuration.Milliseconds(),
+				LastError:      h.LastError,
+			})
+		}
+	}
+	return out
 }
 
 // Debug endpoints for troubleshooting
 
 type debugProcessInfo struct {
-	Status        core.Status `json:"status"`
-	InternalState string      `json:"internal_state"`
-	HealthCheck   string      `json:"health_check"`
+	Status            core.Status          `json:"status"`
+	InternalState     string               `json:"internal_state"`
+	HealthCheck       string               `json:"health_check"`
+	PendingAsyncHooks []core.AsyncHookInfo `json:"pending_async_hooks"`
 }
 
 func (r *Router) handleDebugProcesses(c *gin.Context) {
@@ -948,10 +1756,15 @@ func (r *Router) handleDebugProcesses(c *gin.Context) {
 
 	debugInfos := make([]debugProcessInfo, len(statuses))
 	for i, status := range statuses {
+		pending, _ := r.mgr.PendingAsyncHooks(status.Name)
+		if pending == nil {
+			pending = []core.AsyncHookInfo{}
+		}
 		debugInfos[i] = debugProcessInfo{
-			Status:        status,
-			InternalState: status.State, // Already includes state machine state
-			HealthCheck:   getHealthStatus(status),
+			Status:            status,
+			InternalState:     status.State, // Already includes state machine state
+			HealthCheck:       getHealthStatus(status),
+			PendingAsyncHooks: pending,
 		}
 	}
 
@@ -1067,10 +1880,7 @@ func (r *Router) handleGetSpec(c *gin.Context) {
 	writeJSON(c, http.StatusOK, specResp{Spec: spec, Provisioned: spec.InlineConfig})
 }
 
-type jobResp struct {
-	core.JobSpec
-	Status core.JobStatus `json:"status"`
-}
+type jobResp = apiwire.Job
 
 func (r *Router) jobResponse(name string) (jobResp, bool) {
 	spec, ok := r.jobManager.GetJobSpec(name)
@@ -1113,6 +1923,7 @@ func bindAndValidateJob(c *gin.Context) (spec core.JobSpec, ok bool) {
 		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid JSON: " + err.Error()})
 		return spec, false
 	}
+	setAuditTarget(c, spec.Name)
 	if spec.Name == "" {
 		writeJSON(c, http.StatusBadRequest, errorResp{Error: "spec.name required"})
 		return spec, false
@@ -1159,6 +1970,69 @@ func (r *Router) handleDeleteJob(c *gin.Context) {
 	writeJSON(c, http.StatusOK, okResp{OK: true})
 }
 
+// handleJobInstances returns every instance launched for a job with its PID,
+// state and exit code, so a partially-failed job shows which one failed.
+func (r *Router) handleJobInstances(c *gin.Context) {
+	name := c.Param("name")
+	instances, ok := r.jobManager.GetJobInstances(name)
+	if !ok {
+		writeJSON(c, http.StatusNotFound, errorResp{Error: fmt.Sprintf("job %q not found", name)})
+		return
+	}
+	writeJSON(c, http.StatusOK, instances)
+}
+
+// handleJobInstanceLogs returns the output captured for one instance of a job
+// with capture_output set. id is the instance's attempt number or its name.
+func (r *Router) handleJobInstanceLogs(c *gin.Context) {
+	name, id := c.Param("name"), c.Param("id")
+	attempt, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		instances, _ := r.jobManager.GetJobInstances(name)
+		for _, instance := range instances {
+			if instance.Name == id {
+				attempt = int64(instance.Attempt)
+				break
+			}
+		}
+	}
+	out, ok, err := r.jobManager.JobOutput(c.Request.Context(), name, int32(attempt))
+	if err != nil {
+		writeJSON(c, http.StatusInternalServerError, errorResp{Error: err.Error()})
+		return
+	}
+	if !ok {
+		writeJSON(c, http.StatusNotFound, errorResp{Error: fmt.Sprintf("no output captured for instance %q of job %q", id, name)})
+		return
+	}
+	writeJSON(c, http.StatusOK, out)
+}
+
+// handleSuspendJob stops a job from launching new instances; running ones are
+// left to finish.
+func (r *Router) handleSuspendJob(c *gin.Context) {
+	r.setJobSuspended(c, r.jobManager.SuspendJob)
+}
+
+// handleResumeJob lets a suspended job launch instances again.
+func (r *Router) handleResumeJob(c *gin.Context) {
+	r.setJobSuspended(c, r.jobManager.ResumeJob)
+}
+
+func (r *Router) setJobSuspended(c *gin.Context, apply func(name string) error) {
+	name := c.Param("name")
+	if _, ok := r.jobManager.GetJobSpec(name); !ok {
+		writeJSON(c, http.StatusNotFound, errorResp{Error: fmt.Sprintf("job %q not found", name)})
+		return
+	}
+	if err := apply(name); err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+		return
+	}
+	job, _ := r.jobResponse(name)
+	writeJSON(c, http.StatusOK, job)
+}
+
 // cronJobResp is the wire shape for a single cronjob: the spec fields
 // flattened (via anonymous embedding) plus its live status and next run time.
 // Provisioned reports the same "declared in the main config file" status as
@@ -1234,6 +2108,7 @@ func bindAndValidateCronJob(c *gin.Context) (spec core.CronJob, ok bool) {
 		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid JSON: " + err.Error()})
 		return spec, false
 	}
+	setAuditTarget(c, spec.Name)
 	if spec.Name == "" {
 		writeJSON(c, http.StatusBadRequest, errorResp{Error: "spec.name required"})
 		return spec, false
@@ -1380,8 +2255,12 @@ func (r *Router) handleStart(c *gin.Context) {
 		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
 		return
 	}
+	if selector.regex != "" {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: errRegexSelectorUnsupported})
+		return
+	}
 	if selector.name != "" {
-		err = r.mgr.Start(selector.name)
+		err = r.mgr.StartContext(c.Request.Context(), selector.name)
 	} else if selector.base != "" {
 		err = r.mgr.StartAll(selector.base)
 	} else {
@@ -1401,6 +2280,10 @@ func (r *Router) handleUnregister(c *gin.Context) {
 		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
 		return
 	}
+	if selector.regex != "" {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: errRegexSelectorUnsupported})
+		return
+	}
 	persistedName := selector.base
 	if selector.name != "" {
 		persistedName, err = r.mgr.ProcessBase(selector.name)
@@ -1474,13 +2357,23 @@ func (r *Router) handleGroupStatus(c *gin.Context) {
 		return
 	}
 
+	format, err := apiwire.NegotiateFormat(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+		return
+	}
+
 	groupStatus, err := r.mgr.InstanceGroupStatus(groupName)
 	if err != nil {
 		writeJSON(c, http.StatusNotFound, errorResp{Error: err.Error()})
 		return
 	}
 
-	writeJSON(c, http.StatusOK, groupStatus)
+	members := make(map[string][]apiwire.StatusRow, len(groupStatus))
+	for member, sts := range groupStatus {
+		members[member] = statusRows(sts)
+	}
+	writeStatus(c, format, groupStatus, apiwire.GroupRows(members))
 }
 
 func (r *Router) handleGroupStart(c *gin.Context) {
@@ -1505,6 +2398,166 @@ func (r *Router) handleGroupStart(c *gin.Context) {
 	writeJSON(c, http.StatusOK, okResp{OK: true})
 }
 
+// handleGroupRollingRestart restarts the instances of a group a batch at a
+// time, waiting for each batch to run again before the next, and reports
+// their new PIDs. The rollout stops at the first failed batch; instances
+// after it are not in the results.
+// query: group, max_unavailable=<n> (default 1), wait=<duration> (stop
+// timeout, default 2s), ready_timeout=<duration> (default 30s).
+func (r *Router) handleGroupRollingRestart(c *gin.Context) {
+	groupName := c.Query("group")
+	if groupName == "" {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "group parameter required"})
+		return
+	}
+	if !isSafeName(groupName) {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid group name: allowed [A-Za-z0-9._-] and no '..' or path separators"})
+		return
+	}
+	var opts core.RollingRestartOptions
+	if v := c.Query("max_unavailable"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeJSON(c, http.StatusBadRequest, errorResp{Error: "max_unavailable must be a positive integer"})
+			return
+		}
+		opts.MaxUnavailable = n
+	}
+	for _, d := range []struct {
+		param string
+		dst   *time.Duration
+	}{{"wait", &opts.Wait}, {"ready_timeout", &opts.ReadyTimeout}} {
+		if v := c.Query(d.param); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid " + d.param + " duration: " + err.Error()})
+				return
+			}
+			*d.dst = parsed
+		}
+	}
+
+	var group *core.ManagerInstanceGroup
+	for _, g := range r.mgr.ListInstanceGroups() {
+		if g.Name == groupName {
+			group = &g
+			break
+		}
+	}
+	if group == nil {
+		writeJSON(c, http.StatusNotFound, errorResp{Error: "group " + groupName + " not found"})
+		return
+	}
+
+	restarted, err := core.NewGroup(r.mgr).RollingRestart(core.ServiceGroup{Name: group.Name, Members: group.Members}, opts)
+	if err != nil && len(restarted) == 0 {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: err.Error()})
+		return
+	}
+	results := make([]apiwire.RestartResult, 0, len(restarted))
+	for _, res := range restarted {
+		if res.Err != nil {
+			results = append(results, apiwire.RestartResult{Name: res.Name, Error: res.Err.Error()})
+			continue
+		}
+		results = append(results, apiwire.RestartResult{Name: res.Name, OK: true, PID: res.PID})
+	}
+	writeRestartResults(c, results)
+}
+
+// maxBatchNames caps how many processes a single batch request may address.
+const maxBatchNames = 256
+
+func (r *Router) handleBatchStart(c *gin.Context) {
+	req, ok := bindBatchRequest(c)
+	if !ok {
+		return
+	}
+	writeBatchResults(c, runBatch(req.Names, func(name string) error {
+		return r.mgr.StartContext(c.Request.Context(), name)
+	}))
+}
+
+func (r *Router) handleBatchStop(c *gin.Context) {
+	req, ok := bindBatchRequest(c)
+	if !ok {
+		return
+	}
+	wait := 2 * time.Second
+	if req.Wait != "" {
+		d, err := time.ParseDuration(req.Wait)
+		if err != nil || d < 0 {
+			writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid wait duration: " + req.Wait})
+			return
+		}
+		wait = d
+	}
+	writeBatchResults(c, runBatch(req.Names, func(name string) error {
+		return r.mgr.StopContext(c.Request.Context(), name, wait)
+	}))
+}
+
+// bindBatchRequest decodes and validates a batch body. On failure it writes
+// the error response itself and returns ok=false.
+func bindBatchRequest(c *gin.Context) (req apiwire.BatchRequest, ok bool) {
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid JSON: " + err.Error()})
+		return req, false
+	}
+	if len(req.Names) == 0 {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "names required"})
+		return req, false
+	}
+	if len(req.Names) > maxBatchNames {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: fmt.Sprintf("too many names: %d (max %d)", len(req.Names), maxBatchNames)})
+		return req, false
+	}
+	setAuditTarget(c, strings.Join(req.Names, ","))
+	seen := make(map[string]struct{}, len(req.Names))
+	for _, name := range req.Names {
+		if !isSafeName(name) {
+			writeJSON(c, http.StatusBadRequest, errorResp{Error: fmt.Sprintf("invalid name %q: allowed [A-Za-z0-9._-] and no '..' or path separators", name)})
+			return req, false
+		}
+		if _, dup := seen[name]; dup {
+			writeJSON(c, http.StatusBadRequest, errorResp{Error: fmt.Sprintf("duplicate name %q", name)})
+			return req, false
+		}
+		seen[name] = struct{}{}
+	}
+	return req, true
+}
+
+// runBatch applies op to every name concurrently and returns the results in
+// input order.
+func runBatch(names []string, op func(name string) error) []apiwire.BatchResult {
+	results := make([]apiwire.BatchResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = apiwire.BatchResult{Name: name, OK: true}
+			if err := op(name); err != nil {
+				results[i] = apiwire.BatchResult{Name: name, Error: err.Error()}
+			}
+		}(i, name)
+	}
+	wg.Wait()
+	return results
+}
+
+func writeBatchResults(c *gin.Context, results []apiwire.BatchResult) {
+	status := http.StatusOK
+	for _, res := range results {
+		if !res.OK {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+	writeJSON(c, status, apiwire.BatchResponse{Results: results})
+}
+
 func (r *Router) handleGroupStop(c *gin.Context) {
 	groupName := c.Query("group")
 	if groupName == "" {
@@ -1595,16 +2648,30 @@ func (r *Router) handleProcessMetricsHistory(c *gin.Context) {
 	})
 }
 
+// handleProcessMetricsGroup aggregates the metrics of the instances of the
+// process set base, or of every instance of the members of the instance
+// group group. Membership comes from the registered process set or the
+// group definition, not from name prefixes, so "web" does not take in
+// "web-server-1".
 func (r *Router) handleProcessMetricsGroup(c *gin.Context) {
 	base := c.Query("base")
-	if base == "" {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: "base parameter required"})
+	groupName := c.Query("group")
+	if base == "" && groupName == "" {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "base parameter required (or group for an instance group)"})
+		return
+	}
+	if base != "" && groupName != "" {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "base and group are mutually exclusive"})
 		return
 	}
 
-	// Validate base name to avoid path traversal
-	if !isSafeName(base) {
-		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid base: allowed [A-Za-z0-9._-] and no '..' or path separators"})
+	// Validate the name to avoid path traversal
+	selector, param := base, "base"
+	if groupName != "" {
+		selector, param = groupName, "group"
+	}
+	if !isSafeName(selector) {
+		writeJSON(c, http.StatusBadRequest, errorResp{Error: "invalid " + param + ": allowed [A-Za-z0-9._-] and no '..' or path separators"})
 		return
 	}
 
@@ -1613,37 +2680,117 @@ func (r *Router) handleProcessMetricsGroup(c *gin.Context) {
 		return
 	}
 
-	allMetrics := r.mgr.GetAllProcessMetrics()
-	groupMetrics := make(map[string]interface{})
-	var totalCPU float64
-	var totalMemory float64
-	var processCount int
+	naming := r.mgr.InstanceNaming()
+	var members map[string]bool
+	if groupName != "" {
+		var group *core.ManagerInstanceGroup
+		for _, g := range r.mgr.ListInstanceGroups() {
+			if g.Name == groupName {
+				group = &g
+				break
+			}
+		}
+		if group == nil {
+			writeJSON(c, http.StatusNotFound, errorResp{Error: "group " + groupName + " not found"})
+			return
+		}
+		members = make(map[string]bool)
+		for _, member := range group.Members {
+			for _, name := range naming.Names(member.Name, member.Instances) {
+				members[name] = true
+			}
+		}
+	} else if first, ok := r.registeredName(base); ok {
+		// base may also name a single instance, such as web-1
+		names := []string{base}
+		if b, err := r.mgr.ProcessBase(first); err == nil && b == base {
+			if spec, err := r.mgr.GetSpec(first); err == nil {
+				names = naming.Names(base, spec.Instances)
+			}
+		}
+		members = make(map[string]bool, len(names))
+		for _, name := range names {
+			members[name] = true
+		}
+	}
+	// Without a registered process set (e.g. just unregistered), fall back
+	// to names that parse back to base under the instance naming scheme.
+	belongs := func(name string) bool {
+		if members != nil {
+			return members[name]
+		}
+		parsed, _, ok := naming.Parse(name)
+		return name == base || (ok && parsed == base)
+	}
 
-	// Filter metrics for processes matching the base pattern
-	for name, metrics := range allMetrics {
-		// Check if this process belongs to the base group (e.g., demo-app-1, demo-app-2 belong to demo-app)
-		if strings.HasPrefix(name, base+"-") || name == base {
+	groupMetrics := make(map[string]core.ProcessMetrics)
+	var cpu, memory []float64
+	for name, metrics := range r.mgr.GetAllProcessMetrics() {
+		if belongs(name) {
 			groupMetrics[name] = metrics
-			totalCPU += metrics.CPUPercent
-			totalMemory += metrics.MemoryMB
-			processCount++
+			cpu = append(cpu, metrics.CPUPercent)
+			memory = append(memory, metrics.MemoryMB)
 		}
 	}
 
-	if processCount == 0 {
+	if len(groupMetrics) == 0 {
 		writeJSON(c, http.StatusNotFound, errorResp{Error: "no processes found for base pattern"})
 		return
 	}
 
+	cpuStats, memoryStats := summarize(cpu), summarize(memory)
 	result := map[string]interface{}{
-		"base":          base,
-		"process_count": processCount,
-		"total_cpu":     totalCPU,
-		"total_memory":  totalMemory,
-		"avg_cpu":       totalCPU / float64(processCount),
-		"avg_memory":    totalMemory / float64(processCount),
+		"process_count": len(groupMetrics),
+		"total_cpu":     cpuStats.total,
+		"total_memory":  memoryStats.total,
+		"avg_cpu":       cpuStats.avg,
+		"avg_memory":    memoryStats.avg,
+		"min_cpu":       cpuStats.min,
+		"max_cpu":       cpuStats.max,
+		"p50_cpu":       cpuStats.p50,
+		"p90_cpu":       cpuStats.p90,
+		"p99_cpu":       cpuStats.p99,
+		"min_memory":    memoryStats.min,
+		"max_memory":    memoryStats.max,
+		"p50_memory":    memoryStats.p50,
+		"p90_memory":    memoryStats.p90,
+		"p99_memory":    memoryStats.p99,
 		"processes":     groupMetrics,
 	}
+	if groupName != "" {
+		result["group"] = groupName
+	} else {
+		result["base"] = base
+	}
 
 	writeJSON(c, http.StatusOK, result)
 }
+
+// sampleStats summarizes the values of one metric across processes.
+type sampleStats struct {
+	total, avg, min, max, p50, p90, p99 float64
+}
+
+// summarize computes sampleStats of a non-empty sample. Percentiles use the
+// nearest-rank method, so they are always one of the values.
+func summarize(values []float64) sampleStats {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	var total float64
+	for _, v := range sorted {
+		total += v
+	}
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		return sorted[max(i, 0)]
+	}
+	return sampleStats{
+		total: total,
+		avg:   total / float64(len(sorted)),
+		min:   sorted[0],
+		max:   sorted[len(sorted)-1],
+		p50:   rank(50),
+		p90:   rank(90),
+		p99:   rank(99),
+	}
+}
@@ -4,11 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +23,9 @@ import (
 	"github.com/loykin/provisr/core"
 	corehistory "github.com/loykin/provisr/core/history"
 	"github.com/loykin/provisr/internal/config"
+	"github.com/loykin/provisr/internal/unixsock"
+	"github.com/loykin/provisr/internal/version"
+	apiwire "github.com/loykin/provisr/pkg/api"
 )
 
 type fakeHistoryReader struct {
@@ -274,6 +284,284 @@ func TestStatusUnknown(t *testing.T) {
 	}
 }
 
+func TestStatusFormats(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	if err := mgr.Register(core.Spec{Name: "fmt-demo", Command: "sleep 5"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	mgr.SetInstanceGroups([]core.ManagerInstanceGroup{{Name: "fmt", Members: []core.Spec{{Name: "fmt-demo"}}}})
+	h := NewRouter(mgr, "").Handler()
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/status?name=fmt-demo", "")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("default: expected JSON, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	rec = get("/status?wildcard=fmt-*", "text/plain")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("Accept text/plain: got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if body := rec.Body.String(); !strings.HasPrefix(body, "NAME") || !strings.Contains(body, "fmt-demo") {
+		t.Fatalf("expected a table, got %q", body)
+	}
+	rec = get("/status?name=fmt-demo&detailed=true&format=text", "application/json")
+	if body := rec.Body.String(); !strings.HasPrefix(body, "name=fmt-demo state=running running=true") {
+		t.Fatalf("expected key=value text, got %q", body)
+	}
+	rec = get("/group/status?group=fmt&format=table", "")
+	if body := rec.Body.String(); rec.Code != http.StatusOK || !strings.Contains(body, "fmt-demo") {
+		t.Fatalf("group table: got %d %q", rec.Code, body)
+	}
+	if rec = get("/status?name=fmt-demo&format=yaml", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown format: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestStatusListsStoppedProcesses(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	if err := mgr.Register(core.Spec{Name: "inv-up", Command: "sleep 5"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := mgr.Add(core.Spec{Name: "inv-down", Command: "sleep 5"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	h := NewRouter(mgr, "").Handler()
+
+	names := func(path string) map[string]core.Status {
+		t.Helper()
+		rec := doReq(t, h, http.MethodGet, path, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d %s", path, rec.Code, rec.Body.String())
+		}
+		var sts []core.Status
+		if err := json.Unmarshal(rec.Body.Bytes(), &sts); err != nil {
+			t.Fatal(err)
+		}
+		out := make(map[string]core.Status, len(sts))
+		for _, st := range sts {
+			out[st.Name] = st
+		}
+		return out
+	}
+
+	all := names("/status?wildcard=inv-*")
+	if down, ok := all["inv-down"]; !ok || down.Running || down.State != "stopped" {
+		t.Fatalf("stopped process missing from status: %+v", all)
+	}
+	if _, ok := all["inv-up"]; !ok {
+		t.Fatalf("running process missing from status: %+v", all)
+	}
+	running := names("/status?wildcard=inv-*&running=true")
+	if _, ok := running["inv-down"]; ok || len(running) != 1 {
+		t.Fatalf("running=true should leave out stopped processes: %+v", running)
+	}
+	if rec := doReq(t, h, http.MethodGet, "/status?wildcard=inv-*&running=maybe", nil); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid running flag: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestStatusFiltersByLabel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	for _, spec := range []core.Spec{
+		{Name: "pay-api", Command: "sleep 5", Labels: map[string]string{"team": "payments", "env": "prod"}},
+		{Name: "pay-worker", Command: "sleep 5", Instances: 2, Labels: map[string]string{"team": "payments", "env": "staging"}},
+		{Name: "search", Command: "sleep 5", Labels: map[string]string{"team": "search"}},
+		{Name: "plain", Command: "sleep 5"},
+	} {
+		if err := mgr.Add(spec); err != nil {
+			t.Fatalf("add %s: %v", spec.Name, err)
+		}
+	}
+	h := NewRouter(mgr, "").Handler()
+
+	names := func(path string) string {
+		t.Helper()
+		rec := doReq(t, h, http.MethodGet, path, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d %s", path, rec.Code, rec.Body.String())
+		}
+		var sts []core.Status
+		if err := json.Unmarshal(rec.Body.Bytes(), &sts); err != nil {
+			t.Fatal(err)
+		}
+		out := make([]string, len(sts))
+		for i, st := range sts {
+			out[i] = st.Name
+		}
+		return strings.Join(out, ",")
+	}
+
+	cases := map[string]string{
+		"/status?label=team=payments":                       "pay-api,pay-worker-1,pay-worker-2",
+		"/status?label=team=payments&label=env=prod":        "pay-api",
+		"/status?label=team=payments,env=staging":           "pay-worker-1,pay-worker-2",
+		"/status?wildcard=pay-worker-*&label=team=payments": "pay-worker-1,pay-worker-2",
+		"/status?regex=^s&label=team=payments":              "",
+		"/status?label=team=nobody":                         "",
+	}
+	for path, want := range cases {
+		if got := names(path); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+
+	for _, path := range []string{"/status?label=team", "/status?name=search&label=team=search"} {
+		if rec := doReq(t, h, http.MethodGet, path, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestStatusProjectsFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	if err := mgr.Add(core.Spec{Name: "web", Command: "sleep 5", Instances: 2}); err != nil {
+		t.Fatal(err)
+	}
+	h := NewRouter(mgr, "").Handler()
+
+	get := func(path string) string {
+		t.Helper()
+		rec := doReq(t, h, http.MethodGet, path, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d %s", path, rec.Code, rec.Body.String())
+		}
+		return strings.TrimSpace(rec.Body.String())
+	}
+
+	cases := map[string]string{
+		"/status?name=web-1&fields=name,state":                    `{"name":"web-1","state":"stopped"}`,
+		"/status?name=web-1&fields=name,%20pid,name":              `{"name":"web-1","pid":0}`,
+		"/status?name=web-1&fields=name,pid,running&omit_empty=1": `{"name":"web-1"}`,
+		"/status?wildcard=web-*&fields=name":                      `[{"name":"web-1"},{"name":"web-2"}]`,
+	}
+	for path, want := range cases {
+		if got := get(path); got != want {
+			t.Errorf("%s = %s, want %s", path, got, want)
+		}
+	}
+
+	// omit_empty alone keeps every non-zero field.
+	var st map[string]any
+	if err := json.Unmarshal([]byte(get("/status?name=web-1&omit_empty=true")), &st); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := st["pid"]; ok || st["name"] != "web-1" || st["state"] != "stopped" {
+		t.Errorf("omit_empty status = %v", st)
+	}
+
+	for _, path := range []string{
+		"/status?name=web-1&fields=name,bogus",
+		"/status?name=web-1&omit_empty=maybe",
+		"/status?name=web-1&fields=name&detailed=true",
+	} {
+		if rec := doReq(t, h, http.MethodGet, path, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestStatusPaginates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	if err := mgr.Add(core.Spec{Name: "web", Command: "sleep 5", Instances: 5}); err != nil {
+		t.Fatal(err)
+	}
+	h := NewRouter(mgr, "").Handler()
+
+	cases := map[string]struct{ names, total string }{
+		"/status?wildcard=web-*&limit=2":           {"web-1,web-2", "5"},
+		"/status?wildcard=web-*&offset=2&limit=2":  {"web-3,web-4", "5"},
+		"/status?wildcard=web-*&offset=4":          {"web-5", "5"},
+		"/status?wildcard=web-*&offset=9":          {"", "5"},
+		"/status?wildcard=web-*":                   {"web-1,web-2,web-3,web-4,web-5", ""},
+		"/status?base=web&limit=1&fields=name,pid": {"web-1", "5"},
+	}
+	for path, want := range cases {
+		rec := doReq(t, h, http.MethodGet, path, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d %s", path, rec.Code, rec.Body.String())
+		}
+		var sts []core.Status
+		if err := json.Unmarshal(rec.Body.Bytes(), &sts); err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, len(sts))
+		for i, st := range sts {
+			names[i] = st.Name
+		}
+		if got := strings.Join(names, ","); got != want.names {
+			t.Errorf("%s = %q, want %q", path, got, want.names)
+		}
+		if got := rec.Header().Get("X-Total-Count"); got != want.total {
+			t.Errorf("%s: X-Total-Count = %q, want %q", path, got, want.total)
+		}
+	}
+
+	for _, path := range []string{"/status?wildcard=*&limit=-1", "/status?wildcard=*&offset=x"} {
+		if rec := doReq(t, h, http.MethodGet, path, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestStopByPIDAPI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires Unix sleep")
+	}
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	t.Cleanup(func() { _ = mgr.Shutdown() })
+	if err := mgr.Register(core.Spec{Name: "pid-demo", Command: "sleep 30"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	h := NewRouter(mgr, "").Handler()
+	st, err := mgr.Status("pid-demo")
+	if err != nil || st.PID == 0 {
+		t.Fatalf("no pid: %+v %v", st, err)
+	}
+
+	for path, want := range map[string]int{
+		"/stop?pid=abc":                          http.StatusBadRequest,
+		"/stop?pid=1&name=pid-demo":              http.StatusBadRequest,
+		"/stop?pid=" + strconv.Itoa(os.Getpid()): http.StatusNotFound,
+	} {
+		if rec := doReq(t, h, http.MethodPost, path, nil); rec.Code != want {
+			t.Fatalf("%s: expected %d, got %d %s", path, want, rec.Code, rec.Body.String())
+		}
+	}
+	rec := doReq(t, h, http.MethodPost, "/stop?pid="+strconv.Itoa(st.PID)+"&wait=2s", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stop by pid: %d %s", rec.Code, rec.Body.String())
+	}
+	if st, _ := mgr.Status("pid-demo"); st.Running {
+		t.Fatal("pid-demo is still running")
+	}
+}
+
 func TestGroupsAPI(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mgr := core.New()
@@ -311,6 +599,87 @@ func TestRuntimeStatusDoesNotExposeSecrets(t *testing.T) {
 	}
 }
 
+// pingHistory is a history reader whose store answers Ping with err.
+type pingHistory struct {
+	corehistory.Reader
+	err error
+}
+
+func (p pingHistory) Ping(context.Context) error { return p.err }
+
+func TestHealthzAndReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	readyz := func(h http.Handler) (int, apiwire.Readiness) {
+		t.Helper()
+		rec := doReq(t, h, http.MethodGet, "/api/readyz", nil)
+		var body apiwire.Readiness
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode readyz %s: %v", rec.Body.String(), err)
+		}
+		return rec.Code, body
+	}
+
+	authCfg := &config.AuthConfig{Enabled: true, Store: config.AuthStoreConfig{Type: "memory"}}
+	cron := core.NewCronScheduler(core.NewJobManager(core.New()))
+	r, err := newRouterFromConfig(core.New(), "/api", authCfg, "", cron, pingHistory{})
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	h := r.Handler()
+
+	// Probes need no token, even with auth on.
+	if rec := doReq(t, h, http.MethodGet, "/api/healthz", nil); rec.Code != http.StatusOK {
+		t.Fatalf("healthz = %d: %s", rec.Code, rec.Body.String())
+	}
+	code, body := readyz(h)
+	want := map[string]string{"shutdown": "ok", "cron_scheduler": "ok", "auth_store": "ok", "history_store": "ok"}
+	if code != http.StatusOK || !body.Ready || !reflect.DeepEqual(body.Checks, want) {
+		t.Fatalf("healthy readyz = %d %+v", code, body)
+	}
+
+	// A store that went away makes the daemon unready, but still alive.
+	r.historyReader = pingHistory{err: errors.New("connection refused")}
+	_ = r.authService.Close()
+	code, body = readyz(h)
+	if code != http.StatusServiceUnavailable || body.Ready || body.Checks["history_store"] != "connection refused" ||
+		body.Checks["auth_store"] == "ok" || body.Checks["shutdown"] != "ok" {
+		t.Fatalf("degraded readyz = %d %+v", code, body)
+	}
+	if rec := doReq(t, h, http.MethodGet, "/api/healthz", nil); rec.Code != http.StatusOK {
+		t.Fatalf("healthz with a store down = %d, want 200", rec.Code)
+	}
+
+	_ = cron.Stop()
+	r.markShuttingDown()
+	if _, body = readyz(h); body.Checks["cron_scheduler"] != "stopped" || body.Checks["shutdown"] != "shutting down" {
+		t.Fatalf("readyz while shutting down = %+v", body)
+	}
+}
+
+func TestVersionReturnsBuildInfo(t *testing.T) {
+	defer func(v, c, d string) { version.Version, version.Commit, version.BuildDate = v, c, d }(version.Version, version.Commit, version.BuildDate)
+	version.Version, version.Commit, version.BuildDate = "v1.2.3", "0a1b2c3", "2026-01-02T03:04:05Z"
+
+	h := setupRouter(t, "/api")
+	rec := doReq(t, h, http.MethodGet, "/api/version", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("version expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var got apiwire.VersionInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode version: %v", err)
+	}
+	want := apiwire.VersionInfo{Version: "v1.2.3", Commit: "0a1b2c3", BuildDate: "2026-01-02T03:04:05Z", GoVersion: runtime.Version()}
+	if got != want {
+		t.Fatalf("version = %+v, want %+v", got, want)
+	}
+
+	// Every response carries the version for clients to check.
+	if rec := doReq(t, h, http.MethodGet, "/api/status?wildcard=*", nil); rec.Header().Get(apiwire.VersionHeader) != "v1.2.3" {
+		t.Fatalf("%s header = %q, want v1.2.3", apiwire.VersionHeader, rec.Header().Get(apiwire.VersionHeader))
+	}
+}
+
 func TestTemplatePreviewAPI(t *testing.T) {
 	h := setupRouter(t, "")
 	rec := doReq(t, h, http.MethodGet, "/templates", nil)
@@ -349,6 +718,9 @@ func TestAPIEndpointsRegisterAllIncludesManagerBackedSurface(t *testing.T) {
 	}{
 		{http.MethodGet, "/api/processes/embedded/spec", nil},
 		{http.MethodGet, "/api/processes/embedded/logs", nil},
+		{http.MethodPost, "/api/restart?name=embedded", nil},
+		{http.MethodPost, "/api/signal?name=embedded&signal=TERM", nil},
+		{http.MethodPost, "/api/scale?name=embedded&instances=1", nil},
 		{http.MethodGet, "/api/templates", nil},
 		{http.MethodGet, "/api/templates/worker", nil},
 		{http.MethodPost, "/api/update", core.Spec{Name: "embedded", Command: "sleep 5", Instances: 1}},
@@ -387,12 +759,94 @@ func TestJobsAPI(t *testing.T) {
 		t.Fatalf("get job expected 200, got %d: %s", rec.Code, rec.Body.String())
 	}
 
+	rec = doReq(t, h, http.MethodGet, "/jobs/job-api/instances", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("job instances expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var instances []core.JobInstanceStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &instances); err != nil {
+		t.Fatalf("failed to parse instances json: %v", err)
+	}
+	if len(instances) != 1 || instances[0].Attempt != 1 {
+		t.Fatalf("unexpected instances response: %+v", instances)
+	}
+	if rec := doReq(t, h, http.MethodGet, "/jobs/missing/instances", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("instances of unknown job expected 404, got %d", rec.Code)
+	}
+
 	rec = doReq(t, h, http.MethodDelete, "/jobs/job-api", nil)
 	if rec.Code != http.StatusOK {
 		t.Fatalf("delete job expected 200, got %d: %s", rec.Code, rec.Body.String())
 	}
 }
 
+func TestSuspendResumeJobAPI(t *testing.T) {
+	h := setupRouter(t, "")
+	completions := int32(5)
+	spec := core.JobSpec{Name: "job-suspend", Command: "sleep 5", Completions: &completions}
+	if rec := doReq(t, h, http.MethodPost, "/jobs", spec); rec.Code != http.StatusOK {
+		t.Fatalf("create job expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for _, step := range []struct{ action, phase string }{
+		{"suspend", "Suspended"},
+		{"resume", "Running"},
+	} {
+		rec := doReq(t, h, http.MethodPost, "/jobs/job-suspend/"+step.action, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s expected 200, got %d: %s", step.action, rec.Code, rec.Body.String())
+		}
+		var job struct {
+			Status struct {
+				Phase string `json:"phase"`
+			} `json:"status"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+			t.Fatalf("failed to parse job json: %v", err)
+		}
+		if job.Status.Phase != step.phase {
+			t.Fatalf("after %s phase = %q, want %q", step.action, job.Status.Phase, step.phase)
+		}
+	}
+
+	if rec := doReq(t, h, http.MethodPost, "/jobs/missing/suspend", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("suspend of unknown job expected 404, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestJobInstanceLogsAPI(t *testing.T) {
+	h := setupRouter(t, "")
+	spec := core.JobSpec{Name: "job-logs", Command: `sh -c 'echo migrated'`, CaptureOutput: true}
+	if rec := doReq(t, h, http.MethodPost, "/jobs", spec); rec.Code != http.StatusOK {
+		t.Fatalf("create job expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	rec := doReq(t, h, http.MethodGet, "/jobs/job-logs/instances/1/logs", nil)
+	for rec.Code == http.StatusNotFound && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		rec = doReq(t, h, http.MethodGet, "/jobs/job-logs/instances/1/logs", nil)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("instance logs expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var out core.JobOutput
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("failed to parse output json: %v", err)
+	}
+	if out.Output != "migrated\n" || out.Attempt != 1 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+
+	// The instance name works as well as the attempt number.
+	if rec := doReq(t, h, http.MethodGet, "/jobs/job-logs/instances/"+out.Instance+"/logs", nil); rec.Code != http.StatusOK {
+		t.Fatalf("logs by instance name expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := doReq(t, h, http.MethodGet, "/jobs/job-logs/instances/2/logs", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("logs of unknown attempt expected 404, got %d", rec.Code)
+	}
+}
+
 func TestWildcardStatusAndStop(t *testing.T) {
 	h := setupRouter(t, "")
 	// start 2 instances via API
@@ -428,6 +882,441 @@ func TestWildcardStatusAndStop(t *testing.T) {
 	}
 }
 
+func TestRegexStatusAndStop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	for _, name := range []string{"rx-api", "rx-worker", "other"} {
+		if err := mgr.Register(core.Spec{Name: name, Command: "sleep 5"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewRouter(mgr, "").Handler()
+
+	rec := doReq(t, h, http.MethodGet, "/status?regex="+url.QueryEscape("^rx-(api|worker)$"), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var arr []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &arr); err != nil {
+		t.Fatalf("failed to parse json: %v", err)
+	}
+	if len(arr) != 2 {
+		t.Fatalf("expected 2 statuses, got %d", len(arr))
+	}
+
+	rec = doReq(t, h, http.MethodPost, "/stop?regex="+url.QueryEscape("^rx-"), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stop expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if n, _ := mgr.CountRegex("^rx-"); n != 0 {
+		t.Fatalf("expected rx-* stopped, %d still running", n)
+	}
+	if n, _ := mgr.Count("other"); n != 1 {
+		t.Fatalf("unmatched process should keep running")
+	}
+
+	rec = doReq(t, h, http.MethodGet, "/status?regex="+url.QueryEscape("(bad"), nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid regex expected 400, got %d", rec.Code)
+	}
+	rec = doReq(t, h, http.MethodGet, "/status?regex="+strings.Repeat("a", 300), nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("overlong regex expected 400, got %d", rec.Code)
+	}
+	rec = doReq(t, h, http.MethodPost, "/start?regex=rx", nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("start by regex expected 400, got %d", rec.Code)
+	}
+	rec = doReq(t, h, http.MethodGet, "/status?regex=rx&name=other", nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("regex with name expected 400, got %d", rec.Code)
+	}
+}
+
+func TestBatchStartStop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	for _, name := range []string{"alpha", "bravo", "charlie"} {
+		if err := mgr.Register(core.Spec{Name: name, Command: "sleep 5"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewRouter(mgr, "").Handler()
+
+	decode := func(rec *httptest.ResponseRecorder) apiwire.BatchResponse {
+		t.Helper()
+		var resp apiwire.BatchResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse json: %v (%s)", err, rec.Body.String())
+		}
+		return resp
+	}
+
+	rec := doReq(t, h, http.MethodPost, "/batch/stop", apiwire.BatchRequest{Names: []string{"alpha", "charlie"}, Wait: "2s"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("batch stop expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	resp := decode(rec)
+	if len(resp.Results) != 2 || resp.Results[0].Name != "alpha" || resp.Results[1].Name != "charlie" {
+		t.Fatalf("results should follow request order: %+v", resp.Results)
+	}
+	if n, _ := mgr.Count("bravo"); n != 1 {
+		t.Fatalf("bravo was not in the batch and should keep running")
+	}
+	for _, name := range []string{"alpha", "charlie"} {
+		if st, _ := mgr.Status(name); st.Running {
+			t.Fatalf("%s should be stopped", name)
+		}
+	}
+
+	// Partial success: one unknown name.
+	rec = doReq(t, h, http.MethodPost, "/batch/start", apiwire.BatchRequest{Names: []string{"alpha", "missing"}})
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("partial batch start expected 207, got %d: %s", rec.Code, rec.Body.String())
+	}
+	resp = decode(rec)
+	if !resp.Results[0].OK || resp.Results[1].OK || resp.Results[1].Error == "" {
+		t.Fatalf("unexpected per-name results: %+v", resp.Results)
+	}
+	if st, _ := mgr.Status("alpha"); !st.Running {
+		t.Fatalf("alpha should be running after batch start")
+	}
+
+	for _, body := range []apiwire.BatchRequest{
+		{},
+		{Names: []string{"../etc"}},
+		{Names: []string{"alpha", "alpha"}},
+		{Names: []string{"alpha"}, Wait: "soon"},
+	} {
+		rec = doReq(t, h, http.MethodPost, "/batch/stop", body)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("invalid batch %+v expected 400, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestRestart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	if err := mgr.RegisterN(core.Spec{Name: "web", Command: "sleep 5", Instances: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Register(core.Spec{Name: "idle", Command: "sleep 5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Stop("idle", 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetInstanceGroups([]core.ManagerInstanceGroup{
+		{Name: "all", Members: []core.Spec{{Name: "web", Instances: 2}, {Name: "idle"}}},
+	})
+	h := NewRouter(mgr, "").Handler()
+
+	restart := func(query string, wantCode int) apiwire.RestartResponse {
+		t.Helper()
+		rec := doReq(t, h, http.MethodPost, "/restart?"+query, nil)
+		if rec.Code != wantCode {
+			t.Fatalf("restart?%s expected %d, got %d: %s", query, wantCode, rec.Code, rec.Body.String())
+		}
+		var resp apiwire.RestartResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse json: %v (%s)", err, rec.Body.String())
+		}
+		return resp
+	}
+
+	before, _ := mgr.Status("web-1")
+	resp := restart("base=web&wait=2s", http.StatusOK)
+	if len(resp.Results) != 2 || resp.Results[0].Name != "web-1" || resp.Results[1].Name != "web-2" {
+		t.Fatalf("unexpected results: %+v", resp.Results)
+	}
+	after, _ := mgr.Status("web-1")
+	if !after.Running || after.PID == before.PID || resp.Results[0].PID != after.PID {
+		t.Fatalf("web-1 not restarted: before pid %d, after %+v, reported %d", before.PID, after, resp.Results[0].PID)
+	}
+
+	// A stopped process is started.
+	resp = restart("name=idle", http.StatusOK)
+	if st, _ := mgr.Status("idle"); !st.Running || resp.Results[0].PID != st.PID {
+		t.Fatalf("idle should be running with the reported pid: %+v, %+v", st, resp.Results)
+	}
+
+	resp = restart("group=all", http.StatusOK)
+	if len(resp.Results) != 3 {
+		t.Fatalf("group restart should cover every instance: %+v", resp.Results)
+	}
+	for _, res := range resp.Results {
+		if st, _ := mgr.Status(res.Name); !res.OK || !st.Running || st.PID != res.PID {
+			t.Fatalf("%s not running after group restart: %+v, %+v", res.Name, res, st)
+		}
+	}
+
+	resp = restart("name=missing", http.StatusMultiStatus)
+	if resp.Results[0].OK || resp.Results[0].Error == "" {
+		t.Fatalf("missing process should fail: %+v", resp.Results)
+	}
+	for _, query := range []string{"", "regex=web", "group=all&name=web-1", "group=../etc"} {
+		if rec := doReq(t, h, http.MethodPost, "/restart?"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Fatalf("restart?%s expected 400, got %d", query, rec.Code)
+		}
+	}
+	if rec := doReq(t, h, http.MethodPost, "/restart?base=nothing", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("restart of an unknown base expected 404, got %d", rec.Code)
+	}
+}
+
+func TestScale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	programsDir := t.TempDir()
+	r := NewRouter(mgr, "")
+	r.programsDir = programsDir
+	h := r.Handler()
+
+	if rec := doReq(t, h, http.MethodPost, "/register", core.Spec{Name: "worker", Command: "sleep 5", Instances: 2}); rec.Code != http.StatusOK {
+		t.Fatalf("register expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := doReq(t, h, http.MethodPost, "/scale?name=worker&instances=3", nil); rec.Code != http.StatusOK {
+		t.Fatalf("scale expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if n, _ := mgr.Count("worker"); n != 3 {
+		t.Fatalf("expected 3 running instances, got %d", n)
+	}
+	data, err := os.ReadFile(filepath.Join(programsDir, "worker.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var program struct {
+		Spec core.Spec `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &program); err != nil {
+		t.Fatal(err)
+	}
+	if program.Spec.Name != "worker" || program.Spec.Instances != 3 {
+		t.Fatalf("program file not updated: %s", data)
+	}
+
+	cases := []struct {
+		query string
+		code  int
+	}{
+		{"name=worker", http.StatusBadRequest},
+		{"name=worker&instances=0", http.StatusBadRequest},
+		{"name=worker-2&instances=2", http.StatusBadRequest},
+		{"instances=2", http.StatusBadRequest},
+		{"name=missing&instances=2", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		if rec := doReq(t, h, http.MethodPost, "/scale?"+tc.query, nil); rec.Code != tc.code {
+			t.Fatalf("scale?%s expected %d, got %d: %s", tc.query, tc.code, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell trap")
+	}
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	marker := filepath.Join(t.TempDir(), "hup")
+	if err := mgr.Register(core.Spec{Name: "reload", Command: fmt.Sprintf(`sh -c 'trap "echo hup >> %s" HUP; while :; do sleep 0.1; done'`, marker), StartDuration: 300 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Register(core.Spec{Name: "idle", Command: "sleep 5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Stop("idle", 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	h := NewRouter(mgr, "").Handler()
+
+	if rec := doReq(t, h, http.MethodPost, "/signal?name=reload&signal=HUP", nil); rec.Code != http.StatusOK {
+		t.Fatalf("signal expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if data, _ := os.ReadFile(marker); strings.Contains(string(data), "hup") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("HUP was not delivered")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if st, _ := mgr.Status("reload"); !st.Running {
+		t.Fatal("signalled process should keep running")
+	}
+
+	cases := []struct {
+		query string
+		code  int
+	}{
+		{"name=reload", http.StatusBadRequest},
+		{"name=reload&signal=NOPE", http.StatusBadRequest},
+		{"signal=HUP", http.StatusBadRequest},
+		{"name=../etc&signal=HUP", http.StatusBadRequest},
+		{"name=missing&signal=HUP", http.StatusNotFound},
+		{"name=idle&signal=HUP", http.StatusConflict},
+	}
+	for _, tc := range cases {
+		if rec := doReq(t, h, http.MethodPost, "/signal?"+tc.query, nil); rec.Code != tc.code {
+			t.Fatalf("signal?%s expected %d, got %d: %s", tc.query, tc.code, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestGroupRollingRestart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	if err := mgr.RegisterN(core.Spec{Name: "web", Command: "sleep 5", Instances: 2}); err != nil {
+		t.Fatal(err)
+	}
+	mgr.SetInstanceGroups([]core.ManagerInstanceGroup{
+		{Name: "front", Members: []core.Spec{{Name: "web", Instances: 2}}},
+	})
+	h := NewRouter(mgr, "").Handler()
+
+	before, _ := mgr.Status("web-1")
+	rec := doReq(t, h, http.MethodPost, "/group/rolling-restart?group=front&max_unavailable=1&wait=2s", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rolling restart expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp apiwire.RestartResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse json: %v (%s)", err, rec.Body.String())
+	}
+	if len(resp.Results) != 2 || resp.Results[0].Name != "web-1" || resp.Results[1].Name != "web-2" {
+		t.Fatalf("unexpected results: %+v", resp.Results)
+	}
+	if after, _ := mgr.Status("web-1"); !after.Running || after.PID == before.PID || resp.Results[0].PID != after.PID {
+		t.Fatalf("web-1 not restarted: before pid %d, after %+v, reported %d", before.PID, after, resp.Results[0].PID)
+	}
+
+	cases := []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusBadRequest},
+		{"group=../etc", http.StatusBadRequest},
+		{"group=front&max_unavailable=0", http.StatusBadRequest},
+		{"group=front&ready_timeout=soon", http.StatusBadRequest},
+		{"group=missing", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		if rec := doReq(t, h, http.MethodPost, "/group/rolling-restart?"+tc.query, nil); rec.Code != tc.code {
+			t.Fatalf("rolling-restart?%s expected %d, got %d: %s", tc.query, tc.code, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestDetailedStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	live := core.Spec{
+		Name:      "det-live",
+		Command:   "sleep 5",
+		Lifecycle: core.LifecycleHooks{PostStart: []core.Hook{{Name: "notify", Command: "true"}, {Name: "broken", Command: "exit 1", FailureMode: core.FailureModeIgnore}}},
+	}
+	if err := mgr.Register(live); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Register(core.Spec{Name: "det-exit", Command: "sh -c 'exit 3'"}); err != nil {
+		t.Fatal(err)
+	}
+	h := NewRouter(mgr, "").Handler()
+
+	// Plain status keeps its existing shape.
+	rec := doReq(t, h, http.MethodGet, "/status?name=det-live", nil)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "uptime_seconds") {
+		t.Fatalf("plain status changed: %d %s", rec.Code, rec.Body.String())
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	rec = doReq(t, h, http.MethodGet, "/status?name=det-live&detailed=true", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("detailed status expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var st apiwire.DetailedStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+		t.Fatalf("failed to parse json: %v (%s)", err, rec.Body.String())
+	}
+	if !st.Running || st.PID == 0 || st.UptimeSeconds <= 0 || st.LastExit != nil {
+		t.Fatalf("unexpected live status: %+v", st)
+	}
+	// Metrics are disabled: the payload says so instead of failing.
+	if st.MetricsEnabled || st.Resources != nil {
+		t.Fatalf("expected no resource snapshot without metrics: %+v", st)
+	}
+	if len(st.Hooks) != 2 || st.Hooks[0].Name != "notify" || st.Hooks[0].Runs != 1 || st.Hooks[0].Failures != 0 {
+		t.Fatalf("unexpected hook summaries: %+v", st.Hooks)
+	}
+	if st.Hooks[1].Phase != "post_start" || st.Hooks[1].Failures != 1 || st.Hooks[1].LastError == "" {
+		t.Fatalf("failed hook not summarized: %+v", st.Hooks[1])
+	}
+
+	rec = doReq(t, h, http.MethodGet, "/status?wildcard=det-exit&detailed=true", nil)
+	var list []apiwire.DetailedStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("failed to parse json: %v (%s)", err, rec.Body.String())
+	}
+	if len(list) != 1 || list[0].LastExit == nil || list[0].LastExit.Code != 3 || list[0].UptimeSeconds != 0 {
+		t.Fatalf("expected last exit code 3, got %+v", list)
+	}
+
+	rec = doReq(t, h, http.MethodGet, "/status?name=det-live&detailed=maybe", nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid detailed flag expected 400, got %d", rec.Code)
+	}
+}
+
+func TestDebugProcessesShowsPendingAsyncHooks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	spec := core.Spec{
+		Name:    "with-async",
+		Command: "sleep 5",
+		Lifecycle: core.LifecycleHooks{PostStart: []core.Hook{
+			{Name: "warmup", Command: "sleep 5", RunMode: core.RunModeAsync},
+		}},
+	}
+	if err := mgr.Register(spec); err != nil {
+		t.Fatal(err)
+	}
+	h := NewRouter(mgr, "").Handler()
+
+	rec := doReq(t, h, http.MethodGet, "/debug/processes?pattern=with-async", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("debug processes expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var infos []debugProcessInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &infos); err != nil {
+		t.Fatalf("failed to parse json: %v (%s)", err, rec.Body.String())
+	}
+	if len(infos) != 1 || len(infos[0].PendingAsyncHooks) != 1 || infos[0].PendingAsyncHooks[0].Name != "warmup" {
+		t.Fatalf("expected pending async hook warmup, got %s", rec.Body.String())
+	}
+}
+
+func TestRegisterRejectsUnknownUser(t *testing.T) {
+	h := setupRouter(t, "")
+	rec := doReq(t, h, http.MethodPost, "/register", core.Spec{Name: "as-ghost", Command: "true", User: "provisr-no-such-user"})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("register with unknown user expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "unknown user") {
+		t.Fatalf("expected unknown user error, got %s", rec.Body.String())
+	}
+}
+
 func TestStartByBase(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mgr := core.New()
@@ -552,3 +1441,33 @@ func TestNewServerStartClose(t *testing.T) {
 	// Close immediately; we don't assert more here, just exercise the code path
 	_ = srv.Close()
 }
+
+func TestNewServerListensOnUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "provisr.sock")
+	mgr := core.New()
+	srv, err := NewServer(config.ServerConfig{Listen: "unix://" + path, BasePath: "/api", SocketMode: "0640"}, mgr, nil)
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	defer func() { _ = srv.Close() }()
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("socket not created: %v", err)
+		}
+		if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0o640 {
+			t.Fatalf("unexpected socket mode %v", info.Mode())
+		}
+	}
+
+	client := &http.Client{Transport: unixsock.Transport(path), Timeout: 5 * time.Second}
+	resp, err := client.Get("http://unix/api/status?name=missing")
+	if err != nil {
+		t.Fatalf("request over socket: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown process, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/pkg/tracing"
+)
+
+func TestTracingPropagatesIncomingContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+
+	mgr := core.New()
+	mgr.SetTracer(tracing.NewTracer(tp))
+	if err := mgr.Register(core.Spec{Name: "traced", Command: "sleep 30"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	defer func() { _ = mgr.Unregister("traced", time.Second) }()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPost, "/api/stop?name=traced&wait=1s", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	NewRouter(mgr, "/api").Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stop: %d %s", rec.Code, rec.Body.String())
+	}
+
+	var server, stop sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		switch s.Name() {
+		case "POST /api/stop":
+			server = s
+		case "manager.stop":
+			stop = s
+		}
+	}
+	if server == nil || stop == nil {
+		t.Fatalf("missing spans: server=%v manager=%v", server != nil, stop != nil)
+	}
+	if got := server.SpanContext().TraceID().String(); got != traceID {
+		t.Fatalf("server span trace id = %s, want propagated %s", got, traceID)
+	}
+	if stop.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Fatal("manager.stop span is not a child of the request span")
+	}
+	var hasName bool
+	for _, kv := range server.Attributes() {
+		if kv == attribute.String("process.name", "traced") {
+			hasName = true
+		}
+	}
+	if !hasName {
+		t.Fatalf("server span lacks process.name: %v", server.Attributes())
+	}
+}
@@ -0,0 +1,58 @@
+package tls
+
+import (
+	"crypto/tls"
+
+	"github.com/loykin/provisr/internal/config"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// ACME directory URLs. Staging issues untrusted certificates under far
+// higher rate limits, for trying a setup out before switching to
+// production, the default.
+const (
+	LetsEncryptProductionURL = autocert.DefaultACMEDirectory
+	LetsEncryptStagingURL    = "https://acme-staging-v02.api.letsencrypt.org/directory"
+)
+
+// DefaultACMEHTTPListen is where HTTP-01 challenges are answered unless
+// acme.http_listen says otherwise. The CA always connects to port 80, so
+// another port only works behind a forward from it.
+const DefaultACMEHTTPListen = ":80"
+
+// ACMEHTTPListen returns the HTTP-01 challenge listen address of cfg.
+func ACMEHTTPListen(cfg *config.ACMEConfig) string {
+	if cfg.HTTPListen == "" {
+		return DefaultACMEHTTPListen
+	}
+	return cfg.HTTPListen
+}
+
+// newACMEManager returns an autocert manager that requests certificates
+// for cfg.Domains only and keeps them, and the account key, in cfg.CacheDir.
+func newACMEManager(cfg *config.ACMEConfig) *autocert.Manager {
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cfg.CacheDir),
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+		Email:      cfg.Email,
+	}
+	if cfg.DirectoryURL != "" {
+		m.Client = &acme.Client{DirectoryURL: cfg.DirectoryURL}
+	}
+	return m
+}
+
+// acmeTLSConfig is the server TLS config serving m's certificates, which
+// also answers TLS-ALPN-01 challenges, with the versions cfg sets.
+func acmeTLSConfig(m *autocert.Manager, cfg config.TLSConfig) (*tls.Config, error) {
+	minVer, maxVer, err := cfg.Versions()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := m.TLSConfig()
+	tlsConfig.MinVersion = minVer
+	tlsConfig.MaxVersion = maxVer
+	return tlsConfig, nil
+}
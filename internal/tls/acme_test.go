@@ -0,0 +1,61 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/loykin/provisr/internal/config"
+)
+
+func TestSetupTLSWithACME(t *testing.T) {
+	acme := &config.ACMEConfig{
+		Enabled:      true,
+		Domains:      []string{"provisr.example.com"},
+		CacheDir:     filepath.Join(t.TempDir(), "acme"),
+		DirectoryURL: LetsEncryptStagingURL,
+	}
+	tlsConfig, manager, err := SetupTLSWithACME(config.ServerConfig{TLS: &config.TLSConfig{
+		Enabled:    true,
+		CertFile:   "ignored.crt", // ACME takes precedence over cert files
+		KeyFile:    "ignored.key",
+		MinVersion: "1.2",
+		ACME:       acme,
+	}})
+	if err != nil {
+		t.Fatalf("SetupTLSWithACME: %v", err)
+	}
+	if manager == nil {
+		t.Fatal("expected an ACME manager")
+	}
+	if manager.Client == nil || manager.Client.DirectoryURL != LetsEncryptStagingURL {
+		t.Fatalf("directory URL not applied: %+v", manager.Client)
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS12 || !slices.Contains(tlsConfig.NextProtos, "acme-tls/1") {
+		t.Fatalf("unexpected tls config: min=%x protos=%v", tlsConfig.MinVersion, tlsConfig.NextProtos)
+	}
+	if err := manager.HostPolicy(context.Background(), "provisr.example.com"); err != nil {
+		t.Fatalf("configured domain rejected: %v", err)
+	}
+	if err := manager.HostPolicy(context.Background(), "other.example.com"); err == nil {
+		t.Fatal("unconfigured domain allowed")
+	}
+	if got := ACMEHTTPListen(acme); got != DefaultACMEHTTPListen {
+		t.Fatalf("http listen = %q, want %q", got, DefaultACMEHTTPListen)
+	}
+
+	// Disabled ACME falls back to the certificate files.
+	dir := t.TempDir()
+	acme.Enabled = false
+	tlsConfig, manager, err = SetupTLSWithACME(config.ServerConfig{TLS: &config.TLSConfig{
+		Enabled:      true,
+		Dir:          dir,
+		AutoGenerate: true,
+		ACME:         acme,
+	}})
+	if err != nil || manager != nil || tlsConfig == nil {
+		t.Fatalf("disabled acme: config=%v manager=%v err=%v", tlsConfig, manager, err)
+	}
+}
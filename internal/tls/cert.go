@@ -1,6 +1,9 @@
 package tls
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -11,6 +14,8 @@ import (
 	"net"
 	"os"
 	"time"
+
+	"github.com/loykin/provisr/internal/config"
 )
 
 // CertConfig holds configuration for certificate generation
@@ -23,15 +28,34 @@ type CertConfig struct {
 	CertPath     string
 	KeyPath      string
 	CACertPath   string
+	KeyType      string // config.KeyType*; default rsa2048
+}
+
+// generateKey creates a private key of keyType.
+func generateKey(keyType string) (crypto.Signer, error) {
+	switch keyType {
+	case "", config.KeyTypeRSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case config.KeyTypeRSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	case config.KeyTypeECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", keyType)
+	}
 }
 
 // GenerateSelfSignedCert generates a self-signed certificate and private key
 func GenerateSelfSignedCert(config CertConfig) error {
 	// Generate private key
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	privateKey, err := generateKey(config.KeyType)
 	if err != nil {
 		return fmt.Errorf("failed to generate private key: %w", err)
 	}
+	keyUsage := x509.KeyUsageDigitalSignature
+	if _, ok := privateKey.(*rsa.PrivateKey); ok {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
 
 	// Create certificate template
 	template := x509.Certificate{
@@ -42,7 +66,7 @@ func GenerateSelfSignedCert(config CertConfig) error {
 		},
 		NotBefore:             time.Now(),
 		NotAfter:              config.NotAfter,
-		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		KeyUsage:              keyUsage,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
 	}
@@ -52,13 +76,15 @@ func GenerateSelfSignedCert(config CertConfig) error {
 
 	// Add IP addresses
 	for _, ipStr := range config.IPAddresses {
-		if ip := net.ParseIP(ipStr); ip != nil {
-			template.IPAddresses = append(template.IPAddresses, ip)
+		ip := net.ParseIP(ipStr)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address %q", ipStr)
 		}
+		template.IPAddresses = append(template.IPAddresses, ip)
 	}
 
 	// Create the certificate
-	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, privateKey.Public(), privateKey)
 	if err != nil {
 		return fmt.Errorf("failed to create certificate: %w", err)
 	}
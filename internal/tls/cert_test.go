@@ -0,0 +1,108 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loykin/provisr/internal/config"
+)
+
+func readCert(t *testing.T, path string) *x509.Certificate {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read cert: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("no PEM block in certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return cert
+}
+
+func TestGenerateSelfSignedCertECDSAWithIPSAN(t *testing.T) {
+	dir := t.TempDir()
+	cfg := CertConfig{
+		CommonName:  "10.0.0.5",
+		IPAddresses: []string{"10.0.0.5", "fd00::5"},
+		NotAfter:    time.Now().Add(time.Hour),
+		CertPath:    filepath.Join(dir, tlsCrt),
+		KeyPath:     filepath.Join(dir, tlsKey),
+		KeyType:     config.KeyTypeECDSAP256,
+	}
+	if err := GenerateSelfSignedCert(cfg); err != nil {
+		t.Fatalf("GenerateSelfSignedCert: %v", err)
+	}
+
+	cert := readCert(t, cfg.CertPath)
+	if _, ok := cert.PublicKey.(*ecdsa.PublicKey); !ok {
+		t.Fatalf("public key is %T, want ECDSA", cert.PublicKey)
+	}
+	if cert.KeyUsage&x509.KeyUsageKeyEncipherment != 0 {
+		t.Fatal("ECDSA certificate must not claim key encipherment")
+	}
+	if err := cert.VerifyHostname("10.0.0.5"); err != nil {
+		t.Fatalf("IPv4 SAN: %v", err)
+	}
+	if !cert.IPAddresses[1].Equal(net.ParseIP("fd00::5")) {
+		t.Fatalf("IP SANs = %v", cert.IPAddresses)
+	}
+	if _, err := tls.LoadX509KeyPair(cfg.CertPath, cfg.KeyPath); err != nil {
+		t.Fatalf("key pair does not load: %v", err)
+	}
+
+	cfg.IPAddresses = []string{"not-an-ip"}
+	if err := GenerateSelfSignedCert(cfg); err == nil {
+		t.Fatal("expected an invalid IP address to be rejected")
+	}
+	cfg.IPAddresses, cfg.KeyType = nil, "dsa"
+	if err := GenerateSelfSignedCert(cfg); err == nil {
+		t.Fatal("expected an unknown key type to be rejected")
+	}
+}
+
+func TestAutoGenerateDefaultsToRSA(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := EasyTLSSetup("127.0.0.1:0", dir, true); err != nil {
+		t.Fatalf("EasyTLSSetup: %v", err)
+	}
+	cert := readCert(t, filepath.Join(dir, tlsCrt))
+	if _, ok := cert.PublicKey.(*rsa.PublicKey); !ok {
+		t.Fatalf("public key is %T, want RSA", cert.PublicKey)
+	}
+	if err := cert.VerifyHostname("127.0.0.1"); err != nil {
+		t.Fatalf("default IP SAN: %v", err)
+	}
+}
+
+func TestQuickSelfSignedTLSServesECDSA(t *testing.T) {
+	serverCfg, err := QuickSelfSignedTLS(t.TempDir())
+	if err != nil {
+		t.Fatalf("QuickSelfSignedTLS: %v", err)
+	}
+	state, err := handshake(serverCfg, &tls.Config{InsecureSkipVerify: true}) // #nosec 402
+	if err != nil {
+		t.Fatalf("handshake: %v", err)
+	}
+	cert := state.PeerCertificates[0]
+	if _, ok := cert.PublicKey.(*ecdsa.PublicKey); !ok {
+		t.Fatalf("public key is %T, want ECDSA", cert.PublicKey)
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		if err := cert.VerifyHostname(host); err != nil {
+			t.Errorf("%s: %v", host, err)
+		}
+	}
+}
@@ -53,6 +53,40 @@ func (b *Builder) WithAutoGenConfig(commonName string, dnsNames []string, validD
 	return b
 }
 
+// WithIPAddresses sets the IP address SANs of auto-generated certificates.
+func (b *Builder) WithIPAddresses(ips ...string) *Builder {
+	if b.cfg.AutoGen == nil {
+		b.cfg.AutoGen = &config.AutoGenTLS{}
+	}
+	b.cfg.AutoGen.IPAddresses = ips
+	return b
+}
+
+// WithKeyType sets the key type of auto-generated certificates, one of
+// the config.KeyType* constants.
+func (b *Builder) WithKeyType(keyType string) *Builder {
+	if b.cfg.AutoGen == nil {
+		b.cfg.AutoGen = &config.AutoGenTLS{}
+	}
+	b.cfg.AutoGen.KeyType = keyType
+	return b
+}
+
+// WithVersions sets the minimum and maximum protocol versions, "1.2" or
+// "1.3"; empty keeps the default.
+func (b *Builder) WithVersions(minVersion, maxVersion string) *Builder {
+	b.cfg.MinVersion = minVersion
+	b.cfg.MaxVersion = maxVersion
+	return b
+}
+
+// WithCipherSuites sets the TLS 1.2 cipher suites by their crypto/tls
+// names, replacing config.DefaultCipherSuites.
+func (b *Builder) WithCipherSuites(names ...string) *Builder {
+	b.cfg.CipherSuites = names
+	return b
+}
+
 // Build returns the configured TLS config
 func (b *Builder) Build() *config.TLSConfig {
 	return b.cfg
@@ -66,7 +100,9 @@ func (p Presets) Development(certDir string) *config.TLSConfig {
 	return NewTLSBuilder().
 		WithDir(certDir).
 		WithAutoGenerate(true).
-		WithAutoGenConfig("localhost", []string{"localhost", "127.0.0.1"}, 365).
+		WithAutoGenConfig("localhost", []string{"localhost"}, 365).
+		WithIPAddresses("127.0.0.1", "::1").
+		WithKeyType(config.KeyTypeECDSAP256).
 		Build()
 }
 
@@ -88,6 +124,8 @@ func (p Presets) Testing() (*config.TLSConfig, error) {
 		WithDir(tmpDir).
 		WithAutoGenerate(true).
 		WithAutoGenConfig("test", []string{"test", "localhost"}, 1).
+		WithIPAddresses("127.0.0.1", "::1").
+		WithKeyType(config.KeyTypeECDSAP256).
 		Build(), nil
 }
 
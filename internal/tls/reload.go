@@ -0,0 +1,114 @@
+package tls
+
+import (
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// reloadCheckInterval bounds how often the certificate files are checked
+// for changes; handshakes in between reuse the cached pair.
+const reloadCheckInterval = time.Second
+
+// fileStamp identifies one version of a file on disk.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// certReloader serves a certificate/key pair from disk and picks up a new
+// pair, e.g. one renewed by certbot or cert-manager, when either file
+// changes. A pair that fails to load, such as one caught half-written, is
+// ignored and the previous certificate stays in use until the files settle.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	stamps   [2]fileStamp // of certFile and keyFile when cert was loaded
+	lastSeen time.Time    // when the files were last checked
+}
+
+func newCertReloader(certFile, keyFile string) *certReloader {
+	return &certReloader{certFile: certFile, keyFile: keyFile, interval: reloadCheckInterval}
+}
+
+// GetCertificate is the tls.Config.GetCertificate callback.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cert != nil && time.Since(r.lastSeen) < r.interval {
+		return r.cert, nil
+	}
+	r.lastSeen = time.Now()
+
+	cert, stamps, err := r.load()
+	switch {
+	case err == nil:
+		r.cert, r.stamps = cert, stamps
+	case r.cert == nil:
+		return nil, err
+	default:
+		slog.Warn("TLS certificate reload failed, still serving the previous certificate",
+			"cert_file", r.certFile, "key_file", r.keyFile, "error", err)
+	}
+	return r.cert, nil
+}
+
+// load reads the pair if it changed since the cached one was loaded; an
+// unchanged pair returns the cached certificate. The files are stat'ed
+// before and after reading so a pair being rewritten isn't mixed up.
+func (r *certReloader) load() (*tls.Certificate, [2]fileStamp, error) {
+	before, err := r.stat()
+	if err != nil {
+		return nil, before, err
+	}
+	if r.cert != nil && before == r.stamps {
+		return r.cert, before, nil
+	}
+	baseDir := filepath.Dir(r.certFile)
+	certPEM, err := safeReadFile(baseDir, r.certFile)
+	if err != nil {
+		return nil, before, err
+	}
+	keyPEM, err := safeReadFile(baseDir, r.keyFile)
+	if err != nil {
+		return nil, before, err
+	}
+	after, err := r.stat()
+	if err != nil {
+		return nil, before, err
+	}
+	if after != before {
+		return nil, before, errors.New("certificate files changed while being read")
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, before, err
+	}
+	return &cert, before, nil
+}
+
+func (r *certReloader) stat() ([2]fileStamp, error) {
+	var stamps [2]fileStamp
+	var err error
+	if stamps[0], err = statFile(r.certFile); err != nil {
+		return stamps, err
+	}
+	stamps[1], err = statFile(r.keyFile)
+	return stamps, err
+}
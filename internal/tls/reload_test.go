@@ -0,0 +1,104 @@
+package tls
+
+import (
+	"crypto/tls"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writePair writes a self-signed pair for commonName and moves the file
+// times forward, so the change is seen even on coarse-mtime filesystems.
+func writePair(t *testing.T, certPath, keyPath, commonName string, at time.Time) {
+	t.Helper()
+	if err := GenerateSelfSignedCert(CertConfig{
+		CommonName: commonName,
+		DNSNames:   []string{"localhost"},
+		NotAfter:   time.Now().Add(time.Hour),
+		CertPath:   certPath,
+		KeyPath:    keyPath,
+	}); err != nil {
+		t.Fatalf("generate %s: %v", commonName, err)
+	}
+	for _, p := range []string{certPath, keyPath} {
+		if err := os.Chtimes(p, at, at); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+}
+
+// handshake runs a TLS handshake between a server using serverCfg and a
+// client using clientCfg over an in-memory connection.
+func handshake(serverCfg, clientCfg *tls.Config) (tls.ConnectionState, error) {
+	serverConn, clientConn := net.Pipe()
+	defer func() { _ = clientConn.Close() }()
+	go func() {
+		defer func() { _ = serverConn.Close() }()
+		_ = tls.Server(serverConn, serverCfg).Handshake()
+	}()
+	client := tls.Client(clientConn, clientCfg)
+	err := client.Handshake()
+	return client.ConnectionState(), err
+}
+
+// servedCommonName returns the common name of the certificate a server
+// using cfg presents.
+func servedCommonName(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+	state, err := handshake(cfg, &tls.Config{InsecureSkipVerify: true, ServerName: "localhost"}) // #nosec 402
+	if err != nil {
+		t.Fatalf("handshake: %v", err)
+	}
+	return state.PeerCertificates[0].Subject.CommonName
+}
+
+func TestCertReloadPicksUpRotatedPair(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, tlsCrt), filepath.Join(dir, tlsKey)
+	start := time.Now().Add(-time.Hour)
+	writePair(t, certPath, keyPath, "first", start)
+
+	cfg, err := createTLSConfig(certPath, keyPath, tls.VersionTLS12, tls.VersionTLS13)
+	if err != nil {
+		t.Fatalf("createTLSConfig: %v", err)
+	}
+	reloader := newCertReloader(certPath, keyPath)
+	reloader.interval = 0
+	cfg.GetCertificate = reloader.GetCertificate
+
+	if got := servedCommonName(t, cfg); got != "first" {
+		t.Fatalf("served %q, want first", got)
+	}
+
+	writePair(t, certPath, keyPath, "second", start.Add(time.Minute))
+	if got := servedCommonName(t, cfg); got != "second" {
+		t.Fatalf("served %q after rotation, want second", got)
+	}
+
+	// A half-written certificate keeps the previous pair in service.
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		t.Fatalf("read cert: %v", err)
+	}
+	if err := os.WriteFile(certPath, certPEM[:len(certPEM)/2], 0o600); err != nil {
+		t.Fatalf("truncate cert: %v", err)
+	}
+	if got := servedCommonName(t, cfg); got != "second" {
+		t.Fatalf("served %q with a half-written cert, want second", got)
+	}
+
+	writePair(t, certPath, keyPath, "third", start.Add(2*time.Minute))
+	if got := servedCommonName(t, cfg); got != "third" {
+		t.Fatalf("served %q after the rewrite finished, want third", got)
+	}
+}
+
+func TestCertReloadFirstLoadError(t *testing.T) {
+	dir := t.TempDir()
+	reloader := newCertReloader(filepath.Join(dir, tlsCrt), filepath.Join(dir, tlsKey))
+	if _, err := reloader.GetCertificate(nil); err == nil {
+		t.Fatal("expected an error for missing certificate files")
+	}
+}
@@ -2,6 +2,7 @@ package tls
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/loykin/provisr/internal/config"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 const (
@@ -18,34 +20,6 @@ const (
 	tlsKey   = "tls.key"
 )
 
-// parseTLSVersion parses TLS version string and returns the corresponding constant
-func parseTLSVersion(ver string) (uint16, bool) {
-	switch ver {
-	case "", "default":
-		return tls.VersionTLS13, false
-	case "1.2", "TLS1.2", "tls1.2":
-		return tls.VersionTLS12, true
-	case "1.3", "TLS1.3", "tls1.3":
-		return tls.VersionTLS13, true
-	default:
-		return 0, false
-	}
-}
-
-// resolveTLSVersions resolves minimum and maximum TLS versions from server config
-func resolveTLSVersions(cfg config.TLSConfig) (min uint16, max uint16) {
-	// Defaults: 1.3
-	min = tls.VersionTLS13
-	max = tls.VersionTLS13
-	if v, ok := parseTLSVersion(cfg.MinVersion); ok {
-		min = v
-	}
-	if v, ok := parseTLSVersion(cfg.MaxVersion); ok {
-		max = v
-	}
-	return
-}
-
 // safeReadFile reads file content safely within base directory
 func safeReadFile(baseDir, p string) ([]byte, error) {
 	clean := filepath.Clean(p)
@@ -59,42 +33,62 @@ func safeReadFile(baseDir, p string) ([]byte, error) {
 	return os.ReadFile(clean)
 }
 
-// getCertificationFunc returns a function that loads certificates dynamically
-func getCertificationFunc(certFile, keyFile string) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
-	baseDir := filepath.Dir(certFile)
-	return func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
-		if readCert, err := safeReadFile(baseDir, certFile); err != nil {
-			return nil, err
-		} else if readKey, err := safeReadFile(baseDir, keyFile); err != nil {
-			return nil, err
-		} else {
-			certificate, err := tls.X509KeyPair(readCert, readKey)
-			return &certificate, err
-		}
-	}
-}
-
 // SetupTLS configures TLS settings for the server with improved usability
 func SetupTLS(server config.ServerConfig) (*tls.Config, error) {
+	tlsConfig, _, err := SetupTLSWithACME(server)
+	return tlsConfig, err
+}
+
+// SetupTLSWithACME is SetupTLS that also returns the ACME manager when
+// [server.tls.acme] is enabled, so the caller can serve its HTTP-01
+// challenge handler. The manager is nil otherwise.
+func SetupTLSWithACME(server config.ServerConfig) (*tls.Config, *autocert.Manager, error) {
 	if server.TLS == nil || !server.TLS.Enabled {
-		return nil, nil
+		return nil, nil, nil
 	}
 
-	minVer, maxVer := resolveTLSVersions(*server.TLS)
+	var manager *autocert.Manager
+	var tlsConfig *tls.Config
+	var err error
+	if acme := server.TLS.ACME; acme != nil && acme.Enabled {
+		manager = newACMEManager(acme)
+		tlsConfig, err = acmeTLSConfig(manager, *server.TLS)
+	} else {
+		tlsConfig, err = serverCertificateConfig(server.TLS)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if tlsConfig.CipherSuites, err = server.TLS.CipherSuiteIDs(); err != nil {
+		return nil, nil, err
+	}
+	if err := applyClientAuth(tlsConfig, *server.TLS); err != nil {
+		return nil, nil, err
+	}
+	return tlsConfig, manager, nil
+}
+
+// serverCertificateConfig builds the certificate and version settings of
+// the server TLS config.
+func serverCertificateConfig(cfg *config.TLSConfig) (*tls.Config, error) {
+	minVer, maxVer, err := cfg.Versions()
+	if err != nil {
+		return nil, err
+	}
 
 	// Priority 1: Use specific cert/key files if provided
-	if server.TLS.CertFile != "" && server.TLS.KeyFile != "" {
-		return createTLSConfig(server.TLS.CertFile, server.TLS.KeyFile, minVer, maxVer)
+	if cfg.CertFile != "" && cfg.KeyFile != "" {
+		return createTLSConfig(cfg.CertFile, cfg.KeyFile, minVer, maxVer)
 	}
 
 	// Priority 2: Use directory-based certificates
-	if server.TLS.Dir != "" {
-		keyPath := filepath.Join(server.TLS.Dir, tlsKey)
-		certPath := filepath.Join(server.TLS.Dir, tlsCrt)
+	if cfg.Dir != "" {
+		keyPath := filepath.Join(cfg.Dir, tlsKey)
+		certPath := filepath.Join(cfg.Dir, tlsCrt)
 
 		// Auto-generate if enabled and certificates don't exist
-		if server.TLS.AutoGenerate && !certificatesExist(certPath, keyPath) {
-			if err := generateCertificate(server.TLS, server.TLS.Dir); err != nil {
+		if cfg.AutoGenerate && !certificatesExist(certPath, keyPath) {
+			if err := generateCertificate(cfg, cfg.Dir); err != nil {
 				return nil, fmt.Errorf("certificate generation failed: %w", err)
 			}
 		}
@@ -105,6 +99,45 @@ func SetupTLS(server config.ServerConfig) (*tls.Config, error) {
 	return nil, errors.New("TLS enabled but no valid certificate configuration found")
 }
 
+// applyClientAuth sets how tlsConfig asks clients for certificates. With a
+// client CA file, "request" verifies a certificate when one is presented;
+// "require" accepts any certificate, so it never identifies the client.
+func applyClientAuth(tlsConfig *tls.Config, cfg config.TLSConfig) error {
+	var pool *x509.CertPool
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(filepath.Clean(cfg.ClientCAFile))
+		if err != nil {
+			return fmt.Errorf("read client CA file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client CA file %s contains no PEM certificates", cfg.ClientCAFile)
+		}
+	}
+
+	switch cfg.ClientAuth {
+	case "", "none":
+		tlsConfig.ClientAuth = tls.NoClientCert
+		return nil
+	case "request":
+		tlsConfig.ClientAuth = tls.RequestClientCert
+		if pool != nil {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	case "require":
+		tlsConfig.ClientAuth = tls.RequireAnyClientCert
+	case "require_and_verify":
+		if pool == nil {
+			return errors.New("client_auth require_and_verify needs client_ca_file")
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return fmt.Errorf("invalid client_auth %q: must be none, request, require or require_and_verify", cfg.ClientAuth)
+	}
+	tlsConfig.ClientCAs = pool
+	return nil
+}
+
 // helper functions
 func getOrDefault(value, defaultValue string) string {
 	if value == "" {
@@ -134,26 +167,40 @@ func EasyTLSSetup(listen string, certDir string, autoGen bool) (*tls.Config, err
 	return SetupTLS(serverConfig)
 }
 
-// QuickSelfSignedTLS generates a quick self-signed certificate for testing
+// QuickSelfSignedTLS generates a quick self-signed ECDSA certificate for
+// testing, valid for localhost, 127.0.0.1 and ::1.
 func QuickSelfSignedTLS(certDir string) (*tls.Config, error) {
-	return EasyTLSSetup("localhost:8080", certDir, true)
+	return SetupTLS(config.ServerConfig{
+		Listen: "localhost:8080",
+		TLS:    Default.Development(certDir),
+	})
 }
 
 // createTLSConfig creates TLS configuration with certificate files
 func createTLSConfig(certPath, keyPath string, minVer, maxVer uint16) (*tls.Config, error) {
-	if minVer < tls.VersionTLS12 {
-		minVer = tls.VersionTLS12
-	}
-	if maxVer != 0 && maxVer < minVer {
-		return nil, fmt.Errorf("maximum TLS version must be at least TLS 1.2")
+	minVer, maxVer, err := clampVersions(minVer, maxVer)
+	if err != nil {
+		return nil, err
 	}
 	return &tls.Config{
-		GetCertificate: getCertificationFunc(certPath, keyPath),
+		GetCertificate: newCertReloader(certPath, keyPath).GetCertificate,
 		MinVersion:     minVer,
 		MaxVersion:     maxVer,
 	}, nil
 }
 
+// clampVersions raises minVer to at least TLS 1.2 and checks maxVer, if
+// set, isn't below it.
+func clampVersions(minVer, maxVer uint16) (uint16, uint16, error) {
+	if minVer < tls.VersionTLS12 {
+		minVer = tls.VersionTLS12
+	}
+	if maxVer != 0 && maxVer < minVer {
+		return 0, 0, fmt.Errorf("maximum TLS version must be at least TLS 1.2")
+	}
+	return minVer, maxVer, nil
+}
+
 // certificatesExist checks if both certificate files exist
 func certificatesExist(certPath, keyPath string) bool {
 	_, certErr := os.Stat(certPath)
@@ -176,8 +223,8 @@ func generateCertificate(tlsConfig *config.TLSConfig, destDir string) error {
 
 	commonName := getOrDefault(autoGen.CommonName, "localhost")
 	organization := getOrDefault(autoGen.Organization, "provisr")
-	dnsNames := getOrDefaultSlice(autoGen.DNSNames, []string{"localhost", "127.0.0.1"})
-	ipAddresses := getOrDefaultSlice(autoGen.IPAddresses, []string{"127.0.0.1"})
+	dnsNames := getOrDefaultSlice(autoGen.DNSNames, []string{"localhost"})
+	ipAddresses := getOrDefaultSlice(autoGen.IPAddresses, []string{"127.0.0.1", "::1"})
 
 	// Calculate expiration date
 	validDays := autoGen.ValidDays
@@ -196,5 +243,6 @@ func generateCertificate(tlsConfig *config.TLSConfig, destDir string) error {
 		CertPath:     filepath.Join(destDir, tlsCrt),
 		KeyPath:      filepath.Join(destDir, tlsKey),
 		CACertPath:   filepath.Join(destDir, tlsCaCrt),
+		KeyType:      autoGen.KeyType,
 	})
 }
@@ -0,0 +1,62 @@
+package tls
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/loykin/provisr/internal/config"
+)
+
+func TestSetupTLSProtocolVersions(t *testing.T) {
+	serverCfg, err := SetupTLS(config.ServerConfig{TLS: NewTLSBuilder().
+		WithDir(t.TempDir()).
+		WithAutoGenerate(true).
+		WithVersions("1.2", "").
+		Build()})
+	if err != nil {
+		t.Fatalf("SetupTLS: %v", err)
+	}
+	if serverCfg.MinVersion != tls.VersionTLS12 || serverCfg.MaxVersion != tls.VersionTLS13 {
+		t.Fatalf("versions = %x..%x, want TLS 1.2..1.3", serverCfg.MinVersion, serverCfg.MaxVersion)
+	}
+	if len(serverCfg.CipherSuites) != len(config.DefaultCipherSuites) {
+		t.Fatalf("cipher suites = %v, want the defaults", serverCfg.CipherSuites)
+	}
+
+	client := func(minVer, maxVer uint16, suites ...uint16) *tls.Config {
+		return &tls.Config{InsecureSkipVerify: true, MinVersion: minVer, MaxVersion: maxVer, CipherSuites: suites} // #nosec 402
+	}
+	if _, err := handshake(serverCfg, client(tls.VersionTLS10, tls.VersionTLS10)); err == nil {
+		t.Fatal("TLS 1.0 client was accepted with min_version 1.2")
+	}
+	if _, err := handshake(serverCfg, client(tls.VersionTLS11, tls.VersionTLS11)); err == nil {
+		t.Fatal("TLS 1.1 client was accepted with min_version 1.2")
+	}
+	state, err := handshake(serverCfg, client(tls.VersionTLS12, tls.VersionTLS12))
+	if err != nil || state.Version != tls.VersionTLS12 {
+		t.Fatalf("TLS 1.2 client: version=%x err=%v", state.Version, err)
+	}
+	if _, err := handshake(serverCfg, client(tls.VersionTLS12, tls.VersionTLS12, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA)); err == nil {
+		t.Fatal("TLS 1.2 client offering only a non-default CBC suite was accepted")
+	}
+	if state, err := handshake(serverCfg, client(tls.VersionTLS12, tls.VersionTLS13)); err != nil || state.Version != tls.VersionTLS13 {
+		t.Fatalf("TLS 1.3 client: version=%x err=%v", state.Version, err)
+	}
+}
+
+func TestSetupTLSRejectsInsecureCipherSuites(t *testing.T) {
+	for name, cfg := range map[string]*config.TLSConfig{
+		"insecure suite": NewTLSBuilder().WithCipherSuites("TLS_RSA_WITH_RC4_128_SHA").Build(),
+		"unknown suite":  NewTLSBuilder().WithCipherSuites("TLS_MADE_UP").Build(),
+		"tls 1.3 suite":  NewTLSBuilder().WithCipherSuites("TLS_AES_128_GCM_SHA256").Build(),
+		"tls 1.3 only":   NewTLSBuilder().WithVersions("1.3", "").WithCipherSuites(config.DefaultCipherSuites[0]).Build(),
+		"tls 1.0":        NewTLSBuilder().WithVersions("1.0", "").Build(),
+		"max below min":  NewTLSBuilder().WithVersions("1.3", "1.2").Build(),
+	} {
+		cfg.Dir = t.TempDir()
+		cfg.AutoGenerate = true
+		if _, err := SetupTLS(config.ServerConfig{TLS: cfg}); err == nil {
+			t.Errorf("%s: expected SetupTLS to fail", name)
+		}
+	}
+}
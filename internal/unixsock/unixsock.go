@@ -0,0 +1,124 @@
+// Package unixsock lets the API servers listen on, and the clients dial, a
+// Unix domain socket given as a unix:///path/to.sock address, so a local
+// daemon need not expose its control API on a TCP port.
+package unixsock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Scheme prefixes socket addresses: unix:///run/provisr.sock.
+const Scheme = "unix://"
+
+// DefaultMode is the socket file mode when none is configured: only the
+// daemon's user may connect.
+const DefaultMode os.FileMode = 0o600
+
+// defaultBasePath is the API base path assumed for socket URLs that don't
+// carry a base_path query parameter, matching the default server config.
+const defaultBasePath = "/api"
+
+// Path returns the socket path of a unix:// address, or ok=false for any
+// other address.
+func Path(addr string) (path string, ok bool) {
+	if !strings.HasPrefix(addr, Scheme) {
+		return "", false
+	}
+	if i := strings.IndexByte(addr, '?'); i >= 0 {
+		addr = addr[:i]
+	}
+	return strings.TrimPrefix(addr, Scheme), true
+}
+
+// Listen listens on addr: a unix:// address binds a socket with the given
+// file mode (DefaultMode if zero), anything else is a TCP address. A stale
+// socket file left by a crashed daemon is replaced; one with a live
+// listener is an error.
+func Listen(addr string, mode os.FileMode) (net.Listener, error) {
+	path, ok := Path(addr)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if path == "" {
+		return nil, errors.New("unix socket path is empty")
+	}
+	if err := removeStale(path); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode == 0 {
+		mode = DefaultMode
+	}
+	// Windows only maps the read-only bit, which doesn't apply to sockets.
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(path, mode); err != nil {
+			_ = ln.Close()
+			return nil, fmt.Errorf("chmod %s: %w", path, err)
+		}
+	}
+	return ln, nil
+}
+
+func removeStale(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
+
+// ParseURL splits a unix:///path/to.sock API URL into the socket path and
+// the HTTP base URL requests should be built on. The base path defaults to
+// /api and can be changed with a base_path query parameter, e.g.
+// unix:///run/provisr.sock?base_path=/control. ok is false for other URLs.
+func ParseURL(rawURL string) (socketPath, baseURL string, ok bool) {
+	path, ok := Path(rawURL)
+	if !ok {
+		return "", "", false
+	}
+	basePath := defaultBasePath
+	if i := strings.IndexByte(rawURL, '?'); i >= 0 {
+		if q, err := url.ParseQuery(rawURL[i+1:]); err == nil && q.Has("base_path") {
+			basePath = strings.TrimSuffix(q.Get("base_path"), "/")
+		}
+	}
+	// The host is ignored by the dialer; "unix" keeps the URL valid.
+	return path, "http://unix" + basePath, true
+}
+
+// DialContext returns a dialer that connects every request to socketPath,
+// for use as http.Transport.DialContext.
+func DialContext(socketPath string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socketPath)
+	}
+}
+
+// Transport returns an HTTP transport that sends every request over the
+// socket at socketPath.
+func Transport(socketPath string) *http.Transport {
+	return &http.Transport{DialContext: DialContext(socketPath)}
+}
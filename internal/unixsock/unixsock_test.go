@@ -0,0 +1,85 @@
+package unixsock
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		raw, socket, base string
+		ok                bool
+	}{
+		{"unix:///run/provisr.sock", "/run/provisr.sock", "http://unix/api", true},
+		{"unix:///run/provisr.sock?base_path=/control/", "/run/provisr.sock", "http://unix/control", true},
+		{"unix:///run/provisr.sock?base_path=", "/run/provisr.sock", "http://unix", true},
+		{"http://localhost:8080/api", "", "", false},
+	}
+	for _, tt := range tests {
+		socket, base, ok := ParseURL(tt.raw)
+		if socket != tt.socket || base != tt.base || ok != tt.ok {
+			t.Errorf("ParseURL(%q) = %q, %q, %v; want %q, %q, %v", tt.raw, socket, base, ok, tt.socket, tt.base, tt.ok)
+		}
+	}
+}
+
+func TestListenServesOverSocketAndReplacesStaleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+	// Leave a socket file behind with nothing listening, as a crash would.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	ln, err := Listen(Scheme+path, 0o660)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o660 {
+			t.Fatalf("socket mode = %v, want 0660", info.Mode().Perm())
+		}
+	}
+
+	if _, err := Listen(Scheme+path, 0); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("second Listen on a live socket: got %v", err)
+	}
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.Path)
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	socket, base, _ := ParseURL(Scheme + path)
+	resp, err := (&http.Client{Transport: Transport(socket)}).Get(base + "/status")
+	if err != nil {
+		t.Fatalf("GET over socket: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "/api/status" {
+		t.Fatalf("body = %q", body)
+	}
+	_ = srv.Close()
+}
+
+func TestListenRejectsNonSocketFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-a-socket")
+	if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(Scheme+path, 0); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("expected non-socket file to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+// Package version holds the build information of the provisr binary. The
+// variables are set at link time, e.g.
+//
+//	go build -ldflags "-X github.com/loykin/provisr/internal/version.Version=v1.2.3 \
+//	  -X github.com/loykin/provisr/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/loykin/provisr/internal/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// as the Makefile does. Unset values fall back to what the Go toolchain
+// recorded in the binary.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	apiwire "github.com/loykin/provisr/pkg/api"
+)
+
+// Dev is the Version of builds made without -ldflags or a module version.
+const Dev = "dev"
+
+var (
+	Version   = Dev
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Get returns the build information of the running binary.
+func Get() apiwire.VersionInfo {
+	info := apiwire.VersionInfo{Version: Version, Commit: Commit, BuildDate: BuildDate, GoVersion: runtime.Version()}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	// go install github.com/loykin/provisr/cmd/provisr@v1.2.3 records the
+	// module version; local builds record "(devel)".
+	if info.Version == Dev && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	for _, s := range build.Settings {
+		switch {
+		case s.Key == "vcs.revision" && info.Commit == "":
+			info.Commit = s.Value
+		case s.Key == "vcs.time" && info.BuildDate == "":
+			info.BuildDate = s.Value
+		}
+	}
+	return info
+}
+
+// Skewed reports whether a client and a daemon run different releases.
+// Development builds match anything, as their versions say nothing.
+func Skewed(client, daemon string) bool {
+	if client == "" || daemon == "" || client == Dev || daemon == Dev {
+		return false
+	}
+	return client != daemon
+}
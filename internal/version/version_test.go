@@ -0,0 +1,30 @@
+package version
+
+import "testing"
+
+func TestGetReportsLinkedValues(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, BuildDate = v, c, d }(Version, Commit, BuildDate)
+	Version, Commit, BuildDate = "v1.2.3", "0a1b2c3", "2026-01-02T03:04:05Z"
+
+	info := Get()
+	if info.Version != "v1.2.3" || info.Commit != "0a1b2c3" || info.BuildDate != "2026-01-02T03:04:05Z" || info.GoVersion == "" {
+		t.Fatalf("Get() = %+v", info)
+	}
+}
+
+func TestSkewed(t *testing.T) {
+	for _, tt := range []struct {
+		client, daemon string
+		want           bool
+	}{
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2.3", "v1.3.0", true},
+		{Dev, "v1.3.0", false},
+		{"v1.2.3", Dev, false},
+		{"v1.2.3", "", false},
+	} {
+		if got := Skewed(tt.client, tt.daemon); got != tt.want {
+			t.Errorf("Skewed(%q, %q) = %v, want %v", tt.client, tt.daemon, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"time"
+
+	"github.com/loykin/provisr/core"
+)
+
+// ExportVersion is the ExportDocument schema version written by GET /export.
+// POST /import rejects documents with a newer version.
+const ExportVersion = 1
+
+// ExportDocument is the backup written by GET /export and restored by
+// POST /import: every registered process (from program files and from the
+// main config file alike), the configured groups and the cron jobs. Specs
+// are complete, so lifecycle hooks and log settings round-trip.
+type ExportDocument struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Processes  []core.Spec    `json:"processes"`
+	Groups     []ExportGroup  `json:"groups"`
+	CronJobs   []core.CronJob `json:"cronjobs"`
+}
+
+// ExportGroup is a group by member process names; members must be processes
+// in the same document or already registered on the importing daemon.
+type ExportGroup struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// ImportResult lists what POST /import did, as "kind/name" entries, e.g.
+// "process/web" or "cronjob/backup". Skipped holds entries declared in the
+// target's main config file, which an import never overwrites.
+type ImportResult struct {
+	Created  []string `json:"created"`
+	Replaced []string `json:"replaced"`
+	Skipped  []string `json:"skipped"`
+}
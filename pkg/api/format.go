@@ -0,0 +1,172 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Format selects how GET /status and GET /group/status render their
+// responses, and how the CLI prints them.
+type Format string
+
+const (
+	FormatJSON  Format = "json"  // the regular JSON payload
+	FormatTable Format = "table" // aligned columns with a header row
+	FormatText  Format = "text"  // one line of key=value pairs per process
+)
+
+// ParseFormat validates a format name; the empty string is FormatJSON.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatTable, FormatText:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid format %q: must be json, table or text", s)
+	}
+}
+
+// NegotiateFormat picks the response format of a status request. An
+// explicit format query parameter wins; otherwise the first media range in
+// the Accept header that names JSON or plain text decides, with text/plain
+// meaning FormatTable. Anything else falls back to FormatJSON.
+func NegotiateFormat(query, accept string) (Format, error) {
+	if query != "" {
+		return ParseFormat(query)
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		switch mediaType {
+		case "application/json", "*/*":
+			return FormatJSON, nil
+		case "text/plain", "text/*":
+			return FormatTable, nil
+		}
+	}
+	return FormatJSON, nil
+}
+
+// ContentType is the Content-Type header for responses rendered in f.
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return "application/json"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// StatusRow is the part of a process status shown by the table and text
+// renderings. Its JSON tags match the plain GET /status payload, so clients
+// can decode responses straight into it.
+type StatusRow struct {
+	Name       string        `json:"name"`
+	State      string        `json:"state"`
+	Running    bool          `json:"running"`
+	PID        int           `json:"pid"`
+	Restarts   uint32        `json:"restarts"`
+	Uptime     time.Duration `json:"uptime"` // nanoseconds; 0 unless running
+	DetectedBy string        `json:"detected_by"`
+}
+
+// Row returns the columns of d shown by the table and text renderings.
+func (d DetailedStatus) Row() StatusRow {
+	return StatusRow{
+		Name:       d.Name,
+		State:      d.State,
+		Running:    d.Running,
+		PID:        d.PID,
+		Restarts:   d.Restarts,
+		Uptime:     time.Duration(d.UptimeSeconds * float64(time.Second)),
+		DetectedBy: d.DetectedBy,
+	}
+}
+
+// GroupRows flattens a group status, keyed by member, into rows ordered by
+// member and then by instance as the server listed them.
+func GroupRows(members map[string][]StatusRow) []StatusRow {
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var rows []StatusRow
+	for _, name := range names {
+		rows = append(rows, members[name]...)
+	}
+	return rows
+}
+
+// WriteStatusRows renders rows as FormatTable or FormatText. JSON is left to
+// the caller, which owns the payload shape.
+func WriteStatusRows(w io.Writer, f Format, rows []StatusRow) error {
+	switch f {
+	case FormatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "NAME\tSTATE\tPID\tRESTARTS\tUPTIME\tDETECTED_BY")
+		for _, r := range rows {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+				r.Name, orDash(r.State), pidColumn(r.PID), r.Restarts, uptimeColumn(r), orDash(r.DetectedBy))
+		}
+		return tw.Flush()
+	case FormatText:
+		for _, r := range rows {
+			if _, err := fmt.Fprintf(w, "name=%s state=%s running=%t pid=%d restarts=%d uptime=%s detected_by=%s\n",
+				textValue(r.Name), textValue(r.State), r.Running, r.PID, r.Restarts, uptimeColumn(r), textValue(r.DetectedBy)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("format %q is not a text rendering", f)
+	}
+}
+
+// FormatUptime renders uptime compactly, e.g. "42s", "7m" or "3h12m".
+func FormatUptime(uptime time.Duration) string {
+	switch {
+	case uptime < time.Minute:
+		return fmt.Sprintf("%ds", int(uptime.Seconds()))
+	case uptime < time.Hour:
+		return fmt.Sprintf("%dm", int(uptime.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%dm", int(uptime.Hours()), int(uptime.Minutes())%60)
+	}
+}
+
+func uptimeColumn(r StatusRow) string {
+	if !r.Running || r.Uptime <= 0 {
+		return "-"
+	}
+	return FormatUptime(r.Uptime)
+}
+
+func pidColumn(pid int) string {
+	if pid == 0 {
+		return "-"
+	}
+	return strconv.Itoa(pid)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// textValue quotes values that would otherwise break key=value parsing.
+func textValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testRows = []StatusRow{
+	{Name: "web-1", State: "running", Running: true, PID: 4242, Restarts: 1, Uptime: 3*time.Hour + 12*time.Minute, DetectedBy: "pid"},
+	{Name: "web-2", State: "stopped"},
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		query, accept string
+		want          Format
+		wantErr       bool
+	}{
+		{"", "", FormatJSON, false},
+		{"", "application/json, text/plain, */*", FormatJSON, false},
+		{"", "text/plain", FormatTable, false},
+		{"", "text/html, text/plain;q=0.9", FormatTable, false},
+		{"", "text/plain;q=0, application/json", FormatJSON, false},
+		{"text", "application/json", FormatText, false},
+		{"TABLE", "", FormatTable, false},
+		{"json", "text/plain", FormatJSON, false},
+		{"yaml", "", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NegotiateFormat(tt.query, tt.accept)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NegotiateFormat(%q, %q) = %q, %v; want %q (error: %v)", tt.query, tt.accept, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestWriteStatusRowsTable(t *testing.T) {
+	var b strings.Builder
+	if err := WriteStatusRows(&b, FormatTable, testRows); err != nil {
+		t.Fatalf("WriteStatusRows: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows, got %q", b.String())
+	}
+	if got := strings.Fields(lines[0]); strings.Join(got, " ") != "NAME STATE PID RESTARTS UPTIME DETECTED_BY" {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	if got := strings.Join(strings.Fields(lines[1]), " "); got != "web-1 running 4242 1 3h12m pid" {
+		t.Fatalf("unexpected row %q", lines[1])
+	}
+	if got := strings.Join(strings.Fields(lines[2]), " "); got != "web-2 stopped - 0 - -" {
+		t.Fatalf("unexpected row %q", lines[2])
+	}
+	if strings.Index(lines[1], "running") != strings.Index(lines[2], "stopped") {
+		t.Fatalf("columns not aligned:\n%s", b.String())
+	}
+}
+
+func TestWriteStatusRowsText(t *testing.T) {
+	var b strings.Builder
+	rows := append(testRows, StatusRow{Name: "odd name", State: "running"})
+	if err := WriteStatusRows(&b, FormatText, rows); err != nil {
+		t.Fatalf("WriteStatusRows: %v", err)
+	}
+	want := `name=web-1 state=running running=true pid=4242 restarts=1 uptime=3h12m detected_by=pid
+name=web-2 state=stopped running=false pid=0 restarts=0 uptime=- detected_by=""
+name="odd name" state=running running=false pid=0 restarts=0 uptime=- detected_by=""
+`
+	if b.String() != want {
+		t.Fatalf("unexpected text output:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWriteStatusRowsRejectsJSON(t *testing.T) {
+	if err := WriteStatusRows(&strings.Builder{}, FormatJSON, testRows); err == nil {
+		t.Fatal("expected an error for FormatJSON")
+	}
+}
+
+func TestGroupRowsOrdersByMember(t *testing.T) {
+	rows := GroupRows(map[string][]StatusRow{
+		"worker": {{Name: "worker-1"}, {Name: "worker-2"}},
+		"api":    {{Name: "api"}},
+	})
+	var names []string
+	for _, r := range rows {
+		names = append(names, r.Name)
+	}
+	if got := strings.Join(names, ","); got != "api,worker-1,worker-2" {
+		t.Fatalf("unexpected order %s", got)
+	}
+}
+
+func TestDetailedStatusRow(t *testing.T) {
+	row := DetailedStatus{Name: "a", Running: true, UptimeSeconds: 90}.Row()
+	if row.Uptime != 90*time.Second {
+		t.Fatalf("expected 90s uptime, got %v", row.Uptime)
+	}
+}
@@ -2,7 +2,12 @@
 // API clients. It deliberately contains no Gin handlers or storage adapters.
 package api
 
-import corehistory "github.com/loykin/provisr/core/history"
+import (
+	"time"
+
+	"github.com/loykin/provisr/core"
+	corehistory "github.com/loykin/provisr/core/history"
+)
 
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -17,6 +22,13 @@ type HistoryResponse struct {
 	Total int                 `json:"total"`
 }
 
+// Job is a job as returned by GET /jobs and GET /jobs/{name}: its spec with
+// the live status flattened in. POST /jobs takes the bare core.JobSpec.
+type Job struct {
+	core.JobSpec
+	Status core.JobStatus `json:"status"`
+}
+
 type GroupMember struct {
 	Name      string `json:"name"`
 	Instances int    `json:"instances"`
@@ -39,3 +51,129 @@ type RuntimeStatus struct {
 	ProgramPersistence   bool `json:"program_persistence"`
 	ConfiguredGroupCount int  `json:"configured_group_count"`
 }
+
+// Readiness is the body of GET /readyz: whether the daemon should get
+// traffic, and the outcome of each check, "ok" or why it failed.
+type Readiness struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+// VersionInfo is the build information of a provisr binary, as returned
+// by GET /version.
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// VersionHeader carries the daemon's version on every API response, so
+// clients can notice version skew without an extra request.
+const VersionHeader = "X-Provisr-Version"
+
+// BatchRequest is the body of POST /batch/start and POST /batch/stop.
+type BatchRequest struct {
+	Names []string `json:"names"`
+	Wait  string   `json:"wait,omitempty"` // Go duration; stop only (default 2s)
+}
+
+// BatchResult is the outcome of a batch operation for a single process.
+type BatchResult struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// BatchResponse lists per-name results in request order. The endpoint
+// answers 200 when every name succeeded and 207 Multi-Status otherwise.
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// RestartResult is the outcome of POST /restart for a single process.
+type RestartResult struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	PID   int    `json:"pid,omitempty"` // of the restarted process
+	Error string `json:"error,omitempty"`
+}
+
+// RestartResponse lists per-process results in name order. Like
+// BatchResponse, the endpoint answers 207 Multi-Status when any failed.
+type RestartResponse struct {
+	Results []RestartResult `json:"results"`
+}
+
+// LifecycleEvent is one process lifecycle event returned by GET /events.
+type LifecycleEvent struct {
+	Time     time.Time             `json:"time"`
+	Type     corehistory.EventType `json:"type"`
+	Name     string                `json:"name"`
+	PID      int                   `json:"pid,omitempty"`
+	Status   string                `json:"status,omitempty"`
+	ExitCode *int                  `json:"exit_code,omitempty"`
+	Error    string                `json:"error,omitempty"`
+}
+
+// StateTransition is one process state transition streamed by
+// GET /debug/transitions.
+type StateTransition struct {
+	Time time.Time `json:"time"`
+	Name string    `json:"name"`
+	From string    `json:"from"`
+	To   string    `json:"to"`
+}
+
+// EventsResponse lists lifecycle events oldest first. With follow=true,
+// GET /events streams each LifecycleEvent as a server-sent event instead.
+type EventsResponse struct {
+	Events []LifecycleEvent `json:"events"`
+}
+
+// DetailedStatus is returned by GET /status?detailed=true. Unlike the plain
+// status it is a stable schema owned by this package, so fields are only
+// ever added.
+type DetailedStatus struct {
+	Name           string            `json:"name"`
+	State          string            `json:"state"`
+	Running        bool              `json:"running"`
+	PID            int               `json:"pid"`
+	StartedAt      time.Time         `json:"started_at"`
+	StoppedAt      time.Time         `json:"stopped_at"`
+	UptimeSeconds  float64           `json:"uptime_seconds"` // 0 unless running
+	Restarts       uint32            `json:"restarts"`
+	DetectedBy     string            `json:"detected_by"`
+	LastExit       *ExitInfo         `json:"last_exit,omitempty"` // nil until the process has exited once
+	MetricsEnabled bool              `json:"metrics_enabled"`
+	Resources      *ResourceSnapshot `json:"resources,omitempty"` // nil when metrics are disabled or not yet sampled
+	Hooks          []HookSummary     `json:"hooks"`
+}
+
+// ExitInfo describes how the process last exited.
+type ExitInfo struct {
+	At     time.Time `json:"at"`
+	Code   int       `json:"code"`             // -1 when killed by a signal
+	Signal string    `json:"signal,omitempty"` // terminating signal, if any
+	Error  string    `json:"error,omitempty"`
+}
+
+// ResourceSnapshot is the latest sampled resource usage of the process.
+type ResourceSnapshot struct {
+	SampledAt  time.Time `json:"sampled_at"`
+	CPUPercent float64   `json:"cpu_percent"`
+	MemoryRSS  uint64    `json:"memory_rss"`
+	MemoryMB   float64   `json:"memory_mb"`
+	NumThreads int32     `json:"num_threads"`
+}
+
+// HookSummary aggregates the executions of one lifecycle hook.
+type HookSummary struct {
+	Phase          string    `json:"phase"`
+	Name           string    `json:"name"`
+	Runs           int       `json:"runs"`
+	Failures       int       `json:"failures"`
+	LastRunAt      time.Time `json:"last_run_at"`
+	LastDurationMS int64     `json:"last_duration_ms"`
+	LastError      string    `json:"last_error,omitempty"`
+}
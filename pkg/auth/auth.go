@@ -10,6 +10,8 @@ type (
 	AuthService  = auth.AuthService
 	AuthConfig   = auth.AuthConfig
 	StoreConfig  = auth.StoreConfig
+	OIDCConfig   = auth.OIDCConfig
+	LDAPConfig   = auth.LDAPConfig
 	AuthMethod   = auth.AuthMethod
 	AuthResult   = auth.AuthResult
 	Token        = auth.Token
@@ -20,6 +22,7 @@ type (
 const (
 	AuthMethodBasic = auth.AuthMethodBasic
 	AuthMethodJWT   = auth.AuthMethodJWT
+	AuthMethodOIDC  = auth.AuthMethodOIDC
 )
 
 // NewAuthService creates a new AuthService from the given config.
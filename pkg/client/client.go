@@ -7,10 +7,15 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
+
+	"github.com/loykin/provisr/internal/unixsock"
+	apiwire "github.com/loykin/provisr/pkg/api"
 )
 
 // Client provides HTTP client functionality to communicate with provisr daemon
@@ -22,7 +27,7 @@ type Client struct {
 
 // Config holds client configuration
 type Config struct {
-	BaseURL  string
+	BaseURL  string // http(s)://host:port/api, or unix:///path/to.sock for a local socket
 	Timeout  time.Duration
 	Logger   *slog.Logger // Optional logger for client operations
 	TLS      *TLSClientConfig
@@ -92,6 +97,12 @@ func New(config Config) *Client {
 		}
 	}
 
+	// unix:///path/to.sock sends every request over the daemon's socket.
+	if socketPath, baseURL, ok := unixsock.ParseURL(config.BaseURL); ok {
+		transport.DialContext = unixsock.DialContext(socketPath)
+		config.BaseURL = baseURL
+	}
+
 	return &Client{
 		baseURL: config.BaseURL,
 		logger:  config.Logger,
@@ -168,6 +179,167 @@ func (c *Client) UnregisterProcess(ctx context.Context, req UnregisterRequest) e
 	return nil
 }
 
+// BatchStart starts the named processes concurrently. A non-nil response
+// is returned whenever the daemon processed the batch; check each result's
+// OK field, since some names may have failed while others succeeded.
+func (c *Client) BatchStart(ctx context.Context, names []string) (*BatchResponse, error) {
+	c.logger.Debug("Batch starting processes", "names", names)
+	return c.doBatch(ctx, "/batch/start", apiwire.BatchRequest{Names: names})
+}
+
+// BatchStop stops the named processes concurrently, waiting up to wait for
+// each to exit (0 uses the daemon default). See BatchStart for how partial
+// failures are reported.
+func (c *Client) BatchStop(ctx context.Context, names []string, wait time.Duration) (*BatchResponse, error) {
+	c.logger.Debug("Batch stopping processes", "names", names, "wait", wait)
+	req := apiwire.BatchRequest{Names: names}
+	if wait > 0 {
+		req.Wait = wait.String()
+	}
+	return c.doBatch(ctx, "/batch/stop", req)
+}
+
+func (c *Client) doBatch(ctx context.Context, path string, body apiwire.BatchRequest) (*BatchResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "error", err, "url", req.URL.String())
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+		return nil, c.handleErrorResponse(resp)
+	}
+	var out BatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode batch response: %w", err)
+	}
+	return &out, nil
+}
+
+// DetailedStatus returns the expanded status of the processes selected by q
+// (exactly one of Name, Base, Wildcard or Regex).
+func (c *Client) DetailedStatus(ctx context.Context, q StatusQuery) ([]DetailedStatus, error) {
+	params := url.Values{"detailed": {"true"}}
+	switch {
+	case q.Name != "":
+		params.Set("name", q.Name)
+	case q.Base != "":
+		params.Set("base", q.Base)
+	case q.Wildcard != "":
+		params.Set("wildcard", q.Wildcard)
+	case q.Regex != "":
+		params.Set("regex", q.Regex)
+	default:
+		return nil, fmt.Errorf("status query requires name, base, wildcard or regex")
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/status?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "error", err, "url", req.URL.String())
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+	// A name query answers with a single object; selectors answer with a list.
+	if q.Name != "" {
+		var one DetailedStatus
+		if err := json.NewDecoder(resp.Body).Decode(&one); err != nil {
+			return nil, fmt.Errorf("decode status response: %w", err)
+		}
+		return []DetailedStatus{one}, nil
+	}
+	var out []DetailedStatus
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode status response: %w", err)
+	}
+	return out, nil
+}
+
+// CreateJob creates and starts a job on the daemon.
+func (c *Client) CreateJob(ctx context.Context, spec JobSpec) error {
+	c.logger.Debug("Creating job", "name", spec.Name, "command", spec.Command)
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	return c.doJSONRequest(ctx, "POST", c.baseURL+"/jobs", data)
+}
+
+// ListJobs returns the daemon's jobs with their status, sorted by name.
+func (c *Client) ListJobs(ctx context.Context) ([]Job, error) {
+	var jobs []Job
+	if err := c.getJSON(ctx, "/jobs", &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetJob returns the spec and status of a single job.
+func (c *Client) GetJob(ctx context.Context, name string) (*Job, error) {
+	var job Job
+	if err := c.getJSON(ctx, "/jobs/"+url.PathEscape(name), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// JobInstances returns every instance launched for a job, in launch order.
+func (c *Client) JobInstances(ctx context.Context, name string) ([]JobInstanceStatus, error) {
+	var instances []JobInstanceStatus
+	if err := c.getJSON(ctx, "/jobs/"+url.PathEscape(name)+"/instances", &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// DeleteJob stops a job's running instances and removes it.
+func (c *Client) DeleteJob(ctx context.Context, name string) error {
+	c.logger.Debug("Deleting job", "name", name)
+	return c.doRequest(ctx, "DELETE", c.baseURL+"/jobs/"+url.PathEscape(name), nil)
+}
+
+// getJSON performs a GET request and decodes a 200 response into out.
+func (c *Client) getJSON(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logger.Error("HTTP request failed", "error", err, "url", req.URL.String())
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return c.handleErrorResponse(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
 // setupClientTLS configures TLS settings for HTTP client
 func setupClientTLS(config Config) (*tls.Config, error) {
 	tlsConfig := &tls.Config{}
@@ -228,7 +400,8 @@ func loadCACert(tlsConfig *tls.Config, caCertPath string) error {
 
 // doRequest performs HTTP request with common error handling
 func (c *Client) doRequest(ctx context.Context, method, url string, body []byte) error {
-	var bodyReader *bytes.Reader
+	// A nil *bytes.Reader in an io.Reader is not a nil body.
+	var bodyReader io.Reader
 	if body != nil {
 		bodyReader = bytes.NewReader(body)
 	}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/loykin/provisr/core"
+	"github.com/loykin/provisr/internal/server"
+	"github.com/loykin/provisr/internal/unixsock"
+)
+
+func TestClientBatchStartStop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	for _, name := range []string{"one", "two"} {
+		if err := mgr.Register(core.Spec{Name: name, Command: "sleep 5"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := httptest.NewServer(server.NewRouter(mgr, "").Handler())
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	resp, err := c.BatchStop(ctx, []string{"one", "two"}, time.Second)
+	if err != nil {
+		t.Fatalf("BatchStop: %v", err)
+	}
+	for _, res := range resp.Results {
+		if !res.OK {
+			t.Fatalf("stop %s failed: %s", res.Name, res.Error)
+		}
+	}
+
+	resp, err = c.BatchStart(ctx, []string{"one", "ghost"})
+	if err != nil {
+		t.Fatalf("partial success should not be an error: %v", err)
+	}
+	if len(resp.Results) != 2 || !resp.Results[0].OK || resp.Results[1].OK {
+		t.Fatalf("unexpected results: %+v", resp.Results)
+	}
+
+	if _, err := c.BatchStart(ctx, nil); err == nil {
+		t.Fatalf("empty batch should be rejected")
+	}
+}
+
+func TestClientDetailedStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	for _, name := range []string{"web-1", "web-2"} {
+		if err := mgr.Register(core.Spec{Name: name, Command: "sleep 5"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := httptest.NewServer(server.NewRouter(mgr, "").Handler())
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	one, err := c.DetailedStatus(ctx, StatusQuery{Name: "web-1"})
+	if err != nil {
+		t.Fatalf("DetailedStatus(name): %v", err)
+	}
+	if len(one) != 1 || one[0].Name != "web-1" || !one[0].Running {
+		t.Fatalf("unexpected single status: %+v", one)
+	}
+	all, err := c.DetailedStatus(ctx, StatusQuery{Regex: "^web-"})
+	if err != nil {
+		t.Fatalf("DetailedStatus(regex): %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 statuses, got %+v", all)
+	}
+	if _, err := c.DetailedStatus(ctx, StatusQuery{}); err == nil {
+		t.Fatal("expected an error for an empty query")
+	}
+}
+
+func TestClientJobs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	srv := httptest.NewServer(server.NewRouter(mgr, "").Handler())
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	if err := c.CreateJob(ctx, JobSpec{Name: "migrate", Command: "sleep 5"}); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	if err := c.CreateJob(ctx, JobSpec{Name: "migrate", Command: "sleep 5"}); err == nil {
+		t.Fatal("creating a duplicate job should fail")
+	}
+	jobs, err := c.ListJobs(ctx)
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Name != "migrate" || jobs[0].Command != "sleep 5" {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+	job, err := c.GetJob(ctx, "migrate")
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if job.Status.Phase != "Running" {
+		t.Fatalf("job phase = %q, want Running", job.Status.Phase)
+	}
+	instances, err := c.JobInstances(ctx, "migrate")
+	if err != nil || len(instances) != 1 {
+		t.Fatalf("JobInstances = %+v, %v", instances, err)
+	}
+
+	if err := c.DeleteJob(ctx, "migrate"); err != nil {
+		t.Fatalf("DeleteJob: %v", err)
+	}
+	if _, err := c.GetJob(ctx, "migrate"); err == nil {
+		t.Fatal("deleted job should not be found")
+	}
+}
+
+func TestClientOverUnixSocket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mgr := core.New()
+	defer func() { _ = mgr.Shutdown() }()
+	if err := mgr.Register(core.Spec{Name: "local", Command: "sleep 5"}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "provisr.sock")
+	ln, err := unixsock.Listen(unixsock.Scheme+path, 0)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := &http.Server{Handler: server.NewRouter(mgr, "/api").Handler()}
+	go func() { _ = srv.Serve(ln) }()
+	defer func() { _ = srv.Close() }()
+
+	c := New(Config{BaseURL: "unix://" + path})
+	ctx := context.Background()
+	if !c.IsReachable(ctx) {
+		t.Fatal("daemon not reachable over unix socket")
+	}
+	sts, err := c.DetailedStatus(ctx, StatusQuery{Name: "local"})
+	if err != nil {
+		t.Fatalf("DetailedStatus over socket: %v", err)
+	}
+	if len(sts) != 1 || !sts[0].Running {
+		t.Fatalf("unexpected status: %+v", sts)
+	}
+}
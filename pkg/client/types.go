@@ -3,6 +3,7 @@ package client
 import (
 	"time"
 
+	"github.com/loykin/provisr/core"
 	apiwire "github.com/loykin/provisr/pkg/api"
 )
 
@@ -17,6 +18,7 @@ type RegisterRequest struct {
 	AutoRestart     bool          `json:"autorestart,omitempty"`
 	RestartInterval time.Duration `json:"restart_interval,omitempty"`
 	StartDuration   time.Duration `json:"start_duration,omitempty"`
+	StartTimeout    time.Duration `json:"start_timeout,omitempty"`
 	Instances       int           `json:"instances,omitempty"`
 	Environment     []string      `json:"environment,omitempty"`
 	Priority        int           `json:"priority,omitempty"`
@@ -32,6 +34,7 @@ type StopRequest struct {
 	Name     string        `json:"name,omitempty"`
 	Base     string        `json:"base,omitempty"`
 	Wildcard string        `json:"wildcard,omitempty"`
+	Regex    string        `json:"regex,omitempty"`
 	Wait     time.Duration `json:"wait,omitempty"`
 }
 
@@ -48,6 +51,7 @@ type StatusQuery struct {
 	Name     string
 	Base     string
 	Wildcard string
+	Regex    string
 }
 
 // ProcessStatus represents the status of a single process
@@ -64,3 +68,9 @@ type ProcessStatus struct {
 // ErrorResponse represents an API error response
 type ErrorResponse = apiwire.ErrorResponse
 type HistoryResponse = apiwire.HistoryResponse
+type BatchResult = apiwire.BatchResult
+type BatchResponse = apiwire.BatchResponse
+type DetailedStatus = apiwire.DetailedStatus
+type Job = apiwire.Job
+type JobSpec = core.JobSpec
+type JobInstanceStatus = core.JobInstanceStatus
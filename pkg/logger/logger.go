@@ -8,11 +8,14 @@ import (
 )
 
 type (
-	Config     = core.LogConfig
-	SlogConfig = core.LogSlogConfig
-	FileConfig = core.LogFileConfig
-	LogLevel   = core.LogLevel
-	Format     = core.LogFormat
+	Config       = core.LogConfig
+	SlogConfig   = core.LogSlogConfig
+	FileConfig   = core.LogFileConfig
+	SyslogConfig = core.LogSyslogConfig
+	LogLevel     = core.LogLevel
+	Format       = core.LogFormat
+
+	OutputFormat = core.LogOutputFormat
 )
 
 const (
@@ -23,6 +26,10 @@ const (
 
 	FormatText = core.LogFormatText
 	FormatJSON = core.LogFormatJSON
+
+	OutputFormatRaw      = core.LogOutputFormatRaw
+	OutputFormatJSON     = core.LogOutputFormatJSON
+	OutputFormatPrefixed = core.LogOutputFormatPrefixed
 )
 
 // DefaultConfig returns the default logger configuration.
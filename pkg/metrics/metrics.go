@@ -3,6 +3,8 @@ package metrics
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync/atomic"
 
 	"github.com/loykin/provisr/core/observability"
@@ -43,10 +45,28 @@ var (
 			Namespace: "provisr",
 			Subsystem: "process",
 			Name:      "start_duration_seconds",
-			Help:      "Observed start duration wait window when StartDuration > 0.",
+			Help:      "Time from starting to running, including pre_start hooks.",
 			Buckets:   prometheus.DefBuckets,
 		}, []string{"name"},
 	)
+	processStopDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "provisr",
+			Subsystem: "process",
+			Name:      "stop_duration_seconds",
+			Help:      "Time from stopping to stopped, including pre_stop hooks.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"name"},
+	)
+	hookDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "provisr",
+			Subsystem: "process",
+			Name:      "hook_duration_seconds",
+			Help:      "Lifecycle hook run time by phase, retries counted separately.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"name", "phase"},
+	)
 	runningInstances = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "provisr",
@@ -75,6 +95,35 @@ var (
 	)
 )
 
+// constLabels are added to every metric registered through Register and
+// ProcessMetricsCollector.RegisterMetrics.
+var constLabels atomic.Pointer[prometheus.Labels]
+
+// SetConstLabels sets constant labels (e.g. host, env, cluster) attached to
+// every provisr metric, so series from several daemons scraped into one
+// Prometheus can be told apart. It only affects registrations made after it
+// is called; nil or an empty map removes them.
+func SetConstLabels(labels map[string]string) {
+	if len(labels) == 0 {
+		constLabels.Store(nil)
+		return
+	}
+	l := make(prometheus.Labels, len(labels))
+	for k, v := range labels {
+		l[k] = v
+	}
+	constLabels.Store(&l)
+}
+
+// withConstLabels wraps r so collectors registered through it carry the
+// configured constant labels.
+func withConstLabels(r prometheus.Registerer) prometheus.Registerer {
+	if l := constLabels.Load(); l != nil {
+		return prometheus.WrapRegistererWith(*l, r)
+	}
+	return r
+}
+
 // processMetricsCollector is a global instance for process metrics collection
 var processMetricsCollector *ProcessMetricsCollector
 
@@ -85,10 +134,14 @@ func Register(r prometheus.Registerer) error {
 		return nil
 	}
 	cs := []prometheus.Collector{
-		processStarts, processRestarts, processStops, processStartDuration, runningInstances, stateTransitions, currentStates,
+		processStarts, processRestarts, processStops, processStartDuration, processStopDuration, hookDuration,
+		runningInstances, stateTransitions, currentStates,
 		jobsTotal, jobDuration, jobsActive, jobCompletions, jobBackoffLimit,
-		cronjobsTotal, cronjobDuration, cronjobsActive, cronjobLastSchedule, cronjobNextSchedule,
+		cronjobsTotal, cronjobDuration, cronjobsActive, cronjobLastSchedule, cronjobNextSchedule, cronjobRuns, cronjobLastSuccess,
+		historyEventsDropped,
+		httpRequests, httpRequestDuration,
 	}
+	r = withConstLabels(r)
 	for _, c := range cs {
 		if err := r.Register(c); err != nil {
 			// If already registered, ignore (allows double Register with default registry)
@@ -110,8 +163,12 @@ func observeCoreEvent(event observability.Event) {
 	switch event.Kind {
 	case observability.ProcessStarted:
 		IncStart(event.Name)
+		ObserveStartDuration(event.Name, event.Duration)
 	case observability.ProcessStopped:
 		IncStop(event.Name)
+		ObserveStopDuration(event.Name, event.Duration)
+	case observability.HookCompleted:
+		ObserveHookDuration(event.Name, event.Phase, event.Duration)
 	case observability.ProcessStateChanged:
 		RecordStateTransition(event.Name, event.From, event.To)
 		SetCurrentState(event.Name, event.From, false)
@@ -137,6 +194,10 @@ func observeCoreEvent(event observability.Event) {
 	case observability.CronJobCompleted:
 		IncCronJobTotal(event.Name, event.Phase)
 		ObserveCronJobDuration(event.Name, event.Phase, event.Duration)
+		IncCronJobRun(event.Name, strings.ToLower(event.Phase))
+		if event.Phase == "Succeeded" && event.UnixTime != 0 {
+			SetCronJobLastSuccess(event.Name, event.UnixTime)
+		}
 	}
 }
 
@@ -187,6 +248,16 @@ func ObserveStartDuration(name string, seconds float64) {
 		processStartDuration.WithLabelValues(name).Observe(seconds)
 	}
 }
+func ObserveStopDuration(name string, seconds float64) {
+	if regOK.Load() {
+		processStopDuration.WithLabelValues(name).Observe(seconds)
+	}
+}
+func ObserveHookDuration(name, phase string, seconds float64) {
+	if regOK.Load() {
+		hookDuration.WithLabelValues(name, phase).Observe(seconds)
+	}
+}
 func SetRunningInstances(base string, n int) {
 	if regOK.Load() {
 		runningInstances.WithLabelValues(base).Set(float64(n))
@@ -287,6 +358,22 @@ var (
 			Help:      "Last time a cronjob was scheduled (unix timestamp).",
 		}, []string{"cronjob_name"},
 	)
+	cronjobRuns = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "provisr",
+			Subsystem: "cronjob",
+			Name:      "runs_total",
+			Help:      "Completed cronjob runs by result (succeeded or failed).",
+		}, []string{"cronjob_name", "result"},
+	)
+	cronjobLastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "provisr",
+			Subsystem: "cronjob",
+			Name:      "last_success_timestamp",
+			Help:      "Last time a cronjob run succeeded (unix timestamp).",
+		}, []string{"cronjob_name"},
+	)
 	cronjobNextSchedule = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "provisr",
@@ -295,6 +382,36 @@ var (
 			Help:      "Next time a cronjob will be scheduled (unix timestamp).",
 		}, []string{"cronjob_name"},
 	)
+
+	// History sink metrics
+	historyEventsDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "provisr",
+			Subsystem: "history",
+			Name:      "events_dropped_total",
+			Help:      "History events dropped because a sink's delivery queue was full.",
+		}, []string{"sink"},
+	)
+
+	// HTTP API metrics. path is the route template (e.g. /api/jobs/:name),
+	// never the raw URL, so label cardinality stays bounded by the routes.
+	httpRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "provisr",
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP API requests by method, route and status code.",
+		}, []string{"method", "path", "status"},
+	)
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "provisr",
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP API request latency by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "path"},
+	)
 )
 
 func IncJobTotal(jobName, phase string) {
@@ -350,3 +467,32 @@ func SetCronJobNextSchedule(cronjobName string, timestamp float64) {
 		cronjobNextSchedule.WithLabelValues(cronjobName).Set(timestamp)
 	}
 }
+
+// IncCronJobRun counts a completed cronjob run; result is "succeeded" or
+// "failed".
+func IncCronJobRun(cronjobName, result string) {
+	if regOK.Load() {
+		cronjobRuns.WithLabelValues(cronjobName, result).Inc()
+	}
+}
+
+func SetCronJobLastSuccess(cronjobName string, timestamp float64) {
+	if regOK.Load() {
+		cronjobLastSuccess.WithLabelValues(cronjobName).Set(timestamp)
+	}
+}
+
+func IncHistoryEventDropped(sink string) {
+	if regOK.Load() {
+		historyEventsDropped.WithLabelValues(sink).Inc()
+	}
+}
+
+// ObserveHTTPRequest records one served HTTP API request. route must be a
+// route template rather than the request path.
+func ObserveHTTPRequest(method, route string, status int, seconds float64) {
+	if regOK.Load() {
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		httpRequestDuration.WithLabelValues(method, route).Observe(seconds)
+	}
+}
@@ -9,7 +9,10 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/loykin/provisr/core/observability"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestRegisterIdempotentAndCountersWork(t *testing.T) {
@@ -203,3 +206,121 @@ func (e *errorRegisterer) Register(prometheus.Collector) error {
 
 func (e *errorRegisterer) MustRegister(...prometheus.Collector) {}
 func (e *errorRegisterer) Unregister(prometheus.Collector) bool { return false }
+
+func TestCronJobRunMetrics(t *testing.T) {
+	if err := Register(prometheus.NewRegistry()); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	observe := Observer().Observe
+
+	observe(observability.Event{Kind: observability.CronJobCompleted, Name: "nightly", Phase: "Succeeded", Duration: 2, UnixTime: 1700000000})
+	observe(observability.Event{Kind: observability.CronJobCompleted, Name: "nightly", Phase: "Succeeded", Duration: 3, UnixTime: 1700086400})
+	observe(observability.Event{Kind: observability.CronJobCompleted, Name: "nightly", Phase: "Failed", Duration: 1, UnixTime: 1700172800})
+
+	if got := testutil.ToFloat64(cronjobRuns.WithLabelValues("nightly", "succeeded")); got != 2 {
+		t.Fatalf("succeeded runs = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(cronjobRuns.WithLabelValues("nightly", "failed")); got != 1 {
+		t.Fatalf("failed runs = %v, want 1", got)
+	}
+	// A failure leaves the last success alone.
+	if got := testutil.ToFloat64(cronjobLastSuccess.WithLabelValues("nightly")); got != 1700086400 {
+		t.Fatalf("last success = %v, want 1700086400", got)
+	}
+}
+
+func TestConstLabelsOnScrapedMetrics(t *testing.T) {
+	SetConstLabels(map[string]string{"host": "node-a", "env": "prod"})
+	defer SetConstLabels(nil)
+	originalState := regOK.Load()
+	regOK.Store(false)
+	defer regOK.Store(originalState)
+
+	reg := prometheus.NewRegistry()
+	if err := Register(reg); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	collector := NewProcessMetricsCollector(ProcessMetricsConfig{Enabled: true})
+	if err := collector.RegisterMetrics(reg); err != nil {
+		t.Fatalf("register process metrics: %v", err)
+	}
+	IncStart("web")
+	collector.processCPUPercent.WithLabelValues("web", "0").Set(12.5)
+
+	srv := httptest.NewServer(promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	b, _ := io.ReadAll(resp.Body)
+	s := string(b)
+	for _, want := range []string{
+		`provisr_process_starts_total{env="prod",host="node-a",name="web"} 1`,
+		`provisr_process_cpu_percent{env="prod",host="node-a",instance_id="0",process_name="web"} 12.5`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("scrape missing %s", want)
+		}
+	}
+}
+
+func TestLifecycleDurationMetrics(t *testing.T) {
+	originalState := regOK.Load()
+	regOK.Store(false)
+	defer regOK.Store(originalState)
+	reg := prometheus.NewRegistry()
+	if err := Register(reg); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	observe := Observer().Observe
+
+	observe(observability.Event{Kind: observability.ProcessStarted, Name: "timed", Duration: 0.4})
+	observe(observability.Event{Kind: observability.ProcessStopped, Name: "timed", Duration: 1.5})
+	observe(observability.Event{Kind: observability.HookCompleted, Name: "timed", Phase: "pre_start", Duration: 0.2})
+	observe(observability.Event{Kind: observability.HookCompleted, Name: "timed", Phase: "post_stop", Duration: 0.1})
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	// metric name -> phase label ("" for none) -> observed sum
+	sums := map[string]map[string]float64{}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			if m.GetHistogram() == nil {
+				continue
+			}
+			var name, phase string
+			for _, l := range m.GetLabel() {
+				switch l.GetName() {
+				case "name":
+					name = l.GetValue()
+				case "phase":
+					phase = l.GetValue()
+				}
+			}
+			if name != "timed" {
+				continue
+			}
+			if sums[mf.GetName()] == nil {
+				sums[mf.GetName()] = map[string]float64{}
+			}
+			sums[mf.GetName()][phase] += m.GetHistogram().GetSampleSum()
+		}
+	}
+	for _, want := range []struct {
+		metric, phase string
+		sum           float64
+	}{
+		{"provisr_process_start_duration_seconds", "", 0.4},
+		{"provisr_process_stop_duration_seconds", "", 1.5},
+		{"provisr_process_hook_duration_seconds", "pre_start", 0.2},
+		{"provisr_process_hook_duration_seconds", "post_stop", 0.1},
+	} {
+		if got := sums[want.metric][want.phase]; got != want.sum {
+			t.Errorf("%s{phase=%q} sum = %v, want %v", want.metric, want.phase, got, want.sum)
+		}
+	}
+}
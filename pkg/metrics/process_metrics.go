@@ -7,10 +7,11 @@ import (
 	"log/slog"
 	"runtime"
 	"strconv"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/loykin/provisr/core/instancename"
 	corestats "github.com/loykin/provisr/core/stats"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/shirou/gopsutil/v4/process"
@@ -45,6 +46,20 @@ type ProcessInstanceHistory struct {
 	Instances   map[string][]ProcessMetrics `json:"instances"` // instanceID -> metrics history
 	MaxSize     int                         `json:"max_size"`
 	mu          sync.RWMutex
+
+	names map[string]string // instanceID -> full process name it was collected under
+}
+
+// fullName returns the process name the instanceID series was collected
+// under, falling back to the default naming for series without one.
+func (h *ProcessInstanceHistory) fullName(instanceID string) string {
+	if name, ok := h.names[instanceID]; ok {
+		return name
+	}
+	if instanceID == "0" {
+		return h.ProcessName
+	}
+	return h.ProcessName + "-" + instanceID
 }
 
 // ProcessMetricsCollector manages CPU and memory monitoring for managed processes
@@ -57,6 +72,7 @@ type ProcessMetricsCollector struct {
 	stopCh          chan struct{}
 	stopOnce        sync.Once
 	wg              sync.WaitGroup
+	naming          atomic.Pointer[instancename.Scheme] // nil is the default scheme
 
 	// Prometheus metrics for process monitoring with consistent labels
 	processCPUPercent *prometheus.GaugeVec
@@ -73,21 +89,27 @@ type ProcessMetricsConfig struct {
 }
 
 // parseProcessName extracts process name and instance ID from full name
+// using the instance naming scheme (nil for the default one).
 // Examples: "app-1" -> ("app", "1"), "app" -> ("app", "0")
-func parseProcessName(fullName string) (processName, instanceID string) {
-	parts := strings.Split(fullName, "-")
-	if len(parts) >= 2 {
-		// Check if last part is numeric
-		if _, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
-			processName = strings.Join(parts[:len(parts)-1], "-")
-			instanceID = parts[len(parts)-1]
-			return
-		}
+func parseProcessName(naming *instancename.Scheme, fullName string) (processName, instanceID string) {
+	if base, index, ok := naming.Parse(fullName); ok {
+		return base, strconv.Itoa(index)
 	}
-	// If no numeric suffix, use full name as process name and "0" as instance
+	// If not an instance name, use full name as process name and "0" as instance
 	return fullName, "0"
 }
 
+// SetInstanceNaming sets the scheme instance names are parsed with, so the
+// series of custom-named instances are grouped under their base process.
+// nil restores the default base-1, base-2, ... scheme.
+func (c *ProcessMetricsCollector) SetInstanceNaming(naming *instancename.Scheme) {
+	c.naming.Store(naming)
+}
+
+func (c *ProcessMetricsCollector) parseProcessName(fullName string) (processName, instanceID string) {
+	return parseProcessName(c.naming.Load(), fullName)
+}
+
 // NewProcessMetricsCollector creates a new process metrics collector
 func NewProcessMetricsCollector(config ProcessMetricsConfig) *ProcessMetricsCollector {
 	maxHistory := config.MaxHistory
@@ -158,6 +180,7 @@ func (c *ProcessMetricsCollector) RegisterMetrics(r prometheus.Registerer) error
 		collectors = append(collectors, c.processNumFDs)
 	}
 
+	r = withConstLabels(r)
 	for _, collector := range collectors {
 		if err := r.Register(collector); err != nil {
 			// Ignore already registered errors
@@ -235,7 +258,7 @@ func (c *ProcessMetricsCollector) collectMetrics(processes map[string]int32) {
 
 	// Batch update Prometheus metrics and history
 	for name, metrics := range metricsResults {
-		processName, instanceID := parseProcessName(name)
+		processName, instanceID := c.parseProcessName(name)
 
 		// Update Prometheus metrics with consistent labels
 		c.processCPUPercent.WithLabelValues(processName, instanceID).Set(metrics.CPUPercent)
@@ -246,7 +269,7 @@ func (c *ProcessMetricsCollector) collectMetrics(processes map[string]int32) {
 			c.processNumFDs.WithLabelValues(processName, instanceID).Set(float64(metrics.NumFDs))
 		}
 
-		c.addToInstanceHistory(processName, instanceID, metrics)
+		c.addToInstanceHistory(name, processName, instanceID, metrics)
 	}
 
 	// Clean up metrics for processes that no longer exist
@@ -308,12 +331,13 @@ func (c *ProcessMetricsCollector) getProcessMetrics(name string, pid int32, time
 
 // addToHistory maps a full process instance name to the canonical instance history.
 func (c *ProcessMetricsCollector) addToHistory(name string, metrics ProcessMetrics) {
-	processName, instanceID := parseProcessName(name)
-	c.addToInstanceHistory(processName, instanceID, metrics)
+	processName, instanceID := c.parseProcessName(name)
+	c.addToInstanceHistory(name, processName, instanceID, metrics)
 }
 
-// addToInstanceHistory adds metrics to the instance-based historical data
-func (c *ProcessMetricsCollector) addToInstanceHistory(processName, instanceID string, metrics ProcessMetrics) {
+// addToInstanceHistory adds metrics of the process name to the instance-based
+// historical data
+func (c *ProcessMetricsCollector) addToInstanceHistory(name, processName, instanceID string, metrics ProcessMetrics) {
 	c.historyMu.Lock()
 	defer c.historyMu.Unlock()
 
@@ -323,6 +347,7 @@ func (c *ProcessMetricsCollector) addToInstanceHistory(processName, instanceID s
 			ProcessName: processName,
 			Instances:   make(map[string][]ProcessMetrics),
 			MaxSize:     c.maxHistory,
+			names:       make(map[string]string),
 		}
 		c.instanceHistory[processName] = history
 	}
@@ -334,6 +359,7 @@ func (c *ProcessMetricsCollector) addToInstanceHistory(processName, instanceID s
 	if history.Instances[instanceID] == nil {
 		history.Instances[instanceID] = make([]ProcessMetrics, 0, c.maxHistory)
 	}
+	history.names[instanceID] = name
 
 	// Add new metrics to the instance history
 	instanceMetrics := history.Instances[instanceID]
@@ -356,11 +382,7 @@ func (c *ProcessMetricsCollector) cleanupMetrics(activeProcesses map[string]int3
 	for processName, history := range c.instanceHistory {
 		history.mu.RLock()
 		for instanceID := range history.Instances {
-			fullName := processName + "-" + instanceID
-			if instanceID == "0" {
-				fullName = processName
-			}
-			if _, exists := activeProcesses[fullName]; !exists {
+			if _, exists := activeProcesses[history.fullName(instanceID)]; !exists {
 				toDeleteFromInstance = append(toDeleteFromInstance, struct {
 					processName string
 					instanceID  string
@@ -383,6 +405,7 @@ func (c *ProcessMetricsCollector) cleanupMetrics(activeProcesses map[string]int3
 			if history, exists := c.instanceHistory[item.processName]; exists {
 				history.mu.Lock()
 				delete(history.Instances, item.instanceID)
+				delete(history.names, item.instanceID)
 				// If no instances left, remove the entire process history
 				if len(history.Instances) == 0 {
 					delete(c.instanceHistory, item.processName)
@@ -394,13 +417,34 @@ func (c *ProcessMetricsCollector) cleanupMetrics(activeProcesses map[string]int3
 	}
 }
 
+// Forget drops the Prometheus series and history of the process instance
+// name, e.g. after it was removed by scaling down.
+func (c *ProcessMetricsCollector) Forget(name string) {
+	processName, instanceID := c.parseProcessName(name)
+	c.historyMu.Lock()
+	defer c.historyMu.Unlock()
+	c.processCPUPercent.DeleteLabelValues(processName, instanceID)
+	c.processMemoryMB.DeleteLabelValues(processName, instanceID)
+	c.processNumThreads.DeleteLabelValues(processName, instanceID)
+	c.processNumFDs.DeleteLabelValues(processName, instanceID)
+	if history, exists := c.instanceHistory[processName]; exists {
+		history.mu.Lock()
+		delete(history.Instances, instanceID)
+		delete(history.names, instanceID)
+		if len(history.Instances) == 0 {
+			delete(c.instanceHistory, processName)
+		}
+		history.mu.Unlock()
+	}
+}
+
 // GetMetrics returns the latest metrics for a specific process
 func (c *ProcessMetricsCollector) GetMetrics(name string) (ProcessMetrics, bool) {
 	if !c.enabled {
 		return ProcessMetrics{}, false
 	}
 
-	processName, instanceID := parseProcessName(name)
+	processName, instanceID := c.parseProcessName(name)
 	instance, ok := c.GetInstanceMetrics(processName, instanceID)
 	if !ok {
 		return ProcessMetrics{}, false
@@ -414,7 +458,7 @@ func (c *ProcessMetricsCollector) GetHistory(name string) ([]ProcessMetrics, boo
 		return nil, false
 	}
 
-	processName, instanceID := parseProcessName(name)
+	processName, instanceID := c.parseProcessName(name)
 	return c.GetInstanceHistory(processName, instanceID)
 }
 
@@ -428,15 +472,11 @@ func (c *ProcessMetricsCollector) GetAllMetrics() map[string]ProcessMetrics {
 	defer c.historyMu.RUnlock()
 
 	result := make(map[string]ProcessMetrics)
-	for processName, history := range c.instanceHistory {
+	for _, history := range c.instanceHistory {
 		history.mu.RLock()
 		for instanceID, metrics := range history.Instances {
 			if len(metrics) > 0 {
-				name := processName
-				if instanceID != "0" {
-					name += "-" + instanceID
-				}
-				result[name] = metrics[len(metrics)-1]
+				result[history.fullName(instanceID)] = metrics[len(metrics)-1]
 			}
 		}
 		history.mu.RUnlock()
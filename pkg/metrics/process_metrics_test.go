@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/loykin/provisr/core/instancename"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
@@ -242,6 +243,78 @@ func TestProcessMetricsCleanup(t *testing.T) {
 	assert.False(t, found2)
 }
 
+func TestProcessMetricsForget(t *testing.T) {
+	collector := NewProcessMetricsCollector(ProcessMetricsConfig{Enabled: true})
+	for _, name := range []string{"worker-1", "worker-2", "worker-3"} {
+		collector.addToHistory(name, ProcessMetrics{Name: name})
+		collector.processCPUPercent.WithLabelValues(parseProcessName(nil, name)).Set(1)
+	}
+
+	collector.Forget("worker-3")
+
+	_, found := collector.GetMetrics("worker-3")
+	assert.False(t, found)
+	_, found = collector.GetMetrics("worker-2")
+	assert.True(t, found)
+	assert.False(t, collector.processCPUPercent.DeleteLabelValues("worker", "3"), "series of worker-3 should be gone")
+	assert.True(t, collector.processCPUPercent.DeleteLabelValues("worker", "2"))
+}
+
+func TestParseProcessName(t *testing.T) {
+	padded, err := instancename.New("{{.Base}}_{{.Index}}", 0, 2)
+	assert.NoError(t, err)
+	cases := []struct {
+		naming      *instancename.Scheme
+		name        string
+		processName string
+		instanceID  string
+	}{
+		{nil, "web-1", "web", "1"},
+		{nil, "web-api-12", "web-api", "12"},
+		{nil, "web", "web", "0"},
+		{nil, "web-canary", "web-canary", "0"},
+		{padded, "web_00", "web", "0"},
+		{padded, "web-api_07", "web-api", "7"},
+		{padded, "web-1", "web-1", "0"},
+	}
+	for _, tc := range cases {
+		processName, instanceID := parseProcessName(tc.naming, tc.name)
+		assert.Equal(t, tc.processName, processName, tc.name)
+		assert.Equal(t, tc.instanceID, instanceID, tc.name)
+	}
+}
+
+func TestProcessMetricsCustomInstanceNaming(t *testing.T) {
+	naming, err := instancename.New("{{.Base}}.{{.Index}}", 0, 3)
+	assert.NoError(t, err)
+	collector := NewProcessMetricsCollector(ProcessMetricsConfig{Enabled: true})
+	collector.SetInstanceNaming(naming)
+
+	names := naming.Names("worker", 2)
+	for _, name := range names {
+		collector.addToHistory(name, ProcessMetrics{Name: name, MemoryMB: 10})
+	}
+
+	agg, ok := collector.GetProcessMetrics("worker")
+	assert.True(t, ok)
+	assert.Equal(t, 2, agg.TotalInstances)
+	assert.InDelta(t, 20.0, agg.TotalMemoryMB, 0.001)
+
+	all := collector.GetAllMetrics()
+	assert.Len(t, all, 2)
+	for _, name := range names {
+		assert.Contains(t, all, name)
+		_, found := collector.GetMetrics(name)
+		assert.True(t, found, name)
+	}
+
+	collector.cleanupMetrics(map[string]int32{names[0]: 1})
+	_, found := collector.GetMetrics(names[0])
+	assert.True(t, found)
+	_, found = collector.GetMetrics(names[1])
+	assert.False(t, found)
+}
+
 func TestProcessMetricsSetEnabled(t *testing.T) {
 	config := ProcessMetricsConfig{
 		Enabled: true,
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// PushConfig configures pushing metrics to a Prometheus Pushgateway, for
+// daemons too short-lived to be scraped.
+type PushConfig struct {
+	URL      string        `mapstructure:"url"`
+	Job      string        `mapstructure:"job"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Pusher pushes the metrics of a gatherer to a Pushgateway on an interval
+// and once more when stopped.
+type Pusher struct {
+	pusher   *push.Pusher
+	interval time.Duration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewPusher creates a pusher for the metrics gathered by g. The job label
+// defaults to "provisr" and the interval to 15 seconds.
+func NewPusher(config PushConfig, g prometheus.Gatherer) *Pusher {
+	job := config.Job
+	if job == "" {
+		job = "provisr"
+	}
+	interval := config.Interval
+	if interval == 0 {
+		interval = 15 * time.Second
+	}
+	return &Pusher{
+		pusher:   push.New(config.URL, job).Gatherer(g),
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start pushes on the configured interval until ctx is done or Stop is
+// called. Failed pushes are logged and retried on the next tick.
+func (p *Pusher) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.stopCh:
+				return
+			case <-ticker.C:
+				if err := p.Push(ctx); err != nil {
+					slog.Warn("Failed to push metrics", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Push replaces the metrics of this job on the Pushgateway with the current
+// ones.
+func (p *Pusher) Push(ctx context.Context) error {
+	return p.pusher.PushContext(ctx)
+}
+
+// Stop ends the periodic pushes and pushes a final time, so the values at
+// shutdown (e.g. a cron job run that just finished) are not lost.
+func (p *Pusher) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+	p.wg.Wait()
+	return p.Push(ctx)
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPusherPushesOnIntervalAndStop(t *testing.T) {
+	type pushed struct {
+		method, path string
+		body         []byte
+	}
+	var (
+		mu     sync.Mutex
+		pushes []pushed
+	)
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pushes = append(pushes, pushed{r.Method, r.URL.Path, body})
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(pushes)
+	}
+
+	reg := prometheus.NewRegistry()
+	runs := prometheus.NewCounter(prometheus.CounterOpts{Name: "provisr_test_runs_total", Help: "Test runs."})
+	reg.MustRegister(runs)
+	runs.Inc()
+
+	p := NewPusher(PushConfig{URL: gateway.URL, Job: "batch", Interval: 20 * time.Millisecond}, reg)
+	p.Start(context.Background())
+	deadline := time.Now().Add(2 * time.Second)
+	for count() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected interval pushes, got %d", count())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatalf("final push: %v", err)
+	}
+	n := count()
+	time.Sleep(60 * time.Millisecond)
+	if count() != n {
+		t.Fatal("pusher kept pushing after Stop")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, push := range pushes {
+		if push.method != http.MethodPut || push.path != "/metrics/job/batch" {
+			t.Fatalf("unexpected push %s %s", push.method, push.path)
+		}
+		if !bytes.Contains(push.body, []byte("provisr_test_runs_total")) {
+			t.Fatal("push is missing the registered metric")
+		}
+	}
+}
+
+func TestPusherStopReportsGatewayErrors(t *testing.T) {
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer gateway.Close()
+
+	p := NewPusher(PushConfig{URL: gateway.URL}, prometheus.NewRegistry())
+	if err := p.Stop(context.Background()); err == nil {
+		t.Fatal("expected the failed final push to be reported")
+	}
+}
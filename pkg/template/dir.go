@@ -0,0 +1,104 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// TemplateInfo describes a template available to GenerateJSON.
+type TemplateInfo struct {
+	Type string `json:"type"`
+	Path string `json:"path,omitempty"` // file of a custom template; empty for built-ins
+}
+
+// CustomTemplateSuffix ends the file name of every custom template. Specs
+// generated from templates are plain .json files, often written to the same
+// directory, so the suffix keeps them from being read back as templates.
+const CustomTemplateSuffix = ".tmpl.json"
+
+// NewDirGenerator creates a generator that also reads custom templates from
+// dir: each <type>.tmpl.json file there is a template selected by its type,
+// and takes precedence over a built-in type of the same name.
+func NewDirGenerator(dir string) *Generator {
+	return &Generator{dir: dir}
+}
+
+// customTemplatePath returns the file of the custom template templateType,
+// or "" when there is none.
+func (g *Generator) customTemplatePath(templateType TemplateType) string {
+	if g.dir == "" || templateType == "" || strings.ContainsAny(string(templateType), `/\`) || strings.Contains(string(templateType), "..") {
+		return ""
+	}
+	path := filepath.Join(g.dir, string(templateType)+CustomTemplateSuffix)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return ""
+	}
+	return path
+}
+
+// generateCustomJSON renders the custom template at path for name. The file
+// is a JSON process spec in which {{.Name}} is replaced with name; the
+// "name" field is always set to name.
+func (g *Generator) generateCustomJSON(path, name string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	tmpl, err := texttemplate.New(filepath.Base(path)).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, struct{ Name string }{Name: name}); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", path, err)
+	}
+
+	var spec map[string]interface{}
+	if err := json.Unmarshal(rendered.Bytes(), &spec); err != nil {
+		return nil, fmt.Errorf("template %s is not a JSON object: %w", path, err)
+	}
+	spec["name"] = name
+
+	jsonData, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal template: %w", err)
+	}
+	return jsonData, nil
+}
+
+// ListTemplates returns the custom templates followed by the built-in types
+// they do not override, each group sorted by type.
+func (g *Generator) ListTemplates() ([]TemplateInfo, error) {
+	var custom []TemplateInfo
+	overridden := map[string]bool{}
+	if g.dir != "" {
+		entries, err := os.ReadDir(g.dir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read templates directory: %w", err)
+		}
+		for _, entry := range entries {
+			typ, ok := strings.CutSuffix(entry.Name(), CustomTemplateSuffix)
+			if entry.IsDir() || !ok || typ == "" {
+				continue
+			}
+			custom = append(custom, TemplateInfo{Type: typ, Path: filepath.Join(g.dir, entry.Name())})
+			overridden[typ] = true
+		}
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i].Type < custom[j].Type })
+
+	builtin := g.GetSupportedTypes()
+	sort.Strings(builtin)
+	for _, typ := range builtin {
+		if !overridden[typ] {
+			custom = append(custom, TemplateInfo{Type: typ})
+		}
+	}
+	return custom, nil
+}
@@ -0,0 +1,88 @@
+package template
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirGenerator_CustomTemplates(t *testing.T) {
+	dir := t.TempDir()
+	custom := `{"name": "ignored", "command": "./bin/{{.Name}}", "log": {"file": {"dir": "/var/log/{{.Name}}"}}, "instances": 2}`
+	if err := os.WriteFile(filepath.Join(dir, "edge.tmpl.json"), []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A custom template named like a built-in type replaces it.
+	if err := os.WriteFile(filepath.Join(dir, "web.tmpl.json"), []byte(`{"command": "nginx"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.tmpl.json"), []byte(`{"command": "{{.Missing}}"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g := NewDirGenerator(dir)
+
+	data, err := g.GenerateJSON("edge", "gateway")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+	if spec["name"] != "gateway" || spec["command"] != "./bin/gateway" || spec["instances"] != float64(2) {
+		t.Fatalf("unexpected spec %v", spec)
+	}
+	if dir := spec["log"].(map[string]interface{})["file"].(map[string]interface{})["dir"]; dir != "/var/log/gateway" {
+		t.Fatalf("log dir not substituted: %v", dir)
+	}
+
+	data, err = g.GenerateJSON(TypeWeb, "site")
+	if err != nil || !strings.Contains(string(data), `"nginx"`) {
+		t.Fatalf("custom web template not used: %s, %v", data, err)
+	}
+	// Built-ins remain available as a fallback.
+	if _, err := g.GenerateJSON(TypeAPI, "svc"); err != nil {
+		t.Fatalf("built-in fallback: %v", err)
+	}
+	if _, err := g.GenerateJSON("broken", "x"); err == nil {
+		t.Fatal("expected an error for an unknown template field")
+	}
+	if _, err := g.GenerateJSON("../edge", "x"); err == nil {
+		t.Fatal("expected template types with path separators to be rejected")
+	}
+}
+
+func TestDirGenerator_ListTemplates(t *testing.T) {
+	dir := t.TempDir()
+	// gateway.json is a generated spec, not a template.
+	for _, name := range []string{"web.tmpl.json", "edge.tmpl.json", "gateway.json", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(`{}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	templates, err := NewDirGenerator(dir).ListTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tmpl := range templates {
+		if tmpl.Path != "" {
+			got = append(got, tmpl.Type+"*")
+		} else {
+			got = append(got, tmpl.Type)
+		}
+	}
+	want := "edge* web* api cron database simple worker"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("got %v, want %s", got, want)
+	}
+
+	// A missing directory only has the built-ins.
+	templates, err = NewDirGenerator(filepath.Join(dir, "missing")).ListTemplates()
+	if err != nil || len(templates) != len(NewGenerator().GetSupportedTypes()) {
+		t.Fatalf("missing dir: got %v, %v", templates, err)
+	}
+}
@@ -46,7 +46,9 @@ type FileLogConfig struct {
 }
 
 // Generator provides template generation functionality
-type Generator struct{}
+type Generator struct {
+	dir string // custom templates; see NewDirGenerator
+}
 
 // NewGenerator creates a new template generator
 func NewGenerator() *Generator {
@@ -73,8 +75,12 @@ func (g *Generator) Generate(templateType TemplateType, name string) (*ProcessTe
 	}
 }
 
-// GenerateJSON creates a JSON representation of the template
+// GenerateJSON creates a JSON representation of the template, from the
+// custom template of that type if there is one.
 func (g *Generator) GenerateJSON(templateType TemplateType, name string) ([]byte, error) {
+	if path := g.customTemplatePath(templateType); path != "" {
+		return g.generateCustomJSON(path, name)
+	}
 	template, err := g.Generate(templateType, name)
 	if err != nil {
 		return nil, err
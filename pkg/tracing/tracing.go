@@ -0,0 +1,116 @@
+// Package tracing adapts the core tracing port to OpenTelemetry and sets up
+// an OTLP/HTTP exporter for the provisr daemon.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/loykin/provisr/core/observability"
+)
+
+const instrumentationName = "github.com/loykin/provisr"
+
+// Options configures the exporter installed by Setup.
+type Options struct {
+	Endpoint    string  // OTLP/HTTP host:port; empty uses the OTEL_EXPORTER_OTLP_* environment or localhost:4318
+	Insecure    bool    // send over plain HTTP
+	ServiceName string  // service.name resource attribute (default "provisr")
+	SampleRatio float64 // fraction of new traces sampled; 0 means 1 (always)
+}
+
+// Setup creates a TracerProvider exporting to an OTLP/HTTP collector and
+// installs it, with W3C trace-context propagation, as the global provider.
+// Call Shutdown on the returned provider to flush pending spans on exit.
+func Setup(ctx context.Context, opts Options) (*sdktrace.TracerProvider, error) {
+	var exporterOpts []otlptracehttp.Option
+	if opts.Endpoint != "" {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpoint(opts.Endpoint))
+	}
+	if opts.Insecure {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+
+	serviceName := opts.ServiceName
+	if serviceName == "" {
+		serviceName = "provisr"
+	}
+	ratio := opts.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp, nil
+}
+
+// NewTracer returns a core Tracer backed by tp. Pass it to
+// Manager.SetTracer; a nil tp uses the global provider.
+func NewTracer(tp trace.TracerProvider) observability.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return otelTracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+type otelTracer struct{ tracer trace.Tracer }
+
+func (t otelTracer) Start(ctx context.Context, name string, attrs ...observability.Attr) (context.Context, observability.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(convert(attrs)...))
+	return ctx, otelSpan{span}
+}
+
+func (t otelTracer) SpanFromContext(ctx context.Context) observability.Span {
+	return otelSpan{trace.SpanFromContext(ctx)}
+}
+
+type otelSpan struct{ span trace.Span }
+
+func (s otelSpan) SetAttributes(attrs ...observability.Attr) { s.span.SetAttributes(convert(attrs)...) }
+func (s otelSpan) AddEvent(name string, attrs ...observability.Attr) {
+	s.span.AddEvent(name, trace.WithAttributes(convert(attrs)...))
+}
+func (s otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+func (s otelSpan) End() { s.span.End() }
+
+func convert(attrs []observability.Attr) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			out = append(out, attribute.String(a.Key, v))
+		case bool:
+			out = append(out, attribute.Bool(a.Key, v))
+		case int:
+			out = append(out, attribute.Int(a.Key, v))
+		case int64:
+			out = append(out, attribute.Int64(a.Key, v))
+		case float64:
+			out = append(out, attribute.Float64(a.Key, v))
+		default:
+			out = append(out, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/loykin/provisr/core/observability"
+)
+
+func TestNewTracerExportsSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewTracer(tp)
+
+	ctx, parent := tracer.Start(context.Background(), "manager.start", observability.String("process.name", "web"))
+	_, child := tracer.Start(ctx, "lifecycle_hook", observability.Int("attempt", 2), observability.Bool("async", false))
+	child.RecordError(errors.New("boom"))
+	child.End()
+	tracer.SpanFromContext(ctx).AddEvent("state_transition", observability.String("to", "running"))
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	hook, start := spans[0], spans[1]
+	if hook.Parent().SpanID() != start.SpanContext().SpanID() {
+		t.Fatal("hook span is not a child of manager.start")
+	}
+	if hook.Status().Code != codes.Error || len(hook.Events()) != 1 {
+		t.Fatalf("error not recorded: status=%v events=%v", hook.Status(), hook.Events())
+	}
+	wantAttrs := []attribute.KeyValue{attribute.Int("attempt", 2), attribute.Bool("async", false)}
+	if got := hook.Attributes(); len(got) != 2 || got[0] != wantAttrs[0] || got[1] != wantAttrs[1] {
+		t.Fatalf("hook attributes = %v", got)
+	}
+	if got := start.Attributes(); len(got) != 1 || got[0] != attribute.String("process.name", "web") {
+		t.Fatalf("start attributes = %v", got)
+	}
+	if ev := start.Events(); len(ev) != 1 || ev[0].Name != "state_transition" {
+		t.Fatalf("state transition event missing: %v", ev)
+	}
+}
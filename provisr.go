@@ -7,11 +7,15 @@
 package provisr
 
 import (
+	"log/slog"
 	"net/http"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/loykin/provisr/core"
+	iaudit "github.com/loykin/provisr/internal/audit"
+	iauth "github.com/loykin/provisr/internal/auth"
 	cfg "github.com/loykin/provisr/internal/config"
 	"github.com/loykin/provisr/internal/history/factory"
 	iapi "github.com/loykin/provisr/internal/server"
@@ -25,11 +29,36 @@ import (
 type Spec = core.Spec
 type Status = core.Status
 type DetectorConfig = core.DetectorConfig
+type PortInUseError = core.PortInUseError
+
+// ErrNotRunning is returned (wrapped) by Manager.Signal when the process is
+// not running.
+var ErrNotRunning = core.ErrNotRunning
+
+// ParseSignal parses a signal name such as "HUP" or "SIGUSR1", or a signal
+// number, for Manager.Signal.
+func ParseSignal(s string) (syscall.Signal, error) { return core.ParseSignal(s) }
+
+// ParseLabelSelector parses "key=value" terms, separate or comma-joined,
+// into the labels a process must have to match.
+func ParseLabelSelector(terms ...string) (map[string]string, error) {
+	return core.ParseLabelSelector(terms...)
+}
+
+// ParseStatusFields parses a comma-separated list of Status JSON field
+// names for Status.Project.
+func ParseStatusFields(list string) ([]string, error) { return core.ParseStatusFields(list) }
+
+// EnsureDir creates dir, when missing, with mode (default "0750") and,
+// when set, owner "user[:group]".
+func EnsureDir(dir, mode, owner string) error { return core.EnsureDir(dir, mode, owner) }
 
 // Log config types
 type LogConfig = core.LogConfig
 type LogFileConfig = core.LogFileConfig
 type LogSlogConfig = core.LogSlogConfig
+type LogSyslogConfig = core.LogSyslogConfig
+type LogLine = core.LogLine
 
 // Detector types
 type Detector = core.Detector
@@ -42,6 +71,8 @@ type LifecycleHooks = core.LifecycleHooks
 type Hook = core.Hook
 type FailureMode = core.FailureMode
 type RunMode = core.RunMode
+type HookSummary = core.HookSummary
+type AsyncHookInfo = core.AsyncHookInfo
 type LifecyclePhase = core.LifecyclePhase
 
 const (
@@ -57,6 +88,33 @@ const (
 type Manager = core.Manager
 type ManagerInstanceGroup = core.ManagerInstanceGroup
 
+// EnvProvider computes the environment of each process start (see
+// Manager.SetEnvProvider).
+type EnvProvider = core.EnvProvider
+
+// StartInterceptor vets or adjusts the spec of each process start (see
+// Manager.SetStartInterceptor).
+type StartInterceptor = core.StartInterceptor
+
+// Apply plan types (see Manager.ApplyConfigWithOptions)
+type ApplyOptions = core.ApplyOptions
+type ApplyPlan = core.ApplyPlan
+type PlanChange = core.PlanChange
+
+// Reconcile result types (see Manager.Reconcile)
+type ReconcileResult = core.ReconcileResult
+type ReconcileFailure = core.ReconcileFailure
+
+const (
+	PlanStart   = core.PlanStart
+	PlanRecover = core.PlanRecover
+	PlanStop    = core.PlanStop
+	PlanKeep    = core.PlanKeep
+	PlanAdd     = core.PlanAdd
+	PlanUpdate  = core.PlanUpdate
+	PlanRemove  = core.PlanRemove
+)
+
 // HistorySink is the interface for process event backends.
 // The built-in factory supports opensearch://, postgres://, postgresql://, and sqlite://.
 // For ClickHouse, import github.com/loykin/provisr/history/clickhouse separately.
@@ -64,18 +122,28 @@ type HistorySink = core.HistorySink
 type HistoryReader = core.HistoryReader
 type HistoryEntry = core.HistoryEntry
 type HistoryPruner = core.HistoryPruner
+type HistoryPinger = core.HistoryPinger
+type HistoryEvent = core.HistoryEvent
+type JobOutput = core.JobOutput
+type JobOutputStore = core.JobOutputStore
+type ProcessMetadataStore = core.ProcessMetadataStore
 
 // Process metrics types
 type ProcessMetrics = core.ProcessMetrics
 type ProcessMetricsCollector = metricsadapter.ProcessMetricsCollector
 type ProcessMetricsConfig = metricsadapter.ProcessMetricsConfig
+type MetricsPusher = metricsadapter.Pusher
+type MetricsPushConfig = metricsadapter.PushConfig
 
 // Group / Job / Cron facades (re-exports)
 type Group = core.Group
 type ServiceGroup = core.ServiceGroup
+type RollingRestartOptions = core.RollingRestartOptions
+type GroupRestartResult = core.GroupRestartResult
 type JobManager = core.JobManager
 type JobSpec = core.JobSpec
 type JobStatus = core.JobStatus
+type JobInstanceStatus = core.JobInstanceStatus
 type CronScheduler = core.CronScheduler
 type CronJob = core.CronJob
 type CronJobStatus = core.CronJobStatus
@@ -107,7 +175,14 @@ type ServerConfig = cfg.ServerConfig
 type TLSConfig = cfg.TLSConfig
 type AutoGenTLS = cfg.AutoGenTLS
 type ServerAuthConfig = cfg.AuthConfig
+type GRPCConfig = cfg.GRPCConfig
+type CORSConfig = cfg.CORSConfig
+type AccessLogConfig = cfg.AccessLogConfig
+type RateLimitConfig = cfg.RateLimitConfig
+type AuditConfig = cfg.AuditConfig
 type HistoryConfig = cfg.HistoryConfig
+type MetricsConfig = cfg.MetricsConfig
+type MetricsAuthConfig = cfg.MetricsAuthConfig
 
 // LoadConfig parses a provisr configuration file.
 func LoadConfig(path string) (*cfg.LoadedConfig, error) { return cfg.LoadConfig(path) }
@@ -132,6 +207,54 @@ func NewTLSServerWithHistoryReader(serverConfig ServerConfig, m *Manager, cronSc
 	return iapi.NewTLSServerWithHistoryReader(serverConfig, m, cronScheduler, reader, programsDirectory)
 }
 
+// AuthService authenticates API users and issues their tokens.
+type AuthService = iauth.AuthService
+
+// NewAuthService opens the auth service of authCfg, or returns nil when it
+// is absent or disabled. Pass it to the *WithAuth servers so they share
+// users and tokens, and close it after them.
+func NewAuthService(authCfg *ServerAuthConfig) (*AuthService, error) {
+	return iapi.NewAuthService(authCfg)
+}
+
+// AuditLogger records control-plane calls to the audit log.
+type AuditLogger = iaudit.Logger
+
+// NewAuditLogger opens the audit log of auditCfg, writing through
+// authService for the store sink, or returns nil when it is absent or
+// disabled. Pass it to the *WithAuth servers so they append to one log,
+// and close it after them and before authService.
+func NewAuditLogger(auditCfg *AuditConfig, authService *AuthService) (*AuditLogger, error) {
+	return iapi.NewAuditLogger(auditCfg, authService)
+}
+
+// NewHTTPServerWithAuth is NewHTTPServerWithHistoryReader authenticating
+// with authService and auditing to auditLogger instead of opening its own
+// from serverConfig.Auth and serverConfig.Audit.
+func NewHTTPServerWithAuth(serverConfig ServerConfig, m *Manager, cronScheduler *CronScheduler, reader HistoryReader, authService *AuthService, auditLogger *AuditLogger, programsDirectory string) (*http.Server, error) {
+	return iapi.NewServerWithAuth(serverConfig, m, cronScheduler, reader, authService, auditLogger, programsDirectory)
+}
+
+func NewTLSServerWithAuth(serverConfig ServerConfig, m *Manager, cronScheduler *CronScheduler, reader HistoryReader, authService *AuthService, auditLogger *AuditLogger, programsDirectory string) (*http.Server, error) {
+	return iapi.NewTLSServerWithAuth(serverConfig, m, cronScheduler, reader, authService, auditLogger, programsDirectory)
+}
+
+// GRPCServer serves the gRPC API next to the HTTP server.
+type GRPCServer = iapi.GRPCServer
+
+// NewGRPCServer starts the gRPC API on serverConfig.GRPC.Listen, sharing m
+// and cronScheduler with the HTTP server.
+func NewGRPCServer(serverConfig ServerConfig, m *Manager, cronScheduler *CronScheduler, programsDirectory string) (*GRPCServer, error) {
+	return iapi.NewGRPCServer(serverConfig, m, cronScheduler, programsDirectory)
+}
+
+// NewGRPCServerWithAuth is NewGRPCServer authenticating with authService,
+// so it accepts the tokens of an HTTP server sharing it, and auditing to
+// auditLogger.
+func NewGRPCServerWithAuth(serverConfig ServerConfig, m *Manager, cronScheduler *CronScheduler, authService *AuthService, auditLogger *AuditLogger, programsDirectory string) (*GRPCServer, error) {
+	return iapi.NewGRPCServerWithAuth(serverConfig, m, cronScheduler, authService, auditLogger, programsDirectory)
+}
+
 // Router is a thin facade over the internal HTTP router for embedding into
 // Gin, Echo, or any net/http-compatible mux.
 type Router struct{ inner *iapi.Router }
@@ -144,6 +267,25 @@ func NewRouter(m *Manager, basePath string) *Router {
 // Handler returns the net/http.Handler for the provisr API.
 func (r *Router) Handler() http.Handler { return r.inner.Handler() }
 
+// SetCORS enables CORS for browser clients on the listed origins.
+func (r *Router) SetCORS(c *CORSConfig) { r.inner.SetCORS(c) }
+
+// SetRateLimit enables per-client token-bucket rate limiting.
+func (r *Router) SetRateLimit(c *RateLimitConfig) { r.inner.SetRateLimit(c) }
+
+// RateLimiter is a per-client token-bucket limiter whose Middleware and
+// ClientMiddleware can guard individually registered handlers.
+type RateLimiter = iapi.RateLimiter
+
+// NewRateLimiter creates a RateLimiter for c.RequestsPerSecond and c.Burst.
+func NewRateLimiter(c RateLimitConfig) *RateLimiter { return iapi.NewRateLimiter(c) }
+
+// SetAccessLog enables per-request access logging; a nil logger uses
+// slog.Default().
+func (r *Router) SetAccessLog(c *AccessLogConfig, logger *slog.Logger) {
+	r.inner.SetAccessLog(c, logger)
+}
+
 // APIEndpoints provides individual gin.HandlerFunc accessors so callers can
 // attach per-route middleware before registering with a Gin router group.
 type APIEndpoints struct{ inner *iapi.APIEndpoints }
@@ -157,6 +299,8 @@ func (e *APIEndpoints) RegisterHandler() gin.HandlerFunc    { return e.inner.Reg
 func (e *APIEndpoints) UpdateHandler() gin.HandlerFunc      { return e.inner.UpdateHandler() }
 func (e *APIEndpoints) StartHandler() gin.HandlerFunc       { return e.inner.StartHandler() }
 func (e *APIEndpoints) StopHandler() gin.HandlerFunc        { return e.inner.StopHandler() }
+func (e *APIEndpoints) BatchStartHandler() gin.HandlerFunc  { return e.inner.BatchStartHandler() }
+func (e *APIEndpoints) BatchStopHandler() gin.HandlerFunc   { return e.inner.BatchStopHandler() }
 func (e *APIEndpoints) StatusHandler() gin.HandlerFunc      { return e.inner.StatusHandler() }
 func (e *APIEndpoints) UnregisterHandler() gin.HandlerFunc  { return e.inner.UnregisterHandler() }
 func (e *APIEndpoints) GroupStartHandler() gin.HandlerFunc  { return e.inner.GroupStartHandler() }
@@ -191,6 +335,16 @@ func RegisterMetrics(r prometheus.Registerer) error { return metricsadapter.Regi
 func RegisterMetricsDefault() error                 { return metricsadapter.Register(prometheus.DefaultRegisterer) }
 func MetricsObserver() core.Observer                { return metricsadapter.Observer() }
 
+// NewMetricsPusher pushes the metrics of the default registry to a
+// Pushgateway; call Start and, on shutdown, Stop for a final push.
+func NewMetricsPusher(cfg MetricsPushConfig) *MetricsPusher {
+	return metricsadapter.NewPusher(cfg, prometheus.DefaultGatherer)
+}
+
+// SetMetricsConstLabels sets constant labels added to every metric registered
+// after the call.
+func SetMetricsConstLabels(labels map[string]string) { metricsadapter.SetConstLabels(labels) }
+
 func RegisterMetricsWithProcessMetricsDefault(cfg ProcessMetricsConfig) error {
 	return metricsadapter.RegisterWithProcessMetrics(prometheus.DefaultRegisterer, cfg)
 }
@@ -209,3 +363,23 @@ func ServeMetrics(addr string) error {
 	}
 	return srv.ListenAndServe()
 }
+
+// ServeMetricsWithConfig is ServeMetrics with the path and auth of
+// metricsConfig; authService (see NewAuthService) supplies the API users
+// when metrics.auth has no bearer token. It runs the server in the caller
+// goroutine.
+func ServeMetricsWithConfig(metricsConfig MetricsConfig, authService *AuthService) error {
+	handler, err := iapi.NewMetricsHandler(metricsConfig, authService)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Addr:              metricsConfig.Listen,
+		Handler:           handler,
+		ReadTimeout:       10 * time.Second,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+	return srv.ListenAndServe()
+}